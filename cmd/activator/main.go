@@ -21,6 +21,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -30,6 +31,7 @@ import (
 	zipkin "github.com/openzipkin/zipkin-go"
 	perrors "github.com/pkg/errors"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
@@ -47,10 +49,12 @@ import (
 	activatorconfig "knative.dev/serving/pkg/activator/config"
 	activatorhandler "knative.dev/serving/pkg/activator/handler"
 	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
 	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
 	"knative.dev/serving/pkg/goversion"
+	"knative.dev/serving/pkg/health"
 	pkghttp "knative.dev/serving/pkg/http"
 	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/network"
@@ -111,6 +115,9 @@ func statReporter(statSink *websocket.ManagedConnection, stopCh <-chan struct{},
 
 type config struct {
 	PodName string `split_words:"true" required:"true"`
+	// NodeName is the node the activator runs on. When set, requests are
+	// routed to the pods in the same zone or region first.
+	NodeName string `split_words:"true"` // optional
 }
 
 func main() {
@@ -124,7 +131,9 @@ func main() {
 		log.Fatal("Error parsing logging configuration:", err)
 	}
 	createdLogger, atomicLevel := logging.NewLoggerFromConfig(logConfig, component)
-	logger := createdLogger.With(zap.String(logkey.ControllerType, "activator"))
+	// The log level may be overridden per namespace and per revision.
+	levelOverrides := logging.NewLevelOverrides(logConfig, component, atomicLevel)
+	logger := levelOverrides.Wrap(createdLogger).With(zap.String(logkey.ControllerType, "activator"))
 	defer flush(logger)
 
 	logger.Info("Starting the knative activator")
@@ -152,6 +161,11 @@ func main() {
 		logger.Fatalw("Timed out attempting to get k8s version", zap.Error(err))
 	}
 
+	var env config
+	if err := envconfig.Process("", &env); err != nil {
+		logger.Fatal("Failed to process env", err)
+	}
+
 	reporter, err := activator.NewStatsReporter()
 	if err != nil {
 		logger.Fatalw("Failed to create stats reporter", zap.Error(err))
@@ -170,20 +184,48 @@ func main() {
 	endpointInformer := kubeInformerFactory.Core().V1().Endpoints()
 	serviceInformer := kubeInformerFactory.Core().V1().Services()
 	revisionInformer := servingInformerFactory.Serving().V1alpha1().Revisions()
+	routeInformer := servingInformerFactory.Serving().V1alpha1().Routes()
 	sksInformer := servingInformerFactory.Networking().V1alpha1().ServerlessServices()
-
-	// Run informers instead of starting them from the factory to prevent the sync hanging because of empty handler.
-	if err := controller.StartInformers(
-		stopCh,
+	// Only watch the pods of revisions, to learn when they become Ready before
+	// their Endpoints do, and the Secrets of revisions, holding the keys their
+	// probes are signed with.
+	revisionKubeInformerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClient, defaultResyncInterval,
+		kubeinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = serving.RevisionLabelKey
+		}))
+	podInformer := revisionKubeInformerFactory.Core().V1().Pods()
+	secretInformer := revisionKubeInformerFactory.Core().V1().Secrets()
+
+	nodeInformer := kubeInformerFactory.Core().V1().Nodes()
+
+	informers := []controller.Informer{
 		revisionInformer.Informer(),
+		routeInformer.Informer(),
 		endpointInformer.Informer(),
 		serviceInformer.Informer(),
-		sksInformer.Informer()); err != nil {
+		sksInformer.Informer(),
+		podInformer.Informer(),
+		secretInformer.Informer(),
+	}
+	// The zones of the nodes are only needed to route to the pods close to us.
+	if env.NodeName != "" {
+		informers = append(informers, nodeInformer.Informer())
+	}
+	// Run informers instead of starting them from the factory to prevent the sync hanging because of empty handler.
+	if err := controller.StartInformers(stopCh, informers...); err != nil {
 		logger.Fatalw("Failed to start informers", zap.Error(err))
 	}
 
 	params := queue.BreakerParams{QueueDepth: breakerQueueDepth, MaxConcurrency: breakerMaxConcurrency, InitialCapacity: 0}
 	throttler := activator.NewThrottler(params, endpointInformer, sksInformer.Lister(), revisionInformer.Lister(), logger)
+	throttler.WatchPods(podInformer)
+	if env.NodeName != "" {
+		node, err := nodeInformer.Lister().Get(env.NodeName)
+		if err != nil {
+			logger.Fatalw("Failed to get the node of the activator", zap.Error(err))
+		}
+		throttler.PreferLocalPods(node, nodeInformer.Lister())
+	}
 
 	activatorL3 := fmt.Sprintf("%s:%d", activator.K8sServiceName, networking.ServiceHTTPPort)
 	zipkinEndpoint, err := zipkin.NewEndpoint("activator", activatorL3)
@@ -204,7 +246,7 @@ func main() {
 	})
 
 	// Set up our config store
-	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace())
+	configMapWatcher := health.NewConfigMapWatcher(configmap.NewInformedWatcher(kubeClient, system.Namespace()))
 	configStore := activatorconfig.NewStore(createdLogger, tracerUpdater)
 	configStore.WatchConfigs(configMapWatcher)
 
@@ -214,10 +256,6 @@ func main() {
 	statSink := websocket.NewDurableSendingConnection(autoscalerEndpoint, logger)
 	go statReporter(statSink, stopCh, statChan, logger)
 
-	var env config
-	if err := envconfig.Process("", &env); err != nil {
-		logger.Fatal("Failed to process env", err)
-	}
 	podName := env.PodName
 
 	// Create and run our concurrency reporter
@@ -235,9 +273,22 @@ func main() {
 		revisionInformer.Lister(),
 		serviceInformer.Lister(),
 		sksInformer.Lister(),
+		secretInformer.Lister(),
 	)
+	// Apply the network config to the requests the activator proxies. The
+	// activator doesn't append to X-Forwarded-For, the queue-proxy
+	// sanitizes its requests again and applies forwardedForPolicy.
+	netConfig := newNetworkConfig()
+	ah = network.NewForwardedForHandler(func() network.ForwardedForPolicy {
+		return network.ForwardedForPreserve
+	}, ah)
+	ah = network.NewForwardedHeadersHandler(netConfig.load, ah)
 	ah = activatorhandler.NewRequestEventHandler(reqChan, ah)
-	ah = tracing.HTTPSpanMiddleware(ah)
+	// Suspended Services aren't scaled up again by their requests, when asked.
+	ah = activatorhandler.NewSuspensionHandler(revisionInformer.Lister(), ah)
+	// Routes in maintenance are answered with the maintenance page.
+	ah = activatorhandler.NewMaintenanceHandler(ah)
+	ah = tracing.HTTPSpanMiddlewareWithSampler(ah, activatorhandler.NewTracingSampler(revisionInformer.Lister()))
 	ah = configStore.HTTPMiddleware(ah)
 	reqLogHandler, err := pkghttp.NewRequestLogHandler(ah, logging.NewSyncFileWriter(os.Stdout), "",
 		requestLogTemplateInputGetter(revisionInformer.Lister()))
@@ -245,34 +296,83 @@ func main() {
 		logger.Fatalw("Unable to create request log handler", zap.Error(err))
 	}
 	ah = reqLogHandler
+	// The requests their revision fails to serve are retried against the
+	// fallback revision of their Route.
+	ah = activatorhandler.NewFallbackHandler(routeInformer.Lister(), ah)
+	// Routes splitting their traffic by cookie or header pick the revision here.
+	ah = activatorhandler.NewStickySplitHandler(routeInformer.Lister(), ah)
 	ah = &activatorhandler.ProbeHandler{NextHandler: ah}
 	ah = &activatorhandler.HealthHandler{HealthCheck: statSink.Status, NextHandler: ah}
 
+	// The revisions serving raw TCP streams are proxied on ports of their own.
+	tcpProxy := activatorhandler.NewTCPProxy(logger, throttler, reqChan, serviceInformer.Lister(), sksInformer.Lister())
+	tcpProxy.WatchServerlessServices(sksInformer)
+	defer tcpProxy.Close()
+
 	// Watch the logging config map and dynamically update logging levels.
-	configMapWatcher.Watch(pkglogging.ConfigMapName(), pkglogging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+	configMapWatcher.Watch(pkglogging.ConfigMapName(), levelOverrides.UpdateFromConfigMap(logger))
 	// Watch the observability config map and dynamically update metrics exporter.
 	configMapWatcher.Watch(metrics.ConfigMapName(), metrics.UpdateExporterFromConfigMap(component, logger))
 	// Watch the observability config map and dynamically update request logs.
 	configMapWatcher.Watch(metrics.ConfigMapName(), updateRequestLogFromConfigMap(logger, reqLogHandler))
+	// Watch the network config map and dynamically update the client IP handling.
+	configMapWatcher.Watch(network.ConfigName, updateNetworkConfigFromConfigMap(logger, netConfig))
 	if err = configMapWatcher.Start(stopCh); err != nil {
 		logger.Fatalw("Failed to start configuration manager", zap.Error(err))
 	}
 
+	// The activator is ready once its informers are synced, its ConfigMaps
+	// loaded and it reports to the autoscaler.
+	healthHandler := health.NewHandler()
+	healthHandler.AddCheck("informers", health.InformersSynced(revisionInformer.Informer(), routeInformer.Informer(),
+		endpointInformer.Informer(), serviceInformer.Informer(), sksInformer.Informer(), podInformer.Informer()))
+	healthHandler.AddCheck("configmaps", configMapWatcher.Check)
+	healthHandler.AddCheck("autoscaler", statSink.Status)
+
+	// The queue-proxies register their pods on a port of their own, which
+	// the ingress doesn't route to.
+	registrationTokens := activator.NewRegistrationTokens(kubeClient, revisionInformer.Lister())
+	rh := &activatorhandler.RegistrationHandler{
+		Register:    throttler.Register,
+		Token:       registrationTokens.Token,
+		NextHandler: http.NotFoundHandler(),
+	}
+
 	servers := map[string]*http.Server{
 		"http1": network.NewServer(":"+strconv.Itoa(networking.BackendHTTPPort), ah),
 		"h2c":   network.NewServer(":"+strconv.Itoa(networking.BackendHTTP2Port), ah),
 	}
 
-	errCh := make(chan error, len(servers))
+	errCh := make(chan error, len(servers)+2)
 	for name, server := range servers {
 		go func(name string, s *http.Server) {
+			l, err := net.Listen("tcp", s.Addr)
+			if err != nil {
+				errCh <- perrors.Wrapf(err, "%s server failed to listen", name)
+				return
+			}
+			// The ingress may prepend the address of the client to the connection.
+			l = network.NewProxyProtocolListener(l, netConfig.proxyProtocol)
 			// Don't forward ErrServerClosed as that indicates we're already shutting down.
-			if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			if err := s.Serve(l); err != nil && err != http.ErrServerClosed {
 				errCh <- perrors.Wrapf(err, "%s server failed", name)
 			}
 		}(name, server)
 	}
 
+	registrationServer := network.NewServer(":"+strconv.Itoa(activator.RegistrationPort), rh)
+	go func() {
+		if err := registrationServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- perrors.Wrap(err, "registration server failed")
+		}
+	}()
+
+	go func() {
+		if err := health.ListenAndServe(stopCh, healthHandler); err != nil {
+			errCh <- perrors.Wrap(err, "health server failed")
+		}
+	}()
+
 	// Exit as soon as we see a shutdown signal or one of the servers failed.
 	select {
 	case <-stopCh:
@@ -283,6 +383,7 @@ func main() {
 	for _, server := range servers {
 		server.Shutdown(context.Background())
 	}
+	registrationServer.Shutdown(context.Background())
 }
 
 func flush(logger *zap.SugaredLogger) {
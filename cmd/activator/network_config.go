@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/network"
+)
+
+// networkConfig holds the network configuration applied to the connections
+// and requests the activator receives.
+type networkConfig struct {
+	v atomic.Value
+}
+
+func newNetworkConfig() *networkConfig {
+	nc := &networkConfig{}
+	nc.v.Store(&network.Config{
+		ForwardedForPolicy: network.ForwardedForAppend,
+		ForwardedHeaders:   network.ForwardedHeadersTrust,
+	})
+	return nc
+}
+
+func (nc *networkConfig) load() *network.Config {
+	return nc.v.Load().(*network.Config)
+}
+
+func (nc *networkConfig) proxyProtocol() bool {
+	return nc.load().ProxyProtocol
+}
+
+func updateNetworkConfigFromConfigMap(logger *zap.SugaredLogger, nc *networkConfig) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		cfg, err := network.NewConfigFromConfigMap(configMap)
+		if err != nil {
+			logger.Errorw("Failed to parse the network config, keeping the current one.", zap.Error(err))
+			return
+		}
+		nc.v.Store(cfg)
+		logger.Infow("Updated the network config.",
+			"forwardedForPolicy", cfg.ForwardedForPolicy, "forwardedHeaders", cfg.ForwardedHeaders,
+			"trustedHops", cfg.TrustedHops, "proxyProtocol", cfg.ProxyProtocol)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	testing2 "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/network"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestUpdateNetworkConfigFromConfigMap(t *testing.T) {
+	nc := newNetworkConfig()
+	if nc.proxyProtocol() || nc.load().ForwardedForPolicy != network.ForwardedForAppend ||
+		nc.load().ForwardedHeaders != network.ForwardedHeadersTrust {
+		t.Errorf("Default config = %#v", nc.load())
+	}
+
+	update := updateNetworkConfigFromConfigMap(testing2.TestLogger(t), nc)
+	update(&corev1.ConfigMap{
+		Data: map[string]string{
+			network.ForwardedForPolicyKey: "preserve",
+			network.ProxyProtocolKey:      "enabled",
+			network.ForwardedHeadersKey:   "strip",
+		},
+	})
+	if !nc.proxyProtocol() || nc.load().ForwardedForPolicy != network.ForwardedForPreserve ||
+		nc.load().ForwardedHeaders != network.ForwardedHeadersStrip {
+		t.Errorf("Updated config = %#v", nc.load())
+	}
+
+	// An invalid config is ignored.
+	update(&corev1.ConfigMap{
+		Data: map[string]string{
+			network.ForwardedForPolicyKey: "replace",
+		},
+	})
+	if !nc.proxyProtocol() || nc.load().ForwardedForPolicy != network.ForwardedForPreserve {
+		t.Errorf("Config after invalid update = %#v", nc.load())
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// autoscaler-replay replays recorded autoscaler stats through the decider
+// with an arbitrary configuration and prints the resulting scale trajectory.
+//
+// The stats are read as newline delimited JSON encoded StatMessages, e.g.
+//
+//	{"Key":"ns/rev","Stat":{"Time":"2019-08-01T10:00:00Z","PodName":"rev-1","AverageConcurrentRequests":3.5}}
+//
+// Usage:
+//
+//	autoscaler-replay -stats stats.json -config config-autoscaler.yaml -annotations autoscaling.knative.dev/target=10
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/autoscaler"
+	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+)
+
+var (
+	statsFile            = flag.String("stats", "-", "The file to read the recorded stats from, - for stdin.")
+	configFile           = flag.String("config", "", "A config-autoscaler ConfigMap to use. Defaults are used if empty.")
+	key                  = flag.String("key", "", "Only replay the stats of this namespace/revision key.")
+	annotations          = flag.String("annotations", "", "Comma separated key=value autoscaling annotations of the revision.")
+	containerConcurrency = flag.Int("container-concurrency", 0, "The containerConcurrency of the revision.")
+	startupDelay         = flag.Duration("pod-startup-delay", 0, "How long it takes a new pod to become ready.")
+)
+
+func main() {
+	flag.Parse()
+
+	config, err := loadConfig(*configFile)
+	if err != nil {
+		log.Fatalf("Error loading autoscaler config: %v", err)
+	}
+	pa, err := makePA(*annotations, *containerConcurrency)
+	if err != nil {
+		log.Fatalf("Error parsing annotations: %v", err)
+	}
+
+	in := io.Reader(os.Stdin)
+	if *statsFile != "-" {
+		f, err := os.Open(*statsFile)
+		if err != nil {
+			log.Fatalf("Error opening stats: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	msgs, err := readStats(in, *key)
+	if err != nil {
+		log.Fatalf("Error reading stats: %v", err)
+	}
+
+	// The decider logs every decision, which would drown the trajectory.
+	ctx := logging.WithLogger(context.Background(), zap.NewNop().Sugar())
+	decider := kparesources.MakeDecider(ctx, pa, config, "")
+	metric := aresources.MakeMetric(ctx, pa, "", config)
+	steps, err := replay(ctx, msgs, decider, metric, *startupDelay)
+	if err != nil {
+		log.Fatalf("Error replaying stats: %v", err)
+	}
+	printSteps(os.Stdout, steps)
+}
+
+func loadConfig(path string) (*autoscaler.Config, error) {
+	if path == "" {
+		return autoscaler.NewConfigFromMap(nil)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := yaml.Unmarshal(b, cm); err != nil {
+		return nil, err
+	}
+	return autoscaler.NewConfigFromConfigMap(cm)
+}
+
+func makePA(anns string, cc int) (*v1alpha1.PodAutoscaler, error) {
+	pa := &v1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "replay",
+			Name:        "replay",
+			Annotations: map[string]string{},
+		},
+		Spec: v1alpha1.PodAutoscalerSpec{
+			ContainerConcurrency: v1beta1.RevisionContainerConcurrencyType(cc),
+		},
+	}
+	if anns == "" {
+		return pa, nil
+	}
+	for _, kv := range strings.Split(anns, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("annotation %q is not of the form key=value", kv)
+		}
+		pa.Annotations[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	if err := autoscaling.ValidateAnnotations(pa.Annotations); err != nil {
+		return nil, err
+	}
+	return pa, nil
+}
+
+func printSteps(w io.Writer, steps []step) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintln(tw, "TIME\tSTABLE\tPANIC\tREADY\tDESIRED\tEBC")
+	if len(steps) == 0 {
+		return
+	}
+	start := steps[0].Time
+	for _, s := range steps {
+		fmt.Fprintf(tw, "%v\t%.3f\t%.3f\t%d\t%d\t%d\n",
+			s.Time.Sub(start).Round(time.Millisecond), s.Stable, s.Panic, s.Ready, s.Desired, s.EBC)
+	}
+}
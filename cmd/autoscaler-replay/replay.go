@@ -0,0 +1,218 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/autoscaler/aggregation"
+)
+
+// readStats reads newline delimited JSON encoded autoscaler.StatMessages from
+// r and returns them ordered by time.  If key is not empty, only the
+// messages for that key are returned.  Errors number the stats by their
+// position in r, counting the ones of other keys too.
+func readStats(r io.Reader, key string) ([]autoscaler.StatMessage, error) {
+	var msgs []autoscaler.StatMessage
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for n := 1; ; n++ {
+		var msg autoscaler.StatMessage
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode stat #%d: %v", n, err)
+		}
+		if msg.Stat.Time == nil {
+			return nil, fmt.Errorf("stat #%d of %q has no time", n, msg.Key)
+		}
+		if key != "" && msg.Key != key {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	sort.SliceStable(msgs, func(i, j int) bool {
+		return msgs[i].Stat.Time.Before(*msgs[j].Stat.Time)
+	})
+	return msgs, nil
+}
+
+// replayMetrics is an autoscaler.MetricClient that aggregates the stats like
+// the MetricCollector does, but against the simulated clock of the replay.
+type replayMetrics struct {
+	spec        v1alpha1.MetricSpec
+	buckets     *aggregation.TimedFloat64Buckets
+	now         time.Time
+	lastRequest time.Time
+}
+
+var _ autoscaler.MetricClient = (*replayMetrics)(nil)
+
+func newReplayMetrics(spec v1alpha1.MetricSpec) *replayMetrics {
+	return &replayMetrics{
+		spec:    spec,
+		buckets: aggregation.NewTimedFloat64Buckets(autoscaler.BucketSize),
+	}
+}
+
+func (m *replayMetrics) record(stat autoscaler.Stat) {
+	// Proxied requests have been counted at the activator. Subtract
+	// AverageProxiedConcurrentRequests to avoid double counting.
+	m.buckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+	if (stat.RequestCount > 0 || stat.AverageConcurrentRequests > 0) && stat.Time.After(m.lastRequest) {
+		m.lastRequest = *stat.Time
+	}
+}
+
+// StableAndPanicConcurrency implements autoscaler.MetricClient.
+func (m *replayMetrics) StableAndPanicConcurrency(string) (float64, float64, error) {
+	m.buckets.RemoveOlderThan(m.now.Add(-m.spec.StableWindow))
+	if m.buckets.IsEmpty() {
+		return 0, 0, autoscaler.ErrNoData
+	}
+
+	panicAverage := aggregation.Average{}
+	stableAverage := aggregation.Average{}
+	m.buckets.ForEachBucket(
+		aggregation.YoungerThan(m.now.Add(-m.spec.PanicWindow), panicAverage.Accumulate),
+		stableAverage.Accumulate,
+	)
+	return stableAverage.Value(), panicAverage.Value(), nil
+}
+
+// LastRequest implements autoscaler.MetricClient.
+func (m *replayMetrics) LastRequest(string) (time.Time, error) {
+	return m.lastRequest, nil
+}
+
+// replayPods is a resources.ReadyPodCounter that simulates the pods of the
+// revision following the decisions of the autoscaler.  Scaling up takes
+// startupDelay, scaling down is immediate.
+type replayPods struct {
+	startupDelay time.Duration
+	ready        int
+	pending      []pendingScale
+}
+
+type pendingScale struct {
+	at    time.Time
+	count int
+}
+
+// ReadyCount implements resources.ReadyPodCounter.
+func (p *replayPods) ReadyCount() (int, error) {
+	return p.ready, nil
+}
+
+// advance makes the pods that finished starting by now ready.
+func (p *replayPods) advance(now time.Time) {
+	for len(p.pending) > 0 && !p.pending[0].at.After(now) {
+		if p.pending[0].count > p.ready {
+			p.ready = p.pending[0].count
+		}
+		p.pending = p.pending[1:]
+	}
+}
+
+// scale applies a scale decision made at now.
+func (p *replayPods) scale(now time.Time, desired int) {
+	if desired <= p.ready {
+		p.ready = desired
+		p.pending = nil
+		return
+	}
+	p.pending = append(p.pending, pendingScale{at: now.Add(p.startupDelay), count: desired})
+	p.advance(now)
+}
+
+// step is one point of the scale trajectory.
+type step struct {
+	Time    time.Time
+	Stable  float64
+	Panic   float64
+	Ready   int
+	Desired int32
+	EBC     int32
+}
+
+// nopReporter is an autoscaler.StatsReporter that discards everything.
+type nopReporter struct{}
+
+func (nopReporter) ReportDesiredPodCount(int64) error            { return nil }
+func (nopReporter) ReportRequestedPodCount(int64) error          { return nil }
+func (nopReporter) ReportActualPodCount(int64) error             { return nil }
+func (nopReporter) ReportStableRequestConcurrency(float64) error { return nil }
+func (nopReporter) ReportPanicRequestConcurrency(float64) error  { return nil }
+func (nopReporter) ReportTargetRequestConcurrency(float64) error { return nil }
+func (nopReporter) ReportExcessBurstCapacity(float64) error      { return nil }
+func (nopReporter) ReportPanic(int64) error                      { return nil }
+func (nopReporter) ReportTimeSinceLastRequest(float64) error     { return nil }
+func (nopReporter) ReportTimeSinceScaleFromZero(float64) error   { return nil }
+
+// replay feeds msgs through an autoscaler configured with decider and metric,
+// ticking the simulated clock every decider.Spec.TickInterval, and returns the
+// resulting scale trajectory.
+func replay(ctx context.Context, msgs []autoscaler.StatMessage, decider *autoscaler.Decider,
+	metric *v1alpha1.Metric, startupDelay time.Duration) ([]step, error) {
+	if len(msgs) == 0 {
+		return nil, errors.New("no stats to replay")
+	}
+	if decider.Spec.TickInterval <= 0 {
+		return nil, fmt.Errorf("tick interval must be positive, was %v", decider.Spec.TickInterval)
+	}
+
+	metrics := newReplayMetrics(metric.Spec)
+	pods := &replayPods{startupDelay: startupDelay}
+	scaler, err := autoscaler.New(decider.Namespace, decider.Name, metrics, pods, decider.Spec, nopReporter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []step
+	start, end := *msgs[0].Stat.Time, *msgs[len(msgs)-1].Stat.Time
+	for now := start; !now.After(end.Add(decider.Spec.TickInterval)); now = now.Add(decider.Spec.TickInterval) {
+		for len(msgs) > 0 && !msgs[0].Stat.Time.After(now) {
+			metrics.record(msgs[0].Stat)
+			msgs = msgs[1:]
+		}
+		metrics.now = now
+		pods.advance(now)
+
+		desired, ebc, ok := scaler.Scale(ctx, now)
+		if !ok {
+			continue
+		}
+		stable, panicC, _ := metrics.StableAndPanicConcurrency("")
+		steps = append(steps, step{
+			Time:    now,
+			Stable:  stable,
+			Panic:   panicC,
+			Ready:   pods.ready,
+			Desired: desired,
+			EBC:     ebc,
+		})
+		pods.scale(now, int(desired))
+	}
+	return steps, nil
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/autoscaler"
+	kparesources "knative.dev/serving/pkg/reconciler/autoscaling/kpa/resources"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+)
+
+// recordStats returns JSON encoded stats for pods reporting the given
+// concurrency once a second for d.
+func recordStats(t *testing.T, start time.Time, d time.Duration, pods int, concurrency float64) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for ts := start; ts.Before(start.Add(d)); ts = ts.Add(time.Second) {
+		for p := 0; p < pods; p++ {
+			ts := ts
+			if err := enc.Encode(autoscaler.StatMessage{
+				Key: "ns/rev",
+				Stat: autoscaler.Stat{
+					Time:                      &ts,
+					PodName:                   fmt.Sprintf("pod-%d", p),
+					AverageConcurrentRequests: concurrency,
+				},
+			}); err != nil {
+				t.Fatalf("Encode() = %v", err)
+			}
+		}
+	}
+	return buf.String()
+}
+
+func TestReadStats(t *testing.T) {
+	start := time.Now()
+	in := recordStats(t, start, 3*time.Second, 1, 1) +
+		`{"Key":"other/rev","Stat":{"Time":"2019-08-01T10:00:00Z"}}` + "\n"
+
+	msgs, err := readStats(strings.NewReader(in), "")
+	if err != nil {
+		t.Fatalf("readStats() = %v", err)
+	}
+	if got, want := len(msgs), 4; got != want {
+		t.Errorf("len(msgs) = %d, want %d", got, want)
+	}
+	if got, want := msgs[0].Key, "other/rev"; got != want {
+		t.Errorf("First message key = %q, want %q (sorted by time)", got, want)
+	}
+
+	msgs, err = readStats(strings.NewReader(in), "ns/rev")
+	if err != nil {
+		t.Fatalf("readStats() = %v", err)
+	}
+	if got, want := len(msgs), 3; got != want {
+		t.Errorf("len(msgs) = %d, want %d", got, want)
+	}
+
+	for _, test := range []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "stat without time",
+		in:   in + `{"Key":"ns/rev"}`,
+		want: `stat #5 of "ns/rev" has no time`,
+	}, {
+		name: "stat without time of another key",
+		in:   `{"Key":"other/rev"}`,
+		want: `stat #1 of "other/rev" has no time`,
+	}, {
+		name: "malformed stat",
+		in:   in + `{"Key":`,
+		want: "failed to decode stat #5: unexpected EOF",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := readStats(strings.NewReader(test.in), "ns/rev")
+			if err == nil || err.Error() != test.want {
+				t.Errorf("readStats() = %v, want %s", err, test.want)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	config, err := loadConfig("")
+	if err != nil {
+		t.Fatalf("loadConfig() = %v", err)
+	}
+	pa, err := makePA(autoscaling.TargetAnnotationKey+"=10", 0)
+	if err != nil {
+		t.Fatalf("makePA() = %v", err)
+	}
+
+	// Two pods with 50 concurrent requests each need 15 pods at target 10,
+	// given the default target utilization of 70%.
+	start := time.Now()
+	msgs, err := readStats(strings.NewReader(recordStats(t, start, 10*time.Second, 2, 50)), "")
+	if err != nil {
+		t.Fatalf("readStats() = %v", err)
+	}
+
+	ctx := context.Background()
+	steps, err := replay(ctx, msgs, kparesources.MakeDecider(ctx, pa, config, ""),
+		aresources.MakeMetric(ctx, pa, "", config), 0)
+	if err != nil {
+		t.Fatalf("replay() = %v", err)
+	}
+	if len(steps) == 0 {
+		t.Fatal("replay() returned no steps")
+	}
+	last := steps[len(steps)-1]
+	if got, want := last.Desired, int32(15); got != want {
+		t.Errorf("Desired = %d, want %d", got, want)
+	}
+	if got, want := last.Ready, 15; got != want {
+		t.Errorf("Ready = %d, want %d", got, want)
+	}
+
+	var out bytes.Buffer
+	printSteps(&out, steps)
+	if got, want := strings.Count(out.String(), "\n"), len(steps)+1; got != want {
+		t.Errorf("printSteps() printed %d lines, want %d", got, want)
+	}
+}
+
+func TestReplayPodStartupDelay(t *testing.T) {
+	pods := &replayPods{startupDelay: 10 * time.Second}
+	now := time.Now()
+
+	pods.scale(now, 3)
+	if got, _ := pods.ReadyCount(); got != 0 {
+		t.Errorf("ReadyCount() = %d before startup, want 0", got)
+	}
+	pods.advance(now.Add(10 * time.Second))
+	if got, _ := pods.ReadyCount(); got != 3 {
+		t.Errorf("ReadyCount() = %d after startup, want 3", got)
+	}
+	pods.scale(now.Add(11*time.Second), 1)
+	if got, _ := pods.ReadyCount(); got != 1 {
+		t.Errorf("ReadyCount() = %d after scale down, want 1", got)
+	}
+}
+
+func TestMakePAInvalidAnnotation(t *testing.T) {
+	if _, err := makePA("no-value", 0); err == nil {
+		t.Error("makePA() = nil, want error for malformed annotation")
+	}
+	if _, err := makePA(autoscaling.TargetAnnotationKey+"=-1", 0); err == nil {
+		t.Error("makePA() = nil, want error for invalid annotation value")
+	}
+}
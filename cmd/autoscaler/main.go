@@ -39,7 +39,9 @@ import (
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
 	"knative.dev/serving/pkg/autoscaler/statserver"
+	"knative.dev/serving/pkg/health"
 	"knative.dev/serving/pkg/reconciler/autoscaling/hpa"
+	"knative.dev/serving/pkg/reconciler/autoscaling/keda"
 	"knative.dev/serving/pkg/reconciler/autoscaling/kpa"
 	"knative.dev/serving/pkg/resources"
 
@@ -104,7 +106,7 @@ func main() {
 	statsCh := make(chan *autoscaler.StatMessage, statsBufferLen)
 	defer close(statsCh)
 
-	cmw := configmap.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace())
+	cmw := health.NewConfigMapWatcher(configmap.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace()))
 	// Watch the logging config map and dynamically update logging levels.
 	cmw.Watch(logging.ConfigMapName(), logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
 	// Watch the observability config map and dynamically update metrics exporter.
@@ -118,16 +120,25 @@ func main() {
 	// Set up scalers.
 	// uniScalerFactory depends endpointsInformer to be set.
 	multiScaler := autoscaler.NewMultiScaler(ctx.Done(), uniScalerFactoryFunc(endpointsInformer, collector), logger)
+	// The KEDA-class revisions are woken by the demand the activator reports.
+	kedaDemand := keda.NewDemand()
 
 	psInformerFactory := resources.NewPodScalableInformerFactory(ctx)
 	controllers := []*controller.Impl{
 		kpa.NewController(ctx, cmw, multiScaler, collector, psInformerFactory),
 		hpa.NewController(ctx, cmw, collector, psInformerFactory),
+		keda.NewController(ctx, cmw, kedaDemand, psInformerFactory),
 	}
 
 	// Set up a statserver.
 	statsServer := statserver.New(statsServerAddr, statsCh, logger)
 
+	// The autoscaler is ready once its informers are synced and its
+	// ConfigMaps loaded.
+	healthHandler := health.NewHandler()
+	healthHandler.AddCheck("informers", health.InformersSynced(informers...))
+	healthHandler.AddCheck("configmaps", cmw.Check)
+
 	// Start watching the configs.
 	if err := cmw.Start(ctx.Done()); err != nil {
 		logger.Fatalw("Failed to start watching configs", zap.Error(err))
@@ -144,6 +155,7 @@ func main() {
 		for sm := range statsCh {
 			collector.Record(sm.Key, sm.Stat)
 			multiScaler.Poke(sm.Key, sm.Stat)
+			kedaDemand.Poke(sm.Key, sm.Stat)
 		}
 	}()
 
@@ -152,6 +164,9 @@ func main() {
 		return customMetricsAdapter.Run(ctx.Done())
 	})
 	eg.Go(statsServer.ListenAndServe)
+	eg.Go(func() error {
+		return health.ListenAndServe(ctx.Done(), healthHandler)
+	})
 
 	// This will block until either a signal arrives or one of the grouped functions
 	// returns an error.
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -152,3 +153,7 @@ type testMetricClient struct{}
 func (t *testMetricClient) StableAndPanicConcurrency(key string) (float64, float64, error) {
 	return 1.0, 1.0, nil
 }
+
+func (t *testMetricClient) LastRequest(key string) (time.Time, error) {
+	return time.Time{}, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// config-validator checks the ConfigMaps of Knative Serving with the parsers
+// of its components, and against each other, e.g. in the pre-merge checks of
+// a GitOps repository:
+//
+//	config-validator -f config/ -f overlays/prod/config-autoscaler.yaml
+//
+// Without -f, it checks the ConfigMaps in the cluster. It exits non-zero
+// when it finds errors, or warnings too with -strict.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"knative.dev/serving/pkg/configvalidator"
+)
+
+// files collects the repeated -f flags.
+type files []string
+
+func (f *files) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *files) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+var (
+	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace  = flag.String("namespace", "knative-serving", "The namespace of the ConfigMaps in the cluster.")
+	strict     = flag.Bool("strict", false, "Fail on warnings too.")
+	paths      files
+)
+
+func main() {
+	flag.Var(&paths, "f", "A file or directory of manifests to read the ConfigMaps from. May be repeated.")
+	flag.Parse()
+
+	cms, err := read()
+	if err != nil {
+		log.Fatal("Error reading the ConfigMaps: ", err)
+	}
+	if len(cms) == 0 {
+		log.Fatal("No ConfigMaps of Knative Serving found")
+	}
+
+	findings := configvalidator.Validate(cms)
+	for _, f := range findings {
+		fmt.Println(f)
+	}
+	if configvalidator.Failed(findings, *strict) {
+		os.Exit(1)
+	}
+	fmt.Printf("%d ConfigMaps checked, %d findings\n", len(cms), len(findings))
+}
+
+// read reads the ConfigMaps out of the -f paths, or else out of the cluster.
+func read() ([]*corev1.ConfigMap, error) {
+	if len(paths) > 0 {
+		return configvalidator.ReadFiles(paths)
+	}
+	cfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error building kubeconfig: %v", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building kube clientset: %v", err)
+	}
+	return configvalidator.ReadCluster(kubeClient, *namespace)
+}
@@ -24,9 +24,10 @@ import (
 	"knative.dev/serving/pkg/reconciler/route"
 	"knative.dev/serving/pkg/reconciler/serverlessservice"
 	"knative.dev/serving/pkg/reconciler/service"
+	"knative.dev/serving/pkg/reconciler/suspension"
 
 	// This defines the shared main for injected controllers.
-	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/serving/pkg/sharedmain"
 )
 
 func main() {
@@ -37,5 +38,6 @@ func main() {
 		route.NewController,
 		serverlessservice.NewController,
 		service.NewController,
+		suspension.NewController,
 	)
 }
@@ -163,12 +163,15 @@ func main() {
 		return
 	}
 
-	// Use the IP (assumes IPv4) to set up a magic DNS name under a top-level Magic
+	// Use the IP to set up a magic DNS name under a top-level Magic
 	// DNS service like xip.io or nip.io, where:
 	//     1.2.3.4.xip.io  ===(magically resolves to)===> 1.2.3.4
+	// IPv6 addresses can't be embedded as dotted labels, so services like
+	// sslip.io expect the colons to be replaced with dashes:
+	//     2001-db8--1.sslip.io  ===(magically resolves to)===> 2001:db8::1
 	// Add this magic DNS name without a label selector to the ConfigMap,
 	// and send it back to the API server.
-	domain := fmt.Sprintf("%s.%s", address.IP, *magicDNS)
+	domain := fmt.Sprintf("%s.%s", strings.Replace(address.IP, ":", "-", -1), *magicDNS)
 	domainCM.Data[domain] = ""
 	if _, err = kubeClient.CoreV1().ConfigMaps(system.Namespace()).Update(domainCM); err != nil {
 		logger.Fatalw("Error updating ConfigMap", zap.Error(err))
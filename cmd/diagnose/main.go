@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// diagnose reports the state of a Knative Service, and of the resources
+// serving it, for triage:
+//
+//	diagnose -namespace default -service hello
+//
+// -output json writes the whole resources too, as a bundle to attach to
+// bug reports.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+	"knative.dev/serving/pkg/diagnose"
+)
+
+var (
+	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace  = flag.String("namespace", "default", "The namespace of the Service.")
+	service    = flag.String("service", "", "The name of the Service to diagnose.")
+	output     = flag.String("output", "text", "The format of the report, text or json.")
+)
+
+func main() {
+	flag.Parse()
+	if *service == "" {
+		log.Fatal("-service is required")
+	}
+	if *output != "text" && *output != "json" {
+		log.Fatalf("-output must be text or json, was %q", *output)
+	}
+
+	cfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
+	if err != nil {
+		log.Fatal("Error building kubeconfig: ", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		log.Fatal("Error building kube clientset: ", err)
+	}
+	servingClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		log.Fatal("Error building serving clientset: ", err)
+	}
+
+	report, err := diagnose.Collect(diagnose.Clients{
+		Kube:        kubeClient,
+		Serving:     servingClient,
+		Concurrency: diagnose.CustomMetricsConcurrency(kubeClient.Discovery().RESTClient()),
+	}, *namespace, *service)
+	if err != nil {
+		log.Fatalf("Error getting Service %s/%s: %v", *namespace, *service, err)
+	}
+
+	if *output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.Print(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal("Error writing the report: ", err)
+	}
+}
@@ -20,7 +20,7 @@ import (
 	"knative.dev/serving/pkg/reconciler/certificate"
 
 	// This defines the shared main for injected controllers.
-	"knative.dev/pkg/injection/sharedmain"
+	"knative.dev/serving/pkg/sharedmain"
 )
 
 func main() {
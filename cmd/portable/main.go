@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// portable exports a Knative Service to a manifest, and imports it on
+// another cluster, e.g. to migrate it:
+//
+//	portable export -namespace default -service hello > hello.json
+//	portable import -namespace default -f hello.json \
+//	    -domain example.com=example.org \
+//	    -class istio.ingress.networking.knative.dev=contour.ingress.networking.knative.dev
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+	"knative.dev/serving/pkg/portable"
+)
+
+// mapping is a flag collecting from=to pairs.
+type mapping map[string]string
+
+func (m mapping) String() string {
+	pairs := make([]string, 0, len(m))
+	for from, to := range m {
+		pairs = append(pairs, from+"="+to)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mapping) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("%q is not of the form from=to", value)
+	}
+	m[parts[0]] = parts[1]
+	return nil
+}
+
+var (
+	masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+	kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	namespace  = flag.String("namespace", "default", "The namespace of the Service.")
+	service    = flag.String("service", "", "The name of the Service to export.")
+	file       = flag.String("f", "-", "The manifest to import, - for the standard input.")
+	timeout    = flag.Duration("timeout", 5*time.Minute, "How long each pinned Revision has to be stamped out on import.")
+	domains    = mapping{}
+	classes    = mapping{}
+)
+
+func main() {
+	flag.Var(domains, "domain", "A domain of the source cluster and the one of the target cluster, as from=to. Repeatable.")
+	flag.Var(classes, "class", "A class of the source cluster and the one of the target cluster, as from=to. Repeatable.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s export|import [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	flag.CommandLine.Parse(os.Args[2:])
+
+	cfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
+	if err != nil {
+		log.Fatal("Error building kubeconfig: ", err)
+	}
+	servingClient, err := clientset.NewForConfig(cfg)
+	if err != nil {
+		log.Fatal("Error building serving clientset: ", err)
+	}
+
+	switch command {
+	case "export":
+		if *service == "" {
+			log.Fatal("-service is required")
+		}
+		m, err := portable.Export(servingClient, *namespace, *service)
+		if err != nil {
+			log.Fatalf("Error exporting Service %s/%s: %v", *namespace, *service, err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			log.Fatal("Error writing the manifest: ", err)
+		}
+
+	case "import":
+		in := os.Stdin
+		if *file != "-" {
+			if in, err = os.Open(*file); err != nil {
+				log.Fatal("Error opening the manifest: ", err)
+			}
+			defer in.Close()
+		}
+		m := &portable.Manifest{}
+		if err := json.NewDecoder(in).Decode(m); err != nil {
+			log.Fatal("Error reading the manifest: ", err)
+		}
+		importer := &portable.Importer{
+			Client:       servingClient,
+			PollInterval: time.Second,
+			PollTimeout:  *timeout,
+		}
+		s, err := importer.Import(m, *namespace, portable.Rewrites{
+			Domains: domains,
+			Classes: classes,
+		})
+		if err != nil {
+			log.Fatal("Error importing the manifest: ", err)
+		}
+		log.Printf("Imported Service %s/%s", s.Namespace, s.Name)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
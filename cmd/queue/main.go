@@ -20,6 +20,9 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -30,9 +33,11 @@ import (
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	zipkin "github.com/openzipkin/zipkin-go"
 	"github.com/pkg/errors"
 	"go.opencensus.io/stats"
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/logging/logkey"
 	"knative.dev/pkg/metrics"
@@ -40,14 +45,17 @@ import (
 	"knative.dev/serving/pkg/activator"
 	activatorutil "knative.dev/serving/pkg/activator/util"
 	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
 	pkghttp "knative.dev/serving/pkg/http"
 	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/queue"
 	"knative.dev/serving/pkg/queue/health"
+	"knative.dev/serving/pkg/queue/openapi"
 	"knative.dev/serving/pkg/queue/readiness"
 	queuestats "knative.dev/serving/pkg/queue/stats"
+	"knative.dev/serving/pkg/tracing"
 )
 
 const (
@@ -65,20 +73,28 @@ const (
 	// in the mesh.
 	quitSleepDuration = 20 * time.Second
 
-	badProbeTemplate = "unexpected probe header value: %s"
+	badProbeTemplate  = "unexpected probe header value: %s"
+	badProbeSignature = "invalid probe signature"
 
 	// Metrics' names (without component prefix).
 	requestCountN          = "request_count"
 	responseTimeInMsecN    = "request_latencies"
 	appRequestCountN       = "app_request_count"
 	appResponseTimeInMsecN = "app_request_latencies"
+	rateLimitedCountN      = "rate_limited_request_count"
+	compressionSavedBytesN = "compression_saved_bytes"
+	sloRequestCountN       = "slo_request_count"
+	sloObjectiveN          = "slo_objective"
 
 	// requestQueueHealthPath specifies the path for health checks for
 	// queue-proxy.
 	requestQueueHealthPath = "/health"
 
-	healthURLTemplate = "http://127.0.0.1:%d" + requestQueueHealthPath
-	tcpProbeTimeout   = 100 * time.Millisecond
+	// ipv4Loopback is the host the controller sets on the readiness probe
+	// of the user container.
+	ipv4Loopback = "127.0.0.1"
+
+	tcpProbeTimeout = 100 * time.Millisecond
 	// The 25 millisecond retry interval is an unscientific compromise between wanting to get
 	// started as early as possible while still wanting to give the container some breathing
 	// room to get up and running.
@@ -114,33 +130,66 @@ var (
 		appResponseTimeInMsecN,
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	rateLimitedCountM = stats.Int64(
+		rateLimitedCountN,
+		"The number of requests rejected by the rate limit",
+		stats.UnitDimensionless)
+	compressionSavedBytesM = stats.Int64(
+		compressionSavedBytesN,
+		"The number of bytes saved by compressing responses",
+		stats.UnitBytes)
+	sloRequestCountM = stats.Int64(
+		sloRequestCountN,
+		"The number of requests measured against the SLO",
+		stats.UnitDimensionless)
+	sloObjectiveM = stats.Float64(
+		sloObjectiveN,
+		"The ratio of the requests that must meet the SLO",
+		stats.UnitDimensionless)
 	readinessProbeTimeout = flag.Int("probe-period", -1, "run readiness probe with given timeout")
 )
 
 type config struct {
-	ContainerConcurrency         int    `split_words:"true" required:"true"`
-	QueueServingPort             int    `split_words:"true" required:"true"`
-	RevisionTimeoutSeconds       int    `split_words:"true" required:"true"`
-	UserPort                     int    `split_words:"true" required:"true"`
-	EnableVarLogCollection       bool   `split_words:"true"` // optional
-	ServingConfiguration         string `split_words:"true" required:"true"`
-	ServingNamespace             string `split_words:"true" required:"true"`
-	ServingPodIP                 string `split_words:"true" required:"true"`
-	ServingPod                   string `split_words:"true" required:"true"`
-	ServingRevision              string `split_words:"true" required:"true"`
-	ServingService               string `split_words:"true"` // optional
-	UserContainerName            string `split_words:"true" required:"true"`
-	VarLogVolumeName             string `split_words:"true" required:"true"`
-	InternalVolumePath           string `split_words:"true" required:"true"`
-	ServingLoggingConfig         string `split_words:"true" required:"true"`
-	ServingLoggingLevel          string `split_words:"true" required:"true"`
-	ServingRequestMetricsBackend string `split_words:"true" required:"true"`
-	ServingRequestLogTemplate    string `split_words:"true" required:"true"`
-	ServingReadinessProbe        string `split_words:"true" required:"true"`
+	ContainerConcurrency         int           `split_words:"true" required:"true"`
+	QueueServingPort             int           `split_words:"true" required:"true"`
+	RevisionTimeoutSeconds       int           `split_words:"true" required:"true"`
+	UserPort                     int           `split_words:"true" required:"true"`
+	EnableVarLogCollection       bool          `split_words:"true"` // optional
+	ServingConfiguration         string        `split_words:"true" required:"true"`
+	ServingNamespace             string        `split_words:"true" required:"true"`
+	ServingPodIP                 string        `split_words:"true" required:"true"`
+	ServingPod                   string        `split_words:"true" required:"true"`
+	ServingRevision              string        `split_words:"true" required:"true"`
+	ServingRevisionUID           string        `split_words:"true" required:"true"`
+	ServingService               string        `split_words:"true"` // optional
+	UserContainerName            string        `split_words:"true" required:"true"`
+	VarLogVolumeName             string        `split_words:"true" required:"true"`
+	InternalVolumePath           string        `split_words:"true" required:"true"`
+	ServingLoggingConfig         string        `split_words:"true" required:"true"`
+	ServingLoggingLevel          string        `split_words:"true" required:"true"`
+	ServingRequestMetricsBackend string        `split_words:"true" required:"true"`
+	ServingRequestLogTemplate    string        `split_words:"true" required:"true"`
+	ServingReadinessProbe        string        `split_words:"true" required:"true"`
+	ServingDependencies          string        `split_words:"true"` // optional
+	ActivatorRegistrationHost    string        `split_words:"true"` // optional
+	UserSocket                   string        `split_words:"true"` // optional
+	ForwardedForPolicy           string        `split_words:"true"` // optional
+	ForwardedHeaders             string        `split_words:"true"` // optional
+	TrustedHops                  int           `split_words:"true"` // optional
+	RateLimit                    float64       `split_words:"true"` // optional
+	RateLimitBurst               int           `split_words:"true"` // optional
+	Compression                  string        `split_words:"true"` // optional
+	OpenapiSchema                string        `split_words:"true"` // optional
+	DebugToken                   string        `split_words:"true"` // optional
+	ServingProtocol              string        `split_words:"true"` // optional
+	ServingDataPlaneConfig       bool          `split_words:"true"` // optional
+	SLOAvailability              float64       `split_words:"true"` // optional
+	SLOLatency                   time.Duration `split_words:"true"` // optional
+	SLOLatencyTarget             float64       `split_words:"true"` // optional
 }
 
 func initConfig(env config) {
-	userTargetAddress = "127.0.0.1:" + strconv.Itoa(env.UserPort)
+	userTargetAddress = net.JoinHostPort(network.LoopbackAddress(env.ServingPodIP), strconv.Itoa(env.UserPort))
 	if env.VarLogVolumeName == "" && env.EnableVarLogCollection {
 		logger.Fatal("VAR_LOG_VOLUME_NAME must be specified when ENABLE_VAR_LOG_COLLECTION is true")
 	}
@@ -173,8 +222,20 @@ func knativeProxyHeader(r *http.Request) string {
 	return r.Header.Get(network.ProxyHeaderName)
 }
 
+// sanitizeForwardedHeadersHandler sanitizes the forwarded headers of all
+// the requests, whether they come straight from the ingress or through the
+// activator: the header telling the activator proxied a request could come
+// from the client too.
+func sanitizeForwardedHeadersHandler(cfg *network.Config, h http.Handler) http.Handler {
+	return network.NewForwardedHeadersHandler(func() *network.Config {
+		return cfg
+	}, h)
+}
+
 // Make handler a closure for testing.
-func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.Handler, prober func() bool) func(http.ResponseWriter, *http.Request) {
+// The probes must be signed with probeKey, so that only the components of
+// Knative can send them.
+func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.Handler, prober func() bool, probeKey []byte) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ph := knativeProbeHeader(r)
 		switch {
@@ -183,9 +244,14 @@ func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.H
 				http.Error(w, fmt.Sprintf(badProbeTemplate, ph), http.StatusBadRequest)
 				return
 			}
+			if !network.ValidProbeRequest(r, probeKey, queue.Name) {
+				http.Error(w, badProbeSignature, http.StatusForbidden)
+				return
+			}
 			if prober != nil {
 				if prober() {
 					// Respond with the name of the component handling the request.
+					network.SignProbeResponse(w.Header(), r, probeKey, queue.Name)
 					w.Write([]byte(queue.Name))
 				} else {
 					http.Error(w, "container not ready", http.StatusServiceUnavailable)
@@ -225,17 +291,38 @@ func handler(reqChan chan queue.ReqEvent, breaker *queue.Breaker, handler http.H
 	}
 }
 
-// Sets up /health and /wait-for-drain endpoints.
-func createAdminHandlers(p *readiness.Probe) *http.ServeMux {
+// Sets up /health and /wait-for-drain endpoints, and the /debug/ ones when
+// a debug token is given.
+func createAdminHandlers(p *readiness.Probe, debugToken string) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc(requestQueueHealthPath, healthState.HealthHandler(p.ProbeContainer, p.IsAggressive()))
 	mux.HandleFunc(queue.RequestQueueDrainPath, healthState.DrainHandler())
+	if debugToken != "" {
+		mux.Handle(queue.DebugPath, queue.DebugHandler(debugToken))
+	}
 
 	return mux
 }
+
+// livenessProbeHandler passes the liveness probes of the user container,
+// which the kubelet sends to the admin port, on to probe, and the other
+// requests to h. Unlike on the serving port, those are never rate limited
+// nor validated, and can't be spoofed by the clients of the Revision.
+func livenessProbeHandler(probe, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(network.KubeletProbeHeaderName) != "" {
+			probe.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func probeQueueHealthPath(port int, timeoutSeconds int) error {
-	url := fmt.Sprintf(healthURLTemplate, port)
+	// The exec probe runs in the queue-proxy container, with its environment.
+	host := net.JoinHostPort(network.LoopbackAddress(os.Getenv("SERVING_POD_IP")), strconv.Itoa(port))
+	url := "http://" + host + requestQueueHealthPath
 	timeoutDuration := readiness.PollTimeout
 	if timeoutSeconds != 0 {
 		timeoutDuration = time.Duration(timeoutSeconds) * time.Second
@@ -293,7 +380,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger, _ = logging.NewLogger(env.ServingLoggingConfig, env.ServingLoggingLevel)
+	var atomicLevel zap.AtomicLevel
+	logger, atomicLevel = logging.NewLogger(env.ServingLoggingConfig, env.ServingLoggingLevel)
 	logger = logger.Named("queueproxy")
 	defer flush(logger)
 
@@ -309,6 +397,9 @@ func main() {
 
 	httpProxy = httputil.NewSingleHostReverseProxy(target)
 	httpProxy.Transport = network.AutoTransport
+	if env.UserSocket != "" {
+		httpProxy.Transport = network.NewUnixAutoTransport(env.UserSocket)
+	}
 	httpProxy.FlushInterval = -1
 
 	activatorutil.SetupHeaderPruning(httpProxy)
@@ -346,15 +437,47 @@ func main() {
 		logger.Fatalw("Queue container failed to parse readiness probe", zap.Error(err))
 	}
 
+	localizeProbe(coreProbe, network.LoopbackAddress(env.ServingPodIP))
 	rp := readiness.NewProbe(coreProbe, logger.With(zap.String(logkey.Key, "readinessProbe")))
+	rp.UserSocket = env.UserSocket
+	if env.ServingDependencies != "" {
+		if rp.Dependencies, err = serving.ParseDependencies(env.ServingDependencies); err != nil {
+			logger.Fatalw("Queue container failed to parse the dependencies", zap.Error(err))
+		}
+	}
 
 	adminServer := &http.Server{
 		Addr:    ":" + strconv.Itoa(networking.QueueAdminPort),
-		Handler: createAdminHandlers(rp),
+		Handler: livenessProbeHandler(httpProxy, createAdminHandlers(rp, env.DebugToken)),
+	}
+
+	// The settings of the data-plane configuration are read from the mounted
+	// ConfigMap when enabled, and kept up to date below. It is signed with
+	// the key in the mounted Secret of the revision.
+	dataPlaneKey := func() ([]byte, error) {
+		return ioutil.ReadFile(path.Join(queue.SecretVolumePath, queue.DataPlaneKeyKey))
+	}
+	dataPlaneConfig := queue.DataPlaneConfig{
+		TimeoutSeconds:        int64(env.RevisionTimeoutSeconds),
+		RateLimit:             env.RateLimit,
+		RateLimitBurst:        env.RateLimitBurst,
+		RequestMetricsBackend: env.ServingRequestMetricsBackend,
+	}
+	if env.ServingDataPlaneConfig {
+		key, err := dataPlaneKey()
+		var cfg *queue.DataPlaneConfig
+		if err == nil {
+			cfg, err = queue.ReadDataPlaneConfig(queue.DataPlaneConfigVolumePath, key)
+		}
+		if err != nil {
+			logger.Warnw("Failed to read the data-plane configuration", zap.Error(err))
+		} else {
+			dataPlaneConfig = *cfg
+		}
 	}
 
 	metricsSupported := false
-	if metricsBackend := env.ServingRequestMetricsBackend; metricsBackend != "" {
+	if metricsBackend := dataPlaneConfig.RequestMetricsBackend; metricsBackend != "" {
 		if err := setupMetricsExporter(metricsBackend); err == nil {
 			metricsSupported = true
 			logger.Infof("SERVING_REQUEST_METRICS_BACKEND=%v", metricsBackend)
@@ -367,17 +490,72 @@ func main() {
 
 	// Create queue handler chain
 	// Note: innermost handlers are specified first, ie. the last handler in the chain will be executed first
-	var composedHandler http.Handler = httpProxy
+	networkConfig := &network.Config{
+		ForwardedForPolicy: network.ForwardedForPolicy(env.ForwardedForPolicy),
+		ForwardedHeaders:   network.ForwardedHeadersPolicy(env.ForwardedHeaders),
+		TrustedHops:        env.TrustedHops,
+	}
+	var composedHandler http.Handler = network.NewForwardedForHandler(func() network.ForwardedForPolicy {
+		return networkConfig.ForwardedForPolicy
+	}, httpProxy)
+	if env.Compression != "" {
+		composedHandler = pushCompressionHandler(composedHandler, metricsSupported, env)
+	}
 	if metricsSupported {
-		composedHandler = pushRequestMetricHandler(httpProxy, appRequestCountM, appResponseTimeInMsecM, env)
+		composedHandler = pushRequestMetricHandler(composedHandler, appRequestCountM, appResponseTimeInMsecM, env)
+	}
+	// The probes are signed with the key in the mounted Secret of the
+	// revision, which the pod doesn't start without.
+	probeKey, err := ioutil.ReadFile(path.Join(queue.SecretVolumePath, queue.ProbeKeyKey))
+	if err != nil {
+		logger.Fatalw("Failed to read the probe key", zap.Error(err))
+	}
+	composedHandler = http.HandlerFunc(handler(reqChan, breaker, composedHandler, rp.ProbeContainer, probeKey))
+	if env.OpenapiSchema != "" {
+		// Invalid requests don't count towards the concurrency of the pod.
+		validator, err := openapi.Load(env.OpenapiSchema)
+		if err != nil {
+			logger.Fatalw("Failed to load the OpenAPI schema", zap.Error(err))
+		}
+		composedHandler = openapi.NewHandler(composedHandler, validator, probeKey)
 	}
-	composedHandler = http.HandlerFunc(handler(reqChan, breaker, composedHandler, rp.ProbeContainer))
-	composedHandler = queue.ForwardedShimHandler(composedHandler)
-	composedHandler = queue.TimeToFirstByteTimeoutHandler(composedHandler,
-		time.Duration(env.RevisionTimeoutSeconds)*time.Second, "request timeout")
-	composedHandler = pushRequestLogHandler(composedHandler, env)
+	// The timeout and rate limit may be changed per revision at runtime,
+	// through its data-plane configuration, along with the settings
+	// applyTunables takes care of.
+	applyTunables := func(queue.DataPlaneConfig) {}
+	if env.ServingDataPlaneConfig {
+		var metricsBackend string
+		if metricsSupported {
+			metricsBackend = dataPlaneConfig.RequestMetricsBackend
+		}
+		applyTunables = newTunablesApplier(env, atomicLevel, metricsBackend)
+	}
+	onLimited := rateLimitedReporter(metricsSupported, env)
+	innerHandler := composedHandler
+	dataPlane := queue.NewDataPlaneConfigWatcher(queue.DataPlaneConfigVolumePath, dataPlaneKey, dataPlaneConfig,
+		func(cfg queue.DataPlaneConfig) http.Handler {
+			applyTunables(cfg)
+			h := innerHandler
+			if cfg.RateLimit > 0 {
+				// Rejected requests don't count towards the concurrency of the pod.
+				h = pushRateLimitHandler(h, cfg, probeKey, onLimited)
+			}
+			h = queue.ForwardedShimHandler(h)
+			h = sanitizeForwardedHeadersHandler(networkConfig, h)
+			return queue.TimeToFirstByteTimeoutHandler(h,
+				time.Duration(cfg.TimeoutSeconds)*time.Second, "request timeout")
+		}, logger)
+	composedHandler = pushRequestLogHandler(dataPlane, env)
 	if metricsSupported {
 		composedHandler = pushRequestMetricHandler(composedHandler, requestCountM, responseTimeInMsecM, env)
+		if env.SLOAvailability > 0 || env.SLOLatency > 0 {
+			composedHandler = pushSLOHandler(composedHandler, env)
+		}
+	}
+	if env.ServingDataPlaneConfig {
+		// Sampled according to the tracing settings of the data-plane
+		// configuration.
+		composedHandler = tracing.HTTPSpanMiddleware(composedHandler)
 	}
 	qSP := strconv.Itoa(env.QueueServingPort)
 	logger.Info("Queue-proxy will listen on port ", qSP)
@@ -394,9 +572,44 @@ func main() {
 		}
 	}
 
-	go catchServerError(server.ListenAndServe)
+	// The revisions serving raw TCP streams get their connections proxied
+	// at L4, past all of the HTTP handlers above.
+	var tcpProxy *queue.TCPProxy
+	if networking.ProtocolType(env.ServingProtocol) == networking.ProtocolTCP {
+		tcpProxy = queue.NewTCPProxy(userTargetAddress, reqChan, breaker, logger)
+		go catchServerError(func() error {
+			l, err := net.Listen("tcp", ":"+qSP)
+			if err != nil {
+				return err
+			}
+			return tcpProxy.Serve(l)
+		})
+	} else {
+		go catchServerError(server.ListenAndServe)
+	}
 	go catchServerError(adminServer.ListenAndServe)
 
+	var registrar *queue.Registrar
+	if env.ActivatorRegistrationHost != "" {
+		registrar = newRegistrar(env)
+		registrarStopCh := make(chan struct{})
+		defer close(registrarStopCh)
+		go registrar.Run(queue.RegistrationPeriod, registrarStopCh)
+	}
+
+	if env.ServingDataPlaneConfig {
+		dataPlaneStopCh := make(chan struct{})
+		defer close(dataPlaneStopCh)
+		go dataPlane.Run(queue.DataPlaneConfigPeriod, dataPlaneStopCh)
+	} else {
+		// The log level may be overridden per revision at runtime, through an
+		// annotation on the pod.
+		logLevelStopCh := make(chan struct{})
+		defer close(logLevelStopCh)
+		go queue.NewLogLevelWatcher(path.Join(queue.PodInfoVolumePath, queue.PodAnnotationsFile),
+			atomicLevel, logger).Run(queue.LogLevelPeriod, logLevelStopCh)
+	}
+
 	// Logic that isn't required to be executed before the critical path
 	// and should be started last to not impact start up latency
 	go func() {
@@ -416,9 +629,25 @@ func main() {
 	case <-signals.SetupSignalHandler():
 		logger.Info("Received TERM signal, attempting to gracefully shutdown servers.")
 		healthState.Shutdown(func() {
+			// Tell the activators to stop sending us requests right away.
+			if registrar != nil {
+				registrar.Announce()
+			}
 			// Give Istio time to sync our "not ready" state.
 			time.Sleep(quitSleepDuration)
 
+			if tcpProxy != nil {
+				// The connections may stay open for good, so they only get
+				// as long as the requests of the revision would.
+				ctx, cancel := context.WithTimeout(context.Background(),
+					time.Duration(env.RevisionTimeoutSeconds)*time.Second)
+				defer cancel()
+				if err := tcpProxy.Shutdown(ctx); err != nil {
+					logger.Errorw("Failed to shutdown TCP proxy", zap.Error(err))
+				}
+				return
+			}
+
 			// Calling server.Shutdown() allows pending requests to
 			// complete, while no new work is accepted.
 			if err := server.Shutdown(context.Background()); err != nil {
@@ -433,6 +662,43 @@ func main() {
 	}
 }
 
+// localizeProbe points the readiness probe of the user container, which the
+// controller sets up against the IPv4 loopback, at the loopback of the IP
+// family of the pod.
+func localizeProbe(p *corev1.Probe, loopback string) {
+	switch {
+	case p == nil:
+	case p.HTTPGet != nil && p.HTTPGet.Host == ipv4Loopback:
+		p.HTTPGet.Host = loopback
+	case p.TCPSocket != nil && p.TCPSocket.Host == ipv4Loopback:
+		p.TCPSocket.Host = loopback
+	}
+}
+
+// newRegistrar creates a Registrar announcing the state of the pod to the
+// activators behind env.ActivatorRegistrationHost, signed with the token of
+// the Secret of the revision. The Secret may be mounted after the pod
+// started, so the token is read for every announcement.
+func newRegistrar(env config) *queue.Registrar {
+	return queue.NewRegistrar(env.ActivatorRegistrationHost, activator.RegistrationPort, func() queue.Registration {
+		capacity := -1
+		if breaker != nil {
+			capacity = breaker.Available()
+		}
+		return queue.Registration{
+			Namespace: env.ServingNamespace,
+			Revision:  env.ServingRevision,
+			Pod:       env.ServingPod,
+			PodIP:     env.ServingPodIP,
+			Ready:     healthState.IsAlive(),
+			Draining:  healthState.IsShuttingDown(),
+			Capacity:  capacity,
+		}
+	}, func() ([]byte, error) {
+		return ioutil.ReadFile(path.Join(queue.SecretVolumePath, queue.RegistrationTokenKey))
+	}, logger.Named("registrar"))
+}
+
 // createVarLogLink creates a symlink allowing the fluentd daemon set to capture the
 // logs from the user container /var/log. See fluentd config for more details.
 func createVarLogLink(env config) {
@@ -482,6 +748,108 @@ func pushRequestMetricHandler(currentHandler http.Handler, countMetric *stats.In
 	return handler
 }
 
+func pushRateLimitHandler(currentHandler http.Handler, cfg queue.DataPlaneConfig, probeKey []byte, onLimited func()) http.Handler {
+	burst := cfg.RateLimitBurst
+	if burst == 0 {
+		burst = int(math.Ceil(cfg.RateLimit))
+	}
+	return queue.RateLimitHandler(currentHandler, cfg.RateLimit, burst, probeKey, onLimited)
+}
+
+// newTunablesApplier returns the function applying the log level, request
+// metrics backend and tracing settings of a data-plane configuration, given
+// the request metrics backend the queue-proxy started with, if any. Request
+// metrics can only be switched between backends, not turned on or off,
+// without restarting the queue-proxy.
+func newTunablesApplier(env config, atomicLevel zap.AtomicLevel, metricsBackend string) func(queue.DataPlaneConfig) {
+	defaultLevel := atomicLevel.Level()
+	var oct *tracing.OpenCensusTracer
+	endpoint, err := zipkin.NewEndpoint(queue.Name, net.JoinHostPort(env.ServingPodIP, strconv.Itoa(env.QueueServingPort)))
+	if err != nil {
+		logger.Errorw("Unable to create tracing endpoint. Tracing will be unavailable.", zap.Error(err))
+	} else {
+		oct = tracing.NewOpenCensusTracer(tracing.WithZipkinExporter(tracing.CreateZipkinReporter, endpoint))
+	}
+
+	return func(cfg queue.DataPlaneConfig) {
+		level := defaultLevel
+		if cfg.LogLevel != "" {
+			// The level is validated when decoding the configuration.
+			level.UnmarshalText([]byte(cfg.LogLevel))
+		}
+		if atomicLevel.Level() != level {
+			logger.Infof("Updating logging level from %v to %v.", atomicLevel.Level(), level)
+			atomicLevel.SetLevel(level)
+		}
+
+		if metricsBackend != "" && cfg.RequestMetricsBackend != "" && cfg.RequestMetricsBackend != metricsBackend {
+			if err := setupMetricsExporter(cfg.RequestMetricsBackend); err != nil {
+				logger.Errorw("Error updating the request metrics exporter", zap.Error(err))
+			} else {
+				metricsBackend = cfg.RequestMetricsBackend
+			}
+		}
+
+		if oct != nil {
+			tc := cfg.Tracing
+			if err := oct.ApplyConfig(&tc); err != nil {
+				logger.Errorw("Unable to apply open census tracer config", zap.Error(err))
+			}
+		}
+	}
+}
+
+// rateLimitedReporter returns the callback recording the requests rejected
+// by the rate limit, or nil if metrics aren't supported.
+func rateLimitedReporter(metricsSupported bool, env config) func() {
+	if !metricsSupported {
+		return nil
+	}
+	r, err := queuestats.NewRateLimitReporter(env.ServingNamespace, env.ServingService, env.ServingConfiguration, env.ServingRevision, rateLimitedCountM)
+	if err != nil {
+		logger.Errorw("Error setting up rate limit metrics reporter. Rate limit metrics will be unavailable.", zap.Error(err))
+		return nil
+	}
+	return r.ReportRateLimited
+}
+
+// pushSLOHandler wraps currentHandler with the measurement of the requests
+// against the SLO of the revision, and records its objectives.
+func pushSLOHandler(currentHandler http.Handler, env config) http.Handler {
+	r, err := queuestats.NewSLOReporter(env.ServingNamespace, env.ServingService, env.ServingConfiguration, env.ServingRevision, sloRequestCountM, sloObjectiveM)
+	if err != nil {
+		logger.Errorw("Error setting up SLO metrics reporter. SLO metrics will be unavailable.", zap.Error(err))
+		return currentHandler
+	}
+	slo := queue.SLO{
+		Availability:  env.SLOAvailability,
+		Latency:       env.SLOLatency,
+		LatencyTarget: env.SLOLatencyTarget,
+	}
+	if slo.Availability > 0 {
+		r.ReportObjective(queue.SLIAvailability, slo.Availability)
+	}
+	if slo.LatencyTarget > 0 {
+		r.ReportObjective(queue.SLILatency, slo.LatencyTarget)
+	}
+	return queue.SLOHandler(currentHandler, slo, func(sli string, good bool) {
+		r.ReportRequest(sli, good)
+	})
+}
+
+func pushCompressionHandler(currentHandler http.Handler, metricsSupported bool, env config) http.Handler {
+	var onCompressed func(in, out int64)
+	if metricsSupported {
+		r, err := queuestats.NewCompressionReporter(env.ServingNamespace, env.ServingService, env.ServingConfiguration, env.ServingRevision, compressionSavedBytesM)
+		if err != nil {
+			logger.Errorw("Error setting up compression metrics reporter. Compression metrics will be unavailable.", zap.Error(err))
+		} else {
+			onCompressed = r.ReportCompressed
+		}
+	}
+	return queue.CompressionHandler(currentHandler, strings.Split(env.Compression, ","), onCompressed)
+}
+
 func setupMetricsExporter(backend string) error {
 	// Set up OpenCensus exporter.
 	// NOTE: We use revision as the component instead of queue because queue is
@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/network"
@@ -70,7 +71,7 @@ func TestHandlerReqEvent(t *testing.T) {
 	params := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 10}
 	breaker := queue.NewBreaker(params)
 	reqChan := make(chan queue.ReqEvent, 10)
-	h := handler(reqChan, breaker, proxy, func() bool { return true })
+	h := handler(reqChan, breaker, proxy, func() bool { return true }, []byte("1234"))
 
 	writer := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
@@ -91,37 +92,92 @@ func TestHandlerReqEvent(t *testing.T) {
 	}
 }
 
+func TestSanitizeForwardedHeadersHandler(t *testing.T) {
+	var got string
+	h := sanitizeForwardedHeadersHandler(&network.Config{
+		ForwardedHeaders: network.ForwardedHeadersStrip,
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Forwarded-For")
+	}))
+
+	tests := []struct {
+		name  string
+		proxy string
+		want  string
+	}{{
+		name: "from the ingress",
+		want: "",
+	}, {
+		name:  "from the activator",
+		proxy: activator.Name,
+		want:  "",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set("X-Forwarded-For", "192.0.2.1")
+			if test.proxy != "" {
+				req.Header.Set(network.ProxyHeaderName, test.proxy)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != test.want {
+				t.Errorf("X-Forwarded-For = %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
 func TestProbeHandler(t *testing.T) {
+	key := []byte("1234")
 	testcases := []struct {
 		name          string
 		prober        func() bool
 		wantCode      int
 		wantBody      string
 		requestHeader string
+		key           []byte
 	}{{
 		name:          "unexpected probe header",
 		prober:        func() bool { return true },
 		wantCode:      http.StatusBadRequest,
 		wantBody:      fmt.Sprintf(badProbeTemplate, "test-probe"),
 		requestHeader: "test-probe",
+		key:           key,
+	}, {
+		name:          "unsigned probe",
+		prober:        func() bool { return true },
+		wantCode:      http.StatusForbidden,
+		wantBody:      badProbeSignature,
+		requestHeader: queue.Name,
+	}, {
+		name:          "probe signed with another key",
+		prober:        func() bool { return true },
+		wantCode:      http.StatusForbidden,
+		wantBody:      badProbeSignature,
+		requestHeader: queue.Name,
+		key:           []byte("5678"),
 	}, {
 		name:          "true probe function",
 		prober:        func() bool { return true },
 		wantCode:      http.StatusOK,
 		wantBody:      queue.Name,
 		requestHeader: queue.Name,
+		key:           key,
 	}, {
 		name:          "nil probe function",
 		prober:        nil,
 		wantCode:      http.StatusInternalServerError,
 		wantBody:      "no probe",
 		requestHeader: queue.Name,
+		key:           key,
 	}, {
 		name:          "false probe function",
 		prober:        func() bool { return false },
 		wantCode:      http.StatusServiceUnavailable,
 		wantBody:      "container not ready",
 		requestHeader: queue.Name,
+		key:           key,
 	}}
 
 	for _, tc := range testcases {
@@ -129,8 +185,12 @@ func TestProbeHandler(t *testing.T) {
 			writer := httptest.NewRecorder()
 			req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
 			req.Header.Set(network.ProbeHeaderName, tc.requestHeader)
+			nonce := network.NewProbeNonce()
+			if tc.key != nil {
+				network.SignProbeRequest(req, tc.key, tc.requestHeader, nonce)
+			}
 
-			h := handler(nil, nil, nil, tc.prober)
+			h := handler(nil, nil, nil, tc.prober, key)
 			h(writer, req)
 
 			if got, want := writer.Code, tc.wantCode; got != want {
@@ -140,6 +200,9 @@ func TestProbeHandler(t *testing.T) {
 				// \r\n might be inserted, etc.
 				t.Errorf("probe body = %q, want: %q, diff: %s", got, want, cmp.Diff(got, want))
 			}
+			if got, want := network.ValidProbeResponse(writer.Result(), key, queue.Name, nonce), writer.Code == http.StatusOK; got != want {
+				t.Errorf("ValidProbeResponse() = %v, want: %v", got, want)
+			}
 		})
 	}
 }
@@ -170,6 +233,71 @@ func TestCreateVarLogLink(t *testing.T) {
 	}
 }
 
+func TestLocalizeProbe(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *corev1.Probe
+		want *corev1.Probe
+	}{{
+		name: "nil",
+	}, {
+		name: "http",
+		in:   &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Host: "127.0.0.1"}}},
+		want: &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Host: "::1"}}},
+	}, {
+		name: "tcp",
+		in:   &corev1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Host: "127.0.0.1"}}},
+		want: &corev1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Host: "::1"}}},
+	}, {
+		name: "other host",
+		in:   &corev1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Host: "example.com"}}},
+		want: &corev1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Host: "example.com"}}},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localizeProbe(test.in, "::1")
+			if !cmp.Equal(test.in, test.want) {
+				t.Errorf("localizeProbe (-want, +got) = %s", cmp.Diff(test.want, test.in))
+			}
+		})
+	}
+}
+
+func TestLivenessProbeHandler(t *testing.T) {
+	serve := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name))
+		})
+	}
+	h := livenessProbeHandler(serve("user-container"), serve("admin"))
+
+	for _, test := range []struct {
+		name   string
+		header string
+		want   string
+	}{{
+		name: "admin request",
+		want: "admin",
+	}, {
+		name:   "liveness probe",
+		header: network.KubeletProbeHeaderName,
+		want:   "user-container",
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/healthz", nil)
+			if test.header != "" {
+				req.Header.Set(test.header, queue.Name)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Body.String(); got != test.want {
+				t.Errorf("Served by %q, want: %q", got, test.want)
+			}
+		})
+	}
+}
+
 func TestProbeQueueConnectionFailure(t *testing.T) {
 	port := 12345 // some random port (that's not listening)
 
@@ -20,12 +20,14 @@ import (
 	"context"
 	"flag"
 	"log"
+	"net/http"
 
 	"k8s.io/client-go/tools/clientcmd"
 
 	"go.uber.org/zap"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/logging"
@@ -35,15 +37,28 @@ import (
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/version"
 	"knative.dev/pkg/webhook"
+	"knative.dev/serving/pkg/admission"
 	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	apiconfig "knative.dev/serving/pkg/apis/config"
 	net "knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/client/clientset/versioned"
+	"knative.dev/serving/pkg/health"
+	"knative.dev/serving/pkg/imagepolicy"
+	"knative.dev/serving/pkg/protection"
+	"knative.dev/serving/pkg/quota"
+	"knative.dev/serving/pkg/quota/clientcounter"
+	"knative.dev/serving/pkg/refcheck"
+	"knative.dev/serving/pkg/refcheck/clientchecker"
 )
 
 const (
 	component = "webhook"
+
+	// The names of the webhook configurations the webhooks register.
+	webhookName           = "webhook.serving.knative.dev"
+	protectionWebhookName = "protection.webhook.serving.knative.dev"
 )
 
 var (
@@ -80,12 +95,22 @@ func main() {
 		logger.Fatalw("Failed to get the client set", zap.Error(err))
 	}
 
+	servingClient, err := versioned.NewForConfig(clusterConfig)
+	if err != nil {
+		logger.Fatalw("Failed to get the serving client set", zap.Error(err))
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(clusterConfig)
+	if err != nil {
+		logger.Fatalw("Failed to get the dynamic client", zap.Error(err))
+	}
+
 	if err := version.CheckMinimumVersion(kubeClient.Discovery()); err != nil {
 		logger.Fatalw("Version check failed", err)
 	}
 
 	// Watch the logging config map and dynamically update logging levels.
-	configMapWatcher := configmap.NewInformedWatcher(kubeClient, system.Namespace())
+	configMapWatcher := health.NewConfigMapWatcher(configmap.NewInformedWatcher(kubeClient, system.Namespace()))
 	// Watch the observability config map and dynamically update metrics exporter.
 	configMapWatcher.Watch(metrics.ConfigMapName(), metrics.UpdateExporterFromConfigMap(component, logger))
 	// Watch the observability config map and dynamically update request logs.
@@ -93,10 +118,22 @@ func main() {
 
 	store := apiconfig.NewStore(logger.Named("config-store"))
 	store.WatchConfigs(configMapWatcher)
+	// Watch the webhook config map and apply it to the webhook configurations.
+	configurer := admission.NewConfigurer(dynamicClient, logger.Named("configurer"),
+		webhookName, protectionWebhookName)
+	configMapWatcher.Watch(admission.ConfigName, configurer.Update)
 
 	if err = configMapWatcher.Start(stopCh); err != nil {
 		logger.Fatalw("Failed to start the ConfigMap watcher", zap.Error(err))
 	}
+	// The webhooks reset their configurations when they register.
+	go configurer.Run(stopCh)
+
+	// Report why requests are rejected along with their count and latencies.
+	statsReporter, err := admission.NewStatsReporter()
+	if err != nil {
+		logger.Fatalw("Failed to create the stats reporter", zap.Error(err))
+	}
 
 	options := webhook.ControllerOptions{
 		ServiceName:    "webhook",
@@ -104,7 +141,8 @@ func main() {
 		Namespace:      system.Namespace(),
 		Port:           8443,
 		SecretName:     "webhook-certs",
-		WebhookName:    "webhook.serving.knative.dev",
+		WebhookName:    webhookName,
+		StatsReporter:  statsReporter,
 	}
 
 	handlers := map[schema.GroupVersionKind]webhook.GenericCRD{
@@ -124,8 +162,20 @@ func main() {
 		net.SchemeGroupVersion.WithKind("ServerlessService"):             &net.ServerlessService{},
 	}
 
+	// Review the images of Revisions with the policy service configured in
+	// config-image-policy.
+	imageChecker := imagepolicy.NewReviewChecker(http.DefaultTransport)
+	// Count the usage of the quotas configured in config-quota.
+	quotaCounter := clientcounter.New(kubeClient, servingClient)
+	// Look up the references of templates when reference-validation is
+	// enabled in config-features.
+	refChecker := clientchecker.New(kubeClient)
+
 	// Decorate contexts with the current state of the config.
 	ctxFunc := func(ctx context.Context) context.Context {
+		ctx = imagepolicy.WithChecker(ctx, imageChecker)
+		ctx = quota.WithCounter(ctx, quotaCounter)
+		ctx = refcheck.WithChecker(ctx, refChecker)
 		return v1beta1.WithUpgradeViaDefaulting(store.ToContext(ctx))
 	}
 
@@ -135,7 +185,52 @@ func main() {
 		logger.Fatalw("Failed to create admission controller", zap.Error(err))
 	}
 
-	if err = controller.Run(stopCh); err != nil {
+	// The webhook above doesn't receive deletions, guard them separately.
+	protectionWebhook := &protection.Webhook{
+		Client:  kubeClient,
+		Dynamic: dynamicClient,
+		Options: webhook.ControllerOptions{
+			ServiceName:    "webhook-protection",
+			DeploymentName: "webhook",
+			Namespace:      system.Namespace(),
+			Port:           8444,
+			SecretName:     "webhook-protection-certs",
+			WebhookName:    protectionWebhookName,
+			StatsReporter:  statsReporter,
+		},
+		Logger: logger.Named("protection"),
+	}
+	go func() {
+		if err := protectionWebhook.Run(stopCh); err != nil {
+			logger.Fatalw("Failed to start the protection webhook", zap.Error(err))
+		}
+	}()
+
+	// The webhook is ready once its ConfigMaps are loaded and it serves
+	// with valid certificates.
+	healthHandler := health.NewHandler()
+	healthHandler.AddCheck("configmaps", configMapWatcher.Check)
+	healthHandler.AddCheck("certificates", health.CertificateValid(kubeClient,
+		options.Namespace, options.SecretName, admission.SecretServerCert))
+	healthHandler.AddCheck("protection-certificates", health.CertificateValid(kubeClient,
+		protectionWebhook.Options.Namespace, protectionWebhook.Options.SecretName, admission.SecretServerCert))
+	go func() {
+		if err := health.ListenAndServe(stopCh, healthHandler); err != nil {
+			logger.Fatalw("Failed to serve the health of the webhook", zap.Error(err))
+		}
+	}()
+
+	// Serve the admission controller with certificates rotated before they
+	// expire, rather than with the ones it loads once in Run.
+	certs := admission.NewCertificates(kubeClient, dynamicClient, options, logger.Named("certificates"))
+	if err := certs.Reconcile(); err != nil {
+		logger.Fatalw("Failed to configure the certificates of the admission controller", zap.Error(err))
+	}
+	go certs.Run(stopCh)
+	if err := admission.RegisterMutatingWebhook(kubeClient, options, handlers, certs.CABundle()); err != nil {
+		logger.Fatalw("Failed to register the admission controller", zap.Error(err))
+	}
+	if err = admission.Serve(stopCh, options.Port, controller, certs); err != nil {
 		logger.Fatalw("Failed to start the admission controller", zap.Error(err))
 	}
 }
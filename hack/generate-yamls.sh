@@ -34,6 +34,9 @@
 #   random temporary directory will be created. **All existing YAML files in
 #   this directory will be deleted.**
 # * `$KO_DOCKER_REPO` If not set, use ko.local as the registry.
+# * `$KO_PLATFORMS` The platforms the images are built for, by default
+#   linux/amd64 and linux/arm64. Images pushed to ko.local (the local Docker
+#   daemon) are only built for the platform of the daemon.
 
 set -o errexit
 set -o pipefail
@@ -53,6 +56,7 @@ readonly SERVING_CRD_ALPHA_YAML=${YAML_OUTPUT_DIR}/serving-alpha-crds.yaml
 readonly SERVING_ALPHA_YAML=${YAML_OUTPUT_DIR}/serving-pre-1.14.yaml
 readonly SERVING_CRD_BETA_YAML=${YAML_OUTPUT_DIR}/serving-beta-crds.yaml
 readonly SERVING_BETA_YAML=${YAML_OUTPUT_DIR}/serving-post-1.14.yaml
+readonly SERVING_IMPERSONATION_YAML=${YAML_OUTPUT_DIR}/serving-impersonation.yaml
 
 readonly MONITORING_YAML=${YAML_OUTPUT_DIR}/monitoring.yaml
 readonly MONITORING_METRIC_PROMETHEUS_YAML=${YAML_OUTPUT_DIR}/monitoring-metrics-prometheus.yaml
@@ -62,9 +66,16 @@ readonly MONITORING_TRACE_JAEGER_YAML=${YAML_OUTPUT_DIR}/monitoring-tracing-jaeg
 readonly MONITORING_TRACE_JAEGER_IN_MEM_YAML=${YAML_OUTPUT_DIR}/monitoring-tracing-jaeger-in-mem.yaml
 readonly MONITORING_LOG_ELASTICSEARCH_YAML=${YAML_OUTPUT_DIR}/monitoring-logs-elasticsearch.yaml
 
+: ${KO_DOCKER_REPO:="ko.local"}
+export KO_DOCKER_REPO
+
+: ${KO_PLATFORMS:="linux/amd64,linux/arm64"}
+
 # Flags for all ko commands
 KO_YAML_FLAGS="-P"
 [[ "${KO_DOCKER_REPO}" != gcr.io/* ]] && KO_YAML_FLAGS=""
+# Build multi-arch images, so that the data-plane runs on mixed clusters.
+[[ "${KO_DOCKER_REPO}" != ko.local ]] && KO_YAML_FLAGS="${KO_YAML_FLAGS} --platform=${KO_PLATFORMS}"
 readonly KO_YAML_FLAGS="${KO_YAML_FLAGS} ${KO_FLAGS}"
 
 if [[ -n "${TAG}" ]]; then
@@ -73,9 +84,6 @@ else
   LABEL_YAML_CMD=(cat)
 fi
 
-: ${KO_DOCKER_REPO:="ko.local"}
-export KO_DOCKER_REPO
-
 cd "${YAML_REPO_ROOT}"
 
 echo "Building Knative Serving"
@@ -83,6 +91,8 @@ ko resolve ${KO_YAML_FLAGS} -f config/ | "${LABEL_YAML_CMD[@]}" > "${SERVING_YAM
 # These don't have images, but ko will concatenate them for us.
 ko resolve ${KO_YAML_FLAGS} -f config/v1alpha1 | "${LABEL_YAML_CMD[@]}" > "${SERVING_CRD_ALPHA_YAML}"
 ko resolve ${KO_YAML_FLAGS} -f config/v1beta1 | "${LABEL_YAML_CMD[@]}" > "${SERVING_CRD_BETA_YAML}"
+# Only needed with the -child-resource-service-account flag of the controller.
+ko resolve ${KO_YAML_FLAGS} -f config/impersonation | "${LABEL_YAML_CMD[@]}" > "${SERVING_IMPERSONATION_YAML}"
 
 # Create the full alpha install.
 cat "${SERVING_YAML}" > "${SERVING_ALPHA_YAML}"
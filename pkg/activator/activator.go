@@ -25,10 +25,41 @@ const (
 	Name = "activator"
 	// K8sServiceName is the name of the activator Kubernetes service.
 	K8sServiceName = "activator-service"
+	// RegistrationServiceName is the name of the headless Kubernetes service
+	// through which queue-proxies find all activators to register with.
+	RegistrationServiceName = "activator-registration"
+	// RegistrationPort is the port the activators receive the registrations
+	// of the queue-proxies on. It is only exposed through the headless
+	// registration service, not through the one the ingress routes to.
+	RegistrationPort = 8014
+	// RegistrationKeySecretName is the name of the Secret in the system
+	// namespace holding the key the registration tokens of the revisions are
+	// derived from. The controller creates it.
+	RegistrationKeySecretName = "activator-registration-key"
+	// RegistrationKeySecretKey is the key of the registration key Secret
+	// holding the key.
+	RegistrationKeySecretKey = "key"
 	// RevisionHeaderName is the header key for revision name.
 	RevisionHeaderName = "Knative-Serving-Revision"
 	// RevisionHeaderNamespace is the header key for revision's namespace.
 	RevisionHeaderNamespace = "Knative-Serving-Namespace"
+	// RouteHeaderName is the header key for the Route whose traffic targets
+	// the revisions the activator picks must be part of.
+	RouteHeaderName = "Knative-Serving-Route"
+	// SplitsHeaderName is the header key for the traffic split the activator
+	// picks the revision from, as comma separated name=percent pairs.
+	SplitsHeaderName = "Knative-Serving-Splits"
+	// StickyHeaderName is the header key for what the activator consistently
+	// picks the revision by: "cookie", or "header:" and the name of a header.
+	StickyHeaderName = "Knative-Serving-Sticky"
+	// StickyCookieName is the name of the cookie users are assigned by.
+	StickyCookieName = "knative-serving-sticky"
+	// FallbackHeaderName is the header key for the revision the requests
+	// the revision they are sent to fails to serve are retried against.
+	FallbackHeaderName = "Knative-Serving-Fallback"
+	// MaintenanceHeaderName is the header key marking the requests of Routes
+	// in maintenance, which the activator answers with the maintenance page.
+	MaintenanceHeaderName = "Knative-Serving-Maintenance"
 )
 
 // RevisionID is the combination of namespace and revision name
@@ -0,0 +1,59 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaintenanceConfigName is the name of config map for the responses to
+	// the requests of Routes in maintenance.
+	MaintenanceConfigName = "config-maintenance"
+)
+
+// Maintenance holds the response the activator sends to the requests of
+// Routes in maintenance.
+type Maintenance struct {
+	// Page is the HTML page sent with their 503s.
+	Page string
+	// RetryAfter is how long clients are asked to wait before retrying,
+	// zero if they aren't.
+	RetryAfter time.Duration
+}
+
+// NewMaintenanceFromConfigMap creates a Maintenance from the supplied ConfigMap.
+func NewMaintenanceFromConfigMap(configMap *corev1.ConfigMap) (*Maintenance, error) {
+	m := &Maintenance{
+		Page: configMap.Data["page"],
+	}
+
+	if raw, ok := configMap.Data["retry-after"]; ok {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse retry-after: %v", err)
+		} else if d < 0 {
+			return nil, fmt.Errorf("retry-after must not be negative, was %v", d)
+		}
+		m.RetryAfter = d
+	}
+
+	return m, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	. "knative.dev/pkg/configmap/testing"
+)
+
+func TestOurMaintenance(t *testing.T) {
+	actual, example := ConfigMapsFromTestFile(t, MaintenanceConfigName)
+	for _, tt := range []struct {
+		name string
+		fail bool
+		want *Maintenance
+		data *corev1.ConfigMap
+	}{{
+		name: "actual config",
+		want: &Maintenance{},
+		data: actual,
+	}, {
+		name: "example config",
+		want: &Maintenance{},
+		data: example,
+	}, {
+		name: "with value overrides",
+		want: &Maintenance{
+			Page:       "<h1>Back soon</h1>",
+			RetryAfter: time.Hour,
+		},
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"page":        "<h1>Back soon</h1>",
+				"retry-after": "1h",
+			},
+		},
+	}, {
+		name: "invalid retry after",
+		fail: true,
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"retry-after": "an hour",
+			},
+		},
+	}, {
+		name: "negative retry after",
+		fail: true,
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"retry-after": "-1h",
+			},
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewMaintenanceFromConfigMap(tt.data)
+			if (err != nil) != tt.fail {
+				t.Fatalf("NewMaintenanceFromConfigMap() = %v, want failure: %v", err, tt.fail)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("NewMaintenanceFromConfigMap() = %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 
 	"knative.dev/pkg/configmap"
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
 	tracingconfig "knative.dev/serving/pkg/tracing/config"
 )
 
@@ -28,7 +29,9 @@ type cfgKey struct{}
 
 // Config is a configuration for the activator
 type Config struct {
-	Tracing *tracingconfig.Config
+	Tracing     *tracingconfig.Config
+	Suspension  *suspensionconfig.Suspension
+	Maintenance *Maintenance
 }
 
 // FromContext obtains a Config injected into the passed context
@@ -53,7 +56,9 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"activator",
 			logger,
 			configmap.Constructors{
-				tracingconfig.ConfigName: tracingconfig.NewTracingConfigFromConfigMap,
+				tracingconfig.ConfigName:              tracingconfig.NewTracingConfigFromConfigMap,
+				suspensionconfig.SuspensionConfigName: suspensionconfig.NewSuspensionFromConfigMap,
+				MaintenanceConfigName:                 NewMaintenanceFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -68,7 +73,9 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 // Load creates a Config for this store
 func (s *Store) Load() *Config {
 	return &Config{
-		Tracing: s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
+		Tracing:     s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
+		Suspension:  s.UntypedLoad(suspensionconfig.SuspensionConfigName).(*suspensionconfig.Suspension).DeepCopy(),
+		Maintenance: s.UntypedLoad(MaintenanceConfigName).(*Maintenance).DeepCopy(),
 	}
 }
 
@@ -21,6 +21,7 @@ limitations under the License.
 package config
 
 import (
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
 	tracingconfig "knative.dev/serving/pkg/tracing/config"
 )
 
@@ -32,6 +33,16 @@ func (in *Config) DeepCopyInto(out *Config) {
 		*out = new(tracingconfig.Config)
 		**out = **in
 	}
+	if in.Suspension != nil {
+		in, out := &in.Suspension, &out.Suspension
+		*out = new(suspensionconfig.Suspension)
+		**out = **in
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(Maintenance)
+		**out = **in
+	}
 	return
 }
 
@@ -44,3 +55,19 @@ func (in *Config) DeepCopy() *Config {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Maintenance) DeepCopyInto(out *Maintenance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Maintenance.
+func (in *Maintenance) DeepCopy() *Maintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(Maintenance)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/activator"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	pkghttp "knative.dev/serving/pkg/http"
+)
+
+// fallbackBufferSize is the number of bytes of request bodies buffered to
+// retry them against the fallback revision.  Larger bodies are streamed, and
+// their requests are not retried.
+const fallbackBufferSize = 64 << 10
+
+// NewFallbackHandler creates a handler that retries the requests the
+// revision they are sent to fails to serve, with a 5xx status, against
+// the fallback revision of their Route.  The fallback revision the ingress
+// names must be the one of the Route it names.
+func NewFallbackHandler(routeLister servinglisters.RouteLister, next http.Handler) *FallbackHandler {
+	return &FallbackHandler{routeLister: routeLister, nextHandler: next}
+}
+
+// FallbackHandler sends the requests the revisions of a Route fail to serve
+// to its fallback revision.
+type FallbackHandler struct {
+	routeLister servinglisters.RouteLister
+	nextHandler http.Handler
+}
+
+func (h *FallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fallback := pkghttp.LastHeaderValue(r.Header, activator.FallbackHeaderName)
+	r.Header.Del(activator.FallbackHeaderName)
+	if fallback == "" || fallback == pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderName) ||
+		!h.isFallback(r, fallback) || !bufferBody(r, fallbackBufferSize) {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	// The handlers down the chain modify the request, keep it pristine.
+	retry := r.Clone(r.Context())
+	fw := &fallbackWriter{ResponseWriter: w, header: make(http.Header)}
+	h.nextHandler.ServeHTTP(fw, r)
+	if !fw.failed {
+		return
+	}
+
+	if r.GetBody != nil {
+		retry.Body, _ = r.GetBody()
+	}
+	retry.Header.Set(activator.RevisionHeaderName, fallback)
+	h.nextHandler.ServeHTTP(w, retry)
+}
+
+// isFallback returns whether revision is the fallback traffic target of
+// the Route the ingress named in the headers of r.
+func (h *FallbackHandler) isFallback(r *http.Request, revision string) bool {
+	route, err := headerRoute(h.routeLister, r)
+	if err != nil {
+		return false
+	}
+	for _, t := range route.Status.Traffic {
+		if t.Fallback && t.RevisionName == revision {
+			return true
+		}
+	}
+	return false
+}
+
+// fallbackWriter passes a response on to the http.ResponseWriter it wraps,
+// unless its status is a 5xx, in which case it is dropped.
+type fallbackWriter struct {
+	http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	failed      bool
+}
+
+// Header implements http.ResponseWriter.
+func (fw *fallbackWriter) Header() http.Header {
+	return fw.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (fw *fallbackWriter) WriteHeader(code int) {
+	if fw.wroteHeader {
+		return
+	}
+	fw.wroteHeader = true
+	if code >= http.StatusInternalServerError {
+		fw.failed = true
+		return
+	}
+	for k, v := range fw.header {
+		fw.ResponseWriter.Header()[k] = v
+	}
+	fw.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (fw *fallbackWriter) Write(b []byte) (int, error) {
+	if !fw.wroteHeader {
+		fw.WriteHeader(http.StatusOK)
+	}
+	if fw.failed {
+		return len(b), nil
+	}
+	return fw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (fw *fallbackWriter) Flush() {
+	if f, ok := fw.ResponseWriter.(http.Flusher); ok && fw.wroteHeader && !fw.failed {
+		f.Flush()
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+)
+
+// fallbackRouteLister returns a lister of the Route named route in
+// testNamespace, whose traffic goes to revision and falls back to fallback.
+func fallbackRouteLister(route, revision, fallback string) servinglisters.RouteLister {
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      route,
+		},
+	}
+	r.Status.Traffic = []v1alpha1.TrafficTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{RevisionName: revision, Percent: 100},
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{RevisionName: fallback, Fallback: true},
+	}}
+
+	informer := servinginformers.NewSharedInformerFactory(servingfake.NewSimpleClientset(r), 0)
+	routes := informer.Serving().V1alpha1().Routes()
+	routes.Informer().GetIndexer().Add(r)
+	return routes.Lister()
+}
+
+func TestFallbackHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		fallback  string
+		codes     map[string]int
+		body      string
+		wantCode  int
+		wantBody  string
+		wantTries []string
+	}{{
+		name:      "no fallback",
+		codes:     map[string]int{"primary": http.StatusServiceUnavailable},
+		wantCode:  http.StatusServiceUnavailable,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}, {
+		name:      "primary healthy",
+		fallback:  "good",
+		codes:     map[string]int{"primary": http.StatusOK},
+		wantCode:  http.StatusOK,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}, {
+		name:      "primary client error",
+		fallback:  "good",
+		codes:     map[string]int{"primary": http.StatusNotFound},
+		wantCode:  http.StatusNotFound,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}, {
+		name:      "primary unreachable",
+		fallback:  "good",
+		codes:     map[string]int{"primary": http.StatusBadGateway, "good": http.StatusOK},
+		body:      "request",
+		wantCode:  http.StatusOK,
+		wantBody:  "good",
+		wantTries: []string{"primary", "good"},
+	}, {
+		name:      "fallback fails too",
+		fallback:  "good",
+		codes:     map[string]int{"primary": http.StatusServiceUnavailable, "good": http.StatusInternalServerError},
+		wantCode:  http.StatusInternalServerError,
+		wantBody:  "good",
+		wantTries: []string{"primary", "good"},
+	}, {
+		name:      "fallback is the primary",
+		fallback:  "primary",
+		codes:     map[string]int{"primary": http.StatusServiceUnavailable},
+		wantCode:  http.StatusServiceUnavailable,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}, {
+		name:      "body too large to retry",
+		fallback:  "good",
+		codes:     map[string]int{"primary": http.StatusBadGateway, "good": http.StatusOK},
+		body:      strings.Repeat("x", fallbackBufferSize+1),
+		wantCode:  http.StatusBadGateway,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}, {
+		name:      "fallback not of the route",
+		fallback:  "evil",
+		codes:     map[string]int{"primary": http.StatusBadGateway, "evil": http.StatusOK},
+		wantCode:  http.StatusBadGateway,
+		wantBody:  "primary",
+		wantTries: []string{"primary"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var tries []string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				revision := r.Header.Get(activator.RevisionHeaderName)
+				tries = append(tries, revision)
+				if r.Header.Get(activator.FallbackHeaderName) != "" {
+					t.Error("Fallback header was passed on")
+				}
+				if body, _ := ioutil.ReadAll(r.Body); string(body) != test.body {
+					t.Errorf("Body of %s = %d bytes, want: %d", revision, len(body), len(test.body))
+				}
+				w.Header().Set("X-Revision", revision)
+				w.WriteHeader(test.codes[revision])
+				w.Write([]byte(revision))
+			})
+			handler := NewFallbackHandler(fallbackRouteLister("route", "primary", "good"), next)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(test.body))
+			req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+			req.Header.Set(activator.RouteHeaderName, "route")
+			req.Header.Set(activator.RevisionHeaderName, "primary")
+			if test.fallback != "" {
+				req.Header.Set(activator.FallbackHeaderName, test.fallback)
+			}
+			handler.ServeHTTP(resp, req)
+
+			if got := resp.Code; got != test.wantCode {
+				t.Errorf("Code = %d, want: %d", got, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+			if got := resp.Header().Get("X-Revision"); got != test.wantBody {
+				t.Errorf("X-Revision = %q, want: %q", got, test.wantBody)
+			}
+			if got, want := strings.Join(tries, ","), strings.Join(test.wantTries, ","); got != want {
+				t.Errorf("Tries = %s, want: %s", got, want)
+			}
+		})
+	}
+}
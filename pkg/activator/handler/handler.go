@@ -39,9 +39,11 @@ import (
 	netlisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
 	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/network/prober"
 	"knative.dev/serving/pkg/queue"
+	resourcenames "knative.dev/serving/pkg/reconciler/revision/resources/names"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -58,11 +60,17 @@ type activationHandler struct {
 
 	probeTimeout    time.Duration
 	probeTransport  http.RoundTripper
+	hedgeTransport  http.RoundTripper
+	replayTransport http.RoundTripper
 	endpointTimeout time.Duration
 
 	revisionLister servinglisters.RevisionLister
 	serviceLister  corev1listers.ServiceLister
 	sksLister      netlisters.ServerlessServiceLister
+	secretLister   corev1listers.SecretLister
+
+	// cache holds responses of revisions that opted into response caching.
+	cache *responseCache
 }
 
 // The default time we'll try to probe the revision for activation.
@@ -71,7 +79,7 @@ const defaulTimeout = 2 * time.Minute
 // New constructs a new http.Handler that deals with revision activation.
 func New(l *zap.SugaredLogger, r activator.StatsReporter, t *activator.Throttler,
 	rl servinglisters.RevisionLister, sl corev1listers.ServiceLister,
-	sksL netlisters.ServerlessServiceLister) http.Handler {
+	sksL netlisters.ServerlessServiceLister, secretL corev1listers.SecretLister) http.Handler {
 
 	return &activationHandler{
 		logger:         l,
@@ -81,13 +89,21 @@ func New(l *zap.SugaredLogger, r activator.StatsReporter, t *activator.Throttler
 		revisionLister: rl,
 		sksLister:      sksL,
 		serviceLister:  sl,
+		secretLister:   secretL,
 		probeTimeout:   defaulTimeout,
 		// In activator we collect metrics, so we're wrapping
 		// the RoundTripper the prober would use inside an annotating transport.
 		probeTransport: &ochttp.Transport{
 			Base: network.NewProberTransport(),
 		},
+		// Hedged requests use a new connection, so that they are
+		// likely to be balanced to a different pod.
+		hedgeTransport: network.NewProberTransport(),
+		// Replays use a new connection too, the pod the first attempt
+		// was sent to is likely going away.
+		replayTransport: network.NewProberTransport(),
 		endpointTimeout: defaulTimeout,
+		cache:           newResponseCache(),
 	}
 }
 
@@ -100,7 +116,7 @@ func withOrigProto(or *http.Request) prober.Preparer {
 	}
 }
 
-func (a *activationHandler) probeEndpoint(logger *zap.SugaredLogger, r *http.Request, target *url.URL) (bool, int) {
+func (a *activationHandler) probeEndpoint(logger *zap.SugaredLogger, r *http.Request, target *url.URL, key []byte) (bool, int) {
 	var (
 		attempts int
 		st       = time.Now()
@@ -113,8 +129,9 @@ func (a *activationHandler) probeEndpoint(logger *zap.SugaredLogger, r *http.Req
 			r.Context(),
 			a.probeTransport,
 			url,
-			prober.WithHeader(network.ProbeHeaderName, queue.Name),
+			prober.WithSignature(key, queue.Name),
 			prober.ExpectsBody(queue.Name),
+			prober.ExpectsSignature(key, queue.Name),
 			withOrigProto(r))
 		if err != nil {
 			logger.Warnw("Pod probe failed", zap.Error(err))
@@ -137,7 +154,7 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
 	revID := activator.RevisionID{Namespace: namespace, Name: name}
 
-	logger := a.logger.With(zap.String(logkey.Key, revID.String()))
+	logger := logging.ForRevision(a.logger, namespace, name).With(zap.String(logkey.Key, revID.String()))
 
 	revision, err := a.revisionLister.Revisions(namespace).Get(name)
 	if err != nil {
@@ -146,6 +163,27 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	configurationName := revision.Labels[serving.ConfigurationLabelKey]
+	serviceName := revision.Labels[serving.ServiceLabelKey]
+
+	if ttl := responseCacheTTL(revision); ttl > 0 && a.cache != nil {
+		if key, ok := cacheKey(revID, r); ok {
+			cached, leader := a.cache.lookup(r.Context(), key)
+			if cached != nil {
+				cached.serve(w)
+				a.reporter.ReportRequestCount(namespace, serviceName, configurationName, name, cached.code, 0, 1.0)
+				a.reporter.ReportResponseTime(r.Context(), namespace, serviceName, configurationName, name, cached.code, time.Since(start))
+				return
+			}
+			if leader {
+				recorder := newCacheRecorder(w)
+				// Always release the requests waiting on us, even if we fail.
+				defer func() { a.cache.done(key, recorder.response(), ttl) }()
+				w = recorder
+			}
+		}
+	}
+
 	// SKS name matches that of revision.
 	sks, err := a.sksLister.ServerlessServices(namespace).Get(name)
 	if err != nil {
@@ -165,6 +203,14 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Host:   host,
 	}
 
+	probeKey, err := a.probeKey(revision)
+	if err != nil {
+		// The controller creates the Secret ahead of the pods.
+		logger.Errorw("Error while getting the probe key", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Error getting the probe key: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
 	tryContext, trySpan := trace.StartSpan(r.Context(), "throttler_try")
 	if a.endpointTimeout > 0 {
 		var cancel context.CancelFunc
@@ -177,8 +223,20 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		trySpan.End()
 		a.logger.Debugf("Waiting for throttler took %v time", time.Since(tryStart))
 
+		target := target
+		// Until the Endpoints of the private service list the pods we were
+		// waiting for, the service has no backends. Route to a Ready pod
+		// directly instead of waiting for the Endpoints to propagate. Pods
+		// in our own zone are routed to directly too, when we prefer them.
+		if ip, ok := a.throttler.PodIP(revID); ok {
+			target = &url.URL{
+				Scheme: "http",
+				Host:   net.JoinHostPort(ip, strconv.Itoa(podPort(revision.GetProtocol()))),
+			}
+		}
+
 		probeCtx, probeSpan := trace.StartSpan(r.Context(), "probe")
-		success, attempts := a.probeEndpoint(logger, r.WithContext(probeCtx), target)
+		success, attempts := a.probeEndpoint(logger, r.WithContext(probeCtx), target, probeKey)
 		probeSpan.End()
 
 		var httpStatus int
@@ -186,17 +244,34 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Once we see a successful probe, send traffic.
 			attempts++
 			proxyCtx, proxySpan := trace.StartSpan(r.Context(), "proxy")
-			httpStatus = a.proxyRequest(w, r.WithContext(proxyCtx), target)
+			// Replays and hedges go to another Ready pod if there is one,
+			// else to the private service, which drops the failed pod once
+			// it's no longer Ready.
+			retarget := func(failed string) string {
+				if ip, _, err := net.SplitHostPort(failed); err == nil {
+					if ip, ok := a.throttler.ReplayPodIP(revID, ip); ok {
+						return net.JoinHostPort(ip, strconv.Itoa(podPort(revision.GetProtocol())))
+					}
+				}
+				return host
+			}
+			// Pods failing too many requests in a row are ejected from the
+			// targets of PodIP and ReplayPodIP for a while.
+			report := func(ip string, failed bool) {
+				if a.throttler.ReportPodResult(revID, ip, failed) {
+					logger.Infof("Ejecting pod %s failing requests", ip)
+					a.reporter.ReportPodEjection(namespace, serviceName, configurationName, name)
+				}
+			}
+			httpStatus = a.proxyRequest(w, r.WithContext(proxyCtx), target, hedgeDelay(revision), requestBufferSize(revision), retarget, report)
 			proxySpan.End()
 		} else {
 			httpStatus = http.StatusInternalServerError
 			w.WriteHeader(httpStatus)
 		}
 
-		configurationName := revision.Labels[serving.ConfigurationLabelKey]
-		serviceName := revision.Labels[serving.ServiceLabelKey]
 		a.reporter.ReportRequestCount(namespace, serviceName, configurationName, name, httpStatus, attempts, 1.0)
-		a.reporter.ReportResponseTime(namespace, serviceName, configurationName, name, httpStatus, time.Since(start))
+		a.reporter.ReportResponseTime(r.Context(), namespace, serviceName, configurationName, name, httpStatus, time.Since(start))
 	})
 	if err != nil {
 		// Set error on our capacity waiting span and end it
@@ -214,12 +289,33 @@ func (a *activationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL) int {
+func (a *activationHandler) proxyRequest(w http.ResponseWriter, r *http.Request, target *url.URL, hedgeDelay time.Duration, bufferSize int64, retarget func(string) string, report func(string, bool)) int {
 	network.RewriteHostIn(r)
 	recorder := pkghttp.NewResponseRecorder(w, http.StatusOK)
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	transport := http.RoundTripper(&outlierTransport{base: a.transport, report: report})
+	if hedgeDelay > 0 && a.hedgeTransport != nil && isHedgeable(r) {
+		transport = &hedgingTransport{
+			primary:  transport,
+			hedge:    &outlierTransport{base: a.hedgeTransport, report: report},
+			delay:    hedgeDelay,
+			retarget: retarget,
+		}
+	}
+	if bufferSize > 0 && bufferBody(r, bufferSize) {
+		replay := a.replayTransport
+		if replay == nil {
+			replay = a.transport
+		}
+		transport = &replayingTransport{
+			primary:  transport,
+			replay:   &outlierTransport{base: replay, report: report},
+			backoff:  replayBackoff,
+			retarget: retarget,
+		}
+	}
 	proxy.Transport = &ochttp.Transport{
-		Base: a.transport,
+		Base: transport,
 	}
 	proxy.FlushInterval = -1
 
@@ -256,6 +352,29 @@ func (a *activationHandler) serviceHostName(rev *v1alpha1.Revision, serviceName
 	return net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(port)), nil
 }
 
+// podPort returns the port of the queue-proxy of the pods serving the protocol.
+func podPort(proto networking.ProtocolType) int {
+	if proto == networking.ProtocolH2C {
+		return networking.BackendHTTP2Port
+	}
+	return networking.BackendHTTPPort
+}
+
+// probeKey returns the key the probes of the pods of rev are signed with, out
+// of the Secret of rev.
+func (a *activationHandler) probeKey(rev *v1alpha1.Revision) ([]byte, error) {
+	name := resourcenames.Secret(rev)
+	secret, err := a.secretLister.Secrets(rev.Namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	key := secret.Data[queue.ProbeKeyKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", name, queue.ProbeKeyKey)
+	}
+	return key, nil
+}
+
 func sendError(err error, w http.ResponseWriter) {
 	msg := fmt.Sprintf("Error getting active endpoint: %v", err)
 	if k8serrors.IsNotFound(err) {
@@ -15,6 +15,7 @@ limitations under the License.
 package handler
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -25,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/test/helpers"
 
 	"github.com/google/go-cmp/cmp"
@@ -52,6 +54,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
 	corev1informers "k8s.io/client-go/informers/core/v1"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -317,6 +320,7 @@ func TestActivationHandler(t *testing.T) {
 				revisionLister(revision(testNamespace, testRevName)),
 				serviceLister(service(testNamespace, testRevName, "http")),
 				sksLister(sks(testNamespace, testRevName)),
+				secretLister(secret(testNamespace, testRevName)),
 			)).(*activationHandler)
 			handler.probeTimeout = test.probeTimeout
 
@@ -392,6 +396,7 @@ func TestActivationHandlerOverflow(t *testing.T) {
 		revisionLister(revision(namespace, revName)),
 		serviceLister(service(namespace, revName, "http")),
 		sksLister(sks(namespace, revName)),
+		secretLister(secret(namespace, revName)),
 	)).(*activationHandler)
 
 	// Setup transports.
@@ -436,7 +441,8 @@ func TestActivationHandlerOverflowSeveralRevisions(t *testing.T) {
 	}
 	rt := network.RoundTripperFunc(fakeRT.RT)
 	handler := (New(TestLogger(t), reporter, throttler,
-		revClient, svcClient, sksClient)).(*activationHandler)
+		revClient, svcClient, sksClient,
+		secretLister(secret(testNamespace, rev1), secret(testNamespace, rev2)))).(*activationHandler)
 
 	// Setup transports.
 	handler.transport = rt
@@ -484,6 +490,7 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 		revisionLister: revisionLister(revision(testNamespace, testRevName)),
 		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
 		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		secretLister:   secretLister(secret(testNamespace, testRevName)),
 	}
 
 	writer := httptest.NewRecorder()
@@ -502,6 +509,87 @@ func TestActivationHandlerProxyHeader(t *testing.T) {
 	}
 }
 
+func TestActivationHandlerRoutesToReadyPods(t *testing.T) {
+	breakerParams := queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 0}
+	namespace, revName := testNamespace, testRevName
+
+	interceptCh := make(chan *http.Request, 1)
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		interceptCh <- r
+		fake := httptest.NewRecorder()
+		return fake.Result(), nil
+	})
+	// The Endpoints don't list the pod yet.
+	throttler := activator.NewThrottler(
+		breakerParams,
+		endpointsInformer(endpoints(namespace, revName, 0)),
+		sksLister(sks(namespace, revName)),
+		revisionLister(revision(namespace, revName)),
+		TestLogger(t))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revName + "-pod",
+			Namespace: namespace,
+			Labels:    map[string]string{serving.RevisionLabelKey: revName},
+		},
+		Status: corev1.PodStatus{
+			PodIP: "10.0.0.1",
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+	kubeClient := kubefake.NewSimpleClientset(pod)
+	podInformer := kubeinformers.NewSharedInformerFactory(kubeClient, 0).Core().V1().Pods()
+	throttler.WatchPods(podInformer)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	controller.StartInformers(stopCh, podInformer.Informer())
+	revID := activator.RevisionID{Namespace: namespace, Name: revName}
+	if err := wait.PollImmediate(10*time.Millisecond, 3*time.Second, func() (bool, error) {
+		_, ok := throttler.PodIP(revID)
+		return ok, nil
+	}); err != nil {
+		t.Fatal("Timed out waiting for the pod to be tracked")
+	}
+
+	fakeRT := activatortest.FakeRoundTripper{
+		RequestResponse: &activatortest.FakeResponse{
+			Err:  nil,
+			Code: http.StatusOK,
+			Body: wantBody,
+		},
+	}
+	handler := activationHandler{
+		transport:      rt,
+		probeTransport: network.RoundTripperFunc(fakeRT.RT),
+		logger:         TestLogger(t),
+		reporter:       &fakeReporter{},
+		throttler:      throttler,
+		revisionLister: revisionLister(revision(testNamespace, testRevName)),
+		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
+		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		secretLister:   secretLister(secret(testNamespace, testRevName)),
+	}
+
+	writer := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+	req.Header.Set(activator.RevisionHeaderNamespace, namespace)
+	req.Header.Set(activator.RevisionHeaderName, revName)
+	handler.ServeHTTP(writer, req)
+
+	select {
+	case httpReq := <-interceptCh:
+		if got, want := httpReq.URL.Host, "10.0.0.1:8012"; got != want {
+			t.Errorf("Request sent to %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a request to be intercepted")
+	}
+}
+
 func TestActivationHandlerTraceSpans(t *testing.T) {
 	// Setup transport
 	fakeRt := activatortest.FakeRoundTripper{
@@ -550,6 +638,7 @@ func TestActivationHandlerTraceSpans(t *testing.T) {
 		revisionLister: revisionLister(revision(testNamespace, testRevName)),
 		serviceLister:  serviceLister(service(testNamespace, testRevName, "http")),
 		sksLister:      sksLister(sks(testNamespace, testRevName)),
+		secretLister:   secretLister(secret(testNamespace, testRevName)),
 	}
 	handler.transport = rt
 	handler.probeTransport = rt
@@ -687,7 +776,7 @@ func (f *fakeReporter) ReportRequestCount(ns, service, config, rev string, respo
 	return nil
 }
 
-func (f *fakeReporter) ReportResponseTime(ns, service, config, rev string, responseCode int, d time.Duration) error {
+func (f *fakeReporter) ReportResponseTime(_ context.Context, ns, service, config, rev string, responseCode int, d time.Duration) error {
 	f.mux.Lock()
 	defer f.mux.Unlock()
 	f.calls = append(f.calls, reporterCall{
@@ -703,6 +792,20 @@ func (f *fakeReporter) ReportResponseTime(ns, service, config, rev string, respo
 	return nil
 }
 
+func (f *fakeReporter) ReportPodEjection(ns, service, config, rev string) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.calls = append(f.calls, reporterCall{
+		Op:        "ReportPodEjection",
+		Namespace: ns,
+		Service:   service,
+		Config:    config,
+		Revision:  rev,
+	})
+
+	return nil
+}
+
 func revision(namespace, name string) *v1alpha1.Revision {
 	return &v1alpha1.Revision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -788,6 +891,33 @@ func sksLister(skss ...*nv1a1.ServerlessService) netlisters.ServerlessServiceLis
 	return services.Lister()
 }
 
+// secret returns the Secret of the revision, holding the probe key the
+// FakeRoundTripper checks by default.
+func secret(namespace, revision string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revision + "-secret",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			queue.ProbeKeyKey: activatortest.DefaultProbeKey,
+		},
+	}
+}
+
+func secretLister(secrets ...*corev1.Secret) corev1listers.SecretLister {
+	fake := kubefake.NewSimpleClientset()
+	informer := kubeinformers.NewSharedInformerFactory(fake, 0)
+	lister := informer.Core().V1().Secrets()
+
+	for _, secret := range secrets {
+		fake.Core().Secrets(secret.Namespace).Create(secret)
+		lister.Informer().GetIndexer().Add(secret)
+	}
+
+	return lister.Lister()
+}
+
 func endpoints(namespace, name string, count int) *corev1.Endpoints {
 	ep := &corev1.Endpoints{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+// hedgeDelay returns the delay after which a request to the given revision is
+// hedged, or 0 if the revision did not opt into hedging.
+func hedgeDelay(rev *v1alpha1.Revision) time.Duration {
+	v, ok := rev.Annotations[serving.HedgeDelayAnnotationKey]
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 || d > serving.MaxHedgeDelay {
+		return 0
+	}
+	return d
+}
+
+// isHedgeable returns whether it is safe to send r more than once. Its body,
+// if any, must be replayable, whatever its length: chunked bodies have none.
+func isHedgeable(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// hedgingTransport is an http.RoundTripper that sends a duplicate of the
+// request through hedge if primary has not responded after delay, and
+// returns whichever response arrives first.  The other attempt is cancelled.
+type hedgingTransport struct {
+	primary http.RoundTripper
+	hedge   http.RoundTripper
+	delay   time.Duration
+	// retarget returns the host to send the duplicate to when the request
+	// is slow on the given host, so that it isn't sent to the same pod
+	// again.  If nil, the duplicate goes to the same host.
+	retarget func(slow string) string
+}
+
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+}
+
+// RoundTrip implements http.RoundTripper.
+func (ht *hedgingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// Buffered so that the losing attempt never blocks.
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func(rt http.RoundTripper) {
+		ctx, cancel := context.WithCancel(r.Context())
+		attempt := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			req := r.WithContext(ctx)
+			if attempt > 0 {
+				req = r.Clone(ctx)
+				if ht.retarget != nil {
+					req.URL.Host = ht.retarget(r.URL.Host)
+				}
+			}
+			if attempt > 0 && r.GetBody != nil {
+				// The body of r is read by the first attempt.
+				body, err := r.GetBody()
+				if err != nil {
+					results <- hedgeResult{attempt: attempt, err: err}
+					return
+				}
+				req.Body = body
+			}
+			resp, err := rt.RoundTrip(req)
+			results <- hedgeResult{attempt: attempt, resp: resp, err: err}
+		}()
+	}
+
+	send(ht.primary)
+	pending := 1
+
+	timer := time.NewTimer(ht.delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			send(ht.hedge)
+			pending++
+		case res := <-results:
+			pending--
+			if res.err != nil {
+				cancels[res.attempt]()
+				if pending > 0 {
+					// The other attempt may still succeed.
+					continue
+				}
+			}
+			// Cancel the attempt that lost the race, if any.
+			for i, cancel := range cancels {
+				if i != res.attempt {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				go drain(results, pending)
+			}
+			if res.err != nil {
+				return nil, res.err
+			}
+			res.resp.Body = &cancelOnClose{ReadCloser: res.resp.Body, cancel: cancels[res.attempt]}
+			return res.resp, nil
+		}
+	}
+}
+
+// drain releases the responses of the attempts that lost the race.
+func drain(results chan hedgeResult, n int) {
+	for i := 0; i < n; i++ {
+		if res := <-results; res.resp != nil {
+			res.resp.Body.Close()
+		}
+	}
+}
+
+// cancelOnClose releases the context of the winning attempt once its
+// response body has been consumed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
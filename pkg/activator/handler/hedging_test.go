@@ -0,0 +1,241 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/network"
+)
+
+func TestHedgeDelay(t *testing.T) {
+	rev := revision(testNamespace, testRevName)
+	if got := hedgeDelay(rev); got != 0 {
+		t.Errorf("hedgeDelay() = %v without annotation, want 0", got)
+	}
+	rev.Annotations = map[string]string{serving.HedgeDelayAnnotationKey: "200ms"}
+	if got, want := hedgeDelay(rev), 200*time.Millisecond; got != want {
+		t.Errorf("hedgeDelay() = %v, want %v", got, want)
+	}
+}
+
+// replayable returns a request with body, which it can replay.
+func replayable(method, body string) *http.Request {
+	r, _ := http.NewRequest(method, "http://example.com", strings.NewReader(body))
+	return r
+}
+
+// chunked returns a request with body, of unknown length, as it comes in
+// when chunked.
+func chunked(method, body string) *http.Request {
+	r := httptest.NewRequest(method, "http://example.com", ioutil.NopCloser(strings.NewReader(body)))
+	r.ContentLength = -1
+	r.TransferEncoding = []string{"chunked"}
+	return r
+}
+
+func TestIsHedgeable(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *http.Request
+		want bool
+	}{{
+		name: "get",
+		req:  httptest.NewRequest(http.MethodGet, "http://example.com", nil),
+		want: true,
+	}, {
+		name: "head",
+		req:  httptest.NewRequest(http.MethodHead, "http://example.com", nil),
+		want: true,
+	}, {
+		name: "post",
+		req:  httptest.NewRequest(http.MethodPost, "http://example.com", nil),
+	}, {
+		name: "get with body",
+		req:  httptest.NewRequest(http.MethodGet, "http://example.com", strings.NewReader("body")),
+	}, {
+		name: "get with replayable body",
+		req:  replayable(http.MethodGet, "body"),
+		want: true,
+	}, {
+		name: "chunked get",
+		req:  chunked(http.MethodGet, "body"),
+	}, {
+		name: "chunked post",
+		req:  chunked(http.MethodPost, "body"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isHedgeable(test.req); got != test.want {
+				t.Errorf("isHedgeable() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// respondAfter returns a RoundTripper that responds with body after d, or
+// fails when the request is cancelled first.
+func respondAfter(d time.Duration, body string, err error) http.RoundTripper {
+	return network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec := httptest.NewRecorder()
+		rec.WriteString(body)
+		return rec.Result(), nil
+	})
+}
+
+func TestHedgingTransport(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name     string
+		primary  http.RoundTripper
+		hedge    http.RoundTripper
+		wantBody string
+		wantErr  error
+	}{{
+		name:     "primary is fast",
+		primary:  respondAfter(0, "primary", nil),
+		hedge:    respondAfter(0, "hedge", nil),
+		wantBody: "primary",
+	}, {
+		name:     "primary straggles",
+		primary:  respondAfter(time.Minute, "primary", nil),
+		hedge:    respondAfter(0, "hedge", nil),
+		wantBody: "hedge",
+	}, {
+		name:     "primary fails after hedging",
+		primary:  respondAfter(100*time.Millisecond, "", errFailed),
+		hedge:    respondAfter(200*time.Millisecond, "hedge", nil),
+		wantBody: "hedge",
+	}, {
+		name:    "primary fails before hedging",
+		primary: respondAfter(0, "", errFailed),
+		hedge:   respondAfter(0, "hedge", nil),
+		wantErr: errFailed,
+	}, {
+		name:    "both fail",
+		primary: respondAfter(100*time.Millisecond, "", errFailed),
+		hedge:   respondAfter(0, "", errFailed),
+		wantErr: errFailed,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ht := &hedgingTransport{
+				primary: test.primary,
+				hedge:   test.hedge,
+				delay:   50 * time.Millisecond,
+			}
+			resp, err := ht.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+			if err != test.wantErr {
+				t.Fatalf("RoundTrip() = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Error reading body: %v", err)
+			}
+			if got := string(body); got != test.wantBody {
+				t.Errorf("Body = %q, want %q", got, test.wantBody)
+			}
+		})
+	}
+}
+
+func TestHedgingTransportReplaysBody(t *testing.T) {
+	bodies := make(chan string, 2)
+	readBody := func(delay time.Duration) http.RoundTripper {
+		return network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			b, _ := ioutil.ReadAll(r.Body)
+			bodies <- string(b)
+			return respondAfter(delay, string(b), nil).RoundTrip(r)
+		})
+	}
+	ht := &hedgingTransport{
+		primary: readBody(time.Minute),
+		hedge:   readBody(0),
+		delay:   50 * time.Millisecond,
+	}
+	resp, err := ht.RoundTrip(replayable(http.MethodGet, "body"))
+	if err != nil {
+		t.Fatalf("RoundTrip() = %v", err)
+	}
+	resp.Body.Close()
+	for i := 0; i < 2; i++ {
+		if got := <-bodies; got != "body" {
+			t.Errorf("Attempt read body %q, want %q", got, "body")
+		}
+	}
+}
+
+func TestHedgingTransportRetarget(t *testing.T) {
+	const slow, other = "10.0.0.1:8012", "10.0.0.2:8012"
+	hosts := make(chan string, 2)
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		hosts <- r.URL.Host
+		if r.URL.Host == slow {
+			return respondAfter(time.Minute, slow, nil).RoundTrip(r)
+		}
+		return respondAfter(0, r.URL.Host, nil).RoundTrip(r)
+	})
+	retarget := func(host string) string {
+		if host == slow {
+			return other
+		}
+		return slow
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://"+slow, nil)
+	ht := &hedgingTransport{
+		primary:  rt,
+		hedge:    rt,
+		delay:    50 * time.Millisecond,
+		retarget: retarget,
+	}
+	resp, err := ht.RoundTrip(r)
+	if err != nil {
+		t.Fatalf("RoundTrip() = %v", err)
+	}
+	defer resp.Body.Close()
+	if got, _ := ioutil.ReadAll(resp.Body); string(got) != other {
+		t.Errorf("Body = %q, want the response of %q", got, other)
+	}
+	if got := []string{<-hosts, <-hosts}; got[0] != slow || got[1] != other {
+		t.Errorf("Hosts = %v, want [%s %s]", got, slow, other)
+	}
+	if r.URL.Host != slow {
+		t.Errorf("Request host = %q, want %q", r.URL.Host, slow)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	pkghttp "knative.dev/serving/pkg/http"
+)
+
+// maintenanceMessage is the body of the 503s sent when no page is configured.
+const maintenanceMessage = "The service is down for maintenance."
+
+// NewMaintenanceHandler creates a handler that responds to the requests of
+// Routes in maintenance with a 503 and the page of the config-maintenance
+// ConfigMap, instead of passing them on to next. It expects the config of
+// the activator in the context of the requests.
+func NewMaintenanceHandler(next http.Handler) *MaintenanceHandler {
+	return &MaintenanceHandler{nextHandler: next}
+}
+
+// MaintenanceHandler serves the maintenance page of Routes in maintenance.
+type MaintenanceHandler struct {
+	nextHandler http.Handler
+}
+
+func (h *MaintenanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if pkghttp.LastHeaderValue(r.Header, activator.MaintenanceHeaderName) != "true" {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	cfg := activatorconfig.FromContext(r.Context()).Maintenance
+	if secs := int64(cfg.RetryAfter.Seconds()); secs > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	}
+	if cfg.Page == "" {
+		http.Error(w, maintenanceMessage, http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	io.WriteString(w, cfg.Page)
+}
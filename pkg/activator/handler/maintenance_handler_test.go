@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+func TestMaintenanceHandler(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	tests := []struct {
+		name           string
+		maintenance    string
+		data           map[string]string
+		wantCode       int
+		wantBody       string
+		wantRetryAfter string
+	}{{
+		name:     "not in maintenance",
+		data:     map[string]string{"page": "<h1>Back soon</h1>"},
+		wantCode: http.StatusOK,
+		wantBody: "proxied",
+	}, {
+		name:        "in maintenance",
+		maintenance: "true",
+		wantCode:    http.StatusServiceUnavailable,
+		wantBody:    maintenanceMessage + "\n",
+	}, {
+		name:        "in maintenance, page",
+		maintenance: "true",
+		data: map[string]string{
+			"page":        "<h1>Back soon</h1>",
+			"retry-after": "5m",
+		},
+		wantCode:       http.StatusServiceUnavailable,
+		wantBody:       "<h1>Back soon</h1>",
+		wantRetryAfter: "300",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := activatorconfig.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: tracingconfig.ConfigName},
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: suspensionconfig.SuspensionConfigName},
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: activatorconfig.MaintenanceConfigName},
+				Data:       test.data,
+			})
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("proxied"))
+			})
+			handler := store.HTTPMiddleware(NewMaintenanceHandler(next))
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			if test.maintenance != "" {
+				req.Header.Set(activator.MaintenanceHeaderName, test.maintenance)
+			}
+			handler.ServeHTTP(resp, req)
+
+			if got := resp.Code; got != test.wantCode {
+				t.Errorf("Code = %d, want: %d", got, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+			if got := resp.Header().Get("Retry-After"); got != test.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want: %q", got, test.wantRetryAfter)
+			}
+		})
+	}
+}
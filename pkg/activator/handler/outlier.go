@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net"
+	"net/http"
+)
+
+// outlierTransport is an http.RoundTripper reporting whether the requests it
+// sends failed, with an error or a 5xx response, to the IP they were sent to,
+// so that the pods failing too many of them get ejected.
+type outlierTransport struct {
+	base   http.RoundTripper
+	report func(ip string, failed bool)
+}
+
+func (ot *outlierTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := ot.base.RoundTrip(r)
+	// A request canceled by the client, or a hedge that lost the race, says
+	// nothing about the pod.
+	if r.Context().Err() != nil {
+		return resp, err
+	}
+	ip, _, serr := net.SplitHostPort(r.URL.Host)
+	if serr != nil || net.ParseIP(ip) == nil {
+		return resp, err
+	}
+	ot.report(ip, err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
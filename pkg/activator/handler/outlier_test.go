@@ -0,0 +1,102 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestOutlierTransport(t *testing.T) {
+	respond := func(code int, err error) http.RoundTripper {
+		return network.RoundTripperFunc(func(*http.Request) (*http.Response, error) {
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: code, Body: http.NoBody}, nil
+		})
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		base       http.RoundTripper
+		canceled   bool
+		wantReport bool
+		wantFailed bool
+	}{{
+		name:       "success",
+		url:        "http://10.0.0.1:8080",
+		base:       respond(http.StatusOK, nil),
+		wantReport: true,
+	}, {
+		name:       "client error",
+		url:        "http://10.0.0.1:8080",
+		base:       respond(http.StatusNotFound, nil),
+		wantReport: true,
+	}, {
+		name:       "server error",
+		url:        "http://10.0.0.1:8080",
+		base:       respond(http.StatusBadGateway, nil),
+		wantReport: true,
+		wantFailed: true,
+	}, {
+		name:       "error",
+		url:        "http://10.0.0.1:8080",
+		base:       respond(0, errors.New("refused")),
+		wantReport: true,
+		wantFailed: true,
+	}, {
+		name: "service",
+		url:  "http://private.default.svc.cluster.local:80",
+		base: respond(http.StatusBadGateway, nil),
+	}, {
+		name:     "canceled",
+		url:      "http://10.0.0.1:8080",
+		base:     respond(0, context.Canceled),
+		canceled: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reported, failed bool
+			ot := &outlierTransport{
+				base: test.base,
+				report: func(ip string, f bool) {
+					if ip != "10.0.0.1" {
+						t.Errorf("Reported IP = %s, want 10.0.0.1", ip)
+					}
+					reported, failed = true, f
+				},
+			}
+			r := httptest.NewRequest(http.MethodGet, test.url, nil)
+			if test.canceled {
+				ctx, cancel := context.WithCancel(r.Context())
+				cancel()
+				r = r.WithContext(ctx)
+			}
+			ot.RoundTrip(r)
+			if reported != test.wantReport || failed != test.wantFailed {
+				t.Errorf("Reported = %v, failed = %v, want %v, %v", reported, failed, test.wantReport, test.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/queue"
+)
+
+// maxRegistrationSize is the size limit of the registrations.
+const maxRegistrationSize = 4096
+
+// RegistrationHandler handles the registrations queue-proxies send to
+// announce the state of their pods. The registrations must be signed with
+// the token of the revision of the pod.
+type RegistrationHandler struct {
+	// Register records the registration, or returns an error if its pod
+	// can't be the one of its revision.
+	Register func(*queue.Registration) error
+	// Token returns the registration token of the revision.
+	Token       func(namespace, revision string) ([]byte, error)
+	NextHandler http.Handler
+}
+
+func (h *RegistrationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	val := r.Header.Get(network.RegistrationHeaderName)
+	if val == "" {
+		h.NextHandler.ServeHTTP(w, r)
+		return
+	}
+	if val != queue.Name {
+		http.Error(w, fmt.Sprintf("unexpected registration header value: %q", val), http.StatusBadRequest)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRegistrationSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read registration: %v", err), http.StatusBadRequest)
+		return
+	}
+	reg := &queue.Registration{}
+	if err := json.Unmarshal(body, reg); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode registration: %v", err), http.StatusBadRequest)
+		return
+	}
+	if reg.Namespace == "" || reg.Revision == "" || reg.Pod == "" {
+		http.Error(w, "registration must name the namespace, revision and pod", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.Token(reg.Namespace, reg.Revision)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get the registration token: %v", err), http.StatusForbidden)
+		return
+	}
+	if !queue.ValidRegistrationSignature(body, token, r.Header.Get(network.RegistrationSignatureHeaderName)) {
+		http.Error(w, "invalid registration signature", http.StatusForbidden)
+		return
+	}
+	if err := h.Register(reg); err != nil {
+		http.Error(w, fmt.Sprintf("failed to register: %v", err), http.StatusForbidden)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestRegistrationHandler(t *testing.T) {
+	token := []byte("token")
+	signed := func(body string) http.Header {
+		return mapToHeader(map[string]string{
+			network.RegistrationHeaderName:          queue.Name,
+			network.RegistrationSignatureHeaderName: queue.SignRegistration([]byte(body), token),
+		})
+	}
+	const (
+		podBody      = `{"namespace":"ns","revision":"rev","pod":"pod","podIP":"10.0.0.1","ready":true,"capacity":3}`
+		otherPodBody = `{"namespace":"ns","revision":"rev","pod":"other-pod","podIP":"10.0.0.2","ready":true}`
+		otherRevBody = `{"namespace":"ns","revision":"other-rev","pod":"pod","podIP":"10.0.0.1","ready":true}`
+	)
+
+	examples := []struct {
+		label          string
+		headers        http.Header
+		body           string
+		passed         bool
+		want           *queue.Registration
+		expectedStatus int
+	}{{
+		label:          "forward a normal request",
+		headers:        http.Header{},
+		body:           "hello",
+		passed:         true,
+		expectedStatus: http.StatusOK,
+	}, {
+		label:   "register a pod",
+		headers: signed(podBody),
+		body:    podBody,
+		want: &queue.Registration{
+			Namespace: "ns",
+			Revision:  "rev",
+			Pod:       "pod",
+			PodIP:     "10.0.0.1",
+			Ready:     true,
+			Capacity:  3,
+		},
+		expectedStatus: http.StatusOK,
+	}, {
+		label:          "registration from another component",
+		headers:        mapToHeader(map[string]string{network.RegistrationHeaderName: "not-queue"}),
+		body:           `{"namespace":"ns","revision":"rev","pod":"pod"}`,
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		label:          "unsigned registration",
+		headers:        mapToHeader(map[string]string{network.RegistrationHeaderName: queue.Name}),
+		body:           podBody,
+		expectedStatus: http.StatusForbidden,
+	}, {
+		label:          "registration signed for another body",
+		headers:        signed(podBody),
+		body:           strings.Replace(podBody, `"ready":true`, `"draining":true`, 1),
+		expectedStatus: http.StatusForbidden,
+	}, {
+		label:          "registration of an unknown revision",
+		headers:        signed(otherRevBody),
+		body:           otherRevBody,
+		expectedStatus: http.StatusForbidden,
+	}, {
+		label:          "registration of a pod not of the revision",
+		headers:        signed(otherPodBody),
+		body:           otherPodBody,
+		expectedStatus: http.StatusForbidden,
+	}, {
+		label:          "malformed registration",
+		headers:        mapToHeader(map[string]string{network.RegistrationHeaderName: queue.Name}),
+		body:           `{"namespace":`,
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		label:          "registration without a pod",
+		headers:        mapToHeader(map[string]string{network.RegistrationHeaderName: queue.Name}),
+		body:           `{"namespace":"ns","revision":"rev"}`,
+		expectedStatus: http.StatusBadRequest,
+	}}
+
+	for _, e := range examples {
+		t.Run(e.label, func(t *testing.T) {
+			wasPassed := false
+			var got *queue.Registration
+			handler := RegistrationHandler{
+				Register: func(reg *queue.Registration) error {
+					if reg.Pod != "pod" {
+						return errors.New("unknown pod")
+					}
+					got = reg
+					return nil
+				},
+				Token: func(namespace, revision string) ([]byte, error) {
+					if namespace != "ns" || revision != "rev" {
+						return nil, errors.New("unknown revision")
+					}
+					return token, nil
+				},
+				NextHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					wasPassed = true
+				}),
+			}
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader(e.body))
+			req.Header = e.headers
+			handler.ServeHTTP(resp, req)
+
+			if wasPassed != e.passed {
+				t.Errorf("Request passed = %v, want %v", wasPassed, e.passed)
+			}
+			if resp.Code != e.expectedStatus {
+				t.Errorf("Status = %d, want %d", resp.Code, e.expectedStatus)
+			}
+			if !cmp.Equal(got, e.want) {
+				t.Errorf("Registration (-want, +got) = %s", cmp.Diff(e.want, got))
+			}
+		})
+	}
+}
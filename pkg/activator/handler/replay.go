@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
+)
+
+const (
+	// maxReplays is the number of times a request is replayed after the
+	// first attempt failed.
+	maxReplays = 2
+
+	// replayBackoff is the time waited before the first replay, it doubles
+	// with every further one.
+	replayBackoff = 50 * time.Millisecond
+)
+
+// requestBufferSize returns the number of bytes of request bodies to buffer
+// for the given revision, or 0 if the revision did not opt into replays.
+func requestBufferSize(rev *v1alpha1.Revision) int64 {
+	v, ok := rev.Annotations[serving.RequestBufferSizeAnnotationKey]
+	if !ok {
+		return 0
+	}
+	q, err := resource.ParseQuantity(v)
+	if err != nil {
+		return 0
+	}
+	if size := q.Value(); size > 0 && size <= serving.MaxRequestBufferSize {
+		return size
+	}
+	return 0
+}
+
+// bufferBody reads the body of r into memory if it is no larger than limit,
+// and makes it available through r.GetBody so that r can be sent again.
+// It returns whether r can be replayed.  Bodies over the limit are streamed
+// as before, the bytes already read are put back in front of them.
+func bufferBody(r *http.Request, limit int64) bool {
+	if r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+	if r.ContentLength > limit {
+		return false
+	}
+	buf, err := ioutil.ReadAll(io.LimitReader(r.Body, limit+1))
+	if err != nil || int64(len(buf)) > limit {
+		r.Body = &prefixedBody{
+			Reader: io.MultiReader(bytes.NewReader(buf), &errReader{err: err, body: r.Body}),
+			Closer: r.Body,
+		}
+		return false
+	}
+	r.ContentLength = int64(len(buf))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(buf)), nil
+	}
+	r.Body, _ = r.GetBody()
+	return true
+}
+
+// prefixedBody is a request body whose first bytes have been read already.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// errReader returns err if reading the body failed while buffering it, and
+// the rest of body otherwise.
+type errReader struct {
+	err  error
+	body io.Reader
+}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	return e.body.Read(p)
+}
+
+// replayingTransport is an http.RoundTripper that replays a request through
+// replay when primary failed to return a response, e.g. because the
+// connection to a pod that is shutting down was refused or reset.  Replays
+// carry the ReplayHeaderName header, as the failed attempt may have reached
+// the pod already.  Requests with a body need r.GetBody to be replayed.
+type replayingTransport struct {
+	primary http.RoundTripper
+	replay  http.RoundTripper
+	backoff time.Duration
+	// retarget returns the host to replay a request to after it failed on
+	// the given host, so that it isn't sent to the same pod again.  If nil,
+	// replays go to the same host.
+	retarget func(failed string) string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *replayingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := rt.primary.RoundTrip(r)
+	backoff := rt.backoff
+	host := r.URL.Host
+	for attempt := 1; err != nil && attempt <= maxReplays; attempt++ {
+		if r.Body != nil && r.Body != http.NoBody && r.GetBody == nil {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		replay := r.Clone(r.Context())
+		if r.GetBody != nil {
+			body, gerr := r.GetBody()
+			if gerr != nil {
+				break
+			}
+			replay.Body = body
+		}
+		if rt.retarget != nil {
+			host = rt.retarget(host)
+		}
+		replay.URL.Host = host
+		replay.Header.Set(network.ReplayHeaderName, strconv.Itoa(attempt))
+		resp, err = rt.replay.RoundTrip(replay)
+	}
+	return resp, err
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/network"
+)
+
+func TestRequestBufferSize(t *testing.T) {
+	rev := revision(testNamespace, testRevName)
+	if got := requestBufferSize(rev); got != 0 {
+		t.Errorf("requestBufferSize() = %d without annotation, want 0", got)
+	}
+	rev.Annotations = map[string]string{serving.RequestBufferSizeAnnotationKey: "64Ki"}
+	if got, want := requestBufferSize(rev), int64(64<<10); got != want {
+		t.Errorf("requestBufferSize() = %d, want %d", got, want)
+	}
+	rev.Annotations = map[string]string{serving.RequestBufferSizeAnnotationKey: "1Gi"}
+	if got := requestBufferSize(rev); got != 0 {
+		t.Errorf("requestBufferSize() = %d over the maximum, want 0", got)
+	}
+}
+
+func TestBufferBody(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *http.Request
+		want bool
+	}{{
+		name: "no body",
+		req:  httptest.NewRequest(http.MethodPost, "http://example.com", nil),
+		want: true,
+	}, {
+		name: "small body",
+		req:  httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("body")),
+		want: true,
+	}, {
+		name: "large body",
+		req:  httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("a much larger body")),
+	}, {
+		name: "large body of unknown length",
+		req: func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("a much larger body"))
+			r.ContentLength = -1
+			return r
+		}(),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var want string
+			if test.req.Body != nil {
+				b, _ := ioutil.ReadAll(test.req.Body)
+				want = string(b)
+				test.req.Body = ioutil.NopCloser(strings.NewReader(want))
+			}
+			if got := bufferBody(test.req, 8); got != test.want {
+				t.Errorf("bufferBody() = %v, want %v", got, test.want)
+			}
+			// Whether buffered or not, the body is left intact.
+			if test.req.Body != nil {
+				got, err := ioutil.ReadAll(test.req.Body)
+				if err != nil {
+					t.Fatalf("Error reading body: %v", err)
+				}
+				if string(got) != want {
+					t.Errorf("Body = %q, want %q", got, want)
+				}
+			}
+			if test.req.GetBody != nil {
+				body, _ := test.req.GetBody()
+				if got, _ := ioutil.ReadAll(body); string(got) != want {
+					t.Errorf("GetBody() = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+// failTimes returns a RoundTripper that fails n times, then echoes the body
+// and the replay header of the request.
+func failTimes(n int) http.RoundTripper {
+	return network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if n > 0 {
+			n--
+			return nil, errors.New("connection refused")
+		}
+		rec := httptest.NewRecorder()
+		rec.Header().Set(network.ReplayHeaderName, r.Header.Get(network.ReplayHeaderName))
+		if r.Body != nil {
+			b, _ := ioutil.ReadAll(r.Body)
+			rec.Write(b)
+		}
+		return rec.Result(), nil
+	})
+}
+
+func TestReplayingTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		failures   int
+		buffered   bool
+		wantReplay string
+		wantErr    bool
+	}{{
+		name:     "first attempt succeeds",
+		buffered: true,
+	}, {
+		name:       "replayed once",
+		failures:   1,
+		buffered:   true,
+		wantReplay: "1",
+	}, {
+		name:       "replayed twice",
+		failures:   2,
+		buffered:   true,
+		wantReplay: "2",
+	}, {
+		name:     "out of replays",
+		failures: 3,
+		buffered: true,
+		wantErr:  true,
+	}, {
+		name:     "body not buffered",
+		failures: 1,
+		wantErr:  true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "http://example.com", strings.NewReader("body"))
+			if test.buffered {
+				bufferBody(r, 64)
+			}
+			rt := failTimes(test.failures)
+			resp, err := (&replayingTransport{primary: rt, replay: rt}).RoundTrip(r)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("RoundTrip() = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			if got := resp.Header.Get(network.ReplayHeaderName); got != test.wantReplay {
+				t.Errorf("%s = %q, want %q", network.ReplayHeaderName, got, test.wantReplay)
+			}
+			if got, _ := ioutil.ReadAll(resp.Body); string(got) != "body" {
+				t.Errorf("Body = %q, want %q", got, "body")
+			}
+		})
+	}
+}
+
+func TestReplayingTransportRetarget(t *testing.T) {
+	const failed, other = "10.0.0.1:8012", "10.0.0.2:8012"
+	var hosts []string
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host)
+		if r.URL.Host == failed {
+			return nil, errors.New("connection refused")
+		}
+		return httptest.NewRecorder().Result(), nil
+	})
+	retarget := func(host string) string {
+		if host == failed {
+			return other
+		}
+		return failed
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://"+failed, nil)
+	resp, err := (&replayingTransport{primary: rt, replay: rt, retarget: retarget}).RoundTrip(r)
+	if err != nil {
+		t.Fatalf("RoundTrip() = %v", err)
+	}
+	resp.Body.Close()
+	if want := []string{failed, other}; !cmp.Equal(hosts, want) {
+		t.Errorf("Hosts = %v, want %v", hosts, want)
+	}
+	if r.URL.Host != failed {
+		t.Errorf("Request host = %q, want %q", r.URL.Host, failed)
+	}
+}
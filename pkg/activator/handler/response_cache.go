@@ -0,0 +1,294 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// maxCachedBodyBytes bounds the size of a single cached response body.
+	// Larger responses are proxied as usual but not cached.
+	maxCachedBodyBytes = 1 << 20
+	// maxCacheEntries bounds the number of responses the cache holds at once.
+	maxCacheEntries = 1000
+)
+
+// cacheKeyHeaders are the request headers that select between different
+// representations of the same resource and hence are part of the cache key.
+// Responses that vary on any other header are not cached.
+var cacheKeyHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language"}
+
+// responseCacheTTL returns the TTL with which responses of the given revision
+// may be cached, or 0 if the revision did not opt into caching.
+func responseCacheTTL(rev *v1alpha1.Revision) time.Duration {
+	v, ok := rev.Annotations[serving.ResponseCacheTTLAnnotationKey]
+	if !ok {
+		return 0
+	}
+	ttl, err := time.ParseDuration(v)
+	if err != nil || ttl < 0 || ttl > serving.MaxResponseCacheTTL {
+		return 0
+	}
+	return ttl
+}
+
+// cacheKey returns the key under which the response to r is cached, and
+// whether r may be answered from the cache at all.  Only plain GET requests
+// that carry no credentials are considered.
+func cacheKey(revID activator.RevisionID, r *http.Request) (string, bool) {
+	if r.Method != http.MethodGet {
+		return "", false
+	}
+	for _, h := range []string{"Authorization", "Cookie", "Range", "Upgrade"} {
+		if r.Header.Get(h) != "" {
+			return "", false
+		}
+	}
+	var b strings.Builder
+	b.WriteString(revID.String())
+	b.WriteByte(' ')
+	b.WriteString(r.Host)
+	b.WriteString(r.URL.RequestURI())
+	for _, h := range cacheKeyHeaders {
+		b.WriteByte('\n')
+		b.WriteString(strings.Join(r.Header[h], ","))
+	}
+	return b.String(), true
+}
+
+// cachedResponse is a response stored in the responseCache.
+type cachedResponse struct {
+	code    int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// serve writes the cached response to w.
+func (cr *cachedResponse) serve(w http.ResponseWriter) {
+	for k, v := range cr.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(cr.code)
+	w.Write(cr.body)
+}
+
+// responseCache is a small in-memory cache of GET responses.  Besides caching,
+// it coalesces concurrent requests for the same key: while the first request is
+// in flight, identical requests wait for its response instead of all queueing
+// for the revision's pods.
+type responseCache struct {
+	mu       sync.Mutex
+	entries  map[string]*cachedResponse
+	inflight map[string]chan struct{}
+
+	// now is stubbed out in tests.
+	now func() time.Time
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{
+		entries:  make(map[string]*cachedResponse),
+		inflight: make(map[string]chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// lookup returns the cached response for key, if any.  Otherwise, it reports
+// whether the caller is the leader for key, in which case it must proxy the
+// request and call done once it has the response.  Callers that are neither
+// served from the cache nor the leader proxy the request without caching it.
+func (c *responseCache) lookup(ctx context.Context, key string) (resp *cachedResponse, leader bool) {
+	c.mu.Lock()
+	if resp := c.getLocked(key); resp != nil {
+		c.mu.Unlock()
+		return resp, false
+	}
+	ch, ok := c.inflight[key]
+	if !ok {
+		c.inflight[key] = make(chan struct{})
+		c.mu.Unlock()
+		return nil, true
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ch:
+	case <-ctx.Done():
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.getLocked(key), false
+}
+
+// done records the leader's response for key, if it is cacheable, and
+// releases the requests waiting for it.
+func (c *responseCache) done(key string, resp *cachedResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if resp != nil {
+		resp.expires = c.now().Add(ttl)
+		if len(c.entries) >= maxCacheEntries {
+			c.evictExpiredLocked()
+		}
+		if len(c.entries) < maxCacheEntries {
+			c.entries[key] = resp
+		}
+	}
+	if ch, ok := c.inflight[key]; ok {
+		close(ch)
+		delete(c.inflight, key)
+	}
+}
+
+func (c *responseCache) getLocked(key string) *cachedResponse {
+	resp, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if !c.now().Before(resp.expires) {
+		delete(c.entries, key)
+		return nil
+	}
+	return resp
+}
+
+func (c *responseCache) evictExpiredLocked() {
+	now := c.now()
+	for k, resp := range c.entries {
+		if !now.Before(resp.expires) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// cacheRecorder is an http.ResponseWriter that passes the response through to
+// the wrapped writer while keeping a copy of it for the responseCache.
+type cacheRecorder struct {
+	http.ResponseWriter
+
+	code     int
+	wrote    bool
+	body     bytes.Buffer
+	overflow bool
+}
+
+var _ http.Flusher = (*cacheRecorder)(nil)
+
+func newCacheRecorder(w http.ResponseWriter) *cacheRecorder {
+	return &cacheRecorder{
+		ResponseWriter: w,
+		code:           http.StatusOK,
+	}
+}
+
+// WriteHeader records the response code and sends it to the client.
+func (cr *cacheRecorder) WriteHeader(code int) {
+	cr.code = code
+	cr.wrote = true
+	cr.ResponseWriter.WriteHeader(code)
+}
+
+// Write records up to maxCachedBodyBytes of the body and sends it to the client.
+func (cr *cacheRecorder) Write(p []byte) (int, error) {
+	cr.wrote = true
+	if !cr.overflow {
+		if cr.body.Len()+len(p) > maxCachedBodyBytes {
+			cr.overflow = true
+			cr.body.Reset()
+		} else {
+			cr.body.Write(p)
+		}
+	}
+	return cr.ResponseWriter.Write(p)
+}
+
+// Flush flushes the buffer to the client, if the wrapped writer supports it.
+func (cr *cacheRecorder) Flush() {
+	if f, ok := cr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// response returns the recorded response, or nil if it must not be cached.
+func (cr *cacheRecorder) response() *cachedResponse {
+	if !cr.wrote || cr.code != http.StatusOK || cr.overflow {
+		return nil
+	}
+	header := cr.Header()
+	if len(header["Set-Cookie"]) > 0 {
+		return nil
+	}
+	for _, v := range header["Cache-Control"] {
+		v = strings.ToLower(v)
+		if strings.Contains(v, "no-store") || strings.Contains(v, "no-cache") || strings.Contains(v, "private") {
+			return nil
+		}
+	}
+	if !variesOnKeyHeaders(header) {
+		return nil
+	}
+	return &cachedResponse{
+		code:   cr.code,
+		header: cloneHeader(header),
+		body:   append([]byte(nil), cr.body.Bytes()...),
+	}
+}
+
+// variesOnKeyHeaders returns whether all the request headers listed in the
+// Vary header of the response are part of the cache key.
+func variesOnKeyHeaders(header http.Header) bool {
+	for _, v := range header["Vary"] {
+		for _, h := range strings.Split(v, ",") {
+			h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+			if h == "" {
+				continue
+			}
+			keyed := false
+			for _, k := range cacheKeyHeaders {
+				if h == k {
+					keyed = true
+					break
+				}
+			}
+			if !keyed {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func cloneHeader(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2019 The Knative Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	activatortest "knative.dev/serving/pkg/activator/testing"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestResponseCacheTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		anns map[string]string
+		want time.Duration
+	}{{
+		name: "no annotation",
+	}, {
+		name: "valid",
+		anns: map[string]string{serving.ResponseCacheTTLAnnotationKey: "1s"},
+		want: time.Second,
+	}, {
+		name: "invalid",
+		anns: map[string]string{serving.ResponseCacheTTLAnnotationKey: "soon"},
+	}, {
+		name: "too large",
+		anns: map[string]string{serving.ResponseCacheTTLAnnotationKey: "1h"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := revision(testNamespace, testRevName)
+			rev.Annotations = test.anns
+			if got := responseCacheTTL(rev); got != test.want {
+				t.Errorf("responseCacheTTL() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	revID := activator.RevisionID{Namespace: testNamespace, Name: testRevName}
+	get := func(opts ...func(*http.Request)) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://example.com/path?q=1", nil)
+		for _, opt := range opts {
+			opt(r)
+		}
+		return r
+	}
+	withHeader := func(k, v string) func(*http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set(k, v)
+		}
+	}
+
+	base, ok := cacheKey(revID, get())
+	if !ok {
+		t.Fatal("cacheKey(GET) = false, want true")
+	}
+	if other, _ := cacheKey(revID, get(withHeader("Accept", "text/html"))); other == base {
+		t.Error("Accept header does not affect the cache key")
+	}
+	if other, _ := cacheKey(revID, get(withHeader("User-Agent", "curl"))); other != base {
+		t.Error("User-Agent header affects the cache key")
+	}
+	if other, _ := cacheKey(activator.RevisionID{Namespace: testNamespace, Name: testRevNameOther}, get()); other == base {
+		t.Error("Revision does not affect the cache key")
+	}
+
+	for _, r := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "http://example.com/path", nil),
+		get(withHeader("Authorization", "Bearer foo")),
+		get(withHeader("Cookie", "session=foo")),
+		get(withHeader("Upgrade", "websocket")),
+	} {
+		if _, ok := cacheKey(revID, r); ok {
+			t.Errorf("cacheKey(%s %v) = true, want false", r.Method, r.Header)
+		}
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	c := newResponseCache()
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if _, leader := c.lookup(ctx, "key"); !leader {
+		t.Fatal("First lookup is not the leader")
+	}
+	c.done("key", &cachedResponse{code: http.StatusOK, body: []byte("hi")}, time.Second)
+
+	if resp, _ := c.lookup(ctx, "key"); resp == nil || string(resp.body) != "hi" {
+		t.Errorf("lookup() = %v, want cached response", resp)
+	}
+
+	now = now.Add(time.Second)
+	resp, leader := c.lookup(ctx, "key")
+	if resp != nil {
+		t.Errorf("lookup() = %v after expiry, want nil", resp)
+	}
+	if !leader {
+		t.Error("lookup() after expiry is not the leader")
+	}
+}
+
+func TestCacheRecorder(t *testing.T) {
+	tests := []struct {
+		name   string
+		write  func(w http.ResponseWriter)
+		cached bool
+	}{{
+		name: "ok",
+		write: func(w http.ResponseWriter) {
+			w.Write([]byte(wantBody))
+		},
+		cached: true,
+	}, {
+		name:  "nothing written",
+		write: func(w http.ResponseWriter) {},
+	}, {
+		name: "error",
+		write: func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusInternalServerError)
+		},
+	}, {
+		name: "no-store",
+		write: func(w http.ResponseWriter) {
+			w.Header().Set("Cache-Control", "no-store")
+			w.Write([]byte(wantBody))
+		},
+	}, {
+		name: "set-cookie",
+		write: func(w http.ResponseWriter) {
+			w.Header().Set("Set-Cookie", "session=foo")
+			w.Write([]byte(wantBody))
+		},
+	}, {
+		name: "vary on key headers",
+		write: func(w http.ResponseWriter) {
+			w.Header().Set("Vary", "accept-encoding, Accept")
+			w.Write([]byte(wantBody))
+		},
+		cached: true,
+	}, {
+		name: "vary on other headers",
+		write: func(w http.ResponseWriter) {
+			w.Header().Add("Vary", "Accept")
+			w.Header().Add("Vary", "User-Agent")
+			w.Write([]byte(wantBody))
+		},
+	}, {
+		name: "vary on everything",
+		write: func(w http.ResponseWriter) {
+			w.Header().Set("Vary", "*")
+			w.Write([]byte(wantBody))
+		},
+	}, {
+		name: "too large",
+		write: func(w http.ResponseWriter) {
+			w.Write([]byte(strings.Repeat("x", maxCachedBodyBytes+1)))
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cr := newCacheRecorder(rec)
+			test.write(cr)
+
+			resp := cr.response()
+			if got := resp != nil; got != test.cached {
+				t.Fatalf("cached = %v, want %v", got, test.cached)
+			}
+			if resp != nil && string(resp.body) != rec.Body.String() {
+				t.Errorf("cached body = %q, want %q", resp.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestActivationHandlerResponseCache(t *testing.T) {
+	const requests = 10
+
+	var proxied int32
+	releaseCh := make(chan struct{})
+	rt := network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		if r.Header.Get(network.ProbeHeaderName) != "" {
+			network.SignProbeResponse(rec.Header(), r, activatortest.DefaultProbeKey, queue.Name)
+			rec.WriteString(queue.Name)
+			return rec.Result(), nil
+		}
+		atomic.AddInt32(&proxied, 1)
+		<-releaseCh
+		rec.WriteString(wantBody)
+		return rec.Result(), nil
+	})
+
+	rev := revision(testNamespace, testRevName)
+	rev.Annotations = map[string]string{serving.ResponseCacheTTLAnnotationKey: "1m"}
+
+	breakerParams := queue.BreakerParams{QueueDepth: 100, MaxConcurrency: 100, InitialCapacity: 100}
+	throttler := activator.NewThrottler(
+		breakerParams,
+		endpointsInformer(endpoints(testNamespace, testRevName, breakerParams.InitialCapacity)),
+		sksLister(sks(testNamespace, testRevName)),
+		revisionLister(rev),
+		TestLogger(t))
+
+	handler := (New(TestLogger(t), &fakeReporter{}, throttler,
+		revisionLister(rev),
+		serviceLister(service(testNamespace, testRevName, "http")),
+		sksLister(sks(testNamespace, testRevName)),
+		secretLister(secret(testNamespace, testRevName)),
+	)).(*activationHandler)
+	handler.transport = rt
+	handler.probeTransport = rt
+
+	var wg sync.WaitGroup
+	resps := make(chan *httptest.ResponseRecorder, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+			req.Header.Set(activator.RevisionHeaderName, testRevName)
+			handler.ServeHTTP(resp, req)
+			resps <- resp
+		}()
+	}
+
+	// Let all the requests pile up behind the first one before releasing it.
+	time.Sleep(100 * time.Millisecond)
+	close(releaseCh)
+	wg.Wait()
+	close(resps)
+
+	for resp := range resps {
+		if resp.Code != http.StatusOK || resp.Body.String() != wantBody {
+			t.Errorf("Response = %d %q, want %d %q", resp.Code, resp.Body.String(), http.StatusOK, wantBody)
+		}
+	}
+	if got := atomic.LoadInt32(&proxied); got != 1 {
+		t.Errorf("Proxied requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	pkghttp "knative.dev/serving/pkg/http"
+)
+
+// NewStickySplitHandler creates a handler that picks the revision of the
+// requests the ingress sends through the activator along with a traffic
+// split, consistently by the cookie or the header asked for, before passing
+// them on to next. The revision picked must be a traffic target of the
+// Route the ingress names.
+func NewStickySplitHandler(routeLister servinglisters.RouteLister, next http.Handler) *StickySplitHandler {
+	return &StickySplitHandler{routeLister: routeLister, nextHandler: next}
+}
+
+// StickySplitHandler keeps the users of a Route whose traffic is split
+// between several revisions on the same one.
+type StickySplitHandler struct {
+	routeLister servinglisters.RouteLister
+	nextHandler http.Handler
+}
+
+func (h *StickySplitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	splits := pkghttp.LastHeaderValue(r.Header, activator.SplitsHeaderName)
+	if splits == "" {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+	sticky := pkghttp.LastHeaderValue(r.Header, activator.StickyHeaderName)
+	r.Header.Del(activator.SplitsHeaderName)
+	r.Header.Del(activator.StickyHeaderName)
+
+	split, err := parseSplit(splits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	revision := split.pick(stickyKey(w, r, sticky))
+	if err := checkRouteTarget(h.routeLister, r, revision); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Header.Set(activator.RevisionHeaderName, revision)
+	h.nextHandler.ServeHTTP(w, r)
+}
+
+// checkRouteTarget returns an error unless revision is a traffic target of
+// the Route the ingress named in the headers of r.
+func checkRouteTarget(routeLister servinglisters.RouteLister, r *http.Request, revision string) error {
+	route, err := headerRoute(routeLister, r)
+	if err != nil {
+		return err
+	}
+	for _, t := range route.Status.Traffic {
+		if t.RevisionName == revision {
+			return nil
+		}
+	}
+	return fmt.Errorf("revision %q is not a traffic target of route %q", revision, route.Name)
+}
+
+// headerRoute returns the Route the ingress named in the headers of r.
+func headerRoute(routeLister servinglisters.RouteLister, r *http.Request) (*v1alpha1.Route, error) {
+	namespace := pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderNamespace)
+	name := pkghttp.LastHeaderValue(r.Header, activator.RouteHeaderName)
+	if name == "" {
+		return nil, errors.New("no route named in the request")
+	}
+	route, err := routeLister.Routes(namespace).Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get route %q: %v", name, err)
+	}
+	return route, nil
+}
+
+// stickyKey returns the key sticky assigns the user of r by, or "" when
+// r doesn't carry the header asked for.  Users without a cookie are given
+// one with a new key.
+func stickyKey(w http.ResponseWriter, r *http.Request, sticky string) string {
+	if sticky != serving.StickySplitCookie {
+		return r.Header.Get(strings.TrimPrefix(sticky, serving.StickySplitHeader+":"))
+	}
+	if c, err := r.Cookie(activator.StickyCookieName); err == nil && c.Value != "" {
+		return c.Value
+	}
+	key := strconv.FormatUint(rand.Uint64(), 36)
+	http.SetCookie(w, &http.Cookie{
+		Name:     activator.StickyCookieName,
+		Value:    key,
+		Path:     "/",
+		HttpOnly: true,
+	})
+	return key
+}
+
+// revisionSplit is the share of the traffic split sent to a revision.
+type revisionSplit struct {
+	revision string
+	percent  int
+}
+
+// trafficSplit is a traffic split between revisions.
+type trafficSplit struct {
+	splits []revisionSplit
+	total  int
+}
+
+// parseSplit parses a traffic split of name=percent pairs.
+func parseSplit(splits string) (*trafficSplit, error) {
+	ts := &trafficSplit{}
+	for _, s := range strings.Split(splits, ",") {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid split %q", s)
+		}
+		percent, err := strconv.Atoi(kv[1])
+		if err != nil || percent < 0 {
+			return nil, fmt.Errorf("invalid split %q", s)
+		}
+		ts.splits = append(ts.splits, revisionSplit{revision: kv[0], percent: percent})
+		ts.total += percent
+	}
+	if ts.total == 0 {
+		return nil, fmt.Errorf("invalid splits %q", splits)
+	}
+	return ts, nil
+}
+
+// pick returns the revision whose share of the buckets the hash of key falls
+// in.  Requests without a key are split at random.
+func (ts *trafficSplit) pick(key string) string {
+	var bucket int
+	if key == "" {
+		bucket = rand.Intn(ts.total)
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		bucket = int(h.Sum32() % uint32(ts.total))
+	}
+	for _, s := range ts.splits {
+		if bucket < s.percent {
+			return s.revision
+		}
+		bucket -= s.percent
+	}
+	// Unreachable, the buckets add up to total.
+	return ts.splits[len(ts.splits)-1].revision
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+	servinginformers "knative.dev/serving/pkg/client/informers/externalversions"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+)
+
+// routeLister returns a lister of the Route named route in testNamespace,
+// whose traffic targets the given revisions.
+func routeLister(route string, revisions ...string) servinglisters.RouteLister {
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      route,
+		},
+	}
+	for _, rev := range revisions {
+		r.Status.Traffic = append(r.Status.Traffic, v1alpha1.TrafficTarget{
+			TrafficTarget: v1beta1.TrafficTarget{RevisionName: rev},
+		})
+	}
+
+	fake := servingfake.NewSimpleClientset(r)
+	informer := servinginformers.NewSharedInformerFactory(fake, 0)
+	routes := informer.Serving().V1alpha1().Routes()
+	routes.Informer().GetIndexer().Add(r)
+	return routes.Lister()
+}
+
+func TestStickySplitHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		splits       string
+		sticky       string
+		header       http.Header
+		wantCode     int
+		wantRevision string
+		wantCookie   bool
+		noRoute      bool
+	}{{
+		name:         "no split",
+		header:       http.Header{activator.RevisionHeaderName: []string{"rev-a"}},
+		wantCode:     http.StatusOK,
+		wantRevision: "rev-a",
+	}, {
+		name:         "single revision",
+		splits:       "rev-a=0,rev-b=100",
+		sticky:       "header:X-User-Id",
+		wantCode:     http.StatusOK,
+		wantRevision: "rev-b",
+	}, {
+		name:         "by header",
+		splits:       "rev-a=50,rev-b=50",
+		sticky:       "header:X-User-Id",
+		header:       http.Header{"X-User-Id": []string{"alice"}},
+		wantCode:     http.StatusOK,
+		wantRevision: "rev-b",
+	}, {
+		name:         "by header, other user",
+		splits:       "rev-a=50,rev-b=50",
+		sticky:       "header:X-User-Id",
+		header:       http.Header{"X-User-Id": []string{"bob"}},
+		wantCode:     http.StatusOK,
+		wantRevision: "rev-a",
+	}, {
+		name:         "by cookie",
+		splits:       "rev-a=50,rev-b=50",
+		sticky:       "cookie",
+		header:       http.Header{"Cookie": []string{activator.StickyCookieName + "=alice"}},
+		wantCode:     http.StatusOK,
+		wantRevision: "rev-b",
+	}, {
+		name:       "by cookie, new user",
+		splits:     "rev-a=50,rev-b=50",
+		sticky:     "cookie",
+		wantCode:   http.StatusOK,
+		wantCookie: true,
+	}, {
+		name:     "invalid split",
+		splits:   "rev-a=50,rev-b",
+		sticky:   "cookie",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no traffic",
+		splits:   "rev-a=0",
+		sticky:   "cookie",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "revision not targeted by the route",
+		splits:   "rev-a=0,rev-x=100",
+		sticky:   "header:X-User-Id",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "no route",
+		splits:   "rev-a=50,rev-b=50",
+		sticky:   "header:X-User-Id",
+		noRoute:  true,
+		wantCode: http.StatusBadRequest,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotRevision string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRevision = r.Header.Get(activator.RevisionHeaderName)
+				if r.Header.Get(activator.SplitsHeaderName) != "" {
+					t.Error("Splits header was passed on")
+				}
+			})
+			handler := NewStickySplitHandler(routeLister("route", "rev-a", "rev-b"), next)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			if test.splits != "" {
+				req.Header.Set(activator.SplitsHeaderName, test.splits)
+				req.Header.Set(activator.StickyHeaderName, test.sticky)
+				req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+				if !test.noRoute {
+					req.Header.Set(activator.RouteHeaderName, "route")
+				}
+			}
+			handler.ServeHTTP(resp, req)
+
+			if got := resp.Code; got != test.wantCode {
+				t.Errorf("Code = %d, want: %d", got, test.wantCode)
+			}
+			if test.wantRevision != "" && gotRevision != test.wantRevision {
+				t.Errorf("Revision = %q, want: %q", gotRevision, test.wantRevision)
+			}
+			cookies := resp.Result().Cookies()
+			if gotCookie := len(cookies) == 1 && cookies[0].Name == activator.StickyCookieName; gotCookie != test.wantCookie {
+				t.Errorf("Cookies = %v, want cookie: %v", cookies, test.wantCookie)
+			}
+		})
+	}
+}
+
+func TestStickySplitHandlerConsistent(t *testing.T) {
+	handler := NewStickySplitHandler(routeLister("route", "rev-a", "rev-b", "rev-c"), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(activator.RevisionHeaderName)))
+	}))
+	serve := func(cookie string) (string, []*http.Cookie) {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.Header.Set(activator.SplitsHeaderName, "rev-a=20,rev-b=30,rev-c=50")
+		req.Header.Set(activator.StickyHeaderName, "cookie")
+		req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+		req.Header.Set(activator.RouteHeaderName, "route")
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: activator.StickyCookieName, Value: cookie})
+		}
+		handler.ServeHTTP(resp, req)
+		return resp.Body.String(), resp.Result().Cookies()
+	}
+
+	for i := 0; i < 20; i++ {
+		first, cookies := serve("")
+		if len(cookies) != 1 {
+			t.Fatalf("Cookies = %v, want one", cookies)
+		}
+		for j := 0; j < 5; j++ {
+			if got, _ := serve(cookies[0].Value); got != first {
+				t.Fatalf("Revision = %q, want sticky %q", got, first)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/apis/serving"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	pkghttp "knative.dev/serving/pkg/http"
+)
+
+// suspendedMessage is the body of the 404s sent when no page is configured.
+const suspendedMessage = "The service is suspended."
+
+// NewSuspensionHandler creates a handler that responds to the requests of
+// suspended Revisions with a 404 instead of passing them on to next, when
+// the config-suspension ConfigMap asks for it. It expects the config of the
+// activator in the context of the requests.
+func NewSuspensionHandler(rl servinglisters.RevisionLister, next http.Handler) *SuspensionHandler {
+	return &SuspensionHandler{
+		revisionLister: rl,
+		nextHandler:    next,
+	}
+}
+
+// SuspensionHandler keeps suspended Revisions from being scaled up again.
+type SuspensionHandler struct {
+	revisionLister servinglisters.RevisionLister
+	nextHandler    http.Handler
+}
+
+func (h *SuspensionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := activatorconfig.FromContext(r.Context()).Suspension
+	if !cfg.RespondNotFound || !h.isSuspended(r) {
+		h.nextHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if cfg.NotFoundPage == "" {
+		http.Error(w, suspendedMessage, http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	io.WriteString(w, cfg.NotFoundPage)
+}
+
+func (h *SuspensionHandler) isSuspended(r *http.Request) bool {
+	namespace := pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderNamespace)
+	name := pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderName)
+
+	// Failing to get the Revision is reported down the chain.
+	revision, err := h.revisionLister.Revisions(namespace).Get(name)
+	return err == nil && revision.Annotations[serving.SuspendedAnnotationKey] == "true"
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/apis/serving"
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+func TestSuspensionHandler(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	active := revision(testNamespace, "active")
+	suspended := revision(testNamespace, "suspended")
+	suspended.Annotations = map[string]string{
+		serving.SuspendedAnnotationKey: "true",
+	}
+	rl := revisionLister(active, suspended)
+
+	tests := []struct {
+		name     string
+		revision string
+		data     map[string]string
+		wantCode int
+		wantBody string
+	}{{
+		name:     "suspended, scaled up again",
+		revision: "suspended",
+		wantCode: http.StatusOK,
+		wantBody: "proxied",
+	}, {
+		name:     "active",
+		revision: "active",
+		data:     map[string]string{"respond-not-found": "true"},
+		wantCode: http.StatusOK,
+		wantBody: "proxied",
+	}, {
+		name:     "unknown revision",
+		revision: "unknown",
+		data:     map[string]string{"respond-not-found": "true"},
+		wantCode: http.StatusOK,
+		wantBody: "proxied",
+	}, {
+		name:     "suspended, not found",
+		revision: "suspended",
+		data:     map[string]string{"respond-not-found": "true"},
+		wantCode: http.StatusNotFound,
+		wantBody: suspendedMessage + "\n",
+	}, {
+		name:     "suspended, not found page",
+		revision: "suspended",
+		data: map[string]string{
+			"respond-not-found": "true",
+			"not-found-page":    "<h1>Suspended</h1>",
+		},
+		wantCode: http.StatusNotFound,
+		wantBody: "<h1>Suspended</h1>",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := activatorconfig.NewStore(logtesting.TestLogger(t))
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: tracingconfig.ConfigName},
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: suspensionconfig.SuspensionConfigName},
+				Data:       test.data,
+			})
+			store.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: activatorconfig.MaintenanceConfigName},
+			})
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("proxied"))
+			})
+			handler := store.HTTPMiddleware(NewSuspensionHandler(rl, next))
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set(activator.RevisionHeaderNamespace, testNamespace)
+			req.Header.Set(activator.RevisionHeaderName, test.revision)
+			handler.ServeHTTP(resp, req)
+
+			if got := resp.Code; got != test.wantCode {
+				t.Errorf("Code = %d, want: %d", got, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"knative.dev/pkg/logging/logkey"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/networking"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+	netinformers "knative.dev/serving/pkg/client/informers/externalversions/networking/v1alpha1"
+	netlisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/serving/pkg/logging"
+	"knative.dev/serving/pkg/network"
+
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// tcpDialTimeout bounds the time to connect to a pod of the revision, which
+// has capacity for the connection by then.
+const tcpDialTimeout = 5 * time.Second
+
+// TCPProxy accepts the connections to the revisions serving raw TCP streams,
+// while the activator is in their path, and forwards them to the revisions
+// once they have capacity. Raw connections carry nothing to tell the
+// revision they are meant for, so every revision gets a port of its own, the
+// one allocated to its SKS.
+type TCPProxy struct {
+	logger    *zap.SugaredLogger
+	throttler *activator.Throttler
+	reqChan   chan ReqEvent
+
+	serviceLister corev1listers.ServiceLister
+	sksLister     netlisters.ServerlessServiceLister
+
+	endpointTimeout time.Duration
+	// listen opens the listener for a port, swapped in tests.
+	listen func(port int32) (net.Listener, error)
+
+	mu        sync.Mutex
+	listeners map[int32]*tcpListener
+}
+
+type tcpListener struct {
+	net.Listener
+	rev activator.RevisionID
+}
+
+// NewTCPProxy creates a TCPProxy. It listens on no port until it is given
+// the SKS with WatchServerlessServices.
+func NewTCPProxy(l *zap.SugaredLogger, t *activator.Throttler, reqChan chan ReqEvent,
+	sl corev1listers.ServiceLister, sksL netlisters.ServerlessServiceLister) *TCPProxy {
+	return &TCPProxy{
+		logger:          l,
+		throttler:       t,
+		reqChan:         reqChan,
+		serviceLister:   sl,
+		sksLister:       sksL,
+		endpointTimeout: defaulTimeout,
+		listen: func(port int32) (net.Listener, error) {
+			return net.Listen("tcp", ":"+strconv.Itoa(int(port)))
+		},
+		listeners: make(map[int32]*tcpListener),
+	}
+}
+
+// WatchServerlessServices opens and closes the listeners of the revisions as
+// the ports allocated to their SKS come and go.
+func (p *TCPProxy) WatchServerlessServices(sksInformer netinformers.ServerlessServiceInformer) {
+	sksInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.sksUpdated,
+		UpdateFunc: func(_, newObj interface{}) { p.sksUpdated(newObj) },
+		DeleteFunc: p.sksDeleted,
+	})
+}
+
+// Close closes all of the listeners. The open connections are left to end
+// on their own.
+func (p *TCPProxy) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for port, l := range p.listeners {
+		l.Close()
+		delete(p.listeners, port)
+	}
+}
+
+func (p *TCPProxy) sksUpdated(obj interface{}) {
+	sks := obj.(*netv1alpha1.ServerlessService)
+	rev := activator.RevisionID{Namespace: sks.Namespace, Name: sks.Name}
+	port := sks.Status.ActivatorPort
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked(rev, port)
+	if port == 0 {
+		return
+	}
+	if l, ok := p.listeners[port]; ok {
+		if l.rev == rev {
+			return
+		}
+		// The port was handed over to another revision.
+		l.Close()
+		delete(p.listeners, port)
+	}
+
+	ln, err := p.listen(port)
+	if err != nil {
+		p.logger.Errorw("Failed to listen for "+rev.String(), zap.Int32("port", port), zap.Error(err))
+		return
+	}
+	l := &tcpListener{Listener: ln, rev: rev}
+	p.listeners[port] = l
+	go p.serve(l)
+}
+
+func (p *TCPProxy) sksDeleted(obj interface{}) {
+	sks, ok := obj.(*netv1alpha1.ServerlessService)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if sks, ok = tombstone.Obj.(*netv1alpha1.ServerlessService); !ok {
+			return
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked(activator.RevisionID{Namespace: sks.Namespace, Name: sks.Name}, 0)
+}
+
+// closeLocked closes the listeners of rev on other ports than keep.
+func (p *TCPProxy) closeLocked(rev activator.RevisionID, keep int32) {
+	for port, l := range p.listeners {
+		if l.rev == rev && port != keep {
+			l.Close()
+			delete(p.listeners, port)
+		}
+	}
+}
+
+func (p *TCPProxy) serve(l *tcpListener) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			// The listener was closed.
+			return
+		}
+		go p.handle(l.rev, c)
+	}
+}
+
+func (p *TCPProxy) handle(rev activator.RevisionID, c net.Conn) {
+	defer c.Close()
+	logger := logging.ForRevision(p.logger, rev.Namespace, rev.Name).With(zap.String(logkey.Key, rev.String()))
+
+	// The connection counts towards the concurrency of the revision until
+	// it is handed over to a pod, whose queue-proxy counts it from then on.
+	key := autoscaler.NewMetricKey(rev.Namespace, rev.Name)
+	p.reqChan <- ReqEvent{Key: key, EventType: ReqIn}
+	var once sync.Once
+	handedOver := func() {
+		once.Do(func() { p.reqChan <- ReqEvent{Key: key, EventType: ReqOut} })
+	}
+	defer handedOver()
+
+	ctx := context.Background()
+	if p.endpointTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.endpointTimeout)
+		defer cancel()
+	}
+	err := p.throttler.Try(ctx, rev, func() {
+		target, err := p.target(rev)
+		if err != nil {
+			logger.Errorw("Error while getting the target of the connection", zap.Error(err))
+			return
+		}
+		upstream, err := net.DialTimeout("tcp", target, tcpDialTimeout)
+		if err != nil {
+			logger.Errorw("Failed to connect to the revision", zap.Error(err))
+			return
+		}
+		handedOver()
+		network.Splice(c, upstream)
+	})
+	if err != nil {
+		logger.Errorw("Error proxying connection in the activator", zap.Error(err))
+	}
+}
+
+// target returns the address to connect to for rev: a Ready pod while the
+// throttler picks one, its private service otherwise.
+func (p *TCPProxy) target(rev activator.RevisionID) (string, error) {
+	if ip, ok := p.throttler.PodIP(rev); ok {
+		return net.JoinHostPort(ip, strconv.Itoa(networking.BackendTCPPort)), nil
+	}
+	sks, err := p.sksLister.ServerlessServices(rev.Namespace).Get(rev.Name)
+	if err != nil {
+		return "", err
+	}
+	svc, err := p.serviceLister.Services(rev.Namespace).Get(sks.Status.PrivateServiceName)
+	if err != nil {
+		return "", err
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.Name == networking.ServicePortNameTCP {
+			// Use the ClusterIP directly to elide DNS lookup.
+			return net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(port.Port))), nil
+		}
+	}
+	return "", errors.New("revision needs a TCP port")
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/queue"
+)
+
+// tcpEchoServer echoes back every line it reads.
+func tcpEchoServer(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				s := bufio.NewScanner(c)
+				for s.Scan() {
+					c.Write(append(s.Bytes(), '\n'))
+				}
+			}()
+		}
+	}()
+	return l
+}
+
+func TestTCPProxy(t *testing.T) {
+	echo := tcpEchoServer(t)
+	defer echo.Close()
+	_, echoPort, _ := net.SplitHostPort(echo.Addr().String())
+	port, _ := strconv.Atoi(echoPort)
+
+	// The private service of the revision leads to the echo server.
+	svc := service(testNamespace, testRevName, networking.ServicePortNameTCP)
+	svc.Spec.ClusterIP = "127.0.0.1"
+	svc.Spec.Ports[0].Port = int32(port)
+	revSKS := sks(testNamespace, testRevName)
+
+	throttler := activator.NewThrottler(
+		queue.BreakerParams{QueueDepth: 10, MaxConcurrency: 10, InitialCapacity: 0},
+		endpointsInformer(endpoints(testNamespace, testRevName, 1)),
+		sksLister(revSKS),
+		revisionLister(revision(testNamespace, testRevName)),
+		TestLogger(t))
+	reqChan := make(chan ReqEvent, 10)
+	p := NewTCPProxy(TestLogger(t), throttler, reqChan, serviceLister(svc), sksLister(revSKS))
+	defer p.Close()
+
+	// The activator ports are swapped for random ones.
+	addrs := make(map[int32]string)
+	p.listen = func(port int32) (net.Listener, error) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err == nil {
+			addrs[port] = l.Addr().String()
+		}
+		return l, err
+	}
+
+	tcpSKS := revSKS.DeepCopy()
+	tcpSKS.Spec.ProtocolType = networking.ProtocolTCP
+	tcpSKS.Status.ActivatorPort = networking.ActivatorTCPPortMin
+	p.sksUpdated(tcpSKS)
+	addr, ok := addrs[networking.ActivatorTCPPortMin]
+	if !ok {
+		t.Fatal("No listener for the activator port of the SKS")
+	}
+
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.Write([]byte("CONNECT\n")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if got, err := bufio.NewReader(c).ReadString('\n'); err != nil || got != "CONNECT\n" {
+		t.Errorf("ReadString() = %q, %v, want %q", got, err, "CONNECT\n")
+	}
+
+	// The activator stops counting the connection once it reaches a pod.
+	key := autoscaler.NewMetricKey(testNamespace, testRevName)
+	for _, want := range []ReqEvent{{Key: key, EventType: ReqIn}, {Key: key, EventType: ReqOut}} {
+		select {
+		case got := <-reqChan:
+			if got != want {
+				t.Errorf("Event = %v, want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %v", want)
+		}
+	}
+
+	// Moving the SKS to another port closes the first listener.
+	tcpSKS.Status.ActivatorPort++
+	p.sksUpdated(tcpSKS)
+	if _, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		t.Error("The previous port of the SKS is still open")
+	}
+	if _, ok := addrs[networking.ActivatorTCPPortMin+1]; !ok {
+		t.Error("No listener for the new activator port of the SKS")
+	}
+
+	// So does deleting it.
+	addr = addrs[networking.ActivatorTCPPortMin+1]
+	p.sksDeleted(tcpSKS)
+	if _, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		t.Error("The port of the deleted SKS is still open")
+	}
+}
+
+func TestTCPProxyHTTPRevision(t *testing.T) {
+	p := NewTCPProxy(TestLogger(t), nil, nil, serviceLister(), sksLister())
+	p.listen = func(port int32) (net.Listener, error) {
+		t.Errorf("Unexpected listener on port %d", port)
+		return nil, nil
+	}
+	p.sksUpdated(sks(testNamespace, testRevName))
+	if len(p.listeners) != 0 {
+		t.Errorf("Listeners = %v, want none", p.listeners)
+	}
+}
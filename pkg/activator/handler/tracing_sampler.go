@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+
+	"go.opencensus.io/trace"
+
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/tracing"
+)
+
+// NewTracingSampler returns the sampler of the traces of the requests to a
+// Revision: the sample rate set for its namespace or for itself in the
+// config-tracing ConfigMap, or in its TracingSampleRateAnnotationKey
+// annotation, applies in place of the global one. It expects the config of
+// the activator in the context of the requests.
+// The zipkin endpoint overrides only apply in the queue-proxies, the
+// activator sends all of its traces to the global one.
+func NewTracingSampler(rl servinglisters.RevisionLister) func(*http.Request) trace.Sampler {
+	return func(r *http.Request) trace.Sampler {
+		namespace := pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderNamespace)
+		name := pkghttp.LastHeaderValue(r.Header, activator.RevisionHeaderName)
+
+		cfg := activatorconfig.FromContext(r.Context()).Tracing.ForRevision(namespace, name)
+		// Failing to get the Revision is reported down the chain.
+		if revision, err := rl.Revisions(namespace).Get(name); err == nil {
+			if rate, ok := revision.TracingSampleRate(); ok {
+				cfg.SampleRate = rate
+			}
+		}
+		return tracing.Sampler(cfg)
+	}
+}
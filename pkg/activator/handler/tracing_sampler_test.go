@@ -0,0 +1,98 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/activator"
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/apis/serving"
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+func TestTracingSampler(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	quiet := revision(testNamespace, "quiet")
+	annotated := revision(testNamespace, "annotated")
+	annotated.Annotations = map[string]string{
+		serving.TracingSampleRateAnnotationKey: "1",
+	}
+	sampler := NewTracingSampler(revisionLister(quiet, annotated))
+
+	store := activatorconfig.NewStore(logtesting.TestLogger(t))
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: tracingconfig.ConfigName},
+		Data: map[string]string{
+			"enable":                                "true",
+			"zipkin-endpoint":                       "http://zipkin",
+			"sample-rate":                           "1",
+			"sample-rate." + testNamespace:          "0",
+			"sample-rate." + testNamespace + ".new": "1",
+		},
+	})
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: suspensionconfig.SuspensionConfigName},
+	})
+	store.OnConfigChanged(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: activatorconfig.MaintenanceConfigName},
+	})
+
+	tests := []struct {
+		namespace string
+		revision  string
+		want      bool
+	}{{
+		namespace: "other",
+		revision:  "quiet",
+		want:      true,
+	}, {
+		namespace: testNamespace,
+		revision:  "quiet",
+		want:      false,
+	}, {
+		namespace: testNamespace,
+		revision:  "new",
+		want:      true,
+	}, {
+		namespace: testNamespace,
+		revision:  "annotated",
+		want:      true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.namespace+"/"+test.revision, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set(activator.RevisionHeaderNamespace, test.namespace)
+			req.Header.Set(activator.RevisionHeaderName, test.revision)
+			req = req.WithContext(store.ToContext(req.Context()))
+
+			got := sampler(req)(trace.SamplingParameters{}).Sample
+			if got != test.want {
+				t.Errorf("Sample = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
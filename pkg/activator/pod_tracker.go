@@ -0,0 +1,313 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/queue"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// outlierFailures is the number of requests in a row a pod has to fail,
+	// with an error or a 5xx response, to be ejected.
+	outlierFailures = 5
+	// outlierEjection is how long an ejected pod isn't picked for.
+	outlierEjection = 30 * time.Second
+)
+
+// podTracker keeps the IPs of the Ready pods of each revision, as seen by
+// the pod informer and as announced by their queue-proxies. A pod is seen
+// Ready here as soon as its queue-proxy passes the readiness probe, which is
+// seconds before the Endpoints controller adds it to the Endpoints of the
+// private service.
+type podTracker struct {
+	mu sync.RWMutex
+	// pods maps the revisions to their pods, by pod name.
+	pods map[RevisionID]map[string]*trackedPod
+	// next is the index of the pod picked next, per revision.
+	next map[RevisionID]int
+
+	// now is the clock registrations expire by.
+	now func() time.Time
+}
+
+// trackedPod is a pod that is Ready according to the pod informer, to its
+// queue-proxy, or both.
+type trackedPod struct {
+	ip string
+	// node is the node the pod runs on, if the pod informer saw it.
+	node string
+	// informed is whether the pod informer has seen the pod Ready.
+	informed bool
+	// expires is when the last registration of the pod lapses.
+	expires time.Time
+	// capacity is the number of requests the pod last announced it can
+	// take right away, or -1 if unlimited or unknown.
+	capacity int
+	// failures is the number of requests in a row the pod failed.
+	failures int
+	// ejectedUntil is when the pod is picked again after it was ejected as
+	// an outlier.
+	ejectedUntil time.Time
+}
+
+func (p *trackedPod) live(now time.Time) bool {
+	return p.informed || now.Before(p.expires)
+}
+
+func (p *trackedPod) ejected(now time.Time) bool {
+	return now.Before(p.ejectedUntil)
+}
+
+func newPodTracker() *podTracker {
+	return &podTracker{
+		pods: make(map[RevisionID]map[string]*trackedPod),
+		next: make(map[RevisionID]int),
+		now:  time.Now,
+	}
+}
+
+// update records the pod as Ready or not, depending on its status. It returns
+// the revision of the pod and whether the set of its Ready pods changed.
+func (pt *podTracker) update(pod *corev1.Pod) (RevisionID, bool) {
+	rev := RevisionID{Namespace: pod.Namespace, Name: pod.Labels[serving.RevisionLabelKey]}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	now := pt.now()
+	p, ok := pt.pods[rev][pod.Name]
+	if !isPodReady(pod) {
+		if !ok || !p.informed {
+			return rev, false
+		}
+		p.informed = false
+		if !p.live(now) {
+			pt.deleteLocked(rev, pod.Name)
+		}
+		return rev, true
+	}
+
+	if !ok {
+		pt.addLocked(rev, pod.Name, &trackedPod{
+			ip:       pod.Status.PodIP,
+			node:     pod.Spec.NodeName,
+			informed: true,
+			capacity: -1,
+		})
+		return rev, true
+	}
+	changed := !p.live(now) || p.ip != pod.Status.PodIP
+	p.ip, p.node, p.informed = pod.Status.PodIP, pod.Spec.NodeName, true
+	return rev, changed
+}
+
+// register records the announced Registration of a pod. Pods that are not
+// Ready or are draining are forgotten right away, whatever the informer says.
+// It returns whether the set of Ready pods of the revision changed.
+func (pt *podTracker) register(rev RevisionID, reg *queue.Registration) bool {
+	if !reg.Ready || reg.Draining || reg.PodIP == "" {
+		return pt.remove(rev, reg.Pod)
+	}
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	now := pt.now()
+	p, ok := pt.pods[rev][reg.Pod]
+	if !ok {
+		pt.addLocked(rev, reg.Pod, &trackedPod{
+			ip:       reg.PodIP,
+			expires:  now.Add(queue.RegistrationTTL),
+			capacity: reg.Capacity,
+		})
+		return true
+	}
+	changed := !p.live(now) || p.ip != reg.PodIP
+	p.ip, p.capacity, p.expires = reg.PodIP, reg.Capacity, now.Add(queue.RegistrationTTL)
+	return changed
+}
+
+// remove forgets the pod of the revision. It returns whether the pod was
+// known to be Ready.
+func (pt *podTracker) remove(rev RevisionID, name string) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	p, ok := pt.pods[rev][name]
+	if !ok {
+		return false
+	}
+	pt.deleteLocked(rev, name)
+	return p.live(pt.now())
+}
+
+func (pt *podTracker) addLocked(rev RevisionID, name string, p *trackedPod) {
+	pods, ok := pt.pods[rev]
+	if !ok {
+		pods = make(map[string]*trackedPod)
+		pt.pods[rev] = pods
+	}
+	pods[name] = p
+}
+
+func (pt *podTracker) deleteLocked(rev RevisionID, name string) {
+	pods := pt.pods[rev]
+	delete(pods, name)
+	if len(pods) == 0 {
+		delete(pt.pods, rev)
+		delete(pt.next, rev)
+	}
+}
+
+// report records whether a request sent to the pod of the revision with the
+// given IP failed. A pod that fails outlierFailures requests in a row is
+// ejected, i.e. not picked, for outlierEjection, unless that would leave less
+// than half of the Ready pods of the revision to pick. It returns whether the
+// pod got ejected.
+func (pt *podTracker) report(rev RevisionID, ip string, failed bool) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	now := pt.now()
+	var pod *trackedPod
+	live, ejected := 0, 0
+	for _, p := range pt.pods[rev] {
+		if !p.live(now) {
+			continue
+		}
+		live++
+		if p.ejected(now) {
+			ejected++
+		}
+		if p.ip == ip {
+			pod = p
+		}
+	}
+	if pod == nil || pod.ejected(now) {
+		return false
+	}
+	if !failed {
+		pod.failures = 0
+		return false
+	}
+	pod.failures++
+	if pod.failures < outlierFailures || 2*(ejected+1) > live {
+		return false
+	}
+	pod.failures = 0
+	pod.ejectedUntil = now.Add(outlierEjection)
+	return true
+}
+
+// ejecting returns whether any of the Ready pods of the revision is ejected.
+func (pt *podTracker) ejecting(rev RevisionID) bool {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	now := pt.now()
+	for _, p := range pt.pods[rev] {
+		if p.live(now) && p.ejected(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// count returns the number of Ready pods of the revision.
+func (pt *podTracker) count(rev RevisionID) int {
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+	now := pt.now()
+	n := 0
+	for _, p := range pt.pods[rev] {
+		if p.live(now) {
+			n++
+		}
+	}
+	return n
+}
+
+// pick returns the IP of one of the Ready pods of the revision, round robin.
+// Pods that announced they have no capacity left are only picked when all
+// of them did. Ejected pods are never picked.
+func (pt *podTracker) pick(rev RevisionID) (string, bool) {
+	return pt.pickExcept(rev, "")
+}
+
+// pickExcept is like pick, but never picks the pod with the IP except.
+func (pt *podTracker) pickExcept(rev RevisionID, except string) (string, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	now := pt.now()
+	var ips, full []string
+	for _, p := range pt.pods[rev] {
+		switch {
+		case !p.live(now) || p.ejected(now) || p.ip == except:
+		case p.capacity == 0:
+			full = append(full, p.ip)
+		default:
+			ips = append(ips, p.ip)
+		}
+	}
+	if len(ips) == 0 {
+		ips = full
+	}
+	return pt.nextLocked(rev, ips)
+}
+
+// pickOn returns the IP of one of the Ready pods of the revision running on
+// the nodes accepted by onNode, round robin. Pods that announced they have no
+// capacity left are never picked, so that requests spill over to the other
+// pods.
+func (pt *podTracker) pickOn(rev RevisionID, onNode func(string) bool) (string, bool) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	now := pt.now()
+	var ips []string
+	for _, p := range pt.pods[rev] {
+		if p.live(now) && !p.ejected(now) && p.capacity != 0 && p.node != "" && onNode(p.node) {
+			ips = append(ips, p.ip)
+		}
+	}
+	return pt.nextLocked(rev, ips)
+}
+
+func (pt *podTracker) nextLocked(rev RevisionID, ips []string) (string, bool) {
+	if len(ips) == 0 {
+		return "", false
+	}
+	sort.Strings(ips)
+	i := pt.next[rev] % len(ips)
+	pt.next[rev] = i + 1
+	return ips[i], true
+}
+
+// isPodReady returns true if the pod has an IP, is not terminating and has
+// passed its readiness probes.
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
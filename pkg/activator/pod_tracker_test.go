@@ -0,0 +1,232 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"testing"
+	"time"
+
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/queue"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodTracker(t *testing.T) {
+	pt := newPodTracker()
+
+	if _, changed := pt.update(revisionPod("pod-1", "10.0.0.1", false)); changed {
+		t.Error("update() of an unready pod changed the Ready pods")
+	}
+	if got := pt.count(revID); got != 0 {
+		t.Errorf("count() = %d, want 0", got)
+	}
+	if _, ok := pt.pick(revID); ok {
+		t.Error("pick() = true without Ready pods")
+	}
+
+	rev, changed := pt.update(revisionPod("pod-1", "10.0.0.1", true))
+	if !changed {
+		t.Error("update() of a newly Ready pod didn't change the Ready pods")
+	}
+	if rev != revID {
+		t.Errorf("update() = %v, want %v", rev, revID)
+	}
+	if _, changed := pt.update(revisionPod("pod-1", "10.0.0.1", true)); changed {
+		t.Error("update() of an unchanged pod changed the Ready pods")
+	}
+	pt.update(revisionPod("pod-2", "10.0.0.2", true))
+	if got := pt.count(revID); got != 2 {
+		t.Errorf("count() = %d, want 2", got)
+	}
+
+	// Picking goes round robin.
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		ip, ok := pt.pick(revID)
+		if !ok {
+			t.Fatal("pick() = false with Ready pods")
+		}
+		seen[ip] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("pick() returned %v, want both pods", seen)
+	}
+	for i := 0; i < 2; i++ {
+		if ip, _ := pt.pickExcept(revID, "10.0.0.1"); ip != "10.0.0.2" {
+			t.Errorf("pickExcept(10.0.0.1) = %q, want 10.0.0.2", ip)
+		}
+	}
+
+	terminating := revisionPod("pod-2", "10.0.0.2", true)
+	terminating.DeletionTimestamp = &metav1.Time{}
+	if _, changed := pt.update(terminating); !changed {
+		t.Error("update() of a terminating pod didn't change the Ready pods")
+	}
+	if !pt.remove(revID, "pod-1") {
+		t.Error("remove() of a Ready pod = false")
+	}
+	if pt.remove(revID, "pod-1") {
+		t.Error("remove() of a removed pod = true")
+	}
+	if got := pt.count(revID); got != 0 {
+		t.Errorf("count() = %d, want 0", got)
+	}
+}
+
+func TestPodTrackerRegistrations(t *testing.T) {
+	now := time.Now()
+	pt := newPodTracker()
+	pt.now = func() time.Time { return now }
+
+	if !pt.register(revID, registration("pod-1", "10.0.0.1", 1)) {
+		t.Error("register() of a new Ready pod didn't change the Ready pods")
+	}
+	if pt.register(revID, registration("pod-1", "10.0.0.1", 1)) {
+		t.Error("register() of an unchanged pod changed the Ready pods")
+	}
+	pt.register(revID, registration("pod-2", "10.0.0.2", 0))
+	if got := pt.count(revID); got != 2 {
+		t.Errorf("count() = %d, want 2", got)
+	}
+
+	// The pod without capacity left is not picked.
+	for i := 0; i < 3; i++ {
+		if ip, _ := pt.pick(revID); ip != "10.0.0.1" {
+			t.Errorf("pick() = %q, want 10.0.0.1", ip)
+		}
+	}
+
+	// Draining pods are dropped right away, even when the informer saw them.
+	pt.update(revisionPod("pod-1", "10.0.0.1", true))
+	draining := registration("pod-1", "10.0.0.1", 1)
+	draining.Draining = true
+	if !pt.register(revID, draining) {
+		t.Error("register() of a draining pod didn't change the Ready pods")
+	}
+	if ip, _ := pt.pick(revID); ip != "10.0.0.2" {
+		t.Errorf("pick() = %q, want 10.0.0.2", ip)
+	}
+
+	// Registrations lapse, unless the informer sees the pod Ready.
+	pt.update(revisionPod("pod-3", "10.0.0.3", true))
+	pt.register(revID, registration("pod-3", "10.0.0.3", 1))
+	now = now.Add(queue.RegistrationTTL)
+	if got := pt.count(revID); got != 1 {
+		t.Errorf("count() = %d, want 1", got)
+	}
+	if ip, _ := pt.pick(revID); ip != "10.0.0.3" {
+		t.Errorf("pick() = %q, want 10.0.0.3", ip)
+	}
+}
+
+func TestPodTrackerOutlierEjection(t *testing.T) {
+	now := time.Now()
+	pt := newPodTracker()
+	pt.now = func() time.Time { return now }
+	pt.update(revisionPod("pod-1", "10.0.0.1", true))
+
+	// The only pod of a revision is never ejected.
+	for i := 0; i < outlierFailures; i++ {
+		if pt.report(revID, "10.0.0.1", true) {
+			t.Fatal("report() ejected the only pod")
+		}
+	}
+
+	pt.report(revID, "10.0.0.1", false)
+
+	pt.update(revisionPod("pod-2", "10.0.0.2", true))
+	// Successes in between reset the count of failures.
+	for i := 0; i < outlierFailures-1; i++ {
+		pt.report(revID, "10.0.0.1", true)
+	}
+	pt.report(revID, "10.0.0.1", false)
+	if pt.report(revID, "10.0.0.1", true) {
+		t.Error("report() ejected a pod that didn't fail enough requests in a row")
+	}
+	for i := 0; i < outlierFailures-2; i++ {
+		pt.report(revID, "10.0.0.1", true)
+	}
+	if !pt.report(revID, "10.0.0.1", true) {
+		t.Fatal("report() didn't eject a pod failing every request")
+	}
+	if !pt.ejecting(revID) {
+		t.Error("ejecting() = false, want true")
+	}
+	if got := pt.count(revID); got != 2 {
+		t.Errorf("count() = %d, want 2", got)
+	}
+	for i := 0; i < 3; i++ {
+		if ip, _ := pt.pick(revID); ip != "10.0.0.2" {
+			t.Errorf("pick() = %q, want 10.0.0.2", ip)
+		}
+	}
+	if ip, ok := pt.pickExcept(revID, "10.0.0.2"); ok {
+		t.Errorf("pickExcept() = %q, want none", ip)
+	}
+
+	// No more than half of the pods are ejected.
+	for i := 0; i < outlierFailures; i++ {
+		if pt.report(revID, "10.0.0.2", true) {
+			t.Fatal("report() ejected the last pod left")
+		}
+	}
+
+	// Ejected pods are picked again after a while.
+	now = now.Add(outlierEjection)
+	if pt.ejecting(revID) {
+		t.Error("ejecting() = true, want false")
+	}
+	if ip, _ := pt.pickExcept(revID, "10.0.0.2"); ip != "10.0.0.1" {
+		t.Errorf("pickExcept() = %q, want 10.0.0.1", ip)
+	}
+}
+
+func registration(pod, ip string, capacity int) *queue.Registration {
+	return &queue.Registration{
+		Namespace: testNamespace,
+		Revision:  testRevision,
+		Pod:       pod,
+		PodIP:     ip,
+		Ready:     true,
+		Capacity:  capacity,
+	}
+}
+
+func revisionPod(name, ip string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: testNamespace,
+			Labels: map[string]string{
+				serving.RevisionLabelKey: testRevision,
+			},
+		},
+		Status: corev1.PodStatus{
+			PodIP: ip,
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodReady,
+				Status: status,
+			}},
+		},
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"fmt"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/system"
+	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	"knative.dev/serving/pkg/queue"
+)
+
+// RegistrationTokens derives the tokens the queue-proxies of the revisions
+// sign their registrations with from the key the controller keeps in the
+// system namespace.
+type RegistrationTokens struct {
+	kubeClient     kubernetes.Interface
+	revisionLister servinglisters.RevisionLister
+
+	// key is read once the controller created it, it never changes.
+	mu  sync.RWMutex
+	key []byte
+}
+
+// NewRegistrationTokens creates a RegistrationTokens reading the key with
+// kubeClient.
+func NewRegistrationTokens(kubeClient kubernetes.Interface, revisionLister servinglisters.RevisionLister) *RegistrationTokens {
+	return &RegistrationTokens{
+		kubeClient:     kubeClient,
+		revisionLister: revisionLister,
+	}
+}
+
+// Token returns the registration token of the revision.
+func (rt *RegistrationTokens) Token(namespace, revision string) ([]byte, error) {
+	key, err := rt.getKey()
+	if err != nil {
+		return nil, err
+	}
+	rev, err := rt.revisionLister.Revisions(namespace).Get(revision)
+	if err != nil {
+		return nil, err
+	}
+	return queue.RegistrationToken(key, namespace, revision, rev.UID), nil
+}
+
+func (rt *RegistrationTokens) getKey() ([]byte, error) {
+	rt.mu.RLock()
+	key := rt.key
+	rt.mu.RUnlock()
+	if key != nil {
+		return key, nil
+	}
+
+	secret, err := rt.kubeClient.CoreV1().Secrets(system.Namespace()).Get(RegistrationKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	key = secret.Data[RegistrationKeySecretKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", RegistrationKeySecretName, RegistrationKeySecretKey)
+	}
+	rt.mu.Lock()
+	rt.key = key
+	rt.mu.Unlock()
+	return key, nil
+}
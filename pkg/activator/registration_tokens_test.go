@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestRegistrationTokens(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	tokens := NewRegistrationTokens(kubeClient, revisionLister(testNamespace, testRevision, 10))
+
+	// There are no tokens until the controller created the key.
+	if _, err := tokens.Token(testNamespace, testRevision); err == nil {
+		t.Error("Token() = nil error without a key")
+	}
+
+	kubeClient.CoreV1().Secrets(system.Namespace()).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RegistrationKeySecretName,
+			Namespace: system.Namespace(),
+		},
+		Data: map[string][]byte{
+			RegistrationKeySecretKey: []byte("key"),
+		},
+	})
+	got, err := tokens.Token(testNamespace, testRevision)
+	if err != nil {
+		t.Fatalf("Token() = %v", err)
+	}
+	if want := queue.RegistrationToken([]byte("key"), testNamespace, testRevision, ""); string(got) != string(want) {
+		t.Errorf("Token() = %s, want %s", got, want)
+	}
+
+	if _, err := tokens.Token(testNamespace, "unknown"); err == nil {
+		t.Error("Token() = nil error for an unknown revision")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"go.opencensus.io/tag"
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/serving/pkg/tracing"
 )
 
 var (
@@ -38,6 +39,10 @@ var (
 		"request_latencies",
 		"The response time in millisecond",
 		stats.UnitMilliseconds)
+	podEjectionCountM = stats.Int64(
+		"pod_ejection_count",
+		"The number of times pods are ejected from the targets of Activator",
+		stats.UnitDimensionless)
 
 	// NOTE: 0 should not be used as boundary. See
 	// https://github.com/census-ecosystem/opencensus-go-exporter-stackdriver/issues/98
@@ -47,7 +52,8 @@ var (
 // StatsReporter defines the interface for sending activator metrics
 type StatsReporter interface {
 	ReportRequestCount(ns, service, config, rev string, responseCode, numTries int, v int64) error
-	ReportResponseTime(ns, service, config, rev string, responseCode int, d time.Duration) error
+	ReportResponseTime(reqCtx context.Context, ns, service, config, rev string, responseCode int, d time.Duration) error
+	ReportPodEjection(ns, service, config, rev string) error
 }
 
 // Reporter holds cached metric objects to report autoscaler metrics
@@ -116,6 +122,12 @@ func NewStatsReporter() (*Reporter, error) {
 			Aggregation: defaultLatencyDistribution,
 			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey, r.responseCodeClassKey, r.responseCodeKey},
 		},
+		&view.View{
+			Description: "The number of times pods are ejected from the targets of Activator",
+			Measure:     podEjectionCountM,
+			Aggregation: view.Sum(),
+			TagKeys:     []tag.Key{r.namespaceTagKey, r.serviceTagKey, r.configTagKey, r.revisionTagKey},
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -156,8 +168,9 @@ func (r *Reporter) ReportRequestCount(ns, service, config, rev string, responseC
 	return nil
 }
 
-// ReportResponseTime captures response time requests
-func (r *Reporter) ReportResponseTime(ns, service, config, rev string, responseCode int, d time.Duration) error {
+// ReportResponseTime captures response time requests. The sampled span of
+// the request in reqCtx, if any, becomes the exemplar of the measurement.
+func (r *Reporter) ReportResponseTime(reqCtx context.Context, ns, service, config, rev string, responseCode int, d time.Duration) error {
 	if !r.initialized {
 		return errors.New("StatsReporter is not initialized yet")
 	}
@@ -176,7 +189,32 @@ func (r *Reporter) ReportResponseTime(ns, service, config, rev string, responseC
 	}
 
 	// convert time.Duration in nanoseconds to milliseconds
-	metrics.Record(ctx, responseTimeInMsecM.M(float64(d/time.Millisecond)))
+	// The latencies are knative_revision metrics, which metrics.Record
+	// records on every backend, but it has no room for the exemplars.
+	return stats.RecordWithOptions(ctx,
+		stats.WithMeasurements(responseTimeInMsecM.M(float64(d/time.Millisecond))),
+		stats.WithAttachments(tracing.ExemplarAttachments(reqCtx)))
+}
+
+// ReportPodEjection captures a pod of the revision being ejected as an
+// outlier.
+func (r *Reporter) ReportPodEjection(ns, service, config, rev string) error {
+	if !r.initialized {
+		return errors.New("StatsReporter is not initialized yet")
+	}
+
+	// Note that service names can be an empty string, so it needs a special treatment.
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(r.namespaceTagKey, ns),
+		tag.Insert(r.serviceTagKey, valueOrUnknown(service)),
+		tag.Insert(r.configTagKey, config),
+		tag.Insert(r.revisionTagKey, rev))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, podEjectionCountM.M(1))
 	return nil
 }
 
@@ -17,9 +17,13 @@ limitations under the License.
 package activator
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
 	"knative.dev/pkg/metrics/metricskey"
 	"knative.dev/pkg/metrics/metricstest"
 )
@@ -29,7 +33,7 @@ import (
 // Since golang executes test iterations within the same process, the stats reporter
 // returns an error if the metric is already registered and the test panics.
 func unregister() {
-	metricstest.Unregister("request_count", "request_latencies")
+	metricstest.Unregister("request_count", "request_latencies", "pod_ejection_count")
 }
 
 func TestActivatorReporter(t *testing.T) {
@@ -71,12 +75,23 @@ func TestActivatorReporter(t *testing.T) {
 		"response_code_class":             "2xx",
 	}
 	expectSuccess(t, func() error {
-		return r.ReportResponseTime("testns", "testsvc", "testconfig", "testrev", 200, 1100*time.Millisecond)
+		return r.ReportResponseTime(context.Background(), "testns", "testsvc", "testconfig", "testrev", 200, 1100*time.Millisecond)
 	})
 	expectSuccess(t, func() error {
-		return r.ReportResponseTime("testns", "testsvc", "testconfig", "testrev", 200, 9100*time.Millisecond)
+		return r.ReportResponseTime(context.Background(), "testns", "testsvc", "testconfig", "testrev", 200, 9100*time.Millisecond)
 	})
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags3, 2, 1100.0, 9100.0)
+
+	// test ReportPodEjection
+	wantTags4 := map[string]string{
+		metricskey.LabelNamespaceName:     "testns",
+		metricskey.LabelServiceName:       "testsvc",
+		metricskey.LabelConfigurationName: "testconfig",
+		metricskey.LabelRevisionName:      "testrev",
+	}
+	expectSuccess(t, func() error { return r.ReportPodEjection("testns", "testsvc", "testconfig", "testrev") })
+	expectSuccess(t, func() error { return r.ReportPodEjection("testns", "testsvc", "testconfig", "testrev") })
+	metricstest.CheckSumData(t, "pod_ejection_count", wantTags4, 2)
 }
 
 func TestReportRequestCount_EmptyServiceName(t *testing.T) {
@@ -111,14 +126,39 @@ func TestReportResponseTimeEmptyServiceName(t *testing.T) {
 		"response_code_class":             "2xx",
 	}
 	expectSuccess(t, func() error {
-		return r.ReportResponseTime("testns" /*service=*/, "", "testconfig", "testrev", 200, 7100*time.Millisecond)
+		return r.ReportResponseTime(context.Background(), "testns" /*service=*/, "", "testconfig", "testrev", 200, 7100*time.Millisecond)
 	})
 	expectSuccess(t, func() error {
-		return r.ReportResponseTime("testns" /*service=*/, "", "testconfig", "testrev", 200, 5100*time.Millisecond)
+		return r.ReportResponseTime(context.Background(), "testns" /*service=*/, "", "testconfig", "testrev", 200, 5100*time.Millisecond)
 	})
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags, 2, 5100.0, 7100.0)
 }
 
+func TestReportResponseTimeExemplar(t *testing.T) {
+	r, _ := NewStatsReporter()
+	defer unregister()
+
+	ctx, span := trace.StartSpan(context.Background(), "request", trace.WithSampler(trace.AlwaysSample()))
+	span.End()
+	expectSuccess(t, func() error {
+		return r.ReportResponseTime(ctx, "testns", "testsvc", "testconfig", "testrev", 200, 7*time.Millisecond)
+	})
+
+	rows, err := view.RetrieveData("request_latencies")
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("RetrieveData() = %v, %v, want one row", rows, err)
+	}
+	var exemplar *metricdata.Exemplar
+	for _, e := range rows[0].Data.(*view.DistributionData).ExemplarsPerBucket {
+		if e != nil {
+			exemplar = e
+		}
+	}
+	if exemplar == nil || exemplar.Attachments[metricdata.AttachmentKeySpanContext] != span.SpanContext() {
+		t.Errorf("Exemplar = %+v, want one linking to %v", exemplar, span.SpanContext())
+	}
+}
+
 func expectSuccess(t *testing.T, f func() error) {
 	t.Helper()
 	if err := f(); err != nil {
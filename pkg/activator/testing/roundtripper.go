@@ -27,6 +27,10 @@ import (
 )
 
 // FakeResponse is a response given by the FakeRoundTripper
+// DefaultProbeKey is the key FakeRoundTripper checks probes with, unless
+// told otherwise.
+var DefaultProbeKey = []byte("probe-key")
+
 type FakeResponse struct {
 	Err  error
 	Code int
@@ -48,6 +52,10 @@ type FakeRoundTripper struct {
 	// Response to non-probe requests
 	RequestResponse *FakeResponse
 	responseMux     sync.Mutex
+
+	// ProbeKey verifies the probe requests and signs their responses, as the
+	// queue-proxy does. It defaults to DefaultProbeKey.
+	ProbeKey []byte
 }
 
 func defaultProbeResponse() *FakeResponse {
@@ -103,7 +111,21 @@ func (rt *FakeRoundTripper) RT(req *http.Request) (*http.Response, error) {
 				Body: "probe sent to a wrong system",
 			})
 		}
-		return response(resp)
+		key := rt.ProbeKey
+		if key == nil {
+			key = DefaultProbeKey
+		}
+		if !network.ValidProbeRequest(req, key, queue.Name) {
+			return response(&FakeResponse{
+				Code: http.StatusForbidden,
+				Body: "probe not signed",
+			})
+		}
+		r, err := response(resp)
+		if err == nil {
+			network.SignProbeResponse(r.Header, req, key, queue.Name)
+		}
+		return r, err
 	}
 	resp := rt.RequestResponse
 	if resp == nil {
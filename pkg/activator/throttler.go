@@ -19,6 +19,7 @@ package activator
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -60,6 +61,18 @@ type Throttler struct {
 	revisionLister  servinglisters.RevisionLister
 	sksLister       netlisters.ServerlessServiceLister
 
+	// pods are the Ready pods of the revisions, if we watch them.
+	pods *podTracker
+	// podLister lists all the pods of the revisions, the registrations
+	// are checked against, if we watch them.
+	podLister corev1listers.PodLister
+
+	// local is the topology of the node of the activator and nodeLister
+	// resolves the one of the pods, when requests are routed to the pods
+	// closest to the activator.
+	local      topology
+	nodeLister corev1listers.NodeLister
+
 	numActivatorsMux sync.RWMutex
 	numActivators    int
 }
@@ -85,6 +98,7 @@ func NewThrottler(
 		endpointsLister: endpointsInformer.Lister(),
 		revisionLister:  revisionLister,
 		sksLister:       sksLister,
+		pods:            newPodTracker(),
 	}
 
 	// Update/create the breaker in the throttler when the number of endpoints changes.
@@ -120,6 +134,129 @@ func NewThrottler(
 	return throttler
 }
 
+// WatchPods makes the throttler track the Ready pods of the revisions through
+// the given informer, in addition to the registrations of the queue-proxies. Their count is taken into account for the capacity of
+// the revisions while the Endpoints of the private services don't list them
+// yet, and PodIP routes requests to them directly in that time.
+func (t *Throttler) WatchPods(podInformer corev1informers.PodInformer) {
+	t.podLister = podInformer.Lister()
+	podInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: reconciler.LabelExistsFilterFunc(serving.RevisionLabelKey),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    t.podUpdated,
+			UpdateFunc: controller.PassNew(t.podUpdated),
+			DeleteFunc: t.podDeleted,
+		},
+	})
+}
+
+// PreferLocalPods makes PodIP route requests to the Ready pods in the zone of
+// the given node, the one the activator runs on, or else in its region. When
+// none of them has capacity left, requests spill over to the private service
+// of the revision, which balances them across all zones. Only the pods seen by
+// the pod informer have a known node, so WatchPods must be called as well.
+func (t *Throttler) PreferLocalPods(node *corev1.Node, nodeLister corev1listers.NodeLister) {
+	t.local = nodeTopology(node)
+	t.nodeLister = nodeLister
+}
+
+// Register records the state of a pod announced by its queue-proxy. Pods
+// that are Ready count towards the capacity of their revision and are
+// routed to by PodIP, like the ones seen by the pod informer, until their
+// registration lapses. Draining pods are dropped right away.
+// Only the pods the pod informer knows for the revision, with the same IP,
+// can be registered, so WatchPods must be called as well.
+func (t *Throttler) Register(reg *queue.Registration) error {
+	if t.podLister == nil {
+		return errors.New("the pods of the revisions are not watched")
+	}
+	pod, err := t.podLister.Pods(reg.Namespace).Get(reg.Pod)
+	if err != nil {
+		return err
+	}
+	if pod.Labels[serving.RevisionLabelKey] != reg.Revision {
+		return fmt.Errorf("pod %s/%s is not one of revision %s", reg.Namespace, reg.Pod, reg.Revision)
+	}
+	if reg.PodIP != "" && pod.Status.PodIP != reg.PodIP {
+		return fmt.Errorf("pod %s/%s doesn't have IP %s", reg.Namespace, reg.Pod, reg.PodIP)
+	}
+
+	revID := RevisionID{Namespace: reg.Namespace, Name: reg.Revision}
+	if t.pods.register(revID, reg) {
+		t.podsChanged(revID)
+	}
+	return nil
+}
+
+// PodIP returns the IP of a Ready pod of the revision close to the activator,
+// if PreferLocalPods was called, while some of its pods are ejected, or while
+// the Endpoints of its private service have no ready addresses yet, which is
+// when requests sent to the service would have no backend to go to.
+func (t *Throttler) PodIP(rev RevisionID) (string, bool) {
+	if t.pods.count(rev) == 0 {
+		return "", false
+	}
+	if ip, ok := t.localPodIP(rev); ok {
+		return ip, true
+	}
+	if t.pods.ejecting(rev) {
+		// The private service would still send requests to the ejected
+		// pods.
+		return t.pods.pick(rev)
+	}
+	sks, err := t.sksLister.ServerlessServices(rev.Namespace).Get(rev.Name)
+	if err != nil {
+		return "", false
+	}
+	podCounter := resources.NewScopedEndpointsCounter(t.endpointsLister, sks.Namespace, sks.Status.PrivateServiceName)
+	if size, err := podCounter.ReadyCount(); err == nil && size > 0 {
+		return "", false
+	}
+	return t.pods.pick(rev)
+}
+
+// ReportPodResult records whether a request sent to the pod of the revision
+// with the given IP by PodIP or ReplayPodIP failed, with an error or a 5xx
+// response. Pods failing too many requests in a row are ejected: PodIP and
+// ReplayPodIP route around them for a while. It returns whether the pod got
+// ejected.
+func (t *Throttler) ReportPodResult(rev RevisionID, ip string, failed bool) bool {
+	return t.pods.report(rev, ip, failed)
+}
+
+// ReplayPodIP returns the IP of a Ready pod of the revision other than the
+// one with the IP failed, to replay a request that failed on the latter.
+func (t *Throttler) ReplayPodIP(rev RevisionID, failed string) (string, bool) {
+	return t.pods.pickExcept(rev, failed)
+}
+
+// localPodIP returns the IP of a Ready pod of the revision with capacity left
+// in the zone of the activator, or else in its region, if PreferLocalPods was
+// called.
+func (t *Throttler) localPodIP(rev RevisionID) (string, bool) {
+	if t.nodeLister == nil {
+		return "", false
+	}
+	if t.local.zone != "" {
+		if ip, ok := t.pods.pickOn(rev, t.nodesMatching(func(nt topology) bool { return nt.zone == t.local.zone })); ok {
+			return ip, true
+		}
+	}
+	if t.local.region != "" {
+		return t.pods.pickOn(rev, t.nodesMatching(func(nt topology) bool { return nt.region == t.local.region }))
+	}
+	return "", false
+}
+
+// nodesMatching returns whether the named node exists and its topology
+// matches.
+func (t *Throttler) nodesMatching(match func(topology) bool) func(string) bool {
+	return func(name string) bool {
+		node, err := t.nodeLister.Get(name)
+		return err == nil && match(nodeTopology(node))
+	}
+}
+
 // Remove deletes the breaker from the bookkeeping.
 func (t *Throttler) Remove(rev RevisionID) {
 	t.breakersMux.Lock()
@@ -246,6 +383,7 @@ func (t *Throttler) forceUpdateCapacity(rev RevisionID, breaker breaker, activat
 	if err != nil {
 		return err
 	}
+	size = t.withReadyPods(rev, size)
 
 	return t.updateCapacity(breaker, int(revision.Spec.ContainerConcurrency), size, activatorCount)
 }
@@ -275,8 +413,8 @@ func (t *Throttler) endpointsUpdated(newObj interface{}) {
 		t.logger.Errorf("updating capacity failed: endpoints %s/%s didn't have a revision label", ep.Namespace, ep.Name)
 		return
 	}
-	addresses := resources.ReadyAddressCount(ep)
 	revID := RevisionID{ep.Namespace, revisionName}
+	addresses := t.withReadyPods(revID, resources.ReadyAddressCount(ep))
 	if err := t.UpdateCapacity(revID, addresses); err != nil {
 		t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("updating capacity failed", zap.Error(err))
 	}
@@ -296,6 +434,60 @@ func (t *Throttler) endpointsDeleted(obj interface{}) {
 	t.Remove(revID)
 }
 
+// withReadyPods returns the number of ready addresses of the revision, or the
+// number of its Ready pods, whichever is greater. The latter are ahead of the
+// Endpoints when scaling up.
+func (t *Throttler) withReadyPods(rev RevisionID, addresses int) int {
+	if pods := t.pods.count(rev); pods > addresses {
+		return pods
+	}
+	return addresses
+}
+
+// podUpdated is a handler function to be used by the Pod informer.
+// It updates the capacity of the revision of the pod if it already has a
+// breaker and the pod became Ready or stopped being Ready.
+func (t *Throttler) podUpdated(newObj interface{}) {
+	pod := newObj.(*corev1.Pod)
+	revID, changed := t.pods.update(pod)
+	if changed {
+		t.podsChanged(revID)
+	}
+}
+
+// podDeleted is a handler function to be used by the Pod informer.
+func (t *Throttler) podDeleted(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if pod, ok = tombstone.Obj.(*corev1.Pod); !ok {
+			return
+		}
+	}
+	revID := RevisionID{pod.Namespace, pod.Labels[serving.RevisionLabelKey]}
+	if t.pods.remove(revID, pod.Name) {
+		t.podsChanged(revID)
+	}
+}
+
+// podsChanged updates the capacity of the revision, if we have a breaker for it.
+// Breakers for revisions without requests are created by the Endpoints updates
+// or the first request.
+func (t *Throttler) podsChanged(revID RevisionID) {
+	t.breakersMux.RLock()
+	breaker, ok := t.breakers[revID]
+	t.breakersMux.RUnlock()
+	if !ok {
+		return
+	}
+	if err := t.forceUpdateCapacity(revID, breaker, t.activatorCount()); err != nil {
+		t.logger.With(zap.String(logkey.Key, revID.String())).Errorw("updating capacity failed", zap.Error(err))
+	}
+}
+
 // infiniteBreaker is basically a short circuit.
 // infiniteBreaker provides us capability to send unlimited number
 // of requests to the downstream system.
@@ -406,6 +406,237 @@ func TestHelper_ReactToEndpoints(t *testing.T) {
 	}
 }
 
+func TestThrottlerReactsToPods(t *testing.T) {
+	const updatePollInterval = 10 * time.Millisecond
+	const updatePollTimeout = 3 * time.Second
+
+	fake := kubefake.NewSimpleClientset()
+	informer := kubeinformers.NewSharedInformerFactory(fake, 0)
+	podInformer := informer.Core().V1().Pods()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	controller.StartInformers(stopCh, podInformer.Informer())
+
+	throttler := getThrottler(
+		200,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+	throttler.WatchPods(podInformer)
+	breaker, _, err := throttler.getOrCreateBreaker(revID)
+	if err != nil {
+		t.Fatalf("getOrCreateBreaker() = %v", err)
+	}
+
+	// A pod that is not Ready yet does not add capacity.
+	pod := revisionPod("pod-1", "10.0.0.1", false)
+	fake.CoreV1().Pods(testNamespace).Create(pod)
+	if _, ok := throttler.PodIP(revID); ok {
+		t.Error("PodIP() = true without Ready pods")
+	}
+
+	// Once Ready, it does, ahead of the Endpoints.
+	pod = revisionPod("pod-1", "10.0.0.1", true)
+	fake.CoreV1().Pods(testNamespace).Update(pod)
+	wait.PollImmediate(updatePollInterval, updatePollTimeout, func() (bool, error) {
+		return breaker.Capacity() == 10, nil
+	})
+	if got := breaker.Capacity(); got != 10 {
+		t.Errorf("Capacity() = %d, want 10", got)
+	}
+	if ip, ok := throttler.PodIP(revID); !ok || ip != "10.0.0.1" {
+		t.Errorf("PodIP() = %q, %v, want 10.0.0.1, true", ip, ok)
+	}
+
+	fake.CoreV1().Pods(testNamespace).Delete(pod.Name, &metav1.DeleteOptions{})
+	wait.PollImmediate(updatePollInterval, updatePollTimeout, func() (bool, error) {
+		return breaker.Capacity() == 0, nil
+	})
+	if got := breaker.Capacity(); got != 0 {
+		t.Errorf("Capacity() = %d, want 0", got)
+	}
+}
+
+func TestThrottlerRegister(t *testing.T) {
+	throttler := getThrottler(
+		200,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 0),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+	breaker, _, err := throttler.getOrCreateBreaker(revID)
+	if err != nil {
+		t.Fatalf("getOrCreateBreaker() = %v", err)
+	}
+
+	reg := registration("pod-1", "10.0.0.1", 10)
+	if err := throttler.Register(reg); err == nil {
+		t.Error("Register() = nil without watching the pods")
+	}
+	throttler.podLister = podsInformer(revisionPod("pod-1", "10.0.0.1", false)).Lister()
+
+	// Only the pods of the revision with their own IP can be registered.
+	for _, reg := range []*queue.Registration{
+		registration("pod-2", "10.0.0.2", 10),
+		registration("pod-1", "10.0.0.2", 10),
+		{Namespace: testNamespace, Revision: "other-rev", Pod: "pod-1", PodIP: "10.0.0.1", Ready: true},
+	} {
+		if err := throttler.Register(reg); err == nil {
+			t.Errorf("Register(%+v) = nil", reg)
+		}
+	}
+	if got := breaker.Capacity(); got != 0 {
+		t.Errorf("Capacity() = %d, want 0", got)
+	}
+
+	if err := throttler.Register(reg); err != nil {
+		t.Errorf("Register() = %v", err)
+	}
+	if got := breaker.Capacity(); got != 10 {
+		t.Errorf("Capacity() = %d, want 10", got)
+	}
+	if ip, ok := throttler.PodIP(revID); !ok || ip != "10.0.0.1" {
+		t.Errorf("PodIP() = %q, %v, want 10.0.0.1, true", ip, ok)
+	}
+
+	reg.Draining = true
+	if err := throttler.Register(reg); err != nil {
+		t.Errorf("Register() = %v", err)
+	}
+	if got := breaker.Capacity(); got != 0 {
+		t.Errorf("Capacity() = %d, want 0", got)
+	}
+}
+
+func TestThrottlerPodIPWithEndpoints(t *testing.T) {
+	throttler := getThrottler(
+		200,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 1),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+	throttler.podUpdated(revisionPod("pod-1", "10.0.0.1", true))
+
+	// Once the Endpoints list ready addresses, requests go to the service.
+	if ip, ok := throttler.PodIP(revID); ok {
+		t.Errorf("PodIP() = %q, want none", ip)
+	}
+}
+
+func TestThrottlerPodIPWithEjectedPods(t *testing.T) {
+	throttler := getThrottler(
+		200,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 2),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+	throttler.podUpdated(revisionPod("pod-1", "10.0.0.1", true))
+	throttler.podUpdated(revisionPod("pod-2", "10.0.0.2", true))
+
+	ejected := false
+	for i := 0; i < outlierFailures; i++ {
+		ejected = throttler.ReportPodResult(revID, "10.0.0.1", true)
+	}
+	if !ejected {
+		t.Fatal("ReportPodResult() didn't eject a pod failing every request")
+	}
+
+	// The private service would still send requests to the ejected pod.
+	for i := 0; i < 3; i++ {
+		if ip, _ := throttler.PodIP(revID); ip != "10.0.0.2" {
+			t.Errorf("PodIP() = %q, want 10.0.0.2", ip)
+		}
+	}
+}
+
+func TestThrottlerPreferLocalPods(t *testing.T) {
+	throttler := getThrottler(
+		200,
+		revisionLister(testNamespace, testRevision, 10),
+		endpointsInformer(testNamespace, testRevision, 1),
+		sksLister(testNamespace, testRevision),
+		TestLogger(t),
+		initCapacity)
+	nodes := nodeInformer(
+		node("node-a", "zone-a", "region-1"),
+		node("node-b", "zone-b", "region-1"),
+		node("node-c", "zone-c", "region-2"))
+	local, _ := nodes.Lister().Get("node-a")
+	throttler.PreferLocalPods(local, nodes.Lister())
+
+	throttler.podUpdated(podOnNode(revisionPod("pod-1", "10.0.0.1", true), "node-b"))
+	throttler.podUpdated(podOnNode(revisionPod("pod-2", "10.0.0.2", true), "node-c"))
+
+	// Without pods in our zone, we pick the ones in our region.
+	for i := 0; i < 3; i++ {
+		if ip, ok := throttler.PodIP(revID); !ok || ip != "10.0.0.1" {
+			t.Errorf("PodIP() = %q, %v, want 10.0.0.1, true", ip, ok)
+		}
+	}
+
+	throttler.podUpdated(podOnNode(revisionPod("pod-3", "10.0.0.3", true), "node-a"))
+	throttler.podLister = podsInformer(revisionPod("pod-1", "10.0.0.1", true),
+		revisionPod("pod-3", "10.0.0.3", true)).Lister()
+	for i := 0; i < 3; i++ {
+		if ip, ok := throttler.PodIP(revID); !ok || ip != "10.0.0.3" {
+			t.Errorf("PodIP() = %q, %v, want 10.0.0.3, true", ip, ok)
+		}
+	}
+
+	// Requests spill over once the local pods are out of capacity.
+	throttler.Register(registration("pod-3", "10.0.0.3", 0))
+	if ip, ok := throttler.PodIP(revID); !ok || ip != "10.0.0.1" {
+		t.Errorf("PodIP() = %q, %v, want 10.0.0.1, true", ip, ok)
+	}
+	throttler.Register(registration("pod-1", "10.0.0.1", 0))
+	if ip, ok := throttler.PodIP(revID); ok {
+		t.Errorf("PodIP() = %q, want none", ip)
+	}
+}
+
+func podsInformer(pods ...*corev1.Pod) corev1informers.PodInformer {
+	fake := kubefake.NewSimpleClientset()
+	informer := kubeinformers.NewSharedInformerFactory(fake, 0)
+	podInformer := informer.Core().V1().Pods()
+	for _, pod := range pods {
+		podInformer.Informer().GetIndexer().Add(pod)
+	}
+	return podInformer
+}
+
+func nodeInformer(nodes ...*corev1.Node) corev1informers.NodeInformer {
+	fake := kubefake.NewSimpleClientset()
+	informer := kubeinformers.NewSharedInformerFactory(fake, 0)
+	nodeInformer := informer.Core().V1().Nodes()
+	for _, node := range nodes {
+		nodeInformer.Informer().GetIndexer().Add(node)
+	}
+	return nodeInformer
+}
+
+func node(name, zone, region string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				ZoneLabelKey:   zone,
+				RegionLabelKey: region,
+			},
+		},
+	}
+}
+
+func podOnNode(pod *corev1.Pod, node string) *corev1.Pod {
+	pod.Spec.NodeName = node
+	return pod
+}
+
 func revisionLister(namespace, name string, concurrency v1beta1.RevisionContainerConcurrencyType) servinglisters.RevisionLister {
 	rev := &v1alpha1.Revision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ZoneLabelKey is the label of the nodes naming their zone.
+	ZoneLabelKey = "topology.kubernetes.io/zone"
+	// RegionLabelKey is the label of the nodes naming their region.
+	RegionLabelKey = "topology.kubernetes.io/region"
+
+	// The labels set by the clusters that predate the ones above.
+	legacyZoneLabelKey   = "failure-domain.beta.kubernetes.io/zone"
+	legacyRegionLabelKey = "failure-domain.beta.kubernetes.io/region"
+)
+
+// topology is where a node runs.
+type topology struct {
+	zone   string
+	region string
+}
+
+// nodeTopology returns the zone and region of the node, from its labels.
+func nodeTopology(node *corev1.Node) topology {
+	return topology{
+		zone:   firstLabel(node.Labels, ZoneLabelKey, legacyZoneLabelKey),
+		region: firstLabel(node.Labels, RegionLabelKey, legacyRegionLabelKey),
+	}
+}
+
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := labels[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
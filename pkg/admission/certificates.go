@@ -0,0 +1,316 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/webhook"
+)
+
+// The keys of the secrets holding the certificates of the webhooks.
+const (
+	SecretServerKey  = "server-key.pem"
+	SecretServerCert = "server-cert.pem"
+	// SecretCACert holds the CA certificates the API server trusts, the
+	// current one first. The previous ones are kept until they expire for
+	// the replicas still serving their certificates to be trusted.
+	SecretCACert = "ca-cert.pem"
+)
+
+const (
+	// RotationThreshold is how long before they expire the certificates
+	// of the webhooks are rotated. They are created valid for a year.
+	RotationThreshold = 30 * 24 * time.Hour
+
+	// CertificatesCheckPeriod is how often the certificates are checked
+	// for expiry, and reloaded from their secret.
+	CertificatesCheckPeriod = time.Minute
+
+	// CertificateExpirationN is the time the certificates of the webhooks
+	// expire at, in seconds since the epoch.
+	CertificateExpirationN = "certificate_expiration_time"
+)
+
+var (
+	certificateExpirationStat = stats.Int64(
+		CertificateExpirationN,
+		"The time the certificate of the webhook expires at",
+		"s")
+
+	secretNameKey = mustNewTagKey("secret_name")
+)
+
+func init() {
+	if err := view.Register(
+		&view.View{
+			Description: certificateExpirationStat.Description(),
+			Measure:     certificateExpirationStat,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{secretNameKey},
+		},
+	); err != nil {
+		panic(err)
+	}
+}
+
+// Certificates keeps the certificates a webhook serves with valid. They are
+// rotated in their secret well before they expire, and reloaded from it by
+// all the replicas without restarting. The CA certificate of the rotated
+// certificates is added to the webhook configuration of the webhook.
+type Certificates struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+	options webhook.ControllerOptions
+	logger  *zap.SugaredLogger
+	now     func() time.Time
+
+	m        sync.RWMutex
+	cert     *tls.Certificate
+	caBundle []byte
+}
+
+// NewCertificates creates the Certificates of the webhook with the given
+// options.
+func NewCertificates(client kubernetes.Interface, dynamic dynamic.Interface,
+	options webhook.ControllerOptions, logger *zap.SugaredLogger) *Certificates {
+	return &Certificates{
+		client:  client,
+		dynamic: dynamic,
+		options: options,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// GetCertificate returns the current certificate of the webhook, for
+// tls.Config.
+func (c *Certificates) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if c.cert == nil {
+		return nil, errors.New("the certificates of the webhook aren't loaded")
+	}
+	return c.cert, nil
+}
+
+// CABundle returns the CA certificates the webhook is registered with.
+func (c *Certificates) CABundle() []byte {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.caBundle
+}
+
+// Run reconciles the certificates every CertificatesCheckPeriod until stopCh
+// is closed.
+func (c *Certificates) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(CertificatesCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := c.Reconcile(); err != nil {
+				c.logger.Errorw("Failed to reconcile the certificates of the webhook", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reconcile creates the certificates of the webhook the first time, rotates
+// them when they expire within RotationThreshold, and loads them.
+func (c *Certificates) Reconcile() error {
+	secrets := c.client.CoreV1().Secrets(c.options.Namespace)
+	secret, err := secrets.Get(c.options.SecretName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		secret, err = c.createSecret()
+	}
+	if err != nil {
+		return err
+	}
+
+	expiry, err := certificateExpiry(secret.Data[SecretServerCert])
+	if err != nil || expiry.Sub(c.now()) < RotationThreshold {
+		c.logger.Infof("Rotating the certificates in secret %s/%s, expiring at %v", secret.Namespace, secret.Name, expiry)
+		if secret, err = c.rotate(secret); err != nil {
+			return fmt.Errorf("failed to rotate the certificates: %v", err)
+		}
+		if expiry, err = certificateExpiry(secret.Data[SecretServerCert]); err != nil {
+			return err
+		}
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[SecretServerCert], secret.Data[SecretServerKey])
+	if err != nil {
+		return err
+	}
+	c.m.Lock()
+	c.cert = &cert
+	c.caBundle = secret.Data[SecretCACert]
+	c.m.Unlock()
+	c.reportExpiry(expiry)
+
+	return c.updateCABundle(secret.Data[SecretCACert])
+}
+
+// createSecret creates the secret of the webhook, with new certificates.
+func (c *Certificates) createSecret() (*corev1.Secret, error) {
+	data, err := c.createCerts()
+	if err != nil {
+		return nil, err
+	}
+	secrets := c.client.CoreV1().Secrets(c.options.Namespace)
+	secret, err := secrets.Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.options.SecretName,
+			Namespace: c.options.Namespace,
+		},
+		Data: data,
+	})
+	if apierrs.IsAlreadyExists(err) {
+		// Another replica beat us to it.
+		return secrets.Get(c.options.SecretName, metav1.GetOptions{})
+	}
+	return secret, err
+}
+
+// rotate replaces the certificates in secret with new ones. The CA
+// certificates still valid are kept trusted.
+func (c *Certificates) rotate(secret *corev1.Secret) (*corev1.Secret, error) {
+	data, err := c.createCerts()
+	if err != nil {
+		return nil, err
+	}
+	caBundle := data[SecretCACert]
+	for rest := secret.Data[SecretCACert]; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil && c.now().Before(cert.NotAfter) {
+			caBundle = append(caBundle, pem.EncodeToMemory(block)...)
+		}
+	}
+	data[SecretCACert] = caBundle
+
+	secret = secret.DeepCopy()
+	secret.Data = data
+	// Replicas rotating at the same time conflict, only one of them
+	// succeeds and the others load its certificates on their next check.
+	return c.client.CoreV1().Secrets(secret.Namespace).Update(secret)
+}
+
+func (c *Certificates) createCerts() (map[string][]byte, error) {
+	ctx := logging.WithLogger(context.TODO(), c.logger)
+	serverKey, serverCert, caCert, err := webhook.CreateCerts(ctx, c.options.ServiceName, c.options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		SecretServerKey:  serverKey,
+		SecretServerCert: serverCert,
+		SecretCACert:     caCert,
+	}, nil
+}
+
+// updateCABundle registers caBundle in the webhook configuration of the
+// webhook. The webhook registers itself with the bundle when it starts,
+// but the certificates may be rotated by another replica since.
+func (c *Certificates) updateCABundle(caBundle []byte) error {
+	want := base64.StdEncoding.EncodeToString(caBundle)
+	for _, gvr := range webhookConfigurationResources {
+		err := updateWebhooks(c.dynamic.Resource(gvr), c.options.WebhookName, func(webhook map[string]interface{}) bool {
+			clientConfig, ok := webhook["clientConfig"].(map[string]interface{})
+			if !ok || clientConfig["caBundle"] == want {
+				return false
+			}
+			clientConfig["caBundle"] = want
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Certificates) reportExpiry(expiry time.Time) {
+	ctx, err := tag.New(context.Background(), tag.Insert(secretNameKey, c.options.SecretName))
+	if err != nil {
+		c.logger.Errorw("Failed to report the expiry of the certificates", zap.Error(err))
+		return
+	}
+	metrics.Record(ctx, certificateExpirationStat.M(expiry.Unix()))
+}
+
+// certificateExpiry returns when the PEM encoded certificate expires.
+func certificateExpiry(certPEM []byte) (time.Time, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return time.Time{}, errors.New("no PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
+
+// Serve serves handler over TLS on port until stopCh is closed, with the
+// certificates loaded by certs at the time of each handshake.
+func Serve(stopCh <-chan struct{}, port int, handler http.Handler, certs *Certificates) error {
+	server := &http.Server{
+		Handler: handler,
+		Addr:    fmt.Sprintf(":%v", port),
+		TLSConfig: &tls.Config{
+			GetCertificate: certs.GetCertificate,
+		},
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case <-stopCh:
+		return server.Close()
+	case err := <-errCh:
+		return err
+	}
+}
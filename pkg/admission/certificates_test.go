@@ -0,0 +1,216 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/metrics/metricstest"
+	"knative.dev/pkg/webhook"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func newTestCertificates(t *testing.T) *Certificates {
+	return NewCertificates(fakekube.NewSimpleClientset(),
+		fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+			webhookConfiguration("MutatingWebhookConfiguration", mutatingName, map[string]interface{}{
+				"name": mutatingName,
+				"clientConfig": map[string]interface{}{
+					"caBundle": base64.StdEncoding.EncodeToString([]byte("old-ca")),
+				},
+			})),
+		webhook.ControllerOptions{
+			ServiceName: "webhook",
+			Namespace:   "knative-serving",
+			SecretName:  "webhook-certs",
+			WebhookName: mutatingName,
+		}, TestLogger(t))
+}
+
+func registeredCABundle(t *testing.T, c *Certificates) []byte {
+	t.Helper()
+	u, err := c.dynamic.Resource(webhookConfigurationResources[0]).Get(mutatingName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	webhooks, _, _ := unstructured.NestedSlice(u.Object, "webhooks")
+	caBundle, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "caBundle")
+	b, err := base64.StdEncoding.DecodeString(caBundle)
+	if err != nil {
+		t.Fatalf("DecodeString() = %v", err)
+	}
+	return b
+}
+
+func pemBlocks(b []byte) int {
+	n := 0
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		n++
+	}
+	return n
+}
+
+func TestCertificatesCreated(t *testing.T) {
+	c := newTestCertificates(t)
+	if _, err := c.GetCertificate(nil); err == nil {
+		t.Error("GetCertificate() = nil, wanted an error before the certificates are loaded")
+	}
+
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	cert, err := c.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("GetCertificate() = %v", err)
+	}
+	caBundle := c.CABundle()
+	if got := pemBlocks(caBundle); got != 1 {
+		t.Errorf("CABundle() has %d certificates, want: 1", got)
+	}
+	if got := registeredCABundle(t, c); !bytes.Equal(got, caBundle) {
+		t.Errorf("Registered CA bundle = %q, want: %q", got, caBundle)
+	}
+	metricstest.CheckStatsReported(t, CertificateExpirationN)
+
+	// The certificates are created once.
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if !bytes.Equal(c.CABundle(), caBundle) {
+		t.Error("Reconcile() created new certificates")
+	}
+	if again, _ := c.GetCertificate(nil); !bytes.Equal(again.Certificate[0], cert.Certificate[0]) {
+		t.Error("Reconcile() loaded a new certificate")
+	}
+}
+
+func TestCertificatesRotated(t *testing.T) {
+	c := newTestCertificates(t)
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	cert, _ := c.GetCertificate(nil)
+	caBundle := c.CABundle()
+
+	// Within RotationThreshold of the expiry.
+	c.now = func() time.Time {
+		return time.Now().AddDate(1, 0, 0).Add(-RotationThreshold / 2)
+	}
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	rotated, _ := c.GetCertificate(nil)
+	if bytes.Equal(rotated.Certificate[0], cert.Certificate[0]) {
+		t.Error("Reconcile() didn't rotate the certificate")
+	}
+	// The new CA certificate is trusted along with the previous one.
+	newBundle := c.CABundle()
+	if got := pemBlocks(newBundle); got != 2 {
+		t.Errorf("CABundle() has %d certificates, want: 2", got)
+	}
+	if !bytes.HasSuffix(newBundle, caBundle) {
+		t.Error("CABundle() doesn't trust the previous CA certificate")
+	}
+	if got := registeredCABundle(t, c); !bytes.Equal(got, newBundle) {
+		t.Errorf("Registered CA bundle = %q, want: %q", got, newBundle)
+	}
+
+	// Expired CA certificates are dropped.
+	c.now = func() time.Time {
+		return time.Now().AddDate(2, 0, 0).Add(-RotationThreshold / 2)
+	}
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if got := pemBlocks(c.CABundle()); got != 1 {
+		t.Errorf("CABundle() has %d certificates, want: 1", got)
+	}
+}
+
+// webhookSecretVerbs returns the verbs the Role of the webhook in the
+// release config grants on secrets.
+func webhookSecretVerbs(t *testing.T) sets.String {
+	t.Helper()
+	f, err := os.Open("../../config/200-role.yaml")
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	defer f.Close()
+
+	verbs := sets.NewString()
+	dec := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var role rbacv1.Role
+		if err := dec.Decode(&role); err == io.EOF {
+			return verbs
+		} else if err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+		if role.Kind != "Role" || role.Name != "knative-serving-webhook" {
+			continue
+		}
+		for _, rule := range role.Rules {
+			if sets.NewString(rule.Resources...).Has("secrets") {
+				verbs.Insert(rule.Verbs...)
+			}
+		}
+	}
+}
+
+func TestCertificatesRotatedWithReleaseRBAC(t *testing.T) {
+	c := newTestCertificates(t)
+	verbs := webhookSecretVerbs(t)
+	c.client.(*fakekube.Clientset).PrependReactor("*", "secrets", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if verbs.Has(action.GetVerb()) {
+			return false, nil, nil
+		}
+		return true, nil, apierrs.NewForbidden(corev1.Resource("secrets"), "", fmt.Errorf("verb %q not granted", action.GetVerb()))
+	})
+
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	cert, _ := c.GetCertificate(nil)
+	c.now = func() time.Time {
+		return time.Now().AddDate(1, 0, 0).Add(-RotationThreshold / 2)
+	}
+	if err := c.Reconcile(); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if rotated, _ := c.GetCertificate(nil); bytes.Equal(rotated.Certificate[0], cert.Certificate[0]) {
+		t.Error("Reconcile() didn't rotate the certificate")
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+)
+
+const (
+	// ConfigName is the name of the ConfigMap configuring the webhooks.
+	ConfigName = "config-webhook"
+)
+
+// WebhookConfig is how the API server calls a webhook.
+type WebhookConfig struct {
+	// FailurePolicy is whether requests are admitted (Ignore) or rejected
+	// (Fail) when the webhook can't be called.
+	FailurePolicy admissionregistrationv1beta1.FailurePolicyType `json:"failurePolicy,omitempty"`
+
+	// NamespaceSelector restricts the webhook to the namespaces with
+	// matching labels.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ObjectSelector restricts the webhook to the objects with matching
+	// labels.
+	ObjectSelector *metav1.LabelSelector `json:"objectSelector,omitempty"`
+}
+
+// Config is how the API server calls the webhooks of serving.
+type Config struct {
+	// Webhooks maps the names of webhook configurations to how their
+	// webhooks are called.
+	Webhooks map[string]*WebhookConfig
+}
+
+// Webhook returns how the webhooks of the webhook configuration name are
+// called. Webhooks not configured fail closed, for all the objects.
+func (c *Config) Webhook(name string) *WebhookConfig {
+	if wc, ok := c.Webhooks[name]; ok {
+		return wc
+	}
+	return &WebhookConfig{FailurePolicy: admissionregistrationv1beta1.Fail}
+}
+
+// NewConfigFromConfigMap creates a Config from the supplied ConfigMap.
+func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
+	c := &Config{Webhooks: map[string]*WebhookConfig{}}
+	for name, v := range configMap.Data {
+		if name == configmap.ExampleKey {
+			continue
+		}
+		wc := &WebhookConfig{}
+		if err := yaml.Unmarshal([]byte(v), wc); err != nil {
+			return nil, fmt.Errorf("failed to parse the config of webhook %q: %v", name, err)
+		}
+		switch wc.FailurePolicy {
+		case "":
+			wc.FailurePolicy = admissionregistrationv1beta1.Fail
+		case admissionregistrationv1beta1.Fail, admissionregistrationv1beta1.Ignore:
+		default:
+			return nil, fmt.Errorf("failurePolicy of webhook %q must be %s or %s, was %q", name,
+				admissionregistrationv1beta1.Fail, admissionregistrationv1beta1.Ignore, wc.FailurePolicy)
+		}
+		for field, selector := range map[string]*metav1.LabelSelector{
+			"namespaceSelector": wc.NamespaceSelector,
+			"objectSelector":    wc.ObjectSelector,
+		} {
+			if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+				return nil, fmt.Errorf("invalid %s of webhook %q: %v", field, name, err)
+			}
+		}
+		c.Webhooks[name] = wc
+	}
+	return c, nil
+}
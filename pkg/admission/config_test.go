@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+)
+
+func TestNewConfigFromConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		want    *Config
+		wantErr bool
+	}{{
+		name: "empty",
+		data: map[string]string{
+			configmap.ExampleKey: "not: [parsed",
+		},
+		want: &Config{Webhooks: map[string]*WebhookConfig{}},
+	}, {
+		name: "selectors",
+		data: map[string]string{
+			"webhook.serving.knative.dev": `
+failurePolicy: Ignore
+namespaceSelector:
+  matchExpressions:
+  - key: serving.knative.dev/webhook
+    operator: NotIn
+    values: ["disabled"]
+objectSelector:
+  matchLabels:
+    app: foo`,
+			"protection.webhook.serving.knative.dev": `{}`,
+		},
+		want: &Config{Webhooks: map[string]*WebhookConfig{
+			"webhook.serving.knative.dev": {
+				FailurePolicy: admissionregistrationv1beta1.Ignore,
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      "serving.knative.dev/webhook",
+						Operator: metav1.LabelSelectorOpNotIn,
+						Values:   []string{"disabled"},
+					}},
+				},
+				ObjectSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "foo"},
+				},
+			},
+			"protection.webhook.serving.knative.dev": {
+				FailurePolicy: admissionregistrationv1beta1.Fail,
+			},
+		}},
+	}, {
+		name: "invalid failure policy",
+		data: map[string]string{
+			"webhook.serving.knative.dev": "failurePolicy: Sometimes",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid selector",
+		data: map[string]string{
+			"webhook.serving.knative.dev": `
+namespaceSelector:
+  matchExpressions:
+  - key: foo
+    operator: Near`,
+		},
+		wantErr: true,
+	}, {
+		name: "not yaml",
+		data: map[string]string{
+			"webhook.serving.knative.dev": "failurePolicy: [Fail",
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NewConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+				Data:       test.data,
+			})
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewConfigFromConfigMap() = %v, wantErr %v", err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("NewConfigFromConfigMap() (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigWebhookDefault(t *testing.T) {
+	c := &Config{}
+	want := &WebhookConfig{FailurePolicy: admissionregistrationv1beta1.Fail}
+	if diff := cmp.Diff(want, c.Webhook("webhook.serving.knative.dev")); diff != "" {
+		t.Errorf("Webhook() (-want, +got) = %s", diff)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ReconcilePeriod is how often the webhook configurations are reconciled,
+// since the webhooks reset them when they register.
+const ReconcilePeriod = time.Minute
+
+var webhookConfigurationResources = []schema.GroupVersionResource{{
+	Group:    "admissionregistration.k8s.io",
+	Version:  "v1beta1",
+	Resource: "mutatingwebhookconfigurations",
+}, {
+	Group:    "admissionregistration.k8s.io",
+	Version:  "v1beta1",
+	Resource: "validatingwebhookconfigurations",
+}}
+
+// Configurer applies the Config to the webhook configurations of serving.
+// The configurations are updated as unstructured objects, for fields the
+// typed clients don't know of, e.g. objectSelector, to be kept.
+type Configurer struct {
+	dynamic dynamic.Interface
+	logger  *zap.SugaredLogger
+	names   []string
+
+	m      sync.Mutex
+	config *Config
+}
+
+// NewConfigurer creates a Configurer of the webhook configurations with the
+// given names.
+func NewConfigurer(dynamic dynamic.Interface, logger *zap.SugaredLogger, names ...string) *Configurer {
+	return &Configurer{
+		dynamic: dynamic,
+		logger:  logger,
+		names:   names,
+		config:  &Config{},
+	}
+}
+
+// Update applies the config-webhook ConfigMap to the webhook configurations.
+// Invalid ConfigMaps are ignored, the previous Config stays applied.
+func (c *Configurer) Update(configMap *corev1.ConfigMap) {
+	config, err := NewConfigFromConfigMap(configMap)
+	if err != nil {
+		c.logger.Errorw("Failed to parse the config of the webhooks", zap.Error(err))
+		return
+	}
+	c.m.Lock()
+	c.config = config
+	c.m.Unlock()
+	if err := c.Reconcile(); err != nil {
+		c.logger.Errorw("Failed to reconcile the webhook configurations", zap.Error(err))
+	}
+}
+
+// Run reconciles the webhook configurations every ReconcilePeriod until
+// stopCh is closed.
+func (c *Configurer) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(ReconcilePeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := c.Reconcile(); err != nil {
+				c.logger.Errorw("Failed to reconcile the webhook configurations", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Reconcile applies the current Config to the webhook configurations.
+// Configurations not registered yet are skipped.
+func (c *Configurer) Reconcile() error {
+	c.m.Lock()
+	config := c.config
+	c.m.Unlock()
+	for _, name := range c.names {
+		wc, err := toUnstructured(config.Webhook(name))
+		if err != nil {
+			return err
+		}
+		for _, gvr := range webhookConfigurationResources {
+			if err := c.reconcile(gvr, name, wc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Configurer) reconcile(gvr schema.GroupVersionResource, name string, wc map[string]interface{}) error {
+	return updateWebhooks(c.dynamic.Resource(gvr), name, func(webhook map[string]interface{}) bool {
+		changed := false
+		for _, field := range []string{"failurePolicy", "namespaceSelector", "objectSelector"} {
+			want, ok := wc[field]
+			if equality.Semantic.DeepEqual(webhook[field], want) {
+				continue
+			}
+			changed = true
+			if ok {
+				webhook[field] = want
+			} else {
+				delete(webhook, field)
+			}
+		}
+		return changed
+	})
+}
+
+// updateWebhooks applies update to the webhooks of the webhook configuration
+// name, and updates the configuration when any of them changed. Webhook
+// configurations not registered yet are skipped.
+func updateWebhooks(client dynamic.ResourceInterface, name string, update func(webhook map[string]interface{}) bool) error {
+	configuration, err := client.Get(name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	webhooks, _, err := unstructured.NestedSlice(configuration.Object, "webhooks")
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, w := range webhooks {
+		if webhook, ok := w.(map[string]interface{}); ok && update(webhook) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := unstructured.SetNestedSlice(configuration.Object, webhooks, "webhooks"); err != nil {
+		return err
+	}
+	_, err = client.Update(configuration, metav1.UpdateOptions{})
+	return err
+}
+
+// toUnstructured returns the fields of the webhooks configured by wc, in the
+// form they have in unstructured objects.
+func toUnstructured(wc *WebhookConfig) (map[string]interface{}, error) {
+	b, err := json.Marshal(wc)
+	if err != nil {
+		return nil, err
+	}
+	u := map[string]interface{}{}
+	return u, json.Unmarshal(b, &u)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+const (
+	mutatingName   = "webhook.serving.knative.dev"
+	validatingName = "protection.webhook.serving.knative.dev"
+)
+
+func webhookConfiguration(kind, name string, webhook map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1beta1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name": name,
+		},
+		"webhooks": []interface{}{webhook},
+	}}
+}
+
+func webhooks(t *testing.T, c *Configurer) map[string]interface{} {
+	t.Helper()
+	got := map[string]interface{}{}
+	for i, name := range []string{mutatingName, validatingName} {
+		u, err := c.dynamic.Resource(webhookConfigurationResources[i]).Get(name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Get(%q) = %v", name, err)
+		}
+		webhooks, _, _ := unstructured.NestedSlice(u.Object, "webhooks")
+		got[name] = webhooks[0]
+	}
+	return got
+}
+
+func TestConfigurer(t *testing.T) {
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(),
+		webhookConfiguration("MutatingWebhookConfiguration", mutatingName, map[string]interface{}{
+			"name":          mutatingName,
+			"failurePolicy": "Fail",
+		}),
+		webhookConfiguration("ValidatingWebhookConfiguration", validatingName, map[string]interface{}{
+			"name":          validatingName,
+			"failurePolicy": "Fail",
+			// Set by hand, reset to the defaults.
+			"objectSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "foo"},
+			},
+		}))
+	c := NewConfigurer(dynamicClient, TestLogger(t), mutatingName, validatingName, "not-registered")
+
+	c.Update(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+		Data: map[string]string{
+			mutatingName: `
+failurePolicy: Ignore
+namespaceSelector:
+  matchLabels:
+    serving.knative.dev/webhook: enabled
+objectSelector:
+  matchLabels:
+    app: bar`,
+		},
+	})
+	want := map[string]interface{}{
+		mutatingName: map[string]interface{}{
+			"name":          mutatingName,
+			"failurePolicy": "Ignore",
+			"namespaceSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"serving.knative.dev/webhook": "enabled"},
+			},
+			"objectSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "bar"},
+			},
+		},
+		validatingName: map[string]interface{}{
+			"name":          validatingName,
+			"failurePolicy": "Fail",
+		},
+	}
+	if diff := cmp.Diff(want, webhooks(t, c)); diff != "" {
+		t.Errorf("Webhooks after Update (-want, +got) = %s", diff)
+	}
+
+	// Invalid configs are ignored.
+	c.Update(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigName},
+		Data:       map[string]string{mutatingName: "failurePolicy: Sometimes"},
+	})
+	if diff := cmp.Diff(want, webhooks(t, c)); diff != "" {
+		t.Errorf("Webhooks after invalid Update (-want, +got) = %s", diff)
+	}
+
+	// Removing the config restores the defaults.
+	c.Update(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ConfigName}})
+	want[mutatingName] = map[string]interface{}{
+		"name":          mutatingName,
+		"failurePolicy": "Fail",
+	}
+	if diff := cmp.Diff(want, webhooks(t, c)); diff != "" {
+		t.Errorf("Webhooks after reset (-want, +got) = %s", diff)
+	}
+}
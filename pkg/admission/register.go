@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/markbates/inflect"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/kmp"
+	"knative.dev/pkg/webhook"
+)
+
+// RegisterMutatingWebhook creates or updates the
+// MutatingWebhookConfiguration sending the creations and updates of the
+// resources of handlers to the webhook with the given options, as the
+// admission controller of knative.dev/pkg does when it runs. It is for the
+// admission controller to be served with rotated certificates, see Serve.
+func RegisterMutatingWebhook(client kubernetes.Interface, options webhook.ControllerOptions,
+	handlers map[schema.GroupVersionKind]webhook.GenericCRD, caBundle []byte) error {
+	failurePolicy := admissionregistrationv1beta1.Fail
+
+	rules := make([]admissionregistrationv1beta1.RuleWithOperations, 0, len(handlers))
+	for gvk := range handlers {
+		plural := strings.ToLower(inflect.Pluralize(gvk.Kind))
+		rules = append(rules, admissionregistrationv1beta1.RuleWithOperations{
+			Operations: []admissionregistrationv1beta1.OperationType{
+				admissionregistrationv1beta1.Create,
+				admissionregistrationv1beta1.Update,
+			},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{gvk.Group},
+				APIVersions: []string{gvk.Version},
+				Resources:   []string{plural + "/*"},
+			},
+		})
+	}
+	// Sort the rules for them to be compared with the registered ones.
+	sort.Slice(rules, func(i, j int) bool {
+		lhs, rhs := rules[i], rules[j]
+		if lhs.APIGroups[0] != rhs.APIGroups[0] {
+			return lhs.APIGroups[0] < rhs.APIGroups[0]
+		}
+		if lhs.APIVersions[0] != rhs.APIVersions[0] {
+			return lhs.APIVersions[0] < rhs.APIVersions[0]
+		}
+		return lhs.Resources[0] < rhs.Resources[0]
+	})
+
+	config := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: options.WebhookName,
+		},
+		Webhooks: []admissionregistrationv1beta1.Webhook{{
+			Name:  options.WebhookName,
+			Rules: rules,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: options.Namespace,
+					Name:      options.ServiceName,
+				},
+				CABundle: caBundle,
+			},
+			FailurePolicy: &failurePolicy,
+		}},
+	}
+
+	// Set the owner to our deployment.
+	deployment, err := client.AppsV1().Deployments(options.Namespace).Get(options.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch our deployment: %v", err)
+	}
+	config.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+	}
+
+	configs := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := configs.Get(options.WebhookName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		_, err = configs.Create(config)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if ok, err := kmp.SafeEqual(existing.Webhooks, config.Webhooks); err != nil {
+		return fmt.Errorf("error diffing webhooks: %v", err)
+	} else if ok {
+		return nil
+	}
+	config.ResourceVersion = existing.ResourceVersion
+	_, err = configs.Update(config)
+	return err
+}
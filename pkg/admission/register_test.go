@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/webhook"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+)
+
+func TestRegisterMutatingWebhook(t *testing.T) {
+	client := fakekube.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "webhook",
+			Namespace: "knative-serving",
+		},
+	})
+	options := webhook.ControllerOptions{
+		ServiceName:    "webhook",
+		DeploymentName: "webhook",
+		Namespace:      "knative-serving",
+		WebhookName:    mutatingName,
+	}
+	handlers := map[schema.GroupVersionKind]webhook.GenericCRD{
+		v1beta1.SchemeGroupVersion.WithKind("Service"):   &v1beta1.Service{},
+		v1alpha1.SchemeGroupVersion.WithKind("Service"):  &v1alpha1.Service{},
+		v1alpha1.SchemeGroupVersion.WithKind("Revision"): &v1alpha1.Revision{},
+	}
+
+	if err := RegisterMutatingWebhook(client, options, handlers, []byte("ca")); err != nil {
+		t.Fatalf("RegisterMutatingWebhook() = %v", err)
+	}
+	configs := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	config, err := configs.Get(mutatingName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	var resources []string
+	for _, rule := range config.Webhooks[0].Rules {
+		resources = append(resources, rule.APIVersions[0]+"/"+rule.Resources[0])
+	}
+	if diff := cmp.Diff([]string{"v1alpha1/revisions/*", "v1alpha1/services/*", "v1beta1/services/*"}, resources); diff != "" {
+		t.Errorf("Rules (-want, +got) = %s", diff)
+	}
+	if got, want := *config.Webhooks[0].FailurePolicy, admissionregistrationv1beta1.Fail; got != want {
+		t.Errorf("FailurePolicy = %v, want: %v", got, want)
+	}
+	if got, want := config.OwnerReferences[0].Name, "webhook"; got != want {
+		t.Errorf("Owner = %q, want: %q", got, want)
+	}
+
+	// Registered again with rotated certificates.
+	if err := RegisterMutatingWebhook(client, options, handlers, []byte("new-ca")); err != nil {
+		t.Fatalf("RegisterMutatingWebhook() = %v", err)
+	}
+	if config, err = configs.Get(mutatingName, metav1.GetOptions{}); err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got, want := string(config.Webhooks[0].ClientConfig.CABundle), "new-ca"; got != want {
+		t.Errorf("CABundle = %q, want: %q", got, want)
+	}
+}
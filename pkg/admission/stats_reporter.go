@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission configures, serves and reports the metrics of the
+// admission webhooks of serving.
+package admission
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/webhook"
+)
+
+const (
+	// RejectionCountN is the number of requests the webhooks rejected.
+	RejectionCountN = "rejection_count"
+)
+
+var (
+	rejectionCountStat = stats.Int64(
+		RejectionCountN,
+		"The number of requests rejected by the webhook",
+		stats.UnitDimensionless)
+
+	requestOperationKey = mustNewTagKey("request_operation")
+	kindKindKey         = mustNewTagKey("kind_kind")
+	reasonKey           = mustNewTagKey("reason")
+
+	// reasons classifies the messages of rejections, for the reasons
+	// reported to be few. The first reason whose substring is in the
+	// message applies, rejections matching none are reported as invalid.
+	reasons = []struct {
+		substring string
+		reason    string
+	}{
+		{"Quota of", "quota"},
+		{"Failed to check the quota", "quota"},
+		{"image policy", "image_policy"},
+		{"Immutable fields changed", "immutable"},
+		{"is protected by the", "protected"},
+		{"cannot decode", "decode"},
+	}
+)
+
+func init() {
+	// The request count and latencies are reported by the StatsReporter of
+	// knative.dev/pkg/webhook.
+	if err := view.Register(
+		&view.View{
+			Description: rejectionCountStat.Description(),
+			Measure:     rejectionCountStat,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{requestOperationKey, kindKindKey, reasonKey},
+		},
+	); err != nil {
+		panic(err)
+	}
+}
+
+type reporter struct {
+	webhook.StatsReporter
+}
+
+// NewStatsReporter creates a webhook.StatsReporter reporting the count and
+// the latencies of requests, per resource kind, as well as why requests
+// are rejected.
+func NewStatsReporter() (webhook.StatsReporter, error) {
+	sr, err := webhook.NewStatsReporter()
+	if err != nil {
+		return nil, err
+	}
+	return &reporter{StatsReporter: sr}, nil
+}
+
+// ReportRequest implements webhook.StatsReporter
+func (r *reporter) ReportRequest(req *admissionv1beta1.AdmissionRequest, resp *admissionv1beta1.AdmissionResponse, d time.Duration) error {
+	if err := r.StatsReporter.ReportRequest(req, resp, d); err != nil {
+		return err
+	}
+	if resp.Allowed {
+		return nil
+	}
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(requestOperationKey, string(req.Operation)),
+		tag.Insert(kindKindKey, req.Kind.Kind),
+		tag.Insert(reasonKey, rejectionReason(resp)))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, rejectionCountStat.M(1))
+	return nil
+}
+
+func rejectionReason(resp *admissionv1beta1.AdmissionResponse) string {
+	if resp.Result == nil {
+		return "unknown"
+	}
+	for _, r := range reasons {
+		if strings.Contains(resp.Result.Message, r.substring) {
+			return r.reason
+		}
+	}
+	return "invalid"
+}
+
+func mustNewTagKey(s string) tag.Key {
+	tagKey, err := tag.NewKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return tagKey
+}
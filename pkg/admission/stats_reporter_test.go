@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"testing"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestReportRequest(t *testing.T) {
+	r, err := NewStatsReporter()
+	if err != nil {
+		t.Fatalf("NewStatsReporter() = %v", err)
+	}
+	req := &admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Kind:      metav1.GroupVersionKind{Group: "serving.knative.dev", Version: "v1alpha1", Kind: "Service"},
+		Resource:  metav1.GroupVersionResource{Group: "serving.knative.dev", Version: "v1alpha1", Resource: "services"},
+		Namespace: "default",
+		Name:      "hello",
+	}
+
+	if err := r.ReportRequest(req, &admissionv1beta1.AdmissionResponse{Allowed: true}, 10*time.Millisecond); err != nil {
+		t.Fatalf("ReportRequest() = %v", err)
+	}
+	metricstest.CheckStatsNotReported(t, RejectionCountN)
+
+	rejected := &admissionv1beta1.AdmissionResponse{
+		Result: &metav1.Status{Message: `mutation failed: Quota of 10 exceeded: namespace "default" already holds 10 Services`},
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.ReportRequest(req, rejected, 10*time.Millisecond); err != nil {
+			t.Fatalf("ReportRequest() = %v", err)
+		}
+	}
+	metricstest.CheckCountData(t, RejectionCountN, map[string]string{
+		"request_operation": "CREATE",
+		"kind_kind":         "Service",
+		"reason":            "quota",
+	}, 2)
+	metricstest.CheckStatsReported(t, "request_count", "request_latencies")
+}
+
+func TestRejectionReason(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{{
+		message: `mutation failed: Quota of 10 exceeded: configuration "hello" already has 10 Revisions`,
+		want:    "quota",
+	}, {
+		message: "mutation failed: Failed to check the quota: connection refused",
+		want:    "quota",
+	}, {
+		message: "mutation failed: Images rejected by the image policy: helloworld",
+		want:    "image_policy",
+	}, {
+		message: "mutation failed: Immutable fields changed (-old +new): spec",
+		want:    "immutable",
+	}, {
+		message: `services.serving.knative.dev "hello" is forbidden: "hello" is protected by the serving.knative.dev/protected annotation`,
+		want:    "protected",
+	}, {
+		message: "mutation failed: cannot decode incoming new object: unexpected EOF",
+		want:    "decode",
+	}, {
+		message: "mutation failed: missing field(s): spec.template",
+		want:    "invalid",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			resp := &admissionv1beta1.AdmissionResponse{Result: &metav1.Status{Message: test.message}}
+			if got := rejectionReason(resp); got != test.want {
+				t.Errorf("rejectionReason(%q) = %s, want: %s", test.message, got, test.want)
+			}
+		})
+	}
+
+	if got := rejectionReason(&admissionv1beta1.AdmissionResponse{}); got != "unknown" {
+		t.Errorf("rejectionReason() without a result = %s, want: unknown", got)
+	}
+}
@@ -32,7 +32,7 @@ func getIntGE0(m map[string]string, k string) (int64, *apis.FieldError) {
 	}
 	i, err := strconv.ParseInt(v, 10, 32)
 	if err != nil || i < 0 {
-		return 0, apis.ErrOutOfBoundsValue(v, 1, math.MaxInt32, k)
+		return 0, apis.ErrOutOfBoundsValue(v, 0, math.MaxInt32, k)
 	}
 	return i, nil
 }
@@ -41,7 +41,8 @@ func ValidateAnnotations(anns map[string]string) *apis.FieldError {
 	if len(anns) == 0 {
 		return nil
 	}
-	return validateMinMaxScale(anns).Also(validateFloats(anns)).Also(validateWindows(anns))
+	return validateMinMaxScale(anns).Also(validateFloats(anns)).Also(validateWindows(anns)).
+		Also(validateClass(anns)).Also(validateKEDATriggers(anns))
 }
 
 func validateFloats(annotations map[string]string) *apis.FieldError {
@@ -107,6 +108,12 @@ func validateMinMaxScale(annotations map[string]string) *apis.FieldError {
 	max, err := getIntGE0(annotations, MaxScaleAnnotationKey)
 	errs = errs.Also(err)
 
+	// The pre-warm scale is set by the Service reconciler from the scale of
+	// another Revision, so it is capped by the max bound rather than checked
+	// against it.
+	_, err = getIntGE0(annotations, PreWarmScaleAnnotationKey)
+	errs = errs.Also(err)
+
 	if max != 0 && max < min {
 		errs = errs.Also(&apis.FieldError{
 			Message: fmt.Sprintf("maxScale=%d is less than minScale=%d", max, min),
@@ -115,3 +122,78 @@ func validateMinMaxScale(annotations map[string]string) *apis.FieldError {
 	}
 	return errs
 }
+
+// validateClass rejects the metrics the built-in classes of PodAutoscaler
+// can't scale on, and the annotations they would ignore. Other classes are
+// left alone.
+func validateClass(annotations map[string]string) *apis.FieldError {
+	class := annotations[ClassAnnotationKey]
+	if class == "" {
+		class = KPA
+	}
+	metric, hasMetric := annotations[MetricAnnotationKey]
+
+	var unsupported []string
+	switch class {
+	case KPA:
+		if !hasMetric {
+			metric = Concurrency
+		}
+		if metric != Concurrency {
+			return unsupportedMetric(class, metric)
+		}
+	case HPA:
+		if !hasMetric {
+			metric = CPU
+		}
+		if metric != CPU && metric != Concurrency {
+			return unsupportedMetric(class, metric)
+		}
+		// The HPA doesn't panic, and averages the CPU usage itself.
+		unsupported = []string{PanicWindowPercentageAnnotationKey, PanicThresholdPercentageAnnotationKey}
+		if metric == CPU {
+			unsupported = append(unsupported, WindowAnnotationKey)
+		}
+	default:
+		return nil
+	}
+
+	var paths []string
+	for _, key := range unsupported {
+		if _, ok := annotations[key]; ok {
+			paths = append(paths, key)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Unsupported annotations for PodAutoscaler class %q with metric %q", class, metric),
+		Paths:   paths,
+	}
+}
+
+func unsupportedMetric(class, metric string) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Unsupported metric %q for PodAutoscaler class %q", metric, class),
+		Paths:   []string{MetricAnnotationKey},
+	}
+}
+
+func validateKEDATriggers(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[KEDATriggersAnnotationKey]
+	if !ok {
+		if annotations[ClassAnnotationKey] == KEDA {
+			return apis.ErrMissingField(KEDATriggersAnnotationKey)
+		}
+		return nil
+	}
+	if _, err := ParseKEDATriggers(v); err != nil {
+		return &apis.FieldError{
+			Message: fmt.Sprintf("invalid value: %s", v),
+			Paths:   []string{KEDATriggersAnnotationKey},
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
@@ -43,23 +43,23 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 	}, {
 		name:        "minScale is -1",
 		annotations: map[string]string{MinScaleAnnotationKey: "-1"},
-		expectErr:   "expected 1 <= -1 <= 2147483647: autoscaling.knative.dev/minScale",
+		expectErr:   "expected 0 <= -1 <= 2147483647: autoscaling.knative.dev/minScale",
 	}, {
 		name:        "maxScale is -1",
 		annotations: map[string]string{MaxScaleAnnotationKey: "-1"},
-		expectErr:   "expected 1 <= -1 <= 2147483647: autoscaling.knative.dev/maxScale",
+		expectErr:   "expected 0 <= -1 <= 2147483647: autoscaling.knative.dev/maxScale",
 	}, {
 		name:        "minScale is foo",
 		annotations: map[string]string{MinScaleAnnotationKey: "foo"},
-		expectErr:   "expected 1 <= foo <= 2147483647: autoscaling.knative.dev/minScale",
+		expectErr:   "expected 0 <= foo <= 2147483647: autoscaling.knative.dev/minScale",
 	}, {
 		name:        "maxScale is bar",
 		annotations: map[string]string{MaxScaleAnnotationKey: "bar"},
-		expectErr:   "expected 1 <= bar <= 2147483647: autoscaling.knative.dev/maxScale",
+		expectErr:   "expected 0 <= bar <= 2147483647: autoscaling.knative.dev/maxScale",
 	}, {
 		name:        "max/minScale is bar",
 		annotations: map[string]string{MaxScaleAnnotationKey: "bar", MinScaleAnnotationKey: "bar"},
-		expectErr:   "expected 1 <= bar <= 2147483647: autoscaling.knative.dev/maxScale, autoscaling.knative.dev/minScale",
+		expectErr:   "expected 0 <= bar <= 2147483647: autoscaling.knative.dev/maxScale, autoscaling.knative.dev/minScale",
 	}, {
 		name:        "minScale is 5",
 		annotations: map[string]string{MinScaleAnnotationKey: "5"},
@@ -79,6 +79,16 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 			MinScaleAnnotationKey: "0",
 			MaxScaleAnnotationKey: "0",
 		},
+	}, {
+		name: "preWarmScale above maxScale",
+		annotations: map[string]string{
+			MaxScaleAnnotationKey:     "2",
+			PreWarmScaleAnnotationKey: "5",
+		},
+	}, {
+		name:        "preWarmScale is -1",
+		annotations: map[string]string{PreWarmScaleAnnotationKey: "-1"},
+		expectErr:   "expected 0 <= -1 <= 2147483647: autoscaling.knative.dev/preWarmScale",
 	}, {
 		name:        "panic window percentange bad",
 		annotations: map[string]string{PanicWindowPercentageAnnotationKey: "-1"},
@@ -134,6 +144,73 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 		name:        "TBC invalid",
 		annotations: map[string]string{TargetBurstCapacityKey: "qarashen"},
 		expectErr:   "invalid value: qarashen: autoscaling.knative.dev/targetBurstCapacity",
+	}, {
+		name: "keda triggers",
+		annotations: map[string]string{
+			ClassAnnotationKey:        KEDA,
+			KEDATriggersAnnotationKey: `[{"type": "aws-sqs-queue", "metadata": {"queueLength": "5"}, "authenticationRef": {"name": "sqs"}}]`,
+		},
+	}, {
+		name:        "keda class without triggers",
+		annotations: map[string]string{ClassAnnotationKey: KEDA},
+		expectErr:   "missing field(s): autoscaling.knative.dev/kedaTriggers",
+	}, {
+		name:        "keda triggers not json",
+		annotations: map[string]string{KEDATriggersAnnotationKey: "kafka"},
+		expectErr:   "invalid value: kafka: autoscaling.knative.dev/kedaTriggers\ninvalid character 'k' looking for beginning of value",
+	}, {
+		name:        "keda triggers empty",
+		annotations: map[string]string{KEDATriggersAnnotationKey: "[]"},
+		expectErr:   "invalid value: []: autoscaling.knative.dev/kedaTriggers\nat least one trigger is required",
+	}, {
+		name:        "keda trigger without type",
+		annotations: map[string]string{KEDATriggersAnnotationKey: `[{"metadata": {"topic": "orders"}}]`},
+		expectErr:   "invalid value: [{\"metadata\": {\"topic\": \"orders\"}}]: autoscaling.knative.dev/kedaTriggers\ntrigger 0 has no type",
+	}, {
+		name:        "kpa with concurrency",
+		annotations: map[string]string{ClassAnnotationKey: KPA, MetricAnnotationKey: Concurrency},
+	}, {
+		name:        "kpa with cpu",
+		annotations: map[string]string{ClassAnnotationKey: KPA, MetricAnnotationKey: CPU},
+		expectErr:   `Unsupported metric "cpu" for PodAutoscaler class "kpa.autoscaling.knative.dev": autoscaling.knative.dev/metric`,
+	}, {
+		name:        "default class with cpu",
+		annotations: map[string]string{MetricAnnotationKey: CPU},
+		expectErr:   `Unsupported metric "cpu" for PodAutoscaler class "kpa.autoscaling.knative.dev": autoscaling.knative.dev/metric`,
+	}, {
+		name:        "hpa with unknown metric",
+		annotations: map[string]string{ClassAnnotationKey: HPA, MetricAnnotationKey: "rps"},
+		expectErr:   `Unsupported metric "rps" for PodAutoscaler class "hpa.autoscaling.knative.dev": autoscaling.knative.dev/metric`,
+	}, {
+		name: "hpa with concurrency and window",
+		annotations: map[string]string{
+			ClassAnnotationKey:  HPA,
+			MetricAnnotationKey: Concurrency,
+			WindowAnnotationKey: "30s",
+		},
+	}, {
+		name: "hpa with panic annotations",
+		annotations: map[string]string{
+			ClassAnnotationKey:                    HPA,
+			MetricAnnotationKey:                   Concurrency,
+			PanicWindowPercentageAnnotationKey:    "10",
+			PanicThresholdPercentageAnnotationKey: "200",
+		},
+		expectErr: `Unsupported annotations for PodAutoscaler class "hpa.autoscaling.knative.dev" with metric "concurrency": autoscaling.knative.dev/panicThresholdPercentage, autoscaling.knative.dev/panicWindowPercentage`,
+	}, {
+		name: "hpa with cpu and window",
+		annotations: map[string]string{
+			ClassAnnotationKey:  HPA,
+			WindowAnnotationKey: "30s",
+		},
+		expectErr: `Unsupported annotations for PodAutoscaler class "hpa.autoscaling.knative.dev" with metric "cpu": autoscaling.knative.dev/window`,
+	}, {
+		name: "custom class with any metric",
+		annotations: map[string]string{
+			ClassAnnotationKey:                 "yolo.sandwich.com",
+			MetricAnnotationKey:                "rps",
+			PanicWindowPercentageAnnotationKey: "10",
+		},
 	}, {
 		name:        "TU too small",
 		annotations: map[string]string{TargetUtilizationPercentageKey: "0"},
@@ -166,7 +243,7 @@ func TestValidateScaleBoundAnnotations(t *testing.T) {
 			MinScaleAnnotationKey:                 "-4",
 			MaxScaleAnnotationKey:                 "never",
 		},
-		expectErr: "expected 1 <= -11 <= 100: autoscaling.knative.dev/panicWindowPercentage\nexpected 1 <= -4 <= 2147483647: autoscaling.knative.dev/minScale\nexpected 1 <= never <= 2147483647: autoscaling.knative.dev/maxScale\ninvalid value: fifty: autoscaling.knative.dev/panicThresholdPercentage",
+		expectErr: "expected 0 <= -4 <= 2147483647: autoscaling.knative.dev/minScale\nexpected 0 <= never <= 2147483647: autoscaling.knative.dev/maxScale\nexpected 1 <= -11 <= 100: autoscaling.knative.dev/panicWindowPercentage\ninvalid value: fifty: autoscaling.knative.dev/panicThresholdPercentage",
 	}, {
 		name: "all together now, succeed",
 		annotations: map[string]string{
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// KEDATrigger is a single KEDA scaler a keda.autoscaling.knative.dev class
+// PodAutoscaler scales on. It mirrors the trigger spec of a KEDA ScaledObject.
+type KEDATrigger struct {
+	// Type is the KEDA scaler, e.g. kafka or aws-sqs-queue.
+	Type string `json:"type"`
+	// Metadata is the scaler specific configuration.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// AuthenticationRef optionally names the TriggerAuthentication the
+	// scaler uses.
+	AuthenticationRef *KEDAAuthenticationRef `json:"authenticationRef,omitempty"`
+}
+
+// KEDAAuthenticationRef references a KEDA TriggerAuthentication in the
+// namespace of the PodAutoscaler.
+type KEDAAuthenticationRef struct {
+	Name string `json:"name"`
+}
+
+// ParseKEDATriggers parses the value of the KEDATriggersAnnotationKey
+// annotation.
+func ParseKEDATriggers(v string) ([]KEDATrigger, error) {
+	var triggers []KEDATrigger
+	if err := json.Unmarshal([]byte(v), &triggers); err != nil {
+		return nil, err
+	}
+	if len(triggers) == 0 {
+		return nil, errors.New("at least one trigger is required")
+	}
+	for i, t := range triggers {
+		if t.Type == "" {
+			return nil, fmt.Errorf("trigger %d has no type", i)
+		}
+		if t.AuthenticationRef != nil && t.AuthenticationRef.Name == "" {
+			return nil, fmt.Errorf("trigger %d has an authenticationRef without a name", i)
+		}
+	}
+	return triggers, nil
+}
@@ -32,6 +32,8 @@ const (
 	KPA = "kpa.autoscaling.knative.dev"
 	// HPA is Kubernetes Horizontal Pod Autoscaler
 	HPA = "hpa.autoscaling.knative.dev"
+	// KEDA is Kubernetes Event-driven Autoscaling, scaling on KEDA ScaledObjects
+	KEDA = "keda.autoscaling.knative.dev"
 
 	// MinScaleAnnotationKey is the annotation to specify the minimum number of Pods
 	// the PodAutoscaler should provision. For example,
@@ -41,6 +43,18 @@ const (
 	// the PodAutoscaler should provision. For example,
 	//   autoscaling.knative.dev/maxScale: "10"
 	MaxScaleAnnotationKey = GroupName + "/maxScale"
+	// PreWarmScaleAnnotationKey is the annotation the Service reconciler
+	// attaches to the PodAutoscaler of a Revision being rolled out in
+	// blue/green mode, to scale it up to the current scale of the Revision
+	// serving the traffic before the traffic is switched over to it. It
+	// raises the minimum scale of the PodAutoscaler.
+	PreWarmScaleAnnotationKey = GroupName + "/preWarmScale"
+
+	// KEDATriggersAnnotationKey is the annotation to specify the KEDA triggers
+	// a keda.autoscaling.knative.dev class PodAutoscaler scales on, as a JSON list.
+	// For example,
+	//   autoscaling.knative.dev/kedaTriggers: '[{"type": "kafka", "metadata": {"topic": "orders", "lagThreshold": "50"}}]'
+	KEDATriggersAnnotationKey = GroupName + "/kedaTriggers"
 
 	// MetricAnnotationKey is the annotation to specify what metric the PodAutoscaler
 	// should be scaled on. For example,
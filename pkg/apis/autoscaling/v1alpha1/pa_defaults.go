@@ -45,7 +45,9 @@ func (r *PodAutoscaler) SetDefaults(ctx context.Context) {
 	}
 	// Default metric per class
 	if _, ok := r.Annotations[autoscaling.MetricAnnotationKey]; !ok {
-		r.Annotations[autoscaling.MetricAnnotationKey] = defaultMetric(r.Class())
+		if m := defaultMetric(r.Class()); m != "" {
+			r.Annotations[autoscaling.MetricAnnotationKey] = m
+		}
 	}
 }
 
@@ -78,6 +78,22 @@ func TestPodAutoscalerDefaulting(t *testing.T) {
 				ContainerConcurrency: 0,
 			},
 		},
+	}, {
+		name: "keda class has no default metric",
+		in: &PodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey: autoscaling.KEDA,
+				},
+			},
+		},
+		want: &PodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					autoscaling.ClassAnnotationKey: autoscaling.KEDA,
+				},
+			},
+		},
 	}, {
 		name: "hpa class is not overwritten and defaults to cpu",
 		in: &PodAutoscaler{
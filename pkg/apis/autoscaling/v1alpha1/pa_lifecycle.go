@@ -27,6 +27,7 @@ import (
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 var podCondSet = apis.NewLivingConditionSet(
@@ -77,10 +78,22 @@ func (pa *PodAutoscaler) annotationFloat64(key string) (float64, bool) {
 
 // ScaleBounds returns scale bounds annotations values as a tuple:
 // `(min, max int32)`. The value of 0 for any of min or max means the bound is
-// not set
+// not set. The min bound of suspended PodAutoscalers is not set, and that
+// of PodAutoscalers being pre-warmed is at least their pre-warm scale, up to
+// their max bound.
 func (pa *PodAutoscaler) ScaleBounds() (min, max int32) {
-	return pa.annotationInt32(autoscaling.MinScaleAnnotationKey),
-		pa.annotationInt32(autoscaling.MaxScaleAnnotationKey)
+	max = pa.annotationInt32(autoscaling.MaxScaleAnnotationKey)
+	if pa.Annotations[serving.SuspendedAnnotationKey] != "true" {
+		min = pa.annotationInt32(autoscaling.MinScaleAnnotationKey)
+		preWarm := pa.annotationInt32(autoscaling.PreWarmScaleAnnotationKey)
+		if max != 0 && preWarm > max {
+			preWarm = max
+		}
+		if preWarm > min {
+			min = preWarm
+		}
+	}
+	return min, max
 }
 
 // Target returns the target annotation value or false if not present, or invalid.
@@ -170,6 +183,24 @@ func (pas *PodAutoscalerStatus) MarkInactive(reason, message string) {
 	podCondSet.Manage(pas.duck()).MarkFalse(PodAutoscalerConditionActive, reason, message)
 }
 
+// MarkWaitingOnNodes marks the PA as having the given number of pods that
+// can't be scheduled until nodes are provisioned for them.
+func (pas *PodAutoscalerStatus) MarkWaitingOnNodes(pending int) {
+	podCondSet.Manage(pas.duck()).MarkFalse(PodAutoscalerConditionNodesAvailable, "WaitingOnNodes",
+		"%d pods are waiting for nodes to be provisioned.", pending)
+}
+
+// MarkNodesAvailable marks the PA as having all of its pods scheduled.
+func (pas *PodAutoscalerStatus) MarkNodesAvailable() {
+	podCondSet.Manage(pas.duck()).MarkTrue(PodAutoscalerConditionNodesAvailable)
+}
+
+// IsWaitingOnNodes returns true if pods of the PA are waiting for nodes.
+func (pas *PodAutoscalerStatus) IsWaitingOnNodes() bool {
+	cond := pas.GetCondition(PodAutoscalerConditionNodesAvailable)
+	return cond != nil && cond.Status == corev1.ConditionFalse
+}
+
 // MarkResourceNotOwned changes the "Active" condition to false to reflect that the
 // resource of the given kind and name has already been created, and we do not own it.
 func (pas *PodAutoscalerStatus) MarkResourceNotOwned(kind, name string) {
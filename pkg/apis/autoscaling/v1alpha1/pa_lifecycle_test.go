@@ -27,6 +27,7 @@ import (
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	apitest "knative.dev/pkg/apis/testing"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 func TestPodAutoscalerDuckTypes(t *testing.T) {
@@ -543,6 +544,40 @@ func TestScaleBounds(t *testing.T) {
 		}),
 		wantMin: 0,
 		wantMax: 0,
+	}, {
+		name: "suspended",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey: "1",
+			autoscaling.MaxScaleAnnotationKey: "100",
+			serving.SuspendedAnnotationKey:    "true",
+		}),
+		wantMin: 0,
+		wantMax: 100,
+	}, {
+		name: "pre-warmed",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey:     "1",
+			autoscaling.PreWarmScaleAnnotationKey: "5",
+		}),
+		wantMin: 5,
+		wantMax: 0,
+	}, {
+		name: "pre-warmed below min",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey:     "3",
+			autoscaling.PreWarmScaleAnnotationKey: "2",
+		}),
+		wantMin: 3,
+		wantMax: 0,
+	}, {
+		name: "pre-warmed above max",
+		pa: pa(map[string]string{
+			autoscaling.MinScaleAnnotationKey:     "1",
+			autoscaling.MaxScaleAnnotationKey:     "4",
+			autoscaling.PreWarmScaleAnnotationKey: "6",
+		}),
+		wantMin: 4,
+		wantMax: 4,
 	}}
 
 	for _, tc := range cases {
@@ -584,6 +619,30 @@ func TestMarkResourceFailedCreation(t *testing.T) {
 	}
 }
 
+func TestWaitingOnNodes(t *testing.T) {
+	pa := &PodAutoscalerStatus{}
+	pa.InitializeConditions()
+	pa.MarkActive()
+
+	pa.MarkWaitingOnNodes(3)
+	if !pa.IsWaitingOnNodes() {
+		t.Error("IsWaitingOnNodes = false, want true")
+	}
+	cond := pa.GetCondition(PodAutoscalerConditionNodesAvailable)
+	if got, want := cond.Reason, "WaitingOnNodes"; got != want {
+		t.Errorf("Reason = %q, want %q", got, want)
+	}
+	// Waiting on nodes does not make the PA unready.
+	apitest.CheckConditionSucceeded(pa.duck(), PodAutoscalerConditionReady, t)
+
+	pa.MarkNodesAvailable()
+	if pa.IsWaitingOnNodes() {
+		t.Error("IsWaitingOnNodes = true, want false")
+	}
+	apitest.CheckConditionSucceeded(pa.duck(), PodAutoscalerConditionNodesAvailable, t)
+	apitest.CheckConditionSucceeded(pa.duck(), PodAutoscalerConditionReady, t)
+}
+
 func TestClass(t *testing.T) {
 	cases := []struct {
 		name string
@@ -93,6 +93,11 @@ type PodAutoscalerSpec struct {
 
 	// The application-layer protocol. Matches `ProtocolType` inferred from the revision spec.
 	ProtocolType net.ProtocolType
+
+	// Ports are the ports of the revision besides the one serving its
+	// requests, which its services expose as well.
+	// +optional
+	Ports []net.NamedPort `json:"ports,omitempty"`
 }
 
 const (
@@ -101,6 +106,10 @@ const (
 	PodAutoscalerConditionReady = apis.ConditionReady
 	// PodAutoscalerConditionActive is set when the PodAutoscaler's ScaleTargetRef is receiving traffic.
 	PodAutoscalerConditionActive apis.ConditionType = "Active"
+	// PodAutoscalerConditionNodesAvailable is set to false while pods of the
+	// ScaleTargetRef can't be scheduled for lack of capacity, i.e. are waiting
+	// for the cluster autoscaler to provision nodes. It does not affect Ready.
+	PodAutoscalerConditionNodesAvailable apis.ConditionType = "NodesAvailable"
 )
 
 // PodAutoscalerStatus communicates the observed state of the PodAutoscaler (from the controller).
@@ -114,6 +123,17 @@ type PodAutoscalerStatus struct {
 	// MetricsServiceName is the K8s Service name that provides revision metrics.
 	// The service is managed by the PA object.
 	MetricsServiceName string `json:"metricsServiceName"`
+
+	// IdleSince is the time the revision was last sent a request at, once
+	// it went without requests for a stable window. It is unset while the
+	// revision is active.
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+
+	// ActualScale is the number of ready pods of the revision. It is only
+	// reported by PodAutoscalers of the KPA class.
+	// +optional
+	ActualScale *int32 `json:"actualScale,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
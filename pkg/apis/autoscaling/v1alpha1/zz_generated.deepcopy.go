@@ -23,6 +23,7 @@ package v1alpha1
 import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	networking "knative.dev/serving/pkg/apis/networking"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -123,7 +124,7 @@ func (in *PodAutoscaler) DeepCopyInto(out *PodAutoscaler) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -183,6 +184,11 @@ func (in *PodAutoscalerList) DeepCopyObject() runtime.Object {
 func (in *PodAutoscalerSpec) DeepCopyInto(out *PodAutoscalerSpec) {
 	*out = *in
 	out.ScaleTargetRef = in.ScaleTargetRef
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]networking.NamedPort, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -200,6 +206,15 @@ func (in *PodAutoscalerSpec) DeepCopy() *PodAutoscalerSpec {
 func (in *PodAutoscalerStatus) DeepCopyInto(out *PodAutoscalerStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.ActualScale != nil {
+		in, out := &in.ActualScale, &out.ActualScale
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
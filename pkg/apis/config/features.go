@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// FeaturesConfigName is the name of config map for the feature flags.
+	FeaturesConfigName = "config-features"
+
+	// Enabled turns a feature on.
+	Enabled Flag = "enabled"
+	// Disabled turns a feature off.
+	Disabled Flag = "disabled"
+)
+
+// Flag is the state of a feature, Enabled or Disabled.
+type Flag string
+
+// NewFeaturesConfigFromMap creates a Features from the supplied Map
+func NewFeaturesConfigFromMap(data map[string]string) (*Features, error) {
+	nc := &Features{}
+
+	for _, f := range []struct {
+		key   string
+		field *Flag
+	}{{
+		key:   "kubernetes.podspec-dnspolicy",
+		field: &nc.PodSpecDNSPolicy,
+	}, {
+		key:   "kubernetes.podspec-dnsconfig",
+		field: &nc.PodSpecDNSConfig,
+	}, {
+		key:   "kubernetes.podspec-hostaliases",
+		field: &nc.PodSpecHostAliases,
+	}, {
+		key:   "tag-watching",
+		field: &nc.TagWatching,
+	}, {
+		key:   "reference-validation",
+		field: &nc.ReferenceValidation,
+	}, {
+		key:   "tcp-serving",
+		field: &nc.TCPServing,
+	}} {
+		raw, ok := data[f.key]
+		if !ok {
+			*f.field = Disabled
+			continue
+		}
+		switch flag := Flag(strings.ToLower(raw)); flag {
+		case Enabled, Disabled:
+			*f.field = flag
+		default:
+			return nil, fmt.Errorf("%s must be %q or %q, was %q", f.key, Enabled, Disabled, raw)
+		}
+	}
+
+	return nc, nil
+}
+
+// NewFeaturesConfigFromConfigMap creates a Features from the supplied configMap
+func NewFeaturesConfigFromConfigMap(config *corev1.ConfigMap) (*Features, error) {
+	return NewFeaturesConfigFromMap(config.Data)
+}
+
+// Features holds the feature flags of the API. All of them are Disabled
+// unless turned on in the config-features ConfigMap.
+type Features struct {
+	// PodSpecDNSPolicy allows the dnsPolicy field of the PodSpec.
+	PodSpecDNSPolicy Flag
+	// PodSpecDNSConfig allows the dnsConfig field of the PodSpec.
+	PodSpecDNSConfig Flag
+	// PodSpecHostAliases allows the hostAliases field of the PodSpec.
+	PodSpecHostAliases Flag
+	// TagWatching redeploys the Configurations asking for it when the tag
+	// of their image is pushed to.
+	TagWatching Flag
+	// ReferenceValidation rejects the Services and Configurations referring
+	// to Secrets, ConfigMaps or ServiceAccounts that don't exist.
+	ReferenceValidation Flag
+	// TCPServing allows the Revisions serving raw TCP streams, which the
+	// queue-proxy and the activator proxy at L4.
+	TCPServing Flag
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+
+	. "knative.dev/pkg/configmap/testing"
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestFeaturesConfigurationFromFile(t *testing.T) {
+	cm, example := ConfigMapsFromTestFile(t, FeaturesConfigName)
+
+	if _, err := NewFeaturesConfigFromConfigMap(cm); err != nil {
+		t.Errorf("NewFeaturesConfigFromConfigMap(actual) = %v", err)
+	}
+
+	if _, err := NewFeaturesConfigFromConfigMap(example); err != nil {
+		t.Errorf("NewFeaturesConfigFromConfigMap(example) = %v", err)
+	}
+}
+
+func TestFeaturesConfiguration(t *testing.T) {
+	configTests := []struct {
+		name         string
+		wantErr      bool
+		wantFeatures *Features
+		data         map[string]string
+	}{{
+		name:    "default features",
+		wantErr: false,
+		wantFeatures: &Features{
+			PodSpecDNSPolicy:    Disabled,
+			PodSpecDNSConfig:    Disabled,
+			PodSpecHostAliases:  Disabled,
+			TagWatching:         Disabled,
+			ReferenceValidation: Disabled,
+			TCPServing:          Disabled,
+		},
+		data: map[string]string{},
+	}, {
+		name:    "enabled features",
+		wantErr: false,
+		wantFeatures: &Features{
+			PodSpecDNSPolicy:    Enabled,
+			PodSpecDNSConfig:    Disabled,
+			PodSpecHostAliases:  Enabled,
+			TagWatching:         Enabled,
+			ReferenceValidation: Enabled,
+			TCPServing:          Enabled,
+		},
+		data: map[string]string{
+			"kubernetes.podspec-dnspolicy":   "Enabled",
+			"kubernetes.podspec-dnsconfig":   "disabled",
+			"kubernetes.podspec-hostaliases": "enabled",
+			"tag-watching":                   "enabled",
+			"reference-validation":           "enabled",
+			"tcp-serving":                    "enabled",
+		},
+	}, {
+		name:    "bad flag",
+		wantErr: true,
+		data: map[string]string{
+			"kubernetes.podspec-dnspolicy": "yes",
+		},
+	}}
+
+	for _, tt := range configTests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualFeatures, err := NewFeaturesConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      FeaturesConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFeaturesConfigFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantFeatures, actualFeatures); diff != "" {
+				t.Errorf("NewFeaturesConfigFromConfigMap() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
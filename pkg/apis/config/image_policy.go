@@ -0,0 +1,208 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ImagePolicyConfigName is the name of config map for the image policy.
+	ImagePolicyConfigName = "config-image-policy"
+
+	// DisabledEnforcement admits Revisions without checking their images.
+	DisabledEnforcement EnforcementMode = "disabled"
+	// WarnEnforcement admits Revisions whose images the policy rejects,
+	// logging a warning.
+	WarnEnforcement EnforcementMode = "warn"
+	// Enforce rejects Revisions whose images the policy rejects, or when the
+	// policy can't be checked.
+	Enforce EnforcementMode = "enforce"
+
+	// DefaultImagePolicyTimeout is how long the policy service has to
+	// review the images of a Revision, unless configured otherwise.
+	DefaultImagePolicyTimeout = 5 * time.Second
+
+	enforcementModeKey   = "enforcement-mode"
+	allowedRegistriesKey = "allowed-registries"
+
+	// AnyRegistry allows the images of any registry, e.g. in the namespaces
+	// exempted from the allowed registries.
+	AnyRegistry = "*"
+)
+
+// EnforcementMode is how the image policy is enforced in a namespace.
+type EnforcementMode string
+
+// NewImagePolicyConfigFromMap creates an ImagePolicy from the supplied Map
+func NewImagePolicyConfigFromMap(data map[string]string) (*ImagePolicy, error) {
+	nc := &ImagePolicy{
+		EnforcementMode: DisabledEnforcement,
+		Timeout:         DefaultImagePolicyTimeout,
+	}
+
+	for k, v := range data {
+		switch {
+		case k == enforcementModeKey:
+			mode, err := parseEnforcementMode(k, v)
+			if err != nil {
+				return nil, err
+			}
+			nc.EnforcementMode = mode
+		case strings.HasPrefix(k, enforcementModeKey+"."):
+			mode, err := parseEnforcementMode(k, v)
+			if err != nil {
+				return nil, err
+			}
+			if nc.NamespaceEnforcementModes == nil {
+				nc.NamespaceEnforcementModes = make(map[string]EnforcementMode)
+			}
+			nc.NamespaceEnforcementModes[strings.TrimPrefix(k, enforcementModeKey+".")] = mode
+		case k == allowedRegistriesKey:
+			registries, err := parseRegistries(k, v)
+			if err != nil {
+				return nil, err
+			}
+			nc.AllowedRegistries = registries
+		case strings.HasPrefix(k, allowedRegistriesKey+"."):
+			registries, err := parseRegistries(k, v)
+			if err != nil {
+				return nil, err
+			}
+			if nc.NamespaceAllowedRegistries == nil {
+				nc.NamespaceAllowedRegistries = make(map[string][]string)
+			}
+			nc.NamespaceAllowedRegistries[strings.TrimPrefix(k, allowedRegistriesKey+".")] = registries
+		}
+	}
+
+	if raw, ok := data["policy-service-url"]; ok && raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse policy-service-url: %v", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("policy-service-url must be an http or https URL, was %q", raw)
+		}
+		nc.PolicyServiceURL = raw
+	}
+
+	if raw, ok := data["timeout"]; ok {
+		val, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse timeout: %v", err)
+		}
+		if val <= 0 {
+			return nil, fmt.Errorf("timeout must be positive, was %v", val)
+		}
+		nc.Timeout = val
+	}
+
+	return nc, nil
+}
+
+func parseEnforcementMode(key, raw string) (EnforcementMode, error) {
+	switch mode := EnforcementMode(strings.ToLower(raw)); mode {
+	case DisabledEnforcement, WarnEnforcement, Enforce:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%s must be %q, %q or %q, was %q",
+			key, DisabledEnforcement, WarnEnforcement, Enforce, raw)
+	}
+}
+
+// parseRegistries parses a comma separated list of registries, each
+// optionally followed by a repository path prefix, e.g. "gcr.io/project".
+// Docker Hub is named index.docker.io in the returned list.
+func parseRegistries(key, raw string) ([]string, error) {
+	var registries []string
+	for _, r := range strings.Split(raw, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if r == AnyRegistry {
+			registries = append(registries, r)
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSuffix(r, "/"), "/", 2)
+		reg, err := name.NewRegistry(parts[0], name.StrictValidation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry %q in %s: %v", r, key, err)
+		}
+		if len(parts) == 1 {
+			registries = append(registries, reg.RegistryStr())
+			continue
+		}
+		if strings.ContainsAny(parts[1], ":@") {
+			return nil, fmt.Errorf("invalid repository %q in %s: tags and digests are not allowed", r, key)
+		}
+		registries = append(registries, reg.RegistryStr()+"/"+parts[1])
+	}
+	return registries, nil
+}
+
+// NewImagePolicyConfigFromConfigMap creates an ImagePolicy from the supplied configMap
+func NewImagePolicyConfigFromConfigMap(config *corev1.ConfigMap) (*ImagePolicy, error) {
+	return NewImagePolicyConfigFromMap(config.Data)
+}
+
+// ImagePolicy configures the checks of the images of Revisions against an
+// external policy before they are admitted.
+type ImagePolicy struct {
+	// EnforcementMode is how the policy is enforced in the namespaces
+	// without their own mode.
+	EnforcementMode EnforcementMode
+	// NamespaceEnforcementModes overrides EnforcementMode per namespace.
+	NamespaceEnforcementModes map[string]EnforcementMode
+	// PolicyServiceURL is where the ImageReviews are posted.
+	PolicyServiceURL string
+	// Timeout is how long the policy service has to review the images.
+	Timeout time.Duration
+	// AllowedRegistries are the registries, optionally followed by a
+	// repository path prefix, the images of Revisions must come from in
+	// the namespaces without their own list. Empty allows any registry.
+	AllowedRegistries []string
+	// NamespaceAllowedRegistries overrides AllowedRegistries per namespace.
+	NamespaceAllowedRegistries map[string][]string
+}
+
+// EnforcementModeFor returns how the policy is enforced in namespace.
+func (ip *ImagePolicy) EnforcementModeFor(namespace string) EnforcementMode {
+	if mode, ok := ip.NamespaceEnforcementModes[namespace]; ok {
+		return mode
+	}
+	return ip.EnforcementMode
+}
+
+// AllowedRegistriesFor returns the registries the images of the Revisions
+// of namespace must come from, or nil if any registry is allowed.
+func (ip *ImagePolicy) AllowedRegistriesFor(namespace string) []string {
+	if ip == nil {
+		return nil
+	}
+	if registries, ok := ip.NamespaceAllowedRegistries[namespace]; ok {
+		return registries
+	}
+	return ip.AllowedRegistries
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+
+	. "knative.dev/pkg/configmap/testing"
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestImagePolicyConfigurationFromFile(t *testing.T) {
+	cm, example := ConfigMapsFromTestFile(t, ImagePolicyConfigName)
+
+	if _, err := NewImagePolicyConfigFromConfigMap(cm); err != nil {
+		t.Errorf("NewImagePolicyConfigFromConfigMap(actual) = %v", err)
+	}
+
+	if _, err := NewImagePolicyConfigFromConfigMap(example); err != nil {
+		t.Errorf("NewImagePolicyConfigFromConfigMap(example) = %v", err)
+	}
+}
+
+func TestImagePolicyConfiguration(t *testing.T) {
+	configTests := []struct {
+		name            string
+		wantErr         bool
+		wantImagePolicy *ImagePolicy
+		data            map[string]string
+	}{{
+		name:    "default image policy",
+		wantErr: false,
+		wantImagePolicy: &ImagePolicy{
+			EnforcementMode: DisabledEnforcement,
+			Timeout:         DefaultImagePolicyTimeout,
+		},
+		data: map[string]string{},
+	}, {
+		name:    "per-namespace enforcement",
+		wantErr: false,
+		wantImagePolicy: &ImagePolicy{
+			EnforcementMode: WarnEnforcement,
+			NamespaceEnforcementModes: map[string]EnforcementMode{
+				"production": Enforce,
+				"sandbox":    DisabledEnforcement,
+			},
+			PolicyServiceURL: "https://policy.example.com/review",
+			Timeout:          2 * time.Second,
+		},
+		data: map[string]string{
+			"enforcement-mode":            "warn",
+			"enforcement-mode.production": "Enforce",
+			"enforcement-mode.sandbox":    "disabled",
+			"policy-service-url":          "https://policy.example.com/review",
+			"timeout":                     "2s",
+		},
+	}, {
+		name:    "allowed registries",
+		wantErr: false,
+		wantImagePolicy: &ImagePolicy{
+			EnforcementMode:   DisabledEnforcement,
+			Timeout:           DefaultImagePolicyTimeout,
+			AllowedRegistries: []string{"gcr.io/project", "index.docker.io/library", "registry.example.com:5000"},
+			NamespaceAllowedRegistries: map[string][]string{
+				"sandbox": {AnyRegistry},
+				"team":    {"quay.io/team"},
+			},
+		},
+		data: map[string]string{
+			"allowed-registries":         "gcr.io/project, docker.io/library/, registry.example.com:5000",
+			"allowed-registries.sandbox": "*",
+			"allowed-registries.team":    "quay.io/team",
+		},
+	}, {
+		name:    "bad allowed registry",
+		wantErr: true,
+		data: map[string]string{
+			"allowed-registries": "gcr.io/project, not a registry",
+		},
+	}, {
+		name:    "allowed repository with a tag",
+		wantErr: true,
+		data: map[string]string{
+			"allowed-registries.team": "gcr.io/project/image:latest",
+		},
+	}, {
+		name:    "bad enforcement mode",
+		wantErr: true,
+		data: map[string]string{
+			"enforcement-mode": "strict",
+		},
+	}, {
+		name:    "bad namespace enforcement mode",
+		wantErr: true,
+		data: map[string]string{
+			"enforcement-mode.production": "yes",
+		},
+	}, {
+		name:    "bad policy service url",
+		wantErr: true,
+		data: map[string]string{
+			"policy-service-url": "policy.example.com",
+		},
+	}, {
+		name:    "bad timeout",
+		wantErr: true,
+		data: map[string]string{
+			"timeout": "5",
+		},
+	}, {
+		name:    "negative timeout",
+		wantErr: true,
+		data: map[string]string{
+			"timeout": "-1s",
+		},
+	}}
+
+	for _, tt := range configTests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualImagePolicy, err := NewImagePolicyConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      ImagePolicyConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewImagePolicyConfigFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantImagePolicy, actualImagePolicy); diff != "" {
+				t.Errorf("NewImagePolicyConfigFromConfigMap() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEnforcementModeFor(t *testing.T) {
+	ip := &ImagePolicy{
+		EnforcementMode: WarnEnforcement,
+		NamespaceEnforcementModes: map[string]EnforcementMode{
+			"production": Enforce,
+		},
+	}
+	if got, want := ip.EnforcementModeFor("production"), Enforce; got != want {
+		t.Errorf("EnforcementModeFor(production) = %q, want: %q", got, want)
+	}
+	if got, want := ip.EnforcementModeFor("staging"), WarnEnforcement; got != want {
+		t.Errorf("EnforcementModeFor(staging) = %q, want: %q", got, want)
+	}
+}
+
+func TestAllowedRegistriesFor(t *testing.T) {
+	ip := &ImagePolicy{
+		AllowedRegistries: []string{"gcr.io/project"},
+		NamespaceAllowedRegistries: map[string][]string{
+			"sandbox": {AnyRegistry},
+		},
+	}
+	if got, want := ip.AllowedRegistriesFor("sandbox"), []string{AnyRegistry}; !cmp.Equal(got, want) {
+		t.Errorf("AllowedRegistriesFor(sandbox) = %v, want: %v", got, want)
+	}
+	if got, want := ip.AllowedRegistriesFor("production"), []string{"gcr.io/project"}; !cmp.Equal(got, want) {
+		t.Errorf("AllowedRegistriesFor(production) = %v, want: %v", got, want)
+	}
+	if got := (*ImagePolicy)(nil).AllowedRegistriesFor("production"); got != nil {
+		t.Errorf("AllowedRegistriesFor(production) = %v, want: nil", got)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"knative.dev/serving/pkg/apis/autoscaling"
+)
+
+const (
+	// ProfilesConfigName is the name of config map for the profiles.
+	ProfilesConfigName = "config-profiles"
+)
+
+// profileAnnotations maps the settings of the profiles to the autoscaling
+// annotations they default, since ConfigMap keys can't hold the latter.
+var profileAnnotations = map[string]string{
+	"class":                         autoscaling.ClassAnnotationKey,
+	"metric":                        autoscaling.MetricAnnotationKey,
+	"min-scale":                     autoscaling.MinScaleAnnotationKey,
+	"max-scale":                     autoscaling.MaxScaleAnnotationKey,
+	"target":                        autoscaling.TargetAnnotationKey,
+	"target-utilization-percentage": autoscaling.TargetUtilizationPercentageKey,
+	"target-burst-capacity":         autoscaling.TargetBurstCapacityKey,
+	"window":                        autoscaling.WindowAnnotationKey,
+	"panic-window-percentage":       autoscaling.PanicWindowPercentageAnnotationKey,
+	"panic-threshold-percentage":    autoscaling.PanicThresholdPercentageAnnotationKey,
+}
+
+// NewProfilesConfigFromMap creates a Profiles from the supplied Map. Its
+// keys are the name of a profile and one of its settings, separated by a
+// dot, e.g. "latency.min-scale".
+func NewProfilesConfigFromMap(data map[string]string) (*Profiles, error) {
+	settings := map[string]map[string]string{}
+	for k, v := range data {
+		if strings.HasPrefix(k, "_") {
+			continue
+		}
+		parts := strings.SplitN(k, ".", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s must be a profile name and a setting, separated by a dot", k)
+		}
+		name, setting := parts[0], parts[1]
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			return nil, fmt.Errorf("invalid profile name %q: %s", name, strings.Join(msgs, ", "))
+		}
+		if settings[name] == nil {
+			settings[name] = map[string]string{}
+		}
+		settings[name][setting] = v
+	}
+
+	nc := &Profiles{ByName: make(map[string]Profile, len(settings))}
+	for name, data := range settings {
+		profile := Profile{}
+
+		// Process resource quantity fields
+		for _, rsrc := range []struct {
+			key   string
+			field **resource.Quantity
+		}{{
+			key:   "revision-cpu-request",
+			field: &profile.RevisionCPURequest,
+		}, {
+			key:   "revision-memory-request",
+			field: &profile.RevisionMemoryRequest,
+		}, {
+			key:   "revision-cpu-limit",
+			field: &profile.RevisionCPULimit,
+		}, {
+			key:   "revision-memory-limit",
+			field: &profile.RevisionMemoryLimit,
+		}} {
+			raw, ok := data[rsrc.key]
+			if !ok {
+				continue
+			}
+			val, err := resource.ParseQuantity(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s.%s: %v", name, rsrc.key, err)
+			}
+			*rsrc.field = &val
+			delete(data, rsrc.key)
+		}
+
+		// The rest are autoscaling annotations.
+		for k, v := range data {
+			ann, ok := profileAnnotations[k]
+			if !ok {
+				return nil, fmt.Errorf("unknown setting %s.%s", name, k)
+			}
+			if profile.Annotations == nil {
+				profile.Annotations = map[string]string{}
+			}
+			profile.Annotations[ann] = v
+		}
+		if err := autoscaling.ValidateAnnotations(profile.Annotations); err != nil {
+			return nil, fmt.Errorf("invalid annotations of profile %s: %v", name, err)
+		}
+
+		nc.ByName[name] = profile
+	}
+	return nc, nil
+}
+
+// NewProfilesConfigFromConfigMap creates a Profiles from the supplied configMap
+func NewProfilesConfigFromConfigMap(config *corev1.ConfigMap) (*Profiles, error) {
+	return NewProfilesConfigFromMap(config.Data)
+}
+
+// Profiles holds the presets the templates of Services and Configurations
+// pick by name, which the operator curates.
+type Profiles struct {
+	// ByName maps the names of the profiles to them.
+	ByName map[string]Profile
+}
+
+// Get returns the profile of the given name, if it is configured.
+func (p *Profiles) Get(name string) (Profile, bool) {
+	if p == nil {
+		return Profile{}, false
+	}
+	profile, ok := p.ByName[name]
+	return profile, ok
+}
+
+// Names returns the sorted names of the configured profiles.
+func (p *Profiles) Names() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.ByName))
+	for name := range p.ByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile is a set of autoscaling annotations and resources defaulted into
+// the templates picking it.
+type Profile struct {
+	// Annotations are the autoscaling.knative.dev annotations of the profile.
+	Annotations map[string]string
+
+	RevisionCPURequest    *resource.Quantity
+	RevisionCPULimit      *resource.Quantity
+	RevisionMemoryRequest *resource.Quantity
+	RevisionMemoryLimit   *resource.Quantity
+}
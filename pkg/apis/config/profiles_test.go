@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+
+	. "knative.dev/pkg/configmap/testing"
+	_ "knative.dev/pkg/system/testing"
+)
+
+// shippedProfileKeys are the keys of the profiles config-profiles ships.
+var shippedProfileKeys = []string{
+	"latency.min-scale",
+	"latency.target",
+	"latency.target-burst-capacity",
+	"latency.panic-threshold-percentage",
+	"latency.revision-cpu-request",
+	"throughput.target",
+	"throughput.target-utilization-percentage",
+	"throughput.window",
+	"throughput.revision-cpu-request",
+	"throughput.revision-memory-request",
+	"cost.target",
+	"cost.window",
+	"cost.revision-cpu-request",
+	"cost.revision-memory-request",
+}
+
+func TestProfilesConfigurationFromFile(t *testing.T) {
+	cm, example := ConfigMapsFromTestFile(t, ProfilesConfigName, shippedProfileKeys...)
+
+	profiles, err := NewProfilesConfigFromConfigMap(cm)
+	if err != nil {
+		t.Errorf("NewProfilesConfigFromConfigMap(actual) = %v", err)
+	}
+	if got, want := profiles.Names(), []string{"cost", "latency", "throughput"}; !cmp.Equal(got, want) {
+		t.Errorf("Names() = %v, want: %v", got, want)
+	}
+
+	if _, err := NewProfilesConfigFromConfigMap(example); err != nil {
+		t.Errorf("NewProfilesConfigFromConfigMap(example) = %v", err)
+	}
+}
+
+func TestProfilesConfiguration(t *testing.T) {
+	cpu := resource.MustParse("500m")
+	memory := resource.MustParse("1Gi")
+
+	configTests := []struct {
+		name         string
+		wantErr      bool
+		wantProfiles *Profiles
+		data         map[string]string
+	}{{
+		name:         "no profiles",
+		wantProfiles: &Profiles{ByName: map[string]Profile{}},
+		data:         map[string]string{},
+	}, {
+		name: "profiles",
+		wantProfiles: &Profiles{ByName: map[string]Profile{
+			"latency": {
+				Annotations: map[string]string{
+					"autoscaling.knative.dev/minScale": "1",
+					"autoscaling.knative.dev/target":   "10",
+				},
+				RevisionCPURequest: &cpu,
+			},
+			"batch": {
+				RevisionMemoryLimit: &memory,
+			},
+		}},
+		data: map[string]string{
+			"latency.min-scale":            "1",
+			"latency.target":               "10",
+			"latency.revision-cpu-request": "500m",
+			"batch.revision-memory-limit":  "1Gi",
+		},
+	}, {
+		name:    "no setting",
+		wantErr: true,
+		data: map[string]string{
+			"latency": "fast",
+		},
+	}, {
+		name:    "bad profile name",
+		wantErr: true,
+		data: map[string]string{
+			"Latency.min-scale": "1",
+		},
+	}, {
+		name:    "unknown setting",
+		wantErr: true,
+		data: map[string]string{
+			"latency.container-concurrency": "1",
+		},
+	}, {
+		name:    "bad quantity",
+		wantErr: true,
+		data: map[string]string{
+			"latency.revision-cpu-request": "lots",
+		},
+	}, {
+		name:    "invalid annotations",
+		wantErr: true,
+		data: map[string]string{
+			"latency.min-scale": "5",
+			"latency.max-scale": "2",
+		},
+	}}
+
+	for _, tt := range configTests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualProfiles, err := NewProfilesConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      ProfilesConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProfilesConfigFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(tt.wantProfiles, actualProfiles, ignoreStuff...); diff != "" {
+				t.Errorf("Unexpected profiles (-want, +got): %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// QuotaConfigName is the name of config map for the quotas.
+	QuotaConfigName = "config-quota"
+)
+
+// NewQuotaConfigFromMap creates a Quota from the supplied Map
+func NewQuotaConfigFromMap(data map[string]string) (*Quota, error) {
+	nc := &Quota{}
+
+	// Process int fields
+	for _, i := range []struct {
+		key   string
+		field *int
+	}{{
+		key:   "max-services-per-namespace",
+		field: &nc.MaxServicesPerNamespace,
+	}, {
+		key:   "max-revisions-per-configuration",
+		field: &nc.MaxRevisionsPerConfiguration,
+	}, {
+		key:   "max-revision-pods-per-namespace",
+		field: &nc.MaxRevisionPodsPerNamespace,
+	}} {
+		raw, ok := data[i.key]
+		if !ok {
+			continue
+		}
+		val, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", i.key, err)
+		}
+		if val < 0 {
+			return nil, fmt.Errorf("%s cannot be negative, was %d", i.key, val)
+		}
+		*i.field = val
+	}
+
+	return nc, nil
+}
+
+// NewQuotaConfigFromConfigMap creates a Quota from the supplied configMap
+func NewQuotaConfigFromConfigMap(config *corev1.ConfigMap) (*Quota, error) {
+	return NewQuotaConfigFromMap(config.Data)
+}
+
+// Quota limits the resources each namespace may create, enforced when
+// they are admitted.  Zero means unlimited.
+type Quota struct {
+	// MaxServicesPerNamespace is how many Services a namespace may hold.
+	MaxServicesPerNamespace int
+	// MaxRevisionsPerConfiguration is how many Revisions a Configuration
+	// may have at once, i.e. until the older ones are garbage collected.
+	MaxRevisionsPerConfiguration int
+	// MaxRevisionPodsPerNamespace is how many pods the Revisions of a
+	// namespace may run when a new Revision is created.
+	MaxRevisionPodsPerNamespace int
+}
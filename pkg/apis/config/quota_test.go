@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/system"
+
+	. "knative.dev/pkg/configmap/testing"
+	_ "knative.dev/pkg/system/testing"
+)
+
+func TestQuotaConfigurationFromFile(t *testing.T) {
+	cm, example := ConfigMapsFromTestFile(t, QuotaConfigName)
+
+	if _, err := NewQuotaConfigFromConfigMap(cm); err != nil {
+		t.Errorf("NewQuotaConfigFromConfigMap(actual) = %v", err)
+	}
+
+	if _, err := NewQuotaConfigFromConfigMap(example); err != nil {
+		t.Errorf("NewQuotaConfigFromConfigMap(example) = %v", err)
+	}
+}
+
+func TestQuotaConfiguration(t *testing.T) {
+	configTests := []struct {
+		name      string
+		wantErr   bool
+		wantQuota *Quota
+		data      map[string]string
+	}{{
+		name:      "default quota",
+		wantErr:   false,
+		wantQuota: &Quota{},
+		data:      map[string]string{},
+	}, {
+		name:    "all quotas",
+		wantErr: false,
+		wantQuota: &Quota{
+			MaxServicesPerNamespace:      10,
+			MaxRevisionsPerConfiguration: 20,
+			MaxRevisionPodsPerNamespace:  100,
+		},
+		data: map[string]string{
+			"max-services-per-namespace":      "10",
+			"max-revisions-per-configuration": "20",
+			"max-revision-pods-per-namespace": "100",
+		},
+	}, {
+		name:    "bad quota",
+		wantErr: true,
+		data: map[string]string{
+			"max-services-per-namespace": "many",
+		},
+	}, {
+		name:    "negative quota",
+		wantErr: true,
+		data: map[string]string{
+			"max-revision-pods-per-namespace": "-1",
+		},
+	}}
+
+	for _, tt := range configTests {
+		t.Run(tt.name, func(t *testing.T) {
+			actualQuota, err := NewQuotaConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      QuotaConfigName,
+				},
+				Data: tt.data,
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewQuotaConfigFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+
+			if diff := cmp.Diff(actualQuota, tt.wantQuota); diff != "" {
+				t.Errorf("Want %v, but got %v", tt.wantQuota, actualQuota)
+			}
+		})
+	}
+}
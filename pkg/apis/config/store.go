@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"knative.dev/pkg/configmap"
+	"knative.dev/serving/pkg/deployment"
 )
 
 type cfgKey struct{}
@@ -27,7 +28,12 @@ type cfgKey struct{}
 // Config holds the collection of configurations that we attach to contexts.
 // +k8s:deepcopy-gen=false
 type Config struct {
-	Defaults *Defaults
+	Defaults    *Defaults
+	Features    *Features
+	ImagePolicy *ImagePolicy
+	Quota       *Quota
+	Profiles    *Profiles
+	Deployment  *deployment.Config
 }
 
 // FromContext extracts a Config from the provided context.
@@ -46,8 +52,20 @@ func FromContextOrDefaults(ctx context.Context) *Config {
 		return cfg
 	}
 	defaults, _ := NewDefaultsConfigFromMap(map[string]string{})
+	features, _ := NewFeaturesConfigFromMap(map[string]string{})
+	imagePolicy, _ := NewImagePolicyConfigFromMap(map[string]string{})
+	quota, _ := NewQuotaConfigFromMap(map[string]string{})
+	profiles, _ := NewProfilesConfigFromMap(map[string]string{})
+	// config-deployment has no defaults for its required keys. With none of
+	// them set, nodes of any architecture are allowed.
+	deploymentConfig := &deployment.Config{}
 	return &Config{
-		Defaults: defaults,
+		Defaults:    defaults,
+		Features:    features,
+		ImagePolicy: imagePolicy,
+		Quota:       quota,
+		Profiles:    profiles,
+		Deployment:  deploymentConfig,
 	}
 }
 
@@ -70,7 +88,12 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 			"defaults",
 			logger,
 			configmap.Constructors{
-				DefaultsConfigName: NewDefaultsConfigFromConfigMap,
+				DefaultsConfigName:    NewDefaultsConfigFromConfigMap,
+				FeaturesConfigName:    NewFeaturesConfigFromConfigMap,
+				ImagePolicyConfigName: NewImagePolicyConfigFromConfigMap,
+				QuotaConfigName:       NewQuotaConfigFromConfigMap,
+				ProfilesConfigName:    NewProfilesConfigFromConfigMap,
+				deployment.ConfigName: deployment.NewConfigFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -87,6 +110,11 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 // Load creates a Config from the current config state of the Store.
 func (s *Store) Load() *Config {
 	return &Config{
-		Defaults: s.UntypedLoad(DefaultsConfigName).(*Defaults).DeepCopy(),
+		Defaults:    s.UntypedLoad(DefaultsConfigName).(*Defaults).DeepCopy(),
+		Features:    s.UntypedLoad(FeaturesConfigName).(*Features).DeepCopy(),
+		ImagePolicy: s.UntypedLoad(ImagePolicyConfigName).(*ImagePolicy).DeepCopy(),
+		Quota:       s.UntypedLoad(QuotaConfigName).(*Quota).DeepCopy(),
+		Profiles:    s.UntypedLoad(ProfilesConfigName).(*Profiles).DeepCopy(),
+		Deployment:  s.UntypedLoad(deployment.ConfigName).(*deployment.Config).DeepCopy(),
 	}
 }
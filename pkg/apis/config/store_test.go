@@ -24,6 +24,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"k8s.io/apimachinery/pkg/api/resource"
 	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/deployment"
 
 	. "knative.dev/pkg/configmap/testing"
 )
@@ -37,8 +38,18 @@ func TestStoreLoadWithContext(t *testing.T) {
 	store := NewStore(logtesting.TestLogger(t))
 
 	defaultsConfig := ConfigMapFromTestFile(t, DefaultsConfigName)
+	featuresConfig := ConfigMapFromTestFile(t, FeaturesConfigName)
+	imagePolicyConfig := ConfigMapFromTestFile(t, ImagePolicyConfigName)
+	quotaConfig := ConfigMapFromTestFile(t, QuotaConfigName)
+	profilesConfig := ConfigMapFromTestFile(t, ProfilesConfigName, shippedProfileKeys...)
+	deploymentConfig := ConfigMapFromTestFile(t, deployment.ConfigName, deployment.QueueSidecarImageKey)
 
 	store.OnConfigChanged(defaultsConfig)
+	store.OnConfigChanged(featuresConfig)
+	store.OnConfigChanged(imagePolicyConfig)
+	store.OnConfigChanged(quotaConfig)
+	store.OnConfigChanged(profilesConfig)
+	store.OnConfigChanged(deploymentConfig)
 
 	config := FromContextOrDefaults(store.ToContext(context.Background()))
 
@@ -48,12 +59,48 @@ func TestStoreLoadWithContext(t *testing.T) {
 			t.Errorf("Unexpected defaults config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("features", func(t *testing.T) {
+		expected, _ := NewFeaturesConfigFromConfigMap(featuresConfig)
+		if diff := cmp.Diff(expected, config.Features); diff != "" {
+			t.Errorf("Unexpected features config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("image policy", func(t *testing.T) {
+		expected, _ := NewImagePolicyConfigFromConfigMap(imagePolicyConfig)
+		if diff := cmp.Diff(expected, config.ImagePolicy); diff != "" {
+			t.Errorf("Unexpected image policy config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		expected, _ := NewQuotaConfigFromConfigMap(quotaConfig)
+		if diff := cmp.Diff(expected, config.Quota); diff != "" {
+			t.Errorf("Unexpected quota config (-want, +got): %v", diff)
+		}
+	})
+	t.Run("profiles", func(t *testing.T) {
+		expected, _ := NewProfilesConfigFromConfigMap(profilesConfig)
+		if diff := cmp.Diff(expected, config.Profiles, ignoreStuff...); diff != "" {
+			t.Errorf("Unexpected profiles config (-want, +got): %v", diff)
+		}
+	})
+	t.Run("deployment", func(t *testing.T) {
+		expected, _ := deployment.NewConfigFromConfigMap(deploymentConfig)
+		if diff := cmp.Diff(expected, config.Deployment); diff != "" {
+			t.Errorf("Unexpected deployment config (-want, +got): %v", diff)
+		}
+	})
 }
 
 func TestStoreLoadWithContextOrDefaults(t *testing.T) {
 	defer logtesting.ClearAll()
 
 	defaultsConfig := ConfigMapFromTestFile(t, DefaultsConfigName)
+	featuresConfig := ConfigMapFromTestFile(t, FeaturesConfigName)
+	imagePolicyConfig := ConfigMapFromTestFile(t, ImagePolicyConfigName)
+	quotaConfig := ConfigMapFromTestFile(t, QuotaConfigName)
 	config := FromContextOrDefaults(context.Background())
 
 	t.Run("defaults", func(t *testing.T) {
@@ -62,6 +109,39 @@ func TestStoreLoadWithContextOrDefaults(t *testing.T) {
 			t.Errorf("Unexpected defaults config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("features", func(t *testing.T) {
+		expected, _ := NewFeaturesConfigFromConfigMap(featuresConfig)
+		if diff := cmp.Diff(expected, config.Features); diff != "" {
+			t.Errorf("Unexpected features config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("image policy", func(t *testing.T) {
+		expected, _ := NewImagePolicyConfigFromConfigMap(imagePolicyConfig)
+		if diff := cmp.Diff(expected, config.ImagePolicy); diff != "" {
+			t.Errorf("Unexpected image policy config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		expected, _ := NewQuotaConfigFromConfigMap(quotaConfig)
+		if diff := cmp.Diff(expected, config.Quota); diff != "" {
+			t.Errorf("Unexpected quota config (-want, +got): %v", diff)
+		}
+	})
+	t.Run("profiles", func(t *testing.T) {
+		// The profiles are only those the operator configures.
+		expected := &Profiles{ByName: map[string]Profile{}}
+		if diff := cmp.Diff(expected, config.Profiles); diff != "" {
+			t.Errorf("Unexpected profiles config (-want, +got): %v", diff)
+		}
+	})
+	t.Run("deployment", func(t *testing.T) {
+		if archs := config.Deployment.Architectures; archs.Len() != 0 {
+			t.Errorf("Architectures = %v, want any", archs.List())
+		}
+	})
 }
 
 func TestStoreImmutableConfig(t *testing.T) {
@@ -69,14 +149,39 @@ func TestStoreImmutableConfig(t *testing.T) {
 	store := NewStore(logtesting.TestLogger(t))
 
 	store.OnConfigChanged(ConfigMapFromTestFile(t, DefaultsConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, FeaturesConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, ImagePolicyConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, QuotaConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, ProfilesConfigName, shippedProfileKeys...))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, deployment.ConfigName, deployment.QueueSidecarImageKey))
 
 	config := store.Load()
 
 	config.Defaults.RevisionTimeoutSeconds = 1234
+	config.Features.PodSpecDNSPolicy = Enabled
+	config.ImagePolicy.EnforcementMode = Enforce
+	config.Quota.MaxServicesPerNamespace = 1234
+	config.Profiles.ByName["latency"].Annotations["autoscaling.knative.dev/minScale"] = "1234"
+	config.Deployment.QueueSidecarImage = "mutated"
 
 	newConfig := store.Load()
 
 	if newConfig.Defaults.RevisionTimeoutSeconds == 1234 {
 		t.Error("Defaults config is not immutable")
 	}
+	if newConfig.Features.PodSpecDNSPolicy == Enabled {
+		t.Error("Features config is not immutable")
+	}
+	if newConfig.ImagePolicy.EnforcementMode == Enforce {
+		t.Error("ImagePolicy config is not immutable")
+	}
+	if newConfig.Quota.MaxServicesPerNamespace == 1234 {
+		t.Error("Quota config is not immutable")
+	}
+	if newConfig.Profiles.ByName["latency"].Annotations["autoscaling.knative.dev/minScale"] == "1234" {
+		t.Error("Profiles config is not immutable")
+	}
+	if newConfig.Deployment.QueueSidecarImage == "mutated" {
+		t.Error("Deployment config is not immutable")
+	}
 }
@@ -34,12 +34,26 @@ const (
 	ProtocolHTTP1 ProtocolType = "http1"
 	// ProtocolH2C maps to HTTP/2 with Prior Knowledge.
 	ProtocolH2C ProtocolType = "h2c"
+	// ProtocolTCP maps to raw TCP streams, proxied at L4 without looking
+	// into the requests they carry.
+	ProtocolTCP ProtocolType = "tcp"
 )
 
+// NamedPort is a port of the pods of a revision besides the one serving its
+// requests through the queue-proxy. Its Kubernetes services expose it under
+// its name and number, and traffic to it goes straight to the pods.
+type NamedPort struct {
+	// Name is the name of the port, unique among the ports of the revision.
+	Name string `json:"name"`
+
+	// Port is the number of the port, on the pods and on the services.
+	Port int32 `json:"port"`
+}
+
 // Validate validates that ProtocolType has a correct enum value.
 func (p ProtocolType) Validate(context.Context) *apis.FieldError {
 	switch p {
-	case ProtocolH2C, ProtocolHTTP1:
+	case ProtocolH2C, ProtocolHTTP1, ProtocolTCP:
 		return nil
 	case ProtocolType(""):
 		return apis.ErrMissingField(apis.CurrentField)
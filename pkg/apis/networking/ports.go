@@ -26,12 +26,19 @@ const (
 	// HTTP/2 endpoints.
 	ServiceHTTP2Port = 81
 
+	// ServiceTCPPort is the port that we setup our Serving K8s services for
+	// raw TCP endpoints.
+	ServiceTCPPort = 82
+
 	// BackendHTTPPort is the backend, i.e. `targetPort` that we setup for HTTP services.
 	BackendHTTPPort = 8012
 
 	// BackendHTTP2Port is the backend, i.e. `targetPort` that we setup for HTTP services.
 	BackendHTTP2Port = 8013
 
+	// BackendTCPPort is the backend, i.e. `targetPort` that we setup for TCP services.
+	BackendTCPPort = 8014
+
 	// QueueAdminPort specifies the port number for
 	// health check and lifecycle hooks for queue-proxy.
 	QueueAdminPort = 8022
@@ -49,20 +56,40 @@ const (
 
 	// ServicePortNameH2C is the name of the external port of the service for HTTP/2
 	ServicePortNameH2C = "http2"
+
+	// ServicePortNameTCP is the name of the external port of the service for raw TCP
+	ServicePortNameTCP = "tcp"
+)
+
+// The range of the ports the activator listens on for the TCP revisions. Each
+// TCP revision gets a port of its own, since the activator has nothing but the
+// port a connection arrives on to tell which revision it is meant for.
+const (
+	// ActivatorTCPPortMin is the first port handed out to TCP revisions.
+	ActivatorTCPPortMin = 20000
+
+	// ActivatorTCPPortMax is the last port handed out to TCP revisions.
+	ActivatorTCPPortMax = 29999
 )
 
 // ServicePortName returns the port for the app level protocol.
 func ServicePortName(proto ProtocolType) string {
-	if proto == ProtocolH2C {
+	switch proto {
+	case ProtocolH2C:
 		return ServicePortNameH2C
+	case ProtocolTCP:
+		return ServicePortNameTCP
 	}
 	return ServicePortNameHTTP1
 }
 
 // ServicePort chooses the service (load balancer) port for the public service.
 func ServicePort(proto ProtocolType) int {
-	if proto == ProtocolH2C {
+	switch proto {
+	case ProtocolH2C:
 		return ServiceHTTP2Port
+	case ProtocolTCP:
+		return ServiceTCPPort
 	}
 	return ServiceHTTPPort
 }
@@ -73,6 +73,10 @@ const (
 	// value a different reconciliation logic may be used (for examples,
 	// Cert-Manager-based Certificate will reconcile into a Cert-Manager Certificate).
 	CertificateClassAnnotationKey = GroupName + "/certificate.class"
+
+	// ExternalDNSHostnameAnnotationKey is the annotation external-dns
+	// reads the comma separated domains to create DNS records for from.
+	ExternalDNSHostnameAnnotationKey = "external-dns.alpha.kubernetes.io/hostname"
 )
 
 // ServiceType is the enumeration type for the Kubernetes services
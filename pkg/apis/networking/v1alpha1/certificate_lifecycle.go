@@ -18,7 +18,9 @@ package v1alpha1
 
 import (
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"knative.dev/pkg/apis"
@@ -52,6 +54,34 @@ func (cs *CertificateStatus) MarkResourceNotOwned(kind, name string) {
 		fmt.Sprintf("There is an existing %s %q that we do not own.", kind, name))
 }
 
+// MarkRenewed marks the certificate as not about to expire.
+func (cs *CertificateStatus) MarkRenewed() {
+	certificateCondSet.Manage(cs).SetCondition(apis.Condition{
+		Type:     CertificateConditionRenewed,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityInfo,
+	})
+}
+
+// MarkNotRenewed marks the certificate as about to expire, or expired,
+// without having been renewed.
+func (cs *CertificateStatus) MarkNotRenewed(now time.Time) {
+	notAfter := cs.NotAfter.UTC().Format(time.RFC3339)
+	if now.Before(cs.NotAfter.Time) {
+		certificateCondSet.Manage(cs).MarkFalse(CertificateConditionRenewed, "ExpiringSoon",
+			"The certificate expires at %s and has not been renewed.", notAfter)
+	} else {
+		certificateCondSet.Manage(cs).MarkFalse(CertificateConditionRenewed, "Expired",
+			"The certificate expired at %s and has not been renewed.", notAfter)
+	}
+}
+
+// IsExpiring returns true if the certificate is about to expire, or
+// expired, without having been renewed.
+func (cs *CertificateStatus) IsExpiring() bool {
+	return cs.GetCondition(CertificateConditionRenewed).IsFalse()
+}
+
 // IsReady returns true is the Certificate is ready.
 func (cs *CertificateStatus) IsReady() bool {
 	return certificateCondSet.Manage(cs).IsHappy()
@@ -67,6 +97,10 @@ const (
 	// CertificateConditionReady is set when the requested certificate
 	// is provisioned and valid.
 	CertificateConditionReady = apis.ConditionReady
+
+	// CertificateConditionRenewed is set when the certificate is not about
+	// to expire. It is informational and doesn't affect readiness.
+	CertificateConditionRenewed apis.ConditionType = "Renewed"
 )
 
 var certificateCondSet = apis.NewLivingConditionSet(CertificateConditionReady)
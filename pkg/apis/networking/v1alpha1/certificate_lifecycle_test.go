@@ -17,9 +17,11 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis/duck"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	apitest "knative.dev/pkg/apis/testing"
@@ -80,3 +82,40 @@ func TestMarkNotReady(t *testing.T) {
 	c.MarkNotReady("not ready", "not ready")
 	apitest.CheckConditionFailed(c.duck(), CertificateConditionReady, t)
 }
+
+func TestMarkNotRenewed(t *testing.T) {
+	now := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	c := &CertificateStatus{
+		NotAfter: &metav1.Time{Time: now.Add(24 * time.Hour)},
+	}
+	c.InitializeConditions()
+	c.MarkReady()
+
+	c.MarkNotRenewed(now)
+	if !c.IsExpiring() {
+		t.Error("IsExpiring=false, want: true")
+	}
+	if !c.IsReady() {
+		t.Error("IsReady=false, want: true")
+	}
+	if got, want := c.GetCondition(CertificateConditionRenewed).Reason, "ExpiringSoon"; got != want {
+		t.Errorf("Reason = %q, want: %q", got, want)
+	}
+
+	c.MarkNotRenewed(now.Add(48 * time.Hour))
+	if got, want := c.GetCondition(CertificateConditionRenewed).Reason, "Expired"; got != want {
+		t.Errorf("Reason = %q, want: %q", got, want)
+	}
+
+	c.MarkRenewed()
+	if c.IsExpiring() {
+		t.Error("IsExpiring=true, want: false")
+	}
+
+	// The Renewed condition is informational.
+	c.MarkNotReady("not ready", "not ready")
+	c.MarkRenewed()
+	if c.IsReady() {
+		t.Error("IsReady=true, want: false")
+	}
+}
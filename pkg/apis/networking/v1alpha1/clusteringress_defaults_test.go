@@ -29,6 +29,7 @@ import (
 
 	"knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/deployment"
 )
 
 func TestClusterIngressDefaulting(t *testing.T) {
@@ -337,6 +338,22 @@ func TestClusterIngressDefaulting(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: config.DefaultsConfigName},
 				Data:       map[string]string{"max-revision-timeout-seconds": "2000"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 			return s.ToContext(ctx)
 		},
 	}}
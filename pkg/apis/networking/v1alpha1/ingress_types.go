@@ -230,6 +230,44 @@ type HTTPIngressPath struct {
 	// NOTE: This differs from K8s Ingress which doesn't allow retry settings.
 	// +optional
 	Retries *HTTPRetry `json:"retries,omitempty"`
+
+	// Rewrite rewrites the path and/or the host of the requests before
+	// they are forwarded to the splits.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow rewrites.
+	// +optional
+	Rewrite *HTTPRewrite `json:"rewrite,omitempty"`
+
+	// Redirect answers the requests with a 301 redirect instead of
+	// forwarding them, there must be no splits then.
+	//
+	// NOTE: This differs from K8s Ingress which doesn't allow redirects.
+	// +optional
+	Redirect *HTTPRedirect `json:"redirect,omitempty"`
+}
+
+// HTTPRewrite describes how the requests are rewritten before they are
+// forwarded. At least one of Path and Host must be set.
+type HTTPRewrite struct {
+	// Path replaces the path of the requests, e.g. /v2/status.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Host replaces the Host header (authority) of the requests.
+	// +optional
+	Host string `json:"host,omitempty"`
+}
+
+// HTTPRedirect describes where the requests are redirected to. At least one
+// of Path and Host must be set.
+type HTTPRedirect struct {
+	// Path replaces the path of the URL the requests are redirected to.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Host replaces the host of the URL the requests are redirected to.
+	// +optional
+	Host string `json:"host,omitempty"`
 }
 
 // IngressBackendSplit describes all endpoints for a given service and port.
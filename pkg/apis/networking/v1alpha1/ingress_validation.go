@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -86,6 +87,20 @@ func (h HTTPIngressPath) Validate(ctx context.Context) *apis.FieldError {
 		return apis.ErrMissingField(apis.CurrentField)
 	}
 	var all *apis.FieldError
+	// Redirected requests aren't forwarded anywhere.
+	if h.Redirect != nil {
+		all = all.Also(validateURLParts(h.Redirect.Path, h.Redirect.Host).ViaField("redirect"))
+		if len(h.Splits) != 0 {
+			all = all.Also(apis.ErrDisallowedFields("splits"))
+		}
+		if h.Rewrite != nil {
+			all = all.Also(apis.ErrDisallowedFields("rewrite"))
+		}
+		return all
+	}
+	if h.Rewrite != nil {
+		all = all.Also(validateURLParts(h.Rewrite.Path, h.Rewrite.Host).ViaField("rewrite"))
+	}
 	// Must provide as least one split.
 	if len(h.Splits) == 0 {
 		all = all.Also(apis.ErrMissingField("splits"))
@@ -113,6 +128,18 @@ func (h HTTPIngressPath) Validate(ctx context.Context) *apis.FieldError {
 	return all
 }
 
+// validateURLParts checks the path and the host of rewrites and redirects,
+// at least one of which must be set.
+func validateURLParts(path, host string) *apis.FieldError {
+	if path == "" && host == "" {
+		return apis.ErrMissingOneOf("path", "host")
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		return apis.ErrInvalidValue(path, "path")
+	}
+	return nil
+}
+
 // Validate inspects and validates HTTPIngressPath object.
 func (s IngressBackendSplit) Validate(ctx context.Context) *apis.FieldError {
 	// Must not be empty.
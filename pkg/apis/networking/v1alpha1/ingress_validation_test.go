@@ -270,6 +270,102 @@ func TestIngressSpecValidation(t *testing.T) {
 			}},
 		},
 		want: apis.ErrInvalidValue(-1, "rules[0].http.paths[0].retries.attempts"),
+	}, {
+		name: "valid-rewrite",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Splits: []IngressBackendSplit{{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-000",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+						}},
+						Rewrite: &HTTPRewrite{
+							Path: "/status",
+							Host: "internal.example.com",
+						},
+					}},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "empty-rewrite",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Splits: []IngressBackendSplit{{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-000",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+						}},
+						Rewrite: &HTTPRewrite{},
+					}},
+				},
+			}},
+		},
+		want: apis.ErrMissingOneOf("rules[0].http.paths[0].rewrite.path", "rules[0].http.paths[0].rewrite.host"),
+	}, {
+		name: "valid-redirect",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Path: "^/old([/?].*)?$",
+						Redirect: &HTTPRedirect{
+							Path: "/new",
+						},
+					}},
+				},
+			}},
+		},
+		want: nil,
+	}, {
+		name: "redirect-with-splits",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Splits: []IngressBackendSplit{{
+							IngressBackend: IngressBackend{
+								ServiceName:      "revision-000",
+								ServiceNamespace: "default",
+								ServicePort:      intstr.FromInt(8080),
+							},
+						}},
+						Redirect: &HTTPRedirect{
+							Host: "www.example.com",
+						},
+					}},
+				},
+			}},
+		},
+		want: apis.ErrDisallowedFields("rules[0].http.paths[0].splits"),
+	}, {
+		name: "redirect-relative-path",
+		is: &IngressSpec{
+			Rules: []IngressRule{{
+				Hosts: []string{"example.com"},
+				HTTP: &HTTPIngressRuleValue{
+					Paths: []HTTPIngressPath{{
+						Redirect: &HTTPRedirect{
+							Path: "new",
+						},
+					}},
+				},
+			}},
+		},
+		want: apis.ErrInvalidValue("new", "rules[0].http.paths[0].redirect.path"),
 	}, {
 		name: "empty-tls",
 		is: &IngressSpec{
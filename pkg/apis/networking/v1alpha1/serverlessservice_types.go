@@ -100,6 +100,13 @@ type ServerlessServiceSpec struct {
 	// The application-layer protocol. Matches `RevisionProtocolType` set on the owning pa/revision.
 	// serving imports networking, so just use string.
 	ProtocolType networking.ProtocolType
+
+	// Ports are the ports of the pods besides the one serving the requests,
+	// which the services expose too, under the same names and numbers.
+	// Only the pods back them, so they are unreachable through the public
+	// service in Proxy mode.
+	// +optional
+	Ports []networking.NamedPort `json:"ports,omitempty"`
 }
 
 // ServerlessServiceStatus describes the current state of the ServerlessService.
@@ -115,6 +122,11 @@ type ServerlessServiceStatus struct {
 	// load balances over the user service pods backing this Revision.
 	// +optional
 	PrivateServiceName string `json:"privateServiceName,omitempty"`
+
+	// ActivatorPort holds the port the activator listens on for the
+	// connections to this Revision, when it serves raw TCP streams.
+	// +optional
+	ActivatorPort int32 `json:"activatorPort,omitempty"`
 }
 
 // ConditionType represents a ServerlessService condition value
@@ -0,0 +1,54 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ParseDependencies parses the value of the DependenciesAnnotationKey
+// annotation. The addresses it lists are returned as tcp://host:port URLs.
+func ParseDependencies(v string) ([]*url.URL, error) {
+	var deps []*url.URL
+	for _, dep := range strings.Split(v, ",") {
+		dep = strings.TrimSpace(dep)
+		if !strings.Contains(dep, "://") {
+			dep = "tcp://" + dep
+		}
+		u, err := url.Parse(dep)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case "http", "https":
+			if u.Host == "" {
+				return nil, fmt.Errorf("dependency %q has no host", dep)
+			}
+		case "tcp":
+			if _, port, err := net.SplitHostPort(u.Host); err != nil || port == "" || u.Path != "" {
+				return nil, fmt.Errorf("dependency %q is not a host:port address", strings.TrimPrefix(dep, "tcp://"))
+			}
+		default:
+			return nil, fmt.Errorf("dependency %q is neither an http(s) URL nor a host:port address", dep)
+		}
+		deps = append(deps, u)
+	}
+	return deps, nil
+}
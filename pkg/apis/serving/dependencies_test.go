@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"testing"
+)
+
+func TestParseDependencies(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{{
+		name:  "urls and addresses",
+		value: "http://auth.default.svc.cluster.local/healthz, db:5432,https://api.example.com",
+		want:  []string{"http://auth.default.svc.cluster.local/healthz", "tcp://db:5432", "https://api.example.com"},
+	}, {
+		name:  "ipv6 address",
+		value: "[::1]:6379",
+		want:  []string{"tcp://[::1]:6379"},
+	}, {
+		name:    "address without port",
+		value:   "db",
+		wantErr: true,
+	}, {
+		name:    "empty entry",
+		value:   "db:5432,",
+		wantErr: true,
+	}, {
+		name:    "url without host",
+		value:   "http:///healthz",
+		wantErr: true,
+	}, {
+		name:    "unsupported scheme",
+		value:   "grpc://api:443",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deps, err := ParseDependencies(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParseDependencies() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if len(deps) != len(test.want) {
+				t.Fatalf("ParseDependencies() = %v, want: %v", deps, test.want)
+			}
+			for i, dep := range deps {
+				if got := dep.String(); got != test.want[i] {
+					t.Errorf("Dependency %d = %s, want: %s", i, got, test.want[i])
+				}
+			}
+		})
+	}
+}
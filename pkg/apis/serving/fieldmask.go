@@ -17,7 +17,10 @@ limitations under the License.
 package serving
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/config"
 )
 
 // VolumeMask performs a _shallow_ copy of the Kubernetes Volume object to a new
@@ -70,11 +73,11 @@ func VolumeProjectionMask(in *corev1.VolumeProjection) *corev1.VolumeProjection
 	// Allowed fields
 	out.Secret = in.Secret
 	out.ConfigMap = in.ConfigMap
+	out.ServiceAccountToken = in.ServiceAccountToken
 
 	// Disallowed fields
 	// This list is unnecessary, but added here for clarity
 	out.DownwardAPI = nil
-	out.ServiceAccountToken = nil
 
 	return out
 }
@@ -115,6 +118,24 @@ func SecretProjectionMask(in *corev1.SecretProjection) *corev1.SecretProjection
 	return out
 }
 
+// ServiceAccountTokenProjectionMask performs a _shallow_ copy of the Kubernetes ServiceAccountTokenProjection
+// object to a new Kubernetes ServiceAccountTokenProjection object bringing over only the fields allowed
+// in the Knative API. This does not validate the contents or the bounds of the provided fields.
+func ServiceAccountTokenProjectionMask(in *corev1.ServiceAccountTokenProjection) *corev1.ServiceAccountTokenProjection {
+	if in == nil {
+		return nil
+	}
+
+	out := new(corev1.ServiceAccountTokenProjection)
+
+	// Allowed fields
+	out.Audience = in.Audience
+	out.ExpirationSeconds = in.ExpirationSeconds
+	out.Path = in.Path
+
+	return out
+}
+
 // KeyToPathMask performs a _shallow_ copy of the Kubernetes KeyToPath
 // object to a new Kubernetes KeyToPath object bringing over only the fields allowed
 // in the Knative API. This does not validate the contents or the bounds of the provided fields.
@@ -136,7 +157,9 @@ func KeyToPathMask(in *corev1.KeyToPath) *corev1.KeyToPath {
 // PodSpecMask performs a _shallow_ copy of the Kubernetes PodSpec object to a new
 // Kubernetes PodSpec object bringing over only the fields allowed in the Knative API. This
 // does not validate the contents or the bounds of the provided fields.
-func PodSpecMask(in *corev1.PodSpec) *corev1.PodSpec {
+// Fields behind a feature flag are only brought over when it is enabled in
+// the config attached to the context.
+func PodSpecMask(ctx context.Context, in *corev1.PodSpec) *corev1.PodSpec {
 	if in == nil {
 		return nil
 	}
@@ -147,6 +170,19 @@ func PodSpecMask(in *corev1.PodSpec) *corev1.PodSpec {
 	out.ServiceAccountName = in.ServiceAccountName
 	out.Containers = in.Containers
 	out.Volumes = in.Volumes
+	out.AutomountServiceAccountToken = in.AutomountServiceAccountToken
+
+	// Feature flagged fields
+	features := config.FromContextOrDefaults(ctx).Features
+	if features.PodSpecDNSPolicy == config.Enabled {
+		out.DNSPolicy = in.DNSPolicy
+	}
+	if features.PodSpecDNSConfig == config.Enabled {
+		out.DNSConfig = in.DNSConfig
+	}
+	if features.PodSpecHostAliases == config.Enabled {
+		out.HostAliases = in.HostAliases
+	}
 
 	// Disallowed fields
 	// This list is unnecessary, but added here for clarity
@@ -154,9 +190,7 @@ func PodSpecMask(in *corev1.PodSpec) *corev1.PodSpec {
 	out.RestartPolicy = ""
 	out.TerminationGracePeriodSeconds = nil
 	out.ActiveDeadlineSeconds = nil
-	out.DNSPolicy = ""
 	out.NodeSelector = nil
-	out.AutomountServiceAccountToken = nil
 	out.NodeName = ""
 	out.HostNetwork = false
 	out.HostPID = false
@@ -169,10 +203,8 @@ func PodSpecMask(in *corev1.PodSpec) *corev1.PodSpec {
 	out.Affinity = nil
 	out.SchedulerName = ""
 	out.Tolerations = nil
-	out.HostAliases = nil
 	out.PriorityClassName = ""
 	out.Priority = nil
-	out.DNSConfig = nil
 	out.ReadinessGates = nil
 	out.RuntimeClassName = nil
 	// TODO(mattmoor): Coming in 1.13: out.EnableServiceLinks = nil
@@ -17,12 +17,14 @@ limitations under the License.
 package serving
 
 import (
+	"context"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/config"
 )
 
 func TestVolumeMask(t *testing.T) {
@@ -109,9 +111,10 @@ func TestPodSpecMask(t *testing.T) {
 		InitContainers: []corev1.Container{{
 			Image: "busybox",
 		}},
+		DNSPolicy: corev1.DNSDefault,
 	}
 
-	got := PodSpecMask(in)
+	got := PodSpecMask(context.Background(), in)
 
 	if &want == &got {
 		t.Errorf("Input and output share addresses. Want different addresses")
@@ -123,11 +126,68 @@ func TestPodSpecMask(t *testing.T) {
 		t.Errorf("PodSpecMask (-want, +got): %s", diff)
 	}
 
-	if got = PodSpecMask(nil); got != nil {
+	if got = PodSpecMask(context.Background(), nil); got != nil {
 		t.Errorf("PodSpecMask(nil) = %v, want: nil", got)
 	}
 }
 
+func TestPodSpecMaskFeatures(t *testing.T) {
+	want := &corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Image: "helloworld",
+		}},
+		DNSPolicy: corev1.DNSNone,
+		DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.53"},
+		},
+		HostAliases: []corev1.HostAlias{{
+			IP:        "10.0.0.1",
+			Hostnames: []string{"foo.internal"},
+		}},
+	}
+	in := want.DeepCopy()
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Features: &config.Features{
+			PodSpecDNSPolicy:   config.Enabled,
+			PodSpecDNSConfig:   config.Enabled,
+			PodSpecHostAliases: config.Enabled,
+		},
+	})
+
+	got := PodSpecMask(ctx, in)
+
+	if diff, err := kmp.SafeDiff(want, got); err != nil {
+		t.Errorf("Got error comparing output, err = %v", err)
+	} else if diff != "" {
+		t.Errorf("PodSpecMask (-want, +got): %s", diff)
+	}
+}
+
+func TestServiceAccountTokenProjectionMask(t *testing.T) {
+	want := &corev1.ServiceAccountTokenProjection{
+		Audience:          "vault",
+		ExpirationSeconds: ptr.Int64(3600),
+		Path:              "vault-token",
+	}
+	in := want
+
+	got := ServiceAccountTokenProjectionMask(in)
+
+	if &want == &got {
+		t.Errorf("Input and output share addresses. Want different addresses")
+	}
+
+	if diff, err := kmp.SafeDiff(want, got); err != nil {
+		t.Errorf("Got error comparing output, err = %v", err)
+	} else if diff != "" {
+		t.Errorf("ServiceAccountTokenProjectionMask (-want, +got): %s", diff)
+	}
+
+	if got = ServiceAccountTokenProjectionMask(nil); got != nil {
+		t.Errorf("ServiceAccountTokenProjectionMask(nil) = %v, want: nil", got)
+	}
+}
+
 func TestContainerMask(t *testing.T) {
 	want := &corev1.Container{
 		Name:                     "foo",
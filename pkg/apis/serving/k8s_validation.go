@@ -17,8 +17,10 @@ limitations under the License.
 package serving
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"net"
 	"path/filepath"
 	"strings"
 
@@ -28,12 +30,20 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/networking"
 )
 
 const (
 	minUserID = 0
 	maxUserID = math.MaxInt32
+
+	// defaultUserPort is the port serving the requests when the container
+	// doesn't name one.
+	defaultUserPort = 8080
+
+	minTokenExpirationSeconds = 10 * 60
+	maxTokenExpirationSeconds = 1 << 32
 )
 
 var (
@@ -58,16 +68,42 @@ var (
 	)
 
 	// The port is named "user-port" on the deployment, but a user cannot set an arbitrary name on the port
-	// in Configuration. The name field is reserved for content-negotiation. Currently 'h2c' and 'http1' are
-	// allowed.
+	// in Configuration. The name field is reserved for content-negotiation. Currently 'h2c', 'http1' and
+	// 'tcp' are allowed.
 	// https://knative.dev/serving/blob/master/docs/runtime-contract.md#inbound-network-connectivity
 	validPortNames = sets.NewString(
 		"h2c",
 		"http1",
+		"tcp",
 		"",
 	)
+
+	// The names the other ports of the container cannot take, since the port
+	// serving the requests has them, on the deployment or on the services.
+	reservedPortNames = sets.NewString(
+		"h2c",
+		"http1",
+		"tcp",
+		"user-port",
+		networking.ServicePortNameHTTP1,
+		networking.ServicePortNameH2C,
+		networking.ServicePortNameTCP,
+	)
 )
 
+// ServingPortIndex returns the index of the port of a container serving its
+// requests through the queue-proxy: the first one that is unnamed or named
+// after its protocol, 'h2c', 'http1' or 'tcp', or else the first one. The
+// other ports are passed through, for metrics or debugging.
+func ServingPortIndex(ports []corev1.ContainerPort) int {
+	for i, port := range ports {
+		if validPortNames.Has(port.Name) {
+			return i
+		}
+	}
+	return 0
+}
+
 func ValidateVolumes(vs []corev1.Volume) (sets.String, *apis.FieldError) {
 	volumes := sets.NewString()
 	var errs *apis.FieldError
@@ -127,8 +163,12 @@ func validateProjectedVolumeSource(vp corev1.VolumeProjection) *apis.FieldError
 		specified = append(specified, "configMap")
 		errs = errs.Also(validateConfigMapProjection(vp.ConfigMap).ViaField("configMap"))
 	}
+	if vp.ServiceAccountToken != nil {
+		specified = append(specified, "serviceAccountToken")
+		errs = errs.Also(validateServiceAccountTokenProjection(vp.ServiceAccountToken).ViaField("serviceAccountToken"))
+	}
 	if len(specified) == 0 {
-		errs = errs.Also(apis.ErrMissingOneOf("secret", "configMap"))
+		errs = errs.Also(apis.ErrMissingOneOf("secret", "configMap", "serviceAccountToken"))
 	} else if len(specified) > 1 {
 		errs = errs.Also(apis.ErrMultipleOneOf(specified...))
 	}
@@ -161,6 +201,22 @@ func validateSecretProjection(sp *corev1.SecretProjection) *apis.FieldError {
 	return errs
 }
 
+func validateServiceAccountTokenProjection(sp *corev1.ServiceAccountTokenProjection) *apis.FieldError {
+	errs := apis.CheckDisallowedFields(*sp, *ServiceAccountTokenProjectionMask(sp))
+	if sp.Path == "" {
+		errs = errs.Also(apis.ErrMissingField("path"))
+	} else if filepath.IsAbs(sp.Path) || strings.HasPrefix(filepath.Clean(sp.Path), "..") {
+		errs = errs.Also(apis.ErrInvalidValue(sp.Path, "path"))
+	}
+	// These are the bounds the API server enforces on token expiry.
+	if sp.ExpirationSeconds != nil {
+		if es := *sp.ExpirationSeconds; es < minTokenExpirationSeconds || es > maxTokenExpirationSeconds {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(es, minTokenExpirationSeconds, maxTokenExpirationSeconds, "expirationSeconds"))
+		}
+	}
+	return errs
+}
+
 func validateEnvValueFrom(source *corev1.EnvVarSource) *apis.FieldError {
 	if source == nil {
 		return nil
@@ -218,14 +274,14 @@ func validateEnvFrom(envFromList []corev1.EnvFromSource) *apis.FieldError {
 	return errs
 }
 
-func ValidatePodSpec(ps corev1.PodSpec) *apis.FieldError {
+func ValidatePodSpec(ctx context.Context, ps corev1.PodSpec) *apis.FieldError {
 	// This is inlined, and so it makes for a less meaningful
 	// error message.
 	// if equality.Semantic.DeepEqual(ps, corev1.PodSpec{}) {
 	// 	return apis.ErrMissingField(apis.CurrentField)
 	// }
 
-	errs := apis.CheckDisallowedFields(ps, *PodSpecMask(&ps))
+	errs := apis.CheckDisallowedFields(ps, *PodSpecMask(ctx, &ps))
 
 	volumes, err := ValidateVolumes(ps.Volumes)
 	if err != nil {
@@ -246,6 +302,50 @@ func ValidatePodSpec(ps corev1.PodSpec) *apis.FieldError {
 			errs = errs.Also(apis.ErrInvalidValue("serviceAccountName", ps.ServiceAccountName))
 		}
 	}
+	return errs.Also(validateDNS(ps)).Also(validateTCPServing(ctx, ps))
+}
+
+// validateTCPServing rejects the containers serving raw TCP streams unless
+// the experimental tcp-serving feature is enabled.
+func validateTCPServing(ctx context.Context, ps corev1.PodSpec) *apis.FieldError {
+	if len(ps.Containers) != 1 || len(ps.Containers[0].Ports) == 0 {
+		return nil
+	}
+	ports := ps.Containers[0].Ports
+	i := ServingPortIndex(ports)
+	if ports[i].Name != string(networking.ProtocolTCP) {
+		return nil
+	}
+	if config.FromContextOrDefaults(ctx).Features.TCPServing == config.Enabled {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Port name %v requires the tcp-serving feature", networking.ProtocolTCP),
+		Paths:   []string{fmt.Sprintf("containers[0].ports[%d].name", i)},
+	}
+}
+
+// validateDNS checks the DNS settings of the PodSpec. Pods don't run on the
+// host network, so ClusterFirstWithHostNet makes no sense.
+func validateDNS(ps corev1.PodSpec) *apis.FieldError {
+	var errs *apis.FieldError
+	switch ps.DNSPolicy {
+	case "", corev1.DNSClusterFirst, corev1.DNSDefault:
+	case corev1.DNSNone:
+		if ps.DNSConfig == nil || len(ps.DNSConfig.Nameservers) == 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("dnsPolicy %q requires at least one nameserver", corev1.DNSNone),
+				Paths:   []string{"dnsConfig.nameservers"},
+			})
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(ps.DNSPolicy, "dnsPolicy"))
+	}
+	for i, alias := range ps.HostAliases {
+		if net.ParseIP(alias.IP) == nil {
+			errs = errs.Also(apis.ErrInvalidValue(alias.IP, "ip").ViaFieldIndex("hostAliases", i))
+		}
+	}
 	return errs
 }
 
@@ -379,15 +479,8 @@ func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
 	// user can set container port which names "user-port" to define application's port.
 	// Queue-proxy will use it to send requests to application
 	// if user didn't set any port, it will set default port user-port=8080.
-	if len(ports) > 1 {
-		errs = errs.Also(&apis.FieldError{
-			Message: "More than one container port is set",
-			Paths:   []string{apis.CurrentField},
-			Details: "Only a single port is allowed",
-		})
-	}
-
-	userPort := ports[0]
+	served := ServingPortIndex(ports)
+	userPort := ports[served]
 
 	errs = errs.Also(apis.CheckDisallowedFields(userPort, *ContainerPortMask(&userPort)))
 
@@ -397,11 +490,7 @@ func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
 	}
 
 	// Don't allow userPort to conflict with QueueProxy sidecar
-	if userPort.ContainerPort == networking.BackendHTTPPort ||
-		userPort.ContainerPort == networking.BackendHTTP2Port ||
-		userPort.ContainerPort == networking.QueueAdminPort ||
-		userPort.ContainerPort == networking.AutoscalingQueueMetricsPort ||
-		userPort.ContainerPort == networking.UserQueueMetricsPort {
+	if isQueuePort(userPort.ContainerPort) {
 		errs = errs.Also(apis.ErrInvalidValue(userPort.ContainerPort, "containerPort"))
 	}
 
@@ -412,15 +501,78 @@ func validateContainerPorts(ports []corev1.ContainerPort) *apis.FieldError {
 
 	if !validPortNames.Has(userPort.Name) {
 		errs = errs.Also(&apis.FieldError{
-			Message: fmt.Sprintf("Port name %v is not allowed", ports[0].Name),
+			Message: fmt.Sprintf("Port name %v is not allowed", userPort.Name),
 			Paths:   []string{apis.CurrentField},
-			Details: "Name must be empty, or one of: 'h2c', 'http1'",
+			Details: "Name must be empty, or one of: 'h2c', 'http1', 'tcp'",
 		})
 	}
 
+	// The errors of a serving port picked by name after other ports are
+	// reported under its index.
+	if served != 0 {
+		errs = errs.ViaIndex(served)
+	}
+
+	// The other ports are exposed by the services of the revision under
+	// their names, bypassing the queue-proxy.
+	names := sets.NewString()
+	numbers := sets.NewInt(defaultUserPort)
+	if userPort.ContainerPort != 0 {
+		numbers = sets.NewInt(int(userPort.ContainerPort))
+	}
+	for i, port := range ports {
+		if i != served {
+			errs = errs.Also(validateNamedPort(port, names, numbers).ViaIndex(i))
+		}
+	}
+
 	return errs
 }
 
+// validateNamedPort validates a container port besides the one serving the
+// requests, given the names and numbers of the ports validated before it.
+func validateNamedPort(port corev1.ContainerPort, names sets.String, numbers sets.Int) *apis.FieldError {
+	errs := apis.CheckDisallowedFields(port, *ContainerPortMask(&port))
+
+	switch {
+	case port.Name == "":
+		errs = errs.Also(apis.ErrMissingField("name"))
+	case reservedPortNames.Has(port.Name) || names.Has(port.Name):
+		errs = errs.Also(apis.ErrInvalidValue(port.Name, "name"))
+	default:
+		if msgs := validation.IsValidPortName(port.Name); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(port.Name, "name"))
+		}
+	}
+	names.Insert(port.Name)
+
+	if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+		errs = errs.Also(apis.ErrInvalidValue(port.Protocol, "protocol"))
+	}
+
+	switch {
+	case port.ContainerPort == 0:
+		errs = errs.Also(apis.ErrMissingField("containerPort"))
+	case port.ContainerPort < 0 || port.ContainerPort > 65535:
+		errs = errs.Also(apis.ErrOutOfBoundsValue(port.ContainerPort, 1, 65535, "containerPort"))
+	case isQueuePort(port.ContainerPort) || numbers.Has(int(port.ContainerPort)):
+		errs = errs.Also(apis.ErrInvalidValue(port.ContainerPort, "containerPort"))
+	}
+	numbers.Insert(int(port.ContainerPort))
+
+	return errs
+}
+
+// isQueuePort returns whether the QueueProxy sidecar listens on the port.
+func isQueuePort(port int32) bool {
+	return port == networking.BackendHTTPPort ||
+		port == networking.BackendHTTP2Port ||
+		port == networking.BackendTCPPort ||
+		port == networking.QueueAdminPort ||
+		port == networking.AutoscalingQueueMetricsPort ||
+		port == networking.UserQueueMetricsPort
+}
+
 func validateReadinessProbe(p *corev1.Probe) *apis.FieldError {
 	if p == nil {
 		return nil
@@ -17,6 +17,7 @@ limitations under the License.
 package serving
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"testing"
@@ -28,13 +29,22 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/config"
 )
 
 func TestPodSpecValidation(t *testing.T) {
+	allFeatures := &config.Features{
+		PodSpecDNSPolicy:   config.Enabled,
+		PodSpecDNSConfig:   config.Enabled,
+		PodSpecHostAliases: config.Enabled,
+		TCPServing:         config.Enabled,
+	}
+
 	tests := []struct {
-		name string
-		ps   corev1.PodSpec
-		want *apis.FieldError
+		name     string
+		ps       corev1.PodSpec
+		features *config.Features
+		want     *apis.FieldError
 	}{{
 		name: "valid",
 		ps: corev1.PodSpec{
@@ -170,6 +180,15 @@ func TestPodSpecValidation(t *testing.T) {
 			}},
 		},
 		want: apis.ErrDisallowedFields("initContainers"),
+	}, {
+		name: "no automounted service account token",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			AutomountServiceAccountToken: ptr.Bool(false),
+		},
+		want: nil,
 	}, {
 		name: "bad service account name",
 		ps: corev1.PodSpec{
@@ -179,11 +198,110 @@ func TestPodSpecValidation(t *testing.T) {
 			ServiceAccountName: "foo@bar.baz",
 		},
 		want: apis.ErrInvalidValue("serviceAccountName", "foo@bar.baz"),
+	}, {
+		name: "dns fields disabled",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			DNSPolicy: corev1.DNSDefault,
+			HostAliases: []corev1.HostAlias{{
+				IP:        "10.0.0.1",
+				Hostnames: []string{"foo.internal"},
+			}},
+		},
+		want: apis.ErrDisallowedFields("dnsPolicy", "hostAliases"),
+	}, {
+		name: "dns fields enabled",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			DNSPolicy: corev1.DNSNone,
+			DNSConfig: &corev1.PodDNSConfig{
+				Nameservers: []string{"10.0.0.53"},
+				Searches:    []string{"corp.internal"},
+			},
+			HostAliases: []corev1.HostAlias{{
+				IP:        "10.0.0.1",
+				Hostnames: []string{"foo.internal"},
+			}},
+		},
+		features: allFeatures,
+		want:     nil,
+	}, {
+		name: "dns policy none without nameservers",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			DNSPolicy: corev1.DNSNone,
+		},
+		features: allFeatures,
+		want: &apis.FieldError{
+			Message: `dnsPolicy "None" requires at least one nameserver`,
+			Paths:   []string{"dnsConfig.nameservers"},
+		},
+	}, {
+		name: "dns policy with host network",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			DNSPolicy: corev1.DNSClusterFirstWithHostNet,
+		},
+		features: allFeatures,
+		want:     apis.ErrInvalidValue(corev1.DNSClusterFirstWithHostNet, "dnsPolicy"),
+	}, {
+		name: "bad host alias ip",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+			}},
+			HostAliases: []corev1.HostAlias{{
+				IP:        "foo.internal",
+				Hostnames: []string{"bar.internal"},
+			}},
+		},
+		features: allFeatures,
+		want:     apis.ErrInvalidValue("foo.internal", "hostAliases[0].ip"),
+	}, {
+		name: "tcp serving disabled",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					Name:          "tcp",
+					ContainerPort: 1883,
+				}},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Port name tcp requires the tcp-serving feature",
+			Paths:   []string{"containers[0].ports[0].name"},
+		},
+	}, {
+		name: "tcp serving enabled",
+		ps: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Image: "busybox",
+				Ports: []corev1.ContainerPort{{
+					Name:          "tcp",
+					ContainerPort: 1883,
+				}},
+			}},
+		},
+		features: allFeatures,
+		want:     nil,
 	}}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			got := ValidatePodSpec(test.ps)
+			ctx := context.Background()
+			if test.features != nil {
+				ctx = config.ToContext(ctx, &config.Config{Features: test.features})
+			}
+			got := ValidatePodSpec(ctx, test.ps)
 			if !cmp.Equal(test.want.Error(), got.Error()) {
 				t.Errorf("ValidatePodSpec (-want, +got) = %v",
 					cmp.Diff(test.want.Error(), got.Error()))
@@ -286,11 +404,8 @@ func TestContainerValidation(t *testing.T) {
 				Name: "http1",
 			}},
 		},
-		want: &apis.FieldError{
-			Message: "More than one container port is set",
-			Paths:   []string{"ports"},
-			Details: "Only a single port is allowed",
-		},
+		want: apis.ErrInvalidValue("http1", "ports[1].name").Also(
+			apis.ErrMissingField("ports[1].containerPort")),
 	}, {
 		name: "has container port value too large",
 		c: corev1.Container{
@@ -317,11 +432,101 @@ func TestContainerValidation(t *testing.T) {
 				ContainerPort: 8181,
 			}},
 		},
+		want: apis.ErrMissingField("ports[1].name"),
+	}, {
+		name: "has additional named ports",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name: "h2c",
+			}, {
+				Name:          "metrics",
+				ContainerPort: 9095,
+			}, {
+				Name:          "custom",
+				ContainerPort: 7000,
+				Protocol:      corev1.ProtocolTCP,
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has serving port after additional ports",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "metrics",
+				ContainerPort: 9095,
+			}, {
+				Name:          "http1",
+				ContainerPort: 8080,
+			}, {
+				Name:          "debug",
+				ContainerPort: 7000,
+			}},
+		},
+		want: nil,
+	}, {
+		name: "has invalid serving port after additional ports",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "metrics",
+				ContainerPort: 8080,
+			}, {
+				Name:          "h2c",
+				ContainerPort: 8012,
+			}},
+		},
+		want: apis.ErrInvalidValue(8012, "ports[1].containerPort"),
+	}, {
+		name: "has no serving port among named ports",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{
+				Name:          "metrics",
+				ContainerPort: 9095,
+			}, {
+				Name:          "debug",
+				ContainerPort: 7000,
+			}},
+		},
 		want: &apis.FieldError{
-			Message: "More than one container port is set",
+			Message: "Port name metrics is not allowed",
 			Paths:   []string{"ports"},
-			Details: "Only a single port is allowed",
+			Details: "Name must be empty, or one of: 'h2c', 'http1', 'tcp'",
+		},
+	}, {
+		name: "has additional ports conflicting with each other",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{}, {
+				Name:          "metrics",
+				ContainerPort: 8080,
+			}, {
+				Name:          "metrics",
+				ContainerPort: 9095,
+			}, {
+				Name:          "queue",
+				ContainerPort: 8012,
+			}},
 		},
+		want: apis.ErrInvalidValue(8080, "ports[1].containerPort").Also(
+			apis.ErrInvalidValue("metrics", "ports[2].name")).Also(
+			apis.ErrInvalidValue(8012, "ports[3].containerPort")),
+	}, {
+		name: "has additional port with invalid name and protocol",
+		c: corev1.Container{
+			Image: "foo",
+			Ports: []corev1.ContainerPort{{}, {
+				Name:          "Not_A_Port",
+				ContainerPort: 7000,
+				Protocol:      corev1.ProtocolUDP,
+				HostPort:      7000,
+			}},
+		},
+		want: apis.ErrDisallowedFields("ports[1].hostPort").Also(
+			apis.ErrInvalidValue("Not_A_Port", "ports[1].name")).Also(
+			apis.ErrInvalidValue(corev1.ProtocolUDP, "ports[1].protocol")),
 	}, {
 		name: "has tcp protocol",
 		c: corev1.Container{
@@ -405,7 +610,7 @@ func TestContainerValidation(t *testing.T) {
 		want: &apis.FieldError{
 			Message: fmt.Sprintf("Port name %v is not allowed", "foobar"),
 			Paths:   []string{"ports"},
-			Details: "Name must be empty, or one of: 'h2c', 'http1'",
+			Details: "Name must be empty, or one of: 'h2c', 'http1', 'tcp'",
 		},
 	}, {
 		name: "has unknown volumeMounts",
@@ -962,7 +1167,70 @@ func TestVolumeValidation(t *testing.T) {
 				},
 			},
 		},
-		want: apis.ErrMissingOneOf("projected[0].configMap", "projected[0].secret"),
+		want: apis.ErrMissingOneOf("projected[0].configMap", "projected[0].secret", "projected[0].serviceAccountToken"),
+	}, {
+		name: "service account token projection",
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          "vault",
+							ExpirationSeconds: ptr.Int64(3600),
+							Path:              "vault-token",
+						},
+					}},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "service account token projection without path",
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience: "vault",
+						},
+					}},
+				},
+			},
+		},
+		want: apis.ErrMissingField("projected[0].serviceAccountToken.path"),
+	}, {
+		name: "service account token projection escaping the volume",
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Path: "../token",
+						},
+					}},
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("../token", "projected[0].serviceAccountToken.path"),
+	}, {
+		name: "service account token projection expiring too soon",
+		v: corev1.Volume{
+			Name: "foo",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							ExpirationSeconds: ptr.Int64(60),
+							Path:              "token",
+						},
+					}},
+				},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(60, 600, 1<<32, "projected[0].serviceAccountToken.expirationSeconds"),
 	}, {
 		name: "no name",
 		v: corev1.Volume{
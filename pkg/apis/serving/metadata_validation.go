@@ -17,7 +17,13 @@ limitations under the License.
 package serving
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/autoscaling"
 )
@@ -26,5 +32,175 @@ import (
 // resources is correct.
 func ValidateObjectMetadata(meta metav1.Object) *apis.FieldError {
 	return apis.ValidateObjectMetadata(meta).Also(
-		autoscaling.ValidateAnnotations(meta.GetAnnotations()).ViaField("annotations"))
+		autoscaling.ValidateAnnotations(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateRolloutAnnotations(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateStickySplit(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateMaintenance(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validatePathOverrides(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateDrainTimeout(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateEnvFromUpdates(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validatePort(meta.GetAnnotations()).ViaField("annotations")).Also(
+		validateSLO(meta.GetAnnotations()).ViaField("annotations"))
+}
+
+func validateRolloutAnnotations(anns map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	mode, ok := anns[RolloutModeAnnotationKey]
+	if ok && mode != RolloutModeManual && mode != RolloutModeBlueGreen {
+		errs = errs.Also(apis.ErrInvalidValue(mode, RolloutModeAnnotationKey))
+	}
+	// Blue/green rollouts record the Revision they switched to on the Route.
+	if _, ok := anns[PromotedRevisionAnnotationKey]; ok && mode != RolloutModeManual && mode != RolloutModeBlueGreen {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("%s requires %s=%s or %s", PromotedRevisionAnnotationKey,
+				RolloutModeAnnotationKey, RolloutModeManual, RolloutModeBlueGreen),
+			Paths: []string{PromotedRevisionAnnotationKey},
+		})
+	}
+	return errs
+}
+
+// validateStickySplit checks the StickySplitAnnotationKey annotation and the
+// StickyHeaderAnnotationKey annotation it may require.
+func validateStickySplit(anns map[string]string) *apis.FieldError {
+	mode, ok := anns[StickySplitAnnotationKey]
+	header := anns[StickyHeaderAnnotationKey]
+	switch {
+	case !ok:
+		return nil
+	case mode == StickySplitCookie:
+		return nil
+	case mode != StickySplitHeader:
+		return apis.ErrInvalidValue(mode, StickySplitAnnotationKey)
+	case header == "":
+		return &apis.FieldError{
+			Message: fmt.Sprintf("%s=%s requires %s", StickySplitAnnotationKey,
+				StickySplitHeader, StickyHeaderAnnotationKey),
+			Paths: []string{StickyHeaderAnnotationKey},
+		}
+	case strings.ContainsAny(header, ":, "):
+		return apis.ErrInvalidValue(header, StickyHeaderAnnotationKey)
+	}
+	return nil
+}
+
+// validateMaintenance checks the MaintenanceAnnotationKey annotation and the
+// path prefixes of the MaintenancePathsAnnotationKey annotation.
+func validateMaintenance(anns map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	if v, ok := anns[MaintenanceAnnotationKey]; ok && v != "true" && v != "false" {
+		errs = errs.Also(apis.ErrInvalidValue(v, MaintenanceAnnotationKey))
+	}
+	if v, ok := anns[MaintenancePathsAnnotationKey]; ok {
+		for _, prefix := range strings.Split(v, ",") {
+			if !strings.HasPrefix(prefix, "/") {
+				errs = errs.Also(apis.ErrInvalidValue(v, MaintenancePathsAnnotationKey))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// validatePathOverrides checks the annotations overriding how the requests
+// of path prefixes are routed, and the HostRewriteAnnotationKey annotation.
+func validatePathOverrides(anns map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	if v, ok := anns[PathTimeoutsAnnotationKey]; ok {
+		if _, err := ParsePathTimeouts(v); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("invalid value: %s", v),
+				Paths:   []string{PathTimeoutsAnnotationKey},
+				Details: err.Error(),
+			})
+		}
+	}
+	if v, ok := anns[PathRetriesAnnotationKey]; ok {
+		if _, err := ParsePathRetries(v); err != nil {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("invalid value: %s", v),
+				Paths:   []string{PathRetriesAnnotationKey},
+				Details: err.Error(),
+			})
+		}
+	}
+	for _, key := range []string{PathRewritesAnnotationKey, PathRedirectsAnnotationKey} {
+		if v, ok := anns[key]; ok {
+			if _, err := ParsePathReplacements(v); err != nil {
+				errs = errs.Also(&apis.FieldError{
+					Message: fmt.Sprintf("invalid value: %s", v),
+					Paths:   []string{key},
+					Details: err.Error(),
+				})
+			}
+		}
+	}
+	if v, ok := anns[HostRewriteAnnotationKey]; ok {
+		if msgs := validation.IsDNS1123Subdomain(v); len(msgs) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, HostRewriteAnnotationKey))
+		}
+	}
+	return errs
+}
+
+// validateDrainTimeout checks the DrainTimeoutAnnotationKey annotation, a
+// duration bounded by MaxDrainTimeout.
+func validateDrainTimeout(anns map[string]string) *apis.FieldError {
+	v, ok := anns[DrainTimeoutAnnotationKey]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return apis.ErrInvalidValue(v, DrainTimeoutAnnotationKey)
+	}
+	if d < 0 || d > MaxDrainTimeout {
+		return apis.ErrOutOfBoundsValue(d, 0, MaxDrainTimeout, DrainTimeoutAnnotationKey)
+	}
+	return nil
+}
+
+// validateSLO checks the SLO annotations: the targets are percentages, and
+// the latency target requires a latency.
+func validateSLO(anns map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	for _, key := range []string{SLOAvailabilityAnnotationKey, SLOLatencyTargetAnnotationKey} {
+		v, ok := anns[key]
+		if !ok {
+			continue
+		}
+		if f, err := strconv.ParseFloat(v, 64); err != nil || f <= 0 || f >= 100 {
+			errs = errs.Also(apis.ErrOutOfBoundsValue(v, 0, 100, key))
+		}
+	}
+	if v, ok := anns[SLOLatencyAnnotationKey]; ok {
+		if d, err := time.ParseDuration(v); err != nil || d <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(v, SLOLatencyAnnotationKey))
+		}
+	} else if _, ok := anns[SLOLatencyTargetAnnotationKey]; ok {
+		errs = errs.Also(apis.ErrMissingField(SLOLatencyAnnotationKey))
+	}
+	return errs
+}
+
+// validateEnvFromUpdates checks the EnvFromUpdatesAnnotationKey annotation.
+// validatePort checks that the PortAnnotationKey annotation is a port name.
+func validatePort(anns map[string]string) *apis.FieldError {
+	v, ok := anns[PortAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if msgs := validation.IsValidPortName(v); len(msgs) > 0 {
+		return apis.ErrInvalidValue(v, PortAnnotationKey)
+	}
+	return nil
+}
+
+func validateEnvFromUpdates(anns map[string]string) *apis.FieldError {
+	switch v, ok := anns[EnvFromUpdatesAnnotationKey]; {
+	case !ok, v == EnvFromUpdatesRollout, v == EnvFromUpdatesAnnotate:
+		return nil
+	default:
+		return apis.ErrInvalidValue(v, EnvFromUpdatesAnnotationKey)
+	}
 }
@@ -94,6 +94,332 @@ func TestValidateObjectMetadata(t *testing.T) {
 			Message: "name or generateName is required",
 			Paths:   []string{"name"},
 		},
+	}, {
+		name: "valid manual rollout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RolloutModeAnnotationKey:      RolloutModeManual,
+				PromotedRevisionAnnotationKey: "some-name-00001",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "valid blue/green rollout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RolloutModeAnnotationKey: RolloutModeBlueGreen,
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "promoted revision with blue/green rollout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RolloutModeAnnotationKey:      RolloutModeBlueGreen,
+				PromotedRevisionAnnotationKey: "some-name-00001",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid rollout mode",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				RolloutModeAnnotationKey: "yolo",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: yolo",
+			Paths:   []string{"annotations." + RolloutModeAnnotationKey},
+		})),
+	}, {
+		name: "promoted revision without manual rollout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PromotedRevisionAnnotationKey: "some-name-00001",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "serving.knative.dev/promotedRevision requires serving.knative.dev/rolloutMode=manual or blueGreen",
+			Paths:   []string{"annotations." + PromotedRevisionAnnotationKey},
+		})),
+	}, {
+		name: "valid sticky split by cookie",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				StickySplitAnnotationKey: StickySplitCookie,
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "valid sticky split by header",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				StickySplitAnnotationKey:  StickySplitHeader,
+				StickyHeaderAnnotationKey: "X-User-Id",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid sticky split",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				StickySplitAnnotationKey: "ip",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "invalid value: ip",
+			Paths:   []string{"annotations." + StickySplitAnnotationKey},
+		}),
+	}, {
+		name: "sticky split by header without header",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				StickySplitAnnotationKey: StickySplitHeader,
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "serving.knative.dev/stickySplit=header requires serving.knative.dev/stickyHeader",
+			Paths:   []string{"annotations." + StickyHeaderAnnotationKey},
+		}),
+	}, {
+		name: "invalid sticky header",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				StickySplitAnnotationKey:  StickySplitHeader,
+				StickyHeaderAnnotationKey: "X-User, X-Org",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "invalid value: X-User, X-Org",
+			Paths:   []string{"annotations." + StickyHeaderAnnotationKey},
+		}),
+	}, {
+		name: "valid maintenance",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaintenanceAnnotationKey:      "true",
+				MaintenancePathsAnnotationKey: "/checkout,/api/v1",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid maintenance",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaintenanceAnnotationKey: "yes",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: yes",
+			Paths:   []string{"annotations." + MaintenanceAnnotationKey},
+		})),
+	}, {
+		name: "invalid maintenance paths",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				MaintenanceAnnotationKey:      "true",
+				MaintenancePathsAnnotationKey: "/checkout,api",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: /checkout,api",
+			Paths:   []string{"annotations." + MaintenancePathsAnnotationKey},
+		})),
+	}, {
+		name: "valid path overrides",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PathTimeoutsAnnotationKey: "/upload=300s,/healthz=2s",
+				PathRetriesAnnotationKey:  "/upload=0",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid path timeouts",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PathTimeoutsAnnotationKey: "/upload=-1s",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: /upload=-1s",
+			Paths:   []string{"annotations." + PathTimeoutsAnnotationKey},
+			Details: `timeout of "/upload" must be positive, was -1s`,
+		})),
+	}, {
+		name: "invalid path retries",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PathRetriesAnnotationKey: "upload=1",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: upload=1",
+			Paths:   []string{"annotations." + PathRetriesAnnotationKey},
+			Details: `"upload" is not a path prefix`,
+		})),
+	}, {
+		name: "valid rewrites and redirects",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PathRewritesAnnotationKey:  "/healthz=/status",
+				PathRedirectsAnnotationKey: "/blog=/posts",
+				HostRewriteAnnotationKey:   "internal.example.com",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid path redirects",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PathRedirectsAnnotationKey: "/blog=posts",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: /blog=posts",
+			Paths:   []string{"annotations." + PathRedirectsAnnotationKey},
+			Details: `"posts" is not a path`,
+		})),
+	}, {
+		name: "invalid host rewrite",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				HostRewriteAnnotationKey: "Internal_Host",
+			},
+		},
+		expectErr: collect(collect(&apis.FieldError{
+			Message: "invalid value: Internal_Host",
+			Paths:   []string{"annotations." + HostRewriteAnnotationKey},
+		})),
+	}, {
+		name: "valid drain timeout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				DrainTimeoutAnnotationKey: "30s",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid drain timeout",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				DrainTimeoutAnnotationKey: "soon",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "invalid value: soon",
+			Paths:   []string{"annotations." + DrainTimeoutAnnotationKey},
+		}),
+	}, {
+		name: "drain timeout too long",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				DrainTimeoutAnnotationKey: "1h",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "expected 0 <= 1h0m0s <= 5m0s",
+			Paths:   []string{"annotations." + DrainTimeoutAnnotationKey},
+		}),
+	}, {
+		name: "valid env from updates",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				EnvFromUpdatesAnnotationKey: EnvFromUpdatesAnnotate,
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid env from updates",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				EnvFromUpdatesAnnotationKey: "restart",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "invalid value: restart",
+			Paths:   []string{"annotations." + EnvFromUpdatesAnnotationKey},
+		}),
+	}, {
+		name: "valid slo",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				SLOAvailabilityAnnotationKey:  "99.9",
+				SLOLatencyAnnotationKey:       "300ms",
+				SLOLatencyTargetAnnotationKey: "99",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid slo",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				SLOAvailabilityAnnotationKey: "100",
+				SLOLatencyAnnotationKey:      "fast",
+			},
+		},
+		expectErr: collect(collect(
+			apis.ErrOutOfBoundsValue("100", 0, 100, "annotations."+SLOAvailabilityAnnotationKey),
+			&apis.FieldError{
+				Message: "invalid value: fast",
+				Paths:   []string{"annotations." + SLOLatencyAnnotationKey},
+			})),
+	}, {
+		name: "slo latency target without latency",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				SLOLatencyTargetAnnotationKey: "99",
+			},
+		},
+		expectErr: collect(collect(apis.ErrMissingField("annotations." + SLOLatencyAnnotationKey))),
+	}, {
+		name: "valid port",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PortAnnotationKey: "metrics",
+			},
+		},
+		expectErr: (*apis.FieldError)(nil),
+	}, {
+		name: "invalid port",
+		objectMeta: &metav1.ObjectMeta{
+			Name: "some-name",
+			Annotations: map[string]string{
+				PortAnnotationKey: "Not_A_Port",
+			},
+		},
+		expectErr: collect(&apis.FieldError{
+			Message: "invalid value: Not_A_Port",
+			Paths:   []string{"annotations." + PortAnnotationKey},
+		}),
 	}}
 
 	for _, c := range cases {
@@ -107,3 +433,9 @@ func TestValidateObjectMetadata(t *testing.T) {
 		})
 	}
 }
+
+// collect collects errs with Also, as ValidateObjectMetadata and the
+// validations of the annotations it calls do.
+func collect(errs ...*apis.FieldError) *apis.FieldError {
+	return (*apis.FieldError)(nil).Also(errs...)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsePathTimeouts parses the value of the PathTimeoutsAnnotationKey
+// annotation into the request timeouts of its path prefixes.
+func ParsePathTimeouts(v string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	err := parsePathValues(v, func(prefix, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout of %q must be positive, was %v", prefix, d)
+		}
+		timeouts[prefix] = d
+		return nil
+	})
+	return timeouts, err
+}
+
+// ParsePathRetries parses the value of the PathRetriesAnnotationKey
+// annotation into the retry attempts of its path prefixes.
+func ParsePathRetries(v string) (map[string]int, error) {
+	retries := make(map[string]int)
+	err := parsePathValues(v, func(prefix, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("retries of %q must not be negative, was %d", prefix, n)
+		}
+		retries[prefix] = n
+		return nil
+	})
+	return retries, err
+}
+
+// ParsePathReplacements parses the value of the PathRewritesAnnotationKey
+// and the PathRedirectsAnnotationKey annotations into the paths replacing
+// their path prefixes.
+func ParsePathReplacements(v string) (map[string]string, error) {
+	replacements := make(map[string]string)
+	err := parsePathValues(v, func(prefix, value string) error {
+		if !strings.HasPrefix(value, "/") || strings.ContainsAny(value, "#") {
+			return fmt.Errorf("%q is not a path", value)
+		}
+		replacements[prefix] = value
+		return nil
+	})
+	return replacements, err
+}
+
+// parsePathValues calls add with the path prefix and the value of each of
+// the comma separated prefix=value pairs of v.
+func parsePathValues(v string, add func(prefix, value string) error) error {
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(v, ",") {
+		pair = strings.TrimSpace(pair)
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return fmt.Errorf("%q is not a prefix=value pair", pair)
+		}
+		prefix := pair[:i]
+		if !strings.HasPrefix(prefix, "/") || strings.ContainsAny(prefix, "?#") {
+			return fmt.Errorf("%q is not a path prefix", prefix)
+		}
+		if seen[prefix] {
+			return fmt.Errorf("path prefix %q is listed more than once", prefix)
+		}
+		seen[prefix] = true
+		if err := add(prefix, pair[i+1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
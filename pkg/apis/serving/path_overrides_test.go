@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePathTimeouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]time.Duration
+		wantErr bool
+	}{{
+		name:  "timeouts",
+		value: "/upload=300s, /healthz=2s",
+		want: map[string]time.Duration{
+			"/upload":  300 * time.Second,
+			"/healthz": 2 * time.Second,
+		},
+	}, {
+		name:    "not a pair",
+		value:   "/upload",
+		wantErr: true,
+	}, {
+		name:    "not a path",
+		value:   "upload=300s",
+		wantErr: true,
+	}, {
+		name:    "duplicate prefix",
+		value:   "/upload=300s,/upload=10s",
+		wantErr: true,
+	}, {
+		name:    "invalid duration",
+		value:   "/upload=5 minutes",
+		wantErr: true,
+	}, {
+		name:    "zero duration",
+		value:   "/upload=0s",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePathTimeouts(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParsePathTimeouts() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if !test.wantErr && !cmp.Equal(got, test.want) {
+				t.Errorf("ParsePathTimeouts() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePathRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{{
+		name:  "retries",
+		value: "/upload=0,/api=3",
+		want: map[string]int{
+			"/upload": 0,
+			"/api":    3,
+		},
+	}, {
+		name:    "not a number",
+		value:   "/api=three",
+		wantErr: true,
+	}, {
+		name:    "negative",
+		value:   "/api=-1",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePathRetries(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParsePathRetries() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if !test.wantErr && !cmp.Equal(got, test.want) {
+				t.Errorf("ParsePathRetries() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePathReplacements(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{{
+		name:  "replacements",
+		value: "/healthz=/status,/blog=/posts?source=blog",
+		want: map[string]string{
+			"/healthz": "/status",
+			"/blog":    "/posts?source=blog",
+		},
+	}, {
+		name:    "not a path",
+		value:   "/blog=posts",
+		wantErr: true,
+	}, {
+		name:    "fragment",
+		value:   "/blog=/posts#top",
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParsePathReplacements(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ParsePathReplacements() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if !test.wantErr && !cmp.Equal(got, test.want) {
+				t.Errorf("ParsePathReplacements() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
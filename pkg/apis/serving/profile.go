@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+// SetProfileDefaults defaults the autoscaling annotations of the profile
+// the ProfileAnnotationKey annotation of the metadata picks into it, and
+// the resources of the profile into the containers. What they already set
+// is left alone, and so are the profiles that don't exist, which the
+// validation rejects.
+func SetProfileDefaults(ctx context.Context, meta *metav1.ObjectMeta, containers ...*corev1.Container) {
+	name, ok := meta.Annotations[ProfileAnnotationKey]
+	if !ok {
+		return
+	}
+	profile, ok := config.FromContextOrDefaults(ctx).Profiles.Get(name)
+	if !ok {
+		return
+	}
+
+	for k, v := range profile.Annotations {
+		if _, ok := meta.Annotations[k]; !ok {
+			meta.Annotations[k] = v
+		}
+	}
+	for _, c := range containers {
+		setResourceDefault(&c.Resources.Requests, corev1.ResourceCPU, profile.RevisionCPURequest)
+		setResourceDefault(&c.Resources.Requests, corev1.ResourceMemory, profile.RevisionMemoryRequest)
+		setResourceDefault(&c.Resources.Limits, corev1.ResourceCPU, profile.RevisionCPULimit)
+		setResourceDefault(&c.Resources.Limits, corev1.ResourceMemory, profile.RevisionMemoryLimit)
+	}
+}
+
+func setResourceDefault(list *corev1.ResourceList, name corev1.ResourceName, q *resource.Quantity) {
+	if q == nil {
+		return
+	}
+	if *list == nil {
+		*list = corev1.ResourceList{}
+	}
+	if _, ok := (*list)[name]; !ok {
+		(*list)[name] = *q
+	}
+}
+
+// ValidateProfile rejects the ProfileAnnotationKey annotations picking a
+// profile that isn't configured.
+func ValidateProfile(ctx context.Context, annotations map[string]string) *apis.FieldError {
+	name, ok := annotations[ProfileAnnotationKey]
+	if !ok {
+		return nil
+	}
+	profiles := config.FromContextOrDefaults(ctx).Profiles
+	if _, ok := profiles.Get(name); ok {
+		return nil
+	}
+	err := &apis.FieldError{
+		Message: fmt.Sprintf("Unknown profile %q", name),
+		Paths:   []string{apis.CurrentField},
+		Details: "No profiles are configured in " + config.ProfilesConfigName,
+	}
+	if names := profiles.Names(); len(names) > 0 {
+		err.Details = "Profile must be one of: " + strings.Join(names, ", ")
+	}
+	return err.ViaKey(ProfileAnnotationKey)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serving
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+func withProfiles(t *testing.T, data map[string]string) context.Context {
+	profiles, err := config.NewProfilesConfigFromMap(data)
+	if err != nil {
+		t.Fatalf("NewProfilesConfigFromMap() = %v", err)
+	}
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Profiles = profiles
+	return config.ToContext(context.Background(), cfg)
+}
+
+func TestSetProfileDefaults(t *testing.T) {
+	ctx := withProfiles(t, map[string]string{
+		"latency.min-scale":             "1",
+		"latency.target":                "10",
+		"latency.revision-cpu-request":  "500m",
+		"latency.revision-memory-limit": "1Gi",
+	})
+
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		resources     corev1.ResourceRequirements
+		wantAnns      map[string]string
+		wantResources corev1.ResourceRequirements
+	}{{
+		name:        "no profile",
+		annotations: map[string]string{},
+		wantAnns:    map[string]string{},
+	}, {
+		name:        "unknown profile",
+		annotations: map[string]string{ProfileAnnotationKey: "cost"},
+		wantAnns:    map[string]string{ProfileAnnotationKey: "cost"},
+	}, {
+		name:        "profile",
+		annotations: map[string]string{ProfileAnnotationKey: "latency"},
+		wantAnns: map[string]string{
+			ProfileAnnotationKey:              "latency",
+			autoscaling.MinScaleAnnotationKey: "1",
+			autoscaling.TargetAnnotationKey:   "10",
+		},
+		wantResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}, {
+		name: "profile overridden",
+		annotations: map[string]string{
+			ProfileAnnotationKey:            "latency",
+			autoscaling.TargetAnnotationKey: "50",
+		},
+		resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+		},
+		wantAnns: map[string]string{
+			ProfileAnnotationKey:              "latency",
+			autoscaling.MinScaleAnnotationKey: "1",
+			autoscaling.TargetAnnotationKey:   "50",
+		},
+		wantResources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := &metav1.ObjectMeta{Annotations: tt.annotations}
+			container := &corev1.Container{Resources: tt.resources}
+			SetProfileDefaults(ctx, meta, container)
+			if diff := cmp.Diff(tt.wantAnns, meta.Annotations); diff != "" {
+				t.Errorf("Annotations (-want, +got) = %v", diff)
+			}
+			if !resourcesEqual(tt.wantResources, container.Resources) {
+				t.Errorf("Resources = %v, want: %v", container.Resources, tt.wantResources)
+			}
+		})
+	}
+}
+
+func resourcesEqual(a, b corev1.ResourceRequirements) bool {
+	return cmp.Equal(a, b, cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Cmp(y) == 0
+	}))
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		annotations map[string]string
+		want        *apis.FieldError
+	}{{
+		name: "no profile",
+		ctx:  context.Background(),
+	}, {
+		name:        "configured profile",
+		ctx:         withProfiles(t, map[string]string{"cost.target": "200"}),
+		annotations: map[string]string{ProfileAnnotationKey: "cost"},
+	}, {
+		name:        "unknown profile",
+		ctx:         withProfiles(t, map[string]string{"cost.target": "200", "latency.min-scale": "1"}),
+		annotations: map[string]string{ProfileAnnotationKey: "fast"},
+		want: &apis.FieldError{
+			Message: `Unknown profile "fast"`,
+			Paths:   []string{"[" + ProfileAnnotationKey + "]"},
+			Details: "Profile must be one of: cost, latency",
+		},
+	}, {
+		name:        "no profiles",
+		ctx:         context.Background(),
+		annotations: map[string]string{ProfileAnnotationKey: "fast"},
+		want: &apis.FieldError{
+			Message: `Unknown profile "fast"`,
+			Paths:   []string{"[" + ProfileAnnotationKey + "]"},
+			Details: "No profiles are configured in config-profiles",
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateProfile(tt.ctx, tt.annotations)
+			if got, want := got.Error(), tt.want.Error(); got != want {
+				t.Errorf("ValidateProfile() = %v, want: %v", got, want)
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package serving
 
+import "time"
+
 const (
 	GroupName = "serving.knative.dev"
 
@@ -62,7 +64,346 @@ const (
 	// last updated the resource.
 	UpdaterAnnotation = GroupName + "/lastModifier"
 
+	// RolloutModeAnnotationKey is the annotation key attached to a Service
+	// to select how new Revisions are rolled out.  See RolloutModeManual
+	// and RolloutModeBlueGreen.
+	RolloutModeAnnotationKey = GroupName + "/rolloutMode"
+
+	// RolloutModeManual is the RolloutModeAnnotationKey value that holds
+	// new Revisions at 0% traffic once they become Ready, until they are
+	// promoted via PromotedRevisionAnnotationKey.
+	RolloutModeManual = "manual"
+
+	// RolloutModeBlueGreen is the RolloutModeAnnotationKey value that keeps
+	// all the traffic on the previous Revision while a new one is scaled up
+	// to the scale the previous one actually serves at, and then switches
+	// all of it to the new Revision at once.
+	RolloutModeBlueGreen = "blueGreen"
+
+	// PromotedRevisionAnnotationKey is the annotation key attached to a
+	// Service in manual rollout mode to name the Revision that should
+	// receive the traffic otherwise sent to the latest ready Revision.
+	// The Service reconciler also records it on the Route it creates, in
+	// both the manual and blue/green rollout modes.
+	PromotedRevisionAnnotationKey = GroupName + "/promotedRevision"
+
+	// StickySplitAnnotationKey is the annotation key attached to a Service
+	// or a Route to keep the requests of a given user on the same Revision
+	// while its traffic is split between several, e.g. during an experiment.
+	// Its value is StickySplitCookie or StickySplitHeader.  Such splits are
+	// made by the activator, which stays in the path of their requests.
+	StickySplitAnnotationKey = GroupName + "/stickySplit"
+
+	// StickySplitCookie is the StickySplitAnnotationKey value assigning users
+	// by a cookie, which the activator sets on their first request.
+	StickySplitCookie = "cookie"
+
+	// StickySplitHeader is the StickySplitAnnotationKey value assigning users
+	// by the header named by StickyHeaderAnnotationKey, e.g. one carrying
+	// their ID.
+	StickySplitHeader = "header"
+
+	// StickyHeaderAnnotationKey is the annotation key naming the header
+	// users are assigned by with StickySplitHeader.
+	StickyHeaderAnnotationKey = GroupName + "/stickyHeader"
+
+	// MaintenanceAnnotationKey is the annotation key attached to a Service or
+	// a Route to put it in maintenance when set to "true": its requests are
+	// answered with the 503 maintenance page of the config-maintenance
+	// ConfigMap instead of being sent to its Revisions, which are kept.
+	MaintenanceAnnotationKey = GroupName + "/maintenance"
+
+	// MaintenancePathsAnnotationKey is the annotation key attached to a
+	// Service or a Route in maintenance to restrict it to the requests for
+	// the given comma separated path prefixes, e.g. "/checkout,/api/v1".
+	// All the paths are in maintenance when it isn't set.
+	MaintenancePathsAnnotationKey = GroupName + "/maintenancePaths"
+
+	// PathTimeoutsAnnotationKey is the annotation key attached to a Service
+	// or a Route to override the request timeout of the given comma
+	// separated path prefixes, e.g. "/upload=300s,/healthz=2s".
+	PathTimeoutsAnnotationKey = GroupName + "/pathTimeouts"
+
+	// PathRetriesAnnotationKey is the annotation key attached to a Service
+	// or a Route to override the number of times the requests of the given
+	// comma separated path prefixes are retried, e.g. "/upload=0,/api=3".
+	PathRetriesAnnotationKey = GroupName + "/pathRetries"
+
+	// PathRewritesAnnotationKey is the annotation key attached to a Service
+	// or a Route to replace the path of the requests of the given comma
+	// separated path prefixes before they are forwarded to its Revisions,
+	// e.g. "/healthz=/status".
+	PathRewritesAnnotationKey = GroupName + "/pathRewrites"
+
+	// PathRedirectsAnnotationKey is the annotation key attached to a Service
+	// or a Route to answer the requests of the given comma separated path
+	// prefixes with a redirect to another path, e.g. "/blog=/posts".
+	PathRedirectsAnnotationKey = GroupName + "/pathRedirects"
+
+	// HostRewriteAnnotationKey is the annotation key attached to a Service
+	// or a Route to replace the Host header of its requests before they are
+	// forwarded to its Revisions, e.g. "internal.example.com".
+	HostRewriteAnnotationKey = GroupName + "/hostRewrite"
+
+	// DrainTimeoutAnnotationKey is the annotation key attached to a Service
+	// to choose how long in-flight requests are given to complete when it
+	// is deleted, e.g. "30s".  Its Route is deleted first, then its
+	// Revisions once the timeout has passed.  It is bounded by
+	// MaxDrainTimeout.
+	DrainTimeoutAnnotationKey = GroupName + "/drainTimeout"
+
+	// ProtectedAnnotationKey is the annotation key attached to a Service or
+	// a Route to guard it against accidental deletion.  When set to "true",
+	// the webhook rejects its deletion unless ForceDeleteAnnotationKey is
+	// also set to "true".
+	ProtectedAnnotationKey = GroupName + "/protected"
+
+	// ForceDeleteAnnotationKey is the annotation key attached to a Service
+	// or a Route annotated with ProtectedAnnotationKey to allow its deletion.
+	ForceDeleteAnnotationKey = GroupName + "/forceDelete"
+
+	// SuspendedAnnotationKey is the annotation key the suspension controller
+	// attaches to a Service that went without requests for the idle timeout
+	// of the config-suspension ConfigMap, with the value "true". It is
+	// mirrored onto the Revisions of the Service and their PodAutoscalers,
+	// whose minScale no longer holds pods. The owner of the Service resumes
+	// it by removing the annotation.
+	SuspendedAnnotationKey = GroupName + "/suspended"
+
+	// LastResumedAnnotationKey is the annotation key the suspension
+	// controller attaches to a Service whose owner resumed it, holding the
+	// time it was resumed at. The Service is only suspended again after
+	// going without requests for the idle timeout since.
+	LastResumedAnnotationKey = GroupName + "/lastResumed"
+
+	// WatchImageTagAnnotationKey is the annotation key attached to a
+	// Configuration (or the Service creating it) to have a new Revision
+	// stamped out when the tag of its image starts pointing at a different
+	// digest. It is only acted on when tag watching is enabled in the
+	// config-features ConfigMap.
+	WatchImageTagAnnotationKey = GroupName + "/watchImageTag"
+
+	// ImageDigestAnnotationKey is the annotation key the Configuration
+	// reconciler attaches to the template of a Configuration watching its
+	// image tag, holding the digest the tag was found to point at. Changing
+	// it stamps out a new Revision, which resolves the tag anew.
+	ImageDigestAnnotationKey = GroupName + "/imageDigest"
+
+	// EnvFromUpdatesAnnotationKey is the annotation key attached to a
+	// Configuration (or the Service creating it) to have the ConfigMaps and
+	// Secrets its environment is read from watched for changes, which running
+	// containers don't pick up. See EnvFromUpdatesRollout and
+	// EnvFromUpdatesAnnotate.
+	EnvFromUpdatesAnnotationKey = GroupName + "/envFromUpdates"
+
+	// EnvFromUpdatesRollout is the EnvFromUpdatesAnnotationKey value that
+	// stamps out a new Revision when the environment changes.
+	EnvFromUpdatesRollout = "rollout"
+
+	// EnvFromUpdatesAnnotate is the EnvFromUpdatesAnnotationKey value that
+	// only marks the Configuration as running a stale environment when it
+	// changes.
+	EnvFromUpdatesAnnotate = "annotate"
+
+	// EnvFromHashAnnotationKey is the annotation key the Configuration
+	// reconciler attaches to the Revisions of a Configuration watching its
+	// environment, holding the hash of the ConfigMaps and Secrets it was
+	// created with. In rollout mode it is also attached to the template, where
+	// changing it stamps out a new Revision.
+	EnvFromHashAnnotationKey = GroupName + "/envFromHash"
+
+	// PortAnnotationKey is the annotation key attached to a Route to send
+	// its traffic to the named port of its Revisions instead of the one
+	// serving their requests. Revisions without a port of that name get
+	// their traffic on the usual one. The named ports are only backed by
+	// the pods of the Revisions, not by the activator.
+	PortAnnotationKey = GroupName + "/port"
+
+	// ResponseCacheTTLAnnotationKey is the annotation key attached to a
+	// Revision to opt into caching of idempotent GET responses in the
+	// activator.  Its value is a duration (e.g. "1s") bounded by
+	// MaxResponseCacheTTL.
+	ResponseCacheTTLAnnotationKey = "activator." + GroupName + "/responseCacheTTL"
+
+	// HedgeDelayAnnotationKey is the annotation key attached to a Revision
+	// to opt into hedging of idempotent requests in the activator: when no
+	// response arrived after the given duration (e.g. "200ms"), a duplicate
+	// request is sent and the first response wins.  It is bounded by
+	// MaxHedgeDelay.
+	HedgeDelayAnnotationKey = "activator." + GroupName + "/hedgeDelay"
+
+	// RequestBufferSizeAnnotationKey is the annotation key attached to a
+	// Revision to have the activator buffer request bodies up to the given
+	// size (e.g. "64Ki"), so that a request whose connection to a pod failed
+	// can be replayed to another one.  It is bounded by MaxRequestBufferSize.
+	RequestBufferSizeAnnotationKey = "activator." + GroupName + "/requestBufferSize"
+
+	// QueueLogLevelAnnotationKey is the annotation key the controller
+	// attaches to the pods of a Revision whose queue-proxy log level is
+	// overridden in the logging ConfigMap. The queue-proxy reads it through
+	// the downward API, so the level changes without restarting the pods.
+	// The Deployment of the Revision carries it too, as long as its pods do.
+	QueueLogLevelAnnotationKey = "queue.sidecar." + GroupName + "/logLevel"
+
 	// QueueSideCarResourcePercentageAnnotation is the percentage of user container resources to be used for queue-proxy
 	// It has to be in [0.1,100]
 	QueueSideCarResourcePercentageAnnotation = "queue.sidecar." + GroupName + "/resourcePercentage"
+
+	// QoSClassAnnotationKey is the annotation key attached to a Revision to
+	// choose the QoS class of its pods: "Guaranteed" sets the requests of
+	// the user container and the injected queue-proxy equal to their limits,
+	// "Burstable" drops their CPU limits. Guaranteed pods can't have a mesh
+	// sidecar injected.
+	QoSClassAnnotationKey = GroupName + "/qosClass"
+
+	// NodeOSAnnotationKey is the annotation key attached to a Revision to
+	// choose the operating system of the nodes its pods are scheduled on,
+	// NodeOSLinux (the default) or NodeOSWindows. Windows nodes need to be
+	// enabled in the config-deployment ConfigMap.
+	NodeOSAnnotationKey = GroupName + "/nodeOS"
+
+	// NodeOSLinux schedules the pods of a Revision onto Linux nodes.
+	NodeOSLinux = "linux"
+	// NodeOSWindows schedules the pods of a Revision onto Windows nodes.
+	NodeOSWindows = "windows"
+
+	// NodeArchAnnotationKey is the annotation key attached to a Revision to
+	// choose the CPU architecture of the nodes its pods are scheduled on,
+	// e.g. "amd64" or "arm64". The architecture needs to be allowed in the
+	// config-deployment ConfigMap.
+	NodeArchAnnotationKey = GroupName + "/nodeArch"
+
+	// UserSocketAnnotationKey is the annotation key attached to a Revision
+	// to have its user container serve on a Unix domain socket instead of
+	// its port. When set to "true" the socket path is passed in the K_SOCKET
+	// environment variable and the queue-proxy forwards requests and probes
+	// there.
+	UserSocketAnnotationKey = GroupName + "/userSocket"
+
+	// RateLimitAnnotationKey is the annotation key attached to a Revision to
+	// limit the rate of requests each of its pods serves, in requests per
+	// second. Requests over the limit are rejected with 429 Too Many
+	// Requests by the queue-proxy.
+	RateLimitAnnotationKey = GroupName + "/rateLimit"
+
+	// RateLimitBurstAnnotationKey is the annotation key attached to a
+	// Revision to choose the number of requests its pods serve in a burst
+	// above RateLimitAnnotationKey. It defaults to the rate limit, rounded
+	// up.
+	RateLimitBurstAnnotationKey = GroupName + "/rateLimitBurst"
+
+	// TimeoutOverrideAnnotationKey is the annotation key attached to a
+	// Revision to change the number of seconds its queue-proxies give a
+	// request to start responding, in place of its timeoutSeconds. Unlike
+	// the spec, it can be changed on an existing Revision and is picked up
+	// by its running pods when enableDataPlaneConfig is set in the
+	// config-deployment ConfigMap.
+	TimeoutOverrideAnnotationKey = GroupName + "/timeoutOverrideSeconds"
+
+	// TracingSampleRateAnnotationKey is the annotation key attached to a
+	// Revision to choose the rate, in [0, 1], of its requests the activator
+	// and its queue-proxies trace. It wins over the sample rates of the
+	// config-tracing ConfigMap.
+	TracingSampleRateAnnotationKey = GroupName + "/tracingSampleRate"
+
+	// SLOAvailabilityAnnotationKey is the annotation key attached to a
+	// Service, Configuration or Revision to declare the percentage of its
+	// requests that must not fail with a 5xx, e.g. "99.9". Its queue-proxies
+	// count the requests meeting it and exporting it as an objective.
+	SLOAvailabilityAnnotationKey = GroupName + "/sloAvailability"
+
+	// SLOLatencyAnnotationKey is the annotation key attached to a Service,
+	// Configuration or Revision to declare the duration its requests must
+	// be served within, e.g. "300ms". Its queue-proxies count the requests
+	// meeting it.
+	SLOLatencyAnnotationKey = GroupName + "/sloLatency"
+
+	// SLOLatencyTargetAnnotationKey is the annotation key attached along
+	// with SLOLatencyAnnotationKey to declare the percentage of the requests
+	// that must be served within the latency, e.g. "99".
+	SLOLatencyTargetAnnotationKey = GroupName + "/sloLatencyTarget"
+
+	// ProfileAnnotationKey is the annotation key attached to the template of
+	// a Service or Configuration to pick one of the profiles configured in
+	// the config-profiles ConfigMap, e.g. "latency". The autoscaling
+	// annotations and resources of the profile are defaulted into the
+	// template, unless it sets them itself.
+	ProfileAnnotationKey = GroupName + "/profile"
+
+	// CompressionAnnotationKey is the annotation key attached to a Revision
+	// to have the queue-proxy compress the responses of its pods. Its value
+	// is a comma separated list of content codings, e.g. "gzip,deflate", in
+	// order of preference; the first one the client accepts is used.
+	CompressionAnnotationKey = GroupName + "/compression"
+
+	// OpenAPISchemaAnnotationKey is the annotation key attached to a
+	// Revision to have the queue-proxy validate the requests to its pods
+	// against an OpenAPI (Swagger 2.0) document. Its value is the name of a
+	// ConfigMap in the namespace of the Revision, holding the document under
+	// the OpenAPISchemaConfigMapKey key. Invalid requests are rejected
+	// before they reach the user container.
+	OpenAPISchemaAnnotationKey = GroupName + "/openAPISchema"
+
+	// OpenAPISchemaConfigMapKey is the key of the OpenAPI document in the
+	// ConfigMap named by OpenAPISchemaAnnotationKey, in YAML or JSON.
+	OpenAPISchemaConfigMapKey = "openapi.yaml"
+
+	// DependenciesAnnotationKey is the annotation key attached to a Revision
+	// to declare the downstreams its pods depend on, as a comma separated
+	// list of http(s) URLs and host:port addresses, e.g. of Kubernetes
+	// Services. The queue-proxy only reports a pod ready once each URL has
+	// answered a GET with a 2xx or 3xx status, and each address has accepted
+	// a TCP connection. See ParseDependencies.
+	DependenciesAnnotationKey = GroupName + "/dependencies"
+
+	// DebugSecretAnnotationKey is the annotation key attached to a Revision
+	// to have the queue-proxy serve its pprof profiles and expvar variables
+	// under /debug/ on its admin port. Its value is the name of a Secret in
+	// the namespace of the Revision, holding under DebugTokenSecretKey the
+	// bearer token the requests must present.
+	DebugSecretAnnotationKey = GroupName + "/debugSecret"
+
+	// DebugTokenSecretKey is the key of the token in the Secret named by
+	// DebugSecretAnnotationKey.
+	DebugTokenSecretKey = "token"
+
+	// LastAppliedSpecAnnotationKey is the annotation in which the
+	// reconcilers record the spec they last applied to the resources they
+	// own, e.g. Deployments.  The fields of the spec they don't set are left
+	// alone, and those they no longer set are removed.
+	LastAppliedSpecAnnotationKey = GroupName + "/lastAppliedSpec"
+
+	// DryRunAnnotationKey is the annotation key attached to a Service or a
+	// Configuration to preview the changes of its reconciler.  When set to
+	// "true", the changes it would make to the resources it owns, e.g.
+	// creating a Revision, are logged and reported as events instead of
+	// being made.
+	DryRunAnnotationKey = GroupName + "/dryRun"
+
+	// PrometheusScrapeAnnotationKey, PrometheusPortAnnotationKey and
+	// PrometheusPathAnnotationKey are the conventional annotations telling
+	// Prometheus to scrape the metrics the user container exposes itself.
+	// Like all annotations of a Revision, they are copied onto its pods.
+	PrometheusScrapeAnnotationKey = "prometheus.io/scrape"
+	PrometheusPortAnnotationKey   = "prometheus.io/port"
+	PrometheusPathAnnotationKey   = "prometheus.io/path"
+)
+
+const (
+	// MaxResponseCacheTTL is the largest value accepted for
+	// ResponseCacheTTLAnnotationKey.  The cache is meant to absorb bursts of
+	// identical requests, not to replace a proper HTTP cache.
+	MaxResponseCacheTTL = time.Minute
+
+	// MaxHedgeDelay is the largest value accepted for HedgeDelayAnnotationKey.
+	MaxHedgeDelay = 10 * time.Second
+
+	// MaxRequestBufferSize is the largest value accepted for
+	// RequestBufferSizeAnnotationKey, in bytes.  Buffered bodies are held in
+	// the memory of the activator.
+	MaxRequestBufferSize = 10 << 20
+
+	// MaxDrainTimeout is the largest value accepted for
+	// DrainTimeoutAnnotationKey.
+	MaxDrainTimeout = 5 * time.Minute
 )
@@ -21,6 +21,7 @@ import (
 
 	"knative.dev/pkg/apis"
 
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
 
@@ -40,5 +41,7 @@ func (cs *ConfigurationSpec) SetDefaults(ctx context.Context) {
 		}
 	}
 
-	cs.GetTemplate().Spec.SetDefaults(ctx)
+	template := cs.GetTemplate()
+	serving.SetProfileDefaults(ctx, &template.ObjectMeta, template.Spec.GetContainer())
+	template.Spec.SetDefaults(ctx)
 }
@@ -24,9 +24,12 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/ptr"
 
+	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
 
@@ -176,6 +179,60 @@ func TestConfigurationDefaulting(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "profile",
+		in: &Configuration{
+			Spec: ConfigurationSpec{
+				Template: &RevisionTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							serving.ProfileAnnotationKey:      "latency",
+							autoscaling.MinScaleAnnotationKey: "2",
+						},
+					},
+					Spec: RevisionSpec{
+						DeprecatedContainer: &corev1.Container{},
+					},
+				},
+			},
+		},
+		wc: func(ctx context.Context) context.Context {
+			cfg := config.FromContextOrDefaults(ctx)
+			cfg.Profiles, _ = config.NewProfilesConfigFromMap(map[string]string{
+				"latency.min-scale":            "1",
+				"latency.target":               "10",
+				"latency.revision-cpu-request": "500m",
+			})
+			return config.ToContext(ctx, cfg)
+		},
+		want: &Configuration{
+			Spec: ConfigurationSpec{
+				Template: &RevisionTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							serving.ProfileAnnotationKey:      "latency",
+							autoscaling.MinScaleAnnotationKey: "2",
+							autoscaling.TargetAnnotationKey:   "10",
+						},
+					},
+					Spec: RevisionSpec{
+						RevisionSpec: v1beta1.RevisionSpec{
+							TimeoutSeconds: ptr.Int64(config.DefaultRevisionTimeoutSeconds),
+						},
+						DeprecatedContainer: &corev1.Container{
+							Name: config.DefaultUserContainerName,
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse("500m"),
+								},
+								Limits: corev1.ResourceList{},
+							},
+							ReadinessProbe: defaultProbe,
+						},
+					},
+				},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/pkg/apis"
@@ -112,6 +114,34 @@ func (cs *ConfigurationStatus) MarkLatestReadyDeleted() {
 		"Revision %q was deleted.", cs.LatestReadyRevisionName)
 }
 
+// PropagateResourcesExhausted surfaces the "ResourcesExhausted" condition of
+// the latest created Revision on the Configuration.
+func (cs *ConfigurationStatus) PropagateResourcesExhausted(rs *RevisionStatus) {
+	propagateResourcesExhausted(rs.GetCondition(ConditionTypeResourcesExhausted), confCondSet.Manage(cs))
+}
+
+// MarkEnvFromStale marks the latest created Revision as running with an
+// outdated copy of the given ConfigMaps and Secrets.
+func (cs *ConfigurationStatus) MarkEnvFromStale(refs string) {
+	confCondSet.Manage(cs).SetCondition(apis.Condition{
+		Type:     ConfigurationConditionEnvFromUpToDate,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "EnvFromChanged",
+		Message:  fmt.Sprintf("Revision %q was created before %s changed.", cs.LatestCreatedRevisionName, refs),
+	})
+}
+
+// MarkEnvFromUpToDate marks the latest created Revision as running with the
+// current ConfigMaps and Secrets.
+func (cs *ConfigurationStatus) MarkEnvFromUpToDate() {
+	confCondSet.Manage(cs).SetCondition(apis.Condition{
+		Type:     ConfigurationConditionEnvFromUpToDate,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+	})
+}
+
 func (cs *ConfigurationStatus) duck() *duckv1beta1.Status {
 	return &cs.Status
 }
@@ -325,6 +325,50 @@ func TestLatestRevisionDeletedThenFixed(t *testing.T) {
 	apitesting.CheckConditionSucceeded(r.duck(), ConfigurationConditionReady, t)
 }
 
+func TestConfigurationResourcesExhaustedPropagation(t *testing.T) {
+	r := &ConfigurationStatus{}
+	r.InitializeConditions()
+
+	rs := &RevisionStatus{}
+	rs.InitializeConditions()
+	r.PropagateResourcesExhausted(rs)
+	if got := r.GetCondition(ConditionTypeResourcesExhausted); got != nil {
+		t.Errorf("ResourcesExhausted = %v, want nil", got)
+	}
+
+	const want = "exceeded quota"
+	rs.MarkResourcesExhausted("FailedCreate", want)
+	r.PropagateResourcesExhausted(rs)
+	apitesting.CheckConditionSucceeded(r.duck(), ConditionTypeResourcesExhausted, t)
+	if cnd := r.GetCondition(ConditionTypeResourcesExhausted); cnd == nil || cnd.Message != want {
+		t.Errorf("ResourcesExhausted = %v, want message %v", cnd, want)
+	}
+	// The warning must not affect the Configuration's readiness.
+	apitesting.CheckConditionOngoing(r.duck(), ConfigurationConditionReady, t)
+
+	// A new Revision without the condition resets it.
+	r.PropagateResourcesExhausted(&RevisionStatus{})
+	apitesting.CheckConditionFailed(r.duck(), ConditionTypeResourcesExhausted, t)
+}
+
+func TestConfigurationEnvFromUpToDate(t *testing.T) {
+	r := &ConfigurationStatus{}
+	r.InitializeConditions()
+	r.SetLatestCreatedRevisionName("foo")
+
+	r.MarkEnvFromStale(`ConfigMap "env"`)
+	apitesting.CheckConditionFailed(r.duck(), ConfigurationConditionEnvFromUpToDate, t)
+	if got, want := r.GetCondition(ConfigurationConditionEnvFromUpToDate).Message,
+		`Revision "foo" was created before ConfigMap "env" changed.`; got != want {
+		t.Errorf("Message = %q, want: %q", got, want)
+	}
+	// The warning must not affect the Configuration's readiness.
+	apitesting.CheckConditionOngoing(r.duck(), ConfigurationConditionReady, t)
+
+	r.MarkEnvFromUpToDate()
+	apitesting.CheckConditionSucceeded(r.duck(), ConfigurationConditionEnvFromUpToDate, t)
+}
+
 func TestConfigurationGetGroupVersionKind(t *testing.T) {
 	c := &Configuration{}
 	want := schema.GroupVersionKind{
@@ -96,6 +96,11 @@ const (
 	// ConfigurationConditionReady is set when the configuration's latest
 	// underlying revision has reported readiness.
 	ConfigurationConditionReady = apis.ConditionReady
+
+	// ConfigurationConditionEnvFromUpToDate is a Warning condition set on a
+	// Configuration watching its environment. It is False while its latest
+	// created Revision runs with ConfigMaps or Secrets that have changed since.
+	ConfigurationConditionEnvFromUpToDate apis.ConditionType = "EnvFromUpToDate"
 )
 
 // ConfigurationStatusFields holds all of the non-duckv1beta1.Status status fields of a Route.
@@ -23,6 +23,7 @@ import (
 
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/refcheck"
 )
 
 // Validate makes sure that Configuration is properly configured.
@@ -37,17 +38,40 @@ func (c *Configuration) Validate(ctx context.Context) (errs *apis.FieldError) {
 		errs = errs.Also(c.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	}
 
+	var baseline *RevisionTemplateSpec
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Configuration)
+		baseline = original.Spec.GetTemplate()
 
 		err := c.Spec.GetTemplate().VerifyNameChange(ctx,
 			original.Spec.GetTemplate())
 		errs = errs.Also(err.ViaField("spec.revisionTemplate"))
 	}
 
+	// The references of the Configurations of Services are checked with them.
+	if errs == nil && c.Labels[serving.ServiceLabelKey] == "" {
+		errs = validateReferences(ctx, c.Namespace, &c.Spec, baseline).ViaField("spec")
+	}
+
 	return errs
 }
 
+// validateReferences checks that the resources the template of cs, that of a
+// resource in namespace, refers to exist. They are only checked when the
+// resource is created, or when the template changed from baseline.
+func validateReferences(ctx context.Context, namespace string, cs *ConfigurationSpec, baseline *RevisionTemplateSpec) *apis.FieldError {
+	template := cs.GetTemplate()
+	if apis.IsInStatusUpdate(ctx) || template == nil ||
+		(baseline != nil && equality.Semantic.DeepEqual(baseline.Spec, template.Spec)) {
+		return nil
+	}
+	templateField := "template"
+	if template == cs.DeprecatedRevisionTemplate {
+		templateField = "revisionTemplate"
+	}
+	return refcheck.Validate(ctx, namespace, &template.Spec.PodSpec).ViaField(templateField, "spec")
+}
+
 // Validate makes sure that ConfigurationSpec is properly configured.
 func (cs *ConfigurationSpec) Validate(ctx context.Context) *apis.FieldError {
 	if equality.Semantic.DeepEqual(cs, &ConfigurationSpec{}) {
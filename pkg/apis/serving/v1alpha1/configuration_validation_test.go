@@ -30,7 +30,9 @@ import (
 
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/refcheck"
 )
 
 func TestConfigurationSpecValidation(t *testing.T) {
@@ -592,3 +594,48 @@ func TestConfigurationSubresourceUpdate(t *testing.T) {
 		})
 	}
 }
+
+// noneExist is a refcheck.Checker for which no resource exists.
+type noneExist struct{}
+
+func (noneExist) Exists(string, string, string) (bool, error) { return false, nil }
+
+func TestConfigurationReferences(t *testing.T) {
+	c := &Configuration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "valid",
+			Namespace: "default",
+		},
+		Spec: ConfigurationSpec{
+			DeprecatedRevisionTemplate: &RevisionTemplateSpec{
+				Spec: RevisionSpec{
+					RevisionSpec: v1beta1.RevisionSpec{
+						PodSpec: corev1.PodSpec{
+							ServiceAccountName: "builder",
+							Containers: []corev1.Container{{
+								Image: "busybox",
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Features.ReferenceValidation = config.Enabled
+	ctx := refcheck.WithChecker(config.ToContext(context.Background(), cfg), noneExist{})
+
+	want := &apis.FieldError{
+		Message: `ServiceAccount "builder" does not exist in namespace "default"`,
+		Paths:   []string{"spec.revisionTemplate.spec.serviceAccountName"},
+	}
+	if got := c.Validate(ctx); got.Error() != want.Error() {
+		t.Errorf("Validate() = %v, want: %v", got, want)
+	}
+
+	// The Configurations of Services are checked with them.
+	c.Labels = map[string]string{serving.ServiceLabelKey: "svc"}
+	if got := c.Validate(ctx); got != nil {
+		t.Errorf("Validate() = %v, wanted no error", got)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// ConditionTypeResourcesExhausted is a Warning condition that is set to
+	// True on a Revision when the creation of its pods is rejected, e.g.
+	// because it would exceed a ResourceQuota or violate a LimitRange in its
+	// namespace.  It is propagated to the owning Configuration and Service.
+	ConditionTypeResourcesExhausted apis.ConditionType = "ResourcesExhausted"
+)
+
+// propagateResourcesExhausted copies the ResourcesExhausted condition from
+// src onto the conditions managed by dst.  When src does not carry the
+// condition, a previously propagated one is reset to False.
+func propagateResourcesExhausted(src *apis.Condition, dst apis.ConditionManager) {
+	if src != nil {
+		dst.SetCondition(apis.Condition{
+			Type:     ConditionTypeResourcesExhausted,
+			Status:   src.Status,
+			Severity: apis.ConditionSeverityWarning,
+			Reason:   src.Reason,
+			Message:  src.Message,
+		})
+		return
+	}
+	if dst.GetCondition(ConditionTypeResourcesExhausted) != nil {
+		dst.SetCondition(apis.Condition{
+			Type:     ConditionTypeResourcesExhausted,
+			Status:   corev1.ConditionFalse,
+			Severity: apis.ConditionSeverityWarning,
+		})
+	}
+}
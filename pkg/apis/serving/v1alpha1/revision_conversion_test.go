@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -90,6 +91,7 @@ func TestRevisionConversion(t *testing.T) {
 				},
 				ServiceName: "foo-bar",
 				LogURL:      "http://logger.io",
+				IdleSince:   &metav1.Time{Time: time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)},
 			},
 		},
 	}, {
@@ -28,6 +28,7 @@ import (
 
 	"knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/deployment"
 )
 
 var defaultProbe = &corev1.Probe{
@@ -96,6 +97,22 @@ func TestRevisionDefaulting(t *testing.T) {
 					"revision-timeout-seconds": "123",
 				},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 
 			return s.ToContext(ctx)
 		},
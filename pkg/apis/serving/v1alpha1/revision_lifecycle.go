@@ -103,13 +103,47 @@ func (r *Revision) DeprecatedBuildRef() *corev1.ObjectReference {
 // GetProtocol returns the app level network protocol.
 func (r *Revision) GetProtocol() net.ProtocolType {
 	ports := r.Spec.GetContainer().Ports
-	if len(ports) > 0 && ports[0].Name == string(net.ProtocolH2C) {
-		return net.ProtocolH2C
+	if len(ports) > 0 {
+		switch net.ProtocolType(ports[serving.ServingPortIndex(ports)].Name) {
+		case net.ProtocolH2C:
+			return net.ProtocolH2C
+		case net.ProtocolTCP:
+			return net.ProtocolTCP
+		}
 	}
 
 	return net.ProtocolHTTP1
 }
 
+// GetPorts returns the ports of the revision besides the one serving its
+// requests.
+func (r *Revision) GetPorts() []net.NamedPort {
+	ports := r.Spec.GetContainer().Ports
+	if len(ports) < 2 {
+		return nil
+	}
+	served := serving.ServingPortIndex(ports)
+	named := make([]net.NamedPort, 0, len(ports)-1)
+	for i, p := range ports {
+		if i != served {
+			named = append(named, net.NamedPort{Name: p.Name, Port: p.ContainerPort})
+		}
+	}
+	return named
+}
+
+// TracingSampleRate returns the rate of the requests to trace set in the
+// TracingSampleRateAnnotationKey annotation, or false if it isn't set.
+func (r *Revision) TracingSampleRate() (float64, bool) {
+	// The value is validated in the webhook.
+	if s, ok := r.Annotations[serving.TracingSampleRateAnnotationKey]; ok {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
 // IsReady looks at the conditions and if the Status has a condition
 // RevisionConditionReady returns true if ConditionStatus is True
 func (rs *RevisionStatus) IsReady() bool {
@@ -179,6 +213,26 @@ func (rs *RevisionStatus) MarkResourcesUnavailable(reason, message string) {
 	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, reason, message)
 }
 
+// MarkResourcesExhausted sets the "ResourcesExhausted" condition to true and
+// changes "ResourcesAvailable" to false to reflect that the pods of the
+// Revision are being rejected, e.g. by a ResourceQuota or LimitRange.
+func (rs *RevisionStatus) MarkResourcesExhausted(reason, message string) {
+	revCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     ConditionTypeResourcesExhausted,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  message,
+	})
+	revCondSet.Manage(rs).MarkFalse(RevisionConditionResourcesAvailable, "ResourcesExhausted", message)
+}
+
+// MarkResourcesNotExhausted resets the "ResourcesExhausted" condition, if it
+// was previously set.
+func (rs *RevisionStatus) MarkResourcesNotExhausted() {
+	propagateResourcesExhausted(nil, revCondSet.Manage(rs))
+}
+
 func (rs *RevisionStatus) MarkActive() {
 	revCondSet.Manage(rs).MarkTrue(RevisionConditionActive)
 }
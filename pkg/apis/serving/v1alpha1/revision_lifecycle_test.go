@@ -380,6 +380,32 @@ func TestRevisionResourcesUnavailable(t *testing.T) {
 	}
 }
 
+func TestRevisionResourcesExhausted(t *testing.T) {
+	r := &RevisionStatus{}
+	r.InitializeConditions()
+
+	// Resetting a condition that was never set is a no-op.
+	r.MarkResourcesNotExhausted()
+	if got := r.GetCondition(ConditionTypeResourcesExhausted); got != nil {
+		t.Errorf("ResourcesExhausted = %v, want nil", got)
+	}
+
+	const wantReason, wantMessage = "FailedCreate", "exceeded quota: compute-resources"
+	r.MarkResourcesExhausted(wantReason, wantMessage)
+	apitest.CheckConditionSucceeded(r.duck(), ConditionTypeResourcesExhausted, t)
+	apitest.CheckConditionFailed(r.duck(), RevisionConditionResourcesAvailable, t)
+	apitest.CheckConditionFailed(r.duck(), RevisionConditionReady, t)
+	if got := r.GetCondition(ConditionTypeResourcesExhausted); got == nil || got.Reason != wantReason || got.Message != wantMessage {
+		t.Errorf("ResourcesExhausted = %v, want %v: %v", got, wantReason, wantMessage)
+	}
+	if got := r.GetCondition(RevisionConditionReady); got == nil || got.Message != wantMessage {
+		t.Errorf("Ready = %v, want %v", got, wantMessage)
+	}
+
+	r.MarkResourcesNotExhausted()
+	apitest.CheckConditionFailed(r.duck(), ConditionTypeResourcesExhausted, t)
+}
+
 func TestRevisionGetGroupVersionKind(t *testing.T) {
 	r := &Revision{}
 	want := schema.GroupVersionKind{
@@ -474,6 +500,10 @@ func TestRevisionGetProtocol(t *testing.T) {
 		name:      "h2c",
 		container: containerWithPortName("h2c"),
 		protocol:  net.ProtocolH2C,
+	}, {
+		name:      "tcp",
+		container: containerWithPortName("tcp"),
+		protocol:  net.ProtocolTCP,
 	}, {
 		name:      "unknown",
 		container: containerWithPortName("whatever"),
@@ -482,6 +512,14 @@ func TestRevisionGetProtocol(t *testing.T) {
 		name:      "empty",
 		container: containerWithPortName(""),
 		protocol:  net.ProtocolHTTP1,
+	}, {
+		name: "h2c after additional ports",
+		container: corev1.Container{Ports: []corev1.ContainerPort{{
+			Name: "metrics",
+		}, {
+			Name: "h2c",
+		}}},
+		protocol: net.ProtocolH2C,
 	}}
 
 	for _, tt := range tests {
@@ -502,6 +540,82 @@ func TestRevisionGetProtocol(t *testing.T) {
 	}
 }
 
+func TestRevisionGetPorts(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports []corev1.ContainerPort
+		want  []net.NamedPort
+	}{{
+		name: "none",
+	}, {
+		name:  "serving port only",
+		ports: []corev1.ContainerPort{{Name: "h2c", ContainerPort: 8080}},
+	}, {
+		name: "serving port first",
+		ports: []corev1.ContainerPort{{
+			ContainerPort: 8080,
+		}, {
+			Name:          "metrics",
+			ContainerPort: 9095,
+		}},
+		want: []net.NamedPort{{Name: "metrics", Port: 9095}},
+	}, {
+		name: "serving port between additional ports",
+		ports: []corev1.ContainerPort{{
+			Name:          "metrics",
+			ContainerPort: 9095,
+		}, {
+			Name:          "http1",
+			ContainerPort: 8080,
+		}, {
+			Name:          "debug",
+			ContainerPort: 7000,
+		}},
+		want: []net.NamedPort{{Name: "metrics", Port: 9095}, {Name: "debug", Port: 7000}},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Revision{
+				Spec: RevisionSpec{
+					DeprecatedContainer: &corev1.Container{Ports: tt.ports},
+				},
+			}
+			if got := r.GetPorts(); !cmp.Equal(got, tt.want) {
+				t.Errorf("GetPorts (-want, +got) = %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestRevisionTracingSampleRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        float64
+		wantOK      bool
+	}{{
+		name: "not set",
+	}, {
+		name:        "set",
+		annotations: map[string]string{serving.TracingSampleRateAnnotationKey: "0.001"},
+		want:        0.001,
+		wantOK:      true,
+	}, {
+		name:        "invalid",
+		annotations: map[string]string{serving.TracingSampleRateAnnotationKey: "often"},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Revision{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}}
+			if got, ok := r.TracingSampleRate(); got != tt.want || ok != tt.wantOK {
+				t.Errorf("TracingSampleRate() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestRevisionGetLastPinned(t *testing.T) {
 	cases := []struct {
 		name              string
@@ -21,13 +21,21 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"knative.dev/serving/pkg/apis/config"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
+	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/imagepolicy"
+	"knative.dev/serving/pkg/quota"
 )
 
 func (r *Revision) checkImmutableFields(ctx context.Context, original *Revision) *apis.FieldError {
@@ -55,6 +63,13 @@ func (r *Revision) Validate(ctx context.Context) *apis.FieldError {
 		errs = errs.Also(r.checkImmutableFields(ctx, old))
 	} else {
 		errs = errs.Also(r.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
+		if errs == nil {
+			// Only the images of valid Revisions are worth reviewing.
+			errs = imagepolicy.Validate(ctx, r.Namespace, r.Spec.images())
+		}
+		if errs == nil {
+			errs = quota.ValidateRevision(ctx, r.ObjectMeta)
+		}
 	}
 	return errs
 }
@@ -85,6 +100,14 @@ func (rt *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError {
 	}
 
 	errs = errs.Also(validateAnnotations(rt.Annotations))
+	errs = errs.Also(serving.ValidateProfile(ctx, rt.Annotations))
+	errs = errs.Also(validateNodeArch(ctx, rt.Annotations))
+	containerPath := "spec.container"
+	if rt.Spec.DeprecatedContainer == nil {
+		containerPath = "spec.containers[0]"
+	}
+	errs = errs.Also(validateQoSClass(rt.Annotations, rt.Spec.GetContainer(), containerPath))
+	errs = errs.Also(validateUserSocket(rt.Annotations, rt.Spec.GetContainer(), containerPath))
 	return errs
 }
 
@@ -116,6 +139,18 @@ func (current *RevisionTemplateSpec) VerifyNameChange(ctx context.Context, og *R
 	return nil
 }
 
+// images returns the images of the containers of rs.
+func (rs *RevisionSpec) images() []string {
+	images := make([]string, 0, len(rs.Containers)+1)
+	if rs.DeprecatedContainer != nil {
+		images = append(images, rs.DeprecatedContainer.Image)
+	}
+	for _, c := range rs.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
 // Validate ensures RevisionSpec is properly configured.
 func (rs *RevisionSpec) Validate(ctx context.Context) *apis.FieldError {
 	if equality.Semantic.DeepEqual(rs, &RevisionSpec{}) {
@@ -157,7 +192,275 @@ func (rs *RevisionSpec) Validate(ctx context.Context) *apis.FieldError {
 }
 
 func validateAnnotations(annotations map[string]string) *apis.FieldError {
-	return validatePercentageAnnotationKey(annotations, serving.QueueSideCarResourcePercentageAnnotation)
+	return validatePercentageAnnotationKey(annotations, serving.QueueSideCarResourcePercentageAnnotation).Also(
+		validateDurationAnnotationKey(annotations, serving.ResponseCacheTTLAnnotationKey, serving.MaxResponseCacheTTL)).Also(
+		validateDurationAnnotationKey(annotations, serving.HedgeDelayAnnotationKey, serving.MaxHedgeDelay)).Also(
+		validateRequestBufferSize(annotations)).Also(
+		validateNodeOS(annotations)).Also(
+		validatePrometheusAnnotations(annotations)).Also(
+		validateRateLimit(annotations)).Also(
+		validateTimeoutOverride(annotations)).Also(
+		validateTracingSampleRate(annotations)).Also(
+		validateCompression(annotations)).Also(
+		validateOpenAPISchema(annotations)).Also(
+		validateDependencies(annotations)).Also(
+		validateDebugSecret(annotations))
+}
+
+// validateDebugSecret checks that the DebugSecretAnnotationKey annotation
+// names a Secret.
+func validateDebugSecret(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.DebugSecretAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(v); len(errs) > 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.DebugSecretAnnotationKey)
+	}
+	return nil
+}
+
+// validateDependencies checks the DependenciesAnnotationKey annotation.
+func validateDependencies(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.DependenciesAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if _, err := serving.ParseDependencies(v); err != nil {
+		return (&apis.FieldError{
+			Message: fmt.Sprintf("invalid value: %s", v),
+			Paths:   []string{apis.CurrentField},
+			Details: err.Error(),
+		}).ViaKey(serving.DependenciesAnnotationKey)
+	}
+	return nil
+}
+
+// validateOpenAPISchema checks that the OpenAPISchemaAnnotationKey
+// annotation names a ConfigMap.
+func validateOpenAPISchema(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.OpenAPISchemaAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(v); len(errs) > 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.OpenAPISchemaAnnotationKey)
+	}
+	return nil
+}
+
+// supportedCompressions are the content codings the queue-proxy can
+// compress responses with. Brotli ("br") needs an encoder the queue-proxy
+// does not ship yet.
+var supportedCompressions = sets.NewString("gzip", "deflate")
+
+// validateCompression checks the CompressionAnnotationKey annotation.
+func validateCompression(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.CompressionAnnotationKey]
+	if !ok {
+		return nil
+	}
+	for _, coding := range strings.Split(v, ",") {
+		if !supportedCompressions.Has(strings.TrimSpace(coding)) {
+			return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.CompressionAnnotationKey)
+		}
+	}
+	return nil
+}
+
+// validateRateLimit checks the RateLimitAnnotationKey and
+// RateLimitBurstAnnotationKey annotations. A burst makes no sense without
+// a rate limit.
+func validateRateLimit(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	limit, hasLimit := annotations[serving.RateLimitAnnotationKey]
+	if hasLimit {
+		if v, err := strconv.ParseFloat(limit, 64); err != nil || v <= 0 {
+			errs = errs.Also(apis.ErrInvalidValue(limit, apis.CurrentField).ViaKey(serving.RateLimitAnnotationKey))
+		}
+	}
+	if burst, ok := annotations[serving.RateLimitBurstAnnotationKey]; ok {
+		switch v, err := strconv.Atoi(burst); {
+		case err != nil || v < 1:
+			errs = errs.Also(apis.ErrInvalidValue(burst, apis.CurrentField).ViaKey(serving.RateLimitBurstAnnotationKey))
+		case !hasLimit:
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("%s requires %s", serving.RateLimitBurstAnnotationKey, serving.RateLimitAnnotationKey),
+				Paths:   []string{apis.CurrentField},
+			}).ViaKey(serving.RateLimitBurstAnnotationKey)
+		}
+	}
+	return errs
+}
+
+// validateTimeoutOverride checks the TimeoutOverrideAnnotationKey annotation.
+func validateTimeoutOverride(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.TimeoutOverrideAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if i, err := strconv.ParseInt(v, 10, 64); err != nil || i < 1 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.TimeoutOverrideAnnotationKey)
+	}
+	return nil
+}
+
+// validateTracingSampleRate checks that the TracingSampleRateAnnotationKey
+// annotation is a rate in [0, 1].
+func validateTracingSampleRate(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.TracingSampleRateAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if f, err := strconv.ParseFloat(v, 64); err != nil || f < 0 || f > 1 {
+		return apis.ErrOutOfBoundsValue(v, 0, 1, apis.CurrentField).ViaKey(serving.TracingSampleRateAnnotationKey)
+	}
+	return nil
+}
+
+func validateNodeOS(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.NodeOSAnnotationKey]
+	if !ok || v == serving.NodeOSLinux || v == serving.NodeOSWindows {
+		return nil
+	}
+	return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.NodeOSAnnotationKey)
+}
+
+// validateNodeArch checks the NodeArchAnnotationKey annotation is a node
+// label value of one of the architectures config-deployment allows.
+func validateNodeArch(ctx context.Context, annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.NodeArchAnnotationKey]
+	if !ok {
+		return nil
+	}
+	if v == "" || len(validation.IsValidLabelValue(v)) != 0 {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.NodeArchAnnotationKey)
+	}
+	if archs := config.FromContextOrDefaults(ctx).Deployment.Architectures; archs.Len() != 0 && !archs.Has(v) {
+		err := apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.NodeArchAnnotationKey)
+		err.Details = fmt.Sprintf("config-deployment only allows %s", strings.Join(archs.List(), ", "))
+		return err
+	}
+	return nil
+}
+
+// validatePrometheusAnnotations checks the annotations asking Prometheus to
+// scrape the user container. The ports of the queue-proxy are rejected, its
+// metrics are scraped separately.
+func validatePrometheusAnnotations(annotations map[string]string) *apis.FieldError {
+	var errs *apis.FieldError
+	if v, ok := annotations[serving.PrometheusScrapeAnnotationKey]; ok && v != "true" && v != "false" {
+		errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.PrometheusScrapeAnnotationKey))
+	}
+	if v, ok := annotations[serving.PrometheusPortAnnotationKey]; ok {
+		port, err := strconv.Atoi(v)
+		switch {
+		case err != nil:
+			errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.PrometheusPortAnnotationKey))
+		case port < 1 || port > 65535:
+			errs = errs.Also(apis.ErrOutOfBoundsValue(port, 1, 65535, apis.CurrentField).ViaKey(serving.PrometheusPortAnnotationKey))
+		case queuePorts.Has(port):
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("port %d is reserved for the queue-proxy", port),
+				Paths:   []string{apis.CurrentField},
+			}).ViaKey(serving.PrometheusPortAnnotationKey)
+		}
+	}
+	if v, ok := annotations[serving.PrometheusPathAnnotationKey]; ok && !strings.HasPrefix(v, "/") {
+		errs = errs.Also(apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.PrometheusPathAnnotationKey))
+	}
+	return errs
+}
+
+// queuePorts are the ports the queue-proxy listens on.
+var queuePorts = sets.NewInt(
+	networking.BackendHTTPPort,
+	networking.BackendHTTP2Port,
+	networking.QueueAdminPort,
+	networking.AutoscalingQueueMetricsPort,
+	networking.UserQueueMetricsPort)
+
+// validateQoSClass checks the QoSClassAnnotationKey annotation. A Guaranteed
+// pod needs the CPU and memory of every container bounded, so the user
+// container has to specify both.
+func validateQoSClass(annotations map[string]string, container *corev1.Container, containerPath string) *apis.FieldError {
+	v, ok := annotations[serving.QoSClassAnnotationKey]
+	if !ok {
+		return nil
+	}
+	switch corev1.PodQOSClass(v) {
+	case corev1.PodQOSBurstable:
+		return nil
+	case corev1.PodQOSGuaranteed:
+		if container == nil {
+			return nil
+		}
+		var errs *apis.FieldError
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			_, hasRequest := container.Resources.Requests[r]
+			_, hasLimit := container.Resources.Limits[r]
+			if !hasRequest && !hasLimit {
+				errs = errs.Also(&apis.FieldError{
+					Message: fmt.Sprintf("%s must be requested or limited for QoS class %s", r, v),
+					Paths:   []string{containerPath + ".resources"},
+				})
+			}
+		}
+		return errs
+	default:
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.QoSClassAnnotationKey)
+	}
+}
+
+// validateUserSocket checks the UserSocketAnnotationKey annotation. The
+// kubelet can't dial a Unix domain socket, so a user container serving on
+// one can't have a TCP liveness probe.
+func validateUserSocket(annotations map[string]string, container *corev1.Container, containerPath string) *apis.FieldError {
+	v, ok := annotations[serving.UserSocketAnnotationKey]
+	switch {
+	case !ok || v == "false":
+		return nil
+	case v != "true":
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.UserSocketAnnotationKey)
+	case container != nil && container.LivenessProbe != nil && container.LivenessProbe.TCPSocket != nil:
+		return &apis.FieldError{
+			Message: "TCP liveness probes are not supported with a user socket",
+			Paths:   []string{containerPath + ".livenessProbe.tcpSocket"},
+		}
+	}
+	return nil
+}
+
+func validateDurationAnnotationKey(annotations map[string]string, key string, max time.Duration) *apis.FieldError {
+	v, ok := annotations[key]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(key)
+	}
+	if d <= 0 || d > max {
+		return apis.ErrOutOfBoundsValue(d, 0, max, key)
+	}
+	return nil
+}
+
+// validateRequestBufferSize checks the RequestBufferSizeAnnotationKey
+// annotation, a quantity of bytes bounded by MaxRequestBufferSize.
+func validateRequestBufferSize(annotations map[string]string) *apis.FieldError {
+	v, ok := annotations[serving.RequestBufferSizeAnnotationKey]
+	if !ok {
+		return nil
+	}
+	q, err := resource.ParseQuantity(v)
+	if err != nil {
+		return apis.ErrInvalidValue(v, apis.CurrentField).ViaKey(serving.RequestBufferSizeAnnotationKey)
+	}
+	if size := q.Value(); size <= 0 || size > serving.MaxRequestBufferSize {
+		return apis.ErrOutOfBoundsValue(size, 1, serving.MaxRequestBufferSize, serving.RequestBufferSizeAnnotationKey)
+	}
+	return nil
 }
 
 func validatePercentageAnnotationKey(annotations map[string]string, resourcePercentageAnnotationKey string) *apis.FieldError {
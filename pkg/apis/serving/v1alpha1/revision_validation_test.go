@@ -26,6 +26,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
@@ -33,6 +34,9 @@ import (
 	"knative.dev/serving/pkg/apis/config"
 	net "knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/deployment"
+	"knative.dev/serving/pkg/imagepolicy"
+	"knative.dev/serving/pkg/quota"
 
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
@@ -238,57 +242,575 @@ func TestRevisionSpecValidation(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 			return s.ToContext(ctx)
 		},
-		want: apis.ErrOutOfBoundsValue(100, 0, 50, "timeoutSeconds"),
+		want: apis.ErrOutOfBoundsValue(100, 0, 50, "timeoutSeconds"),
+	}, {
+		name: "provided zero timeout (ok)",
+		rs: &RevisionSpec{
+			DeprecatedContainer: &corev1.Container{
+				Image: "helloworld",
+			},
+			RevisionSpec: v1beta1.RevisionSpec{
+				TimeoutSeconds: ptr.Int64(0),
+			},
+		},
+		want: nil,
+	}, {
+		name: "negative timeout",
+		rs: &RevisionSpec{
+			DeprecatedContainer: &corev1.Container{
+				Image: "helloworld",
+			},
+			RevisionSpec: v1beta1.RevisionSpec{
+				TimeoutSeconds: ptr.Int64(-30),
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(-30, 0,
+			config.DefaultMaxRevisionTimeoutSeconds,
+			"timeoutSeconds"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.wc != nil {
+				ctx = test.wc(ctx)
+			}
+			got := test.rs.Validate(ctx)
+			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestRevisionTemplateSpecValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		rts  *RevisionTemplateSpec
+		want *apis.FieldError
+	}{{
+		name: "valid",
+		rts: &RevisionTemplateSpec{
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "empty spec",
+		rts:  &RevisionTemplateSpec{},
+		want: apis.ErrMissingField("spec"),
+	}, {
+		name: "nested spec error",
+		rts: &RevisionTemplateSpec{
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Name:      "kevin",
+					Image:     "helloworld",
+					Lifecycle: &corev1.Lifecycle{},
+				},
+			},
+		},
+		want: apis.ErrDisallowedFields("spec.container.lifecycle"),
+	}, {
+		name: "has revision template name",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				// We let users bring their own revision name.
+				Name: "parent-foo",
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "Queue sidecar resource percentage annotation more than 100",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QueueSideCarResourcePercentageAnnotation: "200",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "expected 0.1 <= 200 <= 100",
+			Paths:   []string{serving.QueueSideCarResourcePercentageAnnotation},
+		},
+	}, {
+		name: "Invalid queue sidecar resource percentage annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QueueSideCarResourcePercentageAnnotation: "50mx",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: 50mx",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.QueueSideCarResourcePercentageAnnotation)},
+		},
+	}, {
+		name: "valid burstable qos class",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QoSClassAnnotationKey: "Burstable",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "valid guaranteed qos class",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QoSClassAnnotationKey: "Guaranteed",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU: resource.MustParse("100m"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("128Mi"),
+						},
+					},
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "guaranteed qos class without memory",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QoSClassAnnotationKey: "Guaranteed",
+				},
+			},
+			Spec: RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Image: "helloworld",
+							Resources: corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU: resource.MustParse("1"),
+								},
+							},
+						}},
+					},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "memory must be requested or limited for QoS class Guaranteed",
+			Paths:   []string{"spec.containers[0].resources"},
+		},
+	}, {
+		name: "invalid qos class",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.QoSClassAnnotationKey: "BestEffort",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: BestEffort",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.QoSClassAnnotationKey)},
+		},
+	}, {
+		name: "valid node os annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.NodeOSAnnotationKey: serving.NodeOSWindows,
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid node os annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.NodeOSAnnotationKey: "plan9",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: plan9",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.NodeOSAnnotationKey)},
+		},
+	}, {
+		name: "valid node arch annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.NodeArchAnnotationKey: "arm64",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "any node arch allowed without config-deployment",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.NodeArchAnnotationKey: "mips",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid node arch annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.NodeArchAnnotationKey: "arm/64",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: arm/64",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.NodeArchAnnotationKey)},
+		},
+	}, {
+		name: "valid rate limit annotations",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RateLimitAnnotationKey:      "2.5",
+					serving.RateLimitBurstAnnotationKey: "10",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid rate limit annotations",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RateLimitAnnotationKey:      "0",
+					serving.RateLimitBurstAnnotationKey: "lots",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("0", apis.CurrentField).ViaKey(serving.RateLimitAnnotationKey).Also(
+			apis.ErrInvalidValue("lots", apis.CurrentField).ViaKey(serving.RateLimitBurstAnnotationKey)),
+	}, {
+		name: "rate limit burst without rate limit",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RateLimitBurstAnnotationKey: "10",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: fmt.Sprintf("%s requires %s", serving.RateLimitBurstAnnotationKey, serving.RateLimitAnnotationKey),
+			Paths:   []string{fmt.Sprintf("[%s]", serving.RateLimitBurstAnnotationKey)},
+		},
+	}, {
+		name: "invalid timeout override annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.TimeoutOverrideAnnotationKey: "-5",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: apis.ErrInvalidValue("-5", apis.CurrentField).ViaKey(serving.TimeoutOverrideAnnotationKey),
+	}, {
+		name: "invalid tracing sample rate annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.TracingSampleRateAnnotationKey: "1.5",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue("1.5", 0, 1, apis.CurrentField).ViaKey(serving.TracingSampleRateAnnotationKey),
+	}, {
+		name: "valid compression annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.CompressionAnnotationKey: "gzip, deflate",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "unsupported compression annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.CompressionAnnotationKey: "gzip,compress",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: gzip,compress",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.CompressionAnnotationKey)},
+		},
+	}, {
+		name: "valid dependencies annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.DependenciesAnnotationKey: "db:5432,http://auth/healthz",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid dependencies annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.DependenciesAnnotationKey: "db",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: db",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.DependenciesAnnotationKey)},
+			Details: `dependency "db" is not a host:port address`,
+		},
+	}, {
+		name: "invalid debug secret annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.DebugSecretAnnotationKey: "Not_A_Secret",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: Not_A_Secret",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.DebugSecretAnnotationKey)},
+		},
+	}, {
+		name: "valid openapi schema annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.OpenAPISchemaAnnotationKey: "my-api",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "invalid openapi schema annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.OpenAPISchemaAnnotationKey: "My API",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: My API",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.OpenAPISchemaAnnotationKey)},
+		},
 	}, {
-		name: "provided zero timeout (ok)",
-		rs: &RevisionSpec{
-			DeprecatedContainer: &corev1.Container{
-				Image: "helloworld",
+		name: "valid user socket annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.UserSocketAnnotationKey: "true",
+				},
 			},
-			RevisionSpec: v1beta1.RevisionSpec{
-				TimeoutSeconds: ptr.Int64(0),
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
 			},
 		},
 		want: nil,
 	}, {
-		name: "negative timeout",
-		rs: &RevisionSpec{
-			DeprecatedContainer: &corev1.Container{
-				Image: "helloworld",
+		name: "invalid user socket annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.UserSocketAnnotationKey: "yes",
+				},
 			},
-			RevisionSpec: v1beta1.RevisionSpec{
-				TimeoutSeconds: ptr.Int64(-30),
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
 			},
 		},
-		want: apis.ErrOutOfBoundsValue(-30, 0,
-			config.DefaultMaxRevisionTimeoutSeconds,
-			"timeoutSeconds"),
-	}}
-
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			ctx := context.Background()
-			if test.wc != nil {
-				ctx = test.wc(ctx)
-			}
-			got := test.rs.Validate(ctx)
-			if diff := cmp.Diff(test.want.Error(), got.Error()); diff != "" {
-				t.Errorf("Validate (-want, +got) = %v", diff)
-			}
-		})
-	}
-}
-
-func TestRevisionTemplateSpecValidation(t *testing.T) {
-	tests := []struct {
-		name string
-		rts  *RevisionTemplateSpec
-		want *apis.FieldError
-	}{{
-		name: "valid",
+		want: &apis.FieldError{
+			Message: "invalid value: yes",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.UserSocketAnnotationKey)},
+		},
+	}, {
+		name: "user socket with tcp liveness probe",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.UserSocketAnnotationKey: "true",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+					LivenessProbe: &corev1.Probe{
+						Handler: corev1.Handler{
+							TCPSocket: &corev1.TCPSocketAction{},
+						},
+					},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "TCP liveness probes are not supported with a user socket",
+			Paths:   []string{"spec.container.livenessProbe.tcpSocket"},
+		},
+	}, {
+		name: "valid prometheus annotations",
 		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.PrometheusScrapeAnnotationKey: "true",
+					serving.PrometheusPortAnnotationKey:   "8081",
+					serving.PrometheusPathAnnotationKey:   "/stats",
+				},
+			},
 			Spec: RevisionSpec{
 				DeprecatedContainer: &corev1.Container{
 					Image: "helloworld",
@@ -297,27 +819,67 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 		},
 		want: nil,
 	}, {
-		name: "empty spec",
-		rts:  &RevisionTemplateSpec{},
-		want: apis.ErrMissingField("spec"),
+		name: "invalid prometheus annotations",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.PrometheusScrapeAnnotationKey: "yes",
+					serving.PrometheusPathAnnotationKey:   "stats",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: (&apis.FieldError{
+			Message: "invalid value: yes",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.PrometheusScrapeAnnotationKey)},
+		}).Also(&apis.FieldError{
+			Message: "invalid value: stats",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.PrometheusPathAnnotationKey)},
+		}),
 	}, {
-		name: "nested spec error",
+		name: "prometheus port of the queue-proxy",
 		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.PrometheusPortAnnotationKey: "9091",
+				},
+			},
 			Spec: RevisionSpec{
 				DeprecatedContainer: &corev1.Container{
-					Name:      "kevin",
-					Image:     "helloworld",
-					Lifecycle: &corev1.Lifecycle{},
+					Image: "helloworld",
 				},
 			},
 		},
-		want: apis.ErrDisallowedFields("spec.container.lifecycle"),
+		want: &apis.FieldError{
+			Message: "port 9091 is reserved for the queue-proxy",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.PrometheusPortAnnotationKey)},
+		},
 	}, {
-		name: "has revision template name",
+		name: "prometheus port out of range",
 		rts: &RevisionTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				// We let users bring their own revision name.
-				Name: "parent-foo",
+				Annotations: map[string]string{
+					serving.PrometheusPortAnnotationKey: "70000",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: apis.ErrOutOfBoundsValue(70000, 1, 65535, fmt.Sprintf("[%s]", serving.PrometheusPortAnnotationKey)),
+	}, {
+		name: "valid response cache ttl annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.ResponseCacheTTLAnnotationKey: "1s",
+				},
 			},
 			Spec: RevisionSpec{
 				DeprecatedContainer: &corev1.Container{
@@ -327,11 +889,11 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 		},
 		want: nil,
 	}, {
-		name: "Queue sidecar resource percentage annotation more than 100",
+		name: "response cache ttl annotation too large",
 		rts: &RevisionTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
 				Annotations: map[string]string{
-					serving.QueueSideCarResourcePercentageAnnotation: "200",
+					serving.ResponseCacheTTLAnnotationKey: "1h",
 				},
 			},
 			Spec: RevisionSpec{
@@ -341,15 +903,15 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 			},
 		},
 		want: &apis.FieldError{
-			Message: "expected 0.1 <= 200 <= 100",
-			Paths:   []string{serving.QueueSideCarResourcePercentageAnnotation},
+			Message: "expected 0 <= 1h0m0s <= 1m0s",
+			Paths:   []string{serving.ResponseCacheTTLAnnotationKey},
 		},
 	}, {
-		name: "Invalid queue sidecar resource percentage annotation",
+		name: "invalid response cache ttl annotation",
 		rts: &RevisionTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
 				Annotations: map[string]string{
-					serving.QueueSideCarResourcePercentageAnnotation: "50mx",
+					serving.ResponseCacheTTLAnnotationKey: "soon",
 				},
 			},
 			Spec: RevisionSpec{
@@ -359,8 +921,77 @@ func TestRevisionTemplateSpecValidation(t *testing.T) {
 			},
 		},
 		want: &apis.FieldError{
-			Message: "invalid value: 50mx",
-			Paths:   []string{fmt.Sprintf("[%s]", serving.QueueSideCarResourcePercentageAnnotation)},
+			Message: "invalid value: soon",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.ResponseCacheTTLAnnotationKey)},
+		},
+	}, {
+		name: "hedge delay annotation not positive",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.HedgeDelayAnnotationKey: "0s",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "expected 0 <= 0s <= 10s",
+			Paths:   []string{serving.HedgeDelayAnnotationKey},
+		},
+	}, {
+		name: "request buffer size annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RequestBufferSizeAnnotationKey: "64Ki",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: nil,
+	}, {
+		name: "request buffer size annotation too large",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RequestBufferSizeAnnotationKey: "1Gi",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "expected 1 <= 1073741824 <= 10485760",
+			Paths:   []string{serving.RequestBufferSizeAnnotationKey},
+		},
+	}, {
+		name: "invalid request buffer size annotation",
+		rts: &RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					serving.RequestBufferSizeAnnotationKey: "lots",
+				},
+			},
+			Spec: RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "helloworld",
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "invalid value: lots",
+			Paths:   []string{fmt.Sprintf("[%s]", serving.RequestBufferSizeAnnotationKey)},
 		},
 	}}
 
@@ -467,6 +1098,134 @@ func TestRevisionValidation(t *testing.T) {
 	}
 }
 
+type rejectingChecker struct {
+	checked []string
+}
+
+func (rc *rejectingChecker) Check(_ context.Context, _ string, images []string) (*imagepolicy.Decision, error) {
+	rc.checked = append(rc.checked, images...)
+	return &imagepolicy.Decision{Reason: "image is not signed"}, nil
+}
+
+func TestRevisionImagePolicy(t *testing.T) {
+	r := &Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "unsigned",
+			Namespace: "production",
+		},
+		Spec: RevisionSpec{
+			DeprecatedContainer: &corev1.Container{
+				Image: "helloworld",
+			},
+		},
+	}
+	checker := &rejectingChecker{}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		ImagePolicy: &config.ImagePolicy{
+			EnforcementMode: config.Enforce,
+		},
+	})
+	ctx = imagepolicy.WithChecker(ctx, checker)
+
+	want := &apis.FieldError{
+		Message: "Images rejected by the image policy: helloworld",
+		Paths:   []string{"spec"},
+		Details: "image is not signed",
+	}
+	if got := r.Validate(ctx); got.Error() != want.Error() {
+		t.Errorf("Validate() = %v, want: %v", got, want)
+	}
+	if want := []string{"helloworld"}; !cmp.Equal(checker.checked, want) {
+		t.Errorf("Checked images = %v, want: %v", checker.checked, want)
+	}
+
+	// The images of existing Revisions aren't checked again.
+	checker.checked = nil
+	if got := r.Validate(apis.WithinUpdate(ctx, r.DeepCopy())); got != nil {
+		t.Errorf("Validate() = %v, wanted no error", got)
+	}
+	if len(checker.checked) != 0 {
+		t.Errorf("Checked images = %v, wanted none", checker.checked)
+	}
+}
+
+func TestRevisionTemplateSpecNodeArch(t *testing.T) {
+	rts := &RevisionTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				serving.NodeArchAnnotationKey: "mips",
+			},
+		},
+		Spec: RevisionSpec{
+			DeprecatedContainer: &corev1.Container{
+				Image: "helloworld",
+			},
+		},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Deployment: &deployment.Config{
+			Architectures: sets.NewString("amd64", "arm64"),
+		},
+	})
+
+	want := &apis.FieldError{
+		Message: "invalid value: mips",
+		Paths:   []string{fmt.Sprintf("[%s]", serving.NodeArchAnnotationKey)},
+		Details: "config-deployment only allows amd64, arm64",
+	}
+	if got := rts.Validate(ctx); got.Error() != want.Error() {
+		t.Errorf("Validate() = %v, want: %v", got, want)
+	}
+
+	rts.Annotations[serving.NodeArchAnnotationKey] = "arm64"
+	if got := rts.Validate(ctx); got != nil {
+		t.Errorf("Validate() = %v, wanted no error", got)
+	}
+}
+
+type fullCounter struct{}
+
+func (fullCounter) Services(string) (int, error)          { return 10, nil }
+func (fullCounter) Revisions(string, string) (int, error) { return 10, nil }
+func (fullCounter) RevisionPods(string) (int, error)      { return 10, nil }
+
+func TestRevisionQuota(t *testing.T) {
+	r := &Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "one-too-many",
+			Namespace: "production",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey: "config",
+			},
+		},
+		Spec: RevisionSpec{
+			DeprecatedContainer: &corev1.Container{
+				Image: "helloworld",
+			},
+		},
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Quota: &config.Quota{
+			MaxRevisionsPerConfiguration: 10,
+		},
+	})
+	ctx = quota.WithCounter(ctx, fullCounter{})
+
+	want := &apis.FieldError{
+		Message: "Quota of 10 exceeded",
+		Paths:   []string{apis.CurrentField},
+		Details: `configuration "config" already has 10 Revisions`,
+	}
+	if got := r.Validate(ctx); got.Error() != want.Error() {
+		t.Errorf("Validate() = %v, want: %v", got, want)
+	}
+
+	// Existing Revisions aren't counted again.
+	if got := r.Validate(apis.WithinUpdate(ctx, r.DeepCopy())); got != nil {
+		t.Errorf("Validate() = %v, wanted no error", got)
+	}
+}
+
 func TestImmutableFields(t *testing.T) {
 	tests := []struct {
 		name string
@@ -538,6 +1297,22 @@ func TestImmutableFields(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 			return s.ToContext(ctx)
 		},
 		want: nil,
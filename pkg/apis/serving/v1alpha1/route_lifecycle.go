@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -74,6 +75,13 @@ func (rs *RouteStatus) MarkIngressNotConfigured() {
 		"IngressNotConfigured", "Ingress has not yet been reconciled.")
 }
 
+// MarkDomainNotResolved changes the IngressReady condition to be unknown to
+// reflect that the domain of the Route doesn't resolve to the ingress yet.
+func (rs *RouteStatus) MarkDomainNotResolved(domain, reason string) {
+	routeCondSet.Manage(rs).MarkUnknown(RouteConditionIngressReady, "DomainNotResolved",
+		"Domain %q does not resolve to the ingress yet: %s", domain, reason)
+}
+
 func (rs *RouteStatus) MarkTrafficAssigned() {
 	routeCondSet.Manage(rs).MarkTrue(RouteConditionAllTrafficAssigned)
 }
@@ -132,6 +140,19 @@ func (rs *RouteStatus) MarkCertificateReady(name string) {
 	})
 }
 
+// MarkCertificateExpiring changes the CertificateProvisioned condition to
+// reflect that the certificate of the given name is about to expire, or
+// expired, without having been renewed.
+func (rs *RouteStatus) MarkCertificateExpiring(name string, notAfter time.Time) {
+	routeCondSet.Manage(rs).SetCondition(apis.Condition{
+		Type:     RouteConditionCertificateProvisioned,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityWarning,
+		Reason:   "CertificateExpiring",
+		Message:  fmt.Sprintf("Certificate %s expires at %s and has not been renewed.", name, notAfter.UTC().Format(time.RFC3339)),
+	})
+}
+
 func (rs *RouteStatus) MarkCertificateNotReady(name string) {
 	routeCondSet.Manage(rs).SetCondition(apis.Condition{
 		Type:     RouteConditionCertificateProvisioned,
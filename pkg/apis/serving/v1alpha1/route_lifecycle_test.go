@@ -17,6 +17,7 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -355,6 +356,18 @@ func TestCertificateReady(t *testing.T) {
 	apitesting.CheckConditionSucceeded(r.duck(), RouteConditionCertificateProvisioned, t)
 }
 
+func TestCertificateExpiring(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkCertificateExpiring("cert", time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC))
+
+	apitesting.CheckConditionSucceeded(r.duck(), RouteConditionCertificateProvisioned, t)
+	cond := r.GetCondition(RouteConditionCertificateProvisioned)
+	if got, want := cond.Message, "Certificate cert expires at 2019-08-01T00:00:00Z and has not been renewed."; got != want {
+		t.Errorf("Message = %q, want: %q", got, want)
+	}
+}
+
 func TestCertificateNotReady(t *testing.T) {
 	r := &RouteStatus{}
 	r.InitializeConditions()
@@ -386,3 +399,25 @@ func TestIngressNotConfigured(t *testing.T) {
 
 	apitesting.CheckConditionOngoing(r.duck(), RouteConditionIngressReady, t)
 }
+
+func TestDomainNotResolved(t *testing.T) {
+	r := &RouteStatus{}
+	r.InitializeConditions()
+	r.MarkTrafficAssigned()
+	r.PropagateIngressStatus(netv1alpha1.IngressStatus{
+		Status: duckv1beta1.Status{
+			Conditions: duckv1beta1.Conditions{{
+				Type:   netv1alpha1.IngressConditionReady,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	})
+	apitesting.CheckConditionSucceeded(r.duck(), RouteConditionReady, t)
+
+	r.MarkDomainNotResolved("foo.example.com", "no such host")
+	apitesting.CheckConditionOngoing(r.duck(), RouteConditionIngressReady, t)
+	apitesting.CheckConditionOngoing(r.duck(), RouteConditionReady, t)
+	if got, want := r.GetCondition(RouteConditionIngressReady).Reason, "DomainNotResolved"; got != want {
+		t.Errorf("Reason = %q, want: %q", got, want)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
 
 func (r *Route) Validate(ctx context.Context) *apis.FieldError {
@@ -44,13 +45,27 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 	}
 	// Track the targets of named TrafficTarget entries (to detect duplicates).
 	trafficMap := make(map[string]diagnostic)
+	fallback := -1
 
-	percentSum := 0
+	// The targets of each path split its traffic, keyed by path.
+	percentSums := make(map[string]int)
 	for i, tt := range rs.Traffic {
 		// Delegate to the v1beta1 validation.
 		errs = errs.Also(tt.TrafficTarget.Validate(ctx).ViaFieldIndex("traffic", i))
 
-		percentSum += tt.Percent
+		percentSums[tt.Path] += tt.Percent
+
+		if tt.Fallback && fallback >= 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: "Multiple fallback targets",
+				Paths: []string{
+					fmt.Sprintf("traffic[%d].fallback", fallback),
+					fmt.Sprintf("traffic[%d].fallback", i),
+				},
+			})
+		} else if tt.Fallback {
+			fallback = i
+		}
 
 		if tt.DeprecatedName != "" && tt.Tag != "" {
 			errs = errs.Also(apis.ErrMultipleOneOf("name", "tag").
@@ -85,11 +100,5 @@ func (rs *RouteSpec) Validate(ctx context.Context) *apis.FieldError {
 		}
 	}
 
-	if percentSum != 100 {
-		errs = errs.Also(&apis.FieldError{
-			Message: fmt.Sprintf("Traffic targets sum to %d, want 100", percentSum),
-			Paths:   []string{"traffic"},
-		})
-	}
-	return errs
+	return errs.Also(v1beta1.ValidatePathSums(percentSums).ViaField("traffic"))
 }
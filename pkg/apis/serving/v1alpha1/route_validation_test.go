@@ -365,6 +365,75 @@ func TestRouteSpecValidation(t *testing.T) {
 				"traffic[1].tag",
 			},
 		},
+	}, {
+		name: "multiple fallbacks",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "foo",
+					Percent:      100,
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "bar",
+					Fallback:     true,
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "baz",
+					Fallback:     true,
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: "Multiple fallback targets",
+			Paths: []string{
+				"traffic[1].fallback",
+				"traffic[2].fallback",
+			},
+		},
+	}, {
+		name: "paths",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "foo",
+					Percent:      100,
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "bar",
+					Percent:      60,
+					Path:         "/api",
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "baz",
+					Percent:      40,
+					Path:         "/api",
+				},
+			}},
+		},
+	}, {
+		name: "path not summing to 100",
+		rs: &RouteSpec{
+			Traffic: []TrafficTarget{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "foo",
+					Percent:      100,
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "bar",
+					Percent:      60,
+					Path:         "/api",
+				},
+			}},
+		},
+		want: &apis.FieldError{
+			Message: `Traffic targets of path "/api" sum to 60, want 100`,
+			Paths:   []string{"traffic"},
+		},
 	}}
 
 	for _, test := range tests {
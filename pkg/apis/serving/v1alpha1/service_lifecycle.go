@@ -89,6 +89,7 @@ func (ss *ServiceStatus) MarkConfigurationNotReconciled() {
 // to the Service status.
 func (ss *ServiceStatus) PropagateConfigurationStatus(cs *ConfigurationStatus) {
 	ss.ConfigurationStatusFields = cs.ConfigurationStatusFields
+	propagateResourcesExhausted(cs.GetCondition(ConditionTypeResourcesExhausted), serviceCondSet.Manage(ss))
 
 	cc := cs.GetCondition(ConfigurationConditionReady)
 	if cc == nil {
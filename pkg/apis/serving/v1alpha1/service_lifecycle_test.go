@@ -446,6 +446,25 @@ func TestConfigurationStatusPropagation(t *testing.T) {
 	}
 }
 
+func TestServiceResourcesExhaustedPropagation(t *testing.T) {
+	svc := &ServiceStatus{}
+	svc.InitializeConditions()
+
+	cs := &ConfigurationStatus{}
+	cs.InitializeConditions()
+	rs := &RevisionStatus{}
+	rs.MarkResourcesExhausted("FailedCreate", "exceeded quota")
+	cs.PropagateResourcesExhausted(rs)
+
+	svc.PropagateConfigurationStatus(cs)
+	apitesting.CheckConditionSucceeded(svc.duck(), ConditionTypeResourcesExhausted, t)
+	apitesting.CheckConditionOngoing(svc.duck(), ServiceConditionReady, t)
+
+	cs.PropagateResourcesExhausted(&RevisionStatus{})
+	svc.PropagateConfigurationStatus(cs)
+	apitesting.CheckConditionFailed(svc.duck(), ConditionTypeResourcesExhausted, t)
+}
+
 func TestRouteFailurePropagation(t *testing.T) {
 	svc := &ServiceStatus{}
 	svc.InitializeConditions()
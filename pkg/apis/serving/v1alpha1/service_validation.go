@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/quota"
 
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 )
@@ -40,6 +41,11 @@ func (s *Service) Validate(ctx context.Context) (errs *apis.FieldError) {
 		errs = errs.Also(s.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	}
 
+	if !apis.IsInUpdate(ctx) && errs == nil {
+		// Only valid Services are worth counting.
+		errs = quota.ValidateService(ctx, s.Namespace)
+	}
+
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Service)
 
@@ -57,9 +63,30 @@ func (s *Service) Validate(ctx context.Context) (errs *apis.FieldError) {
 		}
 	}
 
+	if errs == nil {
+		// Only valid templates are worth checking the references of.
+		errs = s.validateReferences(ctx)
+	}
+
 	return errs
 }
 
+// validateReferences checks that the resources the template of s refers to
+// exist.
+func (s *Service) validateReferences(ctx context.Context) *apis.FieldError {
+	field, cs := s.Spec.getConfigurationSpec()
+	var baseline *RevisionTemplateSpec
+	if apis.IsInUpdate(ctx) {
+		_, original := apis.GetBaseline(ctx).(*Service).Spec.getConfigurationSpec()
+		baseline = original.GetTemplate()
+	}
+	errs := validateReferences(ctx, s.Namespace, cs, baseline)
+	if field != "" {
+		errs = errs.ViaField(field, "configuration")
+	}
+	return errs.ViaField("spec")
+}
+
 func (ss *ServiceSpec) getConfigurationSpec() (string, *ConfigurationSpec) {
 	switch {
 	case ss.DeprecatedRunLatest != nil:
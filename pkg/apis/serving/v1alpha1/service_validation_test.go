@@ -30,6 +30,7 @@ import (
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/quota"
 )
 
 const incorrectDNS1035Label = "not a DNS 1035 label: [a DNS-1035 label must consist of lower case alphanumeric characters or '-', start with an alphabetic character, and end with an alphanumeric character (e.g. 'my-name',  or 'abc-123', regex used for validation is '[a-z]([-a-z0-9]*[a-z0-9])?')]"
@@ -697,6 +698,41 @@ func TestServiceValidation(t *testing.T) {
 			},
 		},
 		want: nil,
+	}, {
+		name: "over the quota of services",
+		wc: func(ctx context.Context) context.Context {
+			cfg := config.FromContextOrDefaults(ctx)
+			cfg.Quota.MaxServicesPerNamespace = 10
+			return quota.WithCounter(config.ToContext(ctx, cfg), fullCounter{})
+		},
+		s: &Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "valid",
+				Namespace: "production",
+			},
+			Spec: ServiceSpec{
+				DeprecatedRunLatest: &RunLatestType{
+					Configuration: ConfigurationSpec{
+						DeprecatedRevisionTemplate: &RevisionTemplateSpec{
+							Spec: RevisionSpec{
+								RevisionSpec: v1beta1.RevisionSpec{
+									PodSpec: corev1.PodSpec{
+										Containers: []corev1.Container{{
+											Image: "hellworld",
+										}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Quota of 10 exceeded",
+			Paths:   []string{apis.CurrentField},
+			Details: `namespace "production" already holds 10 Services`,
+		},
 	}}
 
 	// TODO(mattmoor): Add a test for default configurationName
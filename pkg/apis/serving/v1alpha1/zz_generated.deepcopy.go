@@ -316,6 +316,10 @@ func (in *RevisionSpec) DeepCopy() *RevisionSpec {
 func (in *RevisionStatus) DeepCopyInto(out *RevisionStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
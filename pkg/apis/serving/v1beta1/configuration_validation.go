@@ -19,8 +19,10 @@ package v1beta1
 import (
 	"context"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/refcheck"
 )
 
 // Validate makes sure that Configuration is properly configured.
@@ -37,16 +39,33 @@ func (c *Configuration) Validate(ctx context.Context) (errs *apis.FieldError) {
 
 	errs = errs.Also(c.Status.Validate(apis.WithinStatus(ctx)).ViaField("status"))
 
+	var baseline *RevisionTemplateSpec
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Configuration)
+		baseline = &original.Spec.Template
 
 		err := c.Spec.Template.VerifyNameChange(ctx, original.Spec.Template)
 		errs = errs.Also(err.ViaField("spec.template"))
 	}
 
+	// The references of the Configurations of Services are checked with them.
+	if errs == nil && c.Labels[serving.ServiceLabelKey] == "" {
+		errs = validateReferences(ctx, c.Namespace, &c.Spec.Template, baseline).ViaField("spec.template")
+	}
+
 	return errs
 }
 
+// validateReferences checks that the resources template, that of a resource
+// in namespace, refers to exist. They are only checked when the resource is
+// created, or when template changed from baseline.
+func validateReferences(ctx context.Context, namespace string, template, baseline *RevisionTemplateSpec) *apis.FieldError {
+	if apis.IsInStatusUpdate(ctx) || (baseline != nil && equality.Semantic.DeepEqual(baseline.Spec, template.Spec)) {
+		return nil
+	}
+	return refcheck.Validate(ctx, namespace, &template.Spec.PodSpec).ViaField("spec")
+}
+
 // Validate implements apis.Validatable
 func (cs *ConfigurationSpec) Validate(ctx context.Context) *apis.FieldError {
 	return cs.Template.Validate(ctx).ViaField("template")
@@ -22,6 +22,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 )
 
 // SetDefaults implements apis.Defaultable
@@ -31,6 +32,11 @@ func (r *Revision) SetDefaults(ctx context.Context) {
 
 // SetDefaults implements apis.Defaultable
 func (rts *RevisionTemplateSpec) SetDefaults(ctx context.Context) {
+	containers := make([]*corev1.Container, 0, len(rts.Spec.Containers))
+	for idx := range rts.Spec.Containers {
+		containers = append(containers, &rts.Spec.Containers[idx])
+	}
+	serving.SetProfileDefaults(ctx, &rts.ObjectMeta, containers...)
 	rts.Spec.SetDefaults(ctx)
 }
 
@@ -29,6 +29,7 @@ import (
 	"knative.dev/pkg/ptr"
 
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/deployment"
 )
 
 var (
@@ -69,6 +70,22 @@ func TestRevisionDefaulting(t *testing.T) {
 					"revision-timeout-seconds": "123",
 				},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 
 			return s.ToContext(ctx)
 		},
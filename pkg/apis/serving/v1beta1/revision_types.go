@@ -121,6 +121,12 @@ type RevisionStatus struct {
 	// may be empty if the image comes from a registry listed to skip resolution.
 	// +optional
 	ImageDigest string `json:"imageDigest,omitempty"`
+
+	// IdleSince is the time the Revision was last sent a request at, once
+	// it went without requests for a while. It is unset while the Revision
+	// is active, and helps to find the Revisions nobody uses anymore.
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -26,6 +26,8 @@ import (
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/kmp"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/imagepolicy"
+	"knative.dev/serving/pkg/quota"
 )
 
 // Validate ensures Revision is properly configured.
@@ -50,6 +52,13 @@ func (r *Revision) Validate(ctx context.Context) *apis.FieldError {
 		}
 	} else {
 		errs = errs.Also(r.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
+		if errs == nil {
+			// Only the images of valid Revisions are worth reviewing.
+			errs = imagepolicy.Validate(ctx, r.Namespace, r.Spec.images())
+		}
+		if errs == nil {
+			errs = quota.ValidateRevision(ctx, r.ObjectMeta)
+		}
 	}
 
 	return errs
@@ -76,7 +85,7 @@ func (rts *RevisionTemplateSpec) Validate(ctx context.Context) *apis.FieldError
 		}
 	}
 
-	return errs
+	return errs.Also(serving.ValidateProfile(ctx, rts.Annotations))
 }
 
 // VerifyNameChange checks that if a user brought their own name previously that it
@@ -107,11 +116,20 @@ func (current *RevisionTemplateSpec) VerifyNameChange(ctx context.Context, og Re
 	return nil
 }
 
+// images returns the images of the containers of rs.
+func (rs *RevisionSpec) images() []string {
+	images := make([]string, 0, len(rs.Containers))
+	for _, c := range rs.Containers {
+		images = append(images, c.Image)
+	}
+	return images
+}
+
 // Validate implements apis.Validatable
 func (rs *RevisionSpec) Validate(ctx context.Context) *apis.FieldError {
 	err := rs.ContainerConcurrency.Validate(ctx).ViaField("containerConcurrency")
 
-	err = err.Also(serving.ValidatePodSpec(rs.PodSpec))
+	err = err.Also(serving.ValidatePodSpec(ctx, rs.PodSpec))
 
 	if rs.TimeoutSeconds != nil {
 		ts := *rs.TimeoutSeconds
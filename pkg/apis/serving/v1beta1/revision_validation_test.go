@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/deployment"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -260,6 +261,22 @@ func TestRevisionSpecValidation(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 			return s.ToContext(ctx)
 		},
 		want: apis.ErrOutOfBoundsValue(100, 0, 50, "timeoutSeconds"),
@@ -368,6 +385,22 @@ func TestImmutableFields(t *testing.T) {
 					"revision-timeout-seconds":     "25",
 					"max-revision-timeout-seconds": "50"},
 			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.FeaturesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ImagePolicyConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.QuotaConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: config.ProfilesConfigName},
+			})
+			s.OnConfigChanged(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: deployment.ConfigName},
+				Data:       map[string]string{deployment.QueueSidecarImageKey: "queue"},
+			})
 			return s.ToContext(ctx)
 		},
 		want: nil,
@@ -94,6 +94,20 @@ type TrafficTarget struct {
 	// +optional
 	Percent int `json:"percent"`
 
+	// Fallback marks the Revision of this target as the one the requests the
+	// other Revisions of the Route fail to serve are retried against, e.g.
+	// the last known good one.  It must name a RevisionName and receive no
+	// traffic of its own, and at most one target may be the fallback.
+	// +optional
+	Fallback bool `json:"fallback,omitempty"`
+
+	// Path is the URL path prefix, e.g. /api, whose requests this target
+	// serves instead of the targets without one, so that the paths of a
+	// domain are served by different Revisions.  The targets of each path
+	// split its traffic among themselves, their percents sum to 100.
+	// +optional
+	Path string `json:"path,omitempty"`
+
 	// URL displays the URL for accessing named traffic targets. URL is displayed in
 	// status, and is disallowed on spec. URL must contain a scheme (e.g. http://) and
 	// a hostname, but may not contain anything else (e.g. basic auth, url path, etc.)
@@ -19,6 +19,8 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
@@ -38,8 +40,10 @@ func validateTrafficList(ctx context.Context, traffic []TrafficTarget) *apis.Fie
 
 	// Track the targets of named TrafficTarget entries (to detect duplicates).
 	trafficMap := make(map[string]int)
+	fallback := -1
 
-	sum := 0
+	// The targets of each path split its traffic, keyed by path.
+	sums := make(map[string]int)
 	for i, tt := range traffic {
 		errs = errs.Also(tt.Validate(ctx).ViaIndex(i))
 
@@ -56,15 +60,47 @@ func validateTrafficList(ctx context.Context, traffic []TrafficTarget) *apis.Fie
 		} else {
 			trafficMap[tt.Tag] = i
 		}
-		sum += tt.Percent
+		if tt.Fallback && fallback >= 0 {
+			errs = errs.Also(&apis.FieldError{
+				Message: "Multiple fallback targets",
+				Paths: []string{
+					fmt.Sprintf("[%d].fallback", i),
+					fmt.Sprintf("[%d].fallback", fallback),
+				},
+			})
+		} else if tt.Fallback {
+			fallback = i
+		}
+		sums[tt.Path] += tt.Percent
 	}
 
-	if sum != 100 {
+	return errs.Also(ValidatePathSums(sums))
+}
+
+// ValidatePathSums checks that the percents of the traffic targets of each
+// path, keyed by path, sum to 100, including those without one.
+func ValidatePathSums(sums map[string]int) *apis.FieldError {
+	var errs *apis.FieldError
+	if sum := sums[""]; sum != 100 {
 		errs = errs.Also(&apis.FieldError{
 			Message: fmt.Sprintf("Traffic targets sum to %d, want 100", sum),
 			Paths:   []string{apis.CurrentField},
 		})
 	}
+	paths := make([]string, 0, len(sums))
+	for path := range sums {
+		paths = append(paths, path)
+	}
+	// Sort the paths to report the errors in a deterministic order.
+	sort.Strings(paths)
+	for _, path := range paths {
+		if sum := sums[path]; path != "" && sum != 100 {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("Traffic targets of path %q sum to %d, want 100", path, sum),
+				Paths:   []string{apis.CurrentField},
+			})
+		}
+	}
 	return errs
 }
 
@@ -78,6 +114,8 @@ func (tt *TrafficTarget) Validate(ctx context.Context) *apis.FieldError {
 	errs := tt.validateLatestRevision(ctx)
 	errs = tt.validateRevisionAndConfiguration(ctx, errs)
 	errs = tt.validateTrafficPercentage(errs)
+	errs = tt.validateFallback(ctx, errs)
+	errs = tt.validatePath(errs)
 	return tt.validateUrl(ctx, errs)
 }
 
@@ -134,6 +172,32 @@ func (tt *TrafficTarget) validateTrafficPercentage(errs *apis.FieldError) *apis.
 	return errs
 }
 
+func (tt *TrafficTarget) validateFallback(ctx context.Context, errs *apis.FieldError) *apis.FieldError {
+	if !tt.Fallback {
+		return errs
+	}
+	// The fallback only serves the requests the other targets failed.
+	if tt.Percent != 0 {
+		errs = errs.Also(&apis.FieldError{
+			Message: "Fallback targets must not receive traffic",
+			Paths:   []string{"percent"},
+		})
+	}
+	// The fallback doesn't float forward, it is known to be good.
+	if apis.IsInSpec(ctx) && tt.RevisionName == "" {
+		errs = errs.Also(apis.ErrMissingField("revisionName"))
+	}
+	return errs
+}
+
+func (tt *TrafficTarget) validatePath(errs *apis.FieldError) *apis.FieldError {
+	// The path is a prefix of the path of the requests, without their query.
+	if tt.Path != "" && (!strings.HasPrefix(tt.Path, "/") || strings.ContainsAny(tt.Path, "?#")) {
+		errs = errs.Also(apis.ErrInvalidValue(tt.Path, "path"))
+	}
+	return errs
+}
+
 func (tt *TrafficTarget) validateLatestRevision(ctx context.Context) *apis.FieldError {
 	if apis.IsInSpec(ctx) && tt.LatestRevision != nil {
 		lr := *tt.LatestRevision
@@ -72,6 +72,34 @@ func TestTrafficTargetValidation(t *testing.T) {
 		},
 		wc:   apis.WithinStatus,
 		want: apis.ErrMissingField("url"),
+	}, {
+		name: "valid fallback",
+		tt: &TrafficTarget{
+			RevisionName: "bar",
+			Fallback:     true,
+		},
+		wc:   apis.WithinSpec,
+		want: nil,
+	}, {
+		name: "invalid fallback with traffic",
+		tt: &TrafficTarget{
+			RevisionName: "bar",
+			Percent:      12,
+			Fallback:     true,
+		},
+		wc: apis.WithinSpec,
+		want: &apis.FieldError{
+			Message: "Fallback targets must not receive traffic",
+			Paths:   []string{"percent"},
+		},
+	}, {
+		name: "invalid fallback with configurationName",
+		tt: &TrafficTarget{
+			ConfigurationName: "bar",
+			Fallback:          true,
+		},
+		wc:   apis.WithinSpec,
+		want: apis.ErrMissingField("revisionName"),
 	}, {
 		name: "invalid with bad revisionName",
 		tt: &TrafficTarget{
@@ -405,6 +433,96 @@ func TestRouteValidation(t *testing.T) {
 				"spec.traffic[1].tag",
 			},
 		},
+	}, {
+		name: "invalid traffic entry (multiple fallbacks)",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}, {
+					Tag:          "bar",
+					RevisionName: "bar",
+					Fallback:     true,
+				}, {
+					Tag:          "baz",
+					RevisionName: "baz",
+					Fallback:     true,
+				}},
+			},
+		},
+		want: &apis.FieldError{
+			Message: "Multiple fallback targets",
+			Paths: []string{
+				"spec.traffic[1].fallback",
+				"spec.traffic[2].fallback",
+			},
+		},
+	}, {
+		name: "valid traffic entry (paths)",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}, {
+					Tag:          "bar",
+					RevisionName: "bar",
+					Percent:      100,
+					Path:         "/api",
+				}},
+			},
+		},
+	}, {
+		name: "invalid traffic entry (path percents)",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      50,
+				}, {
+					Tag:          "bar",
+					RevisionName: "bar",
+					Percent:      50,
+					Path:         "/api",
+				}},
+			},
+		},
+		want: (&apis.FieldError{
+			Message: "Traffic targets sum to 50, want 100",
+			Paths:   []string{"spec.traffic"},
+		}).Also(&apis.FieldError{
+			Message: `Traffic targets of path "/api" sum to 50, want 100`,
+			Paths:   []string{"spec.traffic"},
+		}),
+	}, {
+		name: "invalid traffic entry (path)",
+		r: &Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "valid",
+			},
+			Spec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					RevisionName: "foo",
+					Percent:      100,
+				}, {
+					Tag:          "bar",
+					RevisionName: "bar",
+					Percent:      100,
+					Path:         "api?v=1",
+				}},
+			},
+		},
+		want: apis.ErrInvalidValue("api?v=1", "spec.traffic[1].path"),
 	}, {
 		name: "invalid name - dots",
 		r: &Route{
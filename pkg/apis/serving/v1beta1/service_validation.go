@@ -21,6 +21,7 @@ import (
 
 	"knative.dev/pkg/apis"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/quota"
 )
 
 // Validate makes sure that Service is properly configured.
@@ -35,16 +36,28 @@ func (s *Service) Validate(ctx context.Context) (errs *apis.FieldError) {
 		errs = errs.Also(s.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 	}
 
+	if !apis.IsInUpdate(ctx) && errs == nil {
+		// Only valid Services are worth counting.
+		errs = quota.ValidateService(ctx, s.Namespace)
+	}
+
 	errs = errs.Also(s.Status.Validate(apis.WithinStatus(ctx)).ViaField("status"))
 
+	var baseline *RevisionTemplateSpec
 	if apis.IsInUpdate(ctx) {
 		original := apis.GetBaseline(ctx).(*Service)
+		baseline = &original.Spec.ConfigurationSpec.Template
 
 		err := s.Spec.ConfigurationSpec.Template.VerifyNameChange(ctx,
 			original.Spec.ConfigurationSpec.Template)
 		errs = errs.Also(err.ViaField("spec.template"))
 	}
 
+	if errs == nil {
+		// Only valid templates are worth checking the references of.
+		errs = validateReferences(ctx, s.Namespace, &s.Spec.ConfigurationSpec.Template, baseline).ViaField("spec.template")
+	}
+
 	return errs
 }
 
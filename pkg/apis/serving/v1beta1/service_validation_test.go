@@ -28,6 +28,7 @@ import (
 	"knative.dev/pkg/ptr"
 
 	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/refcheck"
 )
 
 func TestServiceValidation(t *testing.T) {
@@ -603,3 +604,57 @@ func TestServiceSubresourceUpdate(t *testing.T) {
 		})
 	}
 }
+
+// noneExist is a refcheck.Checker for which no resource exists.
+type noneExist struct{}
+
+func (noneExist) Exists(string, string, string) (bool, error) { return false, nil }
+
+func TestServiceReferences(t *testing.T) {
+	s := &Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "valid",
+			Namespace: "default",
+		},
+		Spec: ServiceSpec{
+			ConfigurationSpec: ConfigurationSpec{
+				Template: RevisionTemplateSpec{
+					Spec: RevisionSpec{
+						PodSpec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Image: "busybox",
+								EnvFrom: []corev1.EnvFromSource{{
+									ConfigMapRef: &corev1.ConfigMapEnvSource{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+									},
+								}},
+							}},
+						},
+					},
+				},
+			},
+			RouteSpec: RouteSpec{
+				Traffic: []TrafficTarget{{
+					LatestRevision: ptr.Bool(true),
+					Percent:        100,
+				}},
+			},
+		},
+	}
+	cfg := config.FromContextOrDefaults(context.Background())
+	cfg.Features.ReferenceValidation = config.Enabled
+	ctx := refcheck.WithChecker(config.ToContext(context.Background(), cfg), noneExist{})
+
+	want := &apis.FieldError{
+		Message: `ConfigMap "env" does not exist in namespace "default"`,
+		Paths:   []string{"spec.template.spec.containers[0].envFrom[0].configMapRef.name"},
+	}
+	if got := s.Validate(ctx); got.Error() != want.Error() {
+		t.Errorf("Validate() = %v, want: %v", got, want)
+	}
+
+	// Updates leaving the template alone aren't checked again.
+	if got := s.Validate(apis.WithinUpdate(ctx, s.DeepCopy())); got != nil {
+		t.Errorf("Validate() = %v, wanted no error", got)
+	}
+}
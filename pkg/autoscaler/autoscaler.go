@@ -47,6 +47,12 @@ type Autoscaler struct {
 	panicTime    *time.Time
 	maxPanicPods int32
 
+	// The activity of the revision, which is reported on every Scale call.
+	// Also guarded by the stateMux.
+	watchedSince   time.Time
+	readyPods      int
+	scaledFromZero time.Time
+
 	// specMux guards the current DeciderSpec.
 	specMux     sync.RWMutex
 	deciderSpec DeciderSpec
@@ -96,6 +102,7 @@ func New(
 
 		panicTime:    pt,
 		maxPanicPods: int32(curC),
+		readyPods:    curC,
 	}, nil
 }
 
@@ -125,6 +132,9 @@ func (a *Autoscaler) Scale(ctx context.Context, now time.Time) (desiredPodCount
 	readyPodsCount := math.Max(1, float64(originalReadyPodsCount))
 
 	metricKey := NewMetricKey(a.namespace, a.revision)
+	// The activity is worth reporting for the idle revisions too, which
+	// lack the data to scale on.
+	a.reportActivity(now, metricKey, originalReadyPodsCount)
 	observedStableConcurrency, observedPanicConcurrency, err := a.metricClient.StableAndPanicConcurrency(metricKey)
 	if err != nil {
 		if err == ErrNoData {
@@ -208,3 +218,48 @@ func (a *Autoscaler) currentSpec() DeciderSpec {
 	defer a.specMux.RUnlock()
 	return a.deciderSpec
 }
+
+// reportActivity reports the time since the revision was last sent a request,
+// and since it last scaled from zero, when known.
+func (a *Autoscaler) reportActivity(now time.Time, metricKey string, readyPods int) {
+	a.stateMux.Lock()
+	if a.watchedSince.IsZero() {
+		a.watchedSince = now
+	}
+	if a.readyPods == 0 && readyPods > 0 {
+		a.scaledFromZero = now
+	}
+	a.readyPods = readyPods
+	scaledFromZero := a.scaledFromZero
+	a.stateMux.Unlock()
+
+	if lastRequest, err := a.metricClient.LastRequest(metricKey); err == nil && !lastRequest.IsZero() {
+		a.reporter.ReportTimeSinceLastRequest(now.Sub(lastRequest).Seconds())
+	}
+	if !scaledFromZero.IsZero() {
+		a.reporter.ReportTimeSinceScaleFromZero(now.Sub(scaledFromZero).Seconds())
+	}
+}
+
+// IdleSince returns the time the revision was last sent a request at, once it
+// went without requests for a stable window, and the zero time otherwise.
+// Without any request since the autoscaler started watching the revision, the
+// revision is deemed idle since then.
+func (a *Autoscaler) IdleSince(now time.Time) time.Time {
+	lastRequest, err := a.metricClient.LastRequest(NewMetricKey(a.namespace, a.revision))
+	if err != nil {
+		return time.Time{}
+	}
+	if lastRequest.IsZero() {
+		a.stateMux.Lock()
+		lastRequest = a.watchedSince
+		a.stateMux.Unlock()
+		if lastRequest.IsZero() {
+			return time.Time{}
+		}
+	}
+	if now.Sub(lastRequest) < a.currentSpec().StableWindow {
+		return time.Time{}
+	}
+	return lastRequest
+}
@@ -239,7 +239,10 @@ func TestAutoscalerUpdateTarget(t *testing.T) {
 	a.expectScale(t, time.Now(), 100, expectedEBC(1, 71, 100, 10), true)
 }
 
-type mockReporter struct{}
+type mockReporter struct {
+	timeSinceLastRequest   float64
+	timeSinceScaleFromZero float64
+}
 
 // ReportDesiredPodCount of a mockReporter does nothing and return nil for error.
 func (r *mockReporter) ReportDesiredPodCount(v int64) error {
@@ -281,6 +284,18 @@ func (r *mockReporter) ReportExcessBurstCapacity(v float64) error {
 	return nil
 }
 
+// ReportTimeSinceLastRequest of a mockReporter records the value.
+func (r *mockReporter) ReportTimeSinceLastRequest(v float64) error {
+	r.timeSinceLastRequest = v
+	return nil
+}
+
+// ReportTimeSinceScaleFromZero of a mockReporter records the value.
+func (r *mockReporter) ReportTimeSinceScaleFromZero(v float64) error {
+	r.timeSinceScaleFromZero = v
+	return nil
+}
+
 func newTestAutoscaler(t *testing.T, targetConcurrency, targetBurstCapacity float64, metrics MetricClient) *Autoscaler {
 	t.Helper()
 	deciderSpec := DeciderSpec{
@@ -318,9 +333,57 @@ func (a *Autoscaler) expectScale(t *testing.T, now time.Time, expectScale, expec
 	}
 }
 
+func TestAutoscalerActivity(t *testing.T) {
+	metrics := &testMetricClient{err: ErrNoData}
+	a := newTestAutoscaler(t, 10, 77, metrics)
+	reporter := a.reporter.(*mockReporter)
+
+	// Without any request seen, the revision is idle since it was first
+	// watched, once that was a stable window ago.
+	now := time.Now()
+	a.expectScale(t, now, 0, 0, false)
+	if got := a.IdleSince(now); !got.IsZero() {
+		t.Errorf("IdleSince() = %v, want the zero time", got)
+	}
+	if got := a.IdleSince(now.Add(stableWindow)); !got.Equal(now) {
+		t.Errorf("IdleSince() = %v, want %v", got, now)
+	}
+	// The pod got ready since the autoscaler was created without any.
+	if got, want := reporter.timeSinceScaleFromZero, 0.0; got != want {
+		t.Errorf("Time since scale from zero = %v, want %v", got, want)
+	}
+
+	metrics.lastRequest = now.Add(time.Second)
+	later := now.Add(time.Minute)
+	a.expectScale(t, later, 0, 0, false)
+	if got, want := reporter.timeSinceLastRequest, 59.0; got != want {
+		t.Errorf("Time since last request = %v, want %v", got, want)
+	}
+	if got, want := reporter.timeSinceScaleFromZero, 60.0; got != want {
+		t.Errorf("Time since scale from zero = %v, want %v", got, want)
+	}
+	if got := a.IdleSince(later); !got.IsZero() {
+		t.Errorf("IdleSince() = %v, want the zero time", got)
+	}
+	if got, want := a.IdleSince(later.Add(stableWindow)), metrics.lastRequest; !got.Equal(want) {
+		t.Errorf("IdleSince() = %v, want %v", got, want)
+	}
+
+	// Scaling to zero and back from it.
+	endpoints(0)
+	a.expectScale(t, later, 0, 0, false)
+	endpoints(1)
+	a.expectScale(t, later.Add(time.Minute), 0, 0, false)
+	a.expectScale(t, later.Add(2*time.Minute), 0, 0, false)
+	if got, want := reporter.timeSinceScaleFromZero, 60.0; got != want {
+		t.Errorf("Time since scale from zero = %v, want %v", got, want)
+	}
+}
+
 type testMetricClient struct {
 	stableConcurrency float64
 	panicConcurrency  float64
+	lastRequest       time.Time
 	err               error
 }
 
@@ -328,6 +391,10 @@ func (t *testMetricClient) StableAndPanicConcurrency(key string) (float64, float
 	return t.stableConcurrency, t.panicConcurrency, t.err
 }
 
+func (t *testMetricClient) LastRequest(key string) (time.Time, error) {
+	return t.lastRequest, nil
+}
+
 func endpoints(count int) {
 	epAddresses := make([]corev1.EndpointAddress, count)
 	for i := 0; i < count; i++ {
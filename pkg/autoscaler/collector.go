@@ -79,6 +79,10 @@ type StatMessage struct {
 type MetricClient interface {
 	// StableAndPanicConcurrency returns both the stable and the panic concurrency.
 	StableAndPanicConcurrency(key string) (float64, float64, error)
+
+	// LastRequest returns the time the last request was seen at, or the zero
+	// time if there was none since the collection started.
+	LastRequest(key string) (time.Time, error)
 }
 
 // MetricCollector manages collection of metrics for many entities.
@@ -195,6 +199,19 @@ func (c *MetricCollector) StableAndPanicConcurrency(key string) (float64, float6
 	return collection.stableAndPanicConcurrency(time.Now())
 }
 
+// LastRequest returns the time the last request was seen at, or the zero
+// time if there was none since the collection started.
+func (c *MetricCollector) LastRequest(key string) (time.Time, error) {
+	c.collectionsMutex.RLock()
+	defer c.collectionsMutex.RUnlock()
+
+	collection, exists := c.collections[key]
+	if !exists {
+		return time.Time{}, k8serrors.NewNotFound(av1alpha1.Resource("Metrics"), key)
+	}
+	return collection.getLastRequest(), nil
+}
+
 // collection represents the collection of metrics for one specific entity.
 type collection struct {
 	metricMutex sync.RWMutex
@@ -204,6 +221,9 @@ type collection struct {
 	scraper      StatsScraper
 	buckets      *aggregation.TimedFloat64Buckets
 
+	lastRequestMutex sync.RWMutex
+	lastRequest      time.Time
+
 	grp    sync.WaitGroup
 	stopCh chan struct{}
 }
@@ -277,6 +297,21 @@ func (c *collection) record(stat Stat) {
 	// Proxied requests have been counted at the activator. Subtract
 	// AverageProxiedConcurrentRequests to avoid double counting.
 	c.buckets.Record(*stat.Time, stat.PodName, stat.AverageConcurrentRequests-stat.AverageProxiedConcurrentRequests)
+
+	if stat.RequestCount > 0 || stat.AverageConcurrentRequests > 0 {
+		c.lastRequestMutex.Lock()
+		defer c.lastRequestMutex.Unlock()
+		if stat.Time.After(c.lastRequest) {
+			c.lastRequest = *stat.Time
+		}
+	}
+}
+
+// getLastRequest returns the time of the last stat with requests.
+func (c *collection) getLastRequest() time.Time {
+	c.lastRequestMutex.RLock()
+	defer c.lastRequestMutex.RUnlock()
+	return c.lastRequest
 }
 
 // stableAndPanicConcurrency calculates both stable and panic concurrency based on the
@@ -209,6 +209,46 @@ func TestMetricCollectorRecord(t *testing.T) {
 	}
 }
 
+func TestMetricCollectorLastRequest(t *testing.T) {
+	defer ClearAll()
+
+	logger := TestLogger(t)
+	ctx := context.Background()
+
+	metricKey := NewMetricKey(defaultNamespace, defaultName)
+	scraper := &testScraper{
+		s: func() (*StatMessage, error) {
+			return nil, nil
+		},
+	}
+	coll := NewMetricCollector(scraperFactory(scraper, nil), logger)
+
+	if _, err := coll.LastRequest(metricKey); err == nil {
+		t.Error("LastRequest() = nil, wanted an error")
+	}
+
+	coll.Create(ctx, defaultMetric)
+	if got, err := coll.LastRequest(metricKey); err != nil || !got.IsZero() {
+		t.Errorf("LastRequest() = %v, %v; want the zero time", got, err)
+	}
+
+	then := time.Now()
+	later := then.Add(time.Second)
+	coll.Record(metricKey, Stat{Time: &then, PodName: "testPod", RequestCount: 1})
+	// Stats without requests don't count.
+	coll.Record(metricKey, Stat{Time: &later, PodName: "testPod"})
+	if got, err := coll.LastRequest(metricKey); err != nil || !got.Equal(then) {
+		t.Errorf("LastRequest() = %v, %v; want %v", got, err, then)
+	}
+
+	coll.Record(metricKey, Stat{Time: &later, PodName: "activator", AverageConcurrentRequests: 1})
+	// Stats arriving out of order don't move it back.
+	coll.Record(metricKey, Stat{Time: &then, PodName: "testPod", RequestCount: 1})
+	if got, err := coll.LastRequest(metricKey); err != nil || !got.Equal(later) {
+		t.Errorf("LastRequest() = %v, %v; want %v", got, err, later)
+	}
+}
+
 func scraperFactory(scraper StatsScraper, err error) StatsScraperFactory {
 	return func(*av1alpha1.Metric) (StatsScraper, error) {
 		return scraper, err
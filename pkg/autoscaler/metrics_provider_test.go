@@ -20,6 +20,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/kubernetes-incubator/custom-metrics-apiserver/pkg/provider"
 	"knative.dev/pkg/kmp"
@@ -117,3 +118,7 @@ func (s staticConcurrency) StableAndPanicConcurrency(key string) (float64, float
 	}
 	return 0.0, 0.0, errors.New("doesn't exist")
 }
+
+func (s staticConcurrency) LastRequest(key string) (time.Time, error) {
+	return time.Time{}, nil
+}
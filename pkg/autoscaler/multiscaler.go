@@ -70,6 +70,11 @@ type DeciderStatus struct {
 	// If this number is negative: Activator will be threaded in
 	// the request path by the PodAutoscaler controller.
 	ExcessBurstCapacity int32
+
+	// IdleSince is the time the revision was last sent a request at, once
+	// it went without requests for a stable window. It is the zero time
+	// while the revision is active.
+	IdleSince metav1.Time
 }
 
 // UniScaler records statistics for a particular Decider and proposes the scale for the Decider's target based on those statistics.
@@ -81,6 +86,10 @@ type UniScaler interface {
 
 	// Update reconfigures the UniScaler according to the DeciderSpec.
 	Update(DeciderSpec) error
+
+	// IdleSince returns the time the target was last sent a request at, if
+	// it is idle at the given time, and the zero time otherwise.
+	IdleSince(time.Time) time.Time
 }
 
 // UniScalerFactory creates a UniScaler for a given PA using the given dynamic configuration.
@@ -124,6 +133,18 @@ func (sr *scalerRunner) updateLatestScale(proposed, ebc int32) bool {
 	return ret
 }
 
+// updateIdleSince records the time the revision is idle since, and returns
+// whether it changed.
+func (sr *scalerRunner) updateIdleSince(idleSince time.Time) bool {
+	sr.mux.Lock()
+	defer sr.mux.Unlock()
+	if sr.decider.Status.IdleSince.Time.Equal(idleSince) {
+		return false
+	}
+	sr.decider.Status.IdleSince = metav1.Time{Time: idleSince}
+	return true
+}
+
 // NewMetricKey identifies a UniScaler in the multiscaler. Stats send in
 // are identified and routed via this key.
 func NewMetricKey(namespace string, name string) string {
@@ -291,19 +312,20 @@ func (m *MultiScaler) createScaler(ctx context.Context, decider *Decider) (*scal
 
 func (m *MultiScaler) tickScaler(ctx context.Context, scaler UniScaler, runner *scalerRunner, metricKey string) {
 	logger := logging.FromContext(ctx)
-	desiredScale, excessBC, scaled := scaler.Scale(ctx, time.Now())
-
-	if !scaled {
-		return
-	}
-
-	// Cannot scale negative (nor we can compute burst capacity).
-	if desiredScale < 0 {
+	now := time.Now()
+	desiredScale, excessBC, scaled := scaler.Scale(ctx, now)
+
+	changed := runner.updateIdleSince(scaler.IdleSince(now))
+	switch {
+	case !scaled:
+	case desiredScale < 0:
+		// Cannot scale negative (nor we can compute burst capacity).
 		logger.Errorf("Cannot scale: desiredScale %d < 0.", desiredScale)
-		return
+	case runner.updateLatestScale(desiredScale, excessBC):
+		changed = true
 	}
 
-	if runner.updateLatestScale(desiredScale, excessBC) {
+	if changed {
 		m.Inform(metricKey)
 	}
 }
@@ -331,6 +331,47 @@ func TestMultiScalerIgnoreNegativeScale(t *testing.T) {
 	}
 }
 
+func TestMultiScalerIdleSince(t *testing.T) {
+	ctx := context.Background()
+	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
+	defer close(stopCh)
+	defer close(statCh)
+
+	decider := newDecider()
+	// Without data to scale on, only the change of idleness is informed.
+	uniScaler.setScaleResult(0, 0, false)
+	idleSince := time.Now().Add(-time.Hour)
+	uniScaler.setIdleSince(idleSince)
+
+	errCh := make(chan error)
+	ms.Watch(func(key string) {
+		m, err := ms.Get(ctx, decider.Namespace, decider.Name)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- nil
+		if !m.Status.IdleSince.Time.Equal(idleSince) {
+			t.Errorf("IdleSince = %v, want %v", m.Status.IdleSince, idleSince)
+		}
+	})
+
+	if _, err := ms.Create(ctx, decider); err != nil {
+		t.Fatalf("Create() = %v", err)
+	}
+	if err := verifyTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+	// The idleness didn't change since.
+	if err := verifyNoTick(errCh); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ms.Delete(ctx, decider.Namespace, decider.Name); err != nil {
+		t.Errorf("Delete() = %v", err)
+	}
+}
+
 func TestMultiScalerUpdate(t *testing.T) {
 	ctx := context.Background()
 	ms, stopCh, statCh, uniScaler := createMultiScaler(t)
@@ -385,6 +426,7 @@ type fakeUniScaler struct {
 	surplus    int32
 	scaled     bool
 	scaleCount int
+	idleSince  time.Time
 }
 
 func (u *fakeUniScaler) fakeUniScalerFactory(*Decider) (UniScaler, error) {
@@ -398,6 +440,18 @@ func (u *fakeUniScaler) Scale(context.Context, time.Time) (int32, int32, bool) {
 	return u.replicas, u.surplus, u.scaled
 }
 
+func (u *fakeUniScaler) IdleSince(time.Time) time.Time {
+	u.mutex.RLock()
+	defer u.mutex.RUnlock()
+	return u.idleSince
+}
+
+func (u *fakeUniScaler) setIdleSince(idleSince time.Time) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.idleSince = idleSince
+}
+
 func (u *fakeUniScaler) getScaleCount() int {
 	u.mutex.RLock()
 	defer u.mutex.RUnlock()
@@ -61,6 +61,14 @@ var (
 		"panic_mode",
 		"1 if autoscaler is in panic mode, 0 otherwise",
 		stats.UnitDimensionless)
+	timeSinceLastRequestM = stats.Float64(
+		"time_since_last_request",
+		"Seconds since the revision last received a request",
+		"s")
+	timeSinceScaleFromZeroM = stats.Float64(
+		"time_since_scale_from_zero",
+		"Seconds since the revision last scaled from zero",
+		"s")
 	namespaceTagKey tag.Key
 	configTagKey    tag.Key
 	revisionTagKey  tag.Key
@@ -147,6 +155,18 @@ func register() {
 			Aggregation: view.LastValue(),
 			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
 		},
+		&view.View{
+			Description: "Seconds since the revision last received a request",
+			Measure:     timeSinceLastRequestM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
+		&view.View{
+			Description: "Seconds since the revision last scaled from zero",
+			Measure:     timeSinceScaleFromZeroM,
+			Aggregation: view.LastValue(),
+			TagKeys:     []tag.Key{namespaceTagKey, serviceTagKey, configTagKey, revisionTagKey},
+		},
 	)
 	if err != nil {
 		panic(err)
@@ -163,6 +183,8 @@ type StatsReporter interface {
 	ReportTargetRequestConcurrency(v float64) error
 	ReportExcessBurstCapacity(v float64) error
 	ReportPanic(v int64) error
+	ReportTimeSinceLastRequest(v float64) error
+	ReportTimeSinceScaleFromZero(v float64) error
 }
 
 // Reporter holds cached metric objects to report autoscaler metrics
@@ -240,6 +262,16 @@ func (r *Reporter) ReportPanic(v int64) error {
 	return r.report(panicM.M(v))
 }
 
+// ReportTimeSinceLastRequest captures value v, in seconds, for the time since the last request measure.
+func (r *Reporter) ReportTimeSinceLastRequest(v float64) error {
+	return r.report(timeSinceLastRequestM.M(v))
+}
+
+// ReportTimeSinceScaleFromZero captures value v, in seconds, for the time since scale from zero measure.
+func (r *Reporter) ReportTimeSinceScaleFromZero(v float64) error {
+	return r.report(timeSinceScaleFromZeroM.M(v))
+}
+
 func (r *Reporter) report(m stats.Measurement) error {
 	if !r.initialized {
 		return errors.New("StatsReporter is not initialized yet")
@@ -63,6 +63,8 @@ func TestReporterReport(t *testing.T) {
 	expectSuccess(t, "ReportPanicRequestConcurrency", func() error { return r.ReportPanicRequestConcurrency(3) })
 	expectSuccess(t, "ReportTargetRequestConcurrency", func() error { return r.ReportTargetRequestConcurrency(0.9) })
 	expectSuccess(t, "ReportExcessBurstCapacity", func() error { return r.ReportExcessBurstCapacity(19.84) })
+	expectSuccess(t, "ReportTimeSinceLastRequest", func() error { return r.ReportTimeSinceLastRequest(42) })
+	expectSuccess(t, "ReportTimeSinceScaleFromZero", func() error { return r.ReportTimeSinceScaleFromZero(3600) })
 	metricstest.CheckLastValueData(t, "desired_pods", wantTags, 10)
 	metricstest.CheckLastValueData(t, "requested_pods", wantTags, 7)
 	metricstest.CheckLastValueData(t, "actual_pods", wantTags, 5)
@@ -71,6 +73,8 @@ func TestReporterReport(t *testing.T) {
 	metricstest.CheckLastValueData(t, "excess_burst_capacity", wantTags, 19.84)
 	metricstest.CheckLastValueData(t, "panic_request_concurrency", wantTags, 3)
 	metricstest.CheckLastValueData(t, "target_concurrency_per_pod", wantTags, 0.9)
+	metricstest.CheckLastValueData(t, "time_since_last_request", wantTags, 42)
+	metricstest.CheckLastValueData(t, "time_since_scale_from_zero", wantTags, 3600)
 
 	// All the stats are gauges - record multiple entries for one stat - last one should stick
 	expectSuccess(t, "ReportDesiredPodCount", func() error { return r.ReportDesiredPodCount(1) })
@@ -129,6 +133,8 @@ func resetMetrics() {
 		panicRequestConcurrencyM.Name(),
 		excessBurstCapacityM.Name(),
 		targetRequestConcurrencyM.Name(),
-		panicM.Name())
+		panicM.Name(),
+		timeSinceLastRequestM.Name(),
+		timeSinceScaleFromZeroM.Name())
 	register()
 }
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configvalidator
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ReadConfigMaps reads the ConfigMaps out of a stream of YAML or JSON
+// documents. The documents holding other kinds of objects are skipped, so
+// that whole release manifests can be read.
+func ReadConfigMaps(r io.Reader) ([]*corev1.ConfigMap, error) {
+	var cms []*corev1.ConfigMap
+	dec := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var cm corev1.ConfigMap
+		if err := dec.Decode(&cm); err == io.EOF {
+			return cms, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if cm.Kind == "ConfigMap" {
+			cms = append(cms, &cm)
+		}
+	}
+}
+
+// ReadFiles reads the ConfigMaps out of the given files, and out of the
+// YAML and JSON files of the given directories.
+func ReadFiles(paths []string) ([]*corev1.ConfigMap, error) {
+	var cms []*corev1.ConfigMap
+	for _, path := range paths {
+		files, err := manifests(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			f, err := os.Open(file)
+			if err != nil {
+				return nil, err
+			}
+			read, err := ReadConfigMaps(f)
+			f.Close()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read %s", file)
+			}
+			cms = append(cms, read...)
+		}
+	}
+	return cms, nil
+}
+
+// manifests returns path if it's a file, or its YAML and JSON files if it's
+// a directory.
+func manifests(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	infos, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, info := range infos {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				files = append(files, filepath.Join(path, info.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// ReadCluster reads the ConfigMaps of Knative Serving out of the given
+// namespace of the cluster.
+func ReadCluster(client kubernetes.Interface, namespace string) ([]*corev1.ConfigMap, error) {
+	list, err := client.CoreV1().ConfigMaps(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var cms []*corev1.ConfigMap
+	for i := range list.Items {
+		// The namespace holds other ConfigMaps too, e.g. for leader election.
+		if _, ok := parsers[list.Items[i].Name]; ok {
+			cms = append(cms, &list.Items[i])
+		}
+	}
+	return cms, nil
+}
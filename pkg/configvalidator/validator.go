@@ -0,0 +1,273 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configvalidator checks the ConfigMaps of Knative Serving the way
+// its components load them, and against each other, so that operators can
+// catch mistakes before rolling them out.
+package configvalidator
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/controller"
+	pkglogging "knative.dev/pkg/logging"
+	pkgmetrics "knative.dev/pkg/metrics"
+
+	activatorconfig "knative.dev/serving/pkg/activator/config"
+	"knative.dev/serving/pkg/admission"
+	apiconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/deployment"
+	"knative.dev/serving/pkg/gc"
+	"knative.dev/serving/pkg/logging"
+	"knative.dev/serving/pkg/metrics"
+	"knative.dev/serving/pkg/network"
+	certconfig "knative.dev/serving/pkg/reconciler/certificate/config"
+	ingressconfig "knative.dev/serving/pkg/reconciler/ingress/config"
+	routeconfig "knative.dev/serving/pkg/reconciler/route/config"
+	suspensionconfig "knative.dev/serving/pkg/reconciler/suspension/config"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+// Severity tells whether a Finding breaks the configuration.
+type Severity string
+
+const (
+	// Error is a configuration the components reject, or silently fix up.
+	Error Severity = "error"
+	// Warning is a configuration the components accept, but that is
+	// likely not what was meant.
+	Warning Severity = "warning"
+)
+
+// Finding is a problem with the configuration.
+type Finding struct {
+	Severity Severity
+	// ConfigMap is the name of the ConfigMap to fix.
+	ConfigMap string
+	Message   string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s", f.ConfigMap, f.Severity, f.Message)
+}
+
+// parser loads a ConfigMap into the configuration of the components.
+type parser func(*corev1.ConfigMap) (interface{}, error)
+
+// parsers are the parsers the components use, by name of ConfigMap.
+var parsers = map[string]parser{
+	admission.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return admission.NewConfigFromConfigMap(cm)
+	},
+	apiconfig.DefaultsConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return apiconfig.NewDefaultsConfigFromConfigMap(cm)
+	},
+	apiconfig.FeaturesConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return apiconfig.NewFeaturesConfigFromConfigMap(cm)
+	},
+	apiconfig.ImagePolicyConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return apiconfig.NewImagePolicyConfigFromConfigMap(cm)
+	},
+	apiconfig.QuotaConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return apiconfig.NewQuotaConfigFromConfigMap(cm)
+	},
+	apiconfig.ProfilesConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return apiconfig.NewProfilesConfigFromConfigMap(cm)
+	},
+	autoscaler.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return autoscaler.NewConfigFromConfigMap(cm)
+	},
+	certconfig.CertManagerConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return certconfig.NewCertManagerConfigFromConfigMap(cm)
+	},
+	deployment.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return deployment.NewConfigFromConfigMap(cm)
+	},
+	gc.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		// The minimum timeout is checked by checkStaleRevisionTimeout,
+		// rather than fixed up.
+		return gc.NewConfigFromConfigMapFunc(zap.NewNop().Sugar(), math.MinInt64)(cm)
+	},
+	ingressconfig.IstioConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return ingressconfig.NewIstioFromConfigMap(cm)
+	},
+	pkglogging.ConfigMapName(): func(cm *corev1.ConfigMap) (interface{}, error) {
+		return logging.NewConfigFromConfigMap(cm)
+	},
+	network.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return network.NewConfigFromConfigMap(cm)
+	},
+	pkgmetrics.ConfigMapName(): func(cm *corev1.ConfigMap) (interface{}, error) {
+		return metrics.NewObservabilityConfigFromConfigMap(cm)
+	},
+	routeconfig.DomainConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return routeconfig.NewDomainFromConfigMap(cm)
+	},
+	activatorconfig.MaintenanceConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return activatorconfig.NewMaintenanceFromConfigMap(cm)
+	},
+	suspensionconfig.SuspensionConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return suspensionconfig.NewSuspensionFromConfigMap(cm)
+	},
+	tracingconfig.ConfigName: func(cm *corev1.ConfigMap) (interface{}, error) {
+		return tracingconfig.NewTracingConfigFromConfigMap(cm)
+	},
+}
+
+// configs holds the parsed ConfigMaps, by name. Those that weren't given
+// hold their defaults, when they have any.
+type configs struct {
+	parsed map[string]interface{}
+	given  map[string]bool
+}
+
+// check is a sanity check spanning several keys or ConfigMaps.
+type check func(configs) []Finding
+
+var checks = []check{
+	checkScaleToZeroGracePeriod,
+	checkStaleRevisionTimeout,
+	checkAutoTLS,
+}
+
+// Validate parses the ConfigMaps with the parsers of the components, then
+// checks them against each other. The ConfigMaps not given are taken to
+// have their defaults. The findings are sorted by ConfigMap.
+func Validate(cms []*corev1.ConfigMap) []Finding {
+	var findings []Finding
+	c := configs{
+		parsed: make(map[string]interface{}, len(parsers)),
+		given:  make(map[string]bool, len(cms)),
+	}
+	for _, cm := range cms {
+		parse, ok := parsers[cm.Name]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity:  Warning,
+				ConfigMap: cm.Name,
+				Message:   "not a ConfigMap of Knative Serving, it is ignored",
+			})
+			continue
+		}
+		if c.given[cm.Name] {
+			findings = append(findings, Finding{
+				Severity:  Error,
+				ConfigMap: cm.Name,
+				Message:   "given more than once",
+			})
+			continue
+		}
+		c.given[cm.Name] = true
+		cfg, err := parse(cm)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity:  Error,
+				ConfigMap: cm.Name,
+				Message:   err.Error(),
+			})
+			continue
+		}
+		c.parsed[cm.Name] = cfg
+	}
+	for name, parse := range parsers {
+		if c.given[name] {
+			continue
+		}
+		if cfg, err := parse(&corev1.ConfigMap{}); err == nil {
+			c.parsed[name] = cfg
+		}
+	}
+
+	for _, check := range checks {
+		findings = append(findings, check(c)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].ConfigMap < findings[j].ConfigMap
+	})
+	return findings
+}
+
+// checkScaleToZeroGracePeriod warns when revisions idle for longer after
+// being marked inactive than it took to mark them so.
+func checkScaleToZeroGracePeriod(c configs) []Finding {
+	as, ok := c.parsed[autoscaler.ConfigName].(*autoscaler.Config)
+	if !ok || !as.EnableScaleToZero || as.ScaleToZeroGracePeriod <= as.StableWindow {
+		return nil
+	}
+	return []Finding{{
+		Severity:  Warning,
+		ConfigMap: autoscaler.ConfigName,
+		Message: fmt.Sprintf("scale-to-zero-grace-period (%v) is longer than stable-window (%v): "+
+			"the grace period only needs to cover putting the activator in the request path, "+
+			"idle revisions keep their last pod for %v in total",
+			as.ScaleToZeroGracePeriod, as.StableWindow, as.StableWindow+as.ScaleToZeroGracePeriod),
+	}}
+}
+
+// checkStaleRevisionTimeout reports the timeouts the controllers raise to
+// their resync period, as they would otherwise collect revisions still in
+// use between two resyncs.
+func checkStaleRevisionTimeout(c configs) []Finding {
+	cfg, ok := c.parsed[gc.ConfigName].(*gc.Config)
+	if !ok {
+		return nil
+	}
+	min := controller.DefaultResyncPeriod + cfg.StaleRevisionLastpinnedDebounce
+	if cfg.StaleRevisionTimeout >= min {
+		return nil
+	}
+	return []Finding{{
+		Severity:  Error,
+		ConfigMap: gc.ConfigName,
+		Message: fmt.Sprintf("stale-revision-timeout (%v) must be at least the resync period of the controller (%v) "+
+			"plus stale-revision-lastpinned-debounce (%v), it is raised to %v",
+			cfg.StaleRevisionTimeout, controller.DefaultResyncPeriod, cfg.StaleRevisionLastpinnedDebounce, min),
+	}}
+}
+
+// checkAutoTLS reports auto-TLS enabled without an issuer to get the
+// certificates from, when cert-manager is configured.
+func checkAutoTLS(c configs) []Finding {
+	nc, ok := c.parsed[network.ConfigName].(*network.Config)
+	if !ok || !nc.AutoTLS || !c.given[certconfig.CertManagerConfigName] {
+		return nil
+	}
+	cm, ok := c.parsed[certconfig.CertManagerConfigName].(*certconfig.CertManagerConfig)
+	if !ok || cm.IssuerRef == nil || cm.IssuerRef.Name != "" {
+		return nil
+	}
+	return []Finding{{
+		Severity:  Error,
+		ConfigMap: certconfig.CertManagerConfigName,
+		Message:   fmt.Sprintf("%s is enabled in %s, but issuerRef names no issuer to get the certificates from", network.AutoTLSKey, network.ConfigName),
+	}}
+}
+
+// Failed returns whether any of the findings is an error, or a warning
+// when strict.
+func Failed(findings []Finding, strict bool) bool {
+	for _, f := range findings {
+		if f.Severity == Error || strict {
+			return true
+		}
+	}
+	return false
+}
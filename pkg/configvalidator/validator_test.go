@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configvalidator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func configMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-serving",
+			Name:      name,
+		},
+		Data: data,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cms  []*corev1.ConfigMap
+		want []Finding
+	}{{
+		name: "defaults",
+		cms: []*corev1.ConfigMap{
+			configMap("config-autoscaler", nil),
+			configMap("config-gc", nil),
+			configMap("config-network", nil),
+		},
+	}, {
+		name: "parser error",
+		cms: []*corev1.ConfigMap{
+			configMap("config-autoscaler", map[string]string{
+				"scale-to-zero-grace-period": "10s",
+			}),
+		},
+		want: []Finding{{
+			Severity:  Error,
+			ConfigMap: "config-autoscaler",
+			Message:   "scale-to-zero-grace-period must be at least 30s, got 10s",
+		}},
+	}, {
+		name: "unknown and duplicate",
+		cms: []*corev1.ConfigMap{
+			configMap("config-gc", nil),
+			configMap("config-gc", nil),
+			configMap("config-leader-election", nil),
+		},
+		want: []Finding{{
+			Severity:  Error,
+			ConfigMap: "config-gc",
+			Message:   "given more than once",
+		}, {
+			Severity:  Warning,
+			ConfigMap: "config-leader-election",
+			Message:   "not a ConfigMap of Knative Serving, it is ignored",
+		}},
+	}, {
+		name: "grace period longer than the stable window",
+		cms: []*corev1.ConfigMap{
+			configMap("config-autoscaler", map[string]string{
+				"stable-window":              "40s",
+				"scale-to-zero-grace-period": "60s",
+			}),
+		},
+		want: []Finding{{
+			Severity:  Warning,
+			ConfigMap: "config-autoscaler",
+			Message: "scale-to-zero-grace-period (1m0s) is longer than stable-window (40s): " +
+				"the grace period only needs to cover putting the activator in the request path, " +
+				"idle revisions keep their last pod for 1m40s in total",
+		}},
+	}, {
+		name: "grace period without scale to zero",
+		cms: []*corev1.ConfigMap{
+			configMap("config-autoscaler", map[string]string{
+				"enable-scale-to-zero":       "false",
+				"stable-window":              "40s",
+				"scale-to-zero-grace-period": "60s",
+			}),
+		},
+	}, {
+		name: "stale revision timeout too short",
+		cms: []*corev1.ConfigMap{
+			configMap("config-gc", map[string]string{
+				"stale-revision-timeout": "1h",
+			}),
+		},
+		want: []Finding{{
+			Severity:  Error,
+			ConfigMap: "config-gc",
+			Message: "stale-revision-timeout (1h0m0s) must be at least the resync period of the controller (10h0m0s) " +
+				"plus stale-revision-lastpinned-debounce (5h0m0s), it is raised to 15h0m0s",
+		}},
+	}, {
+		name: "auto-TLS without issuer",
+		cms: []*corev1.ConfigMap{
+			configMap("config-network", map[string]string{
+				"autoTLS": "Enabled",
+			}),
+			configMap("config-certmanager", nil),
+		},
+		want: []Finding{{
+			Severity:  Error,
+			ConfigMap: "config-certmanager",
+			Message:   "autoTLS is enabled in config-network, but issuerRef names no issuer to get the certificates from",
+		}},
+	}, {
+		name: "auto-TLS with issuer",
+		cms: []*corev1.ConfigMap{
+			configMap("config-network", map[string]string{
+				"autoTLS": "Enabled",
+			}),
+			configMap("config-certmanager", map[string]string{
+				"issuerRef": "kind: ClusterIssuer\nname: letsencrypt-issuer",
+			}),
+		},
+	}, {
+		name: "auto-TLS without cert-manager",
+		cms: []*corev1.ConfigMap{
+			configMap("config-network", map[string]string{
+				"autoTLS": "Enabled",
+			}),
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Validate(test.cms)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("Validate (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestValidateReleaseConfig(t *testing.T) {
+	cms, err := ReadFiles([]string{"../../config"})
+	if err != nil {
+		t.Fatalf("ReadFiles() = %v", err)
+	}
+	if len(cms) == 0 {
+		t.Fatal("No ConfigMaps in the release config")
+	}
+	if findings := Validate(cms); len(findings) != 0 {
+		t.Errorf("Validate() = %v, want no findings", findings)
+	}
+}
+
+func TestReadConfigMaps(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: knative-serving
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config-gc
+data:
+  stale-revision-timeout: "15h"
+---
+{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config-features"}}
+`
+	cms, err := ReadConfigMaps(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("ReadConfigMaps() = %v", err)
+	}
+	var got []string
+	for _, cm := range cms {
+		got = append(got, cm.Name)
+	}
+	if want := []string{"config-gc", "config-features"}; !cmp.Equal(got, want) {
+		t.Errorf("ConfigMaps = %v, want %v", got, want)
+	}
+	if got, want := cms[0].Data["stale-revision-timeout"], "15h"; got != want {
+		t.Errorf("stale-revision-timeout = %q, want %q", got, want)
+	}
+}
+
+func TestReadCluster(t *testing.T) {
+	client := kubefake.NewSimpleClientset(
+		configMap("config-gc", nil),
+		configMap("config-leader-election", nil),
+	)
+	cms, err := ReadCluster(client, "knative-serving")
+	if err != nil {
+		t.Fatalf("ReadCluster() = %v", err)
+	}
+	if len(cms) != 1 || cms[0].Name != "config-gc" {
+		t.Errorf("ReadCluster() = %v, want config-gc only", cms)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	warning := []Finding{{Severity: Warning}}
+	if Failed(warning, false) {
+		t.Error("Failed(warning, false) = true")
+	}
+	if !Failed(warning, true) {
+		t.Error("Failed(warning, true) = false")
+	}
+	if !Failed([]Finding{{Severity: Error}}, false) {
+		t.Error("Failed(error, false) = false")
+	}
+}
@@ -18,9 +18,12 @@ package deployment
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -31,6 +34,39 @@ const (
 	// QueueSidecarImageKey is the config map key for queue sidecar image
 	QueueSidecarImageKey           = "queueSidecarImage"
 	registriesSkippingTagResolving = "registriesSkippingTagResolving"
+	propagatedLabelsKey            = "propagatedLabels"
+	propagatedAnnotationsKey       = "propagatedAnnotations"
+	sidecarInjectKey               = "sidecarInject"
+	sidecarProxyCPUKey             = "sidecarProxyCPU"
+	sidecarProxyMemoryKey          = "sidecarProxyMemory"
+	cpuLimitsKey                   = "cpuLimits"
+	enableWindowsKey               = "enableWindows"
+	windowsQueueSidecarImageKey    = "windowsQueueSidecarImage"
+	architecturesKey               = "architectures"
+	vpaRecommendationIntervalKey   = "vpaRecommendationInterval"
+	priorityClassNameKey           = "priorityClassName"
+	enableActivatorRegistrationKey = "enableActivatorRegistration"
+	enableDataPlaneConfigKey       = "enableDataPlaneConfig"
+
+	// SidecarInjectEnabled makes revision pods request a mesh sidecar.
+	SidecarInjectEnabled = "true"
+	// SidecarInjectDisabled makes revision pods opt out of a mesh sidecar.
+	SidecarInjectDisabled = "false"
+	// SidecarInjectNamespaceDefault leaves the decision to the mesh's
+	// injection policy for the namespace.
+	SidecarInjectNamespaceDefault = "namespace-default"
+
+	// CPULimitsKeep keeps the CPU limits of the revision containers as they are.
+	CPULimitsKeep = "keep"
+	// CPULimitsOmit removes the CPU limits of the revision containers, keeping
+	// the requests, so that they are never throttled by the CFS quota.
+	CPULimitsOmit = "omit"
+	// CPULimitsScaleWithConcurrency sets the unset CPU limit of each revision
+	// container to its CPU request times the container concurrency, so that
+	// every concurrent request can use the CPU requested for one. Limits that
+	// are already set, and those of revisions with unlimited concurrency, are
+	// left as they are.
+	CPULimitsScaleWithConcurrency = "scale-with-concurrency"
 )
 
 // NewConfigFromMap creates a DeploymentConfig from the supplied Map
@@ -48,9 +84,95 @@ func NewConfigFromMap(configMap map[string]string) (*Config, error) {
 	} else {
 		nc.RegistriesSkippingTagResolving = sets.NewString(strings.Split(registries, ",")...)
 	}
+
+	if labels, ok := configMap[propagatedLabelsKey]; ok {
+		nc.PropagatedLabels = parseKeys(labels)
+	}
+	if annotations, ok := configMap[propagatedAnnotationsKey]; ok {
+		nc.PropagatedAnnotations = parseKeys(annotations)
+	}
+
+	if inject, ok := configMap[sidecarInjectKey]; ok {
+		switch inject {
+		case SidecarInjectEnabled, SidecarInjectDisabled, SidecarInjectNamespaceDefault:
+			nc.SidecarInject = inject
+		default:
+			return nil, fmt.Errorf("%s must be one of %q, %q or %q, was %q", sidecarInjectKey,
+				SidecarInjectEnabled, SidecarInjectDisabled, SidecarInjectNamespaceDefault, inject)
+		}
+	}
+	if limits, ok := configMap[cpuLimitsKey]; ok {
+		switch limits {
+		case CPULimitsKeep, CPULimitsOmit, CPULimitsScaleWithConcurrency:
+			nc.CPULimits = limits
+		default:
+			return nil, fmt.Errorf("%s must be one of %q, %q or %q, was %q", cpuLimitsKey,
+				CPULimitsKeep, CPULimitsOmit, CPULimitsScaleWithConcurrency, limits)
+		}
+	}
+
+	if enable, ok := configMap[enableWindowsKey]; ok {
+		nc.EnableWindows = strings.ToLower(enable) == "true"
+	}
+	nc.WindowsQueueSidecarImage = configMap[windowsQueueSidecarImageKey]
+
+	if archs, ok := configMap[architecturesKey]; ok {
+		nc.Architectures = parseKeys(archs)
+	}
+
+	if interval, ok := configMap[vpaRecommendationIntervalKey]; ok && interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", vpaRecommendationIntervalKey, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("%s must not be negative, was %v", vpaRecommendationIntervalKey, d)
+		}
+		nc.VPARecommendationInterval = d
+	}
+
+	nc.PriorityClassName = configMap[priorityClassNameKey]
+
+	if enable, ok := configMap[enableActivatorRegistrationKey]; ok {
+		nc.EnableActivatorRegistration = strings.ToLower(enable) == "true"
+	}
+
+	if enable, ok := configMap[enableDataPlaneConfigKey]; ok {
+		nc.EnableDataPlaneConfig = strings.ToLower(enable) == "true"
+	}
+
+	for _, q := range []struct {
+		key   string
+		field *string
+	}{{
+		key:   sidecarProxyCPUKey,
+		field: &nc.SidecarProxyCPU,
+	}, {
+		key:   sidecarProxyMemoryKey,
+		field: &nc.SidecarProxyMemory,
+	}} {
+		if v, ok := configMap[q.key]; ok {
+			if _, err := resource.ParseQuantity(v); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %v", q.key, err)
+			}
+			*q.field = v
+		}
+	}
 	return nc, nil
 }
 
+// parseKeys parses a comma separated list of label or annotation keys, or
+// other names.
+func parseKeys(list string) sets.String {
+	keys := sets.NewString()
+	for _, k := range strings.Split(list, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys.Insert(k)
+		}
+	}
+	return keys
+}
+
 // NewConfigFromConfigMap creates a DeploymentConfig from the supplied configMap
 func NewConfigFromConfigMap(config *corev1.ConfigMap) (*Config, error) {
 	return NewConfigFromMap(config.Data)
@@ -64,4 +186,70 @@ type Config struct {
 
 	// Repositories for which tag to digest resolving should be skipped
 	RegistriesSkippingTagResolving sets.String
+
+	// PropagatedLabels are the keys of the labels that are copied from the
+	// Configuration (and thus the Service) onto its Revisions, and from
+	// there onto the Deployments and Pods.
+	PropagatedLabels sets.String
+
+	// PropagatedAnnotations are the keys of the annotations that are copied
+	// from the Configuration (and thus the Service) onto its Revisions, and
+	// from there onto the Deployments and Pods.
+	PropagatedAnnotations sets.String
+
+	// SidecarInject is the default value of the sidecar.istio.io/inject
+	// annotation of the revision pods, one of SidecarInjectEnabled,
+	// SidecarInjectDisabled and SidecarInjectNamespaceDefault. Revisions can
+	// override it with the annotation. Empty behaves like SidecarInjectEnabled.
+	SidecarInject string
+
+	// SidecarProxyCPU and SidecarProxyMemory are the default CPU and memory
+	// requests of the mesh sidecar of the revision pods. Revisions can
+	// override them with the sidecar.istio.io/proxyCPU and
+	// sidecar.istio.io/proxyMemory annotations.
+	SidecarProxyCPU    string
+	SidecarProxyMemory string
+
+	// CPULimits is how the CPU limits of the user and queue-proxy containers
+	// are set, one of CPULimitsKeep, CPULimitsOmit and
+	// CPULimitsScaleWithConcurrency. Empty behaves like CPULimitsKeep.
+	CPULimits string
+
+	// EnableWindows allows Revisions to be scheduled onto Windows nodes.
+	// When enabled, the pods of all other Revisions are pinned to Linux nodes.
+	EnableWindows bool
+
+	// WindowsQueueSidecarImage is the queue sidecar image injected into the
+	// pods of Revisions scheduled onto Windows nodes. QueueSidecarImage is
+	// Linux only, so Revisions requesting Windows nodes while this is empty
+	// are marked as failed.
+	WindowsQueueSidecarImage string
+
+	// Architectures are the CPU architectures, as in the kubernetes.io/arch
+	// node label, the pods of Revisions may be scheduled onto. If empty, the
+	// pods are scheduled onto nodes of any architecture.
+	Architectures sets.String
+
+	// VPARecommendationInterval is how often the recommendations of the
+	// Vertical Pod Autoscaler for the resources of each Revision are read
+	// and surfaced as events on the Revision. Zero disables creating the
+	// VerticalPodAutoscalers in recommendation mode altogether.
+	VPARecommendationInterval time.Duration
+
+	// PriorityClassName is the PriorityClass of the pods of all Revisions.
+	// The cluster autoscaler only provisions nodes for pending pods whose
+	// priority is above its expendable pods cutoff. If empty, the pods get
+	// the cluster's default priority.
+	PriorityClassName string
+
+	// EnableActivatorRegistration makes the queue-proxies announce the state
+	// of their pods to all activators, which then route to newly Ready pods
+	// and stop routing to draining ones within a second.
+	EnableActivatorRegistration bool
+
+	// EnableDataPlaneConfig hands the queue-proxies the settings they can
+	// change without restarting (timeout, rate limits, log level, request
+	// metrics backend and tracing) through a signed ConfigMap per Revision,
+	// instead of environment variables.
+	EnableDataPlaneConfig bool
 }
@@ -18,6 +18,7 @@ package deployment
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -82,6 +83,232 @@ func TestControllerConfiguration(t *testing.T) {
 				registriesSkippingTagResolving: "ko.local,ko.dev",
 			},
 		},
+	}, {
+		name:    "controller configuration with propagated labels and annotations",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			PropagatedLabels:               sets.NewString("team", "cost-center"),
+			PropagatedAnnotations:          sets.NewString("sidecar.istio.io/inject"),
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				propagatedLabelsKey:      "team, cost-center,",
+				propagatedAnnotationsKey: "sidecar.istio.io/inject",
+			},
+		},
+	}, {
+		name:    "controller configuration with sidecar settings",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			SidecarInject:                  SidecarInjectNamespaceDefault,
+			SidecarProxyCPU:                "100m",
+			SidecarProxyMemory:             "128Mi",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:  noSidecarImage,
+				sidecarInjectKey:      "namespace-default",
+				sidecarProxyCPUKey:    "100m",
+				sidecarProxyMemoryKey: "128Mi",
+			},
+		},
+	}, {
+		name:           "controller configuration with invalid sidecar inject",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				sidecarInjectKey:     "yes",
+			},
+		},
+	}, {
+		name:           "controller configuration with invalid sidecar proxy memory",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:  noSidecarImage,
+				sidecarProxyMemoryKey: "lots",
+			},
+		},
+	}, {
+		name:    "controller configuration with cpu limits",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			CPULimits:                      CPULimitsOmit,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				cpuLimitsKey:         "omit",
+			},
+		},
+	}, {
+		name:           "controller configuration with invalid cpu limits",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				cpuLimitsKey:         "none",
+			},
+		},
+	}, {
+		name:    "controller configuration with windows enabled",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			EnableWindows:                  true,
+			WindowsQueueSidecarImage:       "queue-windows",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:        noSidecarImage,
+				enableWindowsKey:            "True",
+				windowsQueueSidecarImageKey: "queue-windows",
+			},
+		},
+	}, {
+		name:    "controller configuration with architectures",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			Architectures:                  sets.NewString("amd64", "arm64"),
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				architecturesKey:     "amd64, arm64",
+			},
+		},
+	}, {
+		name:    "controller configuration with vpa recommendations",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			VPARecommendationInterval:      10 * time.Minute,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:         noSidecarImage,
+				vpaRecommendationIntervalKey: "10m",
+			},
+		},
+	}, {
+		name:           "controller configuration with invalid vpa recommendation interval",
+		wantErr:        true,
+		wantController: (*Config)(nil),
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:         noSidecarImage,
+				vpaRecommendationIntervalKey: "often",
+			},
+		},
+	}, {
+		name:    "controller configuration with priority class",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			PriorityClassName:              "knative-serving",
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey: noSidecarImage,
+				priorityClassNameKey: "knative-serving",
+			},
+		},
+	}, {
+		name:    "controller configuration with activator registration",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			EnableActivatorRegistration:    true,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:           noSidecarImage,
+				enableActivatorRegistrationKey: "true",
+			},
+		},
+	}, {
+		name:    "controller configuration with data-plane config",
+		wantErr: false,
+		wantController: &Config{
+			RegistriesSkippingTagResolving: sets.NewString("ko.local", "dev.local"),
+			QueueSidecarImage:              noSidecarImage,
+			EnableDataPlaneConfig:          true,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				QueueSidecarImageKey:     noSidecarImage,
+				enableDataPlaneConfigKey: "True",
+			},
+		},
 	}, {
 		name:           "controller with no side car image",
 		wantErr:        true,
@@ -0,0 +1,309 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diagnose collects the state of a Knative Service, and of the
+// resources serving it, into a single report for triage.
+package diagnose
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+	revisionnames "knative.dev/serving/pkg/reconciler/revision/resources/names"
+	servicenames "knative.dev/serving/pkg/reconciler/service/resources/names"
+)
+
+// maxEvents is how many of the latest events of the resources are reported.
+const maxEvents = 20
+
+// ConcurrencyGetter returns the concurrency the autoscaler observes for the
+// Revision namespace/name.
+type ConcurrencyGetter func(namespace, name string) (string, error)
+
+// Clients are the clients the state of a Service is collected with.
+type Clients struct {
+	Kube    kubernetes.Interface
+	Serving clientset.Interface
+	// Concurrency reads the data-plane metrics of Revisions. They are not
+	// reported when it is nil.
+	Concurrency ConcurrencyGetter
+}
+
+// Resource is the state of one of the resources serving a Service.
+type Resource struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Ready   string `json:"ready,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Details summarizes the state of resources without a Ready condition,
+	// e.g. how many replicas of a Deployment are ready.
+	Details string `json:"details,omitempty"`
+	// Object is the whole resource.
+	Object interface{} `json:"object"`
+}
+
+// Event is an event of one of the resources serving a Service.
+type Event struct {
+	LastSeen time.Time `json:"lastSeen"`
+	Type     string    `json:"type"`
+	Kind     string    `json:"kind"`
+	Name     string    `json:"name"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+}
+
+// Concurrency is the concurrency the autoscaler observes for a Revision.
+type Concurrency struct {
+	Revision string `json:"revision"`
+	Value    string `json:"value"`
+}
+
+// Report is the state of a Service and of the resources serving it.
+type Report struct {
+	Namespace   string        `json:"namespace"`
+	Name        string        `json:"name"`
+	Resources   []Resource    `json:"resources"`
+	Concurrency []Concurrency `json:"concurrency,omitempty"`
+	Events      []Event       `json:"events,omitempty"`
+	// Errors are the failures to collect parts of the report, which is
+	// reported without them.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Collect reports the state of the Service namespace/name. Only failing to
+// get the Service fails the collection, the failures to collect the other
+// resources are reported.
+func Collect(clients Clients, namespace, name string) (*Report, error) {
+	service, err := clients.Serving.ServingV1alpha1().Services(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{Namespace: namespace, Name: name}
+	r.addConditioned("Service", service.Name, service.Status.GetCondition(apis.ConditionReady), service)
+
+	c := &collector{Clients: clients, report: r}
+	c.configuration(servicenames.Configuration(service))
+	c.route(servicenames.Route(service))
+	c.events()
+	return r, nil
+}
+
+type collector struct {
+	Clients
+	report *Report
+}
+
+func (c *collector) errorf(format string, args ...interface{}) {
+	c.report.Errors = append(c.report.Errors, fmt.Sprintf(format, args...))
+}
+
+func (c *collector) configuration(name string) {
+	ns := c.report.Namespace
+	config, err := c.Serving.ServingV1alpha1().Configurations(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		c.errorf("Failed to get Configuration %q: %v", name, err)
+		return
+	}
+	c.report.addConditioned("Configuration", config.Name, config.Status.GetCondition(apis.ConditionReady), config)
+
+	revs, err := c.Serving.ServingV1alpha1().Revisions(ns).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{serving.ConfigurationLabelKey: config.Name}).String(),
+	})
+	if err != nil {
+		c.errorf("Failed to list the Revisions of Configuration %q: %v", name, err)
+		return
+	}
+	// Newest Revisions first, they are the likeliest to be broken.
+	items := revs.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[j].CreationTimestamp.Before(&items[i].CreationTimestamp)
+	})
+	for i := range items {
+		c.revision(&items[i])
+	}
+}
+
+func (c *collector) revision(rev *v1alpha1.Revision) {
+	ns := c.report.Namespace
+	c.report.addConditioned("Revision", rev.Name, rev.Status.GetCondition(apis.ConditionReady), rev)
+
+	if pa, err := c.Serving.AutoscalingV1alpha1().PodAutoscalers(ns).Get(revisionnames.PA(rev), metav1.GetOptions{}); err != nil {
+		c.optional("PodAutoscaler", revisionnames.PA(rev), err)
+	} else {
+		c.report.addConditioned("PodAutoscaler", pa.Name, pa.Status.GetCondition(apis.ConditionReady), pa)
+	}
+
+	if sks, err := c.Serving.NetworkingV1alpha1().ServerlessServices(ns).Get(rev.Name, metav1.GetOptions{}); err != nil {
+		c.optional("ServerlessService", rev.Name, err)
+	} else {
+		r := c.report.addConditioned("ServerlessService", sks.Name, sks.Status.GetCondition(apis.ConditionReady), sks)
+		r.Details = fmt.Sprintf("mode %s", sks.Spec.Mode)
+		for _, name := range []string{sks.Status.ServiceName, sks.Status.PrivateServiceName} {
+			if name != "" {
+				c.endpoints(name)
+			}
+		}
+	}
+
+	if d, err := c.Kube.AppsV1().Deployments(ns).Get(revisionnames.Deployment(rev), metav1.GetOptions{}); err != nil {
+		c.optional("Deployment", revisionnames.Deployment(rev), err)
+	} else {
+		var replicas int32
+		if d.Spec.Replicas != nil {
+			replicas = *d.Spec.Replicas
+		}
+		c.report.Resources = append(c.report.Resources, Resource{
+			Kind:    "Deployment",
+			Name:    d.Name,
+			Details: fmt.Sprintf("%d/%d replicas ready", d.Status.ReadyReplicas, replicas),
+			Object:  d,
+		})
+	}
+
+	if c.Concurrency != nil {
+		value, err := c.Concurrency(ns, rev.Name)
+		if err != nil {
+			c.errorf("Failed to get the concurrency of Revision %q: %v", rev.Name, err)
+			return
+		}
+		c.report.Concurrency = append(c.report.Concurrency, Concurrency{Revision: rev.Name, Value: value})
+	}
+}
+
+func (c *collector) endpoints(name string) {
+	ep, err := c.Kube.CoreV1().Endpoints(c.report.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		c.optional("Endpoints", name, err)
+		return
+	}
+	ready, notReady := 0, 0
+	for _, subset := range ep.Subsets {
+		ready += len(subset.Addresses)
+		notReady += len(subset.NotReadyAddresses)
+	}
+	c.report.Resources = append(c.report.Resources, Resource{
+		Kind:    "Endpoints",
+		Name:    ep.Name,
+		Details: fmt.Sprintf("%d ready, %d not ready addresses", ready, notReady),
+		Object:  ep,
+	})
+}
+
+func (c *collector) route(name string) {
+	ns := c.report.Namespace
+	route, err := c.Serving.ServingV1alpha1().Routes(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		c.errorf("Failed to get Route %q: %v", name, err)
+		return
+	}
+	c.report.addConditioned("Route", route.Name, route.Status.GetCondition(apis.ConditionReady), route)
+
+	selector := labels.SelectorFromSet(labels.Set{
+		serving.RouteLabelKey:          route.Name,
+		serving.RouteNamespaceLabelKey: route.Namespace,
+	}).String()
+	cis, err := c.Serving.NetworkingV1alpha1().ClusterIngresses().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		c.errorf("Failed to list the ClusterIngresses of Route %q: %v", name, err)
+	} else {
+		for i := range cis.Items {
+			ci := &cis.Items[i]
+			c.report.addConditioned("ClusterIngress", ci.Name, ci.Status.GetCondition(apis.ConditionReady), ci)
+		}
+	}
+	ings, err := c.Serving.NetworkingV1alpha1().Ingresses(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		c.errorf("Failed to list the Ingresses of Route %q: %v", name, err)
+	} else {
+		for i := range ings.Items {
+			ing := &ings.Items[i]
+			c.report.addConditioned("Ingress", ing.Name, ing.Status.GetCondition(apis.ConditionReady), ing)
+		}
+	}
+}
+
+// optional reports the failure to get a resource which isn't created in
+// every setup, e.g. a Deployment of a Revision scaled to zero through KEDA.
+func (c *collector) optional(kind, name string, err error) {
+	if apierrs.IsNotFound(err) {
+		c.errorf("%s %q does not exist", kind, name)
+		return
+	}
+	c.errorf("Failed to get %s %q: %v", kind, name, err)
+}
+
+// events collects the latest events of the resources reported.
+func (c *collector) events() {
+	list, err := c.Kube.CoreV1().Events(c.report.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		c.errorf("Failed to list events: %v", err)
+		return
+	}
+	reported := make(map[string]bool, len(c.report.Resources))
+	for _, r := range c.report.Resources {
+		reported[r.Kind+"/"+r.Name] = true
+	}
+	var events []Event
+	for _, e := range list.Items {
+		if !reported[e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name] {
+			continue
+		}
+		events = append(events, Event{
+			LastSeen: lastSeen(&e),
+			Type:     e.Type,
+			Kind:     e.InvolvedObject.Kind,
+			Name:     e.InvolvedObject.Name,
+			Reason:   e.Reason,
+			Message:  e.Message,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastSeen.Before(events[j].LastSeen)
+	})
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	c.report.Events = events
+}
+
+func lastSeen(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.FirstTimestamp.Time
+}
+
+func (r *Report) addConditioned(kind, name string, ready *apis.Condition, obj interface{}) *Resource {
+	res := Resource{Kind: kind, Name: name, Object: obj}
+	if ready != nil {
+		res.Ready = string(ready.Status)
+		res.Reason = ready.Reason
+		res.Message = ready.Message
+	}
+	r.Resources = append(r.Resources, res)
+	return &r.Resources[len(r.Resources)-1]
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/ptr"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+func ready(status corev1.ConditionStatus, reason string) duckv1beta1.Status {
+	return duckv1beta1.Status{
+		Conditions: duckv1beta1.Conditions{{
+			Type:   apis.ConditionReady,
+			Status: status,
+			Reason: reason,
+		}},
+	}
+}
+
+func meta(name string, labels map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace:         "default",
+		Name:              name,
+		Labels:            labels,
+		CreationTimestamp: metav1.Now(),
+	}
+}
+
+func event(kind, name, reason string, seen time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name + "." + reason,
+		},
+		InvolvedObject: corev1.ObjectReference{Kind: kind, Name: name},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " happened",
+		LastTimestamp:  metav1.NewTime(seen),
+	}
+}
+
+func testClients() Clients {
+	now := time.Now()
+	revLabels := map[string]string{serving.ConfigurationLabelKey: "hello"}
+	routeLabels := map[string]string{
+		serving.RouteLabelKey:          "hello",
+		serving.RouteNamespaceLabelKey: "default",
+	}
+	serving := servingfake.NewSimpleClientset([]runtime.Object{
+		&v1alpha1.Service{
+			ObjectMeta: meta("hello", nil),
+			Status: v1alpha1.ServiceStatus{
+				Status: ready(corev1.ConditionFalse, "RevisionFailed"),
+			},
+		},
+		&v1alpha1.Configuration{
+			ObjectMeta: meta("hello", nil),
+			Status: v1alpha1.ConfigurationStatus{
+				Status: ready(corev1.ConditionFalse, "RevisionFailed"),
+			},
+		},
+		&v1alpha1.Revision{
+			ObjectMeta: meta("hello-00001", revLabels),
+			Status: v1alpha1.RevisionStatus{
+				Status: ready(corev1.ConditionTrue, ""),
+			},
+		},
+		&v1alpha1.Route{
+			ObjectMeta: meta("hello", nil),
+			Status: v1alpha1.RouteStatus{
+				Status: ready(corev1.ConditionTrue, ""),
+			},
+		},
+		&netv1alpha1.ServerlessService{
+			ObjectMeta: meta("hello-00001", nil),
+			Spec:       netv1alpha1.ServerlessServiceSpec{Mode: netv1alpha1.SKSOperationModeServe},
+			Status: netv1alpha1.ServerlessServiceStatus{
+				Status:      ready(corev1.ConditionTrue, ""),
+				ServiceName: "hello-00001",
+			},
+		},
+		&netv1alpha1.ClusterIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: "hello.default", Labels: routeLabels},
+			Status: netv1alpha1.IngressStatus{
+				Status: ready(corev1.ConditionTrue, ""),
+			},
+		},
+	}...)
+	kube := kubefake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: meta("hello-00001-deployment", nil),
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.Int32(2)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: meta("hello-00001", nil),
+			Subsets: []corev1.EndpointSubset{{
+				Addresses:         []corev1.EndpointAddress{{IP: "10.0.0.1"}},
+				NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.2"}},
+			}},
+		},
+		event("Revision", "hello-00001", "Scaled", now.Add(-time.Minute)),
+		event("Service", "hello", "RevisionFailed", now),
+		event("Pod", "unrelated", "Pulled", now),
+	)
+	return Clients{
+		Kube:    kube,
+		Serving: serving,
+		Concurrency: func(namespace, name string) (string, error) {
+			return "1500m", nil
+		},
+	}
+}
+
+func TestCollect(t *testing.T) {
+	report, err := Collect(testClients(), "default", "hello")
+	if err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+
+	type summary struct {
+		Kind, Name, Ready, Reason, Details string
+	}
+	var got []summary
+	for _, r := range report.Resources {
+		got = append(got, summary{r.Kind, r.Name, r.Ready, r.Reason, r.Details})
+	}
+	want := []summary{
+		{"Service", "hello", "False", "RevisionFailed", ""},
+		{"Configuration", "hello", "False", "RevisionFailed", ""},
+		{"Revision", "hello-00001", "True", "", ""},
+		{"ServerlessService", "hello-00001", "True", "", "mode Serve"},
+		{"Endpoints", "hello-00001", "", "", "1 ready, 1 not ready addresses"},
+		{"Deployment", "hello-00001-deployment", "", "", "1/2 replicas ready"},
+		{"Route", "hello", "True", "", ""},
+		{"ClusterIngress", "hello.default", "True", "", ""},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Resources (-want, +got) = %v", diff)
+	}
+
+	if want := []Concurrency{{Revision: "hello-00001", Value: "1500m"}}; !cmp.Equal(want, report.Concurrency) {
+		t.Errorf("Concurrency = %v, want: %v", report.Concurrency, want)
+	}
+
+	var reasons []string
+	for _, e := range report.Events {
+		reasons = append(reasons, e.Reason)
+	}
+	if want := []string{"Scaled", "RevisionFailed"}; !cmp.Equal(want, reasons) {
+		t.Errorf("Events = %v, want: %v", reasons, want)
+	}
+
+	if want := []string{`PodAutoscaler "hello-00001" does not exist`}; !cmp.Equal(want, report.Errors) {
+		t.Errorf("Errors = %v, want: %v", report.Errors, want)
+	}
+}
+
+func TestCollectPartial(t *testing.T) {
+	clients := testClients()
+	clients.Concurrency = func(namespace, name string) (string, error) {
+		return "", errors.New("the service is unavailable")
+	}
+	report, err := Collect(clients, "default", "hello")
+	if err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	want := `Failed to get the concurrency of Revision "hello-00001": the service is unavailable`
+	if got := report.Errors[len(report.Errors)-1]; got != want {
+		t.Errorf("Errors = %v, want it to end with: %s", report.Errors, want)
+	}
+}
+
+func TestCollectMissingService(t *testing.T) {
+	if _, err := Collect(testClients(), "default", "missing"); err == nil {
+		t.Error("Collect() = nil, want an error")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	report, err := Collect(testClients(), "default", "hello")
+	if err != nil {
+		t.Fatalf("Collect() = %v", err)
+	}
+	var b bytes.Buffer
+	if err := report.Print(&b); err != nil {
+		t.Fatalf("Print() = %v", err)
+	}
+	for _, want := range []string{
+		"Service default/hello",
+		"Deployment hello-00001-deployment 1/2 replicas ready",
+		"hello-00001 1500m",
+		"Warning Service/hello RevisionFailed RevisionFailed happened",
+		`PodAutoscaler "hello-00001" does not exist`,
+	} {
+		// Ignore how the columns are padded.
+		if !strings.Contains(strings.Join(strings.Fields(b.String()), " "), want) {
+			t.Errorf("Print() = \n%s\nwant it to contain: %q", b.String(), want)
+		}
+	}
+}
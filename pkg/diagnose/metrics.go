@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"encoding/json"
+	"errors"
+
+	"k8s.io/client-go/rest"
+	cmetrics "k8s.io/metrics/pkg/apis/custom_metrics/v1beta1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+// CustomMetricsConcurrency returns a ConcurrencyGetter reading the
+// concurrency of Revisions from the custom metrics API the autoscaler
+// serves, with client.
+func CustomMetricsConcurrency(client rest.Interface) ConcurrencyGetter {
+	revisions := v1alpha1.Resource("revisions")
+	return func(namespace, name string) (string, error) {
+		raw, err := client.Get().
+			AbsPath("/apis/custom.metrics.k8s.io/v1beta1/namespaces", namespace,
+				revisions.String(), name, autoscaling.Concurrency).
+			DoRaw()
+		if err != nil {
+			return "", err
+		}
+		var list cmetrics.MetricValueList
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return "", err
+		}
+		if len(list.Items) == 0 {
+			return "", errors.New("no concurrency is observed")
+		}
+		return list.Items[0].Value.String(), nil
+	}
+}
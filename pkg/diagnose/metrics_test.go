@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestCustomMetricsConcurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/custom.metrics.k8s.io/v1beta1/namespaces/default/revisions.serving.knative.dev/hello-00001/concurrency":
+			w.Write([]byte(`{"kind":"MetricValueList","items":[{"metricName":"concurrency","value":"1500m"}]}`))
+		case "/apis/custom.metrics.k8s.io/v1beta1/namespaces/default/revisions.serving.knative.dev/idle/concurrency":
+			w.Write([]byte(`{"kind":"MetricValueList","items":[]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewForConfig() = %v", err)
+	}
+	get := CustomMetricsConcurrency(client.Discovery().RESTClient())
+
+	if got, err := get("default", "hello-00001"); err != nil || got != "1500m" {
+		t.Errorf("Concurrency = %q, %v, want: 1500m", got, err)
+	}
+	if _, err := get("default", "idle"); err == nil {
+		t.Error("Concurrency of an idle Revision = nil, want an error")
+	}
+	if _, err := get("default", "missing"); err == nil {
+		t.Error("Concurrency of a missing Revision = nil, want an error")
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diagnose
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Print writes r to w as tables, the way triage reads it.
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Service %s/%s\n\n", r.Namespace, r.Name)
+
+	fmt.Fprintln(tw, "KIND\tNAME\tREADY\tREASON\tDETAILS")
+	for _, res := range r.Resources {
+		details := res.Details
+		if details == "" {
+			details = res.Message
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", res.Kind, res.Name, res.Ready, res.Reason, details)
+	}
+
+	if len(r.Concurrency) > 0 {
+		fmt.Fprintln(tw, "\nREVISION\tCONCURRENCY")
+		for _, c := range r.Concurrency {
+			fmt.Fprintf(tw, "%s\t%s\n", c.Revision, c.Value)
+		}
+	}
+
+	if len(r.Events) > 0 {
+		fmt.Fprintln(tw, "\nLAST SEEN\tTYPE\tOBJECT\tREASON\tMESSAGE")
+		for _, e := range r.Events {
+			fmt.Fprintf(tw, "%s\t%s\t%s/%s\t%s\t%s\n", e.LastSeen.UTC().Format(time.RFC3339),
+				e.Type, e.Kind, e.Name, e.Reason, e.Message)
+		}
+	}
+
+	if len(r.Errors) > 0 {
+		fmt.Fprintln(tw, "\nNOT COLLECTED")
+		for _, err := range r.Errors {
+			fmt.Fprintln(tw, err)
+		}
+	}
+	return tw.Flush()
+}
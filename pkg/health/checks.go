@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+)
+
+// InformersSynced checks that informers have synced their caches.
+func InformersSynced(informers ...controller.Informer) Check {
+	return func() error {
+		for _, informer := range informers {
+			if !informer.HasSynced() {
+				return errors.New("informers have not synced yet")
+			}
+		}
+		return nil
+	}
+}
+
+// ConfigMapWatcher is a configmap.Watcher checking that the ConfigMaps
+// watched with it are loaded, i.e. observed at least once.
+type ConfigMapWatcher struct {
+	configmap.Watcher
+
+	mu     sync.Mutex
+	loaded map[string]bool
+}
+
+var _ configmap.Watcher = (*ConfigMapWatcher)(nil)
+
+// NewConfigMapWatcher wraps w into a ConfigMapWatcher.
+func NewConfigMapWatcher(w configmap.Watcher) *ConfigMapWatcher {
+	return &ConfigMapWatcher{
+		Watcher: w,
+		loaded:  make(map[string]bool),
+	}
+}
+
+// Watch implements configmap.Watcher
+func (w *ConfigMapWatcher) Watch(name string, o configmap.Observer) {
+	w.mu.Lock()
+	if _, ok := w.loaded[name]; !ok {
+		w.loaded[name] = false
+	}
+	w.mu.Unlock()
+
+	w.Watcher.Watch(name, func(cm *corev1.ConfigMap) {
+		o(cm)
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.loaded[name] = true
+	})
+}
+
+// Check is a Check of the ConfigMaps watched being loaded.
+func (w *ConfigMapWatcher) Check() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var missing []string
+	for name, loaded := range w.loaded {
+		if !loaded {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("ConfigMaps not loaded yet: %s", strings.Join(missing, ", "))
+}
+
+// CertificateValid checks that the PEM encoded certificate in key of the
+// Secret namespace/name, e.g. the one a webhook serves with, is valid now.
+func CertificateValid(client kubernetes.Interface, namespace, name, key string) Check {
+	return func() error {
+		secret, err := client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		block, _ := pem.Decode(secret.Data[key])
+		if block == nil {
+			return fmt.Errorf("secret %s/%s has no PEM encoded certificate in %s", namespace, name, key)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if now.Before(cert.NotBefore) {
+			return fmt.Errorf("certificate is not valid before %v", cert.NotBefore)
+		}
+		if now.After(cert.NotAfter) {
+			return fmt.Errorf("certificate expired at %v", cert.NotAfter)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/webhook"
+)
+
+type fakeInformer bool
+
+func (fakeInformer) Run(<-chan struct{}) {}
+
+func (fi fakeInformer) HasSynced() bool {
+	return bool(fi)
+}
+
+func TestInformersSynced(t *testing.T) {
+	if err := InformersSynced(fakeInformer(true), fakeInformer(true))(); err != nil {
+		t.Errorf("InformersSynced() = %v, want no error", err)
+	}
+	if err := InformersSynced(fakeInformer(true), fakeInformer(false))(); err == nil {
+		t.Error("InformersSynced() = nil, want an error")
+	}
+}
+
+func TestConfigMapWatcher(t *testing.T) {
+	manual := &configmap.ManualWatcher{Namespace: "knative-serving"}
+	w := NewConfigMapWatcher(manual)
+	w.Watch("config-logging", func(*corev1.ConfigMap) {})
+	w.Watch("config-network", func(*corev1.ConfigMap) {})
+
+	want := "ConfigMaps not loaded yet: config-logging, config-network"
+	if err := w.Check(); err == nil || err.Error() != want {
+		t.Errorf("Check() = %v, want: %s", err, want)
+	}
+
+	manual.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "config-logging"},
+	})
+	want = "ConfigMaps not loaded yet: config-network"
+	if err := w.Check(); err == nil || err.Error() != want {
+		t.Errorf("Check() = %v, want: %s", err, want)
+	}
+
+	manual.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: "config-network"},
+	})
+	if err := w.Check(); err != nil {
+		t.Errorf("Check() = %v, want no error", err)
+	}
+}
+
+func expiredCert(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "webhook.knative-serving.svc"},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() = %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateValid(t *testing.T) {
+	_, validCert, _, err := webhook.CreateCerts(context.Background(), "webhook", "knative-serving")
+	if err != nil {
+		t.Fatalf("CreateCerts() = %v", err)
+	}
+	secret := func(name string, cert []byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "knative-serving", Name: name},
+			Data:       map[string][]byte{"server-cert.pem": cert},
+		}
+	}
+	client := kubefake.NewSimpleClientset(
+		secret("valid", validCert),
+		secret("expired", expiredCert(t)),
+		secret("garbage", []byte("not a certificate")),
+	)
+
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr string
+	}{{
+		name:   "valid",
+		secret: "valid",
+	}, {
+		name:    "expired",
+		secret:  "expired",
+		wantErr: "certificate expired at",
+	}, {
+		name:    "not a certificate",
+		secret:  "garbage",
+		wantErr: "has no PEM encoded certificate",
+	}, {
+		name:    "missing",
+		secret:  "missing",
+		wantErr: "not found",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CertificateValid(client, "knative-serving", test.secret, "server-cert.pem")()
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Errorf("CertificateValid() = %v, want no error", err)
+			case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Errorf("CertificateValid() = %v, want an error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}
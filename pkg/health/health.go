@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package health serves the liveness and the readiness of the Knative
+// components. A component is ready once the dependencies it needs to be
+// functional are, e.g. its informers are synced and its ConfigMaps loaded.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Port is the port the components serve their health on.
+	Port = 8090
+	// LivenessPath is the path the liveness of the components is served on.
+	LivenessPath = "/healthz"
+	// ReadinessPath is the path the readiness of the components is served
+	// on, with the results of their checks.
+	ReadinessPath = "/readyz"
+)
+
+// Check returns why a dependency of a component is not functional, or nil.
+type Check func() error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Handler serves the liveness and the readiness of a component.
+type Handler struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler without checks, i.e. always ready.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// AddCheck adds check, reported as name, to those the readiness of the
+// component depends on.
+func (h *Handler) AddCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// ServeHTTP serves the liveness of the component on LivenessPath, and its
+// readiness on ReadinessPath. The component is live as long as it serves.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case LivenessPath:
+		w.Write([]byte("ok"))
+	case ReadinessPath:
+		h.serveReadiness(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveReadiness(w http.ResponseWriter) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var b strings.Builder
+	ready := true
+	for _, c := range h.checks {
+		if err := c.check(); err != nil {
+			ready = false
+			fmt.Fprintf(&b, "[-]%s failed: %v\n", c.name, err)
+		} else {
+			fmt.Fprintf(&b, "[+]%s ok\n", c.name)
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write([]byte(b.String()))
+}
+
+// ListenAndServe serves h on Port until stopCh is closed.
+func ListenAndServe(stopCh <-chan struct{}, h http.Handler) error {
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(Port),
+		Handler: h,
+	}
+	go func() {
+		<-stopCh
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
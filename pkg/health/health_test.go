@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	var cacheErr error
+	h := NewHandler()
+	h.AddCheck("informers", func() error { return nil })
+	h.AddCheck("cache", func() error { return cacheErr })
+
+	tests := []struct {
+		name     string
+		path     string
+		cacheErr error
+		wantCode int
+		wantBody string
+	}{{
+		name:     "live",
+		path:     LivenessPath,
+		cacheErr: errors.New("not synced"),
+		wantCode: http.StatusOK,
+		wantBody: "ok",
+	}, {
+		name:     "ready",
+		path:     ReadinessPath,
+		wantCode: http.StatusOK,
+		wantBody: "[+]informers ok\n[+]cache ok\n",
+	}, {
+		name:     "not ready",
+		path:     ReadinessPath,
+		cacheErr: errors.New("not synced"),
+		wantCode: http.StatusServiceUnavailable,
+		wantBody: "[+]informers ok\n[-]cache failed: not synced\n",
+	}, {
+		name:     "unknown path",
+		path:     "/metrics",
+		wantCode: http.StatusNotFound,
+		wantBody: "404 page not found\n",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cacheErr = test.cacheErr
+			resp := httptest.NewRecorder()
+			h.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, test.path, nil))
+			if resp.Code != test.wantCode {
+				t.Errorf("Code = %d, want: %d", resp.Code, test.wantCode)
+			}
+			if got := resp.Body.String(); got != test.wantBody {
+				t.Errorf("Body = %q, want: %q", got, test.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package imagepolicy checks the images of Revisions against an image
+// policy, e.g. that they are signed, before the Revisions are admitted.
+package imagepolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+// Decision is the verdict of a Checker on a set of images.
+type Decision struct {
+	// Allowed is whether the images may run.
+	Allowed bool
+	// Reason explains why the images may not run.
+	Reason string
+}
+
+// Checker checks images against an image policy. It is the extension point
+// of the webhook for image policies, e.g. an external policy service or the
+// verification of image signatures.
+type Checker interface {
+	// Check decides whether the images of a Revision in namespace may run.
+	Check(ctx context.Context, namespace string, images []string) (*Decision, error)
+}
+
+type checkerKey struct{}
+
+// WithChecker attaches c to ctx, for the Revisions validated with the
+// returned context to have their images checked.
+func WithChecker(ctx context.Context, c Checker) context.Context {
+	return context.WithValue(ctx, checkerKey{}, c)
+}
+
+// GetChecker returns the Checker attached to ctx, or nil.
+func GetChecker(ctx context.Context) Checker {
+	c, _ := ctx.Value(checkerKey{}).(Checker)
+	return c
+}
+
+// Validate checks images, those of a Revision being created in namespace,
+// against the allowed registries of namespace, then with the Checker
+// attached to ctx according to the enforcement mode of namespace. Images
+// are not checked by a Checker when none is attached.
+func Validate(ctx context.Context, namespace string, images []string) *apis.FieldError {
+	if err := validateRegistries(ctx, namespace, images); err != nil {
+		return err
+	}
+	checker := GetChecker(ctx)
+	if checker == nil || len(images) == 0 {
+		return nil
+	}
+	mode := config.FromContextOrDefaults(ctx).ImagePolicy.EnforcementModeFor(namespace)
+	if mode == config.DisabledEnforcement {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx)
+	decision, err := checker.Check(ctx, namespace, images)
+	if err != nil {
+		if mode != config.Enforce {
+			logger.Warnf("Failed to check images %v against the image policy: %v", images, err)
+			return nil
+		}
+		return &apis.FieldError{
+			Message: "Failed to check the images against the image policy",
+			Paths:   []string{"spec"},
+			Details: err.Error(),
+		}
+	}
+	if decision.Allowed {
+		return nil
+	}
+	if mode != config.Enforce {
+		logger.Warnf("Images %v are rejected by the image policy: %s", images, decision.Reason)
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Images rejected by the image policy: %s", strings.Join(images, ", ")),
+		Paths:   []string{"spec"},
+		Details: decision.Reason,
+	}
+}
+
+// validateRegistries rejects the images not coming from the allowed
+// registries of namespace, regardless of the enforcement mode.
+func validateRegistries(ctx context.Context, namespace string, images []string) *apis.FieldError {
+	allowed := config.FromContextOrDefaults(ctx).ImagePolicy.AllowedRegistriesFor(namespace)
+	if len(allowed) == 0 {
+		return nil
+	}
+	var rejected []string
+	for _, image := range images {
+		if !registryAllowed(allowed, image) {
+			rejected = append(rejected, image)
+		}
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Images not from an allowed registry: %s", strings.Join(rejected, ", ")),
+		Paths:   []string{"spec"},
+		Details: fmt.Sprintf("Images must come from one of: %s", strings.Join(allowed, ", ")),
+	}
+}
+
+// registryAllowed returns whether the repository of image is one of allowed,
+// or under one of them.
+func registryAllowed(allowed []string, image string) bool {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return false
+	}
+	repo := ref.Context().Name()
+	for _, a := range allowed {
+		if a == config.AnyRegistry || repo == a || strings.HasPrefix(repo, a+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/pkg/apis"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+type fakeChecker struct {
+	decision *Decision
+	err      error
+
+	checked []string
+}
+
+func (fc *fakeChecker) Check(_ context.Context, _ string, images []string) (*Decision, error) {
+	fc.checked = images
+	return fc.decision, fc.err
+}
+
+func TestValidate(t *testing.T) {
+	images := []string{"gcr.io/repo/image:latest", "gcr.io/repo/sidecar"}
+	allowed := &Decision{Allowed: true}
+	rejected := &Decision{Reason: "image is not signed"}
+
+	tests := []struct {
+		name        string
+		namespace   string
+		checker     *fakeChecker
+		want        *apis.FieldError
+		wantChecked bool
+	}{{
+		name:      "no checker",
+		namespace: "production",
+	}, {
+		name:        "allowed",
+		namespace:   "production",
+		checker:     &fakeChecker{decision: allowed},
+		wantChecked: true,
+	}, {
+		name:        "rejected",
+		namespace:   "production",
+		checker:     &fakeChecker{decision: rejected},
+		wantChecked: true,
+		want: &apis.FieldError{
+			Message: "Images rejected by the image policy: gcr.io/repo/image:latest, gcr.io/repo/sidecar",
+			Paths:   []string{"spec"},
+			Details: "image is not signed",
+		},
+	}, {
+		name:        "check fails",
+		namespace:   "production",
+		checker:     &fakeChecker{err: errors.New("connection refused")},
+		wantChecked: true,
+		want: &apis.FieldError{
+			Message: "Failed to check the images against the image policy",
+			Paths:   []string{"spec"},
+			Details: "connection refused",
+		},
+	}, {
+		name:        "rejected, warn",
+		namespace:   "staging",
+		checker:     &fakeChecker{decision: rejected},
+		wantChecked: true,
+	}, {
+		name:        "check fails, warn",
+		namespace:   "staging",
+		checker:     &fakeChecker{err: errors.New("connection refused")},
+		wantChecked: true,
+	}, {
+		name:      "disabled",
+		namespace: "sandbox",
+		checker:   &fakeChecker{decision: rejected},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer logtesting.ClearAll()
+			ctx := config.ToContext(logtesting.TestContextWithLogger(t), &config.Config{
+				ImagePolicy: &config.ImagePolicy{
+					EnforcementMode: config.WarnEnforcement,
+					NamespaceEnforcementModes: map[string]config.EnforcementMode{
+						"production": config.Enforce,
+						"sandbox":    config.DisabledEnforcement,
+					},
+				},
+			})
+			if test.checker != nil {
+				ctx = WithChecker(ctx, test.checker)
+			}
+
+			got := Validate(ctx, test.namespace, images)
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("Validate() (-want, +got) = %v", cmp.Diff(test.want.Error(), got.Error()))
+			}
+			if test.checker == nil {
+				return
+			}
+			if checked := test.checker.checked != nil; checked != test.wantChecked {
+				t.Errorf("Checked = %v, want: %v", checked, test.wantChecked)
+			}
+		})
+	}
+}
+
+func TestValidateRegistries(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		images    []string
+		checker   *fakeChecker
+		want      *apis.FieldError
+	}{{
+		name:      "allowed registry",
+		namespace: "production",
+		images:    []string{"gcr.io/repo/image:latest", "gcr.io/repo/sidecar@sha256:" + strings.Repeat("a", 64)},
+	}, {
+		name:      "allowed repository",
+		namespace: "production",
+		images:    []string{"ubuntu", "docker.io/library/busybox:1.31"},
+	}, {
+		name:      "not an allowed repository",
+		namespace: "production",
+		images:    []string{"gcr.io/repo/image", "gcr.io/repository/image", "evil/ubuntu"},
+		want: &apis.FieldError{
+			Message: "Images not from an allowed registry: gcr.io/repository/image, evil/ubuntu",
+			Paths:   []string{"spec"},
+			Details: "Images must come from one of: gcr.io/repo, index.docker.io/library",
+		},
+	}, {
+		name:      "rejected regardless of the checker",
+		namespace: "production",
+		images:    []string{"quay.io/repo/image"},
+		checker:   &fakeChecker{decision: &Decision{Allowed: true}},
+		want: &apis.FieldError{
+			Message: "Images not from an allowed registry: quay.io/repo/image",
+			Paths:   []string{"spec"},
+			Details: "Images must come from one of: gcr.io/repo, index.docker.io/library",
+		},
+	}, {
+		name:      "namespace registries",
+		namespace: "team",
+		images:    []string{"quay.io/team/image"},
+	}, {
+		name:      "namespace exception",
+		namespace: "sandbox",
+		images:    []string{"evil/ubuntu"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := config.ToContext(context.Background(), &config.Config{
+				ImagePolicy: &config.ImagePolicy{
+					EnforcementMode:   config.DisabledEnforcement,
+					AllowedRegistries: []string{"gcr.io/repo", "index.docker.io/library"},
+					NamespaceAllowedRegistries: map[string][]string{
+						"team":    {"quay.io/team"},
+						"sandbox": {config.AnyRegistry},
+					},
+				},
+			})
+			if test.checker != nil {
+				ctx = WithChecker(ctx, test.checker)
+			}
+
+			got := Validate(ctx, test.namespace, test.images)
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("Validate() (-want, +got) = %v", cmp.Diff(test.want.Error(), got.Error()))
+			}
+		})
+	}
+}
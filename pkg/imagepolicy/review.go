@@ -0,0 +1,132 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"knative.dev/serving/pkg/apis/config"
+)
+
+const (
+	imageReviewAPIVersion = "imagepolicy.k8s.io/v1alpha1"
+	imageReviewKind       = "ImageReview"
+
+	// maxReviewSize is the maximum size of an ImageReview response read.
+	maxReviewSize = 1 << 20
+)
+
+// imageReview is the subset of the ImageReview of the imagepolicy.k8s.io
+// API group the policy service is sent, the API the ImagePolicyWebhook
+// admission plugin of Kubernetes uses.
+type imageReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Spec       imageReviewSpec    `json:"spec"`
+	Status     *imageReviewStatus `json:"status,omitempty"`
+}
+
+type imageReviewSpec struct {
+	Containers []imageReviewContainerSpec `json:"containers,omitempty"`
+	Namespace  string                     `json:"namespace,omitempty"`
+}
+
+type imageReviewContainerSpec struct {
+	Image string `json:"image,omitempty"`
+}
+
+type imageReviewStatus struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type reviewChecker struct {
+	client *http.Client
+}
+
+var _ Checker = (*reviewChecker)(nil)
+
+// NewReviewChecker creates a Checker that posts an ImageReview to the policy
+// service of the image policy configuration in the context of the checks.
+func NewReviewChecker(transport http.RoundTripper) Checker {
+	return &reviewChecker{
+		client: &http.Client{Transport: transport},
+	}
+}
+
+// Check implements Checker.
+func (rc *reviewChecker) Check(ctx context.Context, namespace string, images []string) (*Decision, error) {
+	cfg := config.FromContextOrDefaults(ctx).ImagePolicy
+	if cfg.PolicyServiceURL == "" {
+		return nil, errors.New("no policy-service-url is configured")
+	}
+
+	review := imageReview{
+		APIVersion: imageReviewAPIVersion,
+		Kind:       imageReviewKind,
+		Spec: imageReviewSpec{
+			Containers: make([]imageReviewContainerSpec, 0, len(images)),
+			Namespace:  namespace,
+		},
+	}
+	for _, image := range images {
+		review.Spec.Containers = append(review.Spec.Containers, imageReviewContainerSpec{Image: image})
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, cfg.PolicyServiceURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := rc.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxReviewSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ImageReview: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy service responded with status %d: %s", resp.StatusCode, body)
+	}
+	var reviewed imageReview
+	if err := json.Unmarshal(body, &reviewed); err != nil {
+		return nil, fmt.Errorf("failed to decode the ImageReview: %v", err)
+	}
+	if reviewed.Status == nil {
+		return nil, errors.New("ImageReview has no status")
+	}
+	return &Decision{
+		Allowed: reviewed.Status.Allowed,
+		Reason:  reviewed.Status.Reason,
+	}, nil
+}
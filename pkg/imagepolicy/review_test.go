@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imagepolicy
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+func reviewContext(url string) context.Context {
+	return config.ToContext(context.Background(), &config.Config{
+		ImagePolicy: &config.ImagePolicy{
+			EnforcementMode:  config.Enforce,
+			PolicyServiceURL: url,
+			Timeout:          100 * time.Millisecond,
+		},
+	})
+}
+
+func TestReviewChecker(t *testing.T) {
+	var got imageReview
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode the ImageReview: %v", err)
+		}
+		got.Status = &imageReviewStatus{Reason: "image is not signed"}
+		json.NewEncoder(w).Encode(got)
+	}))
+	defer server.Close()
+
+	checker := NewReviewChecker(http.DefaultTransport)
+	decision, err := checker.Check(reviewContext(server.URL), "production", []string{"gcr.io/repo/image"})
+	if err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+
+	if want := (&Decision{Reason: "image is not signed"}); !cmp.Equal(decision, want) {
+		t.Errorf("Check() (-want, +got) = %v", cmp.Diff(want, decision))
+	}
+	want := imageReview{
+		APIVersion: "imagepolicy.k8s.io/v1alpha1",
+		Kind:       "ImageReview",
+		Spec: imageReviewSpec{
+			Containers: []imageReviewContainerSpec{{Image: "gcr.io/repo/image"}},
+			Namespace:  "production",
+		},
+		Status: &imageReviewStatus{Reason: "image is not signed"},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("ImageReview (-want, +got) = %v", cmp.Diff(want, got))
+	}
+}
+
+func TestReviewCheckerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{{
+		name: "server error",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		},
+	}, {
+		name: "not json",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("allowed"))
+		},
+	}, {
+		name: "no status",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"apiVersion":"imagepolicy.k8s.io/v1alpha1","kind":"ImageReview"}`))
+		},
+	}, {
+		name: "timeout",
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			// The context of the request is only cancelled once its body is read.
+			ioutil.ReadAll(r.Body)
+			<-r.Context().Done()
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			checker := NewReviewChecker(http.DefaultTransport)
+			if _, err := checker.Check(reviewContext(server.URL), "production", []string{"gcr.io/repo/image"}); err == nil {
+				t.Error("Check() = nil, wanted an error")
+			}
+		})
+	}
+}
+
+func TestReviewCheckerNoURL(t *testing.T) {
+	checker := NewReviewChecker(http.DefaultTransport)
+	if _, err := checker.Check(reviewContext(""), "production", []string{"gcr.io/repo/image"}); err == nil {
+		t.Error("Check() = nil, wanted an error")
+	}
+}
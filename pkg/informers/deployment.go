@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// digestPrefix marks the trimmed last applied specs, which can't be
+// mistaken for JSON.
+const digestPrefix = "sha256:"
+
+// NewDeploymentInformer creates an informer of the Deployments of all the
+// namespaces, paged and trimmed by TrimDeployment.
+func NewDeploymentInformer(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		NewListWatch(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return client.AppsV1().Deployments(metav1.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return client.AppsV1().Deployments(metav1.NamespaceAll).Watch(options)
+			},
+		}, TrimDeployment),
+		&appsv1.Deployment{},
+		resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
+
+// TrimDeployment replaces the last applied spec of Deployments, which is
+// as large as their spec, with its digest. RestoreLastAppliedSpec restores
+// it.
+func TrimDeployment(obj runtime.Object) runtime.Object {
+	d, ok := obj.(*appsv1.Deployment)
+	if !ok {
+		return obj
+	}
+	if last, ok := d.Annotations[serving.LastAppliedSpecAnnotationKey]; ok && !strings.HasPrefix(last, digestPrefix) {
+		d.Annotations[serving.LastAppliedSpecAnnotationKey] = digest([]byte(last))
+	}
+	return d
+}
+
+// RestoreLastAppliedSpec returns d with the last applied spec trimmed by
+// TrimDeployment restored, when spec, as JSON, is the last applied spec.
+// It returns false when it isn't, for the Deployment to be fetched in full.
+// Untrimmed Deployments are returned as is.
+func RestoreLastAppliedSpec(d *appsv1.Deployment, spec []byte) (*appsv1.Deployment, bool) {
+	last, ok := d.Annotations[serving.LastAppliedSpecAnnotationKey]
+	if !ok || !strings.HasPrefix(last, digestPrefix) {
+		return d, true
+	}
+	if last != digest(spec) {
+		return nil, false
+	}
+	d = d.DeepCopy()
+	d.Annotations[serving.LastAppliedSpecAnnotationKey] = string(spec)
+	return d, true
+}
+
+func digest(b []byte) string {
+	return fmt.Sprintf("%s%x", digestPrefix, sha256.Sum256(b))
+}
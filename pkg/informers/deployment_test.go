@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestTrimAndRestoreDeployment(t *testing.T) {
+	spec := `{"template":{"spec":{"containers":[{"image":"busybox"}]}}}`
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				serving.LastAppliedSpecAnnotationKey: spec,
+				"other":                              "kept",
+			},
+		},
+	}
+
+	trimmed := TrimDeployment(d.DeepCopy()).(*appsv1.Deployment)
+	if got := trimmed.Annotations[serving.LastAppliedSpecAnnotationKey]; !strings.HasPrefix(got, "sha256:") {
+		t.Errorf("Trimmed last applied spec = %q, wanted its digest", got)
+	}
+	if got, want := trimmed.Annotations["other"], "kept"; got != want {
+		t.Errorf("Annotation other = %q, want: %q", got, want)
+	}
+	// Trimming is idempotent, e.g. on resyncs.
+	if again := TrimDeployment(trimmed.DeepCopy()).(*appsv1.Deployment); again.Annotations[serving.LastAppliedSpecAnnotationKey] != trimmed.Annotations[serving.LastAppliedSpecAnnotationKey] {
+		t.Error("TrimDeployment() trimmed the digest")
+	}
+
+	restored, ok := RestoreLastAppliedSpec(trimmed, []byte(spec))
+	if !ok {
+		t.Fatal("RestoreLastAppliedSpec() = false, wanted the spec restored")
+	}
+	if got := restored.Annotations[serving.LastAppliedSpecAnnotationKey]; got != spec {
+		t.Errorf("Restored last applied spec = %q, want: %q", got, spec)
+	}
+	if trimmed.Annotations[serving.LastAppliedSpecAnnotationKey] == spec {
+		t.Error("RestoreLastAppliedSpec() modified the cached Deployment")
+	}
+
+	if _, ok := RestoreLastAppliedSpec(trimmed, []byte(`{"replicas":1}`)); ok {
+		t.Error("RestoreLastAppliedSpec() = true for another spec")
+	}
+
+	// Untrimmed Deployments are returned as is.
+	if got, ok := RestoreLastAppliedSpec(d, []byte(`{"replicas":1}`)); !ok || got != d {
+		t.Errorf("RestoreLastAppliedSpec() = %v, %v, wanted the untrimmed deployment", got, ok)
+	}
+}
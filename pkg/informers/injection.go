@@ -0,0 +1,41 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"knative.dev/pkg/injection"
+	kubefactory "knative.dev/pkg/injection/informers/kubeinformers/factory"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servingfactory "knative.dev/serving/pkg/client/injection/informers/serving/factory"
+)
+
+func init() {
+	// The informer factories this package imports are registered before
+	// it, and the informers are only created once all the factories are.
+	injection.Default.RegisterInformerFactory(withInformers)
+}
+
+// withInformers creates the lightened informers in the informer factories
+// of ctx, for the informers injected from them to be these.
+func withInformers(ctx context.Context) context.Context {
+	kubefactory.Get(ctx).InformerFor(&appsv1.Deployment{}, NewDeploymentInformer)
+	servingfactory.Get(ctx).InformerFor(&v1alpha1.Revision{}, NewRevisionInformer)
+	return ctx
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/injection"
+)
+
+// Feature names an optional subsystem, whose informers are only started
+// once it is used.
+type Feature string
+
+const (
+	// HPA is the autoscaling of PodAutoscalers of the HPA class.
+	HPA Feature = "hpa"
+	// Certificates is the provisioning of Certificates for Routes, when
+	// auto TLS is enabled.
+	Certificates Feature = "certificates"
+	// CertManager is the provisioning of Certificates by cert-manager.
+	CertManager Feature = "cert-manager"
+	// Istio is the programming of Istio for Ingresses of the Istio class.
+	Istio Feature = "istio"
+)
+
+// SyncTimeout is how long the informers of a Feature have to sync once
+// they are started.
+const SyncTimeout = time.Minute
+
+type lazyKey struct {
+	feature Feature
+}
+
+// Lazy holds the informers of a Feature until the Feature is used. Until
+// then they are reported synced, and their listers are empty.
+type Lazy struct {
+	feature   Feature
+	m         sync.Mutex
+	informers []cache.SharedIndexInformer
+	start     chan struct{}
+	once      sync.Once
+}
+
+// RegisterLazy registers with injection the informers of feature created
+// by register in the informer factories of ctx, wrapped by Lazy.Wrap.
+// The packages of the factories must be imported by the caller, for the
+// factories to be registered first.
+func RegisterLazy(feature Feature, register func(ctx context.Context, lazy *Lazy)) {
+	injection.Default.RegisterInformerFactory(func(ctx context.Context) context.Context {
+		lazy, ok := ctx.Value(lazyKey{feature}).(*Lazy)
+		if !ok {
+			lazy = &Lazy{feature: feature, start: make(chan struct{})}
+			ctx = context.WithValue(ctx, lazyKey{feature}, lazy)
+		}
+		register(ctx, lazy)
+		return ctx
+	})
+}
+
+// GetLazy returns the Lazy of feature in ctx. It is nil, with the informers
+// of feature started like the others, when none was registered, e.g. in
+// tests.
+func GetLazy(ctx context.Context, feature Feature) *Lazy {
+	lazy, _ := ctx.Value(lazyKey{feature}).(*Lazy)
+	return lazy
+}
+
+// Wrap returns inf wrapped to only run once the Lazy is started.
+func (l *Lazy) Wrap(inf cache.SharedIndexInformer) cache.SharedIndexInformer {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.informers = append(l.informers, inf)
+	return &lazyInformer{SharedIndexInformer: inf, lazy: l}
+}
+
+// Start starts the informers of the Lazy, the first time it is called, and
+// waits for them to be synced.
+func (l *Lazy) Start() error {
+	if l == nil {
+		return nil
+	}
+	l.once.Do(func() {
+		close(l.start)
+	})
+
+	stopCh := make(chan struct{})
+	timer := time.AfterFunc(SyncTimeout, func() { close(stopCh) })
+	defer timer.Stop()
+	l.m.Lock()
+	synced := make([]cache.InformerSynced, 0, len(l.informers))
+	for _, inf := range l.informers {
+		synced = append(synced, inf.HasSynced)
+	}
+	l.m.Unlock()
+	if !cache.WaitForCacheSync(stopCh, synced...) {
+		return fmt.Errorf("the informers of %s failed to sync", l.feature)
+	}
+	return nil
+}
+
+func (l *Lazy) started() bool {
+	select {
+	case <-l.start:
+		return true
+	default:
+		return false
+	}
+}
+
+// lazyInformer runs the informer it wraps once its Lazy is started.
+type lazyInformer struct {
+	cache.SharedIndexInformer
+	lazy *Lazy
+}
+
+// Run implements cache.SharedIndexInformer
+func (i *lazyInformer) Run(stopCh <-chan struct{}) {
+	select {
+	case <-i.lazy.start:
+		i.SharedIndexInformer.Run(stopCh)
+	case <-stopCh:
+	}
+}
+
+// HasSynced implements cache.SharedIndexInformer
+func (i *lazyInformer) HasSynced() bool {
+	return !i.lazy.started() || i.SharedIndexInformer.HasSynced()
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestLazy(t *testing.T) {
+	var lists int32
+	lw := &cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			atomic.AddInt32(&lists, 1)
+			return &appsv1.DeploymentList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+				Items:    deployments(3),
+			}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	}
+
+	lazy := &Lazy{feature: HPA, start: make(chan struct{})}
+	inf := lazy.Wrap(cache.NewSharedIndexInformer(lw, &appsv1.Deployment{}, 0, cache.Indexers{}))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go inf.Run(stopCh)
+
+	if !inf.HasSynced() {
+		t.Error("HasSynced() = false before Start, want true")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := atomic.LoadInt32(&lists); got != 0 {
+		t.Fatalf("Lists before Start = %d, want 0", got)
+	}
+
+	if err := lazy.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got := atomic.LoadInt32(&lists); got != 1 {
+		t.Errorf("Lists after Start = %d, want 1", got)
+	}
+	if got := len(inf.GetStore().List()); got != 3 {
+		t.Errorf("len(List()) = %d, want 3", got)
+	}
+
+	// Starting again is a no-op.
+	if err := lazy.Start(); err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	if got := atomic.LoadInt32(&lists); got != 1 {
+		t.Errorf("Lists after second Start = %d, want 1", got)
+	}
+}
+
+func TestLazyStopped(t *testing.T) {
+	lazy := &Lazy{feature: HPA, start: make(chan struct{})}
+	inf := lazy.Wrap(cache.NewSharedIndexInformer(&cache.ListWatch{}, &appsv1.Deployment{}, 0, cache.Indexers{}))
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		inf.Run(stopCh)
+		close(done)
+	}()
+	close(stopCh)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return once stopped")
+	}
+}
+
+func TestLazyNil(t *testing.T) {
+	var lazy *Lazy
+	if err := lazy.Start(); err != nil {
+		t.Errorf("Start() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package informers lightens the informers of the controllers: their
+// initial lists are paged, the objects are trimmed of what the reconcilers
+// don't read before they are cached, and the informers of optional
+// features are only started once the features are used.
+package informers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/pager"
+)
+
+// PageSize is the number of objects listed at once by the informers.
+const PageSize = 500
+
+// TransformFunc returns obj as it is to be cached. It may modify obj.
+type TransformFunc func(obj runtime.Object) runtime.Object
+
+// NewListWatch returns a ListerWatcher listing the objects of lw in pages
+// of PageSize, and transforming the listed and watched objects with
+// transform, when not nil.
+func NewListWatch(lw cache.ListerWatcher, transform TransformFunc) cache.ListerWatcher {
+	return &listWatch{lw: lw, transform: transform}
+}
+
+type listWatch struct {
+	lw        cache.ListerWatcher
+	transform TransformFunc
+}
+
+// List implements cache.ListerWatcher
+func (l *listWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	// The lists served from the cache of the API server, at resource
+	// version "0", aren't paged.
+	if options.ResourceVersion == "0" {
+		options.ResourceVersion = ""
+	}
+	p := pager.New(pager.SimplePageFunc(l.lw.List))
+	p.PageSize = PageSize
+	list, err := p.List(context.Background(), options)
+	if err != nil || l.transform == nil {
+		return list, err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = l.transform(item)
+	}
+	return list, meta.SetList(list, items)
+}
+
+// Watch implements cache.ListerWatcher
+func (l *listWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	w, err := l.lw.Watch(options)
+	if err != nil || l.transform == nil {
+		return w, err
+	}
+	return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+		if event.Type != watch.Error {
+			event.Object = l.transform(event.Object)
+		}
+		return event, true
+	}), nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informers
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func deployments(n int) []appsv1.Deployment {
+	ds := make([]appsv1.Deployment, n)
+	for i := range ds {
+		ds[i].Name = fmt.Sprintf("deployment-%d", i)
+	}
+	return ds
+}
+
+// pagedListWatch serves the deployments in pages, as the API server does.
+type pagedListWatch struct {
+	deployments []appsv1.Deployment
+	options     []metav1.ListOptions
+	watcher     *watch.FakeWatcher
+}
+
+func (p *pagedListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	p.options = append(p.options, options)
+	start, _ := strconv.Atoi(options.Continue)
+	end := len(p.deployments)
+	if options.Limit > 0 && start+int(options.Limit) < end {
+		end = start + int(options.Limit)
+	}
+	list := &appsv1.DeploymentList{
+		ListMeta: metav1.ListMeta{ResourceVersion: "42"},
+		Items:    append([]appsv1.Deployment(nil), p.deployments[start:end]...),
+	}
+	if end < len(p.deployments) {
+		list.Continue = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+func (p *pagedListWatch) Watch(options metav1.ListOptions) (watch.Interface, error) {
+	return p.watcher, nil
+}
+
+func names(t *testing.T, list runtime.Object) []string {
+	t.Helper()
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		t.Fatalf("ExtractList() = %v", err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.(metav1.Object).GetName())
+	}
+	return names
+}
+
+func TestListPaged(t *testing.T) {
+	lw := &pagedListWatch{deployments: deployments(2*PageSize + 1)}
+	list, err := NewListWatch(lw, nil).List(metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if got, want := len(lw.options), 3; got != want {
+		t.Errorf("Pages = %d, want: %d", got, want)
+	}
+	for _, options := range lw.options {
+		if options.Limit != PageSize || options.ResourceVersion != "" {
+			t.Errorf("ListOptions = %#v, want a page of %d at the latest resource version", options, PageSize)
+		}
+	}
+	if got, want := len(names(t, list)), 2*PageSize+1; got != want {
+		t.Errorf("Items = %d, want: %d", got, want)
+	}
+	accessor, err := meta.ListAccessor(list)
+	if err != nil {
+		t.Fatalf("ListAccessor() = %v", err)
+	}
+	if got, want := accessor.GetResourceVersion(), "42"; got != want {
+		t.Errorf("ResourceVersion = %q, want: %q", got, want)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	lw := &pagedListWatch{
+		deployments: deployments(3),
+		watcher:     watch.NewFake(),
+	}
+	rename := func(obj runtime.Object) runtime.Object {
+		d := obj.(*appsv1.Deployment)
+		d.Name = "transformed-" + d.Name
+		return d
+	}
+	transformed := NewListWatch(lw, rename)
+
+	list, err := transformed.List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	want := []string{"transformed-deployment-0", "transformed-deployment-1", "transformed-deployment-2"}
+	if diff := cmp.Diff(want, names(t, list)); diff != "" {
+		t.Errorf("List() (-want, +got) = %s", diff)
+	}
+
+	w, err := transformed.Watch(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	defer w.Stop()
+	go lw.watcher.Add(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "added"}})
+	event := <-w.ResultChan()
+	if got, want := event.Object.(*appsv1.Deployment).Name, "transformed-added"; got != want {
+		t.Errorf("Watched %q, want: %q", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+
+	"knative.dev/pkg/logging"
+)
+
+// LevelOverride returns the log level config sets for the component in the
+// namespace, with a `loglevel.<component>.<namespace>` key, or for the
+// revision in it, with a `loglevel.<component>.<namespace>.<revision>` key.
+// The level of the revision takes precedence over that of its namespace.
+func LevelOverride(config *logging.Config, component, namespace, revision string) (zapcore.Level, bool) {
+	if config == nil || namespace == "" {
+		return zapcore.InfoLevel, false
+	}
+	key := component + "." + namespace
+	if revision != "" {
+		if level, ok := config.LoggingLevel[key+"."+revision]; ok {
+			return level, true
+		}
+	}
+	level, ok := config.LoggingLevel[key]
+	return level, ok
+}
+
+// LevelOverrides enables the log levels config-logging sets per namespace
+// and per revision for a component, on top of the level of the component.
+// Since a logger only knows about a single level, the atomic level of the
+// logger is lowered to the most verbose of them, and the loggers wrapped by
+// the LevelOverrides drop the entries below the level of their revision.
+type LevelOverrides struct {
+	component   string
+	atomicLevel zap.AtomicLevel
+
+	mu     sync.RWMutex
+	config *logging.Config
+	level  zapcore.Level
+}
+
+// NewLevelOverrides creates a LevelOverrides of the component, whose
+// loggers share atomicLevel, and applies config to them.
+func NewLevelOverrides(config *logging.Config, component string, atomicLevel zap.AtomicLevel) *LevelOverrides {
+	o := &LevelOverrides{
+		component:   component,
+		atomicLevel: atomicLevel,
+		level:       atomicLevel.Level(),
+	}
+	o.Update(config)
+	return o
+}
+
+// Update applies config to the loggers of the LevelOverrides.
+func (o *LevelOverrides) Update(config *logging.Config) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.config = config
+	if level, ok := config.LoggingLevel[o.component]; ok {
+		o.level = level
+	}
+	min := o.level
+	prefix := o.component + "."
+	for key, level := range config.LoggingLevel {
+		if strings.HasPrefix(key, prefix) && level < min {
+			min = level
+		}
+	}
+	o.atomicLevel.SetLevel(min)
+}
+
+// UpdateFromConfigMap returns a helper func that can be used to update the
+// log levels when the logging config map is updated.
+func (o *LevelOverrides) UpdateFromConfigMap(logger *zap.SugaredLogger) func(configMap *corev1.ConfigMap) {
+	return func(configMap *corev1.ConfigMap) {
+		config, err := NewConfigFromConfigMap(configMap)
+		if err != nil {
+			logger.Errorw("Failed to parse the logging configmap. Previous config map will be used.", zap.Error(err))
+			return
+		}
+		o.Update(config)
+	}
+}
+
+// Level returns the log level of the revision in the namespace. Empty
+// names stand for the component as a whole.
+func (o *LevelOverrides) Level(namespace, revision string) zapcore.Level {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if level, ok := LevelOverride(o.config, o.component, namespace, revision); ok {
+		return level
+	}
+	return o.level
+}
+
+// Wrap returns logger logging at the level of the component. The loggers
+// derived from it with ForRevision log at the level of their revision.
+func (o *LevelOverrides) Wrap(logger *zap.SugaredLogger) *zap.SugaredLogger {
+	return logger.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, overrides: o}
+	})).Sugar()
+}
+
+// ForRevision returns logger logging at the level of the revision in the
+// namespace, if logger was wrapped by a LevelOverrides, and logger as is
+// otherwise.
+func ForRevision(logger *zap.SugaredLogger, namespace, revision string) *zap.SugaredLogger {
+	l := logger.Desugar()
+	lc, ok := l.Core().(*levelCore)
+	if !ok {
+		return logger
+	}
+	return l.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return &levelCore{
+			Core:      lc.Core,
+			overrides: lc.overrides,
+			namespace: namespace,
+			revision:  revision,
+		}
+	})).Sugar()
+}
+
+// levelCore drops the entries below the level of its revision, before they
+// reach the core it wraps.
+type levelCore struct {
+	zapcore.Core
+	overrides *LevelOverrides
+	namespace string
+	revision  string
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return level >= c.overrides.Level(c.namespace, c.revision) && c.Core.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{
+		Core:      c.Core.With(fields),
+		overrides: c.overrides,
+		namespace: c.namespace,
+		revision:  c.revision,
+	}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/pkg/logging"
+)
+
+func TestLevelOverride(t *testing.T) {
+	config := &logging.Config{
+		LoggingLevel: map[string]zapcore.Level{
+			"activator":         zapcore.InfoLevel,
+			"activator.ns":      zapcore.WarnLevel,
+			"activator.ns.rev":  zapcore.DebugLevel,
+			"queueproxy.ns.rev": zapcore.ErrorLevel,
+		},
+	}
+	tests := []struct {
+		name      string
+		component string
+		namespace string
+		revision  string
+		want      zapcore.Level
+		wantOK    bool
+	}{{
+		name:      "revision",
+		component: "activator",
+		namespace: "ns",
+		revision:  "rev",
+		want:      zapcore.DebugLevel,
+		wantOK:    true,
+	}, {
+		name:      "namespace",
+		component: "activator",
+		namespace: "ns",
+		revision:  "other",
+		want:      zapcore.WarnLevel,
+		wantOK:    true,
+	}, {
+		name:      "other namespace",
+		component: "activator",
+		namespace: "other",
+		revision:  "rev",
+	}, {
+		name:      "component",
+		component: "activator",
+	}, {
+		name:      "other component",
+		component: "queueproxy",
+		namespace: "ns",
+		revision:  "rev",
+		want:      zapcore.ErrorLevel,
+		wantOK:    true,
+	}, {
+		name:      "no namespace override",
+		component: "queueproxy",
+		namespace: "ns",
+		revision:  "other",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := LevelOverride(config, test.component, test.namespace, test.revision)
+			if ok != test.wantOK {
+				t.Fatalf("LevelOverride() ok = %v, want %v", ok, test.wantOK)
+			}
+			if ok && got != test.want {
+				t.Errorf("LevelOverride() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLevelOverrides(t *testing.T) {
+	atomicLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	logs := &recorder{}
+	core := &recordingCore{LevelEnabler: atomicLevel, recorder: logs}
+	overrides := NewLevelOverrides(&logging.Config{}, "activator", atomicLevel)
+	logger := overrides.Wrap(zap.New(core).Sugar())
+	revLogger := ForRevision(logger.With("key", "value"), "ns", "rev")
+
+	logger.Debug("component")
+	revLogger.Debug("revision")
+	if got := logs.TakeAll(); len(got) != 0 {
+		t.Errorf("Got %d debug logs without overrides, want none", len(got))
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config-logging"},
+		Data: map[string]string{
+			"loglevel.activator":        "warn",
+			"loglevel.activator.ns.rev": "debug",
+		},
+	}
+	overrides.UpdateFromConfigMap(logger)(cm)
+	if got, want := atomicLevel.Level(), zapcore.DebugLevel; got != want {
+		t.Errorf("atomicLevel = %v, want %v", got, want)
+	}
+
+	logger.Info("component")
+	ForRevision(logger, "ns", "other").Info("other revision")
+	revLogger.Debug("revision")
+	got := logs.TakeAll()
+	if len(got) != 1 {
+		t.Fatalf("Got %d logs, want 1: %v", len(got), got)
+	}
+	if got[0].message != "revision" {
+		t.Errorf("Message = %q, want %q", got[0].message, "revision")
+	}
+	if got[0].fields != 1 {
+		t.Errorf("Got %d fields, want 1", got[0].fields)
+	}
+
+	// An invalid config keeps the previous one.
+	cm.Data["loglevel.activator.ns"] = "loud"
+	overrides.UpdateFromConfigMap(logger)(cm)
+	if got, want := overrides.Level("ns", "rev"), zapcore.DebugLevel; got != want {
+		t.Errorf("Level() = %v, want %v", got, want)
+	}
+	if got := logs.TakeAll(); len(got) != 1 {
+		t.Errorf("Got %d logs of the invalid config, want 1", len(got))
+	}
+
+	delete(cm.Data, "loglevel.activator.ns")
+	delete(cm.Data, "loglevel.activator.ns.rev")
+	overrides.UpdateFromConfigMap(logger)(cm)
+	if got, want := atomicLevel.Level(), zapcore.WarnLevel; got != want {
+		t.Errorf("atomicLevel = %v, want %v", got, want)
+	}
+	revLogger.Info("revision")
+	if got := logs.TakeAll(); len(got) != 0 {
+		t.Errorf("Got %d info logs at the warn level, want none", len(got))
+	}
+}
+
+func TestForRevisionUnwrapped(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	if got := ForRevision(logger, "ns", "rev"); got != logger {
+		t.Error("ForRevision() of an unwrapped logger should return it as is")
+	}
+}
+
+type entry struct {
+	message string
+	fields  int
+}
+
+type recorder struct {
+	entries []entry
+}
+
+func (r *recorder) TakeAll() []entry {
+	entries := r.entries
+	r.entries = nil
+	return entries
+}
+
+// recordingCore records the messages it's given and the number of fields
+// they come with.
+type recordingCore struct {
+	zapcore.LevelEnabler
+	recorder *recorder
+	fields   []zapcore.Field
+}
+
+func (c *recordingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recordingCore{
+		LevelEnabler: c.LevelEnabler,
+		recorder:     c.recorder,
+		fields:       append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *recordingCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+func (c *recordingCore) Write(e zapcore.Entry, fields []zapcore.Field) error {
+	c.recorder.entries = append(c.recorder.entries, entry{
+		message: e.Message,
+		fields:  len(c.fields) + len(fields),
+	})
+	return nil
+}
+
+func (c *recordingCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"net/http"
+	"strings"
+)
+
+// forwardedHeaders are the headers proxies set to tell about the requests
+// they forward.
+var forwardedHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+}
+
+// SanitizeForwardedHeaders removes the forwarded headers of a request
+// received from the ingress that can't be trusted according to cfg: either
+// all of them, or the elements of X-Forwarded-For and Forwarded added
+// before the trusted hops, which the client could have made up.
+// Sanitizing the headers again leaves them unchanged.
+func SanitizeForwardedHeaders(h http.Header, cfg *Config) {
+	if cfg.ForwardedHeaders == ForwardedHeadersStrip {
+		for _, name := range forwardedHeaders {
+			h.Del(name)
+		}
+		return
+	}
+	hops := cfg.TrustedHops
+	if hops < 1 {
+		hops = DefaultTrustedHops
+	}
+	keepLastElements(h, "X-Forwarded-For", hops)
+	keepLastElements(h, "Forwarded", hops)
+}
+
+// keepLastElements folds the comma separated elements of the header into
+// one value, keeping only the last n of them.
+func keepLastElements(h http.Header, name string, n int) {
+	var elements []string
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, e := range strings.Split(v, ",") {
+			elements = append(elements, strings.TrimSpace(e))
+		}
+	}
+	if len(elements) > n {
+		h.Set(name, strings.Join(elements[len(elements)-n:], ", "))
+	}
+}
+
+// NewForwardedHeadersHandler wraps h so that the forwarded headers of the
+// requests it receives are sanitized according to the config returned by
+// cfg.
+func NewForwardedHeadersHandler(cfg func() *Config, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SanitizeForwardedHeaders(r.Header, cfg())
+		h.ServeHTTP(w, r)
+	})
+}
+
+// NewForwardedForHandler wraps a handler proxying requests with an
+// httputil.ReverseProxy, so that the X-Forwarded-For header of the requests
+// is treated according to the policy returned by policy.
+func NewForwardedForHandler(policy func() ForwardedForPolicy, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if policy() == ForwardedForPreserve && r.Header.Get("X-Forwarded-For") != "" {
+			// The proxy only appends the address of the peer it can parse,
+			// so hide it from a shallow copy of the request.
+			r = r.WithContext(r.Context())
+			r.RemoteAddr = ""
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestForwardedForHandler(t *testing.T) {
+	var got string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("X-Forwarded-For")
+	}))
+	defer backend.Close()
+	target, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+
+	tests := []struct {
+		name   string
+		policy ForwardedForPolicy
+		xff    string
+		want   string
+	}{{
+		name:   "append",
+		policy: ForwardedForAppend,
+		xff:    "192.0.2.1",
+		want:   "192.0.2.1, 10.0.0.1",
+	}, {
+		name:   "append without header",
+		policy: ForwardedForAppend,
+		want:   "10.0.0.1",
+	}, {
+		name:   "preserve",
+		policy: ForwardedForPreserve,
+		xff:    "192.0.2.1",
+		want:   "192.0.2.1",
+	}, {
+		name:   "preserve without header",
+		policy: ForwardedForPreserve,
+		want:   "10.0.0.1",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewForwardedForHandler(func() ForwardedForPolicy { return test.policy }, proxy)
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.RemoteAddr = "10.0.0.1:12345"
+			if test.xff != "" {
+				req.Header.Set("X-Forwarded-For", test.xff)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if got != test.want {
+				t.Errorf("X-Forwarded-For = %q, want: %q", got, test.want)
+			}
+			if req.RemoteAddr != "10.0.0.1:12345" {
+				t.Errorf("RemoteAddr = %q, want it untouched", req.RemoteAddr)
+			}
+		})
+	}
+}
+
+func TestSanitizeForwardedHeaders(t *testing.T) {
+	headers := func() http.Header {
+		return http.Header{
+			"Forwarded":         []string{"for=198.51.100.1", "for=192.0.2.1;proto=https, for=10.0.0.1"},
+			"X-Forwarded-For":   []string{"198.51.100.1, 192.0.2.1", "10.0.0.1"},
+			"X-Forwarded-Host":  []string{"example.com"},
+			"X-Forwarded-Proto": []string{"https"},
+			"User-Agent":        []string{"test"},
+		}
+	}
+
+	tests := []struct {
+		name string
+		cfg  *Config
+		want http.Header
+	}{{
+		name: "trust the ingress gateway by default",
+		cfg:  &Config{ForwardedHeaders: ForwardedHeadersTrust},
+		want: http.Header{
+			"Forwarded":         []string{"for=10.0.0.1"},
+			"X-Forwarded-For":   []string{"10.0.0.1"},
+			"X-Forwarded-Host":  []string{"example.com"},
+			"X-Forwarded-Proto": []string{"https"},
+			"User-Agent":        []string{"test"},
+		},
+	}, {
+		name: "trust two hops",
+		cfg:  &Config{ForwardedHeaders: ForwardedHeadersTrust, TrustedHops: 2},
+		want: http.Header{
+			"Forwarded":         []string{"for=192.0.2.1;proto=https, for=10.0.0.1"},
+			"X-Forwarded-For":   []string{"192.0.2.1, 10.0.0.1"},
+			"X-Forwarded-Host":  []string{"example.com"},
+			"X-Forwarded-Proto": []string{"https"},
+			"User-Agent":        []string{"test"},
+		},
+	}, {
+		name: "trust more hops than there are",
+		cfg:  &Config{ForwardedHeaders: ForwardedHeadersTrust, TrustedHops: 5},
+		want: headers(),
+	}, {
+		name: "strip",
+		cfg:  &Config{ForwardedHeaders: ForwardedHeadersStrip, TrustedHops: 2},
+		want: http.Header{
+			"User-Agent": []string{"test"},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := headers()
+			SanitizeForwardedHeaders(got, test.cfg)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("SanitizeForwardedHeaders (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+
+			// The queue-proxy sanitizes the requests the activator did again.
+			SanitizeForwardedHeaders(got, test.cfg)
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("SanitizeForwardedHeaders twice (-want, +got) = %s", cmp.Diff(test.want, got))
+			}
+		})
+	}
+}
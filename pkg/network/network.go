@@ -24,6 +24,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -35,9 +36,19 @@ const (
 	// ProbeHeaderName is the name of a header that can be added to
 	// requests to probe the knative networking layer.  Requests
 	// with this header will not be passed to the user container or
-	// included in request metrics.
+	// included in request metrics. The probes of the queue-proxy must
+	// also be signed, see SignProbeRequest.
 	ProbeHeaderName = "K-Network-Probe"
 
+	// RegistrationHeaderName is the name of the header of the requests
+	// queue-proxies send to the activators to announce the state of their
+	// pods. Its value is the name of the sending component.
+	RegistrationHeaderName = "K-Registration"
+
+	// RegistrationSignatureHeaderName is the name of the header holding the
+	// signature of the registrations with the token of the revision.
+	RegistrationSignatureHeaderName = "K-Registration-Signature"
+
 	// ProxyHeaderName is the name of an internal header that activator
 	// uses to mark requests going through it.
 	ProxyHeaderName = "K-Proxy-Request"
@@ -48,6 +59,16 @@ const (
 	// at the Queue proxy level back to be a host header.
 	OriginalHostHeader = "K-Original-Host"
 
+	// RouteTagHeaderName is the name of the header the ingress adds to
+	// requests coming in through a tagged route, holding the tag. The
+	// queue-proxy uses it to break down its request metrics by tag.
+	RouteTagHeaderName = "K-Route-Tag"
+
+	// ReplayHeaderName is the name of the header the activator adds to a
+	// request it replays after the connection to a pod failed, holding the
+	// number of the attempt.
+	ReplayHeaderName = "K-Request-Replay"
+
 	// ConfigName is the name of the configmap containing all
 	// customizations for networking features.
 	ConfigName = "config-network"
@@ -120,6 +141,44 @@ var (
 	// HTTPProtocolKey is the name of the configuration entry that
 	// specifies the HTTP endpoint behavior of Knative ingress.
 	HTTPProtocolKey = "httpProtocol"
+
+	// ForwardedForPolicyKey is the name of the configuration entry that
+	// specifies how the activator and queue-proxy treat the
+	// X-Forwarded-For header of the requests they proxy.
+	ForwardedForPolicyKey = "forwardedForPolicy"
+
+	// ForwardedHeadersKey is the name of the configuration entry that
+	// specifies whether the activator and queue-proxy trust the forwarded
+	// headers of the requests they receive from the ingress.
+	ForwardedHeadersKey = "forwardedHeaders"
+
+	// TrustedHopsKey is the name of the configuration entry that specifies
+	// the number of proxies in front of Knative whose forwarded headers
+	// are trusted.
+	TrustedHopsKey = "trustedHops"
+
+	// DefaultTrustedHops is the number of trusted hops when it isn't
+	// configured: only the ingress gateway is trusted.
+	DefaultTrustedHops = 1
+
+	// ProxyProtocolKey is the name of the configuration entry that
+	// specifies whether the activator accepts PROXY protocol headers
+	// from the ingress.
+	ProxyProtocolKey = "proxyProtocol"
+
+	// DomainResolutionCheckKey is the name of the configuration entry that
+	// specifies whether Routes wait for their domain to resolve to the
+	// ingress before they become ready.
+	DomainResolutionCheckKey = "domainResolutionCheck"
+
+	// DomainResolversKey is the name of the configuration entry that
+	// specifies the DNS servers the domains of Routes are resolved with.
+	DomainResolversKey = "domainResolvers"
+
+	// ExternalDNSKey is the name of the configuration entry that
+	// specifies whether the ingresses of Routes are annotated for
+	// external-dns to publish the records of their domains.
+	ExternalDNSKey = "externalDNS"
 )
 
 // DomainTemplateValues are the available properties people can choose from
@@ -167,6 +226,35 @@ type Config struct {
 
 	// DefaultCertificateClass specifies the default Certificate class.
 	DefaultCertificateClass string
+
+	// ForwardedForPolicy specifies how the X-Forwarded-For header of
+	// proxied requests is treated.
+	ForwardedForPolicy ForwardedForPolicy
+
+	// ForwardedHeaders specifies whether the forwarded headers of the
+	// requests received from the ingress are trusted.
+	ForwardedHeaders ForwardedHeadersPolicy
+
+	// TrustedHops is the number of trailing elements of the X-Forwarded-For
+	// and Forwarded headers that are kept when they are trusted, 0 keeps
+	// DefaultTrustedHops of them.
+	TrustedHops int
+
+	// ProxyProtocol specifies whether the activator reads the client
+	// address from the PROXY protocol header connections start with.
+	ProxyProtocol bool
+
+	// DomainResolutionCheck specifies whether Routes only become ready once
+	// their domain resolves to the ingress.
+	DomainResolutionCheck bool
+
+	// DomainResolvers are the host:port addresses of the DNS servers the
+	// domains of Routes are resolved with, the cluster DNS when empty.
+	DomainResolvers []string
+
+	// ExternalDNS specifies whether the ingresses of Routes carry the
+	// annotations external-dns creates the records of their domains from.
+	ExternalDNS bool
 }
 
 // HTTPProtocol indicates a type of HTTP endpoint behavior
@@ -184,6 +272,35 @@ const (
 	HTTPRedirected HTTPProtocol = "redirected"
 )
 
+// ForwardedForPolicy indicates how the X-Forwarded-For header of the
+// requests proxied by the activator and queue-proxy is treated.
+type ForwardedForPolicy string
+
+const (
+	// ForwardedForAppend appends the address of the peer to the header,
+	// as every proxy along the way should.
+	ForwardedForAppend ForwardedForPolicy = "append"
+
+	// ForwardedForPreserve passes the header set by the ingress through
+	// unchanged, so that the user container sees the same client
+	// addresses whatever path the request took.
+	ForwardedForPreserve ForwardedForPolicy = "preserve"
+)
+
+// ForwardedHeadersPolicy indicates whether the Forwarded and X-Forwarded-*
+// headers of the requests received from the ingress are trusted.
+type ForwardedHeadersPolicy string
+
+const (
+	// ForwardedHeadersTrust passes the headers on, keeping only the
+	// elements added by the trusted hops.
+	ForwardedHeadersTrust ForwardedHeadersPolicy = "trust"
+
+	// ForwardedHeadersStrip removes the headers, so that only what
+	// Knative's own proxies add reaches the user container.
+	ForwardedHeadersStrip ForwardedHeadersPolicy = "strip"
+)
+
 func validateAndNormalizeOutboundIPRanges(s string) (string, error) {
 	s = strings.TrimSpace(s)
 
@@ -278,6 +395,51 @@ func NewConfigFromConfigMap(configMap *corev1.ConfigMap) (*Config, error) {
 	default:
 		return nil, fmt.Errorf("httpProtocol %s in config-network ConfigMap is not supported", configMap.Data[HTTPProtocolKey])
 	}
+
+	switch strings.ToLower(configMap.Data[ForwardedForPolicyKey]) {
+	case "", string(ForwardedForAppend):
+		nc.ForwardedForPolicy = ForwardedForAppend
+	case string(ForwardedForPreserve):
+		nc.ForwardedForPolicy = ForwardedForPreserve
+	default:
+		return nil, fmt.Errorf("forwardedForPolicy %s in config-network ConfigMap is not supported", configMap.Data[ForwardedForPolicyKey])
+	}
+
+	switch strings.ToLower(configMap.Data[ForwardedHeadersKey]) {
+	case "", string(ForwardedHeadersTrust):
+		nc.ForwardedHeaders = ForwardedHeadersTrust
+	case string(ForwardedHeadersStrip):
+		nc.ForwardedHeaders = ForwardedHeadersStrip
+	default:
+		return nil, fmt.Errorf("forwardedHeaders %s in config-network ConfigMap is not supported", configMap.Data[ForwardedHeadersKey])
+	}
+
+	if th, ok := configMap.Data[TrustedHopsKey]; ok {
+		hops, err := strconv.Atoi(th)
+		if err != nil || hops < 1 {
+			return nil, fmt.Errorf("trustedHops %s in config-network ConfigMap must be a positive integer", th)
+		}
+		nc.TrustedHops = hops
+	}
+
+	nc.ProxyProtocol = strings.ToLower(configMap.Data[ProxyProtocolKey]) == "enabled"
+
+	nc.DomainResolutionCheck = strings.ToLower(configMap.Data[DomainResolutionCheckKey]) == "enabled"
+	if resolvers, ok := configMap.Data[DomainResolversKey]; ok && strings.TrimSpace(resolvers) != "" {
+		for _, resolver := range strings.Split(resolvers, ",") {
+			resolver = strings.TrimSpace(resolver)
+			// The port of DNS is the default.
+			if _, _, err := net.SplitHostPort(resolver); err != nil {
+				resolver = net.JoinHostPort(resolver, "53")
+			}
+			if _, port, err := net.SplitHostPort(resolver); err != nil || port == "" {
+				return nil, fmt.Errorf("domainResolvers %s in config-network ConfigMap must be host:port addresses", resolvers)
+			}
+			nc.DomainResolvers = append(nc.DomainResolvers, resolver)
+		}
+	}
+
+	nc.ExternalDNS = strings.ToLower(configMap.Data[ExternalDNSKey]) == "enabled"
 	return nc, nil
 }
 
@@ -340,6 +502,17 @@ func IsKubeletProbe(r *http.Request) bool {
 		r.Header.Get(KubeletProbeHeaderName) != ""
 }
 
+// LoopbackAddress returns the loopback address of the IP family of the given
+// pod IP, i.e. "::1" for IPv6 addresses and "127.0.0.1" otherwise. Pods on
+// IPv6-only clusters may not have an IPv4 loopback, and the apps in them
+// listen on IPv6.
+func LoopbackAddress(podIP string) string {
+	if ip := net.ParseIP(podIP); ip != nil && ip.To4() == nil {
+		return net.IPv6loopback.String()
+	}
+	return "127.0.0.1"
+}
+
 // RewriteHostIn removes the `Host` header from the inbound (server) request
 // and replaces it with our custom header.
 // This is done to avoid Istio Host based routing, see #3870.
@@ -62,6 +62,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -90,6 +92,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -169,6 +173,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -188,6 +194,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -207,6 +215,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -227,6 +237,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -247,6 +259,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -267,6 +281,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -287,6 +303,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -308,6 +326,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             DefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -329,6 +349,8 @@ func TestConfiguration(t *testing.T) {
 			DomainTemplate:             nonDefaultDomainTemplate,
 			TagTemplate:                DefaultTagTemplate,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -426,6 +448,8 @@ func TestConfiguration(t *testing.T) {
 			TagTemplate:                DefaultTagTemplate,
 			AutoTLS:                    true,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -448,6 +472,8 @@ func TestConfiguration(t *testing.T) {
 			TagTemplate:                DefaultTagTemplate,
 			AutoTLS:                    false,
 			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -470,6 +496,8 @@ func TestConfiguration(t *testing.T) {
 			TagTemplate:                DefaultTagTemplate,
 			AutoTLS:                    true,
 			HTTPProtocol:               HTTPDisabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -493,6 +521,8 @@ func TestConfiguration(t *testing.T) {
 			TagTemplate:                DefaultTagTemplate,
 			AutoTLS:                    true,
 			HTTPProtocol:               HTTPRedirected,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -505,6 +535,183 @@ func TestConfiguration(t *testing.T) {
 				HTTPProtocolKey:          "Redirected",
 			},
 		},
+	}, {
+		name:    "network configuration with client IP preservation",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForPreserve,
+			ForwardedHeaders:           ForwardedHeadersTrust,
+			ProxyProtocol:              true,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				ForwardedForPolicyKey: "Preserve",
+				ProxyProtocolKey:      "enabled",
+			},
+		},
+	}, {
+		name:    "network configuration with forwarded headers stripped",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersStrip,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				ForwardedHeadersKey: "Strip",
+			},
+		},
+	}, {
+		name:    "network configuration with trusted hops",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
+			TrustedHops:                2,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				TrustedHopsKey: "2",
+			},
+		},
+	}, {
+		name:    "network configuration with domain resolution check",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
+			DomainResolutionCheck:      true,
+			DomainResolvers:            []string{"8.8.8.8:53", "[2001:4860:4860::8888]:53", "dns.example.com:5353"},
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				DomainResolutionCheckKey: "Enabled",
+				DomainResolversKey:       "8.8.8.8, 2001:4860:4860::8888, dns.example.com:5353",
+			},
+		},
+	}, {
+		name:    "network configuration with external-dns",
+		wantErr: false,
+		wantConfig: &Config{
+			IstioOutboundIPRanges:      "*",
+			DefaultClusterIngressClass: "istio.ingress.networking.knative.dev",
+			DefaultCertificateClass:    CertManagerCertificateClassName,
+			DomainTemplate:             DefaultDomainTemplate,
+			TagTemplate:                DefaultTagTemplate,
+			HTTPProtocol:               HTTPEnabled,
+			ForwardedForPolicy:         ForwardedForAppend,
+			ForwardedHeaders:           ForwardedHeadersTrust,
+			ExternalDNS:                true,
+		},
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				ExternalDNSKey: "Enabled",
+			},
+		},
+	}, {
+		name:    "network configuration with invalid domain resolvers",
+		wantErr: true,
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				DomainResolversKey: "8.8.8.8,dns.example.com:",
+			},
+		},
+	}, {
+		name:    "network configuration with unsupported forwarded headers policy",
+		wantErr: true,
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				ForwardedHeadersKey: "append",
+			},
+		},
+	}, {
+		name:    "network configuration with no trusted hops",
+		wantErr: true,
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				TrustedHopsKey: "0",
+			},
+		},
+	}, {
+		name:    "network configuration with negative trusted hops",
+		wantErr: true,
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				TrustedHopsKey: "-1",
+			},
+		},
+	}, {
+		name:    "network configuration with unsupported forwarded for policy",
+		wantErr: true,
+		config: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      ConfigName,
+			},
+			Data: map[string]string{
+				ForwardedForPolicyKey: "replace",
+			},
+		},
 	}}
 
 	for _, tt := range networkConfigTests {
@@ -638,6 +845,20 @@ func TestIsKubeletProbe(t *testing.T) {
 	}
 }
 
+func TestLoopbackAddress(t *testing.T) {
+	for podIP, want := range map[string]string{
+		"":                "127.0.0.1",
+		"10.4.2.1":        "127.0.0.1",
+		"::ffff:10.4.2.1": "127.0.0.1",
+		"fd00:10:4::2:1":  "::1",
+		"not an ip":       "127.0.0.1",
+	} {
+		if got := LoopbackAddress(podIP); got != want {
+			t.Errorf("LoopbackAddress(%q) = %q, want: %q", podIP, got, want)
+		}
+	}
+}
+
 func TestRewriteHost(t *testing.T) {
 	r := httptest.NewRequest(http.MethodGet, "http://love.is/not-hate", nil)
 	r.Header.Set("Host", "love.is")
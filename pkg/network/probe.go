@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ProbeNonceHeaderName is the name of the header carrying the random
+	// nonce of a signed probe, which makes every probe, and its response,
+	// unique.
+	ProbeNonceHeaderName = "K-Network-Probe-Nonce"
+
+	// ProbeSignatureHeaderName is the name of the header carrying the
+	// signature of a signed probe, and of its response.
+	ProbeSignatureHeaderName = "K-Network-Probe-Signature"
+
+	// probeResponse distinguishes the signature of a response from that of
+	// the request it answers.
+	probeResponse = "response"
+)
+
+// NewProbeNonce returns a random nonce for a signed probe.
+func NewProbeNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// The nonce only has to be unique, not secret.
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+func signProbe(key []byte, parts ...string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validProbe(key []byte, signature string, parts ...string) bool {
+	want := signProbe(key, parts...)
+	return signature != "" && hmac.Equal([]byte(signature), []byte(want))
+}
+
+// SignProbeRequest signs the probe r of the component with key, with the
+// given nonce.
+func SignProbeRequest(r *http.Request, key []byte, component, nonce string) {
+	r.Header.Set(ProbeHeaderName, component)
+	r.Header.Set(ProbeNonceHeaderName, nonce)
+	r.Header.Set(ProbeSignatureHeaderName, signProbe(key, component, nonce))
+}
+
+// ValidProbeRequest reports whether the probe r of the component is signed
+// with key.
+func ValidProbeRequest(r *http.Request, key []byte, component string) bool {
+	return validProbe(key, r.Header.Get(ProbeSignatureHeaderName),
+		component, r.Header.Get(ProbeNonceHeaderName))
+}
+
+// SignProbeResponse signs the response of the component to the probe r,
+// with key. The response must not be cached by any proxy in between.
+func SignProbeResponse(h http.Header, r *http.Request, key []byte, component string) {
+	h.Set("Cache-Control", "no-store")
+	h.Set(ProbeSignatureHeaderName, signProbe(key, probeResponse, component, r.Header.Get(ProbeNonceHeaderName)))
+}
+
+// ValidProbeResponse reports whether resp is the response of the component
+// to the probe with the given nonce, signed with key.
+func ValidProbeResponse(resp *http.Response, key []byte, component, nonce string) bool {
+	return validProbe(key, resp.Header.Get(ProbeSignatureHeaderName), probeResponse, component, nonce)
+}
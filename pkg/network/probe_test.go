@@ -0,0 +1,73 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignedProbe(t *testing.T) {
+	key := []byte("1234")
+	nonce := NewProbeNonce()
+	if other := NewProbeNonce(); other == nonce {
+		t.Errorf("NewProbeNonce() = %q twice", nonce)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	SignProbeRequest(r, key, "queue", nonce)
+	if got, want := r.Header.Get(ProbeHeaderName), "queue"; got != want {
+		t.Errorf("%s = %q, want %q", ProbeHeaderName, got, want)
+	}
+	if !ValidProbeRequest(r, key, "queue") {
+		t.Error("ValidProbeRequest() = false, want true")
+	}
+	if ValidProbeRequest(r, []byte("5678"), "queue") {
+		t.Error("ValidProbeRequest() with another key = true, want false")
+	}
+	if ValidProbeRequest(r, key, "activator") {
+		t.Error("ValidProbeRequest() for another component = true, want false")
+	}
+	replayed := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	replayed.Header = r.Header.Clone()
+	replayed.Header.Set(ProbeNonceHeaderName, NewProbeNonce())
+	if ValidProbeRequest(replayed, key, "queue") {
+		t.Error("ValidProbeRequest() with another nonce = true, want false")
+	}
+	if ValidProbeRequest(httptest.NewRequest(http.MethodGet, "http://example.com", nil), key, "queue") {
+		t.Error("ValidProbeRequest() of an unsigned probe = true, want false")
+	}
+
+	w := httptest.NewRecorder()
+	SignProbeResponse(w.Header(), r, key, "queue")
+	resp := w.Result()
+	if got, want := resp.Header.Get("Cache-Control"), "no-store"; got != want {
+		t.Errorf("Cache-Control = %q, want %q", got, want)
+	}
+	if !ValidProbeResponse(resp, key, "queue", nonce) {
+		t.Error("ValidProbeResponse() = false, want true")
+	}
+	if ValidProbeResponse(resp, key, "queue", NewProbeNonce()) {
+		t.Error("ValidProbeResponse() for another nonce = true, want false")
+	}
+	// The signature of the request does not pass for that of the response.
+	resp.Header.Set(ProbeSignatureHeaderName, r.Header.Get(ProbeSignatureHeaderName))
+	if ValidProbeResponse(resp, key, "queue", nonce) {
+		t.Error("ValidProbeResponse() with the request signature = true, want false")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/serving/pkg/network"
 )
 
 // Preparer is a way for the caller to modify the HTTP request before it goes out.
@@ -68,6 +69,11 @@ func Do(ctx context.Context, transport http.RoundTripper, target string, ops ...
 		return false, errors.Wrapf(err, "error roundtripping %s", target)
 	}
 	defer resp.Body.Close()
+	if resp.Request == nil {
+		// Not every RoundTripper records the request, which the Verifiers
+		// may look at.
+		resp.Request = req
+	}
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return false, errors.Wrap(err, "error reading body")
@@ -152,3 +158,20 @@ func (m *Manager) doAsync(ctx context.Context, target string, arg interface{}, p
 		m.cb(arg, result, err)
 	}()
 }
+
+// WithSignature signs the probe request of the component with key, with a
+// fresh nonce.
+func WithSignature(key []byte, component string) Preparer {
+	return func(r *http.Request) *http.Request {
+		network.SignProbeRequest(r, key, component, network.NewProbeNonce())
+		return r
+	}
+}
+
+// ExpectsSignature validates that the probe response is signed by the
+// component with key, for the nonce of the request.
+func ExpectsSignature(key []byte, component string) Verifier {
+	return func(r *http.Response, b []byte) (bool, error) {
+		return network.ValidProbeResponse(r, key, component, r.Request.Header.Get(network.ProbeNonceHeaderName)), nil
+	}
+}
@@ -81,6 +81,51 @@ func TestDoServing(t *testing.T) {
 	}
 }
 
+func TestDoSigned(t *testing.T) {
+	key := []byte("1234")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !network.ValidProbeRequest(r, key, systemName) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		network.SignProbeResponse(w.Header(), r, key, systemName)
+		w.Write([]byte(systemName))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name        string
+		requestKey  []byte
+		responseKey []byte
+		want        bool
+	}{{
+		name:        "ok",
+		requestKey:  key,
+		responseKey: key,
+		want:        true,
+	}, {
+		name:        "request signed with another key",
+		requestKey:  []byte("5678"),
+		responseKey: key,
+	}, {
+		name:        "response signed with another key",
+		requestKey:  key,
+		responseKey: []byte("5678"),
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Do(context.Background(), network.NewAutoTransport(), ts.URL,
+				WithSignature(test.requestKey, systemName), ExpectsBody(systemName), ExpectsSignature(test.responseKey, systemName))
+			if want := test.want; got != want {
+				t.Errorf("Got = %v, want: %v", got, want)
+			}
+			if err != nil {
+				t.Errorf("Do returned error: %v", err)
+			}
+		})
+	}
+}
+
 func TestBlackHole(t *testing.T) {
 	got, err := Do(context.Background(), network.NewAutoTransport(), "http://gone.fishing.svc.custer.local:8080")
 	if want := false; got != want {
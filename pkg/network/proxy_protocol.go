@@ -0,0 +1,198 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// proxyProtocolV1MaxLength is the longest header of version 1 of the
+	// PROXY protocol, CRLF included.
+	proxyProtocolV1MaxLength = 107
+	// proxyProtocolV2HeaderLength is the length of the fixed part of the
+	// header of version 2 of the PROXY protocol.
+	proxyProtocolV2HeaderLength = 16
+)
+
+var (
+	proxyProtocolV1Prefix    = []byte("PROXY ")
+	proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errProxyProtocolHeader = errors.New("malformed PROXY protocol header")
+)
+
+// NewProxyProtocolListener wraps l so that, while enabled returns true, the
+// connections it accepts are stripped of the PROXY protocol header, version
+// 1 or 2, they start with and report the client address it carries as their
+// remote address. Connections without such a header are left untouched, as
+// not all of them come through the load balancer in front of the ingress.
+func NewProxyProtocolListener(l net.Listener, enabled func() bool) net.Listener {
+	return &proxyProtocolListener{
+		Listener: l,
+		enabled:  enabled,
+	}
+}
+
+type proxyProtocolListener struct {
+	net.Listener
+	enabled func() bool
+}
+
+// Accept implements net.Listener.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil || !l.enabled() {
+		return c, err
+	}
+	return &proxyProtocolConn{
+		Conn: c,
+		r:    bufio.NewReader(c),
+	}, nil
+}
+
+// proxyProtocolConn reads the PROXY protocol header lazily, so that a slow
+// client doesn't block Accept.
+type proxyProtocolConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+func (c *proxyProtocolConn) readHeader() {
+	c.once.Do(func() {
+		c.remote, c.err = readProxyProtocolHeader(c.r)
+	})
+}
+
+// Read implements net.Conn.
+func (c *proxyProtocolConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr implements net.Conn.
+func (c *proxyProtocolConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyProtocolHeader consumes the PROXY protocol header at the start of
+// r, if any, and returns the client address it carries. The address is nil
+// when there's no header or it doesn't carry one.
+func readProxyProtocolHeader(r *bufio.Reader) (net.Addr, error) {
+	// Neither header can be confused with the start of an HTTP request,
+	// and HTTP requests are longer than the prefix of version 1.
+	if b, _ := r.Peek(len(proxyProtocolV1Prefix)); bytes.Equal(b, proxyProtocolV1Prefix) {
+		return readProxyProtocolV1(r)
+	}
+	if b, _ := r.Peek(1); len(b) == 1 && b[0] == proxyProtocolV2Signature[0] {
+		if b, _ := r.Peek(len(proxyProtocolV2Signature)); bytes.Equal(b, proxyProtocolV2Signature) {
+			return readProxyProtocolV2(r)
+		}
+	}
+	return nil, nil
+}
+
+// readProxyProtocolV1 reads a header like
+//
+//	PROXY TCP4 192.0.2.1 10.0.0.1 56324 8012\r\n
+func readProxyProtocolV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) == proxyProtocolV1MaxLength {
+			return nil, errProxyProtocolHeader
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errProxyProtocolHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.Atoi(fields[4])
+	if ip == nil || err != nil || port < 0 || port > 65535 {
+		return nil, errProxyProtocolHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// readProxyProtocolV2 reads a binary header: the signature, the version and
+// command, the address family and protocol, the length of the addresses
+// and the addresses themselves.
+func readProxyProtocolV2(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, proxyProtocolV2HeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+	addrs := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, addrs); err != nil {
+		return nil, err
+	}
+
+	// LOCAL connections, e.g. health checks of the load balancer, carry
+	// no client address.
+	if command := header[12] & 0x0f; command == 0 {
+		return nil, nil
+	}
+	var ipLength int
+	switch family := header[13] >> 4; family {
+	case 1: // AF_INET
+		ipLength = net.IPv4len
+	case 2: // AF_INET6
+		ipLength = net.IPv6len
+	default:
+		return nil, nil
+	}
+	// The source and destination addresses are followed by their ports.
+	if len(addrs) < 2*ipLength+4 {
+		return nil, errProxyProtocolHeader
+	}
+	return &net.TCPAddr{
+		IP:   net.IP(addrs[:ipLength]),
+		Port: int(binary.BigEndian.Uint16(addrs[2*ipLength:])),
+	}, nil
+}
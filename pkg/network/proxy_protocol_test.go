@@ -0,0 +1,146 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	v2 := func(command, family byte, addrs ...byte) string {
+		header := append([]byte{}, proxyProtocolV2Signature...)
+		header = append(header, 0x20|command, family, 0, byte(len(addrs)))
+		return string(append(header, addrs...))
+	}
+
+	tests := []struct {
+		name     string
+		in       string
+		want     net.Addr
+		wantErr  bool
+		wantRest string
+	}{{
+		name:     "no header",
+		in:       "GET / HTTP/1.1\r\n",
+		wantRest: "GET / HTTP/1.1\r\n",
+	}, {
+		name:     "post request",
+		in:       "POST / HTTP/1.1\r\n",
+		wantRest: "POST / HTTP/1.1\r\n",
+	}, {
+		name:     "short",
+		in:       "P",
+		wantRest: "P",
+	}, {
+		name:     "v1 tcp4",
+		in:       "PROXY TCP4 192.0.2.1 10.0.0.1 56324 8012\r\nGET / HTTP/1.1\r\n",
+		want:     &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 56324},
+		wantRest: "GET / HTTP/1.1\r\n",
+	}, {
+		name:     "v1 tcp6",
+		in:       "PROXY TCP6 2001:db8::1 2001:db8::2 56324 8012\r\nGET",
+		want:     &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 56324},
+		wantRest: "GET",
+	}, {
+		name:     "v1 unknown",
+		in:       "PROXY UNKNOWN\r\nGET",
+		wantRest: "GET",
+	}, {
+		name:    "v1 malformed",
+		in:      "PROXY TCP4 nope 10.0.0.1 56324 8012\r\nGET",
+		wantErr: true,
+	}, {
+		name:    "v1 too long",
+		in:      "PROXY TCP4 " + strings.Repeat("1", proxyProtocolV1MaxLength) + "\r\n",
+		wantErr: true,
+	}, {
+		name:     "v2 inet",
+		in:       v2(1, 0x11, 192, 0, 2, 1, 10, 0, 0, 1, 0xdc, 0x04, 0x1f, 0x4c) + "GET",
+		want:     &net.TCPAddr{IP: net.IP{192, 0, 2, 1}, Port: 56324},
+		wantRest: "GET",
+	}, {
+		name:     "v2 local",
+		in:       v2(0, 0x00) + "GET",
+		wantRest: "GET",
+	}, {
+		name:    "v2 truncated addresses",
+		in:      v2(1, 0x11, 192, 0, 2, 1),
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(test.in))
+			got, err := readProxyProtocolHeader(r)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("readProxyProtocolHeader() = %v, wantErr: %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Address = %v, want: %v", got, test.want)
+			}
+			if rest, _ := ioutil.ReadAll(r); string(rest) != test.wantRest {
+				t.Errorf("Rest = %q, want: %q", rest, test.wantRest)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolListener(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	enabled := true
+	pl := NewProxyProtocolListener(l, func() bool { return enabled })
+	defer pl.Close()
+
+	for _, enabled = range []bool{true, false} {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial() = %v", err)
+		}
+		c.Write([]byte("PROXY TCP4 192.0.2.1 10.0.0.1 56324 8012\r\nGET"))
+		c.Close()
+
+		s, err := pl.Accept()
+		if err != nil {
+			t.Fatalf("Accept() = %v", err)
+		}
+		got, want := s.RemoteAddr().String(), c.LocalAddr().String()
+		rest, _ := ioutil.ReadAll(s)
+		wantRest := "PROXY TCP4 192.0.2.1 10.0.0.1 56324 8012\r\nGET"
+		if enabled {
+			want, wantRest = "192.0.2.1:56324", "GET"
+		}
+		if got != want {
+			t.Errorf("enabled=%v: RemoteAddr = %s, want: %s", enabled, got, want)
+		}
+		if string(rest) != wantRest {
+			t.Errorf("enabled=%v: Read = %q, want: %q", enabled, rest, wantRest)
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+// echoServer accepts a single connection and echoes back what it reads
+// until EOF.
+func echoServer(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		b, _ := ioutil.ReadAll(c)
+		c.Write(b)
+		c.Close()
+	}()
+	return l
+}
+
+func TestSplice(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+
+	front, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	defer front.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c, err := front.Accept()
+		if err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", echo.Addr().String())
+		if err != nil {
+			c.Close()
+			return
+		}
+		Splice(c, upstream)
+	}()
+
+	c, err := net.Dial("tcp", front.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer c.Close()
+	if _, err := c.Write([]byte("ping")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	// The echo server only answers once it sees the EOF.
+	c.(*net.TCPConn).CloseWrite()
+
+	got, err := ioutil.ReadAll(c)
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if string(got) != "ping" {
+		t.Errorf("Got %q, want %q", got, "ping")
+	}
+	<-done
+}
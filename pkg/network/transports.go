@@ -17,11 +17,13 @@ package network
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
 	"time"
 
+	"golang.org/x/net/http2"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
@@ -113,5 +115,26 @@ func NewAutoTransport() http.RoundTripper {
 	return newAutoTransport(newHTTPTransport(DefaultConnTimeout, false /*disable keep-alives*/), NewH2CTransport())
 }
 
+// NewUnixAutoTransport creates a RoundTripper like NewAutoTransport that
+// sends all requests to the Unix domain socket at path, whatever their host.
+func NewUnixAutoTransport(path string) http.RoundTripper {
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialWithBackOff(ctx, "unix", path)
+	}
+	return newAutoTransport(&http.Transport{
+		// Those match newHTTPTransport, requests never leave the pod though.
+		MaxIdleConns:          1000,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		DialContext:           dial,
+	}, &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(_, _ string, _ *tls.Config) (net.Conn, error) {
+			return dial(context.Background(), "", "")
+		},
+	})
+}
+
 // AutoTransport uses h2c for HTTP2 requests and falls back to `http.DefaultTransport` for all others
 var AutoTransport = NewAutoTransport()
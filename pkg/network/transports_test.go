@@ -17,8 +17,12 @@ package network
 
 import (
 	"context"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -98,3 +102,38 @@ func TestDialWithBackoff(t *testing.T) {
 	}
 	c.Close()
 }
+
+func TestUnixAutoTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "socket")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	s := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Host))
+		}),
+	}
+	go s.Serve(l)
+	defer s.Close()
+
+	client := &http.Client{Transport: NewUnixAutoTransport(path)}
+	resp, err := client.Get("http://127.0.0.1:8080/")
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() = %v", err)
+	}
+	if got, want := string(body), "127.0.0.1:8080"; got != want {
+		t.Errorf("Host = %q, want: %q", got, want)
+	}
+}
@@ -23,6 +23,11 @@ package network
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
+	if in.DomainResolvers != nil {
+		in, out := &in.DomainResolvers, &out.DomainResolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portable exports Services to manifests which are portable
+// across clusters, and imports them.
+package portable
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+)
+
+// Manifest describes a Service in a form which is independent of the
+// cluster it was exported from.
+type Manifest struct {
+	// Service is the Service with its defaults resolved, in the form of
+	// v1beta1, without the fields set by the cluster. The template of its
+	// Configuration is named after its latest created Revision, with its
+	// image resolved to a digest.
+	Service *v1alpha1.Service `json:"service"`
+
+	// Revisions are the templates of the other Revisions the traffic of
+	// the Service is pinned to, oldest first, with their images resolved
+	// to digests.
+	Revisions []v1alpha1.RevisionTemplateSpec `json:"revisions,omitempty"`
+
+	// URL is the URL the Service was served at by the cluster it was
+	// exported from.
+	URL string `json:"url,omitempty"`
+}
+
+var (
+	// systemLabels are the labels set by the reconcilers, which are set
+	// again by those of the cluster the manifest is imported in.
+	systemLabels = []string{
+		serving.ConfigurationLabelKey,
+		serving.ConfigurationGenerationLabelKey,
+		serving.RevisionUID,
+		serving.RouteLabelKey,
+		serving.ServiceLabelKey,
+	}
+
+	// systemAnnotations are the annotations set by the webhook and the
+	// reconcilers.
+	systemAnnotations = []string{
+		serving.CreatorAnnotation,
+		serving.UpdaterAnnotation,
+		serving.RevisionLastPinnedAnnotationKey,
+	}
+)
+
+// Export returns the Manifest of the Service name in namespace.
+func Export(client clientset.Interface, namespace, name string) (*Manifest, error) {
+	service, err := client.ServingV1alpha1().Services(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	var up v1beta1.Service
+	if err := service.ConvertUp(ctx, &up); err != nil {
+		return nil, err
+	}
+	normalized := &v1alpha1.Service{}
+	if err := normalized.ConvertDown(ctx, &up); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Service: &v1alpha1.Service{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Service",
+			},
+			ObjectMeta: portableMeta(normalized.ObjectMeta),
+			Spec:       normalized.Spec,
+		},
+	}
+	if service.Status.URL != nil {
+		m.URL = service.Status.URL.String()
+	}
+
+	revisions := client.ServingV1alpha1().Revisions(namespace)
+	template := m.Service.Spec.Template
+	if latest := service.Status.LatestCreatedRevisionName; latest != "" && template != nil {
+		rev, err := revisions.Get(latest, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest created revision: %v", err)
+		}
+		template.Name = latest
+		if rev.Status.ImageDigest != "" {
+			template.Spec.GetContainer().Image = rev.Status.ImageDigest
+		}
+	}
+
+	var pinned []*v1alpha1.Revision
+	seen := map[string]bool{}
+	for _, tt := range m.Service.Spec.Traffic {
+		if tt.RevisionName == "" || seen[tt.RevisionName] || (template != nil && tt.RevisionName == template.Name) {
+			continue
+		}
+		seen[tt.RevisionName] = true
+		rev, err := revisions.Get(tt.RevisionName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pinned revision %q: %v", tt.RevisionName, err)
+		}
+		pinned = append(pinned, rev)
+	}
+	sort.Slice(pinned, func(i, j int) bool {
+		return generation(pinned[i]) < generation(pinned[j])
+	})
+	for _, rev := range pinned {
+		rt, err := revisionTemplate(ctx, rev)
+		if err != nil {
+			return nil, err
+		}
+		m.Revisions = append(m.Revisions, *rt)
+	}
+	return m, nil
+}
+
+// revisionTemplate returns the template stamping out rev again, in the form
+// of v1beta1.
+func revisionTemplate(ctx context.Context, rev *v1alpha1.Revision) (*v1alpha1.RevisionTemplateSpec, error) {
+	var up v1beta1.Revision
+	if err := rev.ConvertUp(ctx, &up); err != nil {
+		return nil, err
+	}
+	normalized := &v1alpha1.Revision{}
+	if err := normalized.ConvertDown(ctx, &up); err != nil {
+		return nil, err
+	}
+	rt := &v1alpha1.RevisionTemplateSpec{
+		ObjectMeta: portableMeta(normalized.ObjectMeta),
+		Spec:       normalized.Spec,
+	}
+	if rev.Status.ImageDigest != "" {
+		rt.Spec.GetContainer().Image = rev.Status.ImageDigest
+	}
+	return rt, nil
+}
+
+// portableMeta returns the name, labels and annotations of meta, without
+// those set by the cluster.
+func portableMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	portable := metav1.ObjectMeta{Name: meta.Name}
+	for k, v := range meta.Labels {
+		if !contains(systemLabels, k) {
+			if portable.Labels == nil {
+				portable.Labels = map[string]string{}
+			}
+			portable.Labels[k] = v
+		}
+	}
+	for k, v := range meta.Annotations {
+		if !contains(systemAnnotations, k) {
+			if portable.Annotations == nil {
+				portable.Annotations = map[string]string{}
+			}
+			portable.Annotations[k] = v
+		}
+	}
+	return portable
+}
+
+// generation returns the generation of the Configuration rev was stamped
+// out for.
+func generation(rev *v1alpha1.Revision) int64 {
+	g, _ := strconv.ParseInt(rev.Labels[serving.ConfigurationGenerationLabelKey], 10, 64)
+	return g
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
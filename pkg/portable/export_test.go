@@ -0,0 +1,196 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portable
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	fakeclientset "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+func revision(name, generation, image, digest string) *v1alpha1.Revision {
+	return &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey:           "hello",
+				serving.ConfigurationGenerationLabelKey: generation,
+				serving.ServiceLabelKey:                 "hello",
+				"app":                                   "hello",
+			},
+			Annotations: map[string]string{
+				serving.CreatorAnnotation:               "someone",
+				serving.RevisionLastPinnedAnnotationKey: "1234",
+				"autoscaling.knative.dev/class":         "kpa.autoscaling.knative.dev",
+			},
+		},
+		Spec: v1alpha1.RevisionSpec{
+			DeprecatedContainer: &corev1.Container{Image: image},
+		},
+		Status: v1alpha1.RevisionStatus{ImageDigest: digest},
+	}
+}
+
+func TestExport(t *testing.T) {
+	url, _ := apis.ParseURL("http://hello.default.example.com")
+	service := &v1alpha1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "hello",
+			Namespace:       "default",
+			UID:             "1234",
+			ResourceVersion: "42",
+			Generation:      3,
+			Labels:          map[string]string{"app": "hello"},
+			Annotations: map[string]string{
+				serving.CreatorAnnotation: "someone",
+				serving.UpdaterAnnotation: "someone-else",
+			},
+		},
+		Spec: v1alpha1.ServiceSpec{
+			DeprecatedRelease: &v1alpha1.ReleaseType{
+				Revisions:      []string{"hello-00001", "hello-00002"},
+				RolloutPercent: 10,
+				Configuration: v1alpha1.ConfigurationSpec{
+					DeprecatedRevisionTemplate: &v1alpha1.RevisionTemplateSpec{
+						Spec: v1alpha1.RevisionSpec{
+							DeprecatedContainer: &corev1.Container{Image: "hello:3"},
+						},
+					},
+				},
+			},
+		},
+	}
+	service.Status.URL = url
+	service.Status.LatestCreatedRevisionName = "hello-00003"
+
+	client := fakeclientset.NewSimpleClientset(service,
+		revision("hello-00002", "2", "hello:2", "hello@sha256:2"),
+		revision("hello-00001", "1", "hello:1", "hello@sha256:1"),
+		revision("hello-00003", "3", "hello:3", "hello@sha256:3"))
+
+	got, err := Export(client, "default", "hello")
+	if err != nil {
+		t.Fatalf("Export() = %v", err)
+	}
+
+	template := func(name, image string) v1alpha1.RevisionTemplateSpec {
+		return v1alpha1.RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": "hello"},
+				Annotations: map[string]string{
+					"autoscaling.knative.dev/class": "kpa.autoscaling.knative.dev",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{{Image: image}},
+					},
+				},
+			},
+		}
+	}
+	want := &Manifest{
+		Service: &v1alpha1.Service{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "serving.knative.dev/v1alpha1",
+				Kind:       "Service",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "hello",
+				Labels: map[string]string{"app": "hello"},
+			},
+			Spec: v1alpha1.ServiceSpec{
+				ConfigurationSpec: v1alpha1.ConfigurationSpec{
+					Template: &v1alpha1.RevisionTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Name: "hello-00003"},
+						Spec: v1alpha1.RevisionSpec{
+							RevisionSpec: v1beta1.RevisionSpec{
+								PodSpec: corev1.PodSpec{
+									Containers: []corev1.Container{{Image: "hello@sha256:3"}},
+								},
+							},
+						},
+					},
+				},
+				RouteSpec: v1alpha1.RouteSpec{
+					Traffic: []v1alpha1.TrafficTarget{{
+						TrafficTarget: v1beta1.TrafficTarget{
+							Tag:          "current",
+							RevisionName: "hello-00001",
+							Percent:      90,
+						},
+					}, {
+						TrafficTarget: v1beta1.TrafficTarget{
+							Tag:          "candidate",
+							RevisionName: "hello-00002",
+							Percent:      10,
+						},
+					}, {
+						TrafficTarget: v1beta1.TrafficTarget{
+							Tag:            "latest",
+							LatestRevision: ptr.Bool(true),
+						},
+					}},
+				},
+			},
+		},
+		Revisions: []v1alpha1.RevisionTemplateSpec{
+			template("hello-00001", "hello@sha256:1"),
+			template("hello-00002", "hello@sha256:2"),
+		},
+		URL: "http://hello.default.example.com",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Export() (-want, +got) = %s", diff)
+	}
+}
+
+func TestExportMissingRevision(t *testing.T) {
+	service := &v1alpha1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.ServiceSpec{
+			DeprecatedPinned: &v1alpha1.PinnedType{
+				RevisionName: "hello-00001",
+				Configuration: v1alpha1.ConfigurationSpec{
+					DeprecatedRevisionTemplate: &v1alpha1.RevisionTemplateSpec{
+						Spec: v1alpha1.RevisionSpec{
+							DeprecatedContainer: &corev1.Container{Image: "hello"},
+						},
+					},
+				},
+			},
+		},
+	}
+	client := fakeclientset.NewSimpleClientset(service)
+	if _, err := Export(client, "default", "hello"); err == nil {
+		t.Error("Export() = nil, wanted an error for the missing pinned revision")
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portable
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+)
+
+var (
+	// classAnnotations are the annotations selecting the implementation of
+	// a resource, which differ across clusters.
+	classAnnotations = []string{
+		autoscaling.ClassAnnotationKey,
+		networking.CertificateClassAnnotationKey,
+		networking.IngressClassAnnotationKey,
+	}
+)
+
+// Rewrites are the differences between the cluster a Manifest was exported
+// from and the one it is imported in.
+type Rewrites struct {
+	// Domains maps the domains of the source cluster to those of the
+	// target cluster. The labels and annotations with a value in one of
+	// the domains, e.g. hosts or those selecting domains in config-domain,
+	// are moved to the corresponding domain.
+	Domains map[string]string
+
+	// Classes maps the classes of the source cluster, e.g. ingress or
+	// autoscaler classes, to those of the target cluster.
+	Classes map[string]string
+}
+
+// Importer imports Manifests.
+type Importer struct {
+	Client clientset.Interface
+
+	// PollInterval and PollTimeout bound the wait for each Revision of a
+	// Manifest to be stamped out before the next one is.
+	PollInterval time.Duration
+	PollTimeout  time.Duration
+}
+
+// Import creates the Service of m in namespace, after stamping out the
+// Revisions its traffic is pinned to, with rewrites applied. The Service
+// must not exist yet.
+func (i *Importer) Import(m *Manifest, namespace string, rewrites Rewrites) (*v1alpha1.Service, error) {
+	if m.Service == nil {
+		return nil, fmt.Errorf("the manifest has no service")
+	}
+	service := m.Service.DeepCopy()
+	service.Namespace = namespace
+	rewrites.apply(&service.ObjectMeta)
+	if service.Spec.Template != nil {
+		rewrites.apply(&service.Spec.Template.ObjectMeta)
+	}
+
+	// The Revisions are stamped out by updating the template of the
+	// Service, oldest first, with all the traffic on the latest one until
+	// they all exist.
+	services := i.Client.ServingV1alpha1().Services(namespace)
+	var current *v1alpha1.Service
+	for _, rt := range m.Revisions {
+		rt := rt.DeepCopy()
+		rewrites.apply(&rt.ObjectMeta)
+		desired := service.DeepCopy()
+		desired.Spec.Template = rt
+		desired.Spec.Traffic = []v1alpha1.TrafficTarget{{
+			TrafficTarget: v1beta1.TrafficTarget{
+				Percent:        100,
+				LatestRevision: ptr.Bool(true),
+			},
+		}}
+
+		var err error
+		if current == nil {
+			current, err = services.Create(desired)
+		} else {
+			desired.ResourceVersion = current.ResourceVersion
+			current, err = services.Update(desired)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := i.waitForRevision(namespace, rt.Name); err != nil {
+			return nil, fmt.Errorf("failed waiting for revision %q: %v", rt.Name, err)
+		}
+	}
+
+	if current == nil {
+		return services.Create(service)
+	}
+	service.ResourceVersion = current.ResourceVersion
+	return services.Update(service)
+}
+
+// waitForRevision waits for the Revision name to be created.
+func (i *Importer) waitForRevision(namespace, name string) error {
+	revisions := i.Client.ServingV1alpha1().Revisions(namespace)
+	return wait.PollImmediate(i.PollInterval, i.PollTimeout, func() (bool, error) {
+		_, err := revisions.Get(name, metav1.GetOptions{})
+		if apierrs.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// apply rewrites the labels and annotations of meta.
+func (r Rewrites) apply(meta *metav1.ObjectMeta) {
+	for k, v := range meta.Labels {
+		meta.Labels[k] = r.domain(v)
+	}
+	for k, v := range meta.Annotations {
+		if to, ok := r.Classes[v]; ok && contains(classAnnotations, k) {
+			meta.Annotations[k] = to
+		} else {
+			meta.Annotations[k] = r.domain(v)
+		}
+	}
+}
+
+// domain returns value moved to its domain in the target cluster, or as is
+// when it isn't in one of the rewritten domains.
+func (r Rewrites) domain(value string) string {
+	for from, to := range r.Domains {
+		if value == from {
+			return to
+		}
+		if strings.HasSuffix(value, "."+from) {
+			return strings.TrimSuffix(value, from) + to
+		}
+	}
+	return value
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	fakeclientset "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+func testManifest() *Manifest {
+	template := func(name, image string) *v1alpha1.RevisionTemplateSpec {
+		return &v1alpha1.RevisionTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					"autoscaling.knative.dev/class": "kpa.autoscaling.knative.dev",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{{Image: image}},
+					},
+				},
+			},
+		}
+	}
+	return &Manifest{
+		Service: &v1alpha1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "hello",
+				Labels: map[string]string{
+					"app":    "hello",
+					"domain": "example.com",
+				},
+				Annotations: map[string]string{
+					"networking.knative.dev/ingress.class": "istio.ingress.networking.knative.dev",
+					"hosts":                                "hello.apps.example.com",
+				},
+			},
+			Spec: v1alpha1.ServiceSpec{
+				ConfigurationSpec: v1alpha1.ConfigurationSpec{
+					Template: template("hello-00002", "hello@sha256:2"),
+				},
+				RouteSpec: v1alpha1.RouteSpec{
+					Traffic: []v1alpha1.TrafficTarget{{
+						TrafficTarget: v1beta1.TrafficTarget{
+							RevisionName: "hello-00001",
+							Percent:      90,
+						},
+					}, {
+						TrafficTarget: v1beta1.TrafficTarget{
+							LatestRevision: ptr.Bool(true),
+							Percent:        10,
+						},
+					}},
+				},
+			},
+		},
+		Revisions: []v1alpha1.RevisionTemplateSpec{*template("hello-00001", "hello@sha256:1")},
+	}
+}
+
+func TestImport(t *testing.T) {
+	// The revision stamped out before the traffic is pinned to it.
+	client := fakeclientset.NewSimpleClientset(&v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hello-00001",
+			Namespace: "prod",
+		},
+	})
+	i := &Importer{
+		Client:       client,
+		PollInterval: time.Millisecond,
+		PollTimeout:  time.Second,
+	}
+	m := testManifest()
+	got, err := i.Import(m, "prod", Rewrites{
+		Domains: map[string]string{"example.com": "example.org"},
+		Classes: map[string]string{
+			"istio.ingress.networking.knative.dev": "contour.ingress.networking.knative.dev",
+			"kpa.autoscaling.knative.dev":          "hpa.autoscaling.knative.dev",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Import() = %v", err)
+	}
+
+	var verbs []string
+	var templates []string
+	for _, action := range client.Actions() {
+		if action.GetResource().Resource != "services" {
+			continue
+		}
+		verbs = append(verbs, action.GetVerb())
+		var service *v1alpha1.Service
+		switch a := action.(type) {
+		case clientgotesting.CreateAction:
+			service = a.GetObject().(*v1alpha1.Service)
+		case clientgotesting.UpdateAction:
+			service = a.GetObject().(*v1alpha1.Service)
+		}
+		templates = append(templates, service.Spec.Template.Name)
+		if got, want := service.Spec.Template.Annotations["autoscaling.knative.dev/class"], "hpa.autoscaling.knative.dev"; got != want {
+			t.Errorf("Autoscaler class of %s = %q, want: %q", service.Spec.Template.Name, got, want)
+		}
+	}
+	if diff := cmp.Diff([]string{"create", "update"}, verbs); diff != "" {
+		t.Errorf("Verbs (-want, +got) = %s", diff)
+	}
+	if diff := cmp.Diff([]string{"hello-00001", "hello-00002"}, templates); diff != "" {
+		t.Errorf("Templates (-want, +got) = %s", diff)
+	}
+
+	if got, want := got.Namespace, "prod"; got != want {
+		t.Errorf("Namespace = %q, want: %q", got, want)
+	}
+	if diff := cmp.Diff(m.Service.Spec.Traffic, got.Spec.Traffic); diff != "" {
+		t.Errorf("Traffic (-want, +got) = %s", diff)
+	}
+	wantLabels := map[string]string{
+		"app":    "hello",
+		"domain": "example.org",
+	}
+	if diff := cmp.Diff(wantLabels, got.Labels); diff != "" {
+		t.Errorf("Labels (-want, +got) = %s", diff)
+	}
+	wantAnnotations := map[string]string{
+		"networking.knative.dev/ingress.class": "contour.ingress.networking.knative.dev",
+		"hosts":                                "hello.apps.example.org",
+	}
+	if diff := cmp.Diff(wantAnnotations, got.Annotations); diff != "" {
+		t.Errorf("Annotations (-want, +got) = %s", diff)
+	}
+
+	// The manifest isn't rewritten.
+	if diff := cmp.Diff(testManifest(), m); diff != "" {
+		t.Errorf("Manifest (-want, +got) = %s", diff)
+	}
+}
+
+func TestImportRevisionNotStampedOut(t *testing.T) {
+	i := &Importer{
+		Client:       fakeclientset.NewSimpleClientset(),
+		PollInterval: time.Millisecond,
+		PollTimeout:  10 * time.Millisecond,
+	}
+	if _, err := i.Import(testManifest(), "prod", Rewrites{}); err == nil {
+		t.Error("Import() = nil, wanted an error for the revision never stamped out")
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protection guards Services and Routes annotated with
+// serving.ProtectedAnnotationKey against deletion.
+package protection
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// Resources are the resources whose deletion is guarded.
+var Resources = []schema.GroupResource{{
+	Group:    serving.GroupName,
+	Resource: "services",
+}, {
+	Group:    serving.GroupName,
+	Resource: "routes",
+}}
+
+// CheckDeletion returns an error when obj is protected and its deletion
+// wasn't forced.  Resources controlled by another one, e.g. the Route of a
+// Service, are protected through their controller.
+func CheckDeletion(obj metav1.Object) error {
+	if metav1.GetControllerOf(obj) != nil {
+		return nil
+	}
+	anns := obj.GetAnnotations()
+	if anns[serving.ProtectedAnnotationKey] != "true" || anns[serving.ForceDeleteAnnotationKey] == "true" {
+		return nil
+	}
+	return fmt.Errorf("%q is protected by the %s annotation, set %s to %q to delete it",
+		obj.GetName(), serving.ProtectedAnnotationKey, serving.ForceDeleteAnnotationKey, "true")
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestCheckDeletion(t *testing.T) {
+	tests := []struct {
+		name    string
+		meta    metav1.ObjectMeta
+		wantErr bool
+	}{{
+		name: "not protected",
+	}, {
+		name: "protected",
+		meta: metav1.ObjectMeta{
+			Annotations: map[string]string{serving.ProtectedAnnotationKey: "true"},
+		},
+		wantErr: true,
+	}, {
+		name: "no longer protected",
+		meta: metav1.ObjectMeta{
+			Annotations: map[string]string{serving.ProtectedAnnotationKey: "false"},
+		},
+	}, {
+		name: "forced",
+		meta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				serving.ProtectedAnnotationKey:   "true",
+				serving.ForceDeleteAnnotationKey: "true",
+			},
+		},
+	}, {
+		name: "protected through its controller",
+		meta: metav1.ObjectMeta{
+			Annotations: map[string]string{serving.ProtectedAnnotationKey: "true"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "serving.knative.dev/v1alpha1",
+				Kind:       "Service",
+				Name:       "foo",
+				Controller: ptr.Bool(true),
+			}},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			meta := test.meta
+			meta.Name = "foo"
+			if err := CheckDeletion(&meta); (err != nil) != test.wantErr {
+				t.Errorf("CheckDeletion() = %v, wantErr: %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	clientadmissionregistrationv1beta1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1beta1"
+	"knative.dev/pkg/kmp"
+	"knative.dev/pkg/webhook"
+	"knative.dev/serving/pkg/admission"
+)
+
+// Webhook rejects the deletion of protected Resources.  It is registered as
+// a validating admission webhook for DELETE operations, which the webhook
+// of knative.dev/pkg doesn't receive.
+type Webhook struct {
+	Client  kubernetes.Interface
+	Dynamic dynamic.Interface
+	Options webhook.ControllerOptions
+	Logger  *zap.SugaredLogger
+}
+
+// Run registers the webhook and serves it until stop is closed. Its
+// certificates are rotated before they expire.
+func (wh *Webhook) Run(stop <-chan struct{}) error {
+	logger := wh.Logger
+	certs := admission.NewCertificates(wh.Client, wh.Dynamic, wh.Options, logger)
+	if err := certs.Reconcile(); err != nil {
+		logger.Errorw("Could not configure the protection webhook certs", zap.Error(err))
+		return err
+	}
+	go certs.Run(stop)
+
+	select {
+	case <-time.After(wh.Options.RegistrationDelay):
+		cl := wh.Client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+		if err := wh.register(cl, certs.CABundle()); err != nil {
+			logger.Errorw("Failed to register the protection webhook", zap.Error(err))
+			return err
+		}
+		logger.Info("Successfully registered the protection webhook")
+	case <-stop:
+		return nil
+	}
+
+	return admission.Serve(stop, wh.Options.Port, wh, certs)
+}
+
+// register creates or updates the ValidatingWebhookConfiguration sending
+// the deletions of Resources to the webhook.
+func (wh *Webhook) register(client clientadmissionregistrationv1beta1.ValidatingWebhookConfigurationInterface, caCert []byte) error {
+	failurePolicy := admissionregistrationv1beta1.Fail
+
+	rules := make([]admissionregistrationv1beta1.RuleWithOperations, 0, len(Resources))
+	for _, gr := range Resources {
+		rules = append(rules, admissionregistrationv1beta1.RuleWithOperations{
+			Operations: []admissionregistrationv1beta1.OperationType{
+				admissionregistrationv1beta1.Delete,
+			},
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   []string{gr.Group},
+				APIVersions: []string{"*"},
+				Resources:   []string{gr.Resource},
+			},
+		})
+	}
+
+	config := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: wh.Options.WebhookName,
+		},
+		Webhooks: []admissionregistrationv1beta1.Webhook{{
+			Name:  wh.Options.WebhookName,
+			Rules: rules,
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: wh.Options.Namespace,
+					Name:      wh.Options.ServiceName,
+				},
+				CABundle: caCert,
+			},
+			FailurePolicy: &failurePolicy,
+		}},
+	}
+
+	// Set the owner to our deployment.
+	deployment, err := wh.Client.AppsV1().Deployments(wh.Options.Namespace).Get(wh.Options.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to fetch our deployment: %v", err)
+	}
+	config.OwnerReferences = []metav1.OwnerReference{
+		*metav1.NewControllerRef(deployment, appsv1.SchemeGroupVersion.WithKind("Deployment")),
+	}
+
+	existing, err := client.Get(wh.Options.WebhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(config)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if ok, err := kmp.SafeEqual(existing.Webhooks, config.Webhooks); err != nil {
+		return fmt.Errorf("error diffing webhooks: %v", err)
+	} else if ok {
+		return nil
+	}
+	config.ResourceVersion = existing.ResourceVersion
+	_, err = client.Update(config)
+	return err
+}
+
+// ServeHTTP implements the admission webhook.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var review admissionv1beta1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("could not decode body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "missing admission request", http.StatusBadRequest)
+		return
+	}
+
+	response := admissionv1beta1.AdmissionReview{
+		Response: wh.admit(review.Request),
+	}
+	response.Response.UID = review.Request.UID
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, fmt.Sprintf("could not encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if wh.Options.StatsReporter != nil {
+		if err := wh.Options.StatsReporter.ReportRequest(review.Request, response.Response, time.Since(start)); err != nil {
+			wh.Logger.Warnw("Failed to report the request", zap.Error(err))
+		}
+	}
+}
+
+func (wh *Webhook) admit(req *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
+	if req.Operation != admissionv1beta1.Delete {
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	}
+	logger := wh.Logger.With(zap.String("resource", req.Resource.String()),
+		zap.String("namespace", req.Namespace), zap.String("name", req.Name))
+
+	obj, err := wh.object(req)
+	if apierrors.IsNotFound(err) {
+		// Nothing left to protect.
+		return &admissionv1beta1.AdmissionResponse{Allowed: true}
+	} else if err != nil {
+		logger.Errorw("Failed to get the object being deleted", zap.Error(err))
+		status := apierrors.NewInternalError(err).Status()
+		return &admissionv1beta1.AdmissionResponse{Result: &status}
+	}
+
+	if err := CheckDeletion(obj); err != nil {
+		logger.Infof("Rejecting deletion: %v", err)
+		gr := schema.GroupResource{Group: req.Resource.Group, Resource: req.Resource.Resource}
+		status := apierrors.NewForbidden(gr, req.Name, err).Status()
+		return &admissionv1beta1.AdmissionResponse{Result: &status}
+	}
+	return &admissionv1beta1.AdmissionResponse{Allowed: true}
+}
+
+// object returns the object req deletes.  The API server only sends it
+// along with DELETE operations from Kubernetes 1.15 on.
+func (wh *Webhook) object(req *admissionv1beta1.AdmissionRequest) (metav1.Object, error) {
+	if len(req.OldObject.Raw) > 0 {
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(req.OldObject.Raw, obj); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+	gvr := schema.GroupVersionResource{
+		Group:    req.Resource.Group,
+		Version:  req.Resource.Version,
+		Resource: req.Resource.Resource,
+	}
+	return wh.Dynamic.Resource(gvr).Namespace(req.Namespace).Get(req.Name, metav1.GetOptions{})
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protection
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	fakekube "k8s.io/client-go/kubernetes/fake"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/webhook"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+var services = metav1.GroupVersionResource{
+	Group:    serving.GroupName,
+	Version:  "v1alpha1",
+	Resource: "services",
+}
+
+func service(name string, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("serving.knative.dev/v1alpha1")
+	obj.SetKind("Service")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func raw(t *testing.T, obj *unstructured.Unstructured) runtime.RawExtension {
+	t.Helper()
+	b, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	return runtime.RawExtension{Raw: b}
+}
+
+func TestAdmit(t *testing.T) {
+	protected := map[string]string{serving.ProtectedAnnotationKey: "true"}
+	wh := &Webhook{
+		Dynamic: fakedynamic.NewSimpleDynamicClient(runtime.NewScheme(), service("stored", protected)),
+		Logger:  logtesting.TestLogger(t),
+	}
+
+	tests := []struct {
+		name     string
+		req      *admissionv1beta1.AdmissionRequest
+		wantCode int32
+	}{{
+		name: "not a deletion",
+		req: &admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Update,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "protected",
+			Object:    raw(t, service("protected", protected)),
+		},
+	}, {
+		name: "protected",
+		req: &admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Delete,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "protected",
+			OldObject: raw(t, service("protected", protected)),
+		},
+		wantCode: http.StatusForbidden,
+	}, {
+		name: "not protected",
+		req: &admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Delete,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "unprotected",
+			OldObject: raw(t, service("unprotected", nil)),
+		},
+	}, {
+		name: "protected, without the object",
+		req: &admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Delete,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "stored",
+		},
+		wantCode: http.StatusForbidden,
+	}, {
+		name: "already gone",
+		req: &admissionv1beta1.AdmissionRequest{
+			Operation: admissionv1beta1.Delete,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "gone",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := wh.admit(test.req)
+			if test.wantCode == 0 {
+				if !resp.Allowed {
+					t.Errorf("admit() = %v, wanted it allowed", resp.Result)
+				}
+				return
+			}
+			if resp.Allowed {
+				t.Fatal("admit() allowed the deletion")
+			}
+			if got := resp.Result.Code; got != test.wantCode {
+				t.Errorf("Code = %d, want: %d", got, test.wantCode)
+			}
+		})
+	}
+}
+
+type fakeStatsReporter struct {
+	reported []*admissionv1beta1.AdmissionResponse
+}
+
+func (fsr *fakeStatsReporter) ReportRequest(_ *admissionv1beta1.AdmissionRequest, resp *admissionv1beta1.AdmissionResponse, _ time.Duration) error {
+	fsr.reported = append(fsr.reported, resp)
+	return nil
+}
+
+func TestServeHTTP(t *testing.T) {
+	reporter := &fakeStatsReporter{}
+	wh := &Webhook{
+		Options: webhook.ControllerOptions{StatsReporter: reporter},
+		Logger:  logtesting.TestLogger(t),
+	}
+
+	review := admissionv1beta1.AdmissionReview{
+		Request: &admissionv1beta1.AdmissionRequest{
+			UID:       types.UID("12345"),
+			Operation: admissionv1beta1.Delete,
+			Resource:  services,
+			Namespace: "default",
+			Name:      "protected",
+			OldObject: raw(t, service("protected", map[string]string{serving.ProtectedAnnotationKey: "true"})),
+		},
+	}
+	body, err := json.Marshal(review)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body)))
+
+	var got admissionv1beta1.AdmissionReview
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if got.Response.UID != review.Request.UID {
+		t.Errorf("UID = %q, want: %q", got.Response.UID, review.Request.UID)
+	}
+	if got.Response.Allowed {
+		t.Error("The deletion of a protected Service was allowed")
+	}
+	if len(reporter.reported) != 1 || reporter.reported[0].Allowed {
+		t.Errorf("Reported responses = %v, want the rejection", reporter.reported)
+	}
+}
+
+func TestServeHTTPBadRequest(t *testing.T) {
+	wh := &Webhook{Logger: logtesting.TestLogger(t)}
+
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("{}"))))
+
+	if got, want := rec.Code, http.StatusBadRequest; got != want {
+		t.Errorf("Code = %d, want: %d", got, want)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	client := fakekube.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-serving",
+			Name:      "webhook",
+		},
+	})
+	wh := &Webhook{
+		Client: client,
+		Options: webhook.ControllerOptions{
+			ServiceName:    "webhook-protection",
+			DeploymentName: "webhook",
+			Namespace:      "knative-serving",
+			WebhookName:    "protection.webhook.serving.knative.dev",
+		},
+		Logger: logtesting.TestLogger(t),
+	}
+	configs := client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+
+	if err := wh.register(configs, []byte("ca")); err != nil {
+		t.Fatalf("register() = %v", err)
+	}
+	config, err := configs.Get(wh.Options.WebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got, want := len(config.Webhooks[0].Rules), len(Resources); got != want {
+		t.Errorf("len(Rules) = %d, want: %d", got, want)
+	}
+
+	// A new CA updates the registration.
+	if err := wh.register(configs, []byte("new-ca")); err != nil {
+		t.Fatalf("register() = %v", err)
+	}
+	config, err = configs.Get(wh.Options.WebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	if got, want := string(config.Webhooks[0].ClientConfig.CABundle), "new-ca"; got != want {
+		t.Errorf("CABundle = %q, want: %q", got, want)
+	}
+}
@@ -108,6 +108,12 @@ func (b *Breaker) Capacity() int {
 	return b.sem.Capacity()
 }
 
+// Available returns the number of requests that can be executed on this
+// breaker right away, on top of the ones in flight.
+func (b *Breaker) Available() int {
+	return len(b.sem.queue)
+}
+
 // newSemaphore creates a semaphore with the desired maximal and initial capacity.
 // Maximal capacity is the size of the buffered channel, it defines maximum number of tokens
 // in the rotation. Attempting to add more capacity then the max will result in error.
@@ -177,6 +177,26 @@ func TestBreakerUpdateConcurrency(t *testing.T) {
 	}
 }
 
+func TestBreakerAvailable(t *testing.T) {
+	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 2, InitialCapacity: 2}
+	b := NewBreaker(params)
+	if got, want := b.Available(), 2; got != want {
+		t.Errorf("Available() = %d, want: %d", got, want)
+	}
+
+	inFlight := make(chan struct{})
+	done := make(chan struct{})
+	go b.Maybe(context.Background(), func() {
+		close(inFlight)
+		<-done
+	})
+	<-inFlight
+	if got, want := b.Available(), 1; got != want {
+		t.Errorf("Available() = %d, want: %d", got, want)
+	}
+	close(done)
+}
+
 func TestBreakerUpdateConcurrencyOverlow(t *testing.T) {
 	params := BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 0}
 	b := NewBreaker(params)
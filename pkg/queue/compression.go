@@ -0,0 +1,258 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"knative.dev/serving/pkg/network"
+)
+
+// minCompressSize is the Content-Length below which responses are sent as
+// is, compressing them saves next to nothing.
+const minCompressSize = 1024
+
+// encoder is implemented by the writers of compress/gzip and compress/zlib.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// encoderPools hold the encoders of the supported content codings, they
+// are costly to allocate.
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}},
+	// The "deflate" content coding is the zlib format, not raw deflate.
+	"deflate": {New: func() interface{} {
+		return zlib.NewWriter(nil)
+	}},
+}
+
+// CompressionHandler returns a Handler compressing the responses of h with
+// the first of codings the client accepts in its Accept-Encoding header.
+// Responses that are small, encoded already or not of a textual type are
+// sent as is, as are the responses to probes, HEAD, range and upgrade
+// requests. The size of every compressed response before and after
+// compression is reported to onCompressed.
+func CompressionHandler(h http.Handler, codings []string, onCompressed func(in, out int64)) http.Handler {
+	var supported []string
+	for _, c := range codings {
+		if c = strings.TrimSpace(c); encoderPools[c] != nil {
+			supported = append(supported, c)
+		}
+	}
+	return &compressionHandler{
+		handler:      h,
+		codings:      supported,
+		onCompressed: onCompressed,
+	}
+}
+
+type compressionHandler struct {
+	handler      http.Handler
+	codings      []string
+	onCompressed func(in, out int64)
+}
+
+func (h *compressionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" ||
+		network.IsKubeletProbe(r) || r.Header.Get(network.ProbeHeaderName) != "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+	cw := &compressWriter{
+		ResponseWriter: w,
+		coding:         negotiateCoding(r.Header.Get("Accept-Encoding"), h.codings),
+	}
+	defer func() {
+		if in, out, ok := cw.close(); ok && h.onCompressed != nil {
+			h.onCompressed(in, out)
+		}
+	}()
+	h.handler.ServeHTTP(cw, r)
+}
+
+// negotiateCoding returns the first of codings the Accept-Encoding header
+// accept allows, or "" if none.
+func negotiateCoding(accept string, codings []string) string {
+	if accept == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		coding, params := part, ""
+		if i := strings.Index(part, ";"); i >= 0 {
+			coding, params = part[:i], part[i+1:]
+		}
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			v, err := strconv.ParseFloat(params[len("q="):], 64)
+			if err != nil {
+				continue
+			}
+			q = v
+		}
+		weights[strings.ToLower(strings.TrimSpace(coding))] = q
+	}
+	for _, c := range codings {
+		q, ok := weights[c]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > 0 {
+			return c
+		}
+	}
+	return ""
+}
+
+// compressWriter is an http.ResponseWriter that decides whether to
+// compress the response once its headers are written. Responses are never
+// compressed if coding is "".
+type compressWriter struct {
+	http.ResponseWriter
+	coding string
+
+	wroteHeader bool
+	enc         encoder
+	in          int64
+	out         countingWriter
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if compressible(code, h) {
+		// Whether compressed or not, the response depends on
+		// Accept-Encoding.
+		addVary(h, "Accept-Encoding")
+		if w.coding != "" {
+			h.Del("Content-Length")
+			h.Del("Accept-Ranges")
+			h.Set("Content-Encoding", w.coding)
+			w.out.w = w.ResponseWriter
+			w.enc = encoderPools[w.coding].Get().(encoder)
+			w.enc.Reset(&w.out)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements http.ResponseWriter.
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		// Sniff the type of the uncompressed body, like net/http would.
+		if _, ok := w.Header()["Content-Type"]; !ok && len(p) > 0 {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.enc == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	w.in += int64(len(p))
+	return w.enc.Write(p)
+}
+
+// Flush implements http.Flusher, for streamed responses.
+func (w *compressWriter) Flush() {
+	if w.enc != nil {
+		w.enc.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close finishes the compressed stream, if any, and returns the size of the
+// response before and after compression.
+func (w *compressWriter) close() (int64, int64, bool) {
+	if w.enc == nil {
+		return 0, 0, false
+	}
+	w.enc.Close()
+	w.enc.Reset(nil)
+	encoderPools[w.coding].Put(w.enc)
+	w.enc = nil
+	return w.in, w.out.n, true
+}
+
+// addVary adds header to the Vary header of h, unless it is listed already.
+func addVary(h http.Header, header string) {
+	for _, v := range h["Vary"] {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, header) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", header)
+}
+
+// compressible returns whether a response with the given status code and
+// headers is worth compressing.
+func compressible(code int, h http.Header) bool {
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.ParseInt(cl, 10, 64); err == nil && n < minCompressSize {
+			return false
+		}
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "/json"), strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "/xml"), strings.HasSuffix(mediaType, "+xml"),
+		strings.HasSuffix(mediaType, "/javascript"):
+		return true
+	}
+	return false
+}
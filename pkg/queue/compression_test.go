@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestNegotiateCoding(t *testing.T) {
+	codings := []string{"gzip", "deflate"}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate, gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip;q=0, deflate;q=0.5", "deflate"},
+		{"GZIP", "gzip"},
+		{"br", ""},
+		{"*", "gzip"},
+		{"*;q=0", ""},
+		{"identity", ""},
+	}
+
+	for _, test := range tests {
+		if got := negotiateCoding(test.accept, codings); got != test.want {
+			t.Errorf("negotiateCoding(%q) = %q, want: %q", test.accept, got, test.want)
+		}
+	}
+}
+
+func TestCompressionHandler(t *testing.T) {
+	body := strings.Repeat("Hello, compressed world! ", 100)
+
+	tests := []struct {
+		name         string
+		accept       string
+		method       string
+		probe        bool
+		contentType  string
+		encoding     string
+		short        bool
+		wantEncoding string
+		wantVary     bool
+	}{{
+		name:         "gzip",
+		accept:       "gzip",
+		contentType:  "text/plain",
+		wantEncoding: "gzip",
+		wantVary:     true,
+	}, {
+		name:         "deflate",
+		accept:       "deflate",
+		contentType:  "application/json; charset=utf-8",
+		wantEncoding: "deflate",
+		wantVary:     true,
+	}, {
+		name:         "sniffed content type",
+		accept:       "gzip",
+		wantEncoding: "gzip",
+		wantVary:     true,
+	}, {
+		name:        "not accepted",
+		accept:      "br",
+		contentType: "text/plain",
+		wantVary:    true,
+	}, {
+		name:        "not compressible",
+		accept:      "gzip",
+		contentType: "image/png",
+	}, {
+		name:        "encoded already",
+		accept:      "gzip",
+		contentType: "text/plain",
+		encoding:    "identity",
+	}, {
+		name:        "small",
+		accept:      "gzip",
+		contentType: "text/plain",
+		short:       true,
+	}, {
+		name:        "head",
+		accept:      "gzip",
+		method:      http.MethodHead,
+		contentType: "text/plain",
+	}, {
+		name:        "probe",
+		accept:      "gzip",
+		probe:       true,
+		contentType: "text/plain",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			respBody := body
+			if test.short {
+				respBody = "short"
+			}
+			var gotIn, gotOut int64
+			h := CompressionHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.contentType != "" {
+					w.Header().Set("Content-Type", test.contentType)
+				}
+				if test.encoding != "" {
+					w.Header().Set("Content-Encoding", test.encoding)
+				}
+				w.Header().Set("Content-Length", strconv.Itoa(len(respBody)))
+				io.WriteString(w, respBody)
+			}), []string{"gzip", " deflate"}, func(in, out int64) {
+				gotIn, gotOut = in, out
+			})
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, "http://example.com", nil)
+			req.Header.Set("Accept-Encoding", test.accept)
+			if test.probe {
+				req.Header.Set(network.ProbeHeaderName, Name)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			if got := resp.Header.Get("Content-Encoding"); got != test.wantEncoding && got != test.encoding {
+				t.Fatalf("Content-Encoding = %q, want: %q", got, test.wantEncoding)
+			}
+			if got := resp.Header.Get("Vary") == "Accept-Encoding"; got != test.wantVary {
+				t.Errorf("Vary = %q, want Accept-Encoding: %v", resp.Header.Get("Vary"), test.wantVary)
+			}
+
+			var r io.Reader = resp.Body
+			switch test.wantEncoding {
+			case "gzip":
+				gr, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() = %v", err)
+				}
+				r = gr
+			case "deflate":
+				zr, err := zlib.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("zlib.NewReader() = %v", err)
+				}
+				r = zr
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Error reading body: %v", err)
+			}
+			if test.method != http.MethodHead && string(got) != respBody {
+				t.Errorf("Body = %q, want: %q", got, respBody)
+			}
+
+			if test.wantEncoding == "" {
+				if gotIn != 0 || gotOut != 0 {
+					t.Errorf("Reported (%d, %d) for an uncompressed response", gotIn, gotOut)
+				}
+				return
+			}
+			if resp.Header.Get("Content-Length") != "" {
+				t.Error("Content-Length of the uncompressed body was kept")
+			}
+			if gotIn != int64(len(respBody)) || gotOut != int64(rec.Body.Len()) {
+				t.Errorf("Reported (%d, %d), want: (%d, %d)", gotIn, gotOut, len(respBody), rec.Body.Len())
+			}
+			if gotOut >= gotIn {
+				t.Errorf("Compressed %d bytes into %d", gotIn, gotOut)
+			}
+		})
+	}
+}
@@ -26,4 +26,8 @@ const (
 	// Main usage is to delay the termination of user-container until all
 	// accepted requests have been processed.
 	RequestQueueDrainPath = "/wait-for-drain"
+
+	// DebugPath is the path prefix of the pprof and expvar endpoints of the
+	// admin server. See DebugHandler.
+	DebugPath = "/debug/"
 )
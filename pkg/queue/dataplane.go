@@ -0,0 +1,218 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+const (
+	// DataPlaneConfigVolumePath is where the ConfigMap holding the data-plane
+	// configuration of the revision is mounted into the queue-proxy.
+	DataPlaneConfigVolumePath = "/var/run/knative-dataplane"
+
+	// DataPlaneConfigKey is the key of the ConfigMap, and the file of
+	// DataPlaneConfigVolumePath, holding the data-plane configuration.
+	DataPlaneConfigKey = "config"
+
+	// DataPlaneSignatureKey is the key of the ConfigMap, and the file of
+	// DataPlaneConfigVolumePath, holding the signature of the data-plane
+	// configuration.
+	DataPlaneSignatureKey = "signature"
+
+	// DataPlaneKeyKey is the key of the Secret of the revision, and the file
+	// of SecretVolumePath, holding the key the data-plane configuration is
+	// signed with.
+	DataPlaneKeyKey = "dataplane-key"
+
+	// DataPlaneConfigPeriod is how often the queue-proxy checks for a new
+	// data-plane configuration. The kubelet refreshes the mounted ConfigMap on
+	// its own schedule, which usually takes longer.
+	DataPlaneConfigPeriod = 5 * time.Second
+)
+
+// DataPlaneConfig holds the settings of the queue-proxy that change without
+// restarting it.
+type DataPlaneConfig struct {
+	// TimeoutSeconds is the time a request gets to get its first byte of
+	// response.
+	TimeoutSeconds int64 `json:"timeoutSeconds"`
+	// RateLimit is the number of requests per second the pod takes, unlimited
+	// when zero.
+	RateLimit float64 `json:"rateLimit,omitempty"`
+	// RateLimitBurst is the number of requests the pod takes at once, the
+	// rate limit rounded up when zero.
+	RateLimitBurst int `json:"rateLimitBurst,omitempty"`
+	// LogLevel overrides the level the queue-proxy logs at, the level it
+	// started with when empty.
+	LogLevel string `json:"logLevel,omitempty"`
+	// RequestMetricsBackend is the backend the request metrics are exported
+	// to. Request metrics are only turned on or off when the queue-proxy
+	// starts.
+	RequestMetricsBackend string `json:"requestMetricsBackend,omitempty"`
+	// Tracing is the configuration of the spans the queue-proxy records.
+	Tracing tracingconfig.Config `json:"tracing"`
+}
+
+// EncodeDataPlaneConfig returns the serialized data-plane configuration and
+// its signature with key.
+func EncodeDataPlaneConfig(cfg *DataPlaneConfig, key []byte) (data []byte, signature []byte, err error) {
+	data, err = json.Marshal(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, []byte(signDataPlaneConfig(data, key)), nil
+}
+
+// DecodeDataPlaneConfig returns the data-plane configuration serialized in
+// data, provided it is signed with key.
+func DecodeDataPlaneConfig(data, signature, key []byte) (*DataPlaneConfig, error) {
+	if !hmac.Equal(bytes.TrimSpace(signature), []byte(signDataPlaneConfig(data, key))) {
+		return nil, errors.New("the signature of the data-plane configuration doesn't match")
+	}
+	cfg := &DataPlaneConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	if cfg.TimeoutSeconds <= 0 || cfg.RateLimit < 0 || cfg.RateLimitBurst < 0 ||
+		cfg.Tracing.SampleRate < 0 || cfg.Tracing.SampleRate > 1 {
+		return nil, errors.New("the data-plane configuration is out of bounds")
+	}
+	if cfg.LogLevel != "" {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// ReadDataPlaneConfig reads the data-plane configuration signed with key
+// from dir.
+func ReadDataPlaneConfig(dir string, key []byte) (*DataPlaneConfig, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, DataPlaneConfigKey))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ioutil.ReadFile(filepath.Join(dir, DataPlaneSignatureKey))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDataPlaneConfig(data, signature, key)
+}
+
+func signDataPlaneConfig(data, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DataPlaneConfigWatcher keeps an HTTP handler built from the data-plane
+// configuration mounted into the queue-proxy up to date, or built from
+// the configuration it started with when none is mounted.
+type DataPlaneConfigWatcher struct {
+	dir    string
+	key    func() ([]byte, error)
+	build  func(DataPlaneConfig) http.Handler
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	current DataPlaneConfig
+	handler atomic.Value
+}
+
+// NewDataPlaneConfigWatcher creates a DataPlaneConfigWatcher reading the
+// configuration signed with the key returned by key from dir, and building
+// the handler with build, from initial until another configuration is read.
+func NewDataPlaneConfigWatcher(dir string, key func() ([]byte, error), initial DataPlaneConfig,
+	build func(DataPlaneConfig) http.Handler, logger *zap.SugaredLogger) *DataPlaneConfigWatcher {
+	w := &DataPlaneConfigWatcher{
+		dir:     dir,
+		key:     key,
+		build:   build,
+		logger:  logger,
+		current: initial,
+	}
+	w.handler.Store(build(initial))
+	return w
+}
+
+// ServeHTTP serves the request with the handler of the current configuration.
+func (w *DataPlaneConfigWatcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.handler.Load().(http.Handler).ServeHTTP(rw, r)
+}
+
+// Run updates the configuration every period until stopCh is closed.
+func (w *DataPlaneConfigWatcher) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		w.Update()
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Update rebuilds the handler if the mounted configuration changed. Invalid
+// configurations are ignored, keeping the current one.
+func (w *DataPlaneConfigWatcher) Update() {
+	key, err := w.key()
+	if os.IsNotExist(err) {
+		// The Secret holding the key isn't mounted yet.
+		return
+	} else if err != nil {
+		w.logger.Warnw("Failed to read the data-plane configuration key", zap.Error(err))
+		return
+	}
+	cfg, err := ReadDataPlaneConfig(w.dir, key)
+	if os.IsNotExist(err) {
+		return
+	} else if err != nil {
+		w.logger.Warnw("Ignoring the invalid data-plane configuration", zap.Error(err))
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if reflect.DeepEqual(*cfg, w.current) {
+		return
+	}
+	w.logger.Infof("Updating the data-plane configuration from %+v to %+v.", w.current, *cfg)
+	w.current = *cfg
+	w.handler.Store(w.build(*cfg))
+}
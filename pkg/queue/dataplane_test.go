@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	. "knative.dev/pkg/logging/testing"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+func TestDataPlaneConfigRoundTrip(t *testing.T) {
+	key, otherKey := []byte("key"), []byte("other-key")
+	want := &DataPlaneConfig{
+		TimeoutSeconds:        60,
+		RateLimit:             2.5,
+		RateLimitBurst:        5,
+		LogLevel:              "debug",
+		RequestMetricsBackend: "prometheus",
+		Tracing: tracingconfig.Config{
+			Enable:         true,
+			ZipkinEndpoint: "http://zipkin.istio-system.svc.cluster.local:9411/api/v2/spans",
+			SampleRate:     0.5,
+		},
+	}
+	data, signature, err := EncodeDataPlaneConfig(want, key)
+	if err != nil {
+		t.Fatal("EncodeDataPlaneConfig() =", err)
+	}
+
+	got, err := DecodeDataPlaneConfig(data, signature, key)
+	if err != nil {
+		t.Fatal("DecodeDataPlaneConfig() =", err)
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("DecodeDataPlaneConfig() = %+v, want %+v", got, want)
+	}
+
+	if _, err := DecodeDataPlaneConfig(data, signature, otherKey); err == nil {
+		t.Error("DecodeDataPlaneConfig() = nil, wanted an error for the wrong key")
+	}
+	if _, err := DecodeDataPlaneConfig([]byte(`{"timeoutSeconds":600}`), signature, key); err == nil {
+		t.Error("DecodeDataPlaneConfig() = nil, wanted an error for a tampered configuration")
+	}
+	data, signature, _ = EncodeDataPlaneConfig(&DataPlaneConfig{TimeoutSeconds: -1}, key)
+	if _, err := DecodeDataPlaneConfig(data, signature, key); err == nil {
+		t.Error("DecodeDataPlaneConfig() = nil, wanted an error for a negative timeout")
+	}
+	data, signature, _ = EncodeDataPlaneConfig(&DataPlaneConfig{TimeoutSeconds: 1, LogLevel: "loud"}, key)
+	if _, err := DecodeDataPlaneConfig(data, signature, key); err == nil {
+		t.Error("DecodeDataPlaneConfig() = nil, wanted an error for an invalid log level")
+	}
+}
+
+func TestDataPlaneConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dataplane")
+	if err != nil {
+		t.Fatal("Failed to create the directory:", err)
+	}
+	defer os.RemoveAll(dir)
+	key := []byte("key")
+
+	builds := 0
+	w := NewDataPlaneConfigWatcher(dir, func() ([]byte, error) { return key, nil }, DataPlaneConfig{TimeoutSeconds: 300},
+		func(cfg DataPlaneConfig) http.Handler {
+			builds++
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, cfg.TimeoutSeconds)
+			})
+		}, TestLogger(t))
+
+	write := func(cfg *DataPlaneConfig, signKey []byte) {
+		data, signature, err := EncodeDataPlaneConfig(cfg, signKey)
+		if err != nil {
+			t.Fatal("EncodeDataPlaneConfig() =", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, DataPlaneConfigKey), data, 0644); err != nil {
+			t.Fatal("Failed to write the configuration:", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, DataPlaneSignatureKey), signature, 0644); err != nil {
+			t.Fatal("Failed to write the signature:", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		write      func()
+		want       string
+		wantBuilds int
+	}{{
+		name:       "no file",
+		want:       "300",
+		wantBuilds: 1,
+	}, {
+		name:       "new timeout",
+		write:      func() { write(&DataPlaneConfig{TimeoutSeconds: 30}, key) },
+		want:       "30",
+		wantBuilds: 2,
+	}, {
+		name:       "unchanged",
+		want:       "30",
+		wantBuilds: 2,
+	}, {
+		name:       "wrong signature is ignored",
+		write:      func() { write(&DataPlaneConfig{TimeoutSeconds: 3000}, []byte("other-key")) },
+		want:       "30",
+		wantBuilds: 2,
+	}, {
+		name:       "another timeout",
+		write:      func() { write(&DataPlaneConfig{TimeoutSeconds: 60, RateLimit: 10}, key) },
+		want:       "60",
+		wantBuilds: 3,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.write != nil {
+				test.write()
+			}
+			w.Update()
+
+			rec := httptest.NewRecorder()
+			w.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := rec.Body.String(); got != test.want {
+				t.Errorf("Response = %q, want %q", got, test.want)
+			}
+			if builds != test.wantBuilds {
+				t.Errorf("Builds = %d, want %d", builds, test.wantBuilds)
+			}
+		})
+	}
+}
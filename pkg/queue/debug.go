@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// DebugHandler returns a Handler serving the pprof profiles and the expvar
+// variables of the queue-proxy under DebugPath, to requests bearing token
+// in their Authorization header. Other requests, and all of them when token
+// is empty, are rejected with 401 Unauthorized.
+func DebugHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(DebugPath+"pprof/", pprof.Index)
+	mux.HandleFunc(DebugPath+"pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc(DebugPath+"pprof/profile", pprof.Profile)
+	mux.HandleFunc(DebugPath+"pprof/symbol", pprof.Symbol)
+	mux.HandleFunc(DebugPath+"pprof/trace", pprof.Trace)
+	mux.Handle(DebugPath+"vars", expvar.Handler())
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
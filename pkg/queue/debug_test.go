@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	h := DebugHandler("s3cr3t")
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{{
+		name: "no token",
+		path: DebugPath + "vars",
+		want: http.StatusUnauthorized,
+	}, {
+		name:          "wrong token",
+		path:          DebugPath + "vars",
+		authorization: "Bearer guess",
+		want:          http.StatusUnauthorized,
+	}, {
+		name:          "expvar",
+		path:          DebugPath + "vars",
+		authorization: "Bearer s3cr3t",
+		want:          http.StatusOK,
+	}, {
+		name:          "pprof index",
+		path:          DebugPath + "pprof/",
+		authorization: "Bearer s3cr3t",
+		want:          http.StatusOK,
+	}, {
+		name:          "goroutine profile",
+		path:          DebugPath + "pprof/goroutine?debug=1",
+		authorization: "Bearer s3cr3t",
+		want:          http.StatusOK,
+	}, {
+		name:          "unknown path",
+		path:          DebugPath + "nope",
+		authorization: "Bearer s3cr3t",
+		want:          http.StatusNotFound,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8022"+test.path, nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != test.want {
+				t.Errorf("Status = %d, want: %d", rec.Code, test.want)
+			}
+		})
+	}
+}
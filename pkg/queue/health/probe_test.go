@@ -17,9 +17,13 @@ limitations under the License.
 package health
 
 import (
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -51,6 +55,52 @@ func TestTCPProbe(t *testing.T) {
 	}
 }
 
+func TestSocketProbes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "socket")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Listener = l
+	server.Start()
+	defer server.Close()
+
+	// The addresses don't listen, the probes must go to the socket.
+	tcpConfig := TCPProbeConfigOptions{
+		Address:       "127.0.0.1:0",
+		SocketTimeout: time.Second,
+		SocketPath:    path,
+	}
+	if err := TCPProbe(tcpConfig); err != nil {
+		t.Errorf("TCPProbe() = %v", err)
+	}
+	httpConfig := HTTPProbeConfigOptions{
+		Timeout:       time.Second,
+		HTTPGetAction: newHTTPGetAction(t, "http://127.0.0.1:0"),
+		SocketPath:    path,
+	}
+	if err := HTTPProbe(httpConfig); err != nil {
+		t.Errorf("HTTPProbe() = %v", err)
+	}
+
+	server.Close()
+	if err := TCPProbe(tcpConfig); err == nil {
+		t.Error("Expected TCP probe to fail but it didn't")
+	}
+	if err := HTTPProbe(httpConfig); err == nil {
+		t.Error("Expected HTTP probe to fail but it didn't")
+	}
+}
+
 func TestHTTPProbeSuccess(t *testing.T) {
 	var gotHeader corev1.HTTPHeader
 	var gotKubeletHeader bool
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+const (
+	// PodInfoVolumePath is where the downward API projects the metadata of
+	// the pod into the queue-proxy container.
+	PodInfoVolumePath = "/var/run/knative-podinfo"
+
+	// PodAnnotationsFile is the file of PodInfoVolumePath holding the
+	// annotations of the pod.
+	PodAnnotationsFile = "annotations"
+
+	// LogLevelPeriod is how often the queue-proxy checks the annotations of
+	// its pod for a new log level. The kubelet refreshes the file on its own
+	// schedule, which usually takes longer.
+	LogLevelPeriod = 5 * time.Second
+)
+
+// LogLevelWatcher sets the log level of the queue-proxy to the one annotated
+// on its pod with serving.QueueLogLevelAnnotationKey, or back to the level it
+// started with once the annotation is gone.
+type LogLevelWatcher struct {
+	path         string
+	atomicLevel  zap.AtomicLevel
+	defaultLevel zapcore.Level
+	logger       *zap.SugaredLogger
+}
+
+// NewLogLevelWatcher creates a LogLevelWatcher reading the annotations of the
+// pod from path and setting atomicLevel accordingly.
+func NewLogLevelWatcher(path string, atomicLevel zap.AtomicLevel, logger *zap.SugaredLogger) *LogLevelWatcher {
+	return &LogLevelWatcher{
+		path:         path,
+		atomicLevel:  atomicLevel,
+		defaultLevel: atomicLevel.Level(),
+		logger:       logger,
+	}
+}
+
+// Run updates the log level every period until stopCh is closed.
+func (w *LogLevelWatcher) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		w.Update()
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Update sets the log level to the one currently annotated on the pod.
+func (w *LogLevelWatcher) Update() {
+	annotations, err := readAnnotations(w.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			w.logger.Warnw("Failed to read the annotations of the pod", zap.Error(err))
+		}
+		return
+	}
+	level := w.defaultLevel
+	if v, ok := annotations[serving.QueueLogLevelAnnotationKey]; ok {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			w.logger.Warnw("Ignoring the invalid log level of the pod", zap.String("level", v))
+			return
+		}
+	}
+	if w.atomicLevel.Level() != level {
+		w.logger.Infof("Updating logging level from %v to %v.", w.atomicLevel.Level(), level)
+		w.atomicLevel.SetLevel(level)
+	}
+}
+
+// readAnnotations parses the annotations the downward API writes to path, one
+// `key="value"` line per annotation, with the values quoted as Go strings.
+func readAnnotations(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	annotations := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		v, err := strconv.Unquote(parts[1])
+		if err != nil {
+			continue
+		}
+		annotations[parts[0]] = v
+	}
+	return annotations, scanner.Err()
+}
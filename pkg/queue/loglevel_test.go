@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+func TestLogLevelWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "podinfo")
+	if err != nil {
+		t.Fatal("Failed to create the directory:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, PodAnnotationsFile)
+
+	atomicLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	w := NewLogLevelWatcher(path, atomicLevel, TestLogger(t))
+
+	tests := []struct {
+		name        string
+		annotations string
+		want        zapcore.Level
+	}{{
+		name: "no file",
+		want: zapcore.InfoLevel,
+	}, {
+		name:        "no annotation",
+		annotations: "serving.knative.dev/creator=\"someone\"\n",
+		want:        zapcore.InfoLevel,
+	}, {
+		name:        "debug",
+		annotations: "queue.sidecar.serving.knative.dev/logLevel=\"debug\"\nserving.knative.dev/creator=\"someone\"\n",
+		want:        zapcore.DebugLevel,
+	}, {
+		name:        "invalid level is ignored",
+		annotations: "queue.sidecar.serving.knative.dev/logLevel=\"loud\"\n",
+		want:        zapcore.DebugLevel,
+	}, {
+		name:        "quoted value",
+		annotations: "multiline=\"a\\nb=\\\"c\\\"\"\nqueue.sidecar.serving.knative.dev/logLevel=\"error\"\n",
+		want:        zapcore.ErrorLevel,
+	}, {
+		name:        "annotation removed",
+		annotations: "serving.knative.dev/creator=\"someone\"\n",
+		want:        zapcore.InfoLevel,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.annotations != "" {
+				if err := ioutil.WriteFile(path, []byte(test.annotations), 0644); err != nil {
+					t.Fatal("Failed to write the annotations:", err)
+				}
+			}
+			w.Update()
+			if got := atomicLevel.Level(); got != test.want {
+				t.Errorf("Level = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
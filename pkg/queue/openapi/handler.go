@@ -0,0 +1,68 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"knative.dev/serving/pkg/queue"
+)
+
+// maxBodySize is the size of the largest request body that is validated.
+const maxBodySize = 10 << 20
+
+// NewHandler returns a Handler passing the requests that conform to the
+// document of v on to h. Other requests are rejected with the status code
+// of their Error, those with bodies over maxBodySize with 413 Request
+// Entity Too Large. The network probes signed with probeKey are never
+// validated.
+func NewHandler(h http.Handler, v *Validator, probeKey []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if queue.IsSignedProbe(r, probeKey) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		if r.Body != nil && r.Body != http.NoBody {
+			var err error
+			body, err = ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+			if err != nil {
+				http.Error(w, "error reading request body", http.StatusBadRequest)
+				return
+			}
+			if len(body) > maxBodySize {
+				http.Error(w, "request body too large to validate", http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		if err := v.Validate(r, body); err != nil {
+			status := http.StatusBadRequest
+			if verr, ok := err.(*Error); ok {
+				status = verr.Status
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-openapi/spec"
+)
+
+// patterns caches the compiled patterns of the document.
+var patterns sync.Map
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// parseSimple converts the values of a non-body parameter to the type
+// described by s, like encoding/json would.
+func parseSimple(values []string, s *spec.SimpleSchema) (interface{}, error) {
+	if s.Type != "array" {
+		return parseScalar(values[0], s.Type)
+	}
+	if s.CollectionFormat != "multi" {
+		sep := ","
+		switch s.CollectionFormat {
+		case "ssv":
+			sep = " "
+		case "tsv":
+			sep = "\t"
+		case "pipes":
+			sep = "|"
+		}
+		values = strings.Split(values[0], sep)
+	}
+	itemType := ""
+	if s.Items != nil {
+		itemType = s.Items.Type
+	}
+	items := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		item, err := parseScalar(v, itemType)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func parseScalar(value, typ string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", value)
+		}
+		return float64(i), nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+// validateSimple checks the value of a non-body parameter.
+func validateSimple(path string, value interface{}, s *spec.SimpleSchema, v *spec.CommonValidations) error {
+	return validateSchema(path, simpleSchema(s, v), value)
+}
+
+// simpleSchema converts the description of a non-body parameter into a
+// schema.
+func simpleSchema(s *spec.SimpleSchema, v *spec.CommonValidations) *spec.Schema {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Format:           s.Format,
+		Maximum:          v.Maximum,
+		ExclusiveMaximum: v.ExclusiveMaximum,
+		Minimum:          v.Minimum,
+		ExclusiveMinimum: v.ExclusiveMinimum,
+		MaxLength:        v.MaxLength,
+		MinLength:        v.MinLength,
+		Pattern:          v.Pattern,
+		MaxItems:         v.MaxItems,
+		MinItems:         v.MinItems,
+		UniqueItems:      v.UniqueItems,
+		MultipleOf:       v.MultipleOf,
+		Enum:             v.Enum,
+	}}
+	if s.Type != "" && s.Type != "file" {
+		schema.Type = spec.StringOrArray{s.Type}
+	}
+	if s.Items != nil {
+		schema.Items = &spec.SchemaOrArray{
+			Schema: simpleSchema(&s.Items.SimpleSchema, &s.Items.CommonValidations),
+		}
+	}
+	return schema
+}
+
+// validateSchema checks the JSON value v, as decoded by encoding/json,
+// against the schema s. path locates v in the request for error messages.
+func validateSchema(path string, s *spec.Schema, v interface{}) error {
+	// References left after expanding the document are recursive, they
+	// aren't followed.
+	if s == nil || s.Ref.String() != "" {
+		return nil
+	}
+	for i := range s.AllOf {
+		if err := validateSchema(path, &s.AllOf[i], v); err != nil {
+			return err
+		}
+	}
+
+	if v == nil {
+		if nullable, _ := s.Extensions.GetBool("x-nullable"); nullable || len(s.Type) == 0 || s.Type.Contains("null") {
+			return nil
+		}
+		return fmt.Errorf("%s must not be null", path)
+	}
+	if len(s.Type) > 0 && !matchesType(s.Type, v) {
+		return fmt.Errorf("%s must be of type %s", path, strings.Join(s.Type, " or "))
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		return fmt.Errorf("%s must be one of %v", path, s.Enum)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return validateObject(path, s, v)
+	case []interface{}:
+		return validateArray(path, s, v)
+	case string:
+		return validateString(path, s, v)
+	case float64:
+		return validateNumber(path, s, v)
+	}
+	return nil
+}
+
+func matchesType(types spec.StringOrArray, v interface{}) bool {
+	for _, t := range types {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		// Integers in the document are decoded as float64, like v.
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateObject(path string, s *spec.Schema, v map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			return fmt.Errorf("%s.%s is required", path, name)
+		}
+	}
+	if s.MinProperties != nil && int64(len(v)) < *s.MinProperties {
+		return fmt.Errorf("%s must have at least %d properties", path, *s.MinProperties)
+	}
+	if s.MaxProperties != nil && int64(len(v)) > *s.MaxProperties {
+		return fmt.Errorf("%s must have at most %d properties", path, *s.MaxProperties)
+	}
+
+	// Sorted, for the first error to be stable.
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		switch {
+		case ok:
+			if err := validateSchema(path+"."+name, &prop, v[name]); err != nil {
+				return err
+			}
+		case s.AdditionalProperties == nil:
+		case s.AdditionalProperties.Schema != nil:
+			if err := validateSchema(path+"."+name, s.AdditionalProperties.Schema, v[name]); err != nil {
+				return err
+			}
+		case !s.AdditionalProperties.Allows:
+			return fmt.Errorf("%s.%s is not allowed", path, name)
+		}
+	}
+	return nil
+}
+
+func validateArray(path string, s *spec.Schema, v []interface{}) error {
+	if s.MinItems != nil && int64(len(v)) < *s.MinItems {
+		return fmt.Errorf("%s must have at least %d items", path, *s.MinItems)
+	}
+	if s.MaxItems != nil && int64(len(v)) > *s.MaxItems {
+		return fmt.Errorf("%s must have at most %d items", path, *s.MaxItems)
+	}
+	if s.UniqueItems {
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					return fmt.Errorf("%s must have unique items", path)
+				}
+			}
+		}
+	}
+	if s.Items == nil {
+		return nil
+	}
+	for i, item := range v {
+		items := s.Items.Schema
+		if items == nil {
+			// A tuple, items past its end aren't checked.
+			if i >= len(s.Items.Schemas) {
+				break
+			}
+			items = &s.Items.Schemas[i]
+		}
+		if err := validateSchema(fmt.Sprintf("%s[%d]", path, i), items, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(path string, s *spec.Schema, v string) error {
+	n := int64(utf8.RuneCountInString(v))
+	if s.MinLength != nil && n < *s.MinLength {
+		return fmt.Errorf("%s must be at least %d characters long", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return fmt.Errorf("%s must be at most %d characters long", path, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		// An invalid pattern is a mistake in the document, not the request.
+		if err == nil && !re.MatchString(v) {
+			return fmt.Errorf("%s must match %s", path, s.Pattern)
+		}
+	}
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return fmt.Errorf("%s must be a date-time", path)
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return fmt.Errorf("%s must be a date", path)
+		}
+	}
+	return nil
+}
+
+func validateNumber(path string, s *spec.Schema, v float64) error {
+	if s.Minimum != nil {
+		if min := *s.Minimum; v < min || (s.ExclusiveMinimum && v == min) {
+			bound := "at least"
+			if s.ExclusiveMinimum {
+				bound = "greater than"
+			}
+			return fmt.Errorf("%s must be %s %v", path, bound, min)
+		}
+	}
+	if s.Maximum != nil {
+		if max := *s.Maximum; v > max || (s.ExclusiveMaximum && v == max) {
+			bound := "at most"
+			if s.ExclusiveMaximum {
+				bound = "less than"
+			}
+			return fmt.Errorf("%s must be %s %v", path, bound, max)
+		}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		if q := v / *s.MultipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+			return fmt.Errorf("%s must be a multiple of %v", path, *s.MultipleOf)
+		}
+	}
+	return nil
+}
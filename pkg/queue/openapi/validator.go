@@ -0,0 +1,321 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi validates the requests the queue-proxy receives against
+// an OpenAPI (Swagger 2.0) document.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/go-openapi/spec"
+)
+
+// Error is a request that does not conform to the OpenAPI document.
+type Error struct {
+	// Status is the HTTP status code to reject the request with.
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func errorf(status int, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validator validates requests against the operations of an OpenAPI
+// document.
+type Validator struct {
+	basePath string
+	consumes []string
+	routes   []route
+}
+
+// route is a path of the document, split into segments. Path parameters
+// are segments like "{name}".
+type route struct {
+	segments []string
+	item     spec.PathItem
+}
+
+// Load reads the OpenAPI document at path, in YAML or JSON, and returns a
+// Validator for it.
+func Load(path string) (*Validator, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse returns a Validator for the OpenAPI document b, in YAML or JSON.
+// References within the document are resolved, only Swagger 2.0 documents
+// are supported.
+func Parse(b []byte) (*Validator, error) {
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	var doc spec.Swagger
+	if err := json.Unmarshal(j, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Swagger != "2.0" {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, want 2.0", doc.Swagger)
+	}
+	if err := spec.ExpandSpec(&doc, nil); err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		basePath: strings.TrimSuffix(doc.BasePath, "/"),
+		consumes: doc.Consumes,
+	}
+	if doc.Paths != nil {
+		for p, item := range doc.Paths.Paths {
+			v.routes = append(v.routes, route{
+				segments: strings.Split(strings.Trim(p, "/"), "/"),
+				item:     item,
+			})
+		}
+	}
+	// Literal segments take precedence over path parameters, e.g.
+	// /users/me over /users/{id}.
+	sort.Slice(v.routes, func(i, j int) bool {
+		return routeLess(v.routes[i].segments, v.routes[j].segments)
+	})
+	return v, nil
+}
+
+func routeLess(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if pa, pb := isParam(a[i]), isParam(b[i]); pa != pb {
+			return pb
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// match returns the route of p and the values of its path parameters.
+func (v *Validator) match(p string) (*route, map[string]string) {
+	if v.basePath != "" {
+		if p != v.basePath && !strings.HasPrefix(p, v.basePath+"/") {
+			return nil, nil
+		}
+		p = p[len(v.basePath):]
+	}
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i := range v.routes {
+		r := &v.routes[i]
+		if len(r.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for j, s := range r.segments {
+			if isParam(s) {
+				value, err := url.PathUnescape(segments[j])
+				if err != nil || value == "" {
+					matched = false
+					break
+				}
+				params[s[1:len(s)-1]] = value
+			} else if s != segments[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r, params
+		}
+	}
+	return nil, nil
+}
+
+func operation(item *spec.PathItem, method string) *spec.Operation {
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPut:
+		return item.Put
+	case http.MethodPost:
+		return item.Post
+	case http.MethodDelete:
+		return item.Delete
+	case http.MethodOptions:
+		return item.Options
+	case http.MethodHead:
+		return item.Head
+	case http.MethodPatch:
+		return item.Patch
+	}
+	return nil
+}
+
+// Validate checks the request r with the given body against the operation
+// of the document it is meant for. Requests for paths or methods the
+// document doesn't describe are rejected too. Only JSON and URL encoded
+// form bodies are validated.
+func (v *Validator) Validate(r *http.Request, body []byte) error {
+	route, pathParams := v.match(r.URL.Path)
+	if route == nil {
+		return errorf(http.StatusNotFound, "path %s is not part of the API", r.URL.Path)
+	}
+	op := operation(&route.item, r.Method)
+	if op == nil {
+		return errorf(http.StatusMethodNotAllowed, "method %s is not allowed on %s", r.Method, r.URL.Path)
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if len(body) > 0 {
+		consumes := op.Consumes
+		if len(consumes) == 0 {
+			consumes = v.consumes
+		}
+		if len(consumes) > 0 && !matchesMediaType(contentType, consumes) {
+			return errorf(http.StatusUnsupportedMediaType, "content type %q is not supported", contentType)
+		}
+	}
+
+	var form url.Values
+	if mediaType(contentType) == "application/x-www-form-urlencoded" {
+		var err error
+		if form, err = url.ParseQuery(string(body)); err != nil {
+			return errorf(http.StatusBadRequest, "invalid form: %v", err)
+		}
+	}
+	query := r.URL.Query()
+
+	for _, p := range parameters(route.item.Parameters, op.Parameters) {
+		var err error
+		switch p.In {
+		case "path":
+			err = validateParameter(&p, []string{pathParams[p.Name]})
+		case "query":
+			err = validateParameter(&p, query[p.Name])
+		case "header":
+			err = validateParameter(&p, r.Header[http.CanonicalHeaderKey(p.Name)])
+		case "formData":
+			if form != nil {
+				err = validateParameter(&p, form[p.Name])
+			}
+		case "body":
+			err = validateBody(&p, contentType, body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parameters returns the parameters of an operation, including those of
+// its path unless the operation overrides them.
+func parameters(pathParams, opParams []spec.Parameter) []spec.Parameter {
+	params := append([]spec.Parameter(nil), opParams...)
+	for _, pp := range pathParams {
+		overridden := false
+		for _, op := range opParams {
+			if op.Name == pp.Name && op.In == pp.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, pp)
+		}
+	}
+	return params
+}
+
+func mediaType(contentType string) string {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func matchesMediaType(contentType string, mediaTypes []string) bool {
+	mt := mediaType(contentType)
+	for _, m := range mediaTypes {
+		if m = mediaType(m); m == mt || m == "*/*" ||
+			(strings.HasSuffix(m, "/*") && strings.HasPrefix(mt, strings.TrimSuffix(m, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func isJSON(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "" || mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+// validateBody checks body against the schema of the body parameter p.
+func validateBody(p *spec.Parameter, contentType string, body []byte) error {
+	if len(body) == 0 {
+		if p.Required {
+			return errorf(http.StatusBadRequest, "request body is required")
+		}
+		return nil
+	}
+	if p.Schema == nil || !isJSON(contentType) {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return errorf(http.StatusBadRequest, "invalid JSON body: %v", err)
+	}
+	if err := validateSchema("body", p.Schema, value); err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	return nil
+}
+
+// validateParameter checks the values of the non-body parameter p.
+func validateParameter(p *spec.Parameter, values []string) error {
+	if len(values) == 0 || (len(values) == 1 && values[0] == "" && !p.AllowEmptyValue) {
+		if p.Required {
+			return errorf(http.StatusBadRequest, "%s parameter %q is required", p.In, p.Name)
+		}
+		return nil
+	}
+	value, err := parseSimple(values, &p.SimpleSchema)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%s parameter %q: %v", p.In, p.Name, err)
+	}
+	if err := validateSimple(p.In+" parameter "+p.Name, value, &p.SimpleSchema, &p.CommonValidations); err != nil {
+		return errorf(http.StatusBadRequest, "%v", err)
+	}
+	return nil
+}
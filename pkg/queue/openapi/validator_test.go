@@ -0,0 +1,364 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"knative.dev/serving/pkg/network"
+)
+
+const petstore = `
+swagger: "2.0"
+basePath: /api
+consumes:
+- application/json
+definitions:
+  Pet:
+    type: object
+    required: [name]
+    additionalProperties: false
+    properties:
+      name:
+        type: string
+        minLength: 1
+      tag:
+        type: string
+        enum: [cat, dog]
+      age:
+        type: integer
+        minimum: 0
+      born:
+        type: string
+        format: date
+      owners:
+        type: array
+        maxItems: 2
+        items:
+          type: string
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        type: integer
+        maximum: 100
+      - name: tags
+        in: query
+        type: array
+        items:
+          type: string
+    post:
+      parameters:
+      - name: pet
+        in: body
+        required: true
+        schema:
+          $ref: "#/definitions/Pet"
+  /pets/{id}:
+    parameters:
+    - name: id
+      in: path
+      required: true
+      type: integer
+    get:
+      parameters:
+      - name: X-Request-Id
+        in: header
+        required: true
+        type: string
+        pattern: "^[a-f0-9]+$"
+  /pets/mine:
+    get: {}
+`
+
+func TestParse(t *testing.T) {
+	if _, err := Parse([]byte(petstore)); err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	if _, err := Parse([]byte(`{"swagger": "2.0", "paths": {}}`)); err != nil {
+		t.Errorf("Parse() = %v for a JSON document", err)
+	}
+	if _, err := Parse([]byte(`openapi: 3.0.0`)); err == nil {
+		t.Error("Parse() = nil, want an error for OpenAPI 3")
+	}
+	if _, err := Parse([]byte(`swagger: [`)); err == nil {
+		t.Error("Parse() = nil, want an error for invalid YAML")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "openapi.yaml")
+	if err := ioutil.WriteFile(path, []byte(petstore), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load() = %v", err)
+	}
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Load() = nil, want an error for a missing file")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		header      http.Header
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{{
+		name: "list",
+		url:  "/api/pets?limit=10&tags=a,b",
+	}, {
+		name:        "query parameter of the wrong type",
+		url:         "/api/pets?limit=ten",
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: `query parameter "limit": "ten" is not an integer`,
+	}, {
+		name:        "query parameter too large",
+		url:         "/api/pets?limit=1000",
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "query parameter limit must be at most 100",
+	}, {
+		name:        "unknown path",
+		url:         "/api/owners",
+		wantStatus:  http.StatusNotFound,
+		wantMessage: "path /api/owners is not part of the API",
+	}, {
+		name:        "outside of the base path",
+		url:         "/pets",
+		wantStatus:  http.StatusNotFound,
+		wantMessage: "path /pets is not part of the API",
+	}, {
+		name:        "unknown method",
+		method:      http.MethodDelete,
+		url:         "/api/pets",
+		wantStatus:  http.StatusMethodNotAllowed,
+		wantMessage: "method DELETE is not allowed on /api/pets",
+	}, {
+		name:   "path and header parameters",
+		url:    "/api/pets/42",
+		header: http.Header{"X-Request-Id": []string{"abc123"}},
+	}, {
+		name:        "path parameter of the wrong type",
+		url:         "/api/pets/rex",
+		header:      http.Header{"X-Request-Id": []string{"abc123"}},
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: `path parameter "id": "rex" is not an integer`,
+	}, {
+		name: "literal path over path parameter",
+		url:  "/api/pets/mine",
+	}, {
+		name:        "missing header",
+		url:         "/api/pets/42",
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: `header parameter "X-Request-Id" is required`,
+	}, {
+		name:        "header not matching its pattern",
+		url:         "/api/pets/42",
+		header:      http.Header{"X-Request-Id": []string{"xyz"}},
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "header parameter X-Request-Id must match ^[a-f0-9]+$",
+	}, {
+		name:   "create",
+		method: http.MethodPost,
+		url:    "/api/pets",
+		header: http.Header{"Content-Type": []string{"application/json"}},
+		body:   `{"name": "Rex", "tag": "dog", "age": 3, "born": "2016-04-01", "owners": ["me"]}`,
+	}, {
+		name:        "missing body",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "request body is required",
+	}, {
+		name:        "unsupported content type",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"text/plain"}},
+		body:        "Rex",
+		wantStatus:  http.StatusUnsupportedMediaType,
+		wantMessage: `content type "text/plain" is not supported`,
+	}, {
+		name:        "invalid JSON",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name":`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "invalid JSON body: unexpected end of JSON input",
+	}, {
+		name:        "missing property",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"tag": "dog"}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.name is required",
+	}, {
+		name:        "unknown property",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "color": "brown"}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.color is not allowed",
+	}, {
+		name:        "property of the wrong type",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "age": 3.5}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.age must be of type integer",
+	}, {
+		name:        "property not in enum",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "tag": "fish"}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.tag must be one of [cat dog]",
+	}, {
+		name:        "invalid date",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "born": "yesterday"}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.born must be a date",
+	}, {
+		name:        "too many items",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "owners": ["a", "b", "c"]}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.owners must have at most 2 items",
+	}, {
+		name:        "item of the wrong type",
+		method:      http.MethodPost,
+		url:         "/api/pets",
+		header:      http.Header{"Content-Type": []string{"application/json"}},
+		body:        `{"name": "Rex", "owners": [1]}`,
+		wantStatus:  http.StatusBadRequest,
+		wantMessage: "body.owners[0] must be of type string",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, "http://example.com"+test.url, nil)
+			for k, v := range test.header {
+				r.Header[k] = v
+			}
+
+			err := v.Validate(r, []byte(test.body))
+			if test.wantStatus == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			verr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Validate() = %v, want an *Error", err)
+			}
+			if verr.Status != test.wantStatus || verr.Message != test.wantMessage {
+				t.Errorf("Validate() = (%d, %q), want: (%d, %q)", verr.Status, verr.Message, test.wantStatus, test.wantMessage)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	v, err := Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	var gotBody string
+	probeKey := []byte("1234")
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+	}), v, probeKey)
+
+	// A valid request is passed on, with its body.
+	body := `{"name": "Rex"}`
+	r := httptest.NewRequest(http.MethodPost, "http://example.com/api/pets", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Code = %d, want: %d", rec.Code, http.StatusOK)
+	}
+	if gotBody != body {
+		t.Errorf("Body = %q, want: %q", gotBody, body)
+	}
+
+	// An invalid one is rejected.
+	r = httptest.NewRequest(http.MethodPost, "http://example.com/api/pets", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Code = %d, want: %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// Signed probes are never validated.
+	r = httptest.NewRequest(http.MethodGet, "http://example.com/healthz", nil)
+	network.SignProbeRequest(r, probeKey, "queue", network.NewProbeNonce())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Code = %d for a probe, want: %d", rec.Code, http.StatusOK)
+	}
+
+	// Requests merely looking like probes are.
+	r = httptest.NewRequest(http.MethodGet, "http://example.com/healthz", nil)
+	r.Header.Set("User-Agent", network.KubeProbeUAPrefix+"1.15")
+	r.Header.Set(network.ProbeHeaderName, "queue")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	if rec.Code == http.StatusOK {
+		t.Errorf("Code = %d for a spoofed probe, want an error", rec.Code)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+
+	"knative.dev/serving/pkg/network"
+)
+
+// IsSignedProbe returns whether r is a network probe of the queue-proxy
+// signed with key. Only the components of Knative, which know the probe key
+// of the Revision, can send those, unlike the requests merely looking like
+// probes of the kubelet.
+func IsSignedProbe(r *http.Request, key []byte) bool {
+	return r.Header.Get(network.ProbeHeaderName) == Name && network.ValidProbeRequest(r, key, Name)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitHandler returns a Handler that serves at most limit requests
+// per second with h, in bursts of up to burst requests. Other requests are
+// rejected with 429 Too Many Requests, a Retry-After header telling when
+// they would be served, and reported to onLimited. The network probes
+// signed with probeKey are never limited.
+func RateLimitHandler(h http.Handler, limit float64, burst int, probeKey []byte, onLimited func()) http.Handler {
+	return &rateLimitHandler{
+		handler:   h,
+		limiter:   rate.NewLimiter(rate.Limit(limit), burst),
+		probeKey:  probeKey,
+		onLimited: onLimited,
+		now:       time.Now,
+	}
+}
+
+type rateLimitHandler struct {
+	handler   http.Handler
+	limiter   *rate.Limiter
+	probeKey  []byte
+	onLimited func()
+	now       func() time.Time
+}
+
+func (h *rateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if IsSignedProbe(r, h.probeKey) {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	now := h.now()
+	res := h.limiter.ReserveN(now, 1)
+	if delay := res.DelayFrom(now); delay > 0 {
+		// Give the token back, the request isn't going to wait for it.
+		res.CancelAt(now)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		if h.onLimited != nil {
+			h.onLimited()
+		}
+		return
+	}
+	h.handler.ServeHTTP(w, r)
+}
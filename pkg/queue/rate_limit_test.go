@@ -0,0 +1,96 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"knative.dev/serving/pkg/network"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	limited := 0
+	probeKey := []byte("1234")
+	h := RateLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), 0.5, 2, probeKey, func() { limited++ }).(*rateLimitHandler)
+	now := time.Now()
+	h.now = func() time.Time { return now }
+
+	serve := func(mutate func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		if mutate != nil {
+			mutate(req)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The burst is served.
+	for i := 0; i < 2; i++ {
+		if got := serve(nil).Code; got != http.StatusOK {
+			t.Errorf("Request %d: Code = %d, want: %d", i, got, http.StatusOK)
+		}
+	}
+
+	// The next request has to wait for a token, 2 seconds at 0.5 rps.
+	rec := serve(nil)
+	if got, want := rec.Code, http.StatusTooManyRequests; got != want {
+		t.Errorf("Code = %d, want: %d", got, want)
+	}
+	if got, want := rec.Header().Get("Retry-After"), "2"; got != want {
+		t.Errorf("Retry-After = %q, want: %q", got, want)
+	}
+	if limited != 1 {
+		t.Errorf("Limited requests = %d, want: 1", limited)
+	}
+
+	// Signed probes aren't limited.
+	if got := serve(func(r *http.Request) {
+		network.SignProbeRequest(r, probeKey, Name, network.NewProbeNonce())
+	}).Code; got != http.StatusOK {
+		t.Errorf("Probe: Code = %d, want: %d", got, http.StatusOK)
+	}
+
+	// Requests merely looking like probes are.
+	for name, spoof := range map[string]func(*http.Request){
+		"kubelet user agent": func(r *http.Request) { r.Header.Set("User-Agent", network.KubeProbeUAPrefix+"1.15") },
+		"kubelet header":     func(r *http.Request) { r.Header.Set(network.KubeletProbeHeaderName, Name) },
+		"unsigned probe":     func(r *http.Request) { r.Header.Set(network.ProbeHeaderName, Name) },
+		"badly signed probe": func(r *http.Request) {
+			network.SignProbeRequest(r, []byte("5678"), Name, network.NewProbeNonce())
+		},
+	} {
+		if got, want := serve(spoof).Code, http.StatusTooManyRequests; got != want {
+			t.Errorf("%s: Code = %d, want: %d", name, got, want)
+		}
+	}
+
+	// Rejected requests don't use up tokens.
+	now = now.Add(time.Second)
+	if got, want := serve(nil).Header().Get("Retry-After"), "1"; got != want {
+		t.Errorf("Retry-After = %q, want: %q", got, want)
+	}
+	now = now.Add(time.Second)
+	if got := serve(nil).Code; got != http.StatusOK {
+		t.Errorf("Code = %d, want: %d", got, http.StatusOK)
+	}
+}
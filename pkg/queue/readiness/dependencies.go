@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"knative.dev/serving/pkg/queue/health"
+)
+
+// dependencyTimeout bounds each check of a dependency.
+const dependencyTimeout = time.Second
+
+// probeDependencies checks the Dependencies of p, until all of them were
+// reached once. They only hold back the first readiness of the pod, losing
+// a downstream later on is for the user-container to handle.
+func (p *Probe) probeDependencies() error {
+	if atomic.LoadInt32(&p.dependenciesReached) == 1 {
+		return nil
+	}
+	for _, dep := range p.Dependencies {
+		if err := probeDependency(dep, dependencyTimeout); err != nil {
+			return fmt.Errorf("dependency %s is not reachable: %v", dep, err)
+		}
+	}
+	atomic.StoreInt32(&p.dependenciesReached, 1)
+	if len(p.Dependencies) > 0 {
+		p.logger.Info("Dependencies successfully probed.")
+	}
+	return nil
+}
+
+// probeDependency checks that dep, an http(s) URL or a tcp://host:port
+// address, can be reached within timeout.
+func probeDependency(dep *url.URL, timeout time.Duration) error {
+	if dep.Scheme == "tcp" {
+		return health.TCPProbe(health.TCPProbeConfigOptions{
+			Address:       dep.Host,
+			SocketTimeout: timeout,
+		})
+	}
+	port := dep.Port()
+	if port == "" {
+		port = "80"
+		if dep.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return health.HTTPProbe(health.HTTPProbeConfigOptions{
+		Timeout: timeout,
+		HTTPGetAction: &corev1.HTTPGetAction{
+			Scheme: corev1.URIScheme(strings.ToUpper(dep.Scheme)),
+			Host:   dep.Hostname(),
+			Port:   intstr.Parse(port),
+			Path:   dep.Path,
+		},
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readiness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	logtesting "knative.dev/pkg/logging/testing"
+)
+
+func TestDependencies(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	container := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer container.Close()
+	containerURL, _ := url.Parse(container.URL)
+
+	var up int32
+	dependency := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || atomic.LoadInt32(&up) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	dependencyURL, _ := url.Parse(dependency.URL + "/healthz")
+	// The container itself doubles as a TCP dependency.
+	tcpURL, _ := url.Parse("tcp://" + containerURL.Host)
+
+	pb := newProbe(&corev1.Probe{
+		PeriodSeconds:    1,
+		TimeoutSeconds:   1,
+		SuccessThreshold: 1,
+		FailureThreshold: 1,
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Host: containerURL.Hostname(),
+				Port: intstr.FromString(containerURL.Port()),
+			},
+		},
+	}, t)
+	pb.Dependencies = []*url.URL{tcpURL, dependencyURL}
+
+	if pb.ProbeContainer() {
+		t.Error("Probe succeeded while a dependency is down. Expected failure.")
+	}
+
+	atomic.StoreInt32(&up, 1)
+	if !pb.ProbeContainer() {
+		t.Error("Probe failed with all dependencies up. Expected success.")
+	}
+
+	// Dependencies only hold back the first readiness.
+	dependency.Close()
+	if !pb.ProbeContainer() {
+		t.Error("Probe failed after the dependencies were reached. Expected success.")
+	}
+}
+
+func TestDependencyUnreachable(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	container := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer container.Close()
+	containerURL, _ := url.Parse(container.URL)
+
+	gone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	goneURL, _ := url.Parse("tcp://" + gone.Listener.Addr().String())
+	gone.Close()
+
+	pb := newProbe(&corev1.Probe{
+		PeriodSeconds:    1,
+		TimeoutSeconds:   1,
+		SuccessThreshold: 1,
+		FailureThreshold: 1,
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Host:   containerURL.Hostname(),
+				Port:   intstr.FromString(containerURL.Port()),
+				Scheme: corev1.URISchemeHTTP,
+			},
+		},
+	}, t)
+	pb.Dependencies = []*url.URL{goneURL}
+
+	if pb.ProbeContainer() {
+		t.Error("Probe succeeded with an unreachable dependency. Expected failure.")
+	}
+}
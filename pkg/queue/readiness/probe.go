@@ -18,7 +18,10 @@ package readiness
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
@@ -38,8 +41,16 @@ const (
 // Probe wraps a corev1.Probe along with a logger and a count of consecutive, successful probes
 type Probe struct {
 	*corev1.Probe
-	count  int32
-	logger *zap.SugaredLogger
+	// UserSocket, when set, is the Unix domain socket the user-container
+	// serves on. HTTP and TCP probes are sent there instead of to the port.
+	UserSocket string
+	// Dependencies are the downstreams the user-container depends on. The
+	// probe only succeeds once each of them was reached.
+	Dependencies []*url.URL
+	count        int32
+	logger       *zap.SugaredLogger
+	// dependenciesReached is set to 1 once all the Dependencies were reached.
+	dependenciesReached int32
 }
 
 // NewProbe returns a pointer a new Probe
@@ -76,6 +87,9 @@ func (p *Probe) ProbeContainer() bool {
 		return false
 	}
 
+	if err == nil {
+		err = p.probeDependencies()
+	}
 	if err != nil {
 		// Using Fprintf for a concise error message in the event log.
 		fmt.Fprint(os.Stderr, err.Error())
@@ -111,7 +125,8 @@ func (p *Probe) doProbe(probe func(time.Duration) error) error {
 // if the probe count is greater than success threshold and false if TCP probe fails
 func (p *Probe) tcpProbe() error {
 	config := health.TCPProbeConfigOptions{
-		Address: fmt.Sprintf("%s:%d", p.TCPSocket.Host, p.TCPSocket.Port.IntValue()),
+		Address:    net.JoinHostPort(p.TCPSocket.Host, strconv.Itoa(p.TCPSocket.Port.IntValue())),
+		SocketPath: p.UserSocket,
 	}
 
 	return p.doProbe(func(to time.Duration) error {
@@ -126,6 +141,7 @@ func (p *Probe) tcpProbe() error {
 func (p *Probe) httpProbe() error {
 	config := health.HTTPProbeConfigOptions{
 		HTTPGetAction: p.HTTPGet,
+		SocketPath:    p.UserSocket,
 	}
 
 	return p.doProbe(func(to time.Duration) error {
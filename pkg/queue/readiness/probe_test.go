@@ -148,6 +148,42 @@ func TestTCPSuccess(t *testing.T) {
 	}
 }
 
+func TestTCPSuccessIPv6(t *testing.T) {
+	defer logtesting.ClearAll()
+
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback is not available: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			c.Close()
+		}
+	}()
+
+	pb := newProbe(&corev1.Probe{
+		PeriodSeconds:    1,
+		TimeoutSeconds:   2,
+		SuccessThreshold: 1,
+		FailureThreshold: 1,
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{
+				Host: "::1",
+				Port: intstr.FromInt(l.Addr().(*net.TCPAddr).Port),
+			},
+		},
+	}, t)
+
+	if !pb.ProbeContainer() {
+		t.Error("Probe report failure. Expected success.")
+	}
+}
+
 func TestHTTPFailureToConnect(t *testing.T) {
 	defer logtesting.ClearAll()
 
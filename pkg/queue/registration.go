@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/serving/pkg/network"
+)
+
+const (
+	// RegistrationPeriod is how often queue-proxies announce the state of
+	// their pods to the activators.
+	RegistrationPeriod = 500 * time.Millisecond
+
+	// RegistrationTTL is how long activators keep a registration after it
+	// was last announced.
+	RegistrationTTL = 3 * RegistrationPeriod
+
+	// SecretVolumePath is where the Secret of the revision is mounted into
+	// the queue-proxy.
+	SecretVolumePath = "/var/run/knative-secret"
+
+	// RegistrationTokenKey is the key of the Secret of the revision, and the
+	// file of SecretVolumePath, holding the token the queue-proxy signs its
+	// registrations with.
+	RegistrationTokenKey = "registration-token"
+
+	// ProbeKeyKey is the key of the Secret of the revision, and the file of
+	// SecretVolumePath, holding the key the network probes of its pods, and
+	// their responses, are signed with.
+	ProbeKeyKey = "probe-key"
+)
+
+// RegistrationToken returns the token the queue-proxies of the revision sign
+// their registrations with. It is derived from the key of the activators,
+// which only the activators and the controller can read, so that the
+// activators can check the registrations without reading the Secrets of
+// the revisions.
+func RegistrationToken(key []byte, namespace, revision string, uid types.UID) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(namespace + "/" + revision + "/" + string(uid)))
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// SignRegistration returns the signature of the encoded registration with
+// the token of its revision.
+func SignRegistration(body, token []byte) string {
+	mac := hmac.New(sha256.New, token)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidRegistrationSignature returns whether signature is the one of the
+// encoded registration with the token of its revision.
+func ValidRegistrationSignature(body, token []byte, signature string) bool {
+	return hmac.Equal([]byte(SignRegistration(body, token)), []byte(signature))
+}
+
+// Registration is the state of a pod a queue-proxy announces to the
+// activators.
+type Registration struct {
+	Namespace string `json:"namespace"`
+	Revision  string `json:"revision"`
+	Pod       string `json:"pod"`
+	PodIP     string `json:"podIP"`
+
+	// Ready is whether the pod passes its readiness probe.
+	Ready bool `json:"ready"`
+	// Draining is whether the pod is shutting down and must not be sent any
+	// new requests.
+	Draining bool `json:"draining"`
+	// Capacity is the number of requests the pod can take on top of the
+	// ones in flight without queueing them, or -1 if unlimited.
+	Capacity int `json:"capacity"`
+}
+
+// Registrar announces the Registration of the pod to all activators, which
+// it finds through the DNS records of a headless service.
+type Registrar struct {
+	host   string
+	port   int
+	state  func() Registration
+	token  func() ([]byte, error)
+	logger *zap.SugaredLogger
+
+	client     *http.Client
+	lookupHost func(host string) ([]string, error)
+}
+
+// NewRegistrar creates a Registrar announcing the state returned by the
+// given function to the activators behind the host, on the given port. The
+// registrations are signed with the token returned by the token function.
+func NewRegistrar(host string, port int, state func() Registration, token func() ([]byte, error), logger *zap.SugaredLogger) *Registrar {
+	return &Registrar{
+		host:   host,
+		port:   port,
+		state:  state,
+		token:  token,
+		logger: logger,
+		client: &http.Client{
+			Timeout: RegistrationPeriod,
+		},
+		lookupHost: net.LookupHost,
+	}
+}
+
+// Run announces the Registration every period until stopCh is closed.
+func (r *Registrar) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		r.Announce()
+		select {
+		case <-ticker.C:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// Announce sends the current Registration to all activators, in parallel.
+func (r *Registrar) Announce() {
+	ips, err := r.lookupHost(r.host)
+	if err != nil {
+		r.logger.Warnw("Failed to look up the activators", zap.Error(err))
+		return
+	}
+	token, err := r.token()
+	if err != nil {
+		r.logger.Warnw("Failed to read the registration token", zap.Error(err))
+		return
+	}
+	body, err := json.Marshal(r.state())
+	if err != nil {
+		r.logger.Errorw("Failed to encode the registration", zap.Error(err))
+		return
+	}
+	signature := SignRegistration(body, token)
+
+	var wg sync.WaitGroup
+	wg.Add(len(ips))
+	for _, ip := range ips {
+		go func(ip string) {
+			defer wg.Done()
+			url := "http://" + net.JoinHostPort(ip, strconv.Itoa(r.port))
+			if err := r.send(url, body, signature); err != nil {
+				r.logger.Debugw("Failed to register with activator "+ip, zap.Error(err))
+			}
+		}(ip)
+	}
+	wg.Wait()
+}
+
+func (r *Registrar) send(url string, body []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(network.RegistrationHeaderName, Name)
+	req.Header.Set(network.RegistrationSignatureHeaderName, signature)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registration rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	. "knative.dev/pkg/logging/testing"
+	"knative.dev/serving/pkg/network"
+)
+
+func TestRegistrarAnnounce(t *testing.T) {
+	want := Registration{
+		Namespace: "ns",
+		Revision:  "rev",
+		Pod:       podName,
+		PodIP:     "10.0.0.1",
+		Ready:     true,
+		Capacity:  -1,
+	}
+
+	got := make(chan Registration, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h := r.Header.Get(network.RegistrationHeaderName); h != Name {
+			t.Errorf("%s header = %q, want %q", network.RegistrationHeaderName, h, Name)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if !ValidRegistrationSignature(body, []byte("token"), r.Header.Get(network.RegistrationSignatureHeaderName)) {
+			t.Error("The registration is not signed with the token")
+		}
+		var reg Registration
+		if err := json.Unmarshal(body, &reg); err != nil {
+			t.Errorf("Failed to decode the registration: %v", err)
+		}
+		got <- reg
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	host, port, _ := net.SplitHostPort(u.Host)
+	p, _ := strconv.Atoi(port)
+
+	r := NewRegistrar("activator-registration", p, func() Registration { return want }, func() ([]byte, error) {
+		return []byte("token"), nil
+	}, TestLogger(t))
+	r.lookupHost = func(h string) ([]string, error) {
+		if h != "activator-registration" {
+			t.Errorf("lookupHost(%q), want activator-registration", h)
+		}
+		return []string{host}, nil
+	}
+	r.Announce()
+
+	select {
+	case reg := <-got:
+		if !cmp.Equal(reg, want) {
+			t.Errorf("Registration (-want, +got) = %s", cmp.Diff(want, reg))
+		}
+	default:
+		t.Error("No registration was sent")
+	}
+}
+
+func TestRegistrarLookupFailure(t *testing.T) {
+	called := false
+	r := NewRegistrar("activator-registration", 8014, func() Registration {
+		called = true
+		return Registration{}
+	}, func() ([]byte, error) {
+		return []byte("token"), nil
+	}, TestLogger(t))
+	r.lookupHost = func(string) ([]string, error) {
+		return nil, errors.New("no such host")
+	}
+	r.Announce()
+
+	if called {
+		t.Error("The registration was computed without any activator to send it to")
+	}
+}
+
+func TestRegistrarWithoutToken(t *testing.T) {
+	called := false
+	r := NewRegistrar("activator-registration", 8014, func() Registration {
+		called = true
+		return Registration{}
+	}, func() ([]byte, error) {
+		return nil, errors.New("no such file")
+	}, TestLogger(t))
+	r.lookupHost = func(string) ([]string, error) {
+		return []string{"10.0.0.1"}, nil
+	}
+	r.Announce()
+
+	if called {
+		t.Error("The registration was computed without a token to sign it with")
+	}
+}
+
+func TestRegistrationToken(t *testing.T) {
+	token := RegistrationToken([]byte("key"), "ns", "rev", "uid")
+	if got := RegistrationToken([]byte("key"), "ns", "rev", "uid"); string(got) != string(token) {
+		t.Errorf("RegistrationToken() = %s, want the same token %s", got, token)
+	}
+	for _, other := range [][]byte{
+		RegistrationToken([]byte("other-key"), "ns", "rev", "uid"),
+		RegistrationToken([]byte("key"), "ns", "other-rev", "uid"),
+		RegistrationToken([]byte("key"), "ns", "rev", "other-uid"),
+	} {
+		if string(other) == string(token) {
+			t.Errorf("RegistrationToken() = %s for another key or revision", other)
+		}
+	}
+
+	body := []byte(`{"pod":"pod"}`)
+	signature := SignRegistration(body, token)
+	if !ValidRegistrationSignature(body, token, signature) {
+		t.Error("ValidRegistrationSignature() = false for the signature of the body")
+	}
+	if ValidRegistrationSignature([]byte(`{"pod":"other-pod"}`), token, signature) {
+		t.Error("ValidRegistrationSignature() = true for another body")
+	}
+	if ValidRegistrationSignature(body, []byte("other-token"), signature) {
+		t.Error("ValidRegistrationSignature() = true for another token")
+	}
+}
@@ -17,11 +17,13 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"time"
 
 	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/queue/stats"
 )
 
@@ -49,16 +51,17 @@ func (h *requestMetricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		// If ServeHTTP panics, recover, record the failure and panic again.
 		err := recover()
 		latency := time.Since(startTime)
+		routeTag := r.Header.Get(network.RouteTagHeaderName)
 		if err != nil {
-			h.sendRequestMetrics(http.StatusInternalServerError, latency)
+			h.sendRequestMetrics(r.Context(), http.StatusInternalServerError, routeTag, latency)
 			panic(err)
 		}
-		h.sendRequestMetrics(rr.ResponseCode, latency)
+		h.sendRequestMetrics(r.Context(), rr.ResponseCode, routeTag, latency)
 	}()
 	h.handler.ServeHTTP(rr, r)
 }
 
-func (h *requestMetricHandler) sendRequestMetrics(respCode int, latency time.Duration) {
-	h.statsReporter.ReportRequestCount(respCode, 1)
-	h.statsReporter.ReportResponseTime(respCode, latency)
+func (h *requestMetricHandler) sendRequestMetrics(ctx context.Context, respCode int, routeTag string, latency time.Duration) {
+	h.statsReporter.ReportRequestCount(respCode, routeTag, 1)
+	h.statsReporter.ReportResponseTime(ctx, respCode, routeTag, latency)
 }
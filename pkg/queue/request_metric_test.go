@@ -18,11 +18,13 @@ package queue
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/queue/stats"
 )
 
@@ -50,12 +52,16 @@ func TestRequestMetricHandler(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodPost, "http://example.com", bytes.NewBufferString("test"))
+	req.Header.Set(network.RouteTagHeaderName, "canary")
 	handler.ServeHTTP(resp, req)
 
 	// Serve one request, should get 1 request count and none zero latency
 	if got, want := r.lastRespCode, http.StatusOK; got != want {
 		t.Errorf("response code got %v, want %v", got, want)
 	}
+	if got, want := r.lastRouteTag, "canary"; got != want {
+		t.Errorf("route tag got %q, want %q", got, want)
+	}
 	if got, want := r.lastReqCount, 1; got != int64(want) {
 		t.Errorf("request count got %v, want %v", got, want)
 	}
@@ -99,18 +105,21 @@ func TestRequestMetricHandlerPanickingHandler(t *testing.T) {
 // fakeStatsReporter just record the last stat it received.
 type fakeStatsReporter struct {
 	lastRespCode   int
+	lastRouteTag   string
 	lastReqCount   int64
 	lastReqLatency time.Duration
 }
 
-func (r *fakeStatsReporter) ReportRequestCount(responseCode int, v int64) error {
+func (r *fakeStatsReporter) ReportRequestCount(responseCode int, routeTag string, v int64) error {
 	r.lastRespCode = responseCode
+	r.lastRouteTag = routeTag
 	r.lastReqCount = v
 	return nil
 }
 
-func (r *fakeStatsReporter) ReportResponseTime(responseCode int, d time.Duration) error {
+func (r *fakeStatsReporter) ReportResponseTime(_ context.Context, responseCode int, routeTag string, d time.Duration) error {
 	r.lastRespCode = responseCode
+	r.lastRouteTag = routeTag
 	r.lastReqLatency = d
 	return nil
 }
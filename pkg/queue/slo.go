@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"time"
+
+	pkghttp "knative.dev/serving/pkg/http"
+	"knative.dev/serving/pkg/network"
+)
+
+const (
+	// SLIAvailability is the indicator of the requests not failing with a
+	// 5xx.
+	SLIAvailability = "availability"
+	// SLILatency is the indicator of the requests served within the latency
+	// of the SLO.
+	SLILatency = "latency"
+)
+
+// SLO is the service level objective of a revision.
+type SLO struct {
+	// Availability is the percentage of the requests that must not fail
+	// with a 5xx, or 0 without an availability objective.
+	Availability float64
+	// Latency is the duration the requests must be served within, or 0
+	// without a latency objective.
+	Latency time.Duration
+	// LatencyTarget is the percentage of the requests that must be served
+	// within Latency, or 0 if it isn't declared.
+	LatencyTarget float64
+}
+
+// SLOHandler returns a Handler serving the requests with h, and reporting
+// whether each met the objectives of slo to onRequest, with the indicator
+// it was measured against. Probes are not measured.
+func SLOHandler(h http.Handler, slo SLO, onRequest func(sli string, good bool)) http.Handler {
+	return &sloHandler{
+		handler:   h,
+		slo:       slo,
+		onRequest: onRequest,
+		now:       time.Now,
+	}
+}
+
+type sloHandler struct {
+	handler   http.Handler
+	slo       SLO
+	onRequest func(sli string, good bool)
+	now       func() time.Time
+}
+
+func (h *sloHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if network.IsKubeletProbe(r) || r.Header.Get(network.ProbeHeaderName) != "" {
+		h.handler.ServeHTTP(w, r)
+		return
+	}
+
+	rr := pkghttp.NewResponseRecorder(w, http.StatusOK)
+	start := h.now()
+	defer func() {
+		// If ServeHTTP panics, recover, record the failure and panic again.
+		code := rr.ResponseCode
+		err := recover()
+		if err != nil {
+			code = http.StatusInternalServerError
+		}
+		if h.slo.Availability > 0 {
+			h.onRequest(SLIAvailability, code < http.StatusInternalServerError)
+		}
+		if h.slo.Latency > 0 {
+			h.onRequest(SLILatency, h.now().Sub(start) <= h.slo.Latency)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}()
+	h.handler.ServeHTTP(rr, r)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"knative.dev/serving/pkg/network"
+)
+
+func TestSLOHandler(t *testing.T) {
+	type sliRequest struct {
+		SLI  string
+		Good bool
+	}
+
+	tests := []struct {
+		name    string
+		slo     SLO
+		code    int
+		latency time.Duration
+		probe   bool
+		want    []sliRequest
+	}{{
+		name: "no objective",
+		code: http.StatusInternalServerError,
+	}, {
+		name: "available",
+		slo:  SLO{Availability: 99.9},
+		code: http.StatusNotFound,
+		want: []sliRequest{{SLIAvailability, true}},
+	}, {
+		name: "unavailable",
+		slo:  SLO{Availability: 99.9},
+		code: http.StatusServiceUnavailable,
+		want: []sliRequest{{SLIAvailability, false}},
+	}, {
+		name:    "fast enough",
+		slo:     SLO{Latency: 300 * time.Millisecond, LatencyTarget: 99},
+		code:    http.StatusOK,
+		latency: 300 * time.Millisecond,
+		want:    []sliRequest{{SLILatency, true}},
+	}, {
+		name:    "too slow",
+		slo:     SLO{Availability: 99.9, Latency: 300 * time.Millisecond},
+		code:    http.StatusOK,
+		latency: time.Second,
+		want:    []sliRequest{{SLIAvailability, true}, {SLILatency, false}},
+	}, {
+		name:    "probe",
+		slo:     SLO{Availability: 99.9, Latency: 300 * time.Millisecond},
+		code:    http.StatusServiceUnavailable,
+		latency: time.Second,
+		probe:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []sliRequest
+			now := time.Now()
+			h := SLOHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				now = now.Add(test.latency)
+				w.WriteHeader(test.code)
+			}), test.slo, func(sli string, good bool) {
+				got = append(got, sliRequest{sli, good})
+			}).(*sloHandler)
+			h.now = func() time.Time { return now }
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			if test.probe {
+				req.Header.Set(network.ProbeHeaderName, Name)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("SLI requests = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSLOHandlerPanic(t *testing.T) {
+	var got []bool
+	h := SLOHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("no!")
+	}), SLO{Availability: 99.9}, func(sli string, good bool) {
+		got = append(got, good)
+	})
+
+	defer func() {
+		if err := recover(); err == nil {
+			t.Error("Want ServeHTTP to panic, got nothing.")
+		}
+		if !cmp.Equal(got, []bool{false}) {
+			t.Errorf("Availability = %v, want: [false]", got)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+}
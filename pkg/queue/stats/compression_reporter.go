@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"knative.dev/pkg/metrics"
+)
+
+// CompressionReporter reports the responses compressed by the queue-proxy
+// for a revision.
+type CompressionReporter struct {
+	ctx        context.Context
+	savedBytes *stats.Int64Measure
+}
+
+// NewCompressionReporter creates a reporter recording the bytes compression
+// saved on the responses of the revision with savedBytes.
+func NewCompressionReporter(ns, service, config, rev string, savedBytes *stats.Int64Measure) (*CompressionReporter, error) {
+	ctx, err := newRevisionContext(ns, service, config, rev, savedBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressionReporter{
+		ctx:        ctx,
+		savedBytes: savedBytes,
+	}, nil
+}
+
+// ReportCompressed records a response that was compressed from in to out
+// bytes.  Compression costs bytes on responses that don't compress well.
+func (r *CompressionReporter) ReportCompressed(in, out int64) {
+	metrics.Record(r.ctx, r.savedBytes.M(in-out))
+}
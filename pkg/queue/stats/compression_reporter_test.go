@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestCompressionReporter(t *testing.T) {
+	metric := stats.Int64("compression_saved_bytes",
+		"The number of bytes saved by compressing responses", stats.UnitBytes)
+
+	if _, err := NewCompressionReporter(testNs, testSvc, "", testRev, metric); err == nil {
+		t.Error("NewCompressionReporter() expected an error for an empty configuration")
+	}
+
+	r, err := NewCompressionReporter(testNs, testSvc, testConf, testRev, metric)
+	if err != nil {
+		t.Fatalf("NewCompressionReporter() = %v", err)
+	}
+	defer metricstest.Unregister("compression_saved_bytes")
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName:     testNs,
+		metricskey.LabelServiceName:       testSvc,
+		metricskey.LabelConfigurationName: testConf,
+		metricskey.LabelRevisionName:      testRev,
+	}
+	r.ReportCompressed(4096, 1024)
+	r.ReportCompressed(2048, 1024)
+	metricstest.CheckSumData(t, "compression_saved_bytes", wantTags, 4096)
+}
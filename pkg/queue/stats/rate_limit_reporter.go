@@ -0,0 +1,111 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"context"
+	"errors"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+// RateLimitReporter reports the requests rejected by the rate limit of a
+// revision.
+type RateLimitReporter struct {
+	ctx    context.Context
+	metric *stats.Int64Measure
+}
+
+// NewRateLimitReporter creates a reporter recording the rejected requests
+// of the revision with metric.
+func NewRateLimitReporter(ns, service, config, rev string, metric *stats.Int64Measure) (*RateLimitReporter, error) {
+	ctx, err := newRevisionContext(ns, service, config, rev, metric)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimitReporter{
+		ctx:    ctx,
+		metric: metric,
+	}, nil
+}
+
+// ReportRateLimited records a rejected request.
+func (r *RateLimitReporter) ReportRateLimited() {
+	metrics.Record(r.ctx, r.metric.M(1))
+}
+
+// newRevisionContext registers views summing up each of metrics per
+// revision, and returns a context tagged for the revision to record them
+// with.
+func newRevisionContext(ns, service, config, rev string, metrics ...stats.Measure) (context.Context, error) {
+	keys, ctx, err := revisionTags(ns, service, config, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metric := range metrics {
+		if err := view.Register(&view.View{
+			Description: metric.Description(),
+			Measure:     metric,
+			Aggregation: view.Sum(),
+			TagKeys:     keys,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// revisionTags returns the keys of the tags identifying the revision, and a
+// context tagged for it.
+func revisionTags(ns, service, config, rev string) ([]tag.Key, context.Context, error) {
+	if ns == "" {
+		return nil, nil, errors.New("namespace must not be empty")
+	}
+	if config == "" {
+		return nil, nil, errors.New("config must not be empty")
+	}
+	if rev == "" {
+		return nil, nil, errors.New("revision must not be empty")
+	}
+
+	var keys []tag.Key
+	var mutators []tag.Mutator
+	for _, t := range []struct{ key, value string }{
+		{metricskey.LabelNamespaceName, ns},
+		{metricskey.LabelServiceName, valueOrUnknown(service)},
+		{metricskey.LabelConfigurationName, config},
+		{metricskey.LabelRevisionName, rev},
+	} {
+		key, err := tag.NewKey(t.key)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		mutators = append(mutators, tag.Insert(key, t.value))
+	}
+
+	ctx, err := tag.New(context.Background(), mutators...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keys, ctx, nil
+}
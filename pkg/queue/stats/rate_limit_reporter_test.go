@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestRateLimitReporter(t *testing.T) {
+	metric := stats.Int64("rate_limited_request_count",
+		"The number of requests rejected by the rate limit", stats.UnitDimensionless)
+
+	if _, err := NewRateLimitReporter("", testSvc, testConf, testRev, metric); err == nil {
+		t.Error("NewRateLimitReporter() expected an error for an empty namespace")
+	}
+
+	r, err := NewRateLimitReporter(testNs, "" /*service name*/, testConf, testRev, metric)
+	if err != nil {
+		t.Fatalf("NewRateLimitReporter() = %v", err)
+	}
+	defer metricstest.Unregister("rate_limited_request_count")
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName:     testNs,
+		metricskey.LabelServiceName:       "unknown",
+		metricskey.LabelConfigurationName: testConf,
+		metricskey.LabelRevisionName:      testRev,
+	}
+	r.ReportRateLimited()
+	r.ReportRateLimited()
+	metricstest.CheckSumData(t, "rate_limited_request_count", wantTags, 2)
+}
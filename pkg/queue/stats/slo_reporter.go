@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"context"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+)
+
+const (
+	sliLabel       = "sli"
+	sloResultLabel = "slo_result"
+
+	sloResultGood = "good"
+	sloResultBad  = "bad"
+)
+
+// SLOReporter reports the requests of a revision meeting its SLO or not,
+// and the objectives of its SLO, for the SLO burn rates to be computed.
+type SLOReporter struct {
+	ctx             context.Context
+	sliKey          tag.Key
+	resultKey       tag.Key
+	requestsMetric  *stats.Int64Measure
+	objectiveMetric *stats.Float64Measure
+}
+
+// NewSLOReporter creates a reporter recording the requests of the revision
+// with requestsMetric, tagged with the service level indicator they were
+// measured against and whether they met the objective, and the objectives
+// with objectiveMetric.
+func NewSLOReporter(ns, service, config, rev string, requestsMetric *stats.Int64Measure, objectiveMetric *stats.Float64Measure) (*SLOReporter, error) {
+	keys, ctx, err := revisionTags(ns, service, config, rev)
+	if err != nil {
+		return nil, err
+	}
+	sliKey, err := tag.NewKey(sliLabel)
+	if err != nil {
+		return nil, err
+	}
+	resultKey, err := tag.NewKey(sloResultLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := view.Register(
+		&view.View{
+			Description: requestsMetric.Description(),
+			Measure:     requestsMetric,
+			Aggregation: view.Sum(),
+			TagKeys:     append([]tag.Key{sliKey, resultKey}, keys...),
+		},
+		&view.View{
+			Description: objectiveMetric.Description(),
+			Measure:     objectiveMetric,
+			Aggregation: view.LastValue(),
+			TagKeys:     append([]tag.Key{sliKey}, keys...),
+		},
+	); err != nil {
+		return nil, err
+	}
+
+	return &SLOReporter{
+		ctx:             ctx,
+		sliKey:          sliKey,
+		resultKey:       resultKey,
+		requestsMetric:  requestsMetric,
+		objectiveMetric: objectiveMetric,
+	}, nil
+}
+
+// ReportObjective records the percentage of the requests that must meet
+// the objective of the service level indicator, as a ratio.
+func (r *SLOReporter) ReportObjective(sli string, percentage float64) error {
+	ctx, err := tag.New(r.ctx, tag.Insert(r.sliKey, sli))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, r.objectiveMetric.M(percentage/100))
+	return nil
+}
+
+// ReportRequest records a request meeting the objective of the service
+// level indicator, if good, or not.
+func (r *SLOReporter) ReportRequest(sli string, good bool) error {
+	result := sloResultBad
+	if good {
+		result = sloResultGood
+	}
+	ctx, err := tag.New(r.ctx, tag.Insert(r.sliKey, sli), tag.Insert(r.resultKey, result))
+	if err != nil {
+		return err
+	}
+	metrics.Record(ctx, r.requestsMetric.M(1))
+	return nil
+}
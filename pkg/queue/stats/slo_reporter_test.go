@@ -0,0 +1,57 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stats
+
+import (
+	"testing"
+
+	"go.opencensus.io/stats"
+	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/pkg/metrics/metricstest"
+)
+
+func TestSLOReporter(t *testing.T) {
+	requestsMetric := stats.Int64("slo_request_count",
+		"The number of requests measured against the SLO", stats.UnitDimensionless)
+	objectiveMetric := stats.Float64("slo_objective",
+		"The ratio of the requests that must meet the SLO", stats.UnitDimensionless)
+
+	if _, err := NewSLOReporter(testNs, testSvc, "", testRev, requestsMetric, objectiveMetric); err == nil {
+		t.Error("NewSLOReporter() expected an error for an empty configuration")
+	}
+
+	r, err := NewSLOReporter(testNs, testSvc, testConf, testRev, requestsMetric, objectiveMetric)
+	if err != nil {
+		t.Fatalf("NewSLOReporter() = %v", err)
+	}
+	defer metricstest.Unregister("slo_request_count", "slo_objective")
+
+	wantTags := map[string]string{
+		metricskey.LabelNamespaceName:     testNs,
+		metricskey.LabelServiceName:       testSvc,
+		metricskey.LabelConfigurationName: testConf,
+		metricskey.LabelRevisionName:      testRev,
+		sliLabel:                          "availability",
+	}
+	expectSuccess(t, "ReportObjective", func() error { return r.ReportObjective("availability", 99.5) })
+	metricstest.CheckLastValueData(t, "slo_objective", wantTags, 0.995)
+
+	wantTags[sloResultLabel] = sloResultBad
+	expectSuccess(t, "ReportRequest", func() error { return r.ReportRequest("availability", false) })
+	expectSuccess(t, "ReportRequest", func() error { return r.ReportRequest("availability", false) })
+	metricstest.CheckSumData(t, "slo_request_count", wantTags, 2)
+}
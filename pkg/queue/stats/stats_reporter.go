@@ -27,16 +27,19 @@ import (
 	"go.opencensus.io/tag"
 	"knative.dev/pkg/metrics"
 	"knative.dev/pkg/metrics/metricskey"
+	"knative.dev/serving/pkg/tracing"
 )
 
 // NOTE: 0 should not be used as boundary. See
 // https://github.com/census-ecosystem/opencensus-go-exporter-stackdriver/issues/98
 var defaultLatencyDistribution = view.Distribution(5, 10, 20, 40, 60, 80, 100, 150, 200, 250, 300, 350, 400, 450, 500, 600, 700, 800, 900, 1000, 2000, 5000, 10000, 20000, 50000, 100000)
 
-// StatsReporter defines the interface for sending queue-proxy metrics
+// StatsReporter defines the interface for sending queue-proxy metrics. The
+// routeTag is the tag of the route the request came in through, empty for
+// the default route.
 type StatsReporter interface {
-	ReportRequestCount(responseCode int, v int64) error
-	ReportResponseTime(responseCode int, d time.Duration) error
+	ReportRequestCount(responseCode int, routeTag string, v int64) error
+	ReportResponseTime(reqCtx context.Context, responseCode int, routeTag string, d time.Duration) error
 }
 
 // Reporter holds cached metric objects to report autoscaler metrics
@@ -49,6 +52,7 @@ type Reporter struct {
 	revisionTagKey       tag.Key
 	responseCodeKey      tag.Key
 	responseCodeClassKey tag.Key
+	routeTagKey          tag.Key
 	countMetric          *stats.Int64Measure
 	latencyMetric        *stats.Float64Measure
 }
@@ -90,6 +94,10 @@ func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64M
 	if err != nil {
 		return nil, err
 	}
+	routeTagTag, err := tag.NewKey("route_tag")
+	if err != nil {
+		return nil, err
+	}
 
 	// Create view to see our measurements.
 	err = view.Register(
@@ -97,13 +105,13 @@ func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64M
 			Description: "The number of requests that are routed to queue-proxy",
 			Measure:     countMetric,
 			Aggregation: view.Sum(),
-			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag},
+			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag, routeTagTag},
 		},
 		&view.View{
 			Description: "The response time in millisecond",
 			Measure:     latencyMetric,
 			Aggregation: defaultLatencyDistribution,
-			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag},
+			TagKeys:     []tag.Key{nsTag, svcTag, configTag, revTag, responseCodeTag, responseCodeClassTag, routeTagTag},
 		},
 	)
 	if err != nil {
@@ -131,6 +139,7 @@ func NewStatsReporter(ns, service, config, rev string, countMetric *stats.Int64M
 		revisionTagKey:       revTag,
 		responseCodeKey:      responseCodeTag,
 		responseCodeClassKey: responseCodeClassTag,
+		routeTagKey:          routeTagTag,
 		countMetric:          countMetric,
 		latencyMetric:        latencyMetric,
 	}, nil
@@ -144,7 +153,7 @@ func valueOrUnknown(v string) string {
 }
 
 // ReportRequestCount captures request count metric with value v.
-func (r *Reporter) ReportRequestCount(responseCode int, v int64) error {
+func (r *Reporter) ReportRequestCount(responseCode int, routeTag string, v int64) error {
 	if !r.initialized {
 		return errors.New("StatsReporter is not initialized yet")
 	}
@@ -153,7 +162,8 @@ func (r *Reporter) ReportRequestCount(responseCode int, v int64) error {
 	ctx, err := tag.New(
 		r.ctx,
 		tag.Insert(r.responseCodeKey, strconv.Itoa(responseCode)),
-		tag.Insert(r.responseCodeClassKey, responseCodeClass(responseCode)))
+		tag.Insert(r.responseCodeClassKey, responseCodeClass(responseCode)),
+		tag.Insert(r.routeTagKey, routeTag))
 	if err != nil {
 		return err
 	}
@@ -162,8 +172,9 @@ func (r *Reporter) ReportRequestCount(responseCode int, v int64) error {
 	return nil
 }
 
-// ReportResponseTime captures response time requests
-func (r *Reporter) ReportResponseTime(responseCode int, d time.Duration) error {
+// ReportResponseTime captures response time requests. The sampled span of
+// the request in reqCtx, if any, becomes the exemplar of the measurement.
+func (r *Reporter) ReportResponseTime(reqCtx context.Context, responseCode int, routeTag string, d time.Duration) error {
 	if !r.initialized {
 		return errors.New("StatsReporter is not initialized yet")
 	}
@@ -172,14 +183,18 @@ func (r *Reporter) ReportResponseTime(responseCode int, d time.Duration) error {
 	ctx, err := tag.New(
 		r.ctx,
 		tag.Insert(r.responseCodeKey, strconv.Itoa(responseCode)),
-		tag.Insert(r.responseCodeClassKey, responseCodeClass(responseCode)))
+		tag.Insert(r.responseCodeClassKey, responseCodeClass(responseCode)),
+		tag.Insert(r.routeTagKey, routeTag))
 	if err != nil {
 		return err
 	}
 
 	// convert time.Duration in nanoseconds to milliseconds
-	metrics.Record(ctx, r.latencyMetric.M(float64(d/time.Millisecond)))
-	return nil
+	// The latencies are knative_revision metrics, which metrics.Record
+	// records on every backend, but it has no room for the exemplars.
+	return stats.RecordWithOptions(ctx,
+		stats.WithMeasurements(r.latencyMetric.M(float64(d/time.Millisecond))),
+		stats.WithAttachments(tracing.ExemplarAttachments(reqCtx)))
 }
 
 // responseCodeClass converts response code to a string of response code class.
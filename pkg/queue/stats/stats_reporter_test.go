@@ -17,6 +17,7 @@ limitations under the License.
 package stats
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -89,7 +90,7 @@ func TestNewStatsReporter_negative(t *testing.T) {
 
 func TestReporter_Report(t *testing.T) {
 	r := &Reporter{}
-	if err := r.ReportRequestCount(200, 10); err == nil {
+	if err := r.ReportRequestCount(200, "", 10); err == nil {
 		t.Error("Reporter.ReportRequestCount() expected an error for Report call before init. Got success.")
 	}
 
@@ -104,24 +105,25 @@ func TestReporter_Report(t *testing.T) {
 		metricskey.LabelRevisionName:      testRev,
 		"response_code":                   "200",
 		"response_code_class":             "2xx",
+		"route_tag":                       "",
 	}
 
 	// Send statistics only once and observe the results
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, 1) })
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, "", 1) })
 	metricstest.CheckSumData(t, "request_count", wantTags, 1)
 
 	// The stats are cumulative - record multiple entries, should get sum
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, 2) })
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, 3) })
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, "", 2) })
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, "", 3) })
 	metricstest.CheckSumData(t, "request_count", wantTags, 6)
 
 	// Send statistics only once and observe the results
-	expectSuccess(t, "ReportResponseTime", func() error { return r.ReportResponseTime(200, 100*time.Millisecond) })
+	expectSuccess(t, "ReportResponseTime", func() error { return r.ReportResponseTime(context.Background(), 200, "", 100*time.Millisecond) })
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags, 1, 100, 100)
 
 	// The stats are cumulative - record multiple entries, should get count sum
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportResponseTime(200, 200*time.Millisecond) })
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportResponseTime(200, 300*time.Millisecond) })
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportResponseTime(context.Background(), 200, "", 200*time.Millisecond) })
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportResponseTime(context.Background(), 200, "", 300*time.Millisecond) })
 	metricstest.CheckDistributionData(t, "request_latencies", wantTags, 3, 100, 300)
 
 	unregisterViews(r)
@@ -138,10 +140,11 @@ func TestReporter_Report(t *testing.T) {
 		metricskey.LabelRevisionName:      testRev,
 		"response_code":                   "200",
 		"response_code_class":             "2xx",
+		"route_tag":                       "canary",
 	}
 
-	// Send statistics only once and observe the results
-	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, 1) })
+	// Send statistics of a tagged route only once and observe the results
+	expectSuccess(t, "ReportRequestCount", func() error { return r.ReportRequestCount(200, "canary", 1) })
 	metricstest.CheckSumData(t, "request_count", wantTags, 1)
 
 	unregisterViews(r)
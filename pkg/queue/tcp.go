@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"knative.dev/serving/pkg/network"
+)
+
+// tcpDialTimeout bounds the time to connect to the user container, which
+// listens on the loopback interface.
+const tcpDialTimeout = time.Second
+
+// TCPProxy proxies the raw TCP connections to the user container, for the
+// revisions serving non-HTTP protocols. A connection is a request for the
+// whole of its life: it counts towards the stats of the pod, and holds a
+// slot of the breaker, from the time it is accepted until it is closed.
+type TCPProxy struct {
+	target  string
+	reqChan chan ReqEvent
+	breaker *Breaker
+	logger  *zap.SugaredLogger
+
+	// ctx is cancelled when the connections are cut short on shutdown,
+	// which releases the ones still waiting for the breaker.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	closed   bool
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+}
+
+// NewTCPProxy creates a TCPProxy forwarding the connections to target. The
+// breaker may be nil when the concurrency is unlimited.
+func NewTCPProxy(target string, reqChan chan ReqEvent, breaker *Breaker, logger *zap.SugaredLogger) *TCPProxy {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TCPProxy{
+		target:  target,
+		reqChan: reqChan,
+		breaker: breaker,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		conns:   make(map[net.Conn]struct{}),
+	}
+}
+
+// Serve accepts the connections on l until Shutdown is called, at which
+// point it returns nil.
+func (p *TCPProxy) Serve(l net.Listener) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		l.Close()
+		return nil
+	}
+	p.listener = l
+	p.mu.Unlock()
+
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if p.isClosed() {
+				return nil
+			}
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		if !p.track(c) {
+			c.Close()
+			return nil
+		}
+		go p.handle(c)
+	}
+}
+
+// Shutdown stops accepting connections and waits for the open ones to be
+// closed by their ends. Once ctx is done, it closes them itself and returns
+// the error of ctx.
+func (p *TCPProxy) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	if p.listener != nil {
+		p.listener.Close()
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.cancel()
+	p.mu.Lock()
+	for c := range p.conns {
+		c.Close()
+	}
+	p.mu.Unlock()
+	<-done
+	return ctx.Err()
+}
+
+func (p *TCPProxy) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+// track records c as open, unless the proxy is shutting down.
+func (p *TCPProxy) track(c net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.conns[c] = struct{}{}
+	p.wg.Add(1)
+	return true
+}
+
+func (p *TCPProxy) untrack(c net.Conn) {
+	p.mu.Lock()
+	delete(p.conns, c)
+	p.mu.Unlock()
+	p.wg.Done()
+}
+
+func (p *TCPProxy) handle(c net.Conn) {
+	defer p.untrack(c)
+	defer c.Close()
+
+	p.reqChan <- ReqEvent{Time: time.Now(), EventType: ReqIn}
+	defer func() {
+		p.reqChan <- ReqEvent{Time: time.Now(), EventType: ReqOut}
+	}()
+
+	proxy := func() {
+		upstream, err := net.DialTimeout("tcp", p.target, tcpDialTimeout)
+		if err != nil {
+			p.logger.Errorw("Failed to connect to the user container", zap.Error(err))
+			return
+		}
+		network.Splice(c, upstream)
+	}
+	if p.breaker == nil {
+		proxy()
+		return
+	}
+	if !p.breaker.Maybe(p.ctx, proxy) {
+		p.logger.Warn("Rejecting connection: overload")
+	}
+}
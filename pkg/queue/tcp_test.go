@@ -0,0 +1,176 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	. "knative.dev/pkg/logging/testing"
+)
+
+// echoListener echoes back every line it reads, on all the connections
+// it accepts.
+func echoListener(t *testing.T) net.Listener {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				s := bufio.NewScanner(c)
+				for s.Scan() {
+					c.Write(append(s.Bytes(), '\n'))
+				}
+			}()
+		}
+	}()
+	return l
+}
+
+func startTCPProxy(t *testing.T, target string, breaker *Breaker) (*TCPProxy, chan ReqEvent, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	reqChan := make(chan ReqEvent, 10)
+	p := NewTCPProxy(target, reqChan, breaker, TestLogger(t))
+	go p.Serve(l)
+	return p, reqChan, l.Addr().String()
+}
+
+func roundTrip(t *testing.T, c net.Conn, r *bufio.Reader, line string) {
+	t.Helper()
+	if _, err := c.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	got, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString() = %v", err)
+	}
+	if want := line + "\n"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestTCPProxy(t *testing.T) {
+	echo := echoListener(t)
+	defer echo.Close()
+	p, reqChan, addr := startTCPProxy(t, echo.Addr().String(), nil)
+	defer p.Shutdown(context.Background())
+
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	r := bufio.NewReader(c)
+	roundTrip(t, c, r, "CONNECT")
+	roundTrip(t, c, r, "PUBLISH")
+
+	// The connection is a single request, until it's closed.
+	if got := (<-reqChan).EventType; got != ReqIn {
+		t.Errorf("EventType = %v, want ReqIn", got)
+	}
+	select {
+	case ev := <-reqChan:
+		t.Fatalf("Unexpected event %v while the connection is open", ev)
+	default:
+	}
+	c.Close()
+	select {
+	case ev := <-reqChan:
+		if ev.EventType != ReqOut {
+			t.Errorf("EventType = %v, want ReqOut", ev.EventType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the ReqOut event")
+	}
+}
+
+func TestTCPProxyBreaker(t *testing.T) {
+	echo := echoListener(t)
+	defer echo.Close()
+	breaker := NewBreaker(BreakerParams{QueueDepth: 1, MaxConcurrency: 1, InitialCapacity: 1})
+	p, _, addr := startTCPProxy(t, echo.Addr().String(), breaker)
+	defer p.Shutdown(context.Background())
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	r := bufio.NewReader(first)
+	roundTrip(t, first, r, "first")
+
+	// The second connection waits for the first one to be closed.
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer second.Close()
+	second.Write([]byte("second\n"))
+	second.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := bufio.NewReader(second).ReadString('\n'); err == nil {
+		t.Fatal("The second connection was served while the first one was open")
+	}
+
+	first.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r = bufio.NewReader(second)
+	if got, err := r.ReadString('\n'); err != nil || got != "second\n" {
+		t.Errorf("ReadString() = %q, %v, want %q", got, err, "second\n")
+	}
+}
+
+func TestTCPProxyShutdown(t *testing.T) {
+	echo := echoListener(t)
+	defer echo.Close()
+	p, _, addr := startTCPProxy(t, echo.Addr().String(), nil)
+
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer c.Close()
+	r := bufio.NewReader(c)
+	roundTrip(t, c, r, "hello")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The open connection was closed by the proxy.
+	c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := r.ReadString('\n'); err == nil {
+		t.Error("The connection is still open after Shutdown")
+	}
+	if _, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		t.Error("The proxy still accepts connections after Shutdown")
+	}
+}
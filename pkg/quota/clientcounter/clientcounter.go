@@ -0,0 +1,82 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clientcounter counts the usage of quotas with the API server.
+package clientcounter
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/serving/pkg/apis/serving"
+	clientset "knative.dev/serving/pkg/client/clientset/versioned"
+	"knative.dev/serving/pkg/quota"
+)
+
+type counter struct {
+	kubeClient    kubernetes.Interface
+	servingClient clientset.Interface
+}
+
+var _ quota.Counter = (*counter)(nil)
+
+// New returns a quota.Counter listing the resources of a namespace with
+// kubeClient and servingClient. The webhook has no informers, and resources
+// are only counted when they are created.
+func New(kubeClient kubernetes.Interface, servingClient clientset.Interface) quota.Counter {
+	return &counter{
+		kubeClient:    kubeClient,
+		servingClient: servingClient,
+	}
+}
+
+// Services implements quota.Counter
+func (c *counter) Services(namespace string) (int, error) {
+	list, err := c.servingClient.ServingV1alpha1().Services(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// Revisions implements quota.Counter
+func (c *counter) Revisions(namespace, configuration string) (int, error) {
+	list, err := c.servingClient.ServingV1alpha1().Revisions(namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{
+			serving.ConfigurationLabelKey: configuration,
+		}).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
+
+// RevisionPods implements quota.Counter
+func (c *counter) RevisionPods(namespace string) (int, error) {
+	req, err := labels.NewRequirement(serving.RevisionLabelKey, selection.Exists, nil)
+	if err != nil {
+		return 0, err
+	}
+	list, err := c.kubeClient.CoreV1().Pods(namespace).List(metav1.ListOptions{
+		LabelSelector: labels.NewSelector().Add(*req).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Items), nil
+}
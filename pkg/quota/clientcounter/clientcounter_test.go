@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientcounter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servingfake "knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+func TestCounter(t *testing.T) {
+	meta := func(ns, name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name, Labels: labels}
+	}
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: meta("default", "rev-1-pod", map[string]string{serving.RevisionLabelKey: "rev-1"})},
+		&corev1.Pod{ObjectMeta: meta("default", "rev-2-pod", map[string]string{serving.RevisionLabelKey: "rev-2"})},
+		&corev1.Pod{ObjectMeta: meta("default", "other-pod", nil)},
+		&corev1.Pod{ObjectMeta: meta("other", "rev-3-pod", map[string]string{serving.RevisionLabelKey: "rev-3"})},
+	)
+	servingClient := servingfake.NewSimpleClientset([]runtime.Object{
+		&v1alpha1.Service{ObjectMeta: meta("default", "svc-1", nil)},
+		&v1alpha1.Service{ObjectMeta: meta("default", "svc-2", nil)},
+		&v1alpha1.Service{ObjectMeta: meta("other", "svc-3", nil)},
+		&v1alpha1.Revision{ObjectMeta: meta("default", "rev-1", map[string]string{serving.ConfigurationLabelKey: "config"})},
+		&v1alpha1.Revision{ObjectMeta: meta("default", "rev-2", map[string]string{serving.ConfigurationLabelKey: "config"})},
+		&v1alpha1.Revision{ObjectMeta: meta("default", "rev-4", map[string]string{serving.ConfigurationLabelKey: "other"})},
+	}...)
+	c := New(kubeClient, servingClient)
+
+	if got, err := c.Services("default"); err != nil || got != 2 {
+		t.Errorf("Services() = %d, %v, want 2", got, err)
+	}
+	if got, err := c.Revisions("default", "config"); err != nil || got != 2 {
+		t.Errorf("Revisions() = %d, %v, want 2", got, err)
+	}
+	if got, err := c.RevisionPods("default"); err != nil || got != 2 {
+		t.Errorf("RevisionPods() = %d, %v, want 2", got, err)
+	}
+}
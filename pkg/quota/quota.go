@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota enforces the quotas of config-quota on the Services and
+// Revisions admitted to a namespace.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// Counter counts the resources a namespace holds. It is the extension point
+// of the webhook for where usage is read from.
+type Counter interface {
+	// Services counts the Services of namespace.
+	Services(namespace string) (int, error)
+	// Revisions counts the Revisions of configuration in namespace.
+	Revisions(namespace, configuration string) (int, error)
+	// RevisionPods counts the pods the Revisions of namespace run.
+	RevisionPods(namespace string) (int, error)
+}
+
+type counterKey struct{}
+
+// WithCounter attaches c to ctx, for the resources validated with the
+// returned context to be held to the quotas.
+func WithCounter(ctx context.Context, c Counter) context.Context {
+	return context.WithValue(ctx, counterKey{}, c)
+}
+
+// GetCounter returns the Counter attached to ctx, or nil.
+func GetCounter(ctx context.Context) Counter {
+	c, _ := ctx.Value(counterKey{}).(Counter)
+	return c
+}
+
+// ValidateService checks that a Service being created in namespace fits the
+// quota of Services. Nothing is checked when no Counter is attached to ctx.
+func ValidateService(ctx context.Context, namespace string) *apis.FieldError {
+	counter := GetCounter(ctx)
+	max := config.FromContextOrDefaults(ctx).Quota.MaxServicesPerNamespace
+	if counter == nil || max == 0 {
+		return nil
+	}
+	count, err := counter.Services(namespace)
+	if err != nil {
+		return countFailed(err)
+	}
+	if count >= max {
+		return exceeded(fmt.Sprintf("namespace %q already holds %d Services", namespace, count), max)
+	}
+	return nil
+}
+
+// ValidateRevision checks that a Revision being created, with meta as its
+// metadata, fits the quotas of Revisions and of their pods. The Revision is
+// accounted for the pods it runs once created, i.e. its minScale and at
+// least one. Nothing is checked when no Counter is attached to ctx.
+func ValidateRevision(ctx context.Context, meta metav1.ObjectMeta) *apis.FieldError {
+	counter := GetCounter(ctx)
+	if counter == nil {
+		return nil
+	}
+	quota := config.FromContextOrDefaults(ctx).Quota
+
+	if configuration := meta.Labels[serving.ConfigurationLabelKey]; configuration != "" && quota.MaxRevisionsPerConfiguration != 0 {
+		count, err := counter.Revisions(meta.Namespace, configuration)
+		if err != nil {
+			return countFailed(err)
+		}
+		if count >= quota.MaxRevisionsPerConfiguration {
+			return exceeded(fmt.Sprintf("configuration %q already has %d Revisions", configuration, count),
+				quota.MaxRevisionsPerConfiguration)
+		}
+	}
+
+	if quota.MaxRevisionPodsPerNamespace != 0 {
+		count, err := counter.RevisionPods(meta.Namespace)
+		if err != nil {
+			return countFailed(err)
+		}
+		pods := 1
+		// Invalid values are reported by the validation of the annotations.
+		if min, err := strconv.Atoi(meta.Annotations[autoscaling.MinScaleAnnotationKey]); err == nil && min > pods {
+			pods = min
+		}
+		if count+pods > quota.MaxRevisionPodsPerNamespace {
+			return exceeded(fmt.Sprintf("namespace %q already runs %d pods and the Revision needs %d", meta.Namespace, count, pods),
+				quota.MaxRevisionPodsPerNamespace)
+		}
+	}
+	return nil
+}
+
+func countFailed(err error) *apis.FieldError {
+	return &apis.FieldError{
+		Message: "Failed to check the quota",
+		Paths:   []string{apis.CurrentField},
+		Details: err.Error(),
+	}
+}
+
+func exceeded(details string, max int) *apis.FieldError {
+	return &apis.FieldError{
+		Message: fmt.Sprintf("Quota of %d exceeded", max),
+		Paths:   []string{apis.CurrentField},
+		Details: details,
+	}
+}
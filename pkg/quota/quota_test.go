@@ -0,0 +1,183 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+type fakeCounter struct {
+	services, revisions, pods int
+	err                       error
+}
+
+func (fc *fakeCounter) Services(string) (int, error) {
+	return fc.services, fc.err
+}
+
+func (fc *fakeCounter) Revisions(string, string) (int, error) {
+	return fc.revisions, fc.err
+}
+
+func (fc *fakeCounter) RevisionPods(string) (int, error) {
+	return fc.pods, fc.err
+}
+
+func quotaContext(counter Counter) context.Context {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Quota: &config.Quota{
+			MaxServicesPerNamespace:      2,
+			MaxRevisionsPerConfiguration: 3,
+			MaxRevisionPodsPerNamespace:  10,
+		},
+	})
+	if counter != nil {
+		ctx = WithCounter(ctx, counter)
+	}
+	return ctx
+}
+
+func TestValidateService(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter Counter
+		want    *apis.FieldError
+	}{{
+		name: "no counter",
+	}, {
+		name:    "within quota",
+		counter: &fakeCounter{services: 1},
+	}, {
+		name:    "quota exceeded",
+		counter: &fakeCounter{services: 2},
+		want: &apis.FieldError{
+			Message: "Quota of 2 exceeded",
+			Paths:   []string{apis.CurrentField},
+			Details: `namespace "default" already holds 2 Services`,
+		},
+	}, {
+		name:    "count fails",
+		counter: &fakeCounter{err: errors.New("connection refused")},
+		want: &apis.FieldError{
+			Message: "Failed to check the quota",
+			Paths:   []string{apis.CurrentField},
+			Details: "connection refused",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateService(quotaContext(test.counter), "default")
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("ValidateService() (-want, +got) = %v", cmp.Diff(test.want.Error(), got.Error()))
+			}
+		})
+	}
+}
+
+func TestValidateServiceUnlimited(t *testing.T) {
+	ctx := WithCounter(context.Background(), &fakeCounter{services: 1000})
+	if got := ValidateService(ctx, "default"); got != nil {
+		t.Errorf("ValidateService() = %v, want no error without a quota", got)
+	}
+}
+
+func TestValidateRevision(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter Counter
+		meta    metav1.ObjectMeta
+		want    *apis.FieldError
+	}{{
+		name: "no counter",
+		meta: metav1.ObjectMeta{
+			Namespace: "default",
+			Labels:    map[string]string{serving.ConfigurationLabelKey: "config"},
+		},
+	}, {
+		name:    "within quota",
+		counter: &fakeCounter{revisions: 2, pods: 9},
+		meta: metav1.ObjectMeta{
+			Namespace: "default",
+			Labels:    map[string]string{serving.ConfigurationLabelKey: "config"},
+		},
+	}, {
+		name:    "too many revisions",
+		counter: &fakeCounter{revisions: 3},
+		meta: metav1.ObjectMeta{
+			Namespace: "default",
+			Labels:    map[string]string{serving.ConfigurationLabelKey: "config"},
+		},
+		want: &apis.FieldError{
+			Message: "Quota of 3 exceeded",
+			Paths:   []string{apis.CurrentField},
+			Details: `configuration "config" already has 3 Revisions`,
+		},
+	}, {
+		name:    "revisions without configuration",
+		counter: &fakeCounter{revisions: 3},
+		meta:    metav1.ObjectMeta{Namespace: "default"},
+	}, {
+		name:    "too many pods",
+		counter: &fakeCounter{pods: 10},
+		meta:    metav1.ObjectMeta{Namespace: "default"},
+		want: &apis.FieldError{
+			Message: "Quota of 10 exceeded",
+			Paths:   []string{apis.CurrentField},
+			Details: `namespace "default" already runs 10 pods and the Revision needs 1`,
+		},
+	}, {
+		name:    "too many pods for minScale",
+		counter: &fakeCounter{pods: 7},
+		meta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Annotations: map[string]string{autoscaling.MinScaleAnnotationKey: "4"},
+		},
+		want: &apis.FieldError{
+			Message: "Quota of 10 exceeded",
+			Paths:   []string{apis.CurrentField},
+			Details: `namespace "default" already runs 7 pods and the Revision needs 4`,
+		},
+	}, {
+		name:    "count fails",
+		counter: &fakeCounter{err: errors.New("connection refused")},
+		meta:    metav1.ObjectMeta{Namespace: "default"},
+		want: &apis.FieldError{
+			Message: "Failed to check the quota",
+			Paths:   []string{apis.CurrentField},
+			Details: "connection refused",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ValidateRevision(quotaContext(test.counter), test.meta)
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("ValidateRevision() (-want, +got) = %v", cmp.Diff(test.want.Error(), got.Error()))
+			}
+		})
+	}
+}
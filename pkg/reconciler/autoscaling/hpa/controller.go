@@ -18,18 +18,26 @@ package hpa
 
 import (
 	"context"
+	"time"
 
 	"knative.dev/pkg/apis/duck"
 	hpainformer "knative.dev/pkg/injection/informers/kubeinformers/autoscalingv2beta1/hpa"
 	serviceinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/service"
+	kubefactory "knative.dev/pkg/injection/informers/kubeinformers/factory"
 	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
 	sksinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/serverlessservice"
 
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	autoscalingv2beta1informers "k8s.io/client-go/informers/autoscaling/v2beta1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/reconciler"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
@@ -40,6 +48,17 @@ const (
 	controllerAgentName = "hpa-class-podautoscaler-controller"
 )
 
+func init() {
+	// The HPAs are only watched once HPA-class PAs are used.
+	informers.RegisterLazy(informers.HPA, func(ctx context.Context, lazy *informers.Lazy) {
+		kubefactory.Get(ctx).InformerFor(&autoscalingv2beta1.HorizontalPodAutoscaler{},
+			func(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+				return lazy.Wrap(autoscalingv2beta1informers.NewHorizontalPodAutoscalerInformer(client, metav1.NamespaceAll,
+					resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+			})
+	})
+}
+
 // NewController returns a new HPA reconcile controller.
 func NewController(
 	ctx context.Context,
@@ -61,10 +80,13 @@ func NewController(
 			ServiceLister:     serviceInformer.Lister(),
 			Metrics:           metrics,
 			PSInformerFactory: psInformerFactory,
+			StatusLimiter:     reconciler.NewStatusLimiter(system.RealClock{}),
 		},
-		hpaLister: hpaInformer.Lister(),
+		hpaLister:    hpaInformer.Lister(),
+		hpaInformers: informers.GetLazy(ctx, informers.HPA),
 	}
 	impl := controller.NewImpl(c, c.Logger, "HPA-Class Autoscaling")
+	c.EnqueueAfter = impl.EnqueueAfter
 
 	c.Logger.Info("Setting up hpa-class event handlers")
 	onlyHpaClass := reconciler.AnnotationFilterFunc(autoscaling.ClassAnnotationKey, autoscaling.HPA, false)
@@ -72,7 +94,11 @@ func NewController(
 		FilterFunc: onlyHpaClass,
 		Handler:    controller.HandleAll(impl.Enqueue),
 	}
-	paInformer.Informer().AddEventHandler(paHandler)
+	// The resyncs of the informer are spread, the global updates are not.
+	paInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: onlyHpaClass,
+		Handler:    reconciler.JitterResyncs(impl, reconciler.ResyncJitter),
+	})
 
 	hpaInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: onlyHpaClass,
@@ -88,9 +114,10 @@ func NewController(
 	configsToResync := []interface{}{
 		&autoscaler.Config{},
 	}
-	resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
-		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
-	})
+	resync := configmap.TypeFilter(configsToResync...)(reconciler.SelectiveResync(
+		paInformer.Informer(), areconciler.AffectedByConfig, func(obj interface{}) {
+			paHandler.OnUpdate(obj, obj)
+		}))
 	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
 	configStore.WatchConfigs(cmw)
 	c.ConfigStore = configStore
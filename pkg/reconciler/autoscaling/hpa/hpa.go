@@ -34,6 +34,7 @@ import (
 	"knative.dev/pkg/logging"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/informers"
 	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
 	"knative.dev/serving/pkg/reconciler/autoscaling/config"
 	"knative.dev/serving/pkg/reconciler/autoscaling/hpa/resources"
@@ -43,6 +44,8 @@ import (
 type Reconciler struct {
 	*areconciler.Base
 	hpaLister autoscalingv2beta1listers.HorizontalPodAutoscalerLister
+	// hpaInformers are started by the first HPA-class PA reconciled.
+	hpaInformers *informers.Lazy
 }
 
 var _ controller.Reconciler = (*Reconciler)(nil)
@@ -111,6 +114,9 @@ func (c *Reconciler) reconcile(ctx context.Context, key string, pa *pav1alpha1.P
 	pa.Status.MarkActive()
 
 	// HPA-class PA delegates autoscaling to the Kubernetes Horizontal Pod Autoscaler.
+	if err := c.hpaInformers.Start(); err != nil {
+		return err
+	}
 	desiredHpa := resources.MakeHPA(pa, config.FromContext(ctx).Autoscaler)
 	hpa, err := c.hpaLister.HorizontalPodAutoscalers(pa.Namespace).Get(desiredHpa.Name)
 	if errors.IsNotFound(err) {
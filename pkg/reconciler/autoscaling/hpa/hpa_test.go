@@ -395,6 +395,8 @@ func sks(ns, n string, so ...SKSOption) *nv1a1.ServerlessService {
 	for _, opt := range so {
 		opt(s)
 	}
+	// The spec of the SKS is the one we applied.
+	presources.SetLastAppliedSpec(s, s.Spec)
 	return s
 }
 
@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"knative.dev/pkg/apis/duck"
+	serviceinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/service"
+	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
+	sksinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/serverlessservice"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/reconciler"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+)
+
+const (
+	controllerAgentName = "keda-class-podautoscaler-controller"
+)
+
+// NewController returns a new KEDA reconcile controller, which wakes the
+// revisions the activator reports demand for to demand.
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+	demand *Demand,
+	psInformerFactory duck.InformerFactory,
+) *controller.Impl {
+
+	paInformer := painformer.Get(ctx)
+	sksInformer := sksinformer.Get(ctx)
+	serviceInformer := serviceinformer.Get(ctx)
+
+	c := &Reconciler{
+		Base: &areconciler.Base{
+			Base:              reconciler.NewBase(ctx, controllerAgentName, cmw),
+			PALister:          paInformer.Lister(),
+			SKSLister:         sksInformer.Lister(),
+			ServiceLister:     serviceInformer.Lister(),
+			PSInformerFactory: psInformerFactory,
+			StatusLimiter:     reconciler.NewStatusLimiter(system.RealClock{}),
+		},
+		demand: demand,
+	}
+	impl := controller.NewImpl(c, c.Logger, "KEDA-Class Autoscaling")
+	c.EnqueueAfter = impl.EnqueueAfter
+	demand.watch(paInformer.Lister(), impl.EnqueueKey)
+
+	c.Logger.Info("Setting up keda-class event handlers")
+	onlyKedaClass := reconciler.AnnotationFilterFunc(autoscaling.ClassAnnotationKey, autoscaling.KEDA, false)
+	paHandler := cache.FilteringResourceEventHandler{
+		FilterFunc: onlyKedaClass,
+		Handler:    controller.HandleAll(impl.Enqueue),
+	}
+	// The resyncs of the informer are spread, the global updates are not.
+	paInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: onlyKedaClass,
+		Handler:    reconciler.JitterResyncs(impl, reconciler.ResyncJitter),
+	})
+
+	sksInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: onlyKedaClass,
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
+	// KEDA scales the deployments directly, so watch them through the same
+	// informer the scale is read from to follow scaling to and from zero.
+	// Deployments carry the annotations of their revision, which shares its
+	// name with the PA.
+	if deploymentInformer, _, err := psInformerFactory.Get(appsv1.SchemeGroupVersion.WithResource("deployments")); err != nil {
+		c.Logger.Errorw("Failed to watch deployments", zap.Error(err))
+	} else {
+		deploymentInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+			FilterFunc: onlyKedaClass,
+			Handler:    controller.HandleAll(impl.EnqueueLabelOfNamespaceScopedResource("", serving.RevisionLabelKey)),
+		})
+	}
+
+	c.Logger.Info("Setting up ConfigMap receivers")
+	configsToResync := []interface{}{
+		&autoscaler.Config{},
+	}
+	resync := configmap.TypeFilter(configsToResync...)(reconciler.SelectiveResync(
+		paInformer.Informer(), areconciler.AffectedByConfig, func(obj interface{}) {
+			paHandler.OnUpdate(obj, obj)
+		}))
+	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
+	configStore.WatchConfigs(cmw)
+	c.ConfigStore = configStore
+
+	return impl
+}
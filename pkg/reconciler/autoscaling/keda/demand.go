@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/autoscaler"
+	listers "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+)
+
+// Demand records when the activator last reported requests for the
+// revisions of KEDA-class PodAutoscalers. The triggers of KEDA know nothing
+// of those requests, so the reconciler keeps a replica up while there is
+// demand, to wake the revisions from zero.
+type Demand struct {
+	mu       sync.Mutex
+	last     map[string]time.Time
+	paLister listers.PodAutoscalerLister
+	enqueue  func(key string)
+	now      func() time.Time
+}
+
+// NewDemand creates a Demand, which records nothing until the controller
+// watching it is created.
+func NewDemand() *Demand {
+	return &Demand{
+		last: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// watch starts recording the demand for the KEDA-class PodAutoscalers of
+// paLister, passing their keys to enqueue when demand shows up.
+func (d *Demand) watch(paLister listers.PodAutoscalerLister, enqueue func(key string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paLister = paLister
+	d.enqueue = enqueue
+}
+
+// Poke records the stat the activator reported for the revision of key.
+func (d *Demand) Poke(key string, stat autoscaler.Stat) {
+	if stat.AverageConcurrentRequests == 0 {
+		return
+	}
+	d.mu.Lock()
+	paLister, enqueue := d.paLister, d.enqueue
+	_, known := d.last[key]
+	d.mu.Unlock()
+	if paLister == nil {
+		return
+	}
+	if !known {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return
+		}
+		pa, err := paLister.PodAutoscalers(namespace).Get(name)
+		if err != nil || pa.Class() != autoscaling.KEDA {
+			return
+		}
+	}
+
+	d.mu.Lock()
+	d.last[key] = d.now()
+	d.mu.Unlock()
+	if !known {
+		enqueue(key)
+	}
+}
+
+// Remaining returns how long the demand for the revision of key lasts,
+// when it was last reported within window, and forgets it otherwise.
+func (d *Demand) Remaining(key string, window time.Duration) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	last, ok := d.last[key]
+	if !ok {
+		return 0
+	}
+	remaining := window - d.now().Sub(last)
+	if remaining <= 0 {
+		delete(d.last, key)
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/serving/pkg/autoscaler"
+
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/serving/pkg/testing"
+)
+
+func TestDemand(t *testing.T) {
+	const window = time.Minute
+	kedaKey, kpaKey := key(testRevision, testNamespace), key("kpa-revision", testNamespace)
+	listers := NewListers([]runtime.Object{
+		pa(testRevision, testNamespace, WithKEDAClass, withTriggers),
+		pa("kpa-revision", testNamespace, WithKPAClass),
+	})
+
+	now := time.Now()
+	var enqueued []string
+	d := NewDemand()
+	d.now = func() time.Time { return now }
+
+	// Nothing is recorded until the controller watches.
+	d.Poke(kedaKey, autoscaler.Stat{AverageConcurrentRequests: 1})
+	if got := d.Remaining(kedaKey, window); got != 0 {
+		t.Errorf("Remaining() = %v before watching, want 0", got)
+	}
+
+	d.watch(listers.GetPodAutoscalerLister(), func(key string) {
+		enqueued = append(enqueued, key)
+	})
+	d.Poke(kedaKey, autoscaler.Stat{})
+	d.Poke(kpaKey, autoscaler.Stat{AverageConcurrentRequests: 1})
+	if len(enqueued) != 0 {
+		t.Errorf("Enqueued %v without demand for a KEDA-class PA", enqueued)
+	}
+
+	d.Poke(kedaKey, autoscaler.Stat{AverageConcurrentRequests: 1})
+	now = now.Add(window / 2)
+	d.Poke(kedaKey, autoscaler.Stat{AverageConcurrentRequests: 2})
+	if got, want := len(enqueued), 1; got != want {
+		t.Errorf("Enqueued %d times, want %d", got, want)
+	}
+	if got, want := d.Remaining(kedaKey, window), window; got != want {
+		t.Errorf("Remaining() = %v, want %v", got, want)
+	}
+
+	// The demand is forgotten once the window passed, and enqueued anew
+	// when it shows up again.
+	now = now.Add(window)
+	if got := d.Remaining(kedaKey, window); got != 0 {
+		t.Errorf("Remaining() = %v after the window, want 0", got)
+	}
+	d.Poke(kedaKey, autoscaler.Stat{AverageConcurrentRequests: 1})
+	if got, want := len(enqueued), 2; got != want {
+		t.Errorf("Enqueued %d times, want %d", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+
+	perrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+	"knative.dev/serving/pkg/reconciler/autoscaling/keda/resources"
+	resourceutil "knative.dev/serving/pkg/resources"
+)
+
+// Reconciler implements the control loop for the KEDA resources.
+type Reconciler struct {
+	*areconciler.Base
+	demand *Demand
+}
+
+var _ controller.Reconciler = (*Reconciler)(nil)
+
+// Reconcile is the entry point to the reconciliation control loop.
+func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("invalid resource key %s: %v", key, err))
+		return nil
+	}
+	logger := logging.FromContext(ctx)
+	ctx = c.ConfigStore.ToContext(ctx)
+	logger.Debug("Reconcile keda-class PodAutoscaler")
+
+	original, err := c.PALister.PodAutoscalers(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		logger.Debug("PA no longer exists")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	// Deployment events are enqueued by revision name, which may belong to
+	// a PA of another class.
+	if original.Class() != autoscaling.KEDA {
+		return nil
+	}
+
+	// Don't modify the informer's copy.
+	pa := original.DeepCopy()
+	// Reconcile this copy of the pa and then write back any status
+	// updates regardless of whether the reconciliation errored out.
+	reconcileErr := c.reconcile(ctx, pa)
+	if equality.Semantic.DeepEqual(original.Status, pa.Status) {
+		// If we didn't change anything then don't call updateStatus.
+		// This is important because the copy we loaded from the informer's
+		// cache may be stale and we don't want to overwrite a prior update
+		// to status with this stale state.
+	} else if _, err = c.UpdateStatus(pa); err != nil {
+		logger.Warnw("Failed to update pa status", zap.Error(err))
+		c.Recorder.Eventf(pa, corev1.EventTypeWarning, "UpdateFailed",
+			"Failed to update status for PA %q: %v", pa.Name, err)
+		return err
+	}
+	if reconcileErr != nil {
+		c.Recorder.Event(pa, corev1.EventTypeWarning, "InternalError", reconcileErr.Error())
+	}
+	return reconcileErr
+}
+
+func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler) error {
+	logger := logging.FromContext(ctx)
+
+	if pa.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	// We may be reading a version of the object that was stored at an older version
+	// and may not have had all of the assumed defaults specified.  This won't result
+	// in this getting written back to the API Server, but lets downstream logic make
+	// assumptions about defaulting.
+	pa.SetDefaults(ctx)
+
+	pa.Status.InitializeConditions()
+	logger.Debug("PA exists")
+
+	// The requests the activator holds don't activate the triggers of KEDA,
+	// so a replica is kept up while there are any, and for a stable window
+	// after.
+	remaining := c.demand.Remaining(pa.Namespace+"/"+pa.Name, config.FromContext(ctx).Autoscaler.StableWindow)
+	if remaining > 0 {
+		c.EnqueueAfter(pa, remaining)
+	}
+
+	// KEDA-class PA delegates autoscaling to a KEDA ScaledObject.
+	if err := c.reconcileScaledObject(ctx, pa, remaining > 0); err != nil {
+		return err
+	}
+
+	// KEDA scales the deployment, including to and from zero. We only follow
+	// along, so that the activator is put in the request path while there are
+	// no pods to serve requests.
+	scale, err := resourceutil.GetScaleResource(pa.Namespace, pa.Spec.ScaleTargetRef, c.PSInformerFactory)
+	if err != nil {
+		return perrors.Wrap(err, "error retrieving scale")
+	}
+	if scale.Status.Replicas == 0 {
+		pa.Status.MarkInactive("NoTraffic", "The target is scaled to zero by KEDA.")
+	} else {
+		pa.Status.MarkActive()
+	}
+
+	// KEDA has its own deciders.
+	sks, err := c.ReconcileSKS(ctx, pa, nil /* decider */)
+	if err != nil {
+		return perrors.Wrap(err, "error reconciling SKS")
+	}
+	// Propagate the service name regardless of the status.
+	pa.Status.ServiceName = sks.Status.ServiceName
+	if !pa.Status.IsInactive() && !sks.Status.IsReady() {
+		pa.Status.MarkInactive("ServicesNotReady", "SKS Services are not ready yet")
+	}
+
+	pa.Status.ObservedGeneration = pa.Generation
+	return nil
+}
+
+func (c *Reconciler) reconcileScaledObject(ctx context.Context, pa *pav1alpha1.PodAutoscaler, activated bool) error {
+	logger := logging.FromContext(ctx)
+
+	desired, err := resources.MakeScaledObject(pa, config.FromContext(ctx).Autoscaler, activated)
+	if err != nil {
+		return perrors.Wrap(err, "error making ScaledObject")
+	}
+	client := c.DynamicClientSet.Resource(resources.ScaledObjectGVR).Namespace(pa.Namespace)
+	so, err := client.Get(desired.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		logger.Infof("Creating ScaledObject %q", desired.GetName())
+		if _, err := client.Create(desired, metav1.CreateOptions{}); err != nil {
+			logger.Errorf("Error creating ScaledObject %q: %v", desired.GetName(), err)
+			pa.Status.MarkResourceFailedCreation("ScaledObject", desired.GetName())
+			return err
+		}
+		return nil
+	} else if err != nil {
+		logger.Errorf("Error getting existing ScaledObject %q: %v", desired.GetName(), err)
+		return err
+	} else if !metav1.IsControlledBy(so, pa) {
+		// Surface an error in the PodAutoscaler's status, and return an error.
+		pa.Status.MarkResourceNotOwned("ScaledObject", desired.GetName())
+		return fmt.Errorf("PodAutoscaler: %q does not own ScaledObject: %q", pa.Name, desired.GetName())
+	}
+
+	// Only compare the fields we set, KEDA may default others.
+	want := so.DeepCopy()
+	want.SetLabels(desired.GetLabels())
+	want.SetAnnotations(desired.GetAnnotations())
+	spec, _ := want.Object["spec"].(map[string]interface{})
+	if spec == nil {
+		spec = make(map[string]interface{})
+		want.Object["spec"] = spec
+	}
+	for k, v := range desired.Object["spec"].(map[string]interface{}) {
+		spec[k] = v
+	}
+	if !equality.Semantic.DeepEqual(want, so) {
+		logger.Infof("Updating ScaledObject %q", desired.GetName())
+		if _, err := client.Update(want, metav1.UpdateOptions{}); err != nil {
+			logger.Errorf("Error updating ScaledObject %q: %v", desired.GetName(), err)
+			return err
+		}
+	}
+	return nil
+}
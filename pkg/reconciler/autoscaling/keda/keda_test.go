@@ -0,0 +1,406 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	// Inject our fake informers
+	fakedynamicclient "knative.dev/pkg/injection/clients/dynamicclient/fake"
+	_ "knative.dev/pkg/injection/informers/kubeinformers/corev1/service/fake"
+	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
+	fakepainformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
+	_ "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/serverlessservice/fake"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	asv1a1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/networking"
+	nv1a1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+	"knative.dev/serving/pkg/reconciler"
+	areconciler "knative.dev/serving/pkg/reconciler/autoscaling"
+	"knative.dev/serving/pkg/reconciler/autoscaling/config"
+	"knative.dev/serving/pkg/reconciler/autoscaling/keda/resources"
+	aresources "knative.dev/serving/pkg/reconciler/autoscaling/resources"
+	presources "knative.dev/serving/pkg/resources"
+
+	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/serving/pkg/testing"
+)
+
+const (
+	testNamespace = "test-namespace"
+	testRevision  = "test-revision"
+	testTriggers  = `[{"type": "kafka", "metadata": {"topic": "orders", "lagThreshold": "50"}}]`
+)
+
+func TestControllerCanReconcile(t *testing.T) {
+	ctx, _ := SetupFakeContext(t)
+
+	psFactory := presources.NewPodScalableInformerFactory(ctx)
+
+	ctl := NewController(ctx, configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      autoscaler.ConfigName,
+		},
+		Data: map[string]string{},
+	}), NewDemand(), psFactory)
+
+	podAutoscaler := pa(testRevision, testNamespace, WithKEDAClass, withTriggers)
+	fakeservingclient.Get(ctx).AutoscalingV1alpha1().PodAutoscalers(testNamespace).Create(podAutoscaler)
+	fakepainformer.Get(ctx).Informer().GetIndexer().Add(podAutoscaler)
+
+	// The deployment doesn't exist, so we only get as far as the ScaledObject.
+	if err := ctl.Reconciler.Reconcile(context.Background(), testNamespace+"/"+testRevision); err == nil {
+		t.Error("Reconcile() = nil, wanted an error retrieving the scale")
+	}
+
+	if _, err := fakedynamicclient.Get(ctx).Resource(resources.ScaledObjectGVR).Namespace(testNamespace).Get(testRevision, metav1.GetOptions{}); err != nil {
+		t.Errorf("error getting ScaledObject: %v", err)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	const deployName = testRevision + "-deployment"
+
+	table := TableTest{{
+		Name: "create scaledobject & sks",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers),
+			deploy(testNamespace, testRevision),
+		},
+		Key: key(testRevision, testNamespace),
+		WantCreates: []runtime.Object{
+			scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers)),
+			sks(testNamespace, testRevision, nv1a1.SKSOperationModeServe, WithDeployRef(deployName)),
+		},
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithKEDAClass, withTriggers,
+				WithNoTraffic("ServicesNotReady", "SKS Services are not ready yet")),
+		}},
+	}, {
+		Name: "scaled to zero puts the activator in the path",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithTraffic,
+				WithPAStatusService(testRevision)),
+			deploy(testNamespace, testRevision, withReplicas(0)),
+			sks(testNamespace, testRevision, nv1a1.SKSOperationModeServe, WithDeployRef(deployName), WithSKSReady),
+		},
+		Key: key(testRevision, testNamespace),
+		WithReactors: []ktesting.ReactionFunc{
+			getScaledObject(scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers))),
+		},
+		WantUpdates: []ktesting.UpdateActionImpl{{
+			Object: sks(testNamespace, testRevision, nv1a1.SKSOperationModeProxy, WithDeployRef(deployName), WithSKSReady),
+		}},
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithKEDAClass, withTriggers,
+				WithNoTraffic("NoTraffic", "The target is scaled to zero by KEDA."),
+				WithPAStatusService(testRevision)),
+		}},
+	}, {
+		Name: "scaled from zero takes the activator out of the path",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers,
+				WithNoTraffic("NoTraffic", "The target is scaled to zero by KEDA."),
+				WithPAStatusService(testRevision)),
+			deploy(testNamespace, testRevision),
+			sks(testNamespace, testRevision, nv1a1.SKSOperationModeProxy, WithDeployRef(deployName), WithSKSReady),
+		},
+		Key: key(testRevision, testNamespace),
+		WithReactors: []ktesting.ReactionFunc{
+			getScaledObject(scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers))),
+		},
+		WantUpdates: []ktesting.UpdateActionImpl{{
+			Object: sks(testNamespace, testRevision, nv1a1.SKSOperationModeServe, WithDeployRef(deployName), WithSKSReady),
+		}},
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithTraffic,
+				WithPAStatusService(testRevision)),
+		}},
+	}, {
+		Name: "update scaledobject",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithTraffic,
+				WithPAStatusService(testRevision), withMaxScale("10")),
+			deploy(testNamespace, testRevision),
+			sks(testNamespace, testRevision, nv1a1.SKSOperationModeServe, WithDeployRef(deployName), WithSKSReady),
+		},
+		Key: key(testRevision, testNamespace),
+		WithReactors: []ktesting.ReactionFunc{
+			getScaledObject(scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers))),
+		},
+		WantUpdates: []ktesting.UpdateActionImpl{{
+			Object: scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers, withMaxScale("10"))),
+		}},
+	}, {
+		Name: "scaledobject is disowned",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers),
+			deploy(testNamespace, testRevision),
+		},
+		Key: key(testRevision, testNamespace),
+		WithReactors: []ktesting.ReactionFunc{
+			getScaledObject(scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithPAOwnersRemoved), func(so *unstructured.Unstructured) {
+				so.SetOwnerReferences(nil)
+			})),
+		},
+		WantErr: true,
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithKEDAClass, withTriggers,
+				MarkResourceNotOwnedByPA("ScaledObject", testRevision)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError",
+				`PodAutoscaler: "test-revision" does not own ScaledObject: "test-revision"`),
+		},
+	}, {
+		Name: "failure to create scaledobject",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers),
+			deploy(testNamespace, testRevision),
+		},
+		Key: key(testRevision, testNamespace),
+		WantCreates: []runtime.Object{
+			scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers)),
+		},
+		WithReactors: []ktesting.ReactionFunc{
+			InduceFailure("create", "scaledobjects"),
+		},
+		WantStatusUpdates: []ktesting.UpdateActionImpl{{
+			Object: pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithNoTraffic(
+				"FailedCreate", "Failed to create ScaledObject \"test-revision\".")),
+		}},
+		WantErr: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for create scaledobjects"),
+		},
+	}, {
+		Name: "pa of another class is skipped",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKPAClass),
+			deploy(testNamespace, testRevision),
+		},
+		Key: key(testRevision, testNamespace),
+	}, {
+		Name: "nop deletion reconcile",
+		// Test that with a DeletionTimestamp we do nothing.
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers, WithPADeletionTimestamp),
+			deploy(testNamespace, testRevision),
+		},
+		Key: key(testRevision, testNamespace),
+	}, {
+		Name: "invalid key",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers),
+		},
+		Key: "sandwich///",
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &Reconciler{
+			Base: &areconciler.Base{
+				Base:              reconciler.NewBase(ctx, controllerAgentName, cmw),
+				PALister:          listers.GetPodAutoscalerLister(),
+				SKSLister:         listers.GetServerlessServiceLister(),
+				ConfigStore:       &testConfigStore{config: defaultConfig()},
+				ServiceLister:     listers.GetK8sServiceLister(),
+				PSInformerFactory: presources.NewPodScalableInformerFactory(ctx),
+			},
+			demand: NewDemand(),
+		}
+	}))
+}
+
+func TestReconcileActivatorDemand(t *testing.T) {
+	const deployName = testRevision + "-deployment"
+
+	table := TableTest{{
+		Name: "activator demand keeps a replica up",
+		Objects: []runtime.Object{
+			pa(testRevision, testNamespace, WithKEDAClass, withTriggers,
+				WithNoTraffic("NoTraffic", "The target is scaled to zero by KEDA."),
+				WithPAStatusService(testRevision)),
+			deploy(testNamespace, testRevision, withReplicas(0)),
+			sks(testNamespace, testRevision, nv1a1.SKSOperationModeProxy, WithDeployRef(deployName), WithSKSReady),
+		},
+		Key: key(testRevision, testNamespace),
+		WithReactors: []ktesting.ReactionFunc{
+			getScaledObject(scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers))),
+		},
+		WantUpdates: []ktesting.UpdateActionImpl{{
+			Object: scaledObject(pa(testRevision, testNamespace, WithKEDAClass, withTriggers), withMinReplicas(1)),
+		}},
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		demand := NewDemand()
+		demand.watch(listers.GetPodAutoscalerLister(), func(string) {})
+		demand.Poke(key(testRevision, testNamespace), autoscaler.Stat{AverageConcurrentRequests: 1})
+		return &Reconciler{
+			Base: &areconciler.Base{
+				Base:              reconciler.NewBase(ctx, controllerAgentName, cmw),
+				PALister:          listers.GetPodAutoscalerLister(),
+				SKSLister:         listers.GetServerlessServiceLister(),
+				ConfigStore:       &testConfigStore{config: defaultConfig()},
+				ServiceLister:     listers.GetK8sServiceLister(),
+				PSInformerFactory: presources.NewPodScalableInformerFactory(ctx),
+				EnqueueAfter:      func(interface{}, time.Duration) {},
+			},
+			demand: demand,
+		}
+	}))
+}
+
+func key(name, namespace string) string {
+	return namespace + "/" + name
+}
+
+func withTriggers(pa *asv1a1.PodAutoscaler) {
+	pa.Annotations[autoscaling.KEDATriggersAnnotationKey] = testTriggers
+}
+
+func withMaxScale(max string) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Annotations[autoscaling.MaxScaleAnnotationKey] = max
+	}
+}
+
+func pa(name, namespace string, options ...PodAutoscalerOption) *asv1a1.PodAutoscaler {
+	pa := &asv1a1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{},
+		},
+		Spec: asv1a1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       name + "-deployment",
+			},
+			ProtocolType: networking.ProtocolHTTP1,
+		},
+	}
+	for _, opt := range options {
+		opt(pa)
+	}
+	return pa
+}
+
+func scaledObject(pa *asv1a1.PodAutoscaler, options ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+	so, _ := resources.MakeScaledObject(pa, defaultConfig().Autoscaler, false /* activated */)
+	for _, o := range options {
+		o(so)
+	}
+	return so
+}
+
+func withMinReplicas(min int64) func(*unstructured.Unstructured) {
+	return func(so *unstructured.Unstructured) {
+		unstructured.SetNestedField(so.Object, min, "spec", "minReplicaCount")
+	}
+}
+
+// getScaledObject makes the dynamic client behave as if so existed, since
+// the object sorter of the table tests doesn't know about ScaledObjects.
+func getScaledObject(so *unstructured.Unstructured) ktesting.ReactionFunc {
+	return func(action ktesting.Action) (bool, runtime.Object, error) {
+		switch {
+		case action.Matches("get", "scaledobjects"):
+			return true, so.DeepCopy(), nil
+		case action.Matches("update", "scaledobjects"):
+			return true, action.(ktesting.UpdateAction).GetObject(), nil
+		}
+		return false, nil, nil
+	}
+}
+
+func sks(ns, n string, mode nv1a1.ServerlessServiceOperationMode, so ...SKSOption) *nv1a1.ServerlessService {
+	s := aresources.MakeSKS(pa(n, ns, WithKEDAClass, withTriggers), mode)
+	for _, opt := range so {
+		opt(s)
+	}
+	// The spec of the SKS is the one we applied.
+	presources.SetLastAppliedSpec(s, s.Spec)
+	return s
+}
+
+type deploymentOption func(*appsv1.Deployment)
+
+func withReplicas(replicas int32) deploymentOption {
+	return func(d *appsv1.Deployment) {
+		d.Status.Replicas = replicas
+	}
+}
+
+func deploy(namespace, name string, opts ...deploymentOption) *appsv1.Deployment {
+	s := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-deployment",
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"a": "b",
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			Replicas: 42,
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func defaultConfig() *config.Config {
+	autoscalerConfig, _ := autoscaler.NewConfigFromMap(nil)
+	return &config.Config{
+		Autoscaler: autoscalerConfig,
+	}
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
+
+var _ reconciler.ConfigStore = (*testConfigStore)(nil)
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"math"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+)
+
+// deploymentNameLabelKey is the label KEDA uses to find the ScaledObject
+// of a Deployment.
+const deploymentNameLabelKey = "deploymentName"
+
+var (
+	// ScaledObjectGVK is the GroupVersionKind of KEDA ScaledObjects.
+	ScaledObjectGVK = schema.GroupVersionKind{
+		Group:   "keda.k8s.io",
+		Version: "v1alpha1",
+		Kind:    "ScaledObject",
+	}
+	// ScaledObjectGVR is the GroupVersionResource of KEDA ScaledObjects.
+	ScaledObjectGVR = ScaledObjectGVK.GroupVersion().WithResource("scaledobjects")
+)
+
+// scaledObjectSpec mirrors the parts of the KEDA ScaledObject spec we set.
+// KEDA's types are not vendored, so ScaledObjects are handled as
+// unstructured objects.
+type scaledObjectSpec struct {
+	ScaleTargetRef  scaleTargetRef            `json:"scaleTargetRef"`
+	MinReplicaCount int32                     `json:"minReplicaCount"`
+	MaxReplicaCount int32                     `json:"maxReplicaCount"`
+	Triggers        []autoscaling.KEDATrigger `json:"triggers"`
+}
+
+type scaleTargetRef struct {
+	DeploymentName string `json:"deploymentName"`
+}
+
+// MakeScaledObject creates a KEDA ScaledObject from a PA resource. While
+// activated, the activator holds requests for the revision, which keep a
+// replica up.
+func MakeScaledObject(pa *v1alpha1.PodAutoscaler, config *autoscaler.Config, activated bool) (*unstructured.Unstructured, error) {
+	if pa.Spec.ScaleTargetRef.Kind != "Deployment" {
+		return nil, fmt.Errorf("KEDA can only scale Deployments, got %s", pa.Spec.ScaleTargetRef.Kind)
+	}
+	triggers, err := autoscaling.ParseKEDATriggers(pa.Annotations[autoscaling.KEDATriggersAnnotationKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", autoscaling.KEDATriggersAnnotationKey, err)
+	}
+
+	min, max := pa.ScaleBounds()
+	if min == 0 && (activated || !config.EnableScaleToZero) {
+		min = 1
+	}
+	if max == 0 {
+		max = math.MaxInt32 // default to no limit
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&scaledObjectSpec{
+		ScaleTargetRef:  scaleTargetRef{DeploymentName: pa.Spec.ScaleTargetRef.Name},
+		MinReplicaCount: min,
+		MaxReplicaCount: max,
+		Triggers:        triggers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(map[string]string, len(pa.Labels)+1)
+	for k, v := range pa.Labels {
+		labels[k] = v
+	}
+	labels[deploymentNameLabelKey] = pa.Spec.ScaleTargetRef.Name
+
+	so := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	so.SetGroupVersionKind(ScaledObjectGVK)
+	so.SetName(pa.Name)
+	so.SetNamespace(pa.Namespace)
+	so.SetLabels(labels)
+	so.SetAnnotations(pa.Annotations)
+	so.SetOwnerReferences([]metav1.OwnerReference{*kmeta.NewControllerRef(pa)})
+	return so, nil
+}
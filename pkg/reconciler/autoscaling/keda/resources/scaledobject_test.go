@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/autoscaler"
+
+	. "knative.dev/serving/pkg/testing"
+)
+
+const (
+	testNamespace = "test-namespace"
+	testName      = "test-name"
+	testTriggers  = `[{"type": "kafka", "metadata": {"topic": "orders", "lagThreshold": "50"}}]`
+)
+
+func TestMakeScaledObject(t *testing.T) {
+	cases := []struct {
+		name        string
+		pa          *v1alpha1.PodAutoscaler
+		noZero      bool
+		activated   bool
+		wantMin     int64
+		wantMax     int64
+		wantErr     bool
+		wantTrigger map[string]interface{}
+	}{{
+		name:    "defaults",
+		pa:      pa(),
+		wantMin: 0,
+		wantMax: math.MaxInt32,
+	}, {
+		name:    "with bounds",
+		pa:      pa(WithLowerScaleBound(2), WithUpperScaleBound(5)),
+		wantMin: 2,
+		wantMax: 5,
+	}, {
+		name:    "scale to zero disabled",
+		pa:      pa(),
+		noZero:  true,
+		wantMin: 1,
+		wantMax: math.MaxInt32,
+	}, {
+		name:      "activated",
+		pa:        pa(),
+		activated: true,
+		wantMin:   1,
+		wantMax:   math.MaxInt32,
+	}, {
+		name:      "activated with bounds",
+		pa:        pa(WithLowerScaleBound(2), WithUpperScaleBound(5)),
+		activated: true,
+		wantMin:   2,
+		wantMax:   5,
+	}, {
+		name: "not a deployment",
+		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
+			pa.Spec.ScaleTargetRef.Kind = "StatefulSet"
+		}),
+		wantErr: true,
+	}, {
+		name: "no triggers",
+		pa: pa(func(pa *v1alpha1.PodAutoscaler) {
+			delete(pa.Annotations, autoscaling.KEDATriggersAnnotationKey)
+		}),
+		wantErr: true,
+	}}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &autoscaler.Config{EnableScaleToZero: !tc.noZero}
+			got, err := MakeScaledObject(tc.pa, config, tc.activated)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("MakeScaledObject() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if got, want := got.GroupVersionKind(), ScaledObjectGVK; got != want {
+				t.Errorf("GVK = %v, want %v", got, want)
+			}
+			if got, want := got.GetLabels()[deploymentNameLabelKey], testName+"-deployment"; got != want {
+				t.Errorf("deploymentName label = %q, want %q", got, want)
+			}
+			if !metav1.IsControlledBy(got, tc.pa) {
+				t.Error("ScaledObject is not controlled by the PA")
+			}
+
+			want := map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"deploymentName": testName + "-deployment",
+				},
+				"minReplicaCount": tc.wantMin,
+				"maxReplicaCount": tc.wantMax,
+				"triggers": []interface{}{map[string]interface{}{
+					"type": "kafka",
+					"metadata": map[string]interface{}{
+						"topic":        "orders",
+						"lagThreshold": "50",
+					},
+				}},
+			}
+			if diff := cmp.Diff(want, got.Object["spec"]); diff != "" {
+				t.Errorf("spec (-want, +got) = %s", diff)
+			}
+		})
+	}
+}
+
+func pa(options ...PodAutoscalerOption) *v1alpha1.PodAutoscaler {
+	p := &v1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: testNamespace,
+			Name:      testName,
+			UID:       "2006",
+			Annotations: map[string]string{
+				autoscaling.ClassAnnotationKey:        autoscaling.KEDA,
+				autoscaling.KEDATriggersAnnotationKey: testTriggers,
+			},
+		},
+		Spec: v1alpha1.PodAutoscalerSpec{
+			ScaleTargetRef: corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       testName + "-deployment",
+			},
+		},
+	}
+	for _, fn := range options {
+		fn(p)
+	}
+	return p
+}
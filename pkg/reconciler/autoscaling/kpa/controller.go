@@ -28,6 +28,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	"knative.dev/serving/pkg/autoscaler"
 	"knative.dev/serving/pkg/reconciler"
@@ -62,11 +63,13 @@ func NewController(
 			ServiceLister:     serviceInformer.Lister(),
 			Metrics:           metrics,
 			PSInformerFactory: psInformerFactory,
+			StatusLimiter:     reconciler.NewStatusLimiter(system.RealClock{}),
 		},
 		endpointsLister: endpointsInformer.Lister(),
 		deciders:        deciders,
 	}
 	impl := controller.NewImpl(c, c.Logger, "KPA-Class Autoscaling")
+	c.EnqueueAfter = impl.EnqueueAfter
 	c.scaler = newScaler(ctx, psInformerFactory, impl.EnqueueAfter)
 
 	c.Logger.Info("Setting up KPA-Class event handlers")
@@ -76,7 +79,11 @@ func NewController(
 		FilterFunc: onlyKpaClass,
 		Handler:    controller.HandleAll(impl.Enqueue),
 	}
-	paInformer.Informer().AddEventHandler(paHandler)
+	// The resyncs of the informer are spread, the global updates are not.
+	paInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: onlyKpaClass,
+		Handler:    reconciler.JitterResyncs(impl, reconciler.ResyncJitter),
+	})
 
 	endpointsInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: reconciler.LabelExistsFilterFunc(autoscaling.KPALabelKey),
@@ -100,9 +107,10 @@ func NewController(
 	configsToResync := []interface{}{
 		&autoscaler.Config{},
 	}
-	resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
-		controller.SendGlobalUpdates(paInformer.Informer(), paHandler)
-	})
+	resync := configmap.TypeFilter(configsToResync...)(reconciler.SelectiveResync(
+		paInformer.Informer(), areconciler.AffectedByConfig, func(obj interface{}) {
+			paHandler.OnUpdate(obj, obj)
+		}))
 	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
 	configStore.WatchConfigs(cmw)
 	c.ConfigStore = configStore
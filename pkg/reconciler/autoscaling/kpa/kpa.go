@@ -19,14 +19,13 @@ package kpa
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	perrors "github.com/pkg/errors"
 	"go.uber.org/zap"
 
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
-	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/pkg/ptr"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/autoscaler"
@@ -38,6 +37,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -156,6 +156,7 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 
 	// Propagate service name.
 	pa.Status.ServiceName = sks.Status.ServiceName
+	propagateIdleSince(pa, decider)
 	if sks.Status.IsReady() {
 		podCounter := resourceutil.NewScopedEndpointsCounter(c.endpointsLister, pa.Namespace, sks.Status.PrivateServiceName)
 		got, err = podCounter.ReadyCount()
@@ -164,15 +165,29 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 		}
 	}
 	logger.Infof("PA scale got=%v, want=%v", got, want)
+	pa.Status.ActualScale = ptr.Int32(int32(got))
 
 	err = reportMetrics(pa, want, got)
 	if err != nil {
 		return perrors.Wrap(err, "error reporting metrics")
 	}
 
+	wasInactive := pa.Status.IsInactive()
 	// computeActiveCondition decides if we need to change the SKS mode,
 	// and returns true if the status has changed.
-	if changed := computeActiveCondition(pa, want, got); changed {
+	changed := computeActiveCondition(pa, want, got)
+	switch isInactive := pa.Status.IsInactive(); {
+	case isInactive && !wasInactive:
+		c.Recorder.Eventf(pa, corev1.EventTypeNormal, "ScaledToZero",
+			"Revision %q scaled to zero", pa.Name)
+	case !isInactive && wasInactive:
+		c.Recorder.Eventf(pa, corev1.EventTypeNormal, "ScalingFromZero",
+			"Revision %q is scaling from zero to %d replicas", pa.Name, want)
+	}
+	if err := c.computeNodesAvailable(pa, want, got); err != nil {
+		return perrors.Wrap(err, "error checking for unschedulable pods")
+	}
+	if changed {
 		_, err := c.ReconcileSKS(ctx, pa, decider)
 		if err != nil {
 			return perrors.Wrap(err, "error re-reconciling SKS")
@@ -181,6 +196,21 @@ func (c *Reconciler) reconcile(ctx context.Context, pa *pav1alpha1.PodAutoscaler
 	return nil
 }
 
+// propagateIdleSince sets the time the revision is idle since from the
+// decider. The time the PA has is kept while the revision stays idle, it
+// predates the decider after the autoscaler restarted. Until the decider
+// scaled once it can't tell an active revision from one idle since before.
+func propagateIdleSince(pa *pav1alpha1.PodAutoscaler, decider *autoscaler.Decider) {
+	switch idleSince := decider.Status.IdleSince; {
+	case !idleSince.IsZero():
+		if pa.Status.IdleSince == nil {
+			pa.Status.IdleSince = idleSince.DeepCopy()
+		}
+	case decider.Status.DesiredScale >= 0:
+		pa.Status.IdleSince = nil
+	}
+}
+
 func (c *Reconciler) reconcileDecider(ctx context.Context, pa *pav1alpha1.PodAutoscaler, k8sSvc string) (*autoscaler.Decider, error) {
 	desiredDecider := resources.MakeDecider(ctx, pa, config.FromContext(ctx).Autoscaler, k8sSvc)
 	decider, err := c.deciders.Get(ctx, desiredDecider.Namespace, desiredDecider.Name)
@@ -257,15 +287,62 @@ func computeActiveCondition(pa *pav1alpha1.PodAutoscaler, want int32, got int) (
 	return
 }
 
-// activeThreshold returns the scale required for the pa to be marked Active
-func activeThreshold(pa *pav1alpha1.PodAutoscaler) int {
-	if min, ok := pa.Annotations[autoscaling.MinScaleAnnotationKey]; ok {
-		if ms, err := strconv.Atoi(min); err == nil {
-			if ms > 1 {
-				return ms
+// computeNodesAvailable surfaces on the PA whether the pods we want but
+// don't have yet are waiting for nodes, i.e. can't be scheduled for lack of
+// capacity and depend on the cluster autoscaler provisioning more.
+// The condition is only added once that happens, so that it is absent for
+// clusters that always have enough capacity.
+func (c *Reconciler) computeNodesAvailable(pa *pav1alpha1.PodAutoscaler, want int32, got int) error {
+	pending := 0
+	if int(want) > got {
+		ps, err := resourceutil.GetScaleResource(pa.Namespace, pa.Spec.ScaleTargetRef, c.PSInformerFactory)
+		if err != nil {
+			return err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(ps.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		pods, err := c.KubeClientSet.CoreV1().Pods(pa.Namespace).List(metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods.Items {
+			if isUnschedulable(&pod) {
+				pending++
 			}
 		}
 	}
 
+	switch {
+	case pending > 0:
+		if !pa.Status.IsWaitingOnNodes() {
+			c.Recorder.Eventf(pa, corev1.EventTypeNormal, "WaitingOnNodes",
+				"%d pods of revision %q are waiting for nodes to be provisioned", pending, pa.Name)
+		}
+		pa.Status.MarkWaitingOnNodes(pending)
+	case pa.Status.GetCondition(pav1alpha1.PodAutoscalerConditionNodesAvailable) != nil:
+		pa.Status.MarkNodesAvailable()
+	}
+	return nil
+}
+
+// isUnschedulable returns true if the scheduler found no node to fit the pod.
+func isUnschedulable(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled {
+			return cond.Status == corev1.ConditionFalse && cond.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+	return false
+}
+
+// activeThreshold returns the scale required for the pa to be marked Active
+func activeThreshold(pa *pav1alpha1.PodAutoscaler) int {
+	if min, _ := pa.ScaleBounds(); min > 1 {
+		return int(min)
+	}
 	return 1
 }
@@ -136,6 +136,8 @@ func sks(ns, n string, so ...SKSOption) *nv1a1.ServerlessService {
 	for _, opt := range so {
 		opt(s)
 	}
+	// The spec of the SKS is the one we applied.
+	presources.SetLastAppliedSpec(s, s.Spec)
 	return s
 }
 
@@ -202,7 +204,7 @@ func TestReconcileNegativeBurstCapacity(t *testing.T) {
 		Ctx:  context.WithValue(context.Background(), ebcKey, int32(-1)),
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("yak-40"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("yak-40")),
@@ -215,7 +217,7 @@ func TestReconcileNegativeBurstCapacity(t *testing.T) {
 		Ctx:  context.WithValue(context.Background(), ebcKey, int32(-1)),
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("yak-42"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("yak-42")),
@@ -232,7 +234,7 @@ func TestReconcileNegativeBurstCapacity(t *testing.T) {
 		Ctx:  context.WithValue(context.Background(), ebcKey, int32(1)),
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("yak-42"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("yak-42")),
@@ -290,7 +292,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			kpa(testNamespace, testRevision,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
 				markOld, WithPAStatusService(testRevision),
-				withMSvcStatus("my-my-hey-hey")),
+				withMSvcStatus("my-my-hey-hey"), WithPAActualScale(0)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("my-my-hey-hey")),
@@ -307,7 +309,7 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			kpa(testNamespace, testRevision,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
 				markOld, WithPAStatusService(testRevision),
-				withMSvcStatus("out-of-the-blue")),
+				withMSvcStatus("out-of-the-blue"), WithPAActualScale(0)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithProxyMode, WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("out-of-the-blue")),
@@ -338,12 +340,16 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: kpa(testNamespace, testRevision,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
-				WithPAStatusService(testRevision), withMSvcStatus("and-into-the-black")),
+				WithPAStatusService(testRevision), withMSvcStatus("and-into-the-black"),
+				WithPAActualScale(1)),
 		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: sks(testNamespace, testRevision, WithSKSReady,
 				WithDeployRef(deployName), WithProxyMode),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ScaledToZero", "Revision %q scaled to zero", testRevision),
+		},
 	}, {
 		Name: "from serving to proxy, sks update fail :-(",
 		Key:  key,
@@ -361,13 +367,15 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			InduceFailure("update", "serverlessservices"),
 		},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ScaledToZero", "Revision %q scaled to zero", testRevision),
 			Eventf(corev1.EventTypeWarning, "InternalError",
 				"error re-reconciling SKS: error updating SKS test-revision: inducing failure for update serverlessservices"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: kpa(testNamespace, testRevision,
 				WithNoTraffic("NoTraffic", "The target is not receiving traffic."),
-				WithPAStatusService(testRevision), withMSvcStatus("they-give-you-this")),
+				WithPAStatusService(testRevision), withMSvcStatus("they-give-you-this"),
+				WithPAActualScale(1)),
 		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: sks(testNamespace, testRevision, WithSKSReady,
@@ -378,7 +386,8 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 		Key:  key,
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive,
-				WithPAStatusService(testRevision), withMSvcStatus("but-you-pay-for-that")),
+				WithPAStatusService(testRevision), withMSvcStatus("but-you-pay-for-that"),
+				WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("but-you-pay-for-that")),
@@ -401,7 +410,8 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: kpa(testNamespace, testRevision,
 				WithNoTraffic("TimedOut", "The target could not be activated."),
-				WithPAStatusService(testRevision), withMSvcStatus("once-you're-gone")),
+				WithPAStatusService(testRevision), withMSvcStatus("once-you're-gone"),
+				WithPAActualScale(1)),
 		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: sks(testNamespace, testRevision, WithSKSReady,
@@ -414,6 +424,9 @@ func TestReconcileAndScaleToZero(t *testing.T) {
 			Name:  deployName,
 			Patch: []byte(`[{"op":"add","path":"/spec/replicas","value":0}]`),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ScaledToZero", "Revision %q scaled to zero", testRevision),
+		},
 	}}
 
 	defer logtesting.ClearAll()
@@ -483,13 +496,54 @@ func TestReconcile(t *testing.T) {
 		Key:  key,
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-200"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("a330-200")),
 			expectedDeploy,
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
+	}, {
+		Name: "pods waiting on nodes",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-300"),
+				WithPAStatusService(testRevision)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
+				withMSvcName("a330-300")),
+			expectedDeploy,
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+			pod(testNamespace, "scheduled", usualSelector),
+			pod(testNamespace, "pending-1", usualSelector, withUnschedulable),
+			pod(testNamespace, "pending-2", usualSelector, withUnschedulable),
+			pod(testNamespace, "other-pending", map[string]string{"c": "d"}, withUnschedulable),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-300"),
+				WithPAStatusService(testRevision), withWaitingOnNodes(2), WithPAActualScale(1)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "WaitingOnNodes",
+				"2 pods of revision %q are waiting for nodes to be provisioned", testRevision),
+		},
+	}, {
+		Name: "nodes provisioned",
+		Key:  key,
+		Objects: []runtime.Object{
+			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-800"),
+				WithPAStatusService(testRevision), withWaitingOnNodes(2)),
+			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
+			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
+				withMSvcName("a330-800")),
+			expectedDeploy,
+			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
+			pod(testNamespace, "scheduled", usualSelector),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: kpa(testNamespace, testRevision, markActive, withMSvcStatus("a330-800"),
+				WithPAStatusService(testRevision), markNodesAvailable, WithPAActualScale(1)),
+		}},
 	}, {
 		Name: "metric-service-mistmatch",
 		Key:  key,
@@ -516,7 +570,8 @@ func TestReconcile(t *testing.T) {
 		}},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: kpa(testNamespace, testRevision, markActive,
-				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001")),
+				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001"),
+				WithPAActualScale(1)),
 		}},
 		WantCreates: []runtime.Object{
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -526,7 +581,7 @@ func TestReconcile(t *testing.T) {
 		Key:  key,
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a380-800"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector),
 				withMSvcName("a380-800")),
@@ -563,7 +618,8 @@ func TestReconcile(t *testing.T) {
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: kpa(testNamespace, testRevision, markActive,
-				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001")),
+				WithPAStatusService(testRevision), withMSvcStatus(testRevision+"-00001"),
+				WithPAActualScale(1)),
 		}},
 		WantCreates: []runtime.Object{
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
@@ -594,7 +650,7 @@ func TestReconcile(t *testing.T) {
 		Key:  key,
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive,
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			metricsSvc(testNamespace, testRevision, withSvcSelector(usualSelector)),
 			deploy(testNamespace, testRevision),
@@ -638,7 +694,7 @@ func TestReconcile(t *testing.T) {
 		Key:  key,
 		Objects: []runtime.Object{
 			kpa(testNamespace, testRevision, markActive, withMSvcStatus("a321neo"),
-				WithPAStatusService(testRevision)),
+				WithPAStatusService(testRevision), WithPAActualScale(1)),
 			sks(testNamespace, testRevision, WithDeployRef(deployName), WithSKSReady),
 			expectedDeploy,
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
@@ -727,8 +783,12 @@ func TestReconcile(t *testing.T) {
 				WithDeployRef(deployName)),
 		}},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision),
+				WithPAActualScale(0)),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ScalingFromZero", "Revision %q is scaling from zero to %d replicas", testRevision, 11),
+		},
 	}, {
 		Name: "sks is still not ready",
 		Key:  key,
@@ -741,7 +801,8 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision),
+				WithPAActualScale(0)),
 		}},
 	}, {
 		Name: "sks becomes ready",
@@ -754,7 +815,8 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActive, WithPAStatusService(testRevision),
+				WithPAActualScale(1)),
 		}},
 	}, {
 		Name: "kpa does not become ready without minScale endpoints",
@@ -767,7 +829,8 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(1, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActivating, withMinScale(2), WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActivating, withMinScale(2), WithPAStatusService(testRevision),
+				WithPAActualScale(1)),
 		}},
 	}, {
 		Name: "kpa becomes ready with minScale endpoints",
@@ -780,7 +843,8 @@ func TestReconcile(t *testing.T) {
 			makeSKSPrivateEndpoints(2, testNamespace, testRevision),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: kpa(testNamespace, testRevision, markActive, withMinScale(2), WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActive, withMinScale(2), WithPAStatusService(testRevision),
+				WithPAActualScale(2)),
 		}},
 	}, {
 		Name: "sks does not exist",
@@ -793,7 +857,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			// SKS does not exist, so we're just creating and have no status.
-			Object: kpa(testNamespace, testRevision, markActivating),
+			Object: kpa(testNamespace, testRevision, markActivating, WithPAActualScale(0)),
 		}},
 		WantCreates: []runtime.Object{
 			sks(testNamespace, testRevision, WithDeployRef(deployName)),
@@ -810,7 +874,8 @@ func TestReconcile(t *testing.T) {
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			// SKS just got updated and we don't have up to date status.
-			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision)),
+			Object: kpa(testNamespace, testRevision, markActivating, WithPAStatusService(testRevision),
+				WithPAActualScale(0)),
 		}},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: sks(testNamespace, testRevision, WithPubService,
@@ -904,6 +969,46 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func withWaitingOnNodes(pending int) PodAutoscalerOption {
+	return func(pa *asv1a1.PodAutoscaler) {
+		pa.Status.MarkWaitingOnNodes(pending)
+	}
+}
+
+func markNodesAvailable(pa *asv1a1.PodAutoscaler) {
+	pa.Status.MarkNodesAvailable()
+}
+
+type podOption func(*corev1.Pod)
+
+func pod(namespace, name string, labels map[string]string, opts ...podOption) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodScheduled,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func withUnschedulable(p *corev1.Pod) {
+	p.Status.Conditions = []corev1.PodCondition{{
+		Type:   corev1.PodScheduled,
+		Status: corev1.ConditionFalse,
+		Reason: corev1.PodReasonUnschedulable,
+	}}
+}
+
 type deploymentOption func(*appsv1.Deployment)
 
 func deploy(namespace, name string, opts ...deploymentOption) *appsv1.Deployment {
@@ -1523,3 +1628,43 @@ func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
 }
 
 var _ reconciler.ConfigStore = (*testConfigStore)(nil)
+
+func TestPropagateIdleSince(t *testing.T) {
+	then := time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	later := then.Add(time.Hour)
+	tests := []struct {
+		name    string
+		have    *metav1.Time
+		decider autoscaler.DeciderStatus
+		want    *metav1.Time
+	}{{
+		name:    "becomes idle",
+		decider: autoscaler.DeciderStatus{IdleSince: metav1.Time{Time: then}},
+		want:    &metav1.Time{Time: then},
+	}, {
+		name:    "stays idle since before",
+		have:    &metav1.Time{Time: then},
+		decider: autoscaler.DeciderStatus{DesiredScale: -1, IdleSince: metav1.Time{Time: later}},
+		want:    &metav1.Time{Time: then},
+	}, {
+		name:    "becomes active",
+		have:    &metav1.Time{Time: then},
+		decider: autoscaler.DeciderStatus{DesiredScale: 1},
+	}, {
+		name:    "unknown until scaled",
+		have:    &metav1.Time{Time: then},
+		decider: autoscaler.DeciderStatus{DesiredScale: -1},
+		want:    &metav1.Time{Time: then},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pa := kpa(testNamespace, testRevision)
+			pa.Status.IdleSince = test.have
+			propagateIdleSince(pa, &autoscaler.Decider{Status: test.decider})
+			if !cmp.Equal(pa.Status.IdleSince, test.want) {
+				t.Errorf("IdleSince = %v, want %v", pa.Status.IdleSince, test.want)
+			}
+		})
+	}
+}
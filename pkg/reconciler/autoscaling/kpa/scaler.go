@@ -127,6 +127,11 @@ func activatorProbe(pa *pav1alpha1.PodAutoscaler, transport http.RoundTripper) (
 	if pa.Status.ServiceName == "" {
 		return false, nil
 	}
+	// Raw TCP connections carry no probe response to tell the activator
+	// apart; the grace period alone covers the switch to proxy mode.
+	if pa.Spec.ProtocolType == networking.ProtocolTCP {
+		return true, nil
+	}
 	return prober.Do(context.Background(), transport, paToProbeTarget(pa), probeOptions...)
 }
 
@@ -38,6 +38,7 @@ import (
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/autoscaling"
 	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	clientset "knative.dev/serving/pkg/client/clientset/versioned"
@@ -600,6 +601,16 @@ func TestActivatorProbe(t *testing.T) {
 			}
 		})
 	}
+
+	// The TCP revisions are never probed.
+	tcp := pa.DeepCopy()
+	tcp.Spec.ProtocolType = networking.ProtocolTCP
+	if res, err := activatorProbe(tcp, network.RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Error("Unexpected probe of a TCP revision")
+		return nil, theErr
+	})); !res || err != nil {
+		t.Errorf("activatorProbe() = %v, %v, want true, nil", res, err)
+	}
 }
 
 type countingProber struct {
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	perrors "github.com/pkg/errors"
 
@@ -43,6 +44,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 )
 
@@ -55,6 +57,11 @@ type Base struct {
 	Metrics           resources.Metrics
 	ConfigStore       reconciler.ConfigStore
 	PSInformerFactory duck.InformerFactory
+
+	// StatusLimiter batches the status updates of the PodAutoscalers, which
+	// are enqueued with EnqueueAfter once they may be updated.
+	StatusLimiter *reconciler.StatusLimiter
+	EnqueueAfter  func(interface{}, time.Duration)
 }
 
 // ReconcileSKS reconciles a ServerlessService based on the given PodAutoscaler.
@@ -66,7 +73,11 @@ func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler, d
 	// 1. The revision is scaled to 0.
 	// 2. The excess burst capacity is negative.
 	if pa.Status.IsInactive() || (d != nil && d.Status.ExcessBurstCapacity < 0) {
-		logger.Debugf("SKS %s is in proxy mode: pa.IsInactive = %v, ebc = %d", pa.Name, pa.Status.IsInactive(), d.Status.ExcessBurstCapacity)
+		if d != nil {
+			logger.Debugf("SKS %s is in proxy mode: pa.IsInactive = %v, ebc = %d", pa.Name, pa.Status.IsInactive(), d.Status.ExcessBurstCapacity)
+		} else {
+			logger.Debugf("SKS %s is in proxy mode: pa.IsInactive = %v", pa.Name, pa.Status.IsInactive())
+		}
 		mode = nv1alpha1.SKSOperationModeProxy
 	}
 	sksName := anames.SKS(pa.Name)
@@ -74,6 +85,9 @@ func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler, d
 	if errors.IsNotFound(err) {
 		logger.Infof("SKS %s/%s does not exist; creating.", pa.Namespace, sksName)
 		sks = resources.MakeSKS(pa, mode)
+		if err := resourceutil.SetLastAppliedSpec(sks, sks.Spec); err != nil {
+			return nil, perrors.Wrapf(err, "error creating SKS %s", sksName)
+		}
 		_, err = c.ServingClientSet.NetworkingV1alpha1().ServerlessServices(sks.Namespace).Create(sks)
 		if err != nil {
 			return nil, perrors.Wrapf(err, "error creating SKS %s", sksName)
@@ -86,9 +100,15 @@ func (c *Base) ReconcileSKS(ctx context.Context, pa *pav1alpha1.PodAutoscaler, d
 		return nil, fmt.Errorf("PA: %s does not own SKS: %s", pa.Name, sksName)
 	} else {
 		tmpl := resources.MakeSKS(pa, mode)
-		if !equality.Semantic.DeepEqual(tmpl.Spec, sks.Spec) {
-			want := sks.DeepCopy()
-			want.Spec = tmpl.Spec
+		want := sks.DeepCopy()
+		if err := resourceutil.ApplySpec(sks, sks.Spec, tmpl.Spec, &want.Spec); err != nil {
+			return nil, perrors.Wrapf(err, "error applying SKS %s", sksName)
+		}
+		if err := resourceutil.SetLastAppliedSpec(want, tmpl.Spec); err != nil {
+			return nil, perrors.Wrapf(err, "error applying SKS %s", sksName)
+		}
+		if !equality.Semantic.DeepEqual(want.Spec, sks.Spec) ||
+			!equality.Semantic.DeepEqual(want.Annotations, sks.Annotations) {
 			logger.Infof("SKS %s changed; reconciling, want mode: %v", sksName, want.Spec.Mode)
 			if sks, err = c.ServingClientSet.NetworkingV1alpha1().ServerlessServices(sks.Namespace).Update(want); err != nil {
 				return nil, perrors.Wrapf(err, "error updating SKS %s", sksName)
@@ -138,11 +158,15 @@ func (c *Base) ReconcileMetricsService(ctx context.Context, pa *pav1alpha1.PodAu
 	selector := scale.Spec.Selector.MatchLabels
 	logger.Debugf("PA's %s selector: %v", pa.Name, selector)
 
+	kubeClient, err := c.ChildKubeClientSet(pa.Namespace)
+	if err != nil {
+		return "", err
+	}
 	svc, err := c.metricService(pa)
 	if errors.IsNotFound(err) {
 		logger.Infof("Metrics K8s service for PA %s/%s does not exist; creating.", pa.Namespace, pa.Name)
 		svc = resources.MakeMetricsService(pa, selector)
-		svc, err = c.KubeClientSet.CoreV1().Services(pa.Namespace).Create(svc)
+		svc, err = kubeClient.CoreV1().Services(pa.Namespace).Create(svc)
 		if err != nil {
 			return "", perrors.Wrapf(err, "error creating metrics K8s service for %s/%s", pa.Namespace, pa.Name)
 		}
@@ -160,7 +184,7 @@ func (c *Base) ReconcileMetricsService(ctx context.Context, pa *pav1alpha1.PodAu
 
 		if !equality.Semantic.DeepEqual(want.Spec, svc.Spec) {
 			logger.Info("Metrics K8s Service changed; reconciling:", svc.Name)
-			if _, err = c.KubeClientSet.CoreV1().Services(pa.Namespace).Update(want); err != nil {
+			if _, err = kubeClient.CoreV1().Services(pa.Namespace).Update(want); err != nil {
 				return "", perrors.Wrapf(err, "error updating K8s Service %s", svc.Name)
 			}
 		}
@@ -204,9 +228,54 @@ func (c *Base) UpdateStatus(desired *pav1alpha1.PodAutoscaler) (*pav1alpha1.PodA
 	if reflect.DeepEqual(pa.Status, desired.Status) {
 		return pa, nil
 	}
+	key := desired.Namespace + "/" + desired.Name
+	if delay := c.StatusLimiter.Delay(key, urgentStatusChange(&pa.Status, &desired.Status)); delay > 0 {
+		// The status is computed again, with whatever else changed, by the
+		// time it may be updated.
+		c.EnqueueAfter(desired, delay)
+		return pa, nil
+	}
 	// Don't modify the informers copy
 	existing := pa.DeepCopy()
 	existing.Status = desired.Status
 
 	return c.ServingClientSet.AutoscalingV1alpha1().PodAutoscalers(pa.Namespace).UpdateStatus(existing)
 }
+
+// urgentStatusChange reports whether the change of the status of a
+// PodAutoscaler from before to after should be updated right away.
+func urgentStatusChange(before, after *pav1alpha1.PodAutoscalerStatus) bool {
+	return reconciler.UrgentStatusChange(&before.Status, &after.Status) ||
+		before.ServiceName != after.ServiceName ||
+		before.MetricsServiceName != after.MetricsServiceName
+}
+
+// AffectedByConfig reports whether the PodAutoscaler obj is affected by the
+// change of the fields changed of the autoscaler config. The fields which the
+// PodAutoscaler overrides with annotations do not affect it.
+func AffectedByConfig(_ string, changed sets.String, obj interface{}) bool {
+	pa, ok := obj.(*pav1alpha1.PodAutoscaler)
+	if !ok {
+		return true
+	}
+	changed = sets.NewString(changed.UnsortedList()...)
+	if _, ok := pa.TargetUtilization(); ok {
+		changed.Delete("ContainerConcurrencyTargetFraction")
+	}
+	if pa.Spec.ContainerConcurrency != 0 {
+		changed.Delete("ContainerConcurrencyTargetDefault")
+	}
+	if _, ok := pa.TargetBC(); ok {
+		changed.Delete("TargetBurstCapacity")
+	}
+	if _, ok := pa.Window(); ok {
+		changed.Delete("StableWindow")
+	}
+	if _, ok := pa.PanicWindowPercentage(); ok {
+		changed.Delete("PanicWindowPercentage")
+	}
+	if _, ok := pa.PanicThresholdPercentage(); ok {
+		changed.Delete("PanicThresholdPercentage")
+	}
+	return changed.Len() > 0
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaling
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	pav1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+)
+
+func TestAffectedByConfig(t *testing.T) {
+	pa := func(cc int64, annotations map[string]string) *pav1alpha1.PodAutoscaler {
+		p := &pav1alpha1.PodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		}
+		p.Spec.ContainerConcurrency = v1beta1.RevisionContainerConcurrencyType(cc)
+		return p
+	}
+	tests := []struct {
+		name    string
+		changed []string
+		pa      *pav1alpha1.PodAutoscaler
+		want    bool
+	}{{
+		name:    "not overridden",
+		changed: []string{"StableWindow"},
+		pa:      pa(0, nil),
+		want:    true,
+	}, {
+		name:    "overridden",
+		changed: []string{"StableWindow"},
+		pa:      pa(0, map[string]string{autoscaling.WindowAnnotationKey: "30s"}),
+	}, {
+		name:    "partly overridden",
+		changed: []string{"StableWindow", "PanicThresholdPercentage"},
+		pa:      pa(0, map[string]string{autoscaling.WindowAnnotationKey: "30s"}),
+		want:    true,
+	}, {
+		name:    "default concurrency unused",
+		changed: []string{"ContainerConcurrencyTargetDefault"},
+		pa:      pa(10, nil),
+	}, {
+		name:    "default concurrency used",
+		changed: []string{"ContainerConcurrencyTargetDefault"},
+		pa:      pa(0, nil),
+		want:    true,
+	}, {
+		name:    "never overridden",
+		changed: []string{"EnableScaleToZero"},
+		pa: pa(10, map[string]string{
+			autoscaling.WindowAnnotationKey:                   "30s",
+			autoscaling.TargetUtilizationPercentageKey:        "50",
+			autoscaling.TargetBurstCapacityKey:                "100",
+			autoscaling.PanicWindowPercentageAnnotationKey:    "20",
+			autoscaling.PanicThresholdPercentageAnnotationKey: "300",
+		}),
+		want: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := sets.NewString(test.changed...)
+			if got := AffectedByConfig("config-autoscaler", changed, test.pa); got != test.want {
+				t.Errorf("AffectedByConfig() = %v, want %v", got, test.want)
+			}
+			if got, want := changed.Len(), len(test.changed); got != want {
+				t.Errorf("changed was modified: len = %d, want %d", got, want)
+			}
+		})
+	}
+}
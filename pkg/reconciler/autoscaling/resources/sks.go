@@ -42,6 +42,7 @@ func MakeSKS(pa *pav1alpha1.PodAutoscaler, mode nv1a1.ServerlessServiceOperation
 			Mode:         mode,
 			ObjectRef:    pa.Spec.ScaleTargetRef,
 			ProtocolType: pa.Spec.ProtocolType,
+			Ports:        pa.Spec.Ports,
 		},
 	}
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	cmv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
 	"go.uber.org/zap"
@@ -30,10 +31,12 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	certmanagerclientset "knative.dev/serving/pkg/client/certmanager/clientset/versioned"
 	certmanagerlisters "knative.dev/serving/pkg/client/certmanager/listers/certmanager/v1alpha1"
 	listers "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/certificate/config"
 	"knative.dev/serving/pkg/reconciler/certificate/resources"
@@ -52,8 +55,15 @@ type Reconciler struct {
 	knCertificateLister listers.CertificateLister
 	cmCertificateLister certmanagerlisters.CertificateLister
 	certManagerClient   certmanagerclientset.Interface
+	// cmInformers are started by the first reconcile.
+	cmInformers *informers.Lazy
 
-	configStore reconciler.ConfigStore
+	configStore   reconciler.ConfigStore
+	statsReporter StatsReporter
+
+	clock system.Clock
+	// enqueueAfter queues the Certificate up to be reconciled again later.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -111,12 +121,16 @@ func (c *Reconciler) reconcile(ctx context.Context, knCert *v1alpha1.Certificate
 
 	logger.Info("Reconciling Cert-Manager certificate for Knative cert %s/%s.", knCert.Namespace, knCert.Name)
 	cmConfig := config.FromContext(ctx).CertManager
+	if err := c.cmInformers.Start(); err != nil {
+		return err
+	}
 	cmCert := resources.MakeCertManagerCertificate(cmConfig, knCert)
 	cmCert, err := c.reconcileCMCertificate(ctx, knCert, cmCert)
 	if err != nil {
 		return err
 	}
 
+	wasReady, previousNotAfter := knCert.Status.IsReady(), knCert.Status.NotAfter
 	knCert.Status.NotAfter = cmCert.Status.NotAfter
 	knCert.Status.ObservedGeneration = knCert.Generation
 	// Propagate cert-manager Certificate status to Knative Certificate.
@@ -130,10 +144,73 @@ func (c *Reconciler) reconcile(ctx context.Context, knCert *v1alpha1.Certificate
 		knCert.Status.MarkReady()
 	case cmCertReadyCondition.Status == cmv1alpha1.ConditionFalse:
 		knCert.Status.MarkNotReady(cmCertReadyCondition.Reason, cmCertReadyCondition.Message)
+		if wasReady {
+			c.Recorder.Eventf(knCert, corev1.EventTypeWarning, "RenewalFailed",
+				"Certificate %s/%s failed to renew: %s", knCert.Namespace, knCert.Name, cmCertReadyCondition.Message)
+		}
+	}
+	c.recordIssuance(knCert, wasReady, previousNotAfter)
+	return c.checkExpiry(ctx, knCert, cmCert, cmConfig)
+}
+
+// checkExpiry reports the time left until the Certificate expires, and starts
+// its provisioning over when it is about to expire without having been renewed.
+func (c *Reconciler) checkExpiry(ctx context.Context, knCert *v1alpha1.Certificate, cmCert *cmv1alpha1.Certificate, cmConfig *config.CertManagerConfig) error {
+	if knCert.Status.NotAfter == nil || cmConfig.ExpiryWarningThreshold == 0 {
+		return nil
+	}
+	logger := logging.FromContext(ctx)
+
+	now := c.clock.Now()
+	remaining := knCert.Status.NotAfter.Sub(now)
+	if err := c.statsReporter.ReportValidity(knCert.Namespace, knCert.Name, remaining); err != nil {
+		logger.Warnw("Failed to report certificate validity", zap.Error(err))
+	}
+	if remaining > cmConfig.ExpiryWarningThreshold {
+		knCert.Status.MarkRenewed()
+		c.enqueueAfter(knCert, remaining-cmConfig.ExpiryWarningThreshold)
+		return nil
 	}
+
+	if !knCert.Status.IsExpiring() {
+		c.Recorder.Eventf(knCert, corev1.EventTypeWarning, "CertificateExpiring",
+			"Certificate %s/%s expires at %s and has not been renewed", knCert.Namespace, knCert.Name, knCert.Status.NotAfter.UTC().Format(time.RFC3339))
+	}
+	knCert.Status.MarkNotRenewed(now)
+
+	// Start the provisioning over at most once per retry interval. The
+	// Secret of the expiring certificate is kept, and served, until then.
+	if age := now.Sub(cmCert.CreationTimestamp.Time); age < cmConfig.RenewalRetryInterval {
+		c.enqueueAfter(knCert, cmConfig.RenewalRetryInterval-age)
+		return nil
+	}
+	logger.Infof("Recreating Cert-Manager Certificate %s/%s to renew it", cmCert.Namespace, cmCert.Name)
+	if err := c.certManagerClient.CertmanagerV1alpha1().Certificates(cmCert.Namespace).Delete(cmCert.Name, &metav1.DeleteOptions{}); err != nil {
+		c.Recorder.Eventf(knCert, corev1.EventTypeWarning, "DeleteFailed",
+			"Failed to delete Cert-Manager Certificate %s/%s: %v", cmCert.Namespace, cmCert.Name, err)
+		return err
+	}
+	c.Recorder.Eventf(knCert, corev1.EventTypeNormal, "RenewalRetried",
+		"Recreated Cert-Manager Certificate %s/%s to renew it", cmCert.Namespace, cmCert.Name)
 	return nil
 }
 
+// recordIssuance emits an Event when the Certificate is first issued, and
+// whenever it is renewed with a new expiration time.
+func (c *Reconciler) recordIssuance(knCert *v1alpha1.Certificate, wasReady bool, previousNotAfter *metav1.Time) {
+	if !knCert.Status.IsReady() || knCert.Status.NotAfter == nil {
+		return
+	}
+	switch {
+	case !wasReady || previousNotAfter == nil:
+		c.Recorder.Eventf(knCert, corev1.EventTypeNormal, "CertificateIssued",
+			"Certificate %s/%s issued, valid until %s", knCert.Namespace, knCert.Name, knCert.Status.NotAfter.UTC().Format(time.RFC3339))
+	case !previousNotAfter.Equal(knCert.Status.NotAfter):
+		c.Recorder.Eventf(knCert, corev1.EventTypeNormal, "CertificateRenewed",
+			"Certificate %s/%s renewed, valid until %s", knCert.Namespace, knCert.Name, knCert.Status.NotAfter.UTC().Format(time.RFC3339))
+	}
+}
+
 func (c *Reconciler) reconcileCMCertificate(ctx context.Context, knCert *v1alpha1.Certificate, desired *cmv1alpha1.Certificate) (*cmv1alpha1.Certificate, error) {
 	logger := logging.FromContext(ctx)
 	cmCert, err := c.cmCertificateLister.Certificates(desired.Namespace).Get(desired.Name)
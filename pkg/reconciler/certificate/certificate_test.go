@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgotesting "k8s.io/client-go/testing"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -158,6 +159,46 @@ func TestReconcile(t *testing.T) {
 					},
 				}),
 		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "CertificateIssued",
+				"Certificate foo/knCert issued, valid until %s", notAfter.UTC().Format(time.RFC3339)),
+		},
+		Key: "foo/knCert",
+	}, {
+		Name: "renewed CM Certificate updates Knative Certificate expiration",
+		Objects: []runtime.Object{
+			knCertWithStatus("knCert", "foo",
+				&v1alpha1.CertificateStatus{
+					NotAfter: &metav1.Time{Time: notAfter.Add(-24 * time.Hour)},
+					Status: duckv1beta1.Status{
+						ObservedGeneration: generation,
+						Conditions: duckv1beta1.Conditions{{
+							Type:     v1alpha1.CertificateConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				}),
+			cmCertWithStatus("knCert", "foo", correctDNSNames, certmanagerv1alpha1.ConditionTrue),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: knCertWithStatus("knCert", "foo",
+				&v1alpha1.CertificateStatus{
+					NotAfter: notAfter,
+					Status: duckv1beta1.Status{
+						ObservedGeneration: generation,
+						Conditions: duckv1beta1.Conditions{{
+							Type:     v1alpha1.CertificateConditionReady,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityError,
+						}},
+					},
+				}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "CertificateRenewed",
+				"Certificate foo/knCert renewed, valid until %s", notAfter.UTC().Format(time.RFC3339)),
+		},
 		Key: "foo/knCert",
 	}, {
 		Name: "set Knative Certificate unknown status with CM Certificate unknown status",
@@ -219,6 +260,156 @@ func TestReconcile(t *testing.T) {
 	}))
 }
 
+func TestReconcileExpiry(t *testing.T) {
+	readyCondition := apis.Condition{
+		Type:     v1alpha1.CertificateConditionReady,
+		Status:   corev1.ConditionTrue,
+		Severity: apis.ConditionSeverityError,
+	}
+	expiringCondition := apis.Condition{
+		Type:     v1alpha1.CertificateConditionRenewed,
+		Status:   corev1.ConditionFalse,
+		Severity: apis.ConditionSeverityInfo,
+		Reason:   "ExpiringSoon",
+		Message:  "The certificate expires at " + notAfter.UTC().Format(time.RFC3339) + " and has not been renewed.",
+	}
+	expiringStatus := &v1alpha1.CertificateStatus{
+		NotAfter: notAfter,
+		Status: duckv1beta1.Status{
+			ObservedGeneration: generation,
+			Conditions:         duckv1beta1.Conditions{readyCondition, expiringCondition},
+		},
+	}
+	readyStatus := &v1alpha1.CertificateStatus{
+		NotAfter: notAfter,
+		Status: duckv1beta1.Status{
+			ObservedGeneration: generation,
+			Conditions:         duckv1beta1.Conditions{readyCondition},
+		},
+	}
+
+	var now time.Time
+	table := TableTest{{
+		Name: "certificate far from expiry",
+		Ctx:  withNow(notAfter.Add(-30 * 24 * time.Hour)),
+		Objects: []runtime.Object{
+			knCertWithStatus("knCert", "foo", readyStatus),
+			cmCertWithStatus("knCert", "foo", correctDNSNames, certmanagerv1alpha1.ConditionTrue),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: knCertWithStatus("knCert", "foo",
+				&v1alpha1.CertificateStatus{
+					NotAfter: notAfter,
+					Status: duckv1beta1.Status{
+						ObservedGeneration: generation,
+						Conditions: duckv1beta1.Conditions{readyCondition, {
+							Type:     v1alpha1.CertificateConditionRenewed,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityInfo,
+						}},
+					},
+				}),
+		}},
+		Key: "foo/knCert",
+	}, {
+		Name: "certificate about to expire",
+		Ctx:  withNow(notAfter.Add(-24 * time.Hour)),
+		Objects: []runtime.Object{
+			knCertWithStatus("knCert", "foo", readyStatus),
+			cmCertCreatedAt("knCert", "foo", notAfter.Add(-24*time.Hour-time.Minute)),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: knCertWithStatus("knCert", "foo", expiringStatus),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "CertificateExpiring",
+				"Certificate foo/knCert expires at %s and has not been renewed", notAfter.UTC().Format(time.RFC3339)),
+		},
+		Key: "foo/knCert",
+	}, {
+		Name: "expiring certificate provisioned over",
+		Ctx:  withNow(notAfter.Add(-24 * time.Hour)),
+		Objects: []runtime.Object{
+			knCertWithStatus("knCert", "foo", expiringStatus),
+			cmCertCreatedAt("knCert", "foo", notAfter.Add(-48*time.Hour)),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource: schema.GroupVersionResource{
+					Group:    "certmanager.k8s.io",
+					Version:  "v1alpha1",
+					Resource: "certificates",
+				},
+			},
+			Name: "knCert",
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "RenewalRetried",
+				"Recreated Cert-Manager Certificate %s/%s to renew it", "foo", "knCert"),
+		},
+		Key: "foo/knCert",
+	}, {
+		Name: "renewal failed",
+		Ctx:  withNow(notAfter.Add(-30 * 24 * time.Hour)),
+		Objects: []runtime.Object{
+			knCertWithStatus("knCert", "foo", readyStatus),
+			cmCertWithStatus("knCert", "foo", correctDNSNames, certmanagerv1alpha1.ConditionFalse),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: knCertWithStatus("knCert", "foo",
+				&v1alpha1.CertificateStatus{
+					NotAfter: notAfter,
+					Status: duckv1beta1.Status{
+						ObservedGeneration: generation,
+						Conditions: duckv1beta1.Conditions{{
+							Type:     v1alpha1.CertificateConditionReady,
+							Status:   corev1.ConditionFalse,
+							Severity: apis.ConditionSeverityError,
+						}, {
+							Type:     v1alpha1.CertificateConditionRenewed,
+							Status:   corev1.ConditionTrue,
+							Severity: apis.ConditionSeverityInfo,
+						}},
+					},
+				}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RenewalFailed", "Certificate foo/knCert failed to renew: "),
+		},
+		Key: "foo/knCert",
+	}}
+
+	defer ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		now = ctx.Value(nowKey{}).(time.Time)
+		cmConfig := certmanagerConfig()
+		cmConfig.ExpiryWarningThreshold = 7 * 24 * time.Hour
+		cmConfig.RenewalRetryInterval = time.Hour
+		return &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			knCertificateLister: listers.GetKnCertificateLister(),
+			cmCertificateLister: listers.GetCMCertificateLister(),
+			certManagerClient:   fakecertmanagerclient.Get(ctx),
+			configStore: &testConfigStore{
+				config: &config.Config{
+					CertManager: cmConfig,
+				},
+			},
+			statsReporter: NewStatsReporter(),
+			clock:         FakeClock{Time: now},
+			enqueueAfter:  func(interface{}, time.Duration) {},
+		}
+	}))
+}
+
+type nowKey struct{}
+
+func withNow(now time.Time) context.Context {
+	return context.WithValue(context.Background(), nowKey{}, now)
+}
+
 type testConfigStore struct {
 	config *config.Config
 }
@@ -268,6 +459,12 @@ func cmCert(name, namespace string, dnsNames []string) *certmanagerv1alpha1.Cert
 	return cert
 }
 
+func cmCertCreatedAt(name, namespace string, created time.Time) *certmanagerv1alpha1.Certificate {
+	cert := cmCertWithStatus(name, namespace, correctDNSNames, certmanagerv1alpha1.ConditionTrue)
+	cert.CreationTimestamp = metav1.Time{Time: created}
+	return cert
+}
+
 func cmCertWithStatus(name, namespace string, dnsNames []string, status certmanagerv1alpha1.ConditionStatus) *certmanagerv1alpha1.Certificate {
 	cert := cmCert(name, namespace, dnsNames)
 	cert.UpdateStatusCondition(certmanagerv1alpha1.CertificateConditionReady, status, "", "", false)
@@ -17,6 +17,9 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ghodss/yaml"
 
 	certmanagerv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -27,6 +30,12 @@ const (
 	solverConfigKey = "solverConfig"
 	issuerRefKey    = "issuerRef"
 
+	expiryWarningThresholdKey = "expiryWarningThreshold"
+	renewalRetryIntervalKey   = "renewalRetryInterval"
+
+	defaultExpiryWarningThreshold = 7 * 24 * time.Hour
+	defaultRenewalRetryInterval   = time.Hour
+
 	// CertManagerConfigName is the name of the configmap containing all
 	// configuration related to Cert-Manager.
 	CertManagerConfigName = "config-certmanager"
@@ -37,6 +46,13 @@ const (
 type CertManagerConfig struct {
 	SolverConfig *certmanagerv1alpha1.SolverConfig
 	IssuerRef    *certmanagerv1alpha1.ObjectReference
+
+	// ExpiryWarningThreshold is how long before they expire Certificates
+	// that haven't been renewed are reported as expiring, zero to never.
+	ExpiryWarningThreshold time.Duration
+	// RenewalRetryInterval is how often the provisioning of expiring
+	// Certificates is started over.
+	RenewalRetryInterval time.Duration
 }
 
 // NewCertManagerConfigFromConfigMap creates an CertManagerConfig from the supplied ConfigMap
@@ -46,6 +62,9 @@ func NewCertManagerConfigFromConfigMap(configMap *corev1.ConfigMap) (*CertManage
 	config := &CertManagerConfig{
 		SolverConfig: &certmanagerv1alpha1.SolverConfig{},
 		IssuerRef:    &certmanagerv1alpha1.ObjectReference{},
+
+		ExpiryWarningThreshold: defaultExpiryWarningThreshold,
+		RenewalRetryInterval:   defaultRenewalRetryInterval,
 	}
 
 	if v, ok := configMap.Data[solverConfigKey]; ok {
@@ -59,5 +78,30 @@ func NewCertManagerConfigFromConfigMap(configMap *corev1.ConfigMap) (*CertManage
 			return nil, err
 		}
 	}
+
+	for _, d := range []struct {
+		key   string
+		field *time.Duration
+	}{{
+		key:   expiryWarningThresholdKey,
+		field: &config.ExpiryWarningThreshold,
+	}, {
+		key:   renewalRetryIntervalKey,
+		field: &config.RenewalRetryInterval,
+	}} {
+		if v, ok := configMap.Data[d.key]; ok {
+			val, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %v", d.key, err)
+			}
+			if val < 0 {
+				return nil, fmt.Errorf("%s must not be negative, was %v", d.key, val)
+			}
+			*d.field = val
+		}
+	}
+	if config.RenewalRetryInterval == 0 {
+		return nil, fmt.Errorf("%s must be positive", renewalRetryIntervalKey)
+	}
 	return config, nil
 }
@@ -17,6 +17,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	certmanagerv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
@@ -67,6 +68,8 @@ func TestIssuerRef(t *testing.T) {
 				Name: "letsencrypt-issuer",
 				Kind: "ClusterIssuer",
 			},
+			ExpiryWarningThreshold: defaultExpiryWarningThreshold,
+			RenewalRetryInterval:   defaultRenewalRetryInterval,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -119,7 +122,9 @@ func TestSolverConfig(t *testing.T) {
 					Provider: "cloud-dns-provider",
 				},
 			},
-			IssuerRef: &certmanagerv1alpha1.ObjectReference{},
+			IssuerRef:              &certmanagerv1alpha1.ObjectReference{},
+			ExpiryWarningThreshold: defaultExpiryWarningThreshold,
+			RenewalRetryInterval:   defaultRenewalRetryInterval,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -139,7 +144,9 @@ func TestSolverConfig(t *testing.T) {
 					Ingress: "test-ingress",
 				},
 			},
-			IssuerRef: &certmanagerv1alpha1.ObjectReference{},
+			IssuerRef:              &certmanagerv1alpha1.ObjectReference{},
+			ExpiryWarningThreshold: defaultExpiryWarningThreshold,
+			RenewalRetryInterval:   defaultRenewalRetryInterval,
 		},
 		config: &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
@@ -164,3 +171,70 @@ func TestSolverConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRenewalConfig(t *testing.T) {
+	cases := []struct {
+		name       string
+		wantErr    bool
+		wantConfig *CertManagerConfig
+		data       map[string]string
+	}{{
+		name: "custom threshold and interval",
+		wantConfig: &CertManagerConfig{
+			SolverConfig:           &certmanagerv1alpha1.SolverConfig{},
+			IssuerRef:              &certmanagerv1alpha1.ObjectReference{},
+			ExpiryWarningThreshold: 72 * time.Hour,
+			RenewalRetryInterval:   10 * time.Minute,
+		},
+		data: map[string]string{
+			expiryWarningThresholdKey: "72h",
+			renewalRetryIntervalKey:   "10m",
+		},
+	}, {
+		name: "reporting turned off",
+		wantConfig: &CertManagerConfig{
+			SolverConfig:         &certmanagerv1alpha1.SolverConfig{},
+			IssuerRef:            &certmanagerv1alpha1.ObjectReference{},
+			RenewalRetryInterval: defaultRenewalRetryInterval,
+		},
+		data: map[string]string{
+			expiryWarningThresholdKey: "0s",
+		},
+	}, {
+		name:    "invalid threshold",
+		wantErr: true,
+		data: map[string]string{
+			expiryWarningThresholdKey: "a week",
+		},
+	}, {
+		name:    "negative threshold",
+		wantErr: true,
+		data: map[string]string{
+			expiryWarningThresholdKey: "-1h",
+		},
+	}, {
+		name:    "zero retry interval",
+		wantErr: true,
+		data: map[string]string{
+			renewalRetryIntervalKey: "0s",
+		},
+	}}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualConfig, err := NewCertManagerConfigFromConfigMap(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: system.Namespace(),
+					Name:      CertManagerConfigName,
+				},
+				Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCertManagerConfigFromConfigMap() error = %v, WantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantConfig, actualConfig); diff != "" {
+				t.Errorf("Unexpected config (-want, +got): %s", diff)
+			}
+		})
+	}
+}
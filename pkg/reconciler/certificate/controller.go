@@ -18,15 +18,23 @@ package certificate
 
 import (
 	"context"
+	"time"
 
+	cmv1alpha1 "github.com/jetstack/cert-manager/pkg/apis/certmanager/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
+	cmversioned "knative.dev/serving/pkg/client/certmanager/clientset/versioned"
+	cminformers "knative.dev/serving/pkg/client/certmanager/informers/externalversions/certmanager/v1alpha1"
 	cmclient "knative.dev/serving/pkg/client/certmanager/injection/client"
+	cmfactory "knative.dev/serving/pkg/client/certmanager/injection/informers/certmanager/factory"
 	cmcertinformer "knative.dev/serving/pkg/client/certmanager/injection/informers/certmanager/v1alpha1/certificate"
 	kcertinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/certificate"
 
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/certificate/config"
@@ -36,6 +44,18 @@ const (
 	controllerAgentName = "certificate-controller"
 )
 
+func init() {
+	// The cert-manager Certificates are only watched once a Knative
+	// Certificate of the cert-manager class is reconciled.
+	informers.RegisterLazy(informers.CertManager, func(ctx context.Context, lazy *informers.Lazy) {
+		cmfactory.Get(ctx).InformerFor(&cmv1alpha1.Certificate{},
+			func(client cmversioned.Interface, resync time.Duration) cache.SharedIndexInformer {
+				return lazy.Wrap(cminformers.NewCertificateInformer(client, metav1.NamespaceAll,
+					resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+			})
+	})
+}
+
 // NewController initializes the controller and is called by the generated code
 // Registers eventhandlers to enqueue events.
 func NewController(
@@ -49,11 +69,15 @@ func NewController(
 		Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
 		knCertificateLister: knCertificateInformer.Lister(),
 		cmCertificateLister: cmCertificateInformer.Lister(),
+		cmInformers:         informers.GetLazy(ctx, informers.CertManager),
 		// TODO(mattmoor): Move this to the base.
 		certManagerClient: cmclient.Get(ctx),
+		statsReporter:     NewStatsReporter(),
+		clock:             system.RealClock{},
 	}
 
 	impl := controller.NewImpl(c, c.Logger, "Certificate")
+	c.enqueueAfter = impl.EnqueueAfter
 
 	c.Logger.Info("Setting up event handlers")
 	classFilterFunc := reconciler.AnnotationFilterFunc(networking.CertificateClassAnnotationKey, network.CertManagerCertificateClassName, true)
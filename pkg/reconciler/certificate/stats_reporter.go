@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/metrics/metricskey"
+)
+
+const (
+	// CertificateValidityN is the time left until a certificate expires.
+	CertificateValidityN = "certificate_remaining_validity"
+)
+
+var (
+	certificateValidityStat = stats.Int64(
+		CertificateValidityN,
+		"Time left until a certificate expires, negative once it has",
+		stats.UnitMilliseconds)
+
+	namespaceTagKey   = mustNewTagKey(metricskey.LabelNamespaceName)
+	certificateTagKey = mustNewTagKey("certificate")
+)
+
+func init() {
+	if err := view.Register(&view.View{
+		Description: certificateValidityStat.Description(),
+		Measure:     certificateValidityStat,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{namespaceTagKey, certificateTagKey},
+	}); err != nil {
+		panic(err)
+	}
+}
+
+// StatsReporter reports the metrics of Certificates.
+type StatsReporter interface {
+	// ReportValidity reports the time left until a Certificate expires.
+	ReportValidity(namespace, certificate string, d time.Duration) error
+}
+
+type reporter struct{}
+
+// NewStatsReporter creates a reporter for the metrics of Certificates.
+func NewStatsReporter() StatsReporter {
+	return &reporter{}
+}
+
+// ReportValidity reports the time left until a Certificate expires.
+func (r *reporter) ReportValidity(namespace, certificate string, d time.Duration) error {
+	ctx, err := tag.New(
+		context.Background(),
+		tag.Insert(namespaceTagKey, namespace),
+		tag.Insert(certificateTagKey, certificate))
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, certificateValidityStat.M(int64(d/time.Millisecond)))
+	return nil
+}
+
+func mustNewTagKey(s string) tag.Key {
+	tagKey, err := tag.NewKey(s)
+	if err != nil {
+		panic(err)
+	}
+	return tagKey
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestReportValidity(t *testing.T) {
+	r := NewStatsReporter()
+	if err := r.ReportValidity("test-ns", "test-cert", -time.Minute); err != nil {
+		t.Errorf("ReportValidity() = %v", err)
+	}
+
+	rows, err := view.RetrieveData(CertificateValidityN)
+	if err != nil {
+		t.Fatalf("RetrieveData() = %v", err)
+	}
+	// The tags of rows are sorted by key.
+	want := []tag.Tag{{Key: certificateTagKey, Value: "test-cert"}, {Key: namespaceTagKey, Value: "test-ns"}}
+	for _, row := range rows {
+		if !reflect.DeepEqual(row.Tags, want) {
+			continue
+		}
+		if got := row.Data.(*view.LastValueData).Value; got != -60000 {
+			t.Errorf("Validity = %v, want: -60000", got)
+		}
+		return
+	}
+	t.Errorf("No %s row tagged %v in %v", CertificateValidityN, want, rows)
+}
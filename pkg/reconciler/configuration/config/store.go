@@ -21,6 +21,8 @@ import (
 	"time"
 
 	"knative.dev/pkg/configmap"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/gc"
 )
 
@@ -29,6 +31,8 @@ type cfgKey struct{}
 // +k8s:deepcopy-gen=false
 type Config struct {
 	RevisionGC *gc.Config
+	Deployment *deployment.Config
+	Features   *apisconfig.Features
 }
 
 func FromContext(ctx context.Context) *Config {
@@ -51,6 +55,8 @@ func (s *Store) ToContext(ctx context.Context) context.Context {
 func (s *Store) Load() *Config {
 	return &Config{
 		RevisionGC: s.UntypedLoad(gc.ConfigName).(*gc.Config).DeepCopy(),
+		Deployment: s.UntypedLoad(deployment.ConfigName).(*deployment.Config).DeepCopy(),
+		Features:   s.UntypedLoad(apisconfig.FeaturesConfigName).(*apisconfig.Features).DeepCopy(),
 	}
 }
 
@@ -60,7 +66,9 @@ func NewStore(logger configmap.Logger, minRevisionTimeout time.Duration) *Store
 			"configuration",
 			logger,
 			configmap.Constructors{
-				gc.ConfigName: gc.NewConfigFromConfigMapFunc(logger, minRevisionTimeout),
+				gc.ConfigName:                 gc.NewConfigFromConfigMapFunc(logger, minRevisionTimeout),
+				deployment.ConfigName:         deployment.NewConfigFromConfigMap,
+				apisconfig.FeaturesConfigName: apisconfig.NewFeaturesConfigFromConfigMap,
 			},
 		),
 	}
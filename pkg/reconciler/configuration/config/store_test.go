@@ -24,6 +24,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 
 	logtesting "knative.dev/pkg/logging/testing"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/gc"
 
 	. "knative.dev/pkg/configmap/testing"
@@ -34,8 +36,12 @@ func TestStoreLoadWithContext(t *testing.T) {
 	store := NewStore(logtesting.TestLogger(t), 10*time.Hour)
 
 	gcConfig := ConfigMapFromTestFile(t, "config-gc")
+	deploymentConfig := ConfigMapFromTestFile(t, deployment.ConfigName, deployment.QueueSidecarImageKey)
+	featuresConfig := ConfigMapFromTestFile(t, apisconfig.FeaturesConfigName)
 
 	store.OnConfigChanged(gcConfig)
+	store.OnConfigChanged(deploymentConfig)
+	store.OnConfigChanged(featuresConfig)
 
 	config := FromContext(store.ToContext(context.Background()))
 
@@ -45,4 +51,18 @@ func TestStoreLoadWithContext(t *testing.T) {
 			t.Errorf("Unexpected controller config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("deployment", func(t *testing.T) {
+		expected, _ := deployment.NewConfigFromConfigMap(deploymentConfig)
+		if diff := cmp.Diff(expected, config.Deployment); diff != "" {
+			t.Errorf("Unexpected deployment config (-want, +got): %v", diff)
+		}
+	})
+
+	t.Run("features", func(t *testing.T) {
+		expected, _ := apisconfig.NewFeaturesConfigFromConfigMap(featuresConfig)
+		if diff := cmp.Diff(expected, config.Features); diff != "" {
+			t.Errorf("Unexpected features config (-want, +got): %v", diff)
+		}
+	})
 }
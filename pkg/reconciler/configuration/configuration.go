@@ -28,10 +28,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
+	"knative.dev/pkg/tracker"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
@@ -39,6 +40,7 @@ import (
 	"knative.dev/serving/pkg/reconciler"
 	configns "knative.dev/serving/pkg/reconciler/configuration/config"
 	"knative.dev/serving/pkg/reconciler/configuration/resources"
+	"knative.dev/serving/pkg/reconciler/revision"
 )
 
 // Reconciler implements controller.Reconciler for Configuration resources.
@@ -48,8 +50,23 @@ type Reconciler struct {
 	// listers index properties about resources
 	configurationLister listers.ConfigurationLister
 	revisionLister      listers.RevisionLister
+	configMapLister     corev1listers.ConfigMapLister
+	secretLister        corev1listers.SecretLister
+
+	// revisionIndexer looks the Revisions up by their Configuration.
+	revisionIndexer cache.Indexer
+	// routeIndexer looks the Routes up by the Revisions they route to.
+	routeIndexer cache.Indexer
 
 	configStore reconciler.ConfigStore
+
+	// tracker tracks the ConfigMaps and Secrets of watched environments.
+	tracker tracker.Interface
+
+	// resolver resolves the image tags watched for new digests.
+	resolver revision.Resolver
+	// enqueueAfter enqueues a Configuration after the given delay.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -135,7 +152,10 @@ func (c *Reconciler) reconcile(ctx context.Context, config *v1alpha1.Configurati
 
 	// First, fetch the revision that should exist for the current generation.
 	lcr, err := c.latestCreatedRevision(config)
-	if errors.IsNotFound(err) {
+	if errors.IsNotFound(err) && reconciler.IsDryRun(config) {
+		c.RecordDryRun(ctx, config, "Would create a Revision for generation %d", config.Generation)
+		return nil
+	} else if errors.IsNotFound(err) {
 		lcr, err = c.createRevision(ctx, config)
 		if err != nil {
 			errMsg := fmt.Sprintf("Failed to create Revision for Configuration %q: %v", config.Name, err)
@@ -165,6 +185,7 @@ func (c *Reconciler) reconcile(ctx context.Context, config *v1alpha1.Configurati
 	// Second, set this to be the latest revision that we have created.
 	config.Status.SetLatestCreatedRevisionName(revName)
 	config.Status.ObservedGeneration = config.Generation
+	config.Status.PropagateResourcesExhausted(&lcr.Status)
 
 	// Last, determine whether we should set LatestReadyRevisionName to our
 	// LatestCreatedRevision based on its readiness.
@@ -203,6 +224,12 @@ func (c *Reconciler) reconcile(ctx context.Context, config *v1alpha1.Configurati
 		return err
 	}
 
+	if err := c.watchImageTag(ctx, config, lcr); err != nil {
+		return err
+	}
+	if err := c.watchEnvFrom(ctx, config, lcr); err != nil {
+		return err
+	}
 	return c.gcRevisions(ctx, config)
 }
 
@@ -244,21 +271,35 @@ func CheckNameAvailability(config *v1alpha1.Configuration, lister listers.Revisi
 	return rev, nil
 }
 
+// revisionsOf returns the Revisions labeled with the name of config.
+func (c *Reconciler) revisionsOf(config *v1alpha1.Configuration) ([]*v1alpha1.Revision, error) {
+	objs, err := c.revisionIndexer.ByIndex(reconciler.ConfigurationIndex,
+		reconciler.IndexKey(config.Namespace, config.Name))
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]*v1alpha1.Revision, 0, len(objs))
+	for _, obj := range objs {
+		revs = append(revs, obj.(*v1alpha1.Revision))
+	}
+	return revs, nil
+}
+
 func (c *Reconciler) latestCreatedRevision(config *v1alpha1.Configuration) (*v1alpha1.Revision, error) {
 	if rev, err := CheckNameAvailability(config, c.revisionLister); rev != nil || err != nil {
 		return rev, err
 	}
 
-	lister := c.revisionLister.Revisions(config.Namespace)
+	revs, err := c.revisionsOf(config)
+	if err != nil {
+		return nil, err
+	}
 	generationKey := serving.ConfigurationGenerationLabelKey
-
-	list, err := lister.List(labels.SelectorFromSet(map[string]string{
-		generationKey:                 resources.RevisionLabelValueForKey(generationKey, config),
-		serving.ConfigurationLabelKey: config.Name,
-	}))
-
-	if err == nil && len(list) > 0 {
-		return list[0], nil
+	generation := resources.RevisionLabelValueForKey(generationKey, config)
+	for _, rev := range revs {
+		if rev.Labels[generationKey] == generation {
+			return rev, nil
+		}
 	}
 
 	return nil, errors.NewNotFound(v1alpha1.Resource("revisions"), fmt.Sprintf("revision for %s", config.Name))
@@ -267,7 +308,14 @@ func (c *Reconciler) latestCreatedRevision(config *v1alpha1.Configuration) (*v1a
 func (c *Reconciler) createRevision(ctx context.Context, config *v1alpha1.Configuration) (*v1alpha1.Revision, error) {
 	logger := logging.FromContext(ctx)
 
-	rev := resources.MakeRevision(config)
+	rev := resources.MakeRevision(config, configns.FromContext(ctx).Deployment)
+	if watchesEnvFrom(config) {
+		hash, err := c.envFromHash(config.Namespace, envSources(rev.Spec.GetContainer()))
+		if err != nil {
+			return nil, err
+		}
+		rev.Annotations[serving.EnvFromHashAnnotationKey] = hash
+	}
 	created, err := c.ServingClientSet.ServingV1alpha1().Revisions(config.Namespace).Create(rev)
 	if err != nil {
 		return nil, err
@@ -297,8 +345,7 @@ func (c *Reconciler) gcRevisions(ctx context.Context, config *v1alpha1.Configura
 	cfg := configns.FromContext(ctx).RevisionGC
 	logger := logging.FromContext(ctx)
 
-	selector := labels.Set{serving.ConfigurationLabelKey: config.Name}.AsSelector()
-	revs, err := c.revisionLister.Revisions(config.Namespace).List(selector)
+	revs, err := c.revisionsOf(config)
 	if err != nil {
 		return err
 	}
@@ -315,12 +362,25 @@ func (c *Reconciler) gcRevisions(ctx context.Context, config *v1alpha1.Configura
 	})
 
 	for _, rev := range revs[gcSkipOffset:] {
-		if isRevisionStale(ctx, rev, config) {
-			err := c.ServingClientSet.ServingV1alpha1().Revisions(rev.Namespace).Delete(rev.Name, &metav1.DeleteOptions{})
-			if err != nil {
-				logger.Errorf("Failed to delete stale revision: %v", err)
-				return err
-			}
+		if !isRevisionStale(ctx, rev, config) {
+			continue
+		}
+		// The lastPinned annotation of a Revision lags behind the Routes
+		// routing to it, so check them before deleting it.
+		if routed, err := c.isRevisionRouted(rev); err != nil {
+			return err
+		} else if routed {
+			logger.Infof("Keeping stale revision %s, which is still routed to.", rev.Name)
+			continue
+		}
+		if reconciler.IsDryRun(config) {
+			c.RecordDryRun(ctx, config, "Would delete stale Revision %q", rev.Name)
+			continue
+		}
+		err := c.ServingClientSet.ServingV1alpha1().Revisions(rev.Namespace).Delete(rev.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			logger.Errorf("Failed to delete stale revision: %v", err)
+			return err
 		}
 	}
 	return nil
@@ -23,21 +23,29 @@ import (
 	"time"
 
 	// Inject the fake informers we need.
+	_ "knative.dev/pkg/injection/informers/kubeinformers/corev1/configmap/fake"
+	_ "knative.dev/pkg/injection/informers/kubeinformers/corev1/secret/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/configuration/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision/fake"
+	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/route/fake"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgotesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/ptr"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/gc"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/configuration/config"
@@ -94,6 +102,20 @@ func TestReconcile(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", "Created Revision %q", "no-revisions-yet-00001"),
 		},
 		Key: "foo/no-revisions-yet",
+	}, {
+		Name: "dry-run create revision",
+		Objects: []runtime.Object{
+			cfg("dry-run", "foo", 1234, dryRun),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("dry-run", "foo", 1234, dryRun, func(cfg *v1alpha1.Configuration) {
+				cfg.Status.InitializeConditions()
+			}),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would create a Revision for generation %d", 1234),
+		},
+		Key: "foo/dry-run",
 	}, {
 		Name: "create revision byo name",
 		Objects: []runtime.Object{
@@ -277,6 +299,26 @@ func TestReconcile(t *testing.T) {
 				"matching-revision"),
 		},
 		Key: "foo/matching-revision-failed",
+	}, {
+		Name: "reconcile revision matching generation (resources exhausted)",
+		Objects: []runtime.Object{
+			cfg("matching-revision-exhausted", "foo", 5555, WithLatestCreated("matching-revision"), WithObservedGen),
+			rev("matching-revision-exhausted", "foo", 5555,
+				WithCreationTimestamp(now), WithRevName("matching-revision"),
+				MarkResourcesExhausted("FailedCreate", "exceeded quota")),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("matching-revision-exhausted", "foo", 5555,
+				WithLatestCreated("matching-revision"), WithObservedGen,
+				// The quota rejection is surfaced on the Configuration.
+				WithConfigResourcesExhausted("FailedCreate", "exceeded quota"),
+				MarkLatestCreatedFailed("exceeded quota")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "LatestCreatedFailed", "Latest created revision %q has failed",
+				"matching-revision"),
+		},
+		Key: "foo/matching-revision-exhausted",
 	}, {
 		Name: "reconcile revision matching generation (ready: bad)",
 		Objects: []runtime.Object{
@@ -404,6 +446,8 @@ func TestReconcile(t *testing.T) {
 			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
+			revisionIndexer:     listers.GetIndexer(&v1alpha1.Revision{}),
+			routeIndexer:        routeIndexer(t, listers),
 			configStore: &testConfigStore{
 				config: ReconcilerTestConfig(),
 			},
@@ -452,6 +496,30 @@ func TestGCReconcile(t *testing.T) {
 			Name: "5554",
 		}},
 		Key: "foo/keep-two",
+	}, {
+		Name: "dry-run delete oldest",
+		Objects: []runtime.Object{
+			cfg("dry-run", "foo", 5556, dryRun,
+				WithLatestCreated("5556"),
+				WithLatestReady("5556"),
+				WithObservedGen),
+			rev("dry-run", "foo", 5554, MarkRevisionReady,
+				WithRevName("5554"),
+				WithCreationTimestamp(oldest),
+				WithLastPinned(tenMinutesAgo)),
+			rev("dry-run", "foo", 5555, MarkRevisionReady,
+				WithRevName("5555"),
+				WithCreationTimestamp(older),
+				WithLastPinned(tenMinutesAgo)),
+			rev("dry-run", "foo", 5556, MarkRevisionReady,
+				WithRevName("5556"),
+				WithCreationTimestamp(old),
+				WithLastPinned(tenMinutesAgo)),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would delete stale Revision %q", "5554"),
+		},
+		Key: "foo/dry-run",
 	}, {
 		Name: "keep oldest when no lastPinned",
 		Objects: []runtime.Object{
@@ -520,6 +588,70 @@ func TestGCReconcile(t *testing.T) {
 				WithLastPinned(tenMinutesAgo)),
 		},
 		Key: "foo/keep-two",
+	}, {
+		Name: "keep stale revisions still routed to",
+		Objects: []runtime.Object{
+			cfg("keep-routed", "foo", 5557,
+				WithLatestCreated("5557"),
+				WithLatestReady("5557"),
+				WithObservedGen),
+			rev("keep-routed", "foo", 5553, MarkRevisionReady,
+				WithRevName("5553"),
+				WithCreationTimestamp(oldest.Add(-time.Minute)),
+				WithLastPinned(tenMinutesAgo)),
+			// Tagged in the spec of a Route not reconciled yet.
+			rev("keep-routed", "foo", 5554, MarkRevisionReady,
+				WithRevName("5554"),
+				WithCreationTimestamp(oldest),
+				WithLastPinned(tenMinutesAgo)),
+			// Still routed to as the former latest ready revision.
+			rev("keep-routed", "foo", 5555, MarkRevisionReady,
+				WithRevName("5555"),
+				WithCreationTimestamp(older),
+				WithLastPinned(tenMinutesAgo)),
+			rev("keep-routed", "foo", 5556, MarkRevisionReady,
+				WithRevName("5556"),
+				WithCreationTimestamp(old),
+				WithLastPinned(tenMinutesAgo)),
+			rev("keep-routed", "foo", 5557, MarkRevisionReady,
+				WithRevName("5557"),
+				WithCreationTimestamp(tenMinutesAgo),
+				WithLastPinned(tenMinutesAgo)),
+			route("foo", "keep-routed",
+				WithSpecTraffic(v1alpha1.TrafficTarget{
+					TrafficTarget: v1beta1.TrafficTarget{
+						ConfigurationName: "keep-routed",
+						LatestRevision:    ptr.Bool(true),
+						Percent:           100,
+					},
+				}, v1alpha1.TrafficTarget{
+					TrafficTarget: v1beta1.TrafficTarget{
+						Tag:            "canary",
+						RevisionName:   "5554",
+						LatestRevision: ptr.Bool(false),
+					},
+				}),
+				WithStatusTraffic(v1alpha1.TrafficTarget{
+					TrafficTarget: v1beta1.TrafficTarget{
+						RevisionName:   "5555",
+						LatestRevision: ptr.Bool(true),
+						Percent:        100,
+					},
+				})),
+		},
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource: schema.GroupVersionResource{
+					Group:    "serving.knative.dev",
+					Version:  "v1alpha1",
+					Resource: "revisions",
+				},
+			},
+			Name: "5553",
+		}},
+		Key: "foo/keep-routed",
 	}, {
 		Name: "keep stale revision because of minimum generations",
 		Objects: []runtime.Object{
@@ -542,6 +674,8 @@ func TestGCReconcile(t *testing.T) {
 			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
+			revisionIndexer:     listers.GetIndexer(&v1alpha1.Revision{}),
+			routeIndexer:        routeIndexer(t, listers),
 			configStore: &testConfigStore{
 				config: &config.Config{
 					RevisionGC: &gc.Config{
@@ -549,12 +683,43 @@ func TestGCReconcile(t *testing.T) {
 						StaleRevisionTimeout:            5 * time.Minute,
 						StaleRevisionMinimumGenerations: 2,
 					},
+					Deployment: &deployment.Config{},
+					Features:   &apisconfig.Features{},
 				},
 			},
 		}
 	}))
 }
 
+// routeIndexer returns an indexer of the Routes of listers, with the index
+// of the Routes by the Revisions they route to.
+func routeIndexer(t *testing.T, listers *Listers) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		reconciler.RoutedRevisionIndex: reconciler.IndexByRoutedRevisions,
+	})
+	routes, err := listers.GetRouteLister().List(labels.Everything())
+	if err != nil {
+		t.Fatalf("Failed to list the Routes: %v", err)
+	}
+	for _, r := range routes {
+		indexer.Add(r)
+	}
+	return indexer
+}
+
+func route(namespace, name string, ro ...RouteOption) *v1alpha1.Route {
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	for _, opt := range ro {
+		opt(r)
+	}
+	return r
+}
+
 func cfg(name, namespace string, generation int64, co ...ConfigOption) *v1alpha1.Configuration {
 	c := &v1alpha1.Configuration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -575,8 +740,16 @@ func cfg(name, namespace string, generation int64, co ...ConfigOption) *v1alpha1
 	return c
 }
 
+// dryRun has the Configuration preview the changes to its Revisions.
+func dryRun(cfg *v1alpha1.Configuration) {
+	if cfg.Annotations == nil {
+		cfg.Annotations = make(map[string]string, 1)
+	}
+	cfg.Annotations[serving.DryRunAnnotationKey] = "true"
+}
+
 func rev(name, namespace string, generation int64, ro ...RevisionOption) *v1alpha1.Revision {
-	r := resources.MakeRevision(cfg(name, namespace, generation))
+	r := resources.MakeRevision(cfg(name, namespace, generation), &deployment.Config{})
 	r.SetDefaults(v1beta1.WithUpgradeViaDefaulting(context.Background()))
 	for _, opt := range ro {
 		opt(r)
@@ -600,6 +773,8 @@ func ReconcilerTestConfig() *config.Config {
 			StaleRevisionCreateDelay: 5 * time.Minute,
 			StaleRevisionTimeout:     5 * time.Minute,
 		},
+		Deployment: &deployment.Config{},
+		Features:   &apisconfig.Features{},
 	}
 }
 
@@ -701,6 +876,8 @@ func TestIsRevisionStale(t *testing.T) {
 				StaleRevisionTimeout:            5 * time.Minute,
 				StaleRevisionMinimumGenerations: 2,
 			},
+			Deployment: &deployment.Config{},
+			Features:   &apisconfig.Features{},
 		},
 	}
 	ctx := cfgStore.ToContext(context.Background())
@@ -21,13 +21,20 @@ import (
 
 	configurationinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/configuration"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision"
+	routeinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/route"
 
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
+	configmapinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/configmap"
+	secretinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/secret"
+	"knative.dev/pkg/tracker"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/reconciler"
 	configns "knative.dev/serving/pkg/reconciler/configuration/config"
+	"knative.dev/serving/pkg/reconciler/revision"
 )
 
 const controllerAgentName = "configuration-controller"
@@ -40,13 +47,28 @@ func NewController(
 
 	configurationInformer := configurationinformer.Get(ctx)
 	revisionInformer := revisioninformer.Get(ctx)
+	routeInformer := routeinformer.Get(ctx)
+	configMapInformer := configmapinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
 
 	c := &Reconciler{
 		Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
 		configurationLister: configurationInformer.Lister(),
 		revisionLister:      revisionInformer.Lister(),
+		configMapLister:     configMapInformer.Lister(),
+		secretLister:        secretInformer.Lister(),
+		revisionIndexer:     revisionInformer.Informer().GetIndexer(),
+		routeIndexer:        routeInformer.Informer().GetIndexer(),
+		resolver:            revision.NewResolver(ctx),
+	}
+	if err := reconciler.AddIndexers(revisionInformer.Informer(), reconciler.ConfigurationIndex); err != nil {
+		c.Logger.Fatalw("Failed to index the Revisions", zap.Error(err))
+	}
+	if err := reconciler.AddIndexers(routeInformer.Informer(), reconciler.RoutedRevisionIndex); err != nil {
+		c.Logger.Fatalw("Failed to index the Routes", zap.Error(err))
 	}
 	impl := controller.NewImpl(c, c.Logger, "Configurations")
+	c.enqueueAfter = impl.EnqueueAfter
 
 	c.Logger.Info("Setting up event handlers")
 	configurationInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
@@ -56,6 +78,16 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	// Configurations watching their environment track the ConfigMaps and
+	// Secrets it is read from.
+	c.tracker = tracker.New(impl.EnqueueKey, controller.GetTrackerLease(ctx))
+	configMapInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(c.tracker.OnChanged, corev1.SchemeGroupVersion.WithKind("ConfigMap")),
+	))
+	secretInformer.Informer().AddEventHandler(controller.HandleAll(
+		controller.EnsureTypeMeta(c.tracker.OnChanged, corev1.SchemeGroupVersion.WithKind("Secret")),
+	))
+
 	c.Logger.Info("Setting up ConfigMap receivers")
 	configStore := configns.NewStore(c.Logger.Named("config-store"), controller.GetResyncPeriod(ctx))
 	configStore.WatchConfigs(c.ConfigMapWatcher)
@@ -0,0 +1,257 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+)
+
+// envSource is a ConfigMap or Secret the environment of a container is read
+// from, and the keys read from it. All of its keys are read when keys is nil.
+type envSource struct {
+	kind string
+	name string
+	keys sets.String
+}
+
+// String implements fmt.Stringer.
+func (s *envSource) String() string {
+	return fmt.Sprintf("%s %q", s.kind, s.name)
+}
+
+// envSources returns the ConfigMaps and Secrets the environment of container
+// is read from, sorted by kind and name. Mounted volumes aren't included,
+// the kubelet updates those in place.
+func envSources(container *corev1.Container) []*envSource {
+	byRef := make(map[string]*envSource)
+	add := func(kind, name, key string) {
+		ref := kind + "/" + name
+		src, ok := byRef[ref]
+		if !ok {
+			src = &envSource{kind: kind, name: name, keys: sets.NewString()}
+			byRef[ref] = src
+		}
+		switch {
+		case key == "":
+			src.keys = nil
+		case src.keys != nil:
+			src.keys.Insert(key)
+		}
+	}
+	for _, ef := range container.EnvFrom {
+		switch {
+		case ef.ConfigMapRef != nil:
+			add("ConfigMap", ef.ConfigMapRef.Name, "")
+		case ef.SecretRef != nil:
+			add("Secret", ef.SecretRef.Name, "")
+		}
+	}
+	for _, ev := range container.Env {
+		switch {
+		case ev.ValueFrom == nil:
+		case ev.ValueFrom.ConfigMapKeyRef != nil:
+			add("ConfigMap", ev.ValueFrom.ConfigMapKeyRef.Name, ev.ValueFrom.ConfigMapKeyRef.Key)
+		case ev.ValueFrom.SecretKeyRef != nil:
+			add("Secret", ev.ValueFrom.SecretKeyRef.Name, ev.ValueFrom.SecretKeyRef.Key)
+		}
+	}
+
+	srcs := make([]*envSource, 0, len(byRef))
+	for _, src := range byRef {
+		srcs = append(srcs, src)
+	}
+	sort.Slice(srcs, func(i, j int) bool {
+		return srcs[i].String() < srcs[j].String()
+	})
+	return srcs
+}
+
+// envFromHash hashes the data read from srcs in namespace. Missing
+// ConfigMaps and Secrets are hashed too, so that creating them changes the
+// hash.
+func (c *Reconciler) envFromHash(namespace string, srcs []*envSource) (string, error) {
+	h := sha256.New()
+	for _, src := range srcs {
+		data, err := c.envSourceData(namespace, src)
+		if errors.IsNotFound(err) {
+			fmt.Fprintf(h, "%s missing\n", src)
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n", src)
+		keys := src.keys
+		if keys == nil {
+			keys = sets.StringKeySet(data)
+		}
+		for _, key := range keys.List() {
+			if v, ok := data[key]; ok {
+				fmt.Fprintf(h, "%q=%q\n", key, v)
+			}
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// envSourceData returns the data of the ConfigMap or Secret src.
+func (c *Reconciler) envSourceData(namespace string, src *envSource) (map[string][]byte, error) {
+	if src.kind == "Secret" {
+		secret, err := c.secretLister.Secrets(namespace).Get(src.name)
+		if err != nil {
+			return nil, err
+		}
+		return secret.Data, nil
+	}
+	cm, err := c.configMapLister.ConfigMaps(namespace).Get(src.name)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range cm.BinaryData {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// watchesEnvFrom returns whether config asks for its environment to be
+// watched.
+func watchesEnvFrom(config *v1alpha1.Configuration) bool {
+	_, ok := config.Annotations[serving.EnvFromUpdatesAnnotationKey]
+	return ok
+}
+
+// watchEnvFrom tracks the ConfigMaps and Secrets the environment of config
+// is read from. When they changed since lcr, its latest created Revision, was
+// created, it either stamps out a new Revision or marks config as running a
+// stale environment, as config asks for.
+func (c *Reconciler) watchEnvFrom(ctx context.Context, config *v1alpha1.Configuration, lcr *v1alpha1.Revision) error {
+	logger := logging.FromContext(ctx)
+	if !watchesEnvFrom(config) {
+		return nil
+	}
+
+	template := config.Spec.GetTemplate()
+	srcs := envSources(template.Spec.GetContainer())
+	for _, src := range srcs {
+		ref := corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       src.kind,
+			Namespace:  config.Namespace,
+			Name:       src.name,
+		}
+		if err := c.tracker.Track(ref, config); err != nil {
+			return err
+		}
+	}
+	hash, err := c.envFromHash(config.Namespace, srcs)
+	if err != nil {
+		return err
+	}
+
+	created, ok := lcr.Annotations[serving.EnvFromHashAnnotationKey]
+	switch {
+	case !ok:
+		// The Revision predates the watch, take the environment it runs as
+		// the current one.
+		return c.stampRevisionEnvFromHash(lcr, hash)
+	case created == hash:
+		config.Status.MarkEnvFromUpToDate()
+		return nil
+	}
+
+	names := make([]string, len(srcs))
+	for i, src := range srcs {
+		names[i] = src.String()
+	}
+	config.Status.MarkEnvFromStale(strings.Join(names, ", "))
+	// Revisions named by the user can't be stamped out anew.
+	if config.Annotations[serving.EnvFromUpdatesAnnotationKey] != serving.EnvFromUpdatesRollout ||
+		template.Name != "" || template.Annotations[serving.EnvFromHashAnnotationKey] == hash {
+		return nil
+	}
+
+	logger.Infof("Environment of configuration %q changed", config.Name)
+	if reconciler.IsDryRun(config) {
+		c.RecordDryRun(ctx, config, "Would create a new Revision for the changed environment")
+		return nil
+	}
+	if err := c.stampEnvFromHash(config, hash); err != nil {
+		return err
+	}
+	c.Recorder.Event(config, corev1.EventTypeNormal, "EnvFromUpdated",
+		"Environment changed, creating a new Revision")
+	return nil
+}
+
+// stampEnvFromHash records hash on the template of config. Changing the
+// template bumps the generation of config, for which a new Revision is
+// created.
+func (c *Reconciler) stampEnvFromHash(config *v1alpha1.Configuration, hash string) error {
+	template := "template"
+	if config.Spec.DeprecatedRevisionTemplate != nil {
+		template = "revisionTemplate"
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			template: map[string]interface{}{
+				"metadata": envFromHashMetadata(hash),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.ServingClientSet.ServingV1alpha1().Configurations(config.Namespace).Patch(config.Name, types.MergePatchType, patch)
+	return err
+}
+
+// stampRevisionEnvFromHash records hash on rev.
+func (c *Reconciler) stampRevisionEnvFromHash(rev *v1alpha1.Revision, hash string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": envFromHashMetadata(hash),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.ServingClientSet.ServingV1alpha1().Revisions(rev.Namespace).Patch(rev.Name, types.MergePatchType, patch)
+	return err
+}
+
+func envFromHashMetadata(hash string) map[string]interface{} {
+	return map[string]interface{}{
+		"annotations": map[string]string{
+			serving.EnvFromHashAnnotationKey: hash,
+		},
+	}
+}
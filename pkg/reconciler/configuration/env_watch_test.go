@@ -0,0 +1,364 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/tracker"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/deployment"
+	"knative.dev/serving/pkg/reconciler"
+	"knative.dev/serving/pkg/reconciler/configuration/resources"
+
+	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/serving/pkg/testing/v1alpha1"
+)
+
+func TestEnvSources(t *testing.T) {
+	container := &corev1.Container{
+		EnvFrom: []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "creds"},
+			},
+		}, {
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+			},
+		}},
+		Env: []corev1.EnvVar{{
+			Name:  "PLAIN",
+			Value: "value",
+		}, {
+			Name: "FROM_ENV",
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+					Key:                  "a",
+				},
+			},
+		}, {
+			Name: "FROM_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "token"},
+					Key:                  "b",
+				},
+			},
+		}},
+	}
+
+	var got []string
+	for _, src := range envSources(container) {
+		got = append(got, fmt.Sprintf("%s %v", src, src.keys.List()))
+	}
+	want := []string{`ConfigMap "env" []`, `Secret "creds" []`, `Secret "token" [b]`}
+	if !cmp.Equal(got, want) {
+		t.Errorf("envSources (-want, +got) = %s", cmp.Diff(want, got))
+	}
+}
+
+func TestEnvFromHash(t *testing.T) {
+	base := envHash(t, envConfigMap("a", "1", "b", "2"), envSecret("other", "x"))
+	for _, tc := range []struct {
+		name    string
+		objs    []runtime.Object
+		changed bool
+	}{{
+		name: "same data",
+		objs: []runtime.Object{envConfigMap("b", "2", "a", "1"), envSecret("other", "x")},
+	}, {
+		name:    "changed value",
+		objs:    []runtime.Object{envConfigMap("a", "1", "b", "3"), envSecret("other", "x")},
+		changed: true,
+	}, {
+		name:    "added key",
+		objs:    []runtime.Object{envConfigMap("a", "1", "b", "2", "c", "3"), envSecret("other", "x")},
+		changed: true,
+	}, {
+		name:    "missing",
+		changed: true,
+	}, {
+		name: "unread secret key",
+		objs: []runtime.Object{envConfigMap("a", "1", "b", "2"), envSecret("other", "y")},
+	}, {
+		name:    "read secret key",
+		objs:    []runtime.Object{envConfigMap("a", "1", "b", "2"), envSecret("token", "x")},
+		changed: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := envHash(t, tc.objs...) != base; got != tc.changed {
+				t.Errorf("Hash changed = %v, want: %v", got, tc.changed)
+			}
+		})
+	}
+}
+
+func TestWatchEnvFrom(t *testing.T) {
+	now := time.Now()
+	current := envHash(t, envConfigMap("a", "2"))
+
+	table := TableTest{{
+		Name: "create revision",
+		Objects: []runtime.Object{
+			cfg("create", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout)),
+			envConfigMap("a", "2"),
+		},
+		WantCreates: []runtime.Object{
+			envRev("create", "foo", 1, current, serving.EnvFromUpdatesRollout),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("create", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout),
+				WithLatestCreated("create-00001"), WithObservedGen, withEnvFromUpToDate),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created Revision %q", "create-00001"),
+		},
+		Key: "foo/create",
+	}, {
+		Name: "environment changed, rollout",
+		Objects: []runtime.Object{
+			cfg("rollout", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), WithObservedGen,
+				WithLatestCreated("rollout-00001"), WithLatestReady("rollout-00001"), withEnvFromUpToDate),
+			envRev("rollout", "foo", 1, "stale", serving.EnvFromUpdatesRollout,
+				WithCreationTimestamp(now), MarkRevisionReady, WithRevName("rollout-00001")),
+			envConfigMap("a", "2"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("rollout", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), WithObservedGen,
+				WithLatestCreated("rollout-00001"), WithLatestReady("rollout-00001"), withEnvFromStale),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchEnvFromHash("foo", "rollout", current),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "EnvFromUpdated", "Environment changed, creating a new Revision"),
+		},
+		Key: "foo/rollout",
+	}, {
+		Name: "environment changed, rollout dry-run",
+		Objects: []runtime.Object{
+			cfg("dry-run", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), dryRun, WithObservedGen,
+				WithLatestCreated("dry-run-00001"), WithLatestReady("dry-run-00001"), withEnvFromStale),
+			envRev("dry-run", "foo", 1, "stale", serving.EnvFromUpdatesRollout,
+				WithCreationTimestamp(now), MarkRevisionReady, WithRevName("dry-run-00001")),
+			envConfigMap("a", "2"),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would create a new Revision for the changed environment"),
+		},
+		Key: "foo/dry-run",
+	}, {
+		Name: "environment changed, already stamped",
+		Objects: []runtime.Object{
+			cfg("stamped", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), withTemplateEnvFromHash(current),
+				WithObservedGen, WithLatestCreated("stamped-00001"), WithLatestReady("stamped-00001"), withEnvFromStale),
+			envRev("stamped", "foo", 1, "stale", serving.EnvFromUpdatesRollout,
+				WithCreationTimestamp(now), MarkRevisionReady, WithRevName("stamped-00001")),
+			envConfigMap("a", "2"),
+		},
+		Key: "foo/stamped",
+	}, {
+		Name: "environment changed, annotate",
+		Objects: []runtime.Object{
+			cfg("annotate", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesAnnotate), WithObservedGen,
+				WithLatestCreated("annotate-00001"), WithLatestReady("annotate-00001"), withEnvFromUpToDate),
+			envRev("annotate", "foo", 1, "stale", serving.EnvFromUpdatesAnnotate,
+				WithCreationTimestamp(now), MarkRevisionReady, WithRevName("annotate-00001")),
+			envConfigMap("a", "2"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("annotate", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesAnnotate), WithObservedGen,
+				WithLatestCreated("annotate-00001"), WithLatestReady("annotate-00001"), withEnvFromStale),
+		}},
+		Key: "foo/annotate",
+	}, {
+		Name: "environment unchanged",
+		Objects: []runtime.Object{
+			cfg("unchanged", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), WithObservedGen,
+				WithLatestCreated("unchanged-00001"), WithLatestReady("unchanged-00001"), withEnvFromStale),
+			envRev("unchanged", "foo", 1, current, serving.EnvFromUpdatesRollout,
+				WithCreationTimestamp(now), MarkRevisionReady, WithRevName("unchanged-00001")),
+			envConfigMap("a", "2"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: cfg("unchanged", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), WithObservedGen,
+				WithLatestCreated("unchanged-00001"), WithLatestReady("unchanged-00001"), withEnvFromUpToDate),
+		}},
+		Key: "foo/unchanged",
+	}, {
+		Name: "revision predates the watch",
+		Objects: []runtime.Object{
+			cfg("predates", "foo", 1, watchEnvFrom(serving.EnvFromUpdatesRollout), WithObservedGen,
+				WithLatestCreated("predates-00001"), WithLatestReady("predates-00001")),
+			rev("predates", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady, WithRevName("predates-00001")),
+			envConfigMap("a", "2"),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchRevisionEnvFromHash("foo", "predates-00001", current),
+		},
+		Key: "foo/predates",
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			configurationLister: listers.GetConfigurationLister(),
+			revisionLister:      listers.GetRevisionLister(),
+			revisionIndexer:     listers.GetIndexer(&v1alpha1.Revision{}),
+			configMapLister:     listers.GetConfigMapLister(),
+			secretLister:        listers.GetSecretLister(),
+			configStore:         &testConfigStore{config: ReconcilerTestConfig()},
+			tracker:             tracker.New(func(string) {}, time.Minute),
+		}
+	}))
+}
+
+// envContainer reads its environment from the ConfigMap "env" and the key
+// "token" of the Secret "token".
+func envContainer(container *corev1.Container) {
+	container.EnvFrom = []corev1.EnvFromSource{{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+		},
+	}}
+	container.Env = []corev1.EnvVar{{
+		Name: "TOKEN",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "token"},
+				Key:                  "token",
+			},
+		},
+	}}
+}
+
+func watchEnvFrom(mode string) ConfigOption {
+	return func(cfg *v1alpha1.Configuration) {
+		if cfg.Annotations == nil {
+			cfg.Annotations = make(map[string]string, 1)
+		}
+		cfg.Annotations[serving.EnvFromUpdatesAnnotationKey] = mode
+		envContainer(cfg.Spec.GetTemplate().Spec.GetContainer())
+	}
+}
+
+func withTemplateEnvFromHash(hash string) ConfigOption {
+	return func(cfg *v1alpha1.Configuration) {
+		cfg.Spec.GetTemplate().Annotations = map[string]string{
+			serving.EnvFromHashAnnotationKey: hash,
+		}
+	}
+}
+
+func withEnvFromUpToDate(cfg *v1alpha1.Configuration) {
+	cfg.Status.MarkEnvFromUpToDate()
+}
+
+func withEnvFromStale(cfg *v1alpha1.Configuration) {
+	cfg.Status.MarkEnvFromStale(`ConfigMap "env", Secret "token"`)
+}
+
+// envRev is a Revision of a Configuration watching its environment in mode,
+// created with the environment hashing to hash.
+func envRev(name, namespace string, generation int64, hash, mode string, ro ...RevisionOption) *v1alpha1.Revision {
+	r := resources.MakeRevision(cfg(name, namespace, generation, watchEnvFrom(mode)), &deployment.Config{})
+	r.Annotations[serving.EnvFromHashAnnotationKey] = hash
+	r.SetDefaults(v1beta1.WithUpgradeViaDefaulting(context.Background()))
+	for _, opt := range ro {
+		opt(r)
+	}
+	return r
+}
+
+func envConfigMap(kvs ...string) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "env",
+		},
+		Data: make(map[string]string, len(kvs)/2),
+	}
+	for i := 0; i < len(kvs); i += 2 {
+		cm.Data[kvs[i]] = kvs[i+1]
+	}
+	return cm
+}
+
+func envSecret(key, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "token",
+		},
+		Data: map[string][]byte{
+			key: []byte(value),
+		},
+	}
+}
+
+// envHash hashes the environment of envContainer given objs.
+func envHash(t *testing.T, objs ...runtime.Object) string {
+	t.Helper()
+	listers := NewListers(objs)
+	c := &Reconciler{
+		configMapLister: listers.GetConfigMapLister(),
+		secretLister:    listers.GetSecretLister(),
+	}
+	container := &corev1.Container{}
+	envContainer(container)
+	hash, err := c.envFromHash("foo", envSources(container))
+	if err != nil {
+		t.Fatalf("envFromHash() = %v", err)
+	}
+	return hash
+}
+
+func patchEnvFromHash(namespace, name, hash string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		serving.EnvFromHashAnnotationKey, hash)
+	action.Patch = []byte(patch)
+	return action
+}
+
+func patchRevisionEnvFromHash(namespace, name, hash string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`,
+		serving.EnvFromHashAnnotationKey, hash)
+	action.Patch = []byte(patch)
+	return action
+}
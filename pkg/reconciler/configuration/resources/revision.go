@@ -19,13 +19,15 @@ package resources
 import (
 	"fmt"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
 )
 
 // MakeRevision creates a revision object from configuration.
-func MakeRevision(config *v1alpha1.Configuration) *v1alpha1.Revision {
+func MakeRevision(config *v1alpha1.Configuration, deploymentConfig *deployment.Config) *v1alpha1.Revision {
 	// Start from the ObjectMeta/Spec inlined in the Configuration resources.
 	rev := &v1alpha1.Revision{
 		ObjectMeta: config.Spec.GetTemplate().ObjectMeta,
@@ -40,6 +42,7 @@ func MakeRevision(config *v1alpha1.Configuration) *v1alpha1.Revision {
 
 	UpdateRevisionLabels(rev, config)
 	UpdateRevisionAnnotations(rev, config)
+	propagateMetadata(rev, config, deploymentConfig)
 
 	// Populate OwnerReferences so that deletes cascade.
 	rev.OwnerReferences = append(rev.OwnerReferences, *kmeta.NewControllerRef(config))
@@ -75,6 +78,31 @@ func UpdateRevisionAnnotations(rev *v1alpha1.Revision, config *v1alpha1.Configur
 	}
 }
 
+// sloAnnotations are the annotations declaring the SLO of a Service, which
+// are always copied from its Configuration onto its revisions.
+var sloAnnotations = sets.NewString(
+	serving.SLOAvailabilityAnnotationKey,
+	serving.SLOLatencyAnnotationKey,
+	serving.SLOLatencyTargetAnnotationKey,
+)
+
+// propagateMetadata copies the labels and annotations of the Configuration
+// that are on the allow-lists of the deployment config, and its SLO
+// annotations, onto the revision. Labels and annotations set on the
+// revision template take precedence.
+func propagateMetadata(rev *v1alpha1.Revision, config *v1alpha1.Configuration, deploymentConfig *deployment.Config) {
+	for key, value := range config.Labels {
+		if _, ok := rev.Labels[key]; !ok && deploymentConfig.PropagatedLabels.Has(key) {
+			rev.Labels[key] = value
+		}
+	}
+	for key, value := range config.Annotations {
+		if _, ok := rev.Annotations[key]; !ok && (deploymentConfig.PropagatedAnnotations.Has(key) || sloAnnotations.Has(key)) {
+			rev.Annotations[key] = value
+		}
+	}
+}
+
 // RevisionLabelValueForKey returns the label value for the given key.
 func RevisionLabelValueForKey(key string, config *v1alpha1.Configuration) string {
 	switch key {
@@ -21,17 +21,20 @@ import (
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/ptr"
 
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
 )
 
 func TestMakeRevisions(t *testing.T) {
 	tests := []struct {
-		name          string
-		configuration *v1alpha1.Configuration
-		want          *v1alpha1.Revision
+		name             string
+		configuration    *v1alpha1.Configuration
+		deploymentConfig *deployment.Config
+		want             *v1alpha1.Revision
 	}{{
 		name: "no build",
 		configuration: &v1alpha1.Configuration{
@@ -175,6 +178,80 @@ func TestMakeRevisions(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		name: "with propagated labels and annotations",
+		configuration: &v1alpha1.Configuration{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  "with",
+				Name:       "propagated",
+				Generation: 100,
+				Labels: map[string]string{
+					"team":        "serving",
+					"cost-center": "1234",
+					"internal":    "yes",
+				},
+				Annotations: map[string]string{
+					"sidecar.istio.io/inject":            "false",
+					"owner":                              "me",
+					"internal":                           "yes",
+					serving.SLOAvailabilityAnnotationKey: "99.9",
+					serving.SLOLatencyAnnotationKey:      "300ms",
+				},
+			},
+			Spec: v1alpha1.ConfigurationSpec{
+				DeprecatedRevisionTemplate: &v1alpha1.RevisionTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{
+							// The template takes precedence.
+							"cost-center": "5678",
+						},
+						Annotations: map[string]string{
+							serving.SLOLatencyAnnotationKey: "1s",
+						},
+					},
+					Spec: v1alpha1.RevisionSpec{
+						DeprecatedContainer: &corev1.Container{
+							Image: "busybox",
+						},
+					},
+				},
+			},
+		},
+		deploymentConfig: &deployment.Config{
+			PropagatedLabels:      sets.NewString("team", "cost-center"),
+			PropagatedAnnotations: sets.NewString("sidecar.istio.io/inject", "owner"),
+		},
+		want: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    "with",
+				GenerateName: "propagated-",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+					Kind:               "Configuration",
+					Name:               "propagated",
+					Controller:         ptr.Bool(true),
+					BlockOwnerDeletion: ptr.Bool(true),
+				}},
+				Labels: map[string]string{
+					serving.ConfigurationLabelKey:           "propagated",
+					serving.ConfigurationGenerationLabelKey: "100",
+					serving.ServiceLabelKey:                 "",
+					"team":                                  "serving",
+					"cost-center":                           "5678",
+				},
+				Annotations: map[string]string{
+					"sidecar.istio.io/inject":            "false",
+					"owner":                              "me",
+					serving.SLOAvailabilityAnnotationKey: "99.9",
+					serving.SLOLatencyAnnotationKey:      "1s",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				DeprecatedContainer: &corev1.Container{
+					Image: "busybox",
+				},
+			},
+		},
 	}, {
 		name: "with creator annotation from config",
 		configuration: &v1alpha1.Configuration{
@@ -282,8 +359,11 @@ func TestMakeRevisions(t *testing.T) {
 	}}
 
 	for _, test := range tests {
+		if test.deploymentConfig == nil {
+			test.deploymentConfig = &deployment.Config{}
+		}
 		t.Run(test.name, func(t *testing.T) {
-			got := MakeRevision(test.configuration)
+			got := MakeRevision(test.configuration, test.deploymentConfig)
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("MakeRevision (-want, +got) = %v", diff)
 			}
@@ -293,7 +373,7 @@ func TestMakeRevisions(t *testing.T) {
 			// Test the Template variant.
 			test.configuration.Spec.Template = test.configuration.Spec.DeprecatedRevisionTemplate
 			test.configuration.Spec.DeprecatedRevisionTemplate = nil
-			got := MakeRevision(test.configuration)
+			got := MakeRevision(test.configuration, test.deploymentConfig)
 			if diff := cmp.Diff(test.want, got); diff != "" {
 				t.Errorf("MakeRevision (-want, +got) = %v", diff)
 			}
@@ -0,0 +1,32 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+)
+
+// isRevisionRouted returns whether any Route routes, or is asked to route,
+// to rev.
+func (c *Reconciler) isRevisionRouted(rev *v1alpha1.Revision) (bool, error) {
+	routes, err := c.routeIndexer.ByIndex(reconciler.RoutedRevisionIndex, reconciler.IndexKey(rev.Namespace, rev.Name))
+	if err != nil {
+		return false, err
+	}
+	return len(routes) > 0, nil
+}
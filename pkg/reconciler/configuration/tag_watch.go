@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+	configns "knative.dev/serving/pkg/reconciler/configuration/config"
+)
+
+// tagWatchPeriod is how often the image tag of a Configuration is resolved.
+// Registries don't notify of pushes.
+const tagWatchPeriod = 5 * time.Minute
+
+// watchImageTag stamps out a new Revision of config when the tag of its
+// image points at a different digest than the one lcr, its latest created
+// Revision, runs. It checks the tag again after tagWatchPeriod.
+func (c *Reconciler) watchImageTag(ctx context.Context, config *v1alpha1.Configuration, lcr *v1alpha1.Revision) error {
+	logger := logging.FromContext(ctx)
+	cfgs := configns.FromContext(ctx)
+	if cfgs.Features.TagWatching != apisconfig.Enabled || config.Annotations[serving.WatchImageTagAnnotationKey] != "true" {
+		return nil
+	}
+	c.enqueueAfter(config, tagWatchPeriod)
+
+	// The digest isn't resolved yet, or the image isn't referenced by a tag
+	// from a registry tags are resolved for.
+	if lcr.Status.ImageDigest == "" || lcr.Status.ImageDigest == lcr.Spec.GetContainer().Image {
+		return nil
+	}
+
+	template := config.Spec.GetTemplate()
+	opt := k8schain.Options{
+		Namespace:          config.Namespace,
+		ServiceAccountName: template.Spec.ServiceAccountName,
+	}
+	digest, err := c.resolver.Resolve(template.Spec.GetContainer().Image, opt,
+		cfgs.Deployment.RegistriesSkippingTagResolving)
+	if err != nil {
+		// The Revisions already running aren't affected.
+		logger.Warnw("Failed to resolve the watched image tag", zap.Error(err))
+		return nil
+	}
+	if digest == "" || digest == lcr.Status.ImageDigest || digest == template.Annotations[serving.ImageDigestAnnotationKey] {
+		return nil
+	}
+
+	logger.Infof("Image tag of configuration %q now points at %s", config.Name, digest)
+	if reconciler.IsDryRun(config) {
+		c.RecordDryRun(ctx, config, "Would create a new Revision for image %s", digest)
+		return nil
+	}
+	if err := c.stampImageDigest(config, digest); err != nil {
+		return err
+	}
+	c.Recorder.Eventf(config, corev1.EventTypeNormal, "ImageTagUpdated",
+		"Image tag points at %s, creating a new Revision", digest)
+	return nil
+}
+
+// stampImageDigest records digest on the template of config. Changing the
+// template bumps the generation of config, for which a new Revision is
+// created.
+func (c *Reconciler) stampImageDigest(config *v1alpha1.Configuration, digest string) error {
+	template := "template"
+	if config.Spec.DeprecatedRevisionTemplate != nil {
+		template = "revisionTemplate"
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			template: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						serving.ImageDigestAnnotationKey: digest,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.ServingClientSet.ServingV1alpha1().Configurations(config.Namespace).Patch(config.Name, types.MergePatchType, patch)
+	return err
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configuration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgotesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	apisconfig "knative.dev/serving/pkg/apis/config"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+
+	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/serving/pkg/testing/v1alpha1"
+)
+
+const (
+	oldDigest = "busybox@sha256:deadbeef"
+	newDigest = "busybox@sha256:cafebabe"
+)
+
+type fakeResolver struct {
+	digest string
+	err    error
+}
+
+func (r *fakeResolver) Resolve(_ string, _ k8schain.Options, _ sets.String) (string, error) {
+	return r.digest, r.err
+}
+
+func TestWatchImageTag(t *testing.T) {
+	now := time.Now()
+
+	table := TableTest{{
+		Name: "tag moved",
+		Objects: []runtime.Object{
+			cfg("tag-moved", "foo", 1, WithObservedGen, watchImageTag,
+				WithLatestCreated("tag-moved-00001"), WithLatestReady("tag-moved-00001")),
+			rev("tag-moved", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("tag-moved-00001"), withImageDigest(oldDigest)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchImageDigest("foo", "tag-moved", newDigest),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ImageTagUpdated", "Image tag points at %s, creating a new Revision", newDigest),
+		},
+		Key: "foo/tag-moved",
+	}, {
+		Name: "tag moved, dry-run",
+		Objects: []runtime.Object{
+			cfg("dry-run", "foo", 1, WithObservedGen, watchImageTag, dryRun,
+				WithLatestCreated("dry-run-00001"), WithLatestReady("dry-run-00001")),
+			rev("dry-run", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("dry-run-00001"), withImageDigest(oldDigest)),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would create a new Revision for image %s", newDigest),
+		},
+		Key: "foo/dry-run",
+	}, {
+		Name: "tag unchanged",
+		Objects: []runtime.Object{
+			cfg("tag-unchanged", "foo", 1, WithObservedGen, watchImageTag,
+				WithLatestCreated("tag-unchanged-00001"), WithLatestReady("tag-unchanged-00001")),
+			rev("tag-unchanged", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("tag-unchanged-00001"), withImageDigest(newDigest)),
+		},
+		Key: "foo/tag-unchanged",
+	}, {
+		Name: "digest already stamped",
+		Objects: []runtime.Object{
+			cfg("already-stamped", "foo", 1, WithObservedGen, watchImageTag,
+				func(cfg *v1alpha1.Configuration) {
+					cfg.Spec.GetTemplate().Annotations = map[string]string{
+						serving.ImageDigestAnnotationKey: newDigest,
+					}
+				},
+				WithLatestCreated("already-stamped-00001"), WithLatestReady("already-stamped-00001")),
+			rev("already-stamped", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("already-stamped-00001"), withImageDigest(oldDigest)),
+		},
+		Key: "foo/already-stamped",
+	}, {
+		Name: "tag not watched",
+		Objects: []runtime.Object{
+			cfg("not-watched", "foo", 1, WithObservedGen,
+				WithLatestCreated("not-watched-00001"), WithLatestReady("not-watched-00001")),
+			rev("not-watched", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("not-watched-00001"), withImageDigest(oldDigest)),
+		},
+		Key: "foo/not-watched",
+	}, {
+		Name:    "patch fails",
+		WantErr: true,
+		WithReactors: []clientgotesting.ReactionFunc{
+			InduceFailure("patch", "configurations"),
+		},
+		Objects: []runtime.Object{
+			cfg("patch-fails", "foo", 1, WithObservedGen, watchImageTag,
+				WithLatestCreated("patch-fails-00001"), WithLatestReady("patch-fails-00001")),
+			rev("patch-fails", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("patch-fails-00001"), withImageDigest(oldDigest)),
+		},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchImageDigest("foo", "patch-fails", newDigest),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "InternalError", "inducing failure for patch configurations"),
+		},
+		Key: "foo/patch-fails",
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return newTagWatchingReconciler(ctx, listers, cmw, &fakeResolver{digest: newDigest})
+	}))
+}
+
+func TestWatchImageTagResolveFails(t *testing.T) {
+	now := time.Now()
+
+	table := TableTest{{
+		Name: "resolve fails",
+		Objects: []runtime.Object{
+			cfg("resolve-fails", "foo", 1, WithObservedGen, watchImageTag,
+				WithLatestCreated("resolve-fails-00001"), WithLatestReady("resolve-fails-00001")),
+			rev("resolve-fails", "foo", 1, WithCreationTimestamp(now), MarkRevisionReady,
+				WithRevName("resolve-fails-00001"), withImageDigest(oldDigest)),
+		},
+		Key: "foo/resolve-fails",
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return newTagWatchingReconciler(ctx, listers, cmw, &fakeResolver{err: errors.New("registry unavailable")})
+	}))
+}
+
+func newTagWatchingReconciler(ctx context.Context, listers *Listers, cmw configmap.Watcher, resolver *fakeResolver) controller.Reconciler {
+	cfg := ReconcilerTestConfig()
+	cfg.Features = &apisconfig.Features{TagWatching: apisconfig.Enabled}
+	return &Reconciler{
+		Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+		configurationLister: listers.GetConfigurationLister(),
+		revisionLister:      listers.GetRevisionLister(),
+		revisionIndexer:     listers.GetIndexer(&v1alpha1.Revision{}),
+		configStore:         &testConfigStore{config: cfg},
+		resolver:            resolver,
+		enqueueAfter:        func(interface{}, time.Duration) {},
+	}
+}
+
+func watchImageTag(cfg *v1alpha1.Configuration) {
+	if cfg.Annotations == nil {
+		cfg.Annotations = make(map[string]string, 1)
+	}
+	cfg.Annotations[serving.WatchImageTagAnnotationKey] = "true"
+}
+
+func withImageDigest(digest string) RevisionOption {
+	return func(rev *v1alpha1.Revision) {
+		rev.Status.ImageDigest = digest
+	}
+}
+
+func patchImageDigest(namespace, name, digest string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		serving.ImageDigestAnnotationKey, digest)
+	action.Patch = []byte(patch)
+	return action
+}
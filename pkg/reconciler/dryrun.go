@@ -0,0 +1,43 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// IsDryRun returns whether the changes to the resources obj owns should be
+// reported with RecordDryRun rather than made.
+func IsDryRun(obj metav1.Object) bool {
+	return obj.GetAnnotations()[serving.DryRunAnnotationKey] == "true"
+}
+
+// RecordDryRun logs and emits an event on obj for a change its reconciler
+// would make were obj not in dry-run.
+func (b *Base) RecordDryRun(ctx context.Context, obj runtime.Object, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	logging.FromContext(ctx).Infof("Dry-run: %s", msg)
+	b.Recorder.Event(obj, corev1.EventTypeNormal, "DryRun", msg)
+}
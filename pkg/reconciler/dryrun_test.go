@@ -0,0 +1,66 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{{
+		name: "no annotation",
+	}, {
+		name:        "dry-run",
+		annotations: map[string]string{serving.DryRunAnnotationKey: "true"},
+		want:        true,
+	}, {
+		name:        "not dry-run",
+		annotations: map[string]string{serving.DryRunAnnotationKey: "false"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: test.annotations}
+			if got := IsDryRun(obj); got != test.want {
+				t.Errorf("IsDryRun() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecordDryRun(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	b := &Base{Recorder: recorder}
+
+	b.RecordDryRun(context.Background(), &corev1.Pod{}, "Would create Pod %q", "foo")
+
+	want := `Normal DryRun Would create Pod "foo"`
+	if got := <-recorder.Events; got != want {
+		t.Errorf("Event = %q, want: %q", got, want)
+	}
+}
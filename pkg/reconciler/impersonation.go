@@ -0,0 +1,116 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"knative.dev/pkg/injection"
+)
+
+// childServiceAccount is the name of the ServiceAccount, in the namespace of
+// the child resources, the reconcilers create and update them as.
+var childServiceAccount = flag.String("child-resource-service-account", "",
+	"The ServiceAccount, in the namespace of the child resources, to impersonate when creating and updating them. Empty uses the credentials of the controller.")
+
+func init() {
+	injection.Default.RegisterClient(withImpersonator)
+}
+
+func withImpersonator(ctx context.Context, cfg *rest.Config) context.Context {
+	if *childServiceAccount == "" {
+		return ctx
+	}
+	return WithImpersonator(ctx, NewImpersonator(cfg, *childServiceAccount))
+}
+
+type impersonatorKey struct{}
+
+// WithImpersonator attaches i to ctx, for the reconcilers created with the
+// returned context to create and update their child resources with the
+// clients of i.
+func WithImpersonator(ctx context.Context, i *Impersonator) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, i)
+}
+
+// GetImpersonator returns the Impersonator attached to ctx, or nil.
+func GetImpersonator(ctx context.Context) *Impersonator {
+	i, _ := ctx.Value(impersonatorKey{}).(*Impersonator)
+	return i
+}
+
+// Impersonator hands out the Kubernetes clients impersonating the
+// ServiceAccount of each namespace, so that quotas and audit logs attribute
+// the child resources to the tenants owning the namespaces rather than to
+// the controller.
+type Impersonator struct {
+	cfg            *rest.Config
+	serviceAccount string
+
+	// newClient builds the client of a rest.Config, a seam for tests.
+	newClient func(*rest.Config) (kubernetes.Interface, error)
+
+	mu      sync.Mutex
+	clients map[string]kubernetes.Interface
+}
+
+// NewImpersonator creates an Impersonator of the ServiceAccount named
+// serviceAccount, with the credentials of cfg. The identity cfg
+// authenticates as must be allowed to impersonate the ServiceAccounts.
+func NewImpersonator(cfg *rest.Config, serviceAccount string) *Impersonator {
+	return &Impersonator{
+		cfg:            cfg,
+		serviceAccount: serviceAccount,
+		newClient: func(cfg *rest.Config) (kubernetes.Interface, error) {
+			return kubernetes.NewForConfig(cfg)
+		},
+		clients: make(map[string]kubernetes.Interface),
+	}
+}
+
+// KubeClientFor returns the client impersonating the ServiceAccount of
+// namespace. The API server puts the impersonated ServiceAccount in its
+// groups itself.
+func (i *Impersonator) KubeClientFor(namespace string) (kubernetes.Interface, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if c, ok := i.clients[namespace]; ok {
+		return c, nil
+	}
+	cfg := rest.CopyConfig(i.cfg)
+	cfg.Impersonate = rest.ImpersonationConfig{
+		UserName: serviceAccountUsername(namespace, i.serviceAccount),
+	}
+	c, err := i.newClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the client impersonating %s/%s: %v", namespace, i.serviceAccount, err)
+	}
+	i.clients[namespace] = c
+	return c, nil
+}
+
+// serviceAccountUsername returns the username ServiceAccounts authenticate as.
+func serviceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	// ConfigurationIndex is the name of the index of the resources by the
+	// namespace/name key of the Configuration they are labeled with.
+	ConfigurationIndex = "configuration"
+	// RouteIndex is the name of the index of the resources by the
+	// namespace/name key of the Route they are labeled with.
+	RouteIndex = "route"
+	// ServiceIndex is the name of the index of the resources by the
+	// namespace/name key of the Service they are labeled with.
+	ServiceIndex = "service"
+	// RoutedRevisionIndex is the name of the index of the Routes by the
+	// namespace/name keys of the Revisions they route to.
+	RoutedRevisionIndex = "routedRevision"
+)
+
+// Indexers are the indexers the reconcilers look the resources of their
+// informers up with, instead of listing and filtering all of them.
+var Indexers = cache.Indexers{
+	ConfigurationIndex:  IndexByConfiguration,
+	RouteIndex:          IndexByRoute,
+	ServiceIndex:        IndexByService,
+	RoutedRevisionIndex: IndexByRoutedRevisions,
+}
+
+// IndexKey returns the key of the resource namespace/name in the indexes.
+func IndexKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// IndexByConfiguration indexes obj by the Configuration it is labeled with.
+func IndexByConfiguration(obj interface{}) ([]string, error) {
+	return indexByLabel(obj, serving.ConfigurationLabelKey, "")
+}
+
+// IndexByRoute indexes obj by the Route it is labeled with. The namespace
+// of the Route of cluster-scoped resources is read from their labels.
+func IndexByRoute(obj interface{}) ([]string, error) {
+	return indexByLabel(obj, serving.RouteLabelKey, serving.RouteNamespaceLabelKey)
+}
+
+// IndexByService indexes obj by the Service it is labeled with.
+func IndexByService(obj interface{}) ([]string, error) {
+	return indexByLabel(obj, serving.ServiceLabelKey, "")
+}
+
+// IndexByRoutedRevisions indexes a Route by the Revisions named in its
+// traffic, tagged or not: those it is asked to route to in its spec, which
+// it may not have pinned yet, and those it routes to in its status, e.g.
+// the former latest ready Revision of a Configuration until it catches up.
+func IndexByRoutedRevisions(obj interface{}) ([]string, error) {
+	route, ok := obj.(*v1alpha1.Route)
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	for _, traffic := range [][]v1alpha1.TrafficTarget{route.Spec.Traffic, route.Status.Traffic} {
+		for _, tt := range traffic {
+			if tt.RevisionName != "" {
+				keys = append(keys, IndexKey(route.Namespace, tt.RevisionName))
+			}
+		}
+	}
+	return keys, nil
+}
+
+// indexByLabel indexes obj by the value of its label key, in its namespace
+// or, if it is cluster-scoped, in that of its label namespaceKey.
+func indexByLabel(obj interface{}, key, namespaceKey string) ([]string, error) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	name, ok := m.GetLabels()[key]
+	if !ok {
+		return nil, nil
+	}
+	namespace := m.GetNamespace()
+	if namespace == "" && namespaceKey != "" {
+		namespace = m.GetLabels()[namespaceKey]
+	}
+	return []string{IndexKey(namespace, name)}, nil
+}
+
+// AddIndexers adds to the informer si those of the indexers of the given
+// names it doesn't have yet, so that the controllers sharing it can each
+// add the ones they need. It must be called before si is started.
+func AddIndexers(si cache.SharedIndexInformer, names ...string) error {
+	existing := si.GetIndexer().GetIndexers()
+	indexers := make(cache.Indexers, len(names))
+	for _, name := range sets.NewString(names...).List() {
+		if _, ok := existing[name]; !ok {
+			indexers[name] = Indexers[name]
+		}
+	}
+	if len(indexers) == 0 {
+		return nil
+	}
+	return si.AddIndexers(indexers)
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+)
+
+func TestIndexByLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		index cache.IndexFunc
+		obj   interface{}
+		want  []string
+	}{{
+		name:  "configuration",
+		index: IndexByConfiguration,
+		obj: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "rev",
+				Labels:    map[string]string{serving.ConfigurationLabelKey: "config"},
+			},
+		},
+		want: []string{"ns/config"},
+	}, {
+		name:  "not labeled",
+		index: IndexByConfiguration,
+		obj: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "rev",
+				Labels:    map[string]string{serving.ServiceLabelKey: "svc"},
+			},
+		},
+	}, {
+		name:  "service",
+		index: IndexByService,
+		obj: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "rev",
+				Labels:    map[string]string{serving.ServiceLabelKey: "svc"},
+			},
+		},
+		want: []string{"ns/svc"},
+	}, {
+		name:  "route",
+		index: IndexByRoute,
+		obj: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ns",
+				Name:      "svc",
+				Labels: map[string]string{
+					serving.RouteLabelKey:          "route",
+					serving.RouteNamespaceLabelKey: "other",
+				},
+			},
+		},
+		want: []string{"ns/route"},
+	}, {
+		name:  "route of cluster-scoped resource",
+		index: IndexByRoute,
+		obj: &netv1alpha1.ClusterIngress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ci",
+				Labels: map[string]string{
+					serving.RouteLabelKey:          "route",
+					serving.RouteNamespaceLabelKey: "ns",
+				},
+			},
+		},
+		want: []string{"ns/route"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.index(test.obj)
+			if err != nil {
+				t.Fatalf("Index() = %v", err)
+			}
+			if !cmp.Equal(got, test.want) {
+				t.Errorf("Index() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIndexByRoutedRevisions(t *testing.T) {
+	route := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "route",
+		},
+		Spec: v1alpha1.RouteSpec{
+			Traffic: []v1alpha1.TrafficTarget{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					RevisionName: "pinned",
+				},
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					ConfigurationName: "config",
+				},
+			}},
+		},
+		Status: v1alpha1.RouteStatus{
+			RouteStatusFields: v1alpha1.RouteStatusFields{
+				Traffic: []v1alpha1.TrafficTarget{{
+					TrafficTarget: v1beta1.TrafficTarget{
+						RevisionName: "latest",
+					},
+				}},
+			},
+		},
+	}
+
+	got, err := IndexByRoutedRevisions(route)
+	if err != nil {
+		t.Fatalf("IndexByRoutedRevisions() = %v", err)
+	}
+	if want := []string{"ns/pinned", "ns/latest"}; !cmp.Equal(got, want) {
+		t.Errorf("IndexByRoutedRevisions() = %v, want %v", got, want)
+	}
+}
+
+func TestAddIndexers(t *testing.T) {
+	si := cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1alpha1.Revision{}, 0, cache.Indexers{})
+	if err := AddIndexers(si, ConfigurationIndex); err != nil {
+		t.Fatalf("AddIndexers() = %v", err)
+	}
+	// The controllers sharing the informer may ask for the same indexers.
+	if err := AddIndexers(si, ConfigurationIndex, ServiceIndex); err != nil {
+		t.Fatalf("AddIndexers() = %v", err)
+	}
+
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns",
+			Name:      "rev",
+			Labels: map[string]string{
+				serving.ConfigurationLabelKey: "config",
+				serving.ServiceLabelKey:       "svc",
+			},
+		},
+	}
+	si.GetIndexer().Add(rev)
+	for index, key := range map[string]string{
+		ConfigurationIndex: "ns/config",
+		ServiceIndex:       "ns/svc",
+	} {
+		got, err := si.GetIndexer().ByIndex(index, key)
+		if err != nil {
+			t.Fatalf("ByIndex(%s) = %v", index, err)
+		}
+		if want := []interface{}{rev}; !cmp.Equal(got, want) {
+			t.Errorf("ByIndex(%s) = %v, want %v", index, got, want)
+		}
+	}
+}
@@ -21,8 +21,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"knative.dev/pkg/apis/istio/v1alpha3"
+	istioclientset "knative.dev/pkg/client/clientset/versioned"
+	istioinformers "knative.dev/pkg/client/informers/externalversions/istio/v1alpha3"
+	istiofactory "knative.dev/pkg/client/injection/informers/istio/factory"
 	gatewayinformer "knative.dev/pkg/client/injection/informers/istio/v1alpha3/gateway"
 	virtualserviceinformer "knative.dev/pkg/client/injection/informers/istio/v1alpha3/virtualservice"
 	"knative.dev/pkg/logging"
@@ -40,6 +44,7 @@ import (
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/ingress/config"
@@ -60,6 +65,22 @@ const (
 	controllerAgentName = "ingress-controller"
 )
 
+func init() {
+	// The Istio resources are only watched once an Ingress is reconciled.
+	informers.RegisterLazy(informers.Istio, func(ctx context.Context, lazy *informers.Lazy) {
+		factory := istiofactory.Get(ctx)
+		indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+		factory.InformerFor(&v1alpha3.Gateway{},
+			func(client istioclientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+				return lazy.Wrap(istioinformers.NewGatewayInformer(client, metav1.NamespaceAll, resync, indexers))
+			})
+		factory.InformerFor(&v1alpha3.VirtualService{},
+			func(client istioclientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+				return lazy.Wrap(istioinformers.NewVirtualServiceInformer(client, metav1.NamespaceAll, resync, indexers))
+			})
+	})
+}
+
 type Reconciler struct {
 	*BaseIngressReconciler
 	ingressLister listers.IngressLister
@@ -87,6 +108,10 @@ type BaseIngressReconciler struct {
 	SecretLister         corev1listers.SecretLister
 	ConfigStore          reconciler.ConfigStore
 
+	// istioInformers back the VirtualServiceLister and the GatewayLister,
+	// and are started by the first reconcile.
+	istioInformers *informers.Lazy
+
 	Tracker   tracker.Interface
 	Finalizer string
 }
@@ -103,6 +128,7 @@ func NewBaseIngressReconciler(ctx context.Context, agentName, finalizer string,
 		GatewayLister:        gatewayInformer.Lister(),
 		SecretLister:         secretInformer.Lister(),
 		Finalizer:            finalizer,
+		istioInformers:       informers.GetLazy(ctx, informers.Istio),
 	}
 	return base
 }
@@ -204,6 +230,9 @@ func (r *BaseIngressReconciler) ReconcileIngress(ctx context.Context, ra Reconci
 	} else if err != nil {
 		return err
 	}
+	if err := r.istioInformers.Start(); err != nil {
+		return err
+	}
 	// Don't modify the informers copy
 	ingress := original.DeepCopyObject().(v1alpha1.IngressAccessor)
 
@@ -29,6 +29,7 @@ import (
 	"knative.dev/pkg/apis/istio/v1alpha3"
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
@@ -173,19 +174,18 @@ func makeVirtualServiceRoute(hosts []string, http *v1alpha1.HTTPIngressPath, gat
 	for _, host := range expandedHosts(hosts) {
 		matches = append(matches, makeMatch(host, http.Path, gateways))
 	}
+	// Istio doesn't allow redirects to route the requests anywhere.
+	if http.Redirect != nil {
+		return &v1alpha3.HTTPRoute{
+			Match: matches,
+			Redirect: &v1alpha3.HTTPRedirect{
+				URI:       http.Redirect.Path,
+				Authority: http.Redirect.Host,
+			},
+		}
+	}
 	weights := []v1alpha3.HTTPRouteDestination{}
 	for _, split := range http.Splits {
-
-		var h *v1alpha3.Headers
-
-		if len(split.AppendHeaders) > 0 {
-			h = &v1alpha3.Headers{
-				Request: &v1alpha3.HeaderOperations{
-					Add: split.AppendHeaders,
-				},
-			}
-		}
-
 		weights = append(weights, v1alpha3.HTTPRouteDestination{
 			Destination: v1alpha3.Destination{
 				Host: network.GetServiceHostname(
@@ -193,7 +193,7 @@ func makeVirtualServiceRoute(hosts []string, http *v1alpha1.HTTPIngressPath, gat
 				Port: makePortSelector(split.ServicePort),
 			},
 			Weight:  split.Percent,
-			Headers: h,
+			Headers: makeSplitHeaders(split.AppendHeaders),
 		})
 	}
 
@@ -206,9 +206,18 @@ func makeVirtualServiceRoute(hosts []string, http *v1alpha1.HTTPIngressPath, gat
 		}
 	}
 
+	var rewrite *v1alpha3.HTTPRewrite
+	if http.Rewrite != nil {
+		rewrite = &v1alpha3.HTTPRewrite{
+			URI:       http.Rewrite.Path,
+			Authority: http.Rewrite.Host,
+		}
+	}
+
 	return &v1alpha3.HTTPRoute{
 		Match:   matches,
 		Route:   weights,
+		Rewrite: rewrite,
 		Timeout: http.Timeout.Duration.String(),
 		Retries: &v1alpha3.HTTPRetry{
 			Attempts:      http.Retries.Attempts,
@@ -223,6 +232,45 @@ func makeVirtualServiceRoute(hosts []string, http *v1alpha1.HTTPIngressPath, gat
 	}
 }
 
+// trustedHeaders are the headers of the requests the activator and the
+// queue-proxies trust the ingress to set. The clients could send them too,
+// so each split overwrites the ones it sets and removes the others.
+var trustedHeaders = sets.NewString(
+	activator.RevisionHeaderName,
+	activator.RevisionHeaderNamespace,
+	activator.RouteHeaderName,
+	activator.SplitsHeaderName,
+	activator.StickyHeaderName,
+	activator.FallbackHeaderName,
+	activator.MaintenanceHeaderName,
+	network.RouteTagHeaderName,
+)
+
+// makeSplitHeaders returns the operations on the headers of the requests
+// sent to a split adding headers, which overwrite the trusted headers.
+func makeSplitHeaders(headers map[string]string) *v1alpha3.Headers {
+	ops := &v1alpha3.HeaderOperations{}
+	for k, v := range headers {
+		if trustedHeaders.Has(k) {
+			if ops.Set == nil {
+				ops.Set = make(map[string]string)
+			}
+			ops.Set[k] = v
+			continue
+		}
+		if ops.Add == nil {
+			ops.Add = make(map[string]string)
+		}
+		ops.Add[k] = v
+	}
+	for _, k := range trustedHeaders.List() {
+		if _, ok := headers[k]; !ok {
+			ops.Remove = append(ops.Remove, k)
+		}
+	}
+	return &v1alpha3.Headers{Request: ops}
+}
+
 func dedup(hosts []string) []string {
 	return sets.NewString(hosts...).List()
 }
@@ -28,14 +28,24 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/system"
 	_ "knative.dev/pkg/system/testing"
+	"knative.dev/serving/pkg/activator"
 	apiconfig "knative.dev/serving/pkg/apis/config"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/network"
 )
 
 var (
 	defaultMaxRevisionTimeout = time.Duration(apiconfig.DefaultMaxRevisionTimeoutSeconds) * time.Second
+
+	// removeTrustedHeaders are the header operations of the splits that
+	// don't add any headers.
+	removeTrustedHeaders = &v1alpha3.Headers{
+		Request: &v1alpha3.HeaderOperations{
+			Remove: trustedHeaders.List(),
+		},
+	}
 )
 
 func TestMakeVirtualServices_CorrectMetadata(t *testing.T) {
@@ -241,6 +251,7 @@ func TestMakeMeshVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 					Add: map[string]string{
 						"ugh": "blah",
 					},
+					Remove: trustedHeaders.List(),
 				},
 			},
 		}},
@@ -377,6 +388,7 @@ func TestMakeIngressVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 					Add: map[string]string{
 						"ugh": "blah",
 					},
+					Remove: trustedHeaders.List(),
 				},
 			},
 		}},
@@ -406,7 +418,8 @@ func TestMakeIngressVirtualServiceSpec_CorrectRoutes(t *testing.T) {
 				Host: "v1-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 100,
+			Weight:  100,
+			Headers: removeTrustedHeaders,
 		}},
 		Headers: &v1alpha3.Headers{
 			Request: &v1alpha3.HeaderOperations{
@@ -465,7 +478,8 @@ func TestMakeVirtualServiceRoute_Vanilla(t *testing.T) {
 				Host: "revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 100,
+			Weight:  100,
+			Headers: removeTrustedHeaders,
 		}},
 		Timeout: defaultMaxRevisionTimeout.String(),
 		Retries: &v1alpha3.HTTPRetry{
@@ -479,6 +493,84 @@ func TestMakeVirtualServiceRoute_Vanilla(t *testing.T) {
 	}
 }
 
+func TestMakeVirtualServiceRoute_Rewrite(t *testing.T) {
+	ingressPath := &v1alpha1.HTTPIngressPath{
+		Splits: []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: "test-ns",
+				ServiceName:      "revision-service",
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+		}},
+		Timeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+		Retries: &v1alpha1.HTTPRetry{
+			PerTryTimeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+			Attempts:      networking.DefaultRetryCount,
+		},
+		Rewrite: &v1alpha1.HTTPRewrite{
+			Path: "/status",
+			Host: "internal.example.com",
+		},
+	}
+	route := makeVirtualServiceRoute([]string{"a.com"}, ingressPath, []string{"gateway-1"})
+	expected := v1alpha3.HTTPRoute{
+		Match: []v1alpha3.HTTPMatchRequest{{
+			Gateways:  []string{"knative-testing/gateway-1"},
+			Authority: &istiov1alpha1.StringMatch{Regex: `^a\.com(?::\d{1,5})?$`},
+		}},
+		Route: []v1alpha3.HTTPRouteDestination{{
+			Destination: v1alpha3.Destination{
+				Host: "revision-service.test-ns.svc.cluster.local",
+				Port: v1alpha3.PortSelector{Number: 80},
+			},
+			Weight:  100,
+			Headers: removeTrustedHeaders,
+		}},
+		Rewrite: &v1alpha3.HTTPRewrite{
+			URI:       "/status",
+			Authority: "internal.example.com",
+		},
+		Timeout: defaultMaxRevisionTimeout.String(),
+		Retries: &v1alpha3.HTTPRetry{
+			Attempts:      networking.DefaultRetryCount,
+			PerTryTimeout: defaultMaxRevisionTimeout.String(),
+		},
+		WebsocketUpgrade: true,
+	}
+	if diff := cmp.Diff(&expected, route); diff != "" {
+		t.Errorf("Unexpected route  (-want +got): %v", diff)
+	}
+}
+
+func TestMakeVirtualServiceRoute_Redirect(t *testing.T) {
+	ingressPath := &v1alpha1.HTTPIngressPath{
+		Path:    "^/old([/?].*)?$",
+		Timeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+		Retries: &v1alpha1.HTTPRetry{
+			PerTryTimeout: &metav1.Duration{Duration: defaultMaxRevisionTimeout},
+			Attempts:      networking.DefaultRetryCount,
+		},
+		Redirect: &v1alpha1.HTTPRedirect{
+			Path: "/new",
+		},
+	}
+	route := makeVirtualServiceRoute([]string{"a.com"}, ingressPath, []string{"gateway-1"})
+	expected := v1alpha3.HTTPRoute{
+		Match: []v1alpha3.HTTPMatchRequest{{
+			Gateways:  []string{"knative-testing/gateway-1"},
+			Authority: &istiov1alpha1.StringMatch{Regex: `^a\.com(?::\d{1,5})?$`},
+			URI:       &istiov1alpha1.StringMatch{Regex: "^/old([/?].*)?$"},
+		}},
+		Redirect: &v1alpha3.HTTPRedirect{
+			URI: "/new",
+		},
+	}
+	if diff := cmp.Diff(&expected, route); diff != "" {
+		t.Errorf("Unexpected route  (-want +got): %v", diff)
+	}
+}
+
 // Two active targets.
 func TestMakeVirtualServiceRoute_TwoTargets(t *testing.T) {
 	ingressPath := &v1alpha1.HTTPIngressPath{
@@ -515,13 +607,15 @@ func TestMakeVirtualServiceRoute_TwoTargets(t *testing.T) {
 				Host: "revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Number: 80},
 			},
-			Weight: 90,
+			Weight:  90,
+			Headers: removeTrustedHeaders,
 		}, {
 			Destination: v1alpha3.Destination{
 				Host: "new-revision-service.test-ns.svc.cluster.local",
 				Port: v1alpha3.PortSelector{Name: "test-port"},
 			},
-			Weight: 10,
+			Weight:  10,
+			Headers: removeTrustedHeaders,
 		}},
 		Timeout: defaultMaxRevisionTimeout.String(),
 		Retries: &v1alpha3.HTTPRetry{
@@ -535,6 +629,38 @@ func TestMakeVirtualServiceRoute_TwoTargets(t *testing.T) {
 	}
 }
 
+func TestMakeSplitHeaders(t *testing.T) {
+	got := makeSplitHeaders(map[string]string{
+		activator.RevisionHeaderName:      "rev",
+		activator.RevisionHeaderNamespace: "ns",
+		"ugh":                             "blah",
+	})
+	want := &v1alpha3.Headers{
+		Request: &v1alpha3.HeaderOperations{
+			// The headers the activator trusts overwrite the ones of the
+			// clients, or remove them.
+			Set: map[string]string{
+				activator.RevisionHeaderName:      "rev",
+				activator.RevisionHeaderNamespace: "ns",
+			},
+			Add: map[string]string{
+				"ugh": "blah",
+			},
+			Remove: []string{
+				network.RouteTagHeaderName,
+				activator.FallbackHeaderName,
+				activator.MaintenanceHeaderName,
+				activator.RouteHeaderName,
+				activator.SplitsHeaderName,
+				activator.StickyHeaderName,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("makeSplitHeaders (-want, +got) = %v", diff)
+	}
+}
+
 func TestGetHosts_Duplicate(t *testing.T) {
 	ci := &v1alpha1.ClusterIngress{
 		Spec: v1alpha1.IngressSpec{
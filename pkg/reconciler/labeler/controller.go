@@ -19,6 +19,8 @@ package labeler
 import (
 	"context"
 
+	"go.uber.org/zap"
+
 	configurationinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/configuration"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision"
 	routeinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/route"
@@ -48,6 +50,11 @@ func NewRouteToConfigurationController(
 		routeLister:         routeInformer.Lister(),
 		configurationLister: configInformer.Lister(),
 		revisionLister:      revisionInformer.Lister(),
+
+		configurationIndexer: configInformer.Informer().GetIndexer(),
+	}
+	if err := reconciler.AddIndexers(configInformer.Informer(), reconciler.RouteIndex); err != nil {
+		c.Logger.Fatalw("Failed to index the Configurations", zap.Error(err))
 	}
 	impl := controller.NewImpl(c, c.Logger, "Labels")
 
@@ -36,6 +36,9 @@ type Reconciler struct {
 	routeLister         listers.RouteLister
 	configurationLister listers.ConfigurationLister
 	revisionLister      listers.RevisionLister
+
+	// configurationIndexer looks the Configurations up by their Route.
+	configurationIndexer cache.Indexer
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -144,6 +144,8 @@ func TestReconcile(t *testing.T) {
 			routeLister:         listers.GetRouteLister(),
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
+
+			configurationIndexer: listers.GetIndexer(&v1alpha1.Configuration{}),
 		}
 	}))
 }
@@ -23,7 +23,6 @@ import (
 	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
@@ -31,6 +30,7 @@ import (
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	servingv1alpha1 "knative.dev/serving/pkg/client/clientset/versioned/typed/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
 )
 
 func (c *Reconciler) syncLabels(ctx context.Context, r *v1alpha1.Route) error {
@@ -114,16 +114,16 @@ func (c *Reconciler) deleteLabelForOutsideOfGivenConfigurations(
 	logger := logging.FromContext(ctx)
 
 	// Get Configurations set as traffic target before this sync.
-	selector := labels.SelectorFromSet(labels.Set{serving.RouteLabelKey: routeName})
-
-	oldConfigsList, err := c.configurationLister.Configurations(routeNamespace).List(selector)
+	oldConfigsList, err := c.configurationIndexer.ByIndex(reconciler.RouteIndex,
+		reconciler.IndexKey(routeNamespace, routeName))
 	if err != nil {
 		return err
 	}
 
 	// Delete label for newly removed configurations as traffic target.
 	configClient := c.ServingClientSet.ServingV1alpha1().Configurations(routeNamespace)
-	for _, config := range oldConfigsList {
+	for _, obj := range oldConfigsList {
+		config := obj.(*v1alpha1.Configuration)
 		if configs.Has(config.Name) {
 			continue
 		}
@@ -87,6 +87,10 @@ type Base struct {
 	// StatsReporter reports reconciler's metrics.
 	StatsReporter StatsReporter
 
+	// Impersonator, if not nil, hands out the clients the child resources
+	// are created and updated with.
+	Impersonator *Impersonator
+
 	// Sugared logger is easier to use but is not as performant as the
 	// raw logger. In performance critical paths, call logger.Desugar()
 	// and use the returned raw logger instead. In addition to the
@@ -144,12 +148,23 @@ func NewBase(ctx context.Context, controllerAgentName string, cmw configmap.Watc
 		ConfigMapWatcher: cmw,
 		Recorder:         recorder,
 		StatsReporter:    statsReporter,
+		Impersonator:     GetImpersonator(ctx),
 		Logger:           logger,
 	}
 
 	return base
 }
 
+// ChildKubeClientSet returns the client to create, update and delete the
+// child resources in namespace with: that of the Impersonator if any, else
+// KubeClientSet.
+func (b *Base) ChildKubeClientSet(namespace string) (kubernetes.Interface, error) {
+	if b.Impersonator == nil {
+		return b.KubeClientSet, nil
+	}
+	return b.Impersonator.KubeClientFor(namespace)
+}
+
 func (b *Base) MarkNeedsUpgrade(gvr schema.GroupVersionResource, namespace, name string) error {
 	// Add the annotation serving.knative.dev/forceUpgrade=true to trigger webhook-based defaulting.
 	_, err := b.DynamicClientSet.Resource(gvr).Namespace(namespace).Patch(name, types.JSONPatchType,
@@ -26,6 +26,9 @@ import (
 	_ "knative.dev/pkg/injection/clients/kubeclient/fake"
 	_ "knative.dev/serving/pkg/client/injection/client/fake"
 
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/kubernetes"
+	fakekubeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/injection"
@@ -58,3 +61,54 @@ func TestNew(t *testing.T) {
 		t.Fatal("Expected NewBase to add a StatsReporter")
 	}
 }
+
+func TestChildKubeClientSet(t *testing.T) {
+	kubeClient := fakekubeclientset.NewSimpleClientset()
+	b := &Base{KubeClientSet: kubeClient}
+	if got, err := b.ChildKubeClientSet("tenant"); err != nil || got != kubeClient {
+		t.Errorf("ChildKubeClientSet() = %v, %v, want: KubeClientSet", got, err)
+	}
+
+	var impersonated []rest.ImpersonationConfig
+	i := NewImpersonator(&rest.Config{Host: "https://kubernetes"}, "builder")
+	i.newClient = func(cfg *rest.Config) (kubernetes.Interface, error) {
+		impersonated = append(impersonated, cfg.Impersonate)
+		return fakekubeclientset.NewSimpleClientset(), nil
+	}
+	b.Impersonator = i
+
+	tenant, err := b.ChildKubeClientSet("tenant")
+	if err != nil {
+		t.Fatalf("ChildKubeClientSet(tenant) = %v", err)
+	}
+	if tenant == kubeClient {
+		t.Error("ChildKubeClientSet(tenant) = KubeClientSet, want the impersonating client")
+	}
+	if again, _ := b.ChildKubeClientSet("tenant"); again != tenant {
+		t.Error("ChildKubeClientSet(tenant) returned a new client, want the cached one")
+	}
+	if _, err := b.ChildKubeClientSet("other"); err != nil {
+		t.Fatalf("ChildKubeClientSet(other) = %v", err)
+	}
+	want := []rest.ImpersonationConfig{{
+		UserName: "system:serviceaccount:tenant:builder",
+	}, {
+		UserName: "system:serviceaccount:other:builder",
+	}}
+	if !cmp.Equal(impersonated, want) {
+		t.Errorf("Impersonated users (-want, +got) = %v", cmp.Diff(want, impersonated))
+	}
+	if i.cfg.Impersonate.UserName != "" {
+		t.Errorf("Impersonate.UserName = %q, want the config of the controller left alone", i.cfg.Impersonate.UserName)
+	}
+}
+
+func TestImpersonatorFromContext(t *testing.T) {
+	if got := GetImpersonator(context.Background()); got != nil {
+		t.Errorf("GetImpersonator() = %v, want: nil", got)
+	}
+	i := NewImpersonator(&rest.Config{}, "builder")
+	if got := GetImpersonator(WithImpersonator(context.Background(), i)); got != i {
+		t.Errorf("GetImpersonator() = %v, want: %v", got, i)
+	}
+}
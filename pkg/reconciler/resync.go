@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+// AffectedFunc reports whether obj is affected by the change of the fields
+// changed of the config name.
+type AffectedFunc func(name string, changed sets.String, obj interface{}) bool
+
+// ChangedFields returns the names of the fields that differ between the
+// structs old and new point to, which must be of the same type.
+func ChangedFields(old, new interface{}) sets.String {
+	o, n := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	changed := sets.NewString()
+	for i := 0; i < o.NumField(); i++ {
+		if !equality.Semantic.DeepEqual(o.Field(i).Interface(), n.Field(i).Interface()) {
+			changed.Insert(o.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
+
+// SelectiveResync returns a callback for a config store, which passes to
+// handler the objects of si affected by the fields of the config that
+// changed, instead of all of them. Nothing is passed when no field changed.
+// All the objects are passed on the first value of each config, which has
+// nothing to be compared to.
+func SelectiveResync(si cache.SharedInformer, affected AffectedFunc, handler func(obj interface{})) func(name string, value interface{}) {
+	var (
+		m        sync.Mutex
+		previous = make(map[string]interface{})
+	)
+	return func(name string, value interface{}) {
+		m.Lock()
+		prev, ok := previous[name]
+		previous[name] = value
+		m.Unlock()
+
+		if !ok {
+			for _, obj := range si.GetStore().List() {
+				handler(obj)
+			}
+			return
+		}
+		changed := ChangedFields(prev, value)
+		if changed.Len() == 0 {
+			return
+		}
+		for _, obj := range si.GetStore().List() {
+			if affected(name, changed, obj) {
+				handler(obj)
+			}
+		}
+	}
+}
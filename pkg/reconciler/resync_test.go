@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+)
+
+type testConfig struct {
+	Name   string
+	Values map[string]string
+	Count  int
+}
+
+func TestChangedFields(t *testing.T) {
+	old := &testConfig{Name: "a", Values: map[string]string{"k": "v"}, Count: 1}
+	tests := []struct {
+		name string
+		new  *testConfig
+		want []string
+	}{{
+		name: "same",
+		new:  &testConfig{Name: "a", Values: map[string]string{"k": "v"}, Count: 1},
+		want: []string{},
+	}, {
+		name: "one",
+		new:  &testConfig{Name: "a", Values: map[string]string{"k": "w"}, Count: 1},
+		want: []string{"Values"},
+	}, {
+		name: "all",
+		new:  &testConfig{Name: "b", Count: 2},
+		want: []string{"Count", "Name", "Values"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ChangedFields(old, test.new).List(); !cmp.Equal(got, test.want) {
+				t.Errorf("ChangedFields() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSelectiveResync(t *testing.T) {
+	si := cache.NewSharedInformer(&cache.ListWatch{}, &corev1.Pod{}, 0)
+	for _, name := range []string{"count", "name"} {
+		si.GetStore().Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	// Each pod is affected by the field it is named after.
+	affected := func(_ string, changed sets.String, obj interface{}) bool {
+		return changed.Has(map[string]string{"count": "Count", "name": "Name"}[obj.(*corev1.Pod).Name])
+	}
+	var got sets.String
+	resync := SelectiveResync(si, affected, func(obj interface{}) {
+		got.Insert(obj.(*corev1.Pod).Name)
+	})
+
+	for _, step := range []struct {
+		name  string
+		value *testConfig
+		want  []string
+	}{{
+		name:  "first value",
+		value: &testConfig{Name: "a", Count: 1},
+		want:  []string{"count", "name"},
+	}, {
+		name:  "no change",
+		value: &testConfig{Name: "a", Count: 1},
+		want:  []string{},
+	}, {
+		name:  "unused field",
+		value: &testConfig{Name: "a", Count: 1, Values: map[string]string{"k": "v"}},
+		want:  []string{},
+	}, {
+		name:  "count",
+		value: &testConfig{Name: "a", Count: 2, Values: map[string]string{"k": "v"}},
+		want:  []string{"count"},
+	}, {
+		name:  "both",
+		value: &testConfig{Name: "b", Count: 3, Values: map[string]string{"k": "v"}},
+		want:  []string{"count", "name"},
+	}} {
+		got = sets.NewString()
+		resync("config", step.value)
+		if !cmp.Equal(got.List(), step.want) {
+			t.Errorf("%s: handled %v, want %v", step.name, got.List(), step.want)
+		}
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/network"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 )
 
 type cfgKey struct{}
@@ -38,6 +39,7 @@ type Config struct {
 	Observability *metrics.ObservabilityConfig
 	Logging       *pkglogging.Config
 	Autoscaler    *autoscaler.Config
+	Tracing       *tracingconfig.Config
 }
 
 func FromContext(ctx context.Context) *Config {
@@ -65,6 +67,7 @@ func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value i
 				pkgmetrics.ConfigMapName(): metrics.NewObservabilityConfigFromConfigMap,
 				autoscaler.ConfigName:      autoscaler.NewConfigFromConfigMap,
 				pkglogging.ConfigMapName(): logging.NewConfigFromConfigMap,
+				tracingconfig.ConfigName:   tracingconfig.NewTracingConfigFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -84,5 +87,6 @@ func (s *Store) Load() *Config {
 		Observability: s.UntypedLoad(pkgmetrics.ConfigMapName()).(*metrics.ObservabilityConfig).DeepCopy(),
 		Logging:       s.UntypedLoad((pkglogging.ConfigMapName())).(*pkglogging.Config).DeepCopy(),
 		Autoscaler:    s.UntypedLoad(autoscaler.ConfigName).(*autoscaler.Config).DeepCopy(),
+		Tracing:       s.UntypedLoad(tracingconfig.ConfigName).(*tracingconfig.Config).DeepCopy(),
 	}
 }
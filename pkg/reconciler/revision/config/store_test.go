@@ -31,6 +31,7 @@ import (
 	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/network"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 
 	. "knative.dev/pkg/configmap/testing"
 )
@@ -44,12 +45,14 @@ func TestStoreLoadWithContext(t *testing.T) {
 	observabilityConfig := ConfigMapFromTestFile(t, pkgmetrics.ConfigMapName())
 	loggingConfig := ConfigMapFromTestFile(t, pkglogging.ConfigMapName())
 	autoscalerConfig := ConfigMapFromTestFile(t, autoscaler.ConfigName)
+	tracingConfig := ConfigMapFromTestFile(t, tracingconfig.ConfigName)
 
 	store.OnConfigChanged(deploymentConfig)
 	store.OnConfigChanged(networkConfig)
 	store.OnConfigChanged(observabilityConfig)
 	store.OnConfigChanged(loggingConfig)
 	store.OnConfigChanged(autoscalerConfig)
+	store.OnConfigChanged(tracingConfig)
 
 	config := FromContext(store.ToContext(context.Background()))
 
@@ -89,6 +92,13 @@ func TestStoreLoadWithContext(t *testing.T) {
 			t.Errorf("Unexpected autoscaler config (-want, +got): %v", diff)
 		}
 	})
+
+	t.Run("tracing", func(t *testing.T) {
+		expected, _ := tracingconfig.NewTracingConfigFromConfigMap(tracingConfig)
+		if diff := cmp.Diff(expected, config.Tracing); diff != "" {
+			t.Errorf("Unexpected tracing config (-want, +got): %v", diff)
+		}
+	})
 }
 
 func TestStoreImmutableConfig(t *testing.T) {
@@ -100,6 +110,7 @@ func TestStoreImmutableConfig(t *testing.T) {
 	store.OnConfigChanged(ConfigMapFromTestFile(t, pkgmetrics.ConfigMapName()))
 	store.OnConfigChanged(ConfigMapFromTestFile(t, pkglogging.ConfigMapName()))
 	store.OnConfigChanged(ConfigMapFromTestFile(t, autoscaler.ConfigName))
+	store.OnConfigChanged(ConfigMapFromTestFile(t, tracingconfig.ConfigName))
 
 	config := store.Load()
 
@@ -18,26 +18,31 @@ package revision
 
 import (
 	"context"
-	"net/http"
+	"sync"
 
 	imageinformer "knative.dev/caching/pkg/client/injection/informers/caching/v1alpha1/image"
-	"knative.dev/pkg/injection/clients/kubeclient"
 	deploymentinformer "knative.dev/pkg/injection/informers/kubeinformers/appsv1/deployment"
 	configmapinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/configmap"
+	secretinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/secret"
 	serviceinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/service"
 	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
-	"knative.dev/pkg/logging"
+	pkglogging "knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/deployment"
+	"knative.dev/serving/pkg/logging"
 	"knative.dev/serving/pkg/metrics"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/revision/config"
+	"knative.dev/serving/pkg/reconciler/revision/resources"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 )
 
 const (
@@ -50,16 +55,10 @@ func NewController(
 	ctx context.Context,
 	cmw configmap.Watcher,
 ) *controller.Impl {
-	transport := http.DefaultTransport
-	if rt, err := newResolverTransport(k8sCertPath); err != nil {
-		logging.FromContext(ctx).Errorf("Failed to create resolver transport: %v", err)
-	} else {
-		transport = rt
-	}
-
 	deploymentInformer := deploymentinformer.Get(ctx)
 	serviceInformer := serviceinformer.Get(ctx)
 	configMapInformer := configmapinformer.Get(ctx)
+	secretInformer := secretinformer.Get(ctx)
 	imageInformer := imageinformer.Get(ctx)
 	revisionInformer := revisioninformer.Get(ctx)
 	paInformer := painformer.Get(ctx)
@@ -72,16 +71,16 @@ func NewController(
 		deploymentLister:    deploymentInformer.Lister(),
 		serviceLister:       serviceInformer.Lister(),
 		configMapLister:     configMapInformer.Lister(),
-		resolver: &digestResolver{
-			client:    kubeclient.Get(ctx),
-			transport: transport,
-		},
+		secretLister:        secretInformer.Lister(),
+		resolver:            NewResolver(ctx),
+		statusLimiter:       reconciler.NewStatusLimiter(system.RealClock{}),
 	}
 	impl := controller.NewImpl(c, c.Logger, "Revisions")
+	c.enqueueAfter = impl.EnqueueAfter
 
 	// Set up an event handler for when the resource types of interest change
 	c.Logger.Info("Setting up event handlers")
-	revisionInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+	revisionInformer.Informer().AddEventHandler(reconciler.JitterResyncs(impl, reconciler.ResyncJitter))
 
 	deploymentInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: controller.Filter(v1alpha1.SchemeGroupVersion.WithKind("Revision")),
@@ -102,21 +101,80 @@ func NewController(
 		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
 	})
 
+	secretInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: controller.Filter(v1alpha1.SchemeGroupVersion.WithKind("Revision")),
+		Handler:    controller.HandleAll(impl.EnqueueControllerOf),
+	})
+
 	configsToResync := []interface{}{
 		&network.Config{},
 		&metrics.ObservabilityConfig{},
 		&deployment.Config{},
+		&tracingconfig.Config{},
 	}
 
-	resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
-		// Triggers syncs on all revisions when configuration
-		// changes
-		impl.GlobalResync(revisionInformer.Informer())
-	})
+	// Triggers syncs on the revisions affected by configuration changes.
+	resync := configmap.TypeFilter(configsToResync...)(reconciler.SelectiveResync(
+		revisionInformer.Informer(), affectedByConfig, impl.Enqueue))
+
+	// The queue-proxy log level overrides are applied to the pods in place,
+	// only the revisions they changed for are resynced.
+	logLevelResync := queueLogLevelResync(revisionInformer.Informer(), impl.Enqueue)
 
-	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
+	configStore := config.NewStore(c.Logger.Named("config-store"), resync, logLevelResync)
 	configStore.WatchConfigs(c.ConfigMapWatcher)
 	c.configStore = configStore
 
 	return impl
 }
+
+// affectedByConfig reports whether the Revision obj is affected by the change
+// of the fields changed of the config name. Of the network config only the
+// fields applied to the Deployments affect the Revisions.
+func affectedByConfig(name string, changed sets.String, obj interface{}) bool {
+	rev, ok := obj.(*v1alpha1.Revision)
+	if !ok || name != network.ConfigName {
+		return true
+	}
+	if changed.HasAny("ForwardedForPolicy", "ForwardedHeaders", "TrustedHops") {
+		return true
+	}
+	_, pinned := rev.Annotations[resources.IstioOutboundIPRangeAnnotation]
+	return changed.Has("IstioOutboundIPRanges") && !pinned
+}
+
+// queueLogLevelResync returns a callback of the config store handling the
+// Revisions whose queue-proxy log level override changed with the logging
+// config.
+func queueLogLevelResync(si cache.SharedInformer, handler func(obj interface{})) func(name string, value interface{}) {
+	var (
+		m        sync.Mutex
+		previous *pkglogging.Config
+	)
+	return func(name string, value interface{}) {
+		cfg, ok := value.(*pkglogging.Config)
+		if !ok {
+			return
+		}
+		m.Lock()
+		prev := previous
+		previous = cfg
+		m.Unlock()
+
+		// The Revisions are all reconciled once the controller starts.
+		if prev == nil {
+			return
+		}
+		for _, obj := range si.GetStore().List() {
+			rev, ok := obj.(*v1alpha1.Revision)
+			if !ok {
+				continue
+			}
+			was, wasSet := logging.LevelOverride(prev, queueProxyComponent, rev.Namespace, rev.Name)
+			is, isSet := logging.LevelOverride(cfg, queueProxyComponent, rev.Namespace, rev.Name)
+			if wasSet != isSet || was != is {
+				handler(obj)
+			}
+		}
+	}
+}
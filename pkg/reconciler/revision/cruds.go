@@ -18,14 +18,17 @@ package revision
 
 import (
 	"context"
+	"encoding/json"
 
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	caching "knative.dev/caching/pkg/apis/caching/v1alpha1"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/reconciler/revision/config"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
 	presources "knative.dev/serving/pkg/resources"
@@ -42,8 +45,23 @@ func (c *Reconciler) createDeployment(ctx context.Context, rev *v1alpha1.Revisio
 		cfgs.Autoscaler,
 		cfgs.Deployment,
 	)
+	if err := presources.SetLastAppliedSpec(deployment, appliedDeploymentSpec(deployment)); err != nil {
+		return nil, err
+	}
+
+	kubeClient, err := c.ChildKubeClientSet(deployment.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return kubeClient.AppsV1().Deployments(deployment.Namespace).Create(deployment)
+}
 
-	return c.KubeClientSet.AppsV1().Deployments(deployment.Namespace).Create(deployment)
+// appliedDeploymentSpec returns the part of the spec of deployment we own.
+// Its scale is owned by the autoscaler.
+func appliedDeploymentSpec(deployment *appsv1.Deployment) *appsv1.DeploymentSpec {
+	spec := deployment.Spec.DeepCopy()
+	spec.Replicas = nil
+	return spec
 }
 
 func (c *Reconciler) checkAndUpdateDeployment(ctx context.Context, rev *v1alpha1.Revision, have *appsv1.Deployment) (*appsv1.Deployment, error) {
@@ -59,26 +77,40 @@ func (c *Reconciler) checkAndUpdateDeployment(ctx context.Context, rev *v1alpha1
 		cfgs.Deployment,
 	)
 
-	// Preserve the current scale of the Deployment.
-	deployment.Spec.Replicas = have.Spec.Replicas
-
 	// Preserve the label selector since it's immutable.
 	// TODO(dprotaso): determine other immutable properties.
 	deployment.Spec.Selector = have.Spec.Selector
 
+	// Apply the part of the spec we own onto the spec we have, leaving the
+	// fields we don't set, e.g. the current scale, alone.
+	spec := appliedDeploymentSpec(deployment)
+	have, err := c.restoreDeployment(have, spec)
+	if err != nil {
+		return nil, err
+	}
+	desiredDeployment := have.DeepCopy()
+	if err := presources.ApplySpec(have, have.Spec, spec, &desiredDeployment.Spec); err != nil {
+		return nil, err
+	}
+	if err := presources.SetLastAppliedSpec(desiredDeployment, spec); err != nil {
+		return nil, err
+	}
+
 	// If the spec we want is the spec we have, then we're good.
-	if equality.Semantic.DeepEqual(have.Spec, deployment.Spec) {
+	if equality.Semantic.DeepEqual(have.Spec, desiredDeployment.Spec) &&
+		equality.Semantic.DeepEqual(have.Annotations, desiredDeployment.Annotations) {
 		return have, nil
 	}
 
 	// Otherwise attempt an update (with ONLY the spec changes).
-	desiredDeployment := have.DeepCopy()
-	desiredDeployment.Spec = deployment.Spec
-
 	// Carry over new labels.
 	desiredDeployment.Labels = presources.UnionMaps(deployment.Labels, desiredDeployment.Labels)
 
-	d, err := c.KubeClientSet.AppsV1().Deployments(deployment.Namespace).Update(desiredDeployment)
+	kubeClient, err := c.ChildKubeClientSet(deployment.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	d, err := kubeClient.AppsV1().Deployments(deployment.Namespace).Update(desiredDeployment)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +130,20 @@ func (c *Reconciler) checkAndUpdateDeployment(ctx context.Context, rev *v1alpha1
 	return d, nil
 }
 
+// restoreDeployment returns have, from the informer, with the last applied
+// spec it was trimmed of restored. When the spec to apply isn't the last
+// applied one, the Deployment is fetched in full.
+func (c *Reconciler) restoreDeployment(have *appsv1.Deployment, spec *appsv1.DeploymentSpec) (*appsv1.Deployment, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	if restored, ok := informers.RestoreLastAppliedSpec(have, b); ok {
+		return restored, nil
+	}
+	return c.KubeClientSet.AppsV1().Deployments(have.Namespace).Get(have.Name, metav1.GetOptions{})
+}
+
 func (c *Reconciler) createImageCache(ctx context.Context, rev *v1alpha1.Revision) (*caching.Image, error) {
 	image := resources.MakeImageCache(rev)
 
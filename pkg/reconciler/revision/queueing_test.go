@@ -38,6 +38,7 @@ import (
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/network"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -177,6 +178,10 @@ func newTestController(t *testing.T) (
 				"panic-window":                            "10s",
 				"scale-to-zero-threshold":                 "10m",
 				"tick-interval":                           "2s",
+			}}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      tracingconfig.ConfigName,
 			}},
 	}
 	for _, configMap := range configs {
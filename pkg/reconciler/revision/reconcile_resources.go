@@ -18,7 +18,10 @@ package revision
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
@@ -26,19 +29,56 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/logging/logkey"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servinglogging "knative.dev/serving/pkg/logging"
+	"knative.dev/serving/pkg/queue"
+	"knative.dev/serving/pkg/reconciler/revision/config"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
 	resourcenames "knative.dev/serving/pkg/reconciler/revision/resources/names"
 )
 
+// queueProxyComponent is the component of the queue-proxy in the logging
+// config.
+const queueProxyComponent = "queueproxy"
+
 func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revision) error {
 	ns := rev.Namespace
 	deploymentName := resourcenames.Deployment(rev)
 	logger := logging.FromContext(ctx).With(zap.String(logkey.Deployment, deploymentName))
 
+	// Surface misconfigurations in the revision's status, there is no point
+	// in retrying until the config changes.
+	deploymentConfig := config.FromContext(ctx).Deployment
+	if resources.NodeOS(rev) == serving.NodeOSWindows && !deploymentConfig.EnableWindows {
+		rev.Status.MarkResourcesUnavailable("WindowsDisabled",
+			"Revision requests Windows nodes, but they are not enabled in config-deployment")
+		return nil
+	}
+	if resources.NodeOS(rev) == serving.NodeOSWindows && deploymentConfig.WindowsQueueSidecarImage == "" {
+		rev.Status.MarkResourcesUnavailable("WindowsQueueSidecarImageMissing",
+			"Revision requests Windows nodes, but no windowsQueueSidecarImage is set in config-deployment")
+		return nil
+	}
+	if !resources.NodeArchAllowed(rev, deploymentConfig) {
+		rev.Status.MarkResourcesUnavailable("ArchitectureNotAllowed", fmt.Sprintf(
+			"Revision requests %s nodes, but only %s are allowed in config-deployment",
+			resources.NodeArch(rev), strings.Join(deploymentConfig.Architectures.List(), ", ")))
+		return nil
+	}
+	if resources.GuaranteedWithSidecar(rev, deploymentConfig) {
+		rev.Status.MarkResourcesUnavailable("GuaranteedWithSidecar",
+			`Revision requests Guaranteed pods, but they get a mesh sidecar injected; set sidecar.istio.io/inject to "false"`)
+		return nil
+	}
+
 	deployment, err := c.deploymentLister.Deployments(ns).Get(deploymentName)
 	if apierrs.IsNotFound(err) {
 		// Deployment does not exist. Create it.
@@ -76,12 +116,25 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 
 			// Update the revision status if pod cannot be scheduled(possibly resource constraints)
 			// If pod cannot be scheduled then we expect the container status to be empty.
+			// Once the revision is ready, pods pending while it scales, e.g. up from
+			// zero until nodes are added, don't make it unready, for its Routes and
+			// Services not to flap: they are only reported in an event.
+			unschedulable := false
 			for _, cond := range pod.Status.Conditions {
 				if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
-					rev.Status.MarkResourcesUnavailable(cond.Reason, cond.Message)
+					unschedulable = true
+					if rev.Status.IsReady() {
+						c.reportFailure(rev, string(corev1.PodScheduled), cond.LastTransitionTime, cond.Reason,
+							"Pods of Revision %s can't be scheduled: %s", rev.Name, cond.Message)
+					} else {
+						rev.Status.MarkResourcesUnavailable(cond.Reason, cond.Message)
+					}
 					break
 				}
 			}
+			if !unschedulable {
+				c.clearFailure(rev, string(corev1.PodScheduled))
+			}
 
 			for _, status := range pod.Status.ContainerStatuses {
 				if status.Name == rev.Spec.GetContainer().Name {
@@ -98,6 +151,21 @@ func (c *Reconciler) reconcileDeployment(ctx context.Context, rev *v1alpha1.Revi
 		}
 	}
 
+	// Surface pods being rejected at admission, e.g. because they would exceed
+	// a ResourceQuota or violate a LimitRange in the namespace. Like the pods
+	// that can't be scheduled, they are only reported in an event once the
+	// revision is ready.
+	if cond := replicaFailure(deployment); cond != nil && rev.Status.IsReady() {
+		c.reportFailure(rev, string(appsv1.DeploymentReplicaFailure), cond.LastTransitionTime, cond.Reason,
+			"Pods of Revision %s can't be created: %s", rev.Name, cond.Message)
+	} else if cond != nil {
+		logger.Infof("%s marking resources exhausted with: %s: %s", rev.Name, cond.Reason, cond.Message)
+		rev.Status.MarkResourcesExhausted(cond.Reason, cond.Message)
+	} else {
+		c.clearFailure(rev, string(appsv1.DeploymentReplicaFailure))
+		rev.Status.MarkResourcesNotExhausted()
+	}
+
 	// Now that we have a Deployment, determine whether there is any relevant
 	// status to surface in the Revision.
 	if hasDeploymentTimedOut(deployment) && !rev.Status.IsActivationRequired() {
@@ -168,11 +236,16 @@ func (c *Reconciler) reconcilePA(ctx context.Context, rev *v1alpha1.Revision) er
 		}
 		// This change will trigger PA -> SKS -> K8s service change;
 		// and those after reconciliation will back progpagate here.
-		rev.Status.MarkDeploying("Updating")
+		// A ready revision stays ready meanwhile, as it keeps serving.
+		if !rev.Status.IsReady() {
+			rev.Status.MarkDeploying("Updating")
+		}
 	}
 
 	// Propagate the service name from the PA.
 	rev.Status.ServiceName = pa.Status.ServiceName
+	// And how long the revision has been idle.
+	rev.Status.IdleSince = pa.Status.IdleSince.DeepCopy()
 
 	// Reflect the PA status in our own.
 	cond := pa.Status.GetCondition(av1alpha1.PodAutoscalerConditionReady)
@@ -195,6 +268,102 @@ func (c *Reconciler) reconcilePA(ctx context.Context, rev *v1alpha1.Revision) er
 	return nil
 }
 
+// vpaRecommendationTolerance is how much, relative to the requests, the
+// recommendations of the VPA may differ before they are surfaced.
+const vpaRecommendationTolerance = 0.1
+
+func (c *Reconciler) reconcileVPA(ctx context.Context, rev *v1alpha1.Revision) error {
+	interval := config.FromContext(ctx).Deployment.VPARecommendationInterval
+	if interval == 0 {
+		return nil
+	}
+	ns := rev.Namespace
+	vpaName := resourcenames.VPA(rev)
+	logger := logging.FromContext(ctx)
+
+	client := c.DynamicClientSet.Resource(resources.VPAGVR).Namespace(ns)
+	vpa, err := client.Get(vpaName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		// VPA does not exist. Create it.
+		if _, err := client.Create(resources.MakeVPA(rev), metav1.CreateOptions{}); err != nil {
+			logger.Errorf("Error creating VPA %q: %v", vpaName, err)
+			return err
+		}
+		logger.Infof("Created VPA %q", vpaName)
+	} else if err != nil {
+		logger.Errorf("Error reconciling VPA %q: %v", vpaName, err)
+		return err
+	} else if !metav1.IsControlledBy(vpa, rev) {
+		// Surface an error in the revision's status, and return an error.
+		rev.Status.MarkResourceNotOwned("VerticalPodAutoscaler", vpaName)
+		return fmt.Errorf("revision: %q does not own VerticalPodAutoscaler: %q", rev.Name, vpaName)
+	} else if recs, err := resources.VPARecommendations(vpa); err != nil {
+		logger.Warnw("Failed to read the VPA recommendations", zap.Error(err))
+	} else {
+		container := rev.Spec.GetContainer()
+		if rec, ok := recs[container.Name]; ok && resources.RecommendationDiffers(
+			container.Resources.Requests, rec, vpaRecommendationTolerance) {
+			c.Recorder.Eventf(rev, corev1.EventTypeNormal, "ResourceRecommendation",
+				"VPA recommends requests of %s for container %q, which requests %s",
+				formatResources(rec), container.Name, formatResources(container.Resources.Requests))
+		}
+	}
+
+	// We don't watch the VPAs, so check back for new recommendations.
+	c.enqueueAfter(rev, interval)
+	return nil
+}
+
+// formatResources formats a ResourceList as a sorted list of name=quantity.
+func formatResources(rl corev1.ResourceList) string {
+	if len(rl) == 0 {
+		return "nothing"
+	}
+	res := make([]string, 0, len(rl))
+	for name, q := range rl {
+		res = append(res, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	sort.Strings(res)
+	return strings.Join(res, ", ")
+}
+
+// replicaFailure returns the ReplicaFailure condition of the Deployment if
+// its pods are failing to be created, and nil otherwise.
+// reportFailure records a warning event about the pods of the revision,
+// caused by the condition cond that last transitioned at transition. The
+// event is recorded once per transition of the condition, rather than on
+// every reconcile.
+func (c *Reconciler) reportFailure(rev *v1alpha1.Revision, cond string, transition metav1.Time, reason, messageFmt string, args ...interface{}) {
+	// A Revision is never reconciled concurrently, so its own map needs no
+	// locking.
+	key := types.NamespacedName{Namespace: rev.Namespace, Name: rev.Name}
+	v, _ := c.reportedFailures.LoadOrStore(key, map[string]metav1.Time{})
+	reported := v.(map[string]metav1.Time)
+	if last, ok := reported[cond]; ok && last.Equal(&transition) {
+		return
+	}
+	reported[cond] = transition
+	c.Recorder.Eventf(rev, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
+
+// clearFailure forgets the condition of the pods of the revision reported by
+// reportFailure, once it no longer holds.
+func (c *Reconciler) clearFailure(rev *v1alpha1.Revision, cond string) {
+	if v, ok := c.reportedFailures.Load(types.NamespacedName{Namespace: rev.Namespace, Name: rev.Name}); ok {
+		delete(v.(map[string]metav1.Time), cond)
+	}
+}
+
+func replicaFailure(deployment *appsv1.Deployment) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		cond := &deployment.Status.Conditions[i]
+		if cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue {
+			return cond
+		}
+	}
+	return nil
+}
+
 func hasDeploymentTimedOut(deployment *appsv1.Deployment) bool {
 	// as per https://kubernetes.io/docs/concepts/workloads/controllers/deployment
 	for _, cond := range deployment.Status.Conditions {
@@ -210,3 +379,229 @@ func hasDeploymentTimedOut(deployment *appsv1.Deployment) bool {
 	}
 	return false
 }
+
+// reconcileQueueLogLevel annotates the pods of the revision with the log
+// level the logging config sets for their queue-proxies, which pick it up
+// without being restarted. Only the revisions with such an override have their
+// pods listed; their Deployment carries the annotation as well, so that the
+// pods are rid of it once the override is gone. The override is part of the
+// data-plane configuration instead, when enabled.
+func (c *Reconciler) reconcileQueueLogLevel(ctx context.Context, rev *v1alpha1.Revision) error {
+	cfgs := config.FromContext(ctx)
+	want := ""
+	if level, ok := servinglogging.LevelOverride(cfgs.Logging, queueProxyComponent, rev.Namespace, rev.Name); ok && !cfgs.Deployment.EnableDataPlaneConfig {
+		want = level.String()
+	}
+
+	deployment, err := c.deploymentLister.Deployments(rev.Namespace).Get(resourcenames.Deployment(rev))
+	if apierrs.IsNotFound(err) {
+		// The Deployment was just created, it will be reconciled again.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	have, annotated := deployment.Annotations[serving.QueueLogLevelAnnotationKey]
+	if !annotated && want == "" {
+		return nil
+	}
+
+	pods, err := c.KubeClientSet.CoreV1().Pods(rev.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{serving.RevisionLabelKey: rev.Name}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	patch, err := queueLogLevelPatch(want)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if level, ok := pod.Annotations[serving.QueueLogLevelAnnotationKey]; ok == (want != "") && level == want {
+			continue
+		}
+		_, err := c.KubeClientSet.CoreV1().Pods(pod.Namespace).Patch(pod.Name, types.MergePatchType, patch)
+		if err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if annotated == (want != "") && have == want {
+		return nil
+	}
+	deployment = deployment.DeepCopy()
+	if want == "" {
+		delete(deployment.Annotations, serving.QueueLogLevelAnnotationKey)
+	} else {
+		if deployment.Annotations == nil {
+			deployment.Annotations = make(map[string]string, 1)
+		}
+		deployment.Annotations[serving.QueueLogLevelAnnotationKey] = want
+	}
+	kubeClient, err := c.ChildKubeClientSet(deployment.Namespace)
+	if err != nil {
+		return err
+	}
+	_, err = kubeClient.AppsV1().Deployments(deployment.Namespace).Update(deployment)
+	return err
+}
+
+// reconcileDataPlaneConfig keeps the ConfigMap holding the data-plane
+// configuration of the revision in line with its timeout and rate limit
+// annotations and with the queue-proxy settings of the logging,
+// observability and tracing configs, when enabled. The queue-proxies pick
+// the changes up without being restarted.
+func (c *Reconciler) reconcileDataPlaneConfig(ctx context.Context, rev *v1alpha1.Revision) error {
+	cfgs := config.FromContext(ctx)
+	if !cfgs.Deployment.EnableDataPlaneConfig {
+		return nil
+	}
+	key, err := c.dataPlaneKey(rev)
+	if err != nil {
+		return err
+	}
+	want, err := resources.MakeDataPlaneConfig(rev, key, cfgs.Logging, cfgs.Observability, cfgs.Tracing)
+	if err != nil {
+		return err
+	}
+
+	have, err := c.configMapLister.ConfigMaps(rev.Namespace).Get(want.Name)
+	if apierrs.IsNotFound(err) {
+		_, err = c.KubeClientSet.CoreV1().ConfigMaps(rev.Namespace).Create(want)
+		return err
+	} else if err != nil {
+		return err
+	} else if !metav1.IsControlledBy(have, rev) {
+		rev.Status.MarkResourceNotOwned("ConfigMap", want.Name)
+		return fmt.Errorf("revision: %q does not own ConfigMap: %q", rev.Name, want.Name)
+	}
+	if equality.Semantic.DeepEqual(have.Data, want.Data) {
+		return nil
+	}
+	have = have.DeepCopy()
+	have.Data = want.Data
+	_, err = c.KubeClientSet.CoreV1().ConfigMaps(have.Namespace).Update(have)
+	return err
+}
+
+// reconcileSecret keeps the Secret holding the key the network probes of the
+// pods of the revision are signed with, the registration token of the
+// revision, when the queue-proxies register with the activators, and the
+// key its data-plane configuration is signed with, when enabled. The key
+// the tokens are derived from is created on first use, and so are the probe
+// and data-plane keys, which are kept for the life of the revision.
+func (c *Reconciler) reconcileSecret(ctx context.Context, rev *v1alpha1.Revision) error {
+	cfg := config.FromContext(ctx).Deployment
+	name := resourcenames.Secret(rev)
+	have, err := c.secretLister.Secrets(rev.Namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		have = nil
+	} else if err != nil {
+		return err
+	} else if !metav1.IsControlledBy(have, rev) {
+		rev.Status.MarkResourceNotOwned("Secret", name)
+		return fmt.Errorf("revision: %q does not own Secret: %q", rev.Name, name)
+	}
+
+	var probeKey, registrationKey, dataPlaneKey []byte
+	if have != nil {
+		probeKey = have.Data[queue.ProbeKeyKey]
+	}
+	if len(probeKey) == 0 {
+		newProbeKey := c.newProbeKey
+		if newProbeKey == nil {
+			newProbeKey = resources.MakeProbeKey
+		}
+		if probeKey, err = newProbeKey(); err != nil {
+			return err
+		}
+	}
+	if cfg.EnableActivatorRegistration {
+		if registrationKey, err = c.registrationKey(); err != nil {
+			return err
+		}
+	}
+	if cfg.EnableDataPlaneConfig {
+		if have != nil {
+			dataPlaneKey = have.Data[queue.DataPlaneKeyKey]
+		}
+		if len(dataPlaneKey) == 0 {
+			if dataPlaneKey, err = resources.MakeDataPlaneKey(); err != nil {
+				return err
+			}
+		}
+	}
+	want := resources.MakeSecret(rev, probeKey, registrationKey, dataPlaneKey)
+
+	if have == nil {
+		_, err = c.KubeClientSet.CoreV1().Secrets(rev.Namespace).Create(want)
+		return err
+	}
+	if equality.Semantic.DeepEqual(have.Data, want.Data) {
+		return nil
+	}
+	have = have.DeepCopy()
+	have.Data = want.Data
+	_, err = c.KubeClientSet.CoreV1().Secrets(have.Namespace).Update(have)
+	return err
+}
+
+// dataPlaneKey returns the key the data-plane configuration of the revision
+// is signed with, out of its Secret.
+func (c *Reconciler) dataPlaneKey(rev *v1alpha1.Revision) ([]byte, error) {
+	name := resourcenames.Secret(rev)
+	secret, err := c.secretLister.Secrets(rev.Namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		// Just created by reconcileSecret, and not in the informer's cache yet.
+		secret, err = c.KubeClientSet.CoreV1().Secrets(rev.Namespace).Get(name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	key := secret.Data[queue.DataPlaneKeyKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", name, queue.DataPlaneKeyKey)
+	}
+	return key, nil
+}
+
+// registrationKey returns the key the registration tokens are derived
+// from, creating it if it doesn't exist yet.
+func (c *Reconciler) registrationKey() ([]byte, error) {
+	secret, err := c.secretLister.Secrets(system.Namespace()).Get(activator.RegistrationKeySecretName)
+	if apierrs.IsNotFound(err) {
+		want, err := resources.MakeRegistrationKey()
+		if err != nil {
+			return nil, err
+		}
+		secret, err = c.KubeClientSet.CoreV1().Secrets(want.Namespace).Create(want)
+		if apierrs.IsAlreadyExists(err) {
+			secret, err = c.KubeClientSet.CoreV1().Secrets(want.Namespace).Get(want.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	key := secret.Data[activator.RegistrationKeySecretKey]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", activator.RegistrationKeySecretName, activator.RegistrationKeySecretKey)
+	}
+	return key, nil
+}
+
+// queueLogLevelPatch returns the merge patch setting the log level annotation
+// of a pod to level, or removing it if level is empty.
+func queueLogLevelPatch(level string) ([]byte, error) {
+	var value interface{}
+	if level != "" {
+		value = level
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				serving.QueueLogLevelAnnotationKey: value,
+			},
+		},
+	})
+}
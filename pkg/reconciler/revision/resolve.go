@@ -17,6 +17,7 @@ limitations under the License.
 package revision
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -34,8 +35,30 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/injection/clients/kubeclient"
+	"knative.dev/pkg/logging"
 )
 
+// Resolver resolves the image references that use tags to digests.
+type Resolver interface {
+	Resolve(string, k8schain.Options, sets.String) (string, error)
+}
+
+// NewResolver creates a Resolver authenticating to registries like the
+// kubelet would, and trusting the certificates the cluster trusts.
+func NewResolver(ctx context.Context) Resolver {
+	transport := http.DefaultTransport
+	if rt, err := newResolverTransport(k8sCertPath); err != nil {
+		logging.FromContext(ctx).Errorf("Failed to create resolver transport: %v", err)
+	} else {
+		transport = rt
+	}
+	return &digestResolver{
+		client:    kubeclient.Get(ctx),
+		transport: transport,
+	}
+}
+
 type digestResolver struct {
 	client    kubernetes.Interface
 	transport http.RoundTripper
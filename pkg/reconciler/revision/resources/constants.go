@@ -25,6 +25,8 @@ const (
 	QueueContainerName = "queue-proxy"
 
 	sidecarIstioInjectAnnotation = "sidecar.istio.io/inject"
+	sidecarProxyCPUAnnotation    = "sidecar.istio.io/proxyCPU"
+	sidecarProxyMemoryAnnotation = "sidecar.istio.io/proxyMemory"
 	// IstioOutboundIPRangeAnnotation defines the outbound ip ranges istio allows.
 	// TODO(mattmoor): Make this private once we remove revision_test.go
 	IstioOutboundIPRangeAnnotation = "traffic.sidecar.istio.io/includeOutboundIPRanges"
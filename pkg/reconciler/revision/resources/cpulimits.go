@@ -0,0 +1,58 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/serving/pkg/deployment"
+)
+
+// applyCPULimits adjusts the CPU limits of the containers according to the
+// given deployment.Config CPULimits policy. Limits are only ever removed or
+// derived from the CPU requests, the requests are never changed. Limits set
+// by the user are never overridden by derived ones.
+func applyCPULimits(containers []corev1.Container, policy string, containerConcurrency int64) {
+	for i := range containers {
+		c := &containers[i]
+		switch policy {
+		case deployment.CPULimitsOmit:
+			removeCPULimit(c)
+		case deployment.CPULimitsScaleWithConcurrency:
+			if _, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+				continue
+			}
+			request, ok := c.Resources.Requests[corev1.ResourceCPU]
+			// Unlimited concurrency gives no bound to scale with.
+			if !ok || request.IsZero() || containerConcurrency == 0 {
+				continue
+			}
+			if c.Resources.Limits == nil {
+				c.Resources.Limits = corev1.ResourceList{}
+			}
+			c.Resources.Limits[corev1.ResourceCPU] = *resource.NewMilliQuantity(
+				request.MilliValue()*containerConcurrency, request.Format)
+		}
+	}
+}
+
+func removeCPULimit(c *corev1.Container) {
+	delete(c.Resources.Limits, corev1.ResourceCPU)
+	if len(c.Resources.Limits) == 0 {
+		c.Resources.Limits = nil
+	}
+}
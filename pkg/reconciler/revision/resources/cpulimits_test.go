@@ -0,0 +1,112 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/serving/pkg/deployment"
+)
+
+func TestApplyCPULimits(t *testing.T) {
+	container := func(request, limit string) corev1.Container {
+		c := corev1.Container{}
+		if request != "" {
+			c.Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(request)}
+		}
+		if limit != "" {
+			c.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(limit)}
+		}
+		return c
+	}
+	withMemoryLimit := func(c corev1.Container) corev1.Container {
+		if c.Resources.Limits == nil {
+			c.Resources.Limits = corev1.ResourceList{}
+		}
+		c.Resources.Limits[corev1.ResourceMemory] = resource.MustParse("1Gi")
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		cc     int64
+		in     []corev1.Container
+		want   []corev1.Container
+	}{{
+		name:   "default keeps limits",
+		policy: "",
+		cc:     10,
+		in:     []corev1.Container{container("100m", "1"), container("25m", "")},
+		want:   []corev1.Container{container("100m", "1"), container("25m", "")},
+	}, {
+		name:   "keep",
+		policy: deployment.CPULimitsKeep,
+		cc:     10,
+		in:     []corev1.Container{container("100m", "1"), container("25m", "")},
+		want:   []corev1.Container{container("100m", "1"), container("25m", "")},
+	}, {
+		name:   "omit",
+		policy: deployment.CPULimitsOmit,
+		cc:     10,
+		in:     []corev1.Container{container("100m", "1"), container("25m", "40m")},
+		want:   []corev1.Container{container("100m", ""), container("25m", "")},
+	}, {
+		name:   "omit keeps memory limits",
+		policy: deployment.CPULimitsOmit,
+		in:     []corev1.Container{withMemoryLimit(container("100m", "1"))},
+		want:   []corev1.Container{withMemoryLimit(container("100m", ""))},
+	}, {
+		name:   "scale with concurrency",
+		policy: deployment.CPULimitsScaleWithConcurrency,
+		cc:     4,
+		in:     []corev1.Container{container("100m", ""), container("25m", "")},
+		want:   []corev1.Container{container("100m", "400m"), container("25m", "100m")},
+	}, {
+		name:   "scale with concurrency keeps explicit limit",
+		policy: deployment.CPULimitsScaleWithConcurrency,
+		cc:     4,
+		in:     []corev1.Container{container("100m", "1"), container("25m", "")},
+		want:   []corev1.Container{container("100m", "1"), container("25m", "100m")},
+	}, {
+		name:   "scale with concurrency without request",
+		policy: deployment.CPULimitsScaleWithConcurrency,
+		cc:     4,
+		in:     []corev1.Container{container("", "1")},
+		want:   []corev1.Container{container("", "1")},
+	}, {
+		name:   "scale with unlimited concurrency",
+		policy: deployment.CPULimitsScaleWithConcurrency,
+		cc:     0,
+		in:     []corev1.Container{container("100m", "1"), container("25m", "")},
+		want:   []corev1.Container{container("100m", "1"), container("25m", "")},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applyCPULimits(test.in, test.policy, test.cc)
+			if diff := cmp.Diff(test.want, test.in, cmp.Comparer(func(a, b resource.Quantity) bool {
+				return a.Cmp(b) == 0
+			})); diff != "" {
+				t.Errorf("applyCPULimits (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	servinglogging "knative.dev/serving/pkg/logging"
+	"knative.dev/serving/pkg/metrics"
+	"knative.dev/serving/pkg/queue"
+	"knative.dev/serving/pkg/reconciler/revision/resources/names"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+const dataPlaneConfigVolumeName = "knative-dataplane"
+
+var dataPlaneConfigVolumeMount = corev1.VolumeMount{
+	Name:      dataPlaneConfigVolumeName,
+	MountPath: queue.DataPlaneConfigVolumePath,
+	ReadOnly:  true,
+}
+
+// dataPlaneAnnotations are the annotations of a revision that its
+// queue-proxies read from the data-plane configuration, rather than from
+// their environment, when the data-plane configuration is enabled. They
+// are kept off the pods, so that changing them doesn't restart them.
+var dataPlaneAnnotations = sets.NewString(
+	serving.RateLimitAnnotationKey,
+	serving.RateLimitBurstAnnotationKey,
+	serving.TimeoutOverrideAnnotationKey,
+	serving.TracingSampleRateAnnotationKey,
+)
+
+// MakeDataPlaneConfig makes the ConfigMap holding the data-plane
+// configuration of the revision, signed with key, which its queue-proxies
+// read from the Secret of the revision.
+func MakeDataPlaneConfig(rev *v1alpha1.Revision, key []byte, loggingConfig *logging.Config,
+	observabilityConfig *metrics.ObservabilityConfig, tracingConfig *tracingconfig.Config) (*corev1.ConfigMap, error) {
+	cfg := dataPlaneConfig(rev)
+	if level, ok := servinglogging.LevelOverride(loggingConfig, "queueproxy", rev.Namespace, rev.Name); ok {
+		cfg.LogLevel = level.String()
+	}
+	cfg.RequestMetricsBackend = observabilityConfig.RequestMetricsBackend
+	if tracingConfig != nil {
+		cfg.Tracing = *tracingConfig.ForRevision(rev.Namespace, rev.Name)
+		if rate, ok := rev.TracingSampleRate(); ok {
+			cfg.Tracing.SampleRate = rate
+		}
+	}
+	data, signature, err := queue.EncodeDataPlaneConfig(cfg, key)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            names.DataPlaneConfig(rev),
+			Namespace:       rev.Namespace,
+			Labels:          makeLabels(rev),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+		},
+		Data: map[string]string{
+			queue.DataPlaneConfigKey:    string(data),
+			queue.DataPlaneSignatureKey: string(signature),
+		},
+	}, nil
+}
+
+// dataPlaneConfig returns the data-plane configuration set by the
+// annotations of the revision, which are validated in the webhook.
+func dataPlaneConfig(rev *v1alpha1.Revision) *queue.DataPlaneConfig {
+	cfg := &queue.DataPlaneConfig{}
+	if rev.Spec.TimeoutSeconds != nil {
+		cfg.TimeoutSeconds = *rev.Spec.TimeoutSeconds
+	}
+	if v, ok := rev.Annotations[serving.TimeoutOverrideAnnotationKey]; ok {
+		cfg.TimeoutSeconds, _ = strconv.ParseInt(v, 10, 64)
+	}
+	if v, ok := rev.Annotations[serving.RateLimitAnnotationKey]; ok {
+		cfg.RateLimit, _ = strconv.ParseFloat(v, 64)
+		if v, ok := rev.Annotations[serving.RateLimitBurstAnnotationKey]; ok {
+			cfg.RateLimitBurst, _ = strconv.Atoi(v)
+		}
+	}
+	return cfg
+}
+
+// applyDataPlaneConfig mounts the ConfigMap holding the data-plane
+// configuration of the revision into the queue-proxy. The ConfigMap is
+// optional, the queue-proxy keeps the configuration of its environment
+// until the ConfigMap shows up.
+func applyDataPlaneConfig(podSpec *corev1.PodSpec, rev *v1alpha1.Revision) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: dataPlaneConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: names.DataPlaneConfig(rev)},
+				Optional:             ptr.Bool(true),
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name == QueueContainerName {
+			c.VolumeMounts = append(c.VolumeMounts, dataPlaneConfigVolumeMount)
+		}
+	}
+}
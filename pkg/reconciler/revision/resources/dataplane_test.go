@@ -0,0 +1,182 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/metrics"
+	"knative.dev/serving/pkg/queue"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
+)
+
+func TestMakeDataPlaneConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		lc          *logging.Config
+		oc          *metrics.ObservabilityConfig
+		tc          *tracingconfig.Config
+		want        queue.DataPlaneConfig
+	}{{
+		name: "spec timeout",
+		want: queue.DataPlaneConfig{TimeoutSeconds: 300},
+	}, {
+		name: "timeout override",
+		annotations: map[string]string{
+			serving.TimeoutOverrideAnnotationKey: "42",
+		},
+		want: queue.DataPlaneConfig{TimeoutSeconds: 42},
+	}, {
+		name: "rate limit",
+		annotations: map[string]string{
+			serving.RateLimitAnnotationKey:      "2.5",
+			serving.RateLimitBurstAnnotationKey: "10",
+		},
+		want: queue.DataPlaneConfig{TimeoutSeconds: 300, RateLimit: 2.5, RateLimitBurst: 10},
+	}, {
+		name: "queue-proxy settings",
+		lc: &logging.Config{
+			LoggingLevel: map[string]zapcore.Level{"queueproxy.foo.bar": zapcore.DebugLevel},
+		},
+		oc: &metrics.ObservabilityConfig{RequestMetricsBackend: "prometheus"},
+		tc: &tracingconfig.Config{Enable: true, ZipkinEndpoint: "http://zipkin", SampleRate: 0.5},
+		want: queue.DataPlaneConfig{
+			TimeoutSeconds:        300,
+			LogLevel:              "debug",
+			RequestMetricsBackend: "prometheus",
+			Tracing:               tracingconfig.Config{Enable: true, ZipkinEndpoint: "http://zipkin", SampleRate: 0.5},
+		},
+	}, {
+		name: "tracing overrides",
+		tc: &tracingconfig.Config{
+			Enable:                  true,
+			ZipkinEndpoint:          "http://zipkin",
+			SampleRate:              0.5,
+			SampleRateOverrides:     map[string]float64{"foo": 0.001},
+			ZipkinEndpointOverrides: map[string]string{"foo.bar": "http://zipkin.foo"},
+		},
+		want: queue.DataPlaneConfig{
+			TimeoutSeconds: 300,
+			Tracing:        tracingconfig.Config{Enable: true, ZipkinEndpoint: "http://zipkin.foo", SampleRate: 0.001},
+		},
+	}, {
+		name: "tracing sample rate annotation",
+		annotations: map[string]string{
+			serving.TracingSampleRateAnnotationKey: "1",
+		},
+		tc: &tracingconfig.Config{
+			Enable:              true,
+			ZipkinEndpoint:      "http://zipkin",
+			SampleRate:          0.5,
+			SampleRateOverrides: map[string]float64{"foo": 0.001},
+		},
+		want: queue.DataPlaneConfig{
+			TimeoutSeconds: 300,
+			Tracing:        tracingconfig.Config{Enable: true, ZipkinEndpoint: "http://zipkin", SampleRate: 1},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   "foo",
+					Name:        "bar",
+					UID:         "1234",
+					Annotations: test.annotations,
+				},
+				Spec: v1alpha1.RevisionSpec{
+					RevisionSpec: v1beta1.RevisionSpec{
+						TimeoutSeconds: ptr.Int64(300),
+					},
+				},
+			}
+
+			lc, oc := test.lc, test.oc
+			if lc == nil {
+				lc = &logging.Config{}
+			}
+			if oc == nil {
+				oc = &metrics.ObservabilityConfig{}
+			}
+			got, err := MakeDataPlaneConfig(rev, []byte("key"), lc, oc, test.tc)
+			if err != nil {
+				t.Fatalf("MakeDataPlaneConfig() = %v", err)
+			}
+			wantMeta := metav1.ObjectMeta{
+				Namespace:       "foo",
+				Name:            "bar-dataplane",
+				Labels:          makeLabels(rev),
+				OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+			}
+			if diff := cmp.Diff(wantMeta, got.ObjectMeta); diff != "" {
+				t.Errorf("ObjectMeta (-want, +got) = %v", diff)
+			}
+			cfg, err := queue.DecodeDataPlaneConfig([]byte(got.Data[queue.DataPlaneConfigKey]),
+				[]byte(got.Data[queue.DataPlaneSignatureKey]), []byte("key"))
+			if err != nil {
+				t.Fatalf("DecodeDataPlaneConfig() = %v", err)
+			}
+			if diff := cmp.Diff(test.want, *cfg); diff != "" {
+				t.Errorf("DataPlaneConfig (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestApplyDataPlaneConfig(t *testing.T) {
+	got := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "user-container",
+		}, {
+			Name: QueueContainerName,
+		}},
+	}
+	applyDataPlaneConfig(&got, &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+
+	want := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "user-container",
+		}, {
+			Name:         QueueContainerName,
+			VolumeMounts: []corev1.VolumeMount{dataPlaneConfigVolumeMount},
+		}},
+		Volumes: []corev1.Volume{{
+			Name: dataPlaneConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "foo-dataplane"},
+					Optional:             ptr.Bool(true),
+				},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("applyDataPlaneConfig() (-want, +got) = %v", diff)
+	}
+}
@@ -44,6 +44,7 @@ const (
 	varLogVolumePath   = "/var/log"
 	internalVolumeName = "knative-internal"
 	internalVolumePath = "/var/knative-internal"
+	podInfoVolumeName  = "knative-podinfo"
 )
 
 var (
@@ -71,6 +72,29 @@ var (
 		MountPath: internalVolumePath,
 	}
 
+	// podInfoVolume projects the annotations of the pod into the
+	// queue-proxy, which picks its log level up from there when the
+	// controller overrides it.
+	podInfoVolume = corev1.Volume{
+		Name: podInfoVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{{
+					Path: queue.PodAnnotationsFile,
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.annotations",
+					},
+				}},
+			},
+		},
+	}
+
+	podInfoVolumeMount = corev1.VolumeMount{
+		Name:      podInfoVolumeName,
+		MountPath: queue.PodInfoVolumePath,
+		ReadOnly:  true,
+	}
+
 	// This PreStop hook is actually calling an endpoint on the queue-proxy
 	// because of the way PreStop hooks are called by kubelet. We use this
 	// to block the user-container from exiting before the queue-proxy is ready
@@ -91,10 +115,10 @@ func rewriteUserProbe(p *corev1.Probe, userPort int) {
 	}
 	switch {
 	case p.HTTPGet != nil:
-		// For HTTP probes, we route them through the queue container
-		// so that we know the queue proxy is ready/live as well.
-		// It doesn't matter to which queue serving port we are forwarding the probe.
-		p.HTTPGet.Port = intstr.FromInt(networking.BackendHTTPPort)
+		// For HTTP probes, we route them through the admin port of the queue
+		// container, so that we know the queue proxy is live as well, and
+		// they don't go through the handlers of the requests.
+		p.HTTPGet.Port = intstr.FromInt(networking.QueueAdminPort)
 		// With mTLS enabled, Istio rewrites probes, but doesn't spoof the kubelet
 		// user agent, so we need to inject an extra header to be able to distinguish
 		// between probes and real requests.
@@ -117,8 +141,8 @@ func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, observab
 	userPort := getUserPort(rev)
 	userPortInt := int(userPort)
 	userPortStr := strconv.Itoa(userPortInt)
-	// Replacement is safe as only up to a single port is allowed on the Revision
-	userContainer.Ports = buildContainerPorts(userPort)
+	// The first port is the one serving the requests, the others are kept as they are.
+	userContainer.Ports = buildContainerPorts(userPort, rev.GetPorts())
 	userContainer.Env = append(userContainer.Env, buildUserPortEnv(userPortStr))
 	userContainer.Env = append(userContainer.Env, getKnativeEnvVar(rev)...)
 	// Explicitly disable stdin and tty allocation
@@ -145,15 +169,37 @@ func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, observab
 	// If the client provides probes, we should fill in the port for them.
 	rewriteUserProbe(userContainer.LivenessProbe, userPortInt)
 
+	queueContainer := makeQueueContainer(rev, loggingConfig, observabilityConfig, autoscalerConfig, deploymentConfig)
+	queueContainer.VolumeMounts = append([]corev1.VolumeMount{podInfoVolumeMount}, queueContainer.VolumeMounts...)
+	containers := []corev1.Container{
+		*userContainer,
+		*queueContainer,
+	}
+	applyCPULimits(containers, deploymentConfig.CPULimits, int64(rev.Spec.ContainerConcurrency))
+	// The QoS class chosen for the revision takes precedence over the
+	// cluster-wide CPU limit policy.
+	applyQoSClass(containers, rev.Annotations[serving.QoSClassAnnotationKey])
+
 	podSpec := &corev1.PodSpec{
-		Containers: []corev1.Container{
-			*userContainer,
-			*makeQueueContainer(rev, loggingConfig, observabilityConfig, autoscalerConfig, deploymentConfig),
-		},
-		Volumes:                       append([]corev1.Volume{varLogVolume}, rev.Spec.Volumes...),
+		Containers:                    containers,
+		Volumes:                       append([]corev1.Volume{varLogVolume, podInfoVolume}, rev.Spec.Volumes...),
 		ServiceAccountName:            rev.Spec.ServiceAccountName,
+		AutomountServiceAccountToken:  rev.Spec.AutomountServiceAccountToken,
 		TerminationGracePeriodSeconds: rev.Spec.TimeoutSeconds,
+		DNSPolicy:                     rev.Spec.DNSPolicy,
+		DNSConfig:                     rev.Spec.DNSConfig,
+		HostAliases:                   rev.Spec.HostAliases,
+		PriorityClassName:             deploymentConfig.PriorityClassName,
+	}
+
+	applyNodeOS(podSpec, rev, deploymentConfig)
+	applyNodeArch(podSpec, rev, deploymentConfig)
+	applyUserSocket(podSpec, rev)
+	applyOpenAPISchema(podSpec, rev)
+	if deploymentConfig.EnableDataPlaneConfig {
+		applyDataPlaneConfig(podSpec, rev)
 	}
+	applySecret(podSpec, rev)
 
 	// Add the Knative internal volume only if /var/log collection is enabled
 	if observabilityConfig.EnableVarLogCollection {
@@ -166,8 +212,10 @@ func makePodSpec(rev *v1alpha1.Revision, loggingConfig *logging.Config, observab
 func getUserPort(rev *v1alpha1.Revision) int32 {
 	ports := rev.Spec.GetContainer().Ports
 
-	if len(ports) > 0 && ports[0].ContainerPort != 0 {
-		return ports[0].ContainerPort
+	if len(ports) > 0 {
+		if port := ports[serving.ServingPortIndex(ports)].ContainerPort; port != 0 {
+			return port
+		}
 	}
 
 	//TODO(#2258): Use container EXPOSE metadata from image before falling back to default value
@@ -175,11 +223,19 @@ func getUserPort(rev *v1alpha1.Revision) int32 {
 	return v1alpha1.DefaultUserPort
 }
 
-func buildContainerPorts(userPort int32) []corev1.ContainerPort {
-	return []corev1.ContainerPort{{
+func buildContainerPorts(userPort int32, named []networking.NamedPort) []corev1.ContainerPort {
+	ports := []corev1.ContainerPort{{
 		Name:          v1alpha1.UserPortName,
 		ContainerPort: userPort,
 	}}
+	for _, p := range named {
+		ports = append(ports, corev1.ContainerPort{
+			Name:          p.Name,
+			ContainerPort: p.Port,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	return ports
 }
 
 func buildUserPortEnv(userPort string) corev1.EnvVar {
@@ -189,19 +245,65 @@ func buildUserPortEnv(userPort string) corev1.EnvVar {
 	}
 }
 
+// applyForwardedHeaders tells the queue-proxy how to treat the forwarded
+// headers of the requests it receives, unless it's the default.
+func applyForwardedHeaders(podSpec *corev1.PodSpec, networkConfig *network.Config) {
+	var env []corev1.EnvVar
+	if p := networkConfig.ForwardedForPolicy; p != "" && p != network.ForwardedForAppend {
+		env = append(env, corev1.EnvVar{
+			Name:  "FORWARDED_FOR_POLICY",
+			Value: string(p),
+		})
+	}
+	if p := networkConfig.ForwardedHeaders; p != "" && p != network.ForwardedHeadersTrust {
+		env = append(env, corev1.EnvVar{
+			Name:  "FORWARDED_HEADERS",
+			Value: string(p),
+		})
+	}
+	if networkConfig.TrustedHops > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "TRUSTED_HOPS",
+			Value: strconv.Itoa(networkConfig.TrustedHops),
+		})
+	}
+	for i := range podSpec.Containers {
+		if c := &podSpec.Containers[i]; c.Name == QueueContainerName {
+			c.Env = append(c.Env, env...)
+		}
+	}
+}
+
 // MakeDeployment constructs a K8s Deployment resource from a revision.
 func MakeDeployment(rev *v1alpha1.Revision,
 	loggingConfig *logging.Config, networkConfig *network.Config, observabilityConfig *metrics.ObservabilityConfig,
 	autoscalerConfig *autoscaler.Config, deploymentConfig *deployment.Config) *appsv1.Deployment {
 
 	podTemplateAnnotations := resources.FilterMap(rev.GetAnnotations(), func(k string) bool {
-		return k == serving.RevisionLastPinnedAnnotationKey
+		return k == serving.RevisionLastPinnedAnnotationKey ||
+			(deploymentConfig.EnableDataPlaneConfig && dataPlaneAnnotations.Has(k))
 	})
 
-	// TODO(nghia): Remove the need for this
-	// Only force-set the inject annotation if the revision does not state otherwise.
+	// Only set the sidecar annotations if the revision does not state otherwise.
+	// The queue-proxy readiness probe is an exec probe and the user container
+	// is probed by the queue-proxy over localhost, so neither is affected by
+	// the mesh rewriting or intercepting HTTP probes.
 	if _, ok := podTemplateAnnotations[sidecarIstioInjectAnnotation]; !ok {
-		podTemplateAnnotations[sidecarIstioInjectAnnotation] = "true"
+		switch deploymentConfig.SidecarInject {
+		case deployment.SidecarInjectNamespaceDefault:
+		case "":
+			podTemplateAnnotations[sidecarIstioInjectAnnotation] = deployment.SidecarInjectEnabled
+		default:
+			podTemplateAnnotations[sidecarIstioInjectAnnotation] = deploymentConfig.SidecarInject
+		}
+	}
+	for annotation, value := range map[string]string{
+		sidecarProxyCPUAnnotation:    deploymentConfig.SidecarProxyCPU,
+		sidecarProxyMemoryAnnotation: deploymentConfig.SidecarProxyMemory,
+	} {
+		if _, ok := podTemplateAnnotations[annotation]; !ok && value != "" {
+			podTemplateAnnotations[annotation] = value
+		}
 	}
 	// TODO(mattmoor): Once we have a mechanism for decorating arbitrary deployments (and opting
 	// out via annotation) we should explicitly disable that here to avoid redundant Image
@@ -221,6 +323,9 @@ func MakeDeployment(rev *v1alpha1.Revision,
 		}
 	}
 
+	podSpec := makePodSpec(rev, loggingConfig, observabilityConfig, autoscalerConfig, deploymentConfig)
+	applyForwardedHeaders(podSpec, networkConfig)
+
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.Deployment(rev),
@@ -241,7 +346,7 @@ func MakeDeployment(rev *v1alpha1.Revision,
 					Labels:      makeLabels(rev),
 					Annotations: podTemplateAnnotations,
 				},
-				Spec: *makePodSpec(rev, loggingConfig, observabilityConfig, autoscalerConfig, deploymentConfig),
+				Spec: *podSpec,
 			},
 		},
 	}
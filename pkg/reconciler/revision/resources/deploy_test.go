@@ -47,7 +47,7 @@ var (
 	defaultUserContainer = &corev1.Container{
 		Name:                     containerName,
 		Image:                    "busybox",
-		Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort),
+		Ports:                    buildContainerPorts(v1alpha1.DefaultUserPort, nil),
 		VolumeMounts:             []corev1.VolumeMount{varLogVolumeMount},
 		Lifecycle:                userLifecycle,
 		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
@@ -82,6 +82,7 @@ var (
 			TimeoutSeconds: 10,
 		},
 		SecurityContext: queueSecurityContext,
+		VolumeMounts:    []corev1.VolumeMount{podInfoVolumeMount, secretVolumeMount},
 		Env: []corev1.EnvVar{{
 			Name:  "SERVING_NAMESPACE",
 			Value: "foo", // matches namespace
@@ -94,6 +95,9 @@ var (
 		}, {
 			Name:  "SERVING_REVISION",
 			Value: "bar", // matches name
+		}, {
+			Name:  "SERVING_REVISION_UID",
+			Value: "1234", // matches uid
 		}, {
 			Name:  "QUEUE_SERVING_PORT",
 			Value: "8012",
@@ -149,8 +153,17 @@ var (
 		}},
 	}
 
+	secretVolume = corev1.Volume{
+		Name: secretVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: "bar-secret",
+			},
+		},
+	}
+
 	defaultPodSpec = &corev1.PodSpec{
-		Volumes:                       []corev1.Volume{varLogVolume},
+		Volumes:                       []corev1.Volume{varLogVolume, podInfoVolume, secretVolume},
 		TerminationGracePeriodSeconds: refInt64(45),
 	}
 
@@ -261,7 +274,9 @@ func withEnvVar(name, value string) containerOption {
 
 func withInternalVolumeMount() containerOption {
 	return func(container *corev1.Container) {
-		container.VolumeMounts = append(container.VolumeMounts, internalVolumeMount)
+		// The secret is mounted last.
+		n := len(container.VolumeMounts) - 1
+		container.VolumeMounts = append(container.VolumeMounts[:n:n], internalVolumeMount, container.VolumeMounts[n])
 	}
 }
 
@@ -311,12 +326,6 @@ func podSpec(containers []corev1.Container, opts ...podSpecOption) *corev1.PodSp
 	return podSpec
 }
 
-func withAppendedVolumes(volumes ...corev1.Volume) podSpecOption {
-	return func(ps *corev1.PodSpec) {
-		ps.Volumes = append(ps.Volumes, volumes...)
-	}
-}
-
 func makeDeployment(opts ...deploymentOption) *appsv1.Deployment {
 	deploy := defaultDeployment.DeepCopy()
 	for _, option := range opts {
@@ -392,6 +401,41 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("SERVING_READINESS_PROBE", ""),
 				),
 			}),
+	}, {
+		name: "serving port after additional ports",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Spec.GetContainer().Ports = []corev1.ContainerPort{{
+					Name:          "metrics",
+					ContainerPort: 9095,
+				}, {
+					Name:          "http1",
+					ContainerPort: 8888,
+				}}
+			},
+		),
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(
+					func(container *corev1.Container) {
+						container.Ports = buildContainerPorts(8888, []networking.NamedPort{{
+							Name: "metrics",
+							Port: 9095,
+						}})
+					},
+					withEnvVar("PORT", "8888"),
+				),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("USER_PORT", "8888"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			}),
 	}, {
 		name: "volumes passed through",
 		rev: revision(
@@ -435,14 +479,16 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("USER_PORT", "8888"),
 					withEnvVar("SERVING_READINESS_PROBE", ""),
 				),
-			}, withAppendedVolumes(corev1.Volume{
-				Name: "asdf",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: "asdf",
+			}, func(ps *corev1.PodSpec) {
+				ps.Volumes = []corev1.Volume{varLogVolume, podInfoVolume, {
+					Name: "asdf",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: "asdf",
+						},
 					},
-				},
-			})),
+				}, secretVolume}
+			}),
 	}, {
 		name: "concurrency=1 no owner",
 		rev:  revision(withContainerConcurrency(1)),
@@ -458,6 +504,86 @@ func TestMakePodSpec(t *testing.T) {
 					withEnvVar("SERVING_READINESS_PROBE", ""),
 				),
 			}),
+	}, {
+		name: "dns settings passed through",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Spec.DNSPolicy = corev1.DNSNone
+				revision.Spec.DNSConfig = &corev1.PodDNSConfig{
+					Nameservers: []string{"10.0.0.53"},
+				}
+				revision.Spec.HostAliases = []corev1.HostAlias{{
+					IP:        "10.0.0.1",
+					Hostnames: []string{"foo.internal"},
+				}}
+			},
+		),
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			},
+			func(ps *corev1.PodSpec) {
+				ps.DNSPolicy = corev1.DNSNone
+				ps.DNSConfig = &corev1.PodDNSConfig{
+					Nameservers: []string{"10.0.0.53"},
+				}
+				ps.HostAliases = []corev1.HostAlias{{
+					IP:        "10.0.0.1",
+					Hostnames: []string{"foo.internal"},
+				}}
+			}),
+	}, {
+		name: "service account token not automounted",
+		rev: revision(
+			withContainerConcurrency(1),
+			func(revision *v1alpha1.Revision) {
+				revision.Spec.AutomountServiceAccountToken = ptr.Bool(false)
+			},
+		),
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			},
+			func(ps *corev1.PodSpec) {
+				ps.AutomountServiceAccountToken = ptr.Bool(false)
+			}),
+	}, {
+		name: "with priority class",
+		rev:  revision(withContainerConcurrency(1)),
+		lc:   &logging.Config{},
+		oc:   &metrics.ObservabilityConfig{},
+		ac:   &autoscaler.Config{},
+		cc: &deployment.Config{
+			PriorityClassName: "knative-serving",
+		},
+		want: podSpec(
+			[]corev1.Container{
+				userContainer(),
+				queueContainer(
+					withEnvVar("CONTAINER_CONCURRENCY", "1"),
+					withEnvVar("SERVING_READINESS_PROBE", ""),
+				),
+			},
+			func(ps *corev1.PodSpec) {
+				ps.PriorityClassName = "knative-serving"
+			}),
 	}, {
 		name: "concurrency=1 no owner digest resolved",
 		rev: revision(
@@ -585,7 +711,7 @@ func TestMakePodSpec(t *testing.T) {
 					withLivenessProbe(corev1.Handler{
 						HTTPGet: &corev1.HTTPGetAction{
 							Path: "/",
-							Port: intstr.FromInt(networking.BackendHTTPPort),
+							Port: intstr.FromInt(networking.QueueAdminPort),
 							HTTPHeaders: []corev1.HTTPHeader{{
 								Name:  network.KubeletProbeHeaderName,
 								Value: "queue",
@@ -805,6 +931,71 @@ func TestMakeDeployment(t *testing.T) {
 			deploy.ObjectMeta.Annotations[sidecarIstioInjectAnnotation] = "false"
 			deploy.Spec.Template.ObjectMeta.Annotations[sidecarIstioInjectAnnotation] = "false"
 		}),
+	}, {
+		name: "with prometheus annotations",
+		rev: revision(withoutLabels, func(revision *v1alpha1.Revision) {
+			revision.ObjectMeta.Annotations = map[string]string{
+				serving.PrometheusScrapeAnnotationKey: "true",
+				serving.PrometheusPortAnnotationKey:   "8081",
+			}
+		}),
+		lc: &logging.Config{},
+		nc: &network.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.ObjectMeta.Annotations[serving.PrometheusScrapeAnnotationKey] = "true"
+			deploy.ObjectMeta.Annotations[serving.PrometheusPortAnnotationKey] = "8081"
+			deploy.Spec.Template.ObjectMeta.Annotations[serving.PrometheusScrapeAnnotationKey] = "true"
+			deploy.Spec.Template.ObjectMeta.Annotations[serving.PrometheusPortAnnotationKey] = "8081"
+		}),
+	}, {
+		name: "with sidecar injection disabled by default",
+		rev:  revision(withoutLabels),
+		lc:   &logging.Config{},
+		nc:   &network.Config{},
+		oc:   &metrics.ObservabilityConfig{},
+		ac:   &autoscaler.Config{},
+		cc: &deployment.Config{
+			SidecarInject: deployment.SidecarInjectDisabled,
+		},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.Spec.Template.ObjectMeta.Annotations[sidecarIstioInjectAnnotation] = "false"
+		}),
+	}, {
+		name: "with sidecar injection left to the namespace",
+		rev:  revision(withoutLabels),
+		lc:   &logging.Config{},
+		nc:   &network.Config{},
+		oc:   &metrics.ObservabilityConfig{},
+		ac:   &autoscaler.Config{},
+		cc: &deployment.Config{
+			SidecarInject: deployment.SidecarInjectNamespaceDefault,
+		},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			delete(deploy.Spec.Template.ObjectMeta.Annotations, sidecarIstioInjectAnnotation)
+		}),
+	}, {
+		name: "with sidecar proxy resources",
+		rev: revision(withoutLabels, func(revision *v1alpha1.Revision) {
+			revision.ObjectMeta.Annotations = map[string]string{
+				sidecarProxyMemoryAnnotation: "1Gi",
+			}
+		}),
+		lc: &logging.Config{},
+		nc: &network.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{
+			SidecarProxyCPU:    "100m",
+			SidecarProxyMemory: "128Mi",
+		},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			deploy.ObjectMeta.Annotations[sidecarProxyMemoryAnnotation] = "1Gi"
+			deploy.Spec.Template.ObjectMeta.Annotations[sidecarProxyCPUAnnotation] = "100m"
+			deploy.Spec.Template.ObjectMeta.Annotations[sidecarProxyMemoryAnnotation] = "1Gi"
+		}),
 	}, {
 		name: "with outbound IP range override",
 		rev: revision(
@@ -826,6 +1017,26 @@ func TestMakeDeployment(t *testing.T) {
 			deploy.ObjectMeta.Annotations[IstioOutboundIPRangeAnnotation] = "10.4.0.0/14,10.7.240.0/20"
 			deploy.Spec.Template.ObjectMeta.Annotations[IstioOutboundIPRangeAnnotation] = "10.4.0.0/14,10.7.240.0/20"
 		}),
+	}, {
+		name: "with data-plane config",
+		rev: revision(withoutLabels, func(revision *v1alpha1.Revision) {
+			revision.ObjectMeta.Annotations = map[string]string{
+				serving.RateLimitAnnotationKey:       "10",
+				serving.TimeoutOverrideAnnotationKey: "30",
+			}
+		}),
+		lc: &logging.Config{},
+		nc: &network.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{
+			EnableDataPlaneConfig: true,
+		},
+		want: makeDeployment(func(deploy *appsv1.Deployment) {
+			// The pods don't restart when the data-plane annotations change.
+			deploy.ObjectMeta.Annotations[serving.RateLimitAnnotationKey] = "10"
+			deploy.ObjectMeta.Annotations[serving.TimeoutOverrideAnnotationKey] = "30"
+		}),
 	}}
 
 	for _, test := range tests {
@@ -839,3 +1050,74 @@ func TestMakeDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyForwardedHeaders(t *testing.T) {
+	podSpec := func(env ...corev1.EnvVar) *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "user-container",
+			}, {
+				Name: QueueContainerName,
+				Env:  env,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		nc   *network.Config
+		want *corev1.PodSpec
+	}{{
+		name: "unset",
+		nc:   &network.Config{},
+		want: podSpec(),
+	}, {
+		name: "defaults",
+		nc: &network.Config{
+			ForwardedForPolicy: network.ForwardedForAppend,
+			ForwardedHeaders:   network.ForwardedHeadersTrust,
+		},
+		want: podSpec(),
+	}, {
+		name: "preserve",
+		nc: &network.Config{
+			ForwardedForPolicy: network.ForwardedForPreserve,
+			ForwardedHeaders:   network.ForwardedHeadersTrust,
+		},
+		want: podSpec(corev1.EnvVar{
+			Name:  "FORWARDED_FOR_POLICY",
+			Value: "preserve",
+		}),
+	}, {
+		name: "strip",
+		nc: &network.Config{
+			ForwardedForPolicy: network.ForwardedForAppend,
+			ForwardedHeaders:   network.ForwardedHeadersStrip,
+		},
+		want: podSpec(corev1.EnvVar{
+			Name:  "FORWARDED_HEADERS",
+			Value: "strip",
+		}),
+	}, {
+		name: "trusted hops",
+		nc: &network.Config{
+			ForwardedForPolicy: network.ForwardedForAppend,
+			ForwardedHeaders:   network.ForwardedHeadersTrust,
+			TrustedHops:        2,
+		},
+		want: podSpec(corev1.EnvVar{
+			Name:  "TRUSTED_HOPS",
+			Value: "2",
+		}),
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := podSpec()
+			applyForwardedHeaders(got, test.nc)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("applyForwardedHeaders (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -32,3 +32,20 @@ func ImageCache(rev kmeta.Accessor) string {
 func PA(rev kmeta.Accessor) string {
 	return rev.GetName()
 }
+
+// VPA returns the VerticalPodAutoscaler name for the revision.
+func VPA(rev kmeta.Accessor) string {
+	return rev.GetName()
+}
+
+// DataPlaneConfig returns the name of the ConfigMap holding the data-plane
+// configuration of the revision.
+func DataPlaneConfig(rev kmeta.Accessor) string {
+	return kmeta.ChildName(rev.GetName(), "-dataplane")
+}
+
+// Secret returns the name of the Secret holding the credentials of the
+// queue-proxies of the revision.
+func Secret(rev kmeta.Accessor) string {
+	return kmeta.ChildName(rev.GetName(), "-secret")
+}
@@ -95,6 +95,33 @@ func TestNamer(t *testing.T) {
 		},
 		f:    PA,
 		want: "baz",
+	}, {
+		name: "VPA",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "qux",
+			},
+		},
+		f:    VPA,
+		want: "qux",
+	}, {
+		name: "DataPlaneConfig",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+			},
+		},
+		f:    DataPlaneConfig,
+		want: "foo-dataplane",
+	}, {
+		name: "Secret",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "foo",
+			},
+		},
+		f:    Secret,
+		want: "foo-secret",
 	}}
 
 	for _, test := range tests {
@@ -0,0 +1,74 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+// nodeArchKey is the well-known node label holding the CPU architecture.
+// Some providers also taint nodes of non-default architectures with it.
+const nodeArchKey = "kubernetes.io/arch"
+
+// NodeArch returns the CPU architecture the revision requested for its pods,
+// or the empty string if it didn't request one.
+func NodeArch(rev *v1alpha1.Revision) string {
+	return rev.Annotations[serving.NodeArchAnnotationKey]
+}
+
+// NodeArchAllowed returns whether the CPU architecture requested by the
+// revision, if any, is one of the architectures allowed in config-deployment.
+func NodeArchAllowed(rev *v1alpha1.Revision, deploymentConfig *deployment.Config) bool {
+	arch := NodeArch(rev)
+	return arch == "" || deploymentConfig.Architectures.Len() == 0 || deploymentConfig.Architectures.Has(arch)
+}
+
+// applyNodeArch pins the pods of the revision to nodes of the architecture it
+// requested, or else to nodes of any of the allowed architectures. The pods
+// tolerate the architecture taint for the architectures they may run on.
+func applyNodeArch(podSpec *corev1.PodSpec, rev *v1alpha1.Revision, deploymentConfig *deployment.Config) {
+	archs := deploymentConfig.Architectures.List()
+	if arch := NodeArch(rev); arch != "" {
+		addNodeSelector(podSpec, nodeArchKey, arch)
+		archs = []string{arch}
+	} else if len(archs) > 0 {
+		podSpec.Affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      nodeArchKey,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   archs,
+						}},
+					}},
+				},
+			},
+		}
+	}
+	for _, arch := range archs {
+		podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{
+			Key:      nodeArchKey,
+			Operator: corev1.TolerationOpEqual,
+			Value:    arch,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+func TestApplyNodeArch(t *testing.T) {
+	revision := func(arch string) *v1alpha1.Revision {
+		rev := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+		if arch != "" {
+			rev.Annotations = map[string]string{serving.NodeArchAnnotationKey: arch}
+		}
+		return rev
+	}
+	toleration := func(arch string) corev1.Toleration {
+		return corev1.Toleration{
+			Key:      nodeArchKey,
+			Operator: corev1.TolerationOpEqual,
+			Value:    arch,
+			Effect:   corev1.TaintEffectNoSchedule,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		rev         *v1alpha1.Revision
+		archs       sets.String
+		want        corev1.PodSpec
+		wantAllowed bool
+	}{{
+		name:        "no constraints",
+		rev:         revision(""),
+		want:        corev1.PodSpec{},
+		wantAllowed: true,
+	}, {
+		name:  "allowed architectures",
+		rev:   revision(""),
+		archs: sets.NewString("arm64", "amd64"),
+		want: corev1.PodSpec{
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      nodeArchKey,
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"amd64", "arm64"},
+							}},
+						}},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{toleration("amd64"), toleration("arm64")},
+		},
+		wantAllowed: true,
+	}, {
+		name:  "requested architecture",
+		rev:   revision("arm64"),
+		archs: sets.NewString("arm64", "amd64"),
+		want: corev1.PodSpec{
+			NodeSelector: map[string]string{nodeArchKey: "arm64"},
+			Tolerations:  []corev1.Toleration{toleration("arm64")},
+		},
+		wantAllowed: true,
+	}, {
+		name: "requested architecture without constraints",
+		rev:  revision("arm64"),
+		want: corev1.PodSpec{
+			NodeSelector: map[string]string{nodeArchKey: "arm64"},
+			Tolerations:  []corev1.Toleration{toleration("arm64")},
+		},
+		wantAllowed: true,
+	}, {
+		name:  "requested architecture not allowed",
+		rev:   revision("arm64"),
+		archs: sets.NewString("amd64"),
+		want: corev1.PodSpec{
+			NodeSelector: map[string]string{nodeArchKey: "arm64"},
+			Tolerations:  []corev1.Toleration{toleration("arm64")},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &deployment.Config{Architectures: test.archs}
+			got := corev1.PodSpec{}
+			applyNodeArch(&got, test.rev, cfg)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("applyNodeArch() (-want, +got) = %v", diff)
+			}
+			if got, want := NodeArchAllowed(test.rev, cfg), test.wantAllowed; got != want {
+				t.Errorf("NodeArchAllowed() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+const (
+	// nodeOSLabelKey is the well-known node label holding the operating system.
+	nodeOSLabelKey = "kubernetes.io/os"
+	// nodeOSTaintKey is the taint commonly put on Windows nodes to keep
+	// Linux pods off them.
+	nodeOSTaintKey = "node.kubernetes.io/os"
+)
+
+// NodeOS returns the operating system the pods of the revision are
+// scheduled onto.
+func NodeOS(rev *v1alpha1.Revision) string {
+	if os, ok := rev.Annotations[serving.NodeOSAnnotationKey]; ok {
+		return os
+	}
+	return serving.NodeOSLinux
+}
+
+// applyNodeOS pins the pods of the revision to nodes of its operating system,
+// if Windows nodes are enabled. Otherwise all nodes are Linux nodes and the
+// pod spec is left untouched.
+func applyNodeOS(podSpec *corev1.PodSpec, rev *v1alpha1.Revision, deploymentConfig *deployment.Config) {
+	if !deploymentConfig.EnableWindows {
+		return
+	}
+	os := NodeOS(rev)
+	addNodeSelector(podSpec, nodeOSLabelKey, os)
+	if os == serving.NodeOSWindows {
+		podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{
+			Key:      nodeOSTaintKey,
+			Operator: corev1.TolerationOpEqual,
+			Value:    serving.NodeOSWindows,
+			Effect:   corev1.TaintEffectNoSchedule,
+		})
+	}
+}
+
+func addNodeSelector(podSpec *corev1.PodSpec, key, value string) {
+	if podSpec.NodeSelector == nil {
+		podSpec.NodeSelector = make(map[string]string, 1)
+	}
+	podSpec.NodeSelector[key] = value
+}
+
+// queueSidecarImage returns the queue sidecar image for the operating system
+// of the revision.
+func queueSidecarImage(rev *v1alpha1.Revision, deploymentConfig *deployment.Config) string {
+	if NodeOS(rev) == serving.NodeOSWindows {
+		return deploymentConfig.WindowsQueueSidecarImage
+	}
+	return deploymentConfig.QueueSidecarImage
+}
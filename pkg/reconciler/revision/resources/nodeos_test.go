@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+func TestApplyNodeOS(t *testing.T) {
+	revision := func(os string) *v1alpha1.Revision {
+		rev := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+		if os != "" {
+			rev.Annotations = map[string]string{serving.NodeOSAnnotationKey: os}
+		}
+		return rev
+	}
+
+	tests := []struct {
+		name    string
+		rev     *v1alpha1.Revision
+		enabled bool
+		want    corev1.PodSpec
+		image   string
+	}{{
+		name:  "windows disabled",
+		rev:   revision(""),
+		want:  corev1.PodSpec{},
+		image: "queue",
+	}, {
+		name:    "linux pinned when windows enabled",
+		rev:     revision(""),
+		enabled: true,
+		want: corev1.PodSpec{
+			NodeSelector: map[string]string{nodeOSLabelKey: serving.NodeOSLinux},
+		},
+		image: "queue",
+	}, {
+		name:    "windows",
+		rev:     revision(serving.NodeOSWindows),
+		enabled: true,
+		want: corev1.PodSpec{
+			NodeSelector: map[string]string{nodeOSLabelKey: serving.NodeOSWindows},
+			Tolerations: []corev1.Toleration{{
+				Key:      nodeOSTaintKey,
+				Operator: corev1.TolerationOpEqual,
+				Value:    serving.NodeOSWindows,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}},
+		},
+		image: "queue-windows",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := &deployment.Config{
+				QueueSidecarImage:        "queue",
+				WindowsQueueSidecarImage: "queue-windows",
+				EnableWindows:            test.enabled,
+			}
+			got := corev1.PodSpec{}
+			applyNodeOS(&got, test.rev, cfg)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("applyNodeOS() (-want, +got) = %v", diff)
+			}
+			if got, want := queueSidecarImage(test.rev, cfg), test.image; got != want {
+				t.Errorf("queueSidecarImage() = %q, want %q", got, want)
+			}
+		})
+	}
+}
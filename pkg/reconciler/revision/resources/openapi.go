@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	openAPISchemaVolumeName = "knative-openapi"
+	openAPISchemaVolumePath = "/var/run/knative-openapi"
+
+	// queueOpenAPISchemaEnvKey tells the queue-proxy where to read the
+	// OpenAPI document to validate requests against from.
+	queueOpenAPISchemaEnvKey = "OPENAPI_SCHEMA"
+)
+
+var openAPISchemaVolumeMount = corev1.VolumeMount{
+	Name:      openAPISchemaVolumeName,
+	MountPath: openAPISchemaVolumePath,
+	ReadOnly:  true,
+}
+
+// applyOpenAPISchema mounts the ConfigMap holding the OpenAPI document of
+// the revision, if any, into the queue-proxy and tells it where to find it.
+func applyOpenAPISchema(podSpec *corev1.PodSpec, rev *v1alpha1.Revision) {
+	name, ok := rev.Annotations[serving.OpenAPISchemaAnnotationKey]
+	if !ok {
+		return
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: openAPISchemaVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Items: []corev1.KeyToPath{{
+					Key:  serving.OpenAPISchemaConfigMapKey,
+					Path: serving.OpenAPISchemaConfigMapKey,
+				}},
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name != QueueContainerName {
+			continue
+		}
+		c.VolumeMounts = append(c.VolumeMounts, openAPISchemaVolumeMount)
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  queueOpenAPISchemaEnvKey,
+			Value: path.Join(openAPISchemaVolumePath, serving.OpenAPISchemaConfigMapKey),
+		})
+	}
+}
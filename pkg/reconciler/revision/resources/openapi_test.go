@@ -0,0 +1,89 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestApplyOpenAPISchema(t *testing.T) {
+	podSpec := func() corev1.PodSpec {
+		return corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "user-container",
+			}, {
+				Name: QueueContainerName,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		rev  *v1alpha1.Revision
+		want corev1.PodSpec
+	}{{
+		name: "no annotation",
+		rev:  &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+		want: podSpec(),
+	}, {
+		name: "schema",
+		rev: &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Annotations: map[string]string{serving.OpenAPISchemaAnnotationKey: "my-api"},
+		}},
+		want: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "user-container",
+			}, {
+				Name:         QueueContainerName,
+				VolumeMounts: []corev1.VolumeMount{openAPISchemaVolumeMount},
+				Env: []corev1.EnvVar{{
+					Name:  queueOpenAPISchemaEnvKey,
+					Value: "/var/run/knative-openapi/openapi.yaml",
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: openAPISchemaVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "my-api"},
+						Items: []corev1.KeyToPath{{
+							Key:  "openapi.yaml",
+							Path: "openapi.yaml",
+						}},
+					},
+				},
+			}},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := podSpec()
+			applyOpenAPISchema(&got, test.rev)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("applyOpenAPISchema() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -103,6 +103,9 @@ func TestMakePA(t *testing.T) {
 					Ports: []corev1.ContainerPort{{
 						Name:     "h2c",
 						HostPort: int32(443),
+					}, {
+						Name:          "metrics",
+						ContainerPort: 9095,
 					}},
 				},
 			},
@@ -134,6 +137,10 @@ func TestMakePA(t *testing.T) {
 					Name:       "baz-deployment",
 				},
 				ProtocolType: networking.ProtocolH2C,
+				Ports: []networking.NamedPort{{
+					Name: "metrics",
+					Port: 9095,
+				}},
 			}},
 	}}
 
@@ -0,0 +1,101 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+// queueGuaranteedResources are the resources of the queue-proxy in a
+// Guaranteed pod when it has no limit for them.
+var queueGuaranteedResources = corev1.ResourceList{
+	corev1.ResourceCPU:    queueContainerLimitCPU.min,
+	corev1.ResourceMemory: queueContainerLimitMemory.min,
+}
+
+// GuaranteedWithSidecar returns whether the revision asks for Guaranteed
+// pods while a mesh sidecar is injected into them. The injected sidecar has
+// no matching requests and limits, so the pods can't be Guaranteed.
+func GuaranteedWithSidecar(rev *v1alpha1.Revision, deploymentConfig *deployment.Config) bool {
+	if corev1.PodQOSClass(rev.Annotations[serving.QoSClassAnnotationKey]) != corev1.PodQOSGuaranteed {
+		return false
+	}
+	inject, ok := rev.Annotations[sidecarIstioInjectAnnotation]
+	if !ok {
+		inject = deploymentConfig.SidecarInject
+	}
+	return inject == "" || inject == deployment.SidecarInjectEnabled
+}
+
+// applyQoSClass makes the requests and limits of the user container and the
+// queue-proxy consistent with the given pod QoS class. An empty class leaves
+// them untouched.
+func applyQoSClass(containers []corev1.Container, qosClass string) {
+	for i := range containers {
+		c := &containers[i]
+		switch corev1.PodQOSClass(qosClass) {
+		case corev1.PodQOSGuaranteed:
+			var fallback corev1.ResourceList
+			if c.Name == QueueContainerName {
+				fallback = queueGuaranteedResources
+			}
+			guarantee(&c.Resources, fallback)
+		case corev1.PodQOSBurstable:
+			// Only the CPU limit is dropped, the others keep the memory and
+			// ephemeral storage of the containers bounded. Kubernetes
+			// defaults a missing request to the limit, so keep that first.
+			if limit, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+				if _, ok := c.Resources.Requests[corev1.ResourceCPU]; !ok {
+					if c.Resources.Requests == nil {
+						c.Resources.Requests = corev1.ResourceList{}
+					}
+					c.Resources.Requests[corev1.ResourceCPU] = limit
+				}
+			}
+			removeCPULimit(c)
+		}
+	}
+}
+
+// guarantee sets the CPU and memory requests and limits of rr to the same
+// value: the limit if there is one, else the request, else the fallback.
+func guarantee(rr *corev1.ResourceRequirements, fallback corev1.ResourceList) {
+	for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		var value resource.Quantity
+		if limit, ok := rr.Limits[r]; ok {
+			value = limit
+		} else if request, ok := rr.Requests[r]; ok {
+			value = request
+		} else if f, ok := fallback[r]; ok {
+			value = f
+		} else {
+			continue
+		}
+		if rr.Requests == nil {
+			rr.Requests = corev1.ResourceList{}
+		}
+		if rr.Limits == nil {
+			rr.Limits = corev1.ResourceList{}
+		}
+		rr.Requests[r] = value
+		rr.Limits[r] = value
+	}
+}
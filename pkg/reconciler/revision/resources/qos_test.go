@@ -0,0 +1,164 @@
+/*
+Copyright 2018 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/deployment"
+)
+
+func TestApplyQoSClass(t *testing.T) {
+	list := func(cpu, memory string) corev1.ResourceList {
+		l := corev1.ResourceList{}
+		if cpu != "" {
+			l[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			l[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return l
+	}
+	user := func(requests, limits corev1.ResourceList) corev1.Container {
+		return corev1.Container{
+			Name:      "user-container",
+			Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits},
+		}
+	}
+	queue := func(requests, limits corev1.ResourceList) corev1.Container {
+		return corev1.Container{
+			Name:      QueueContainerName,
+			Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits},
+		}
+	}
+
+	tests := []struct {
+		name string
+		qos  string
+		in   []corev1.Container
+		want []corev1.Container
+	}{{
+		name: "no class",
+		in:   []corev1.Container{user(list("100m", ""), list("1", "")), queue(list("25m", ""), nil)},
+		want: []corev1.Container{user(list("100m", ""), list("1", "")), queue(list("25m", ""), nil)},
+	}, {
+		name: "guaranteed",
+		qos:  "Guaranteed",
+		in:   []corev1.Container{user(list("100m", "128Mi"), list("1", "")), queue(list("25m", ""), nil)},
+		want: []corev1.Container{
+			user(list("1", "128Mi"), list("1", "128Mi")),
+			queue(list("25m", "200Mi"), list("25m", "200Mi")),
+		},
+	}, {
+		name: "guaranteed with queue limits",
+		qos:  "Guaranteed",
+		in:   []corev1.Container{queue(list("25m", "50Mi"), list("40m", "200Mi"))},
+		want: []corev1.Container{queue(list("40m", "200Mi"), list("40m", "200Mi"))},
+	}, {
+		name: "guaranteed with queue without cpu request",
+		qos:  "Guaranteed",
+		in:   []corev1.Container{queue(nil, nil)},
+		want: []corev1.Container{queue(list("40m", "200Mi"), list("40m", "200Mi"))},
+	}, {
+		name: "burstable",
+		qos:  "Burstable",
+		in:   []corev1.Container{user(list("100m", ""), list("1", "1Gi")), queue(list("25m", "50Mi"), list("40m", "200Mi"))},
+		want: []corev1.Container{user(list("100m", ""), list("", "1Gi")), queue(list("25m", "50Mi"), list("", "200Mi"))},
+	}, {
+		name: "burstable keeps ephemeral storage limits",
+		qos:  "Burstable",
+		in: []corev1.Container{user(list("100m", ""), corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("1"),
+			corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		})},
+		want: []corev1.Container{user(list("100m", ""), corev1.ResourceList{
+			corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		})},
+	}, {
+		name: "burstable without requests",
+		qos:  "Burstable",
+		in:   []corev1.Container{user(nil, list("1", ""))},
+		want: []corev1.Container{user(list("1", ""), nil)},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			applyQoSClass(test.in, test.qos)
+			if diff := cmp.Diff(test.want, test.in, cmp.Comparer(func(a, b resource.Quantity) bool {
+				return a.Cmp(b) == 0
+			})); diff != "" {
+				t.Errorf("applyQoSClass (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
+func TestGuaranteedWithSidecar(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		inject      string
+		want        bool
+	}{{
+		name: "no class",
+	}, {
+		name:        "burstable",
+		annotations: map[string]string{serving.QoSClassAnnotationKey: "Burstable"},
+	}, {
+		name:        "guaranteed with default injection",
+		annotations: map[string]string{serving.QoSClassAnnotationKey: "Guaranteed"},
+		want:        true,
+	}, {
+		name:        "guaranteed with injection disabled",
+		annotations: map[string]string{serving.QoSClassAnnotationKey: "Guaranteed"},
+		inject:      deployment.SidecarInjectDisabled,
+	}, {
+		name:        "guaranteed with namespace default injection",
+		annotations: map[string]string{serving.QoSClassAnnotationKey: "Guaranteed"},
+		inject:      deployment.SidecarInjectNamespaceDefault,
+	}, {
+		name: "guaranteed with injection disabled on the revision",
+		annotations: map[string]string{
+			serving.QoSClassAnnotationKey: "Guaranteed",
+			sidecarIstioInjectAnnotation:  "false",
+		},
+		inject: deployment.SidecarInjectEnabled,
+	}, {
+		name: "guaranteed with injection enabled on the revision",
+		annotations: map[string]string{
+			serving.QoSClassAnnotationKey: "Guaranteed",
+			sidecarIstioInjectAnnotation:  "true",
+		},
+		inject: deployment.SidecarInjectDisabled,
+		want:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rev := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Annotations: test.annotations}}
+			if got := GuaranteedWithSidecar(rev, &deployment.Config{SidecarInject: test.inject}); got != test.want {
+				t.Errorf("GuaranteedWithSidecar = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	pkgmetrics "knative.dev/pkg/metrics"
 	"knative.dev/pkg/ptr"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -53,6 +54,10 @@ var (
 		Name:          requestQueueHTTPPortName,
 		ContainerPort: int32(networking.BackendHTTP2Port),
 	}
+	queueTCPPort = corev1.ContainerPort{
+		Name:          requestQueueHTTPPortName,
+		ContainerPort: int32(networking.BackendTCPPort),
+	}
 	queueNonServingPorts = []corev1.ContainerPort{{
 		// Provides health checks and lifecycle hooks.
 		Name:          v1alpha1.QueueAdminPortName,
@@ -212,12 +217,22 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, o
 		ts = *rev.Spec.TimeoutSeconds
 	}
 
+	// The request metrics backend is read from the data-plane configuration
+	// when enabled, so that changing it doesn't restart the pods.
+	metricsBackend := observabilityConfig.RequestMetricsBackend
+	if deploymentConfig.EnableDataPlaneConfig {
+		metricsBackend = ""
+	}
+
 	// We need to configure only one serving port for the Queue proxy, since
 	// we know the protocol that is being used by this application.
 	ports := queueNonServingPorts
-	if rev.GetProtocol() == networking.ProtocolH2C {
+	switch rev.GetProtocol() {
+	case networking.ProtocolH2C:
 		ports = append(ports, queueHTTP2Port)
-	} else {
+	case networking.ProtocolTCP:
+		ports = append(ports, queueTCPPort)
+	default:
 		ports = append(ports, queueHTTPPort)
 	}
 
@@ -233,9 +248,9 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, o
 	// TODO(joshrider) bubble up error instead of squashing it here
 	probeJSON, _ := readiness.EncodeProbe(rp)
 
-	return &corev1.Container{
+	c := &corev1.Container{
 		Name:            QueueContainerName,
-		Image:           deploymentConfig.QueueSidecarImage,
+		Image:           queueSidecarImage(rev, deploymentConfig),
 		Resources:       createQueueResources(rev.GetAnnotations(), rev.Spec.GetContainer()),
 		Ports:           ports,
 		ReadinessProbe:  makeQueueProbe(rp),
@@ -253,6 +268,11 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, o
 		}, {
 			Name:  "SERVING_REVISION",
 			Value: rev.Name,
+		}, {
+			// The probes of the activator are signed with a key derived from
+			// the UID.
+			Name:  "SERVING_REVISION_UID",
+			Value: string(rev.UID),
 		}, {
 			Name:  "QUEUE_SERVING_PORT",
 			Value: strconv.Itoa(int(ports[len(ports)-1].ContainerPort)),
@@ -287,7 +307,7 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, o
 			Value: observabilityConfig.RequestLogTemplate,
 		}, {
 			Name:  "SERVING_REQUEST_METRICS_BACKEND",
-			Value: observabilityConfig.RequestMetricsBackend,
+			Value: metricsBackend,
 		}, {
 			Name:  "USER_PORT",
 			Value: strconv.Itoa(int(userPort)),
@@ -314,6 +334,74 @@ func makeQueueContainer(rev *v1alpha1.Revision, loggingConfig *logging.Config, o
 			Value: probeJSON,
 		}},
 	}
+	if deploymentConfig.EnableActivatorRegistration {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "ACTIVATOR_REGISTRATION_HOST",
+			Value: network.GetServiceHostname(activator.RegistrationServiceName, system.Namespace()),
+		})
+	}
+	if deploymentConfig.EnableDataPlaneConfig {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "SERVING_DATA_PLANE_CONFIG",
+			Value: "true",
+		})
+	}
+	// The rate limit is read from the data-plane configuration when enabled.
+	if limit, ok := rev.Annotations[serving.RateLimitAnnotationKey]; ok && !deploymentConfig.EnableDataPlaneConfig {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "RATE_LIMIT",
+			Value: limit,
+		})
+		if burst, ok := rev.Annotations[serving.RateLimitBurstAnnotationKey]; ok {
+			c.Env = append(c.Env, corev1.EnvVar{
+				Name:  "RATE_LIMIT_BURST",
+				Value: burst,
+			})
+		}
+	}
+	if compression, ok := rev.Annotations[serving.CompressionAnnotationKey]; ok {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "COMPRESSION",
+			Value: compression,
+		})
+	}
+	for _, slo := range []struct{ key, env string }{
+		{serving.SLOAvailabilityAnnotationKey, "SLO_AVAILABILITY"},
+		{serving.SLOLatencyAnnotationKey, "SLO_LATENCY"},
+		{serving.SLOLatencyTargetAnnotationKey, "SLO_LATENCY_TARGET"},
+	} {
+		if v, ok := rev.Annotations[slo.key]; ok {
+			c.Env = append(c.Env, corev1.EnvVar{
+				Name:  slo.env,
+				Value: v,
+			})
+		}
+	}
+	if deps, ok := rev.Annotations[serving.DependenciesAnnotationKey]; ok {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "SERVING_DEPENDENCIES",
+			Value: deps,
+		})
+	}
+	if rev.GetProtocol() == networking.ProtocolTCP {
+		// The queue-proxy serves HTTP unless told otherwise.
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  "SERVING_PROTOCOL",
+			Value: string(networking.ProtocolTCP),
+		})
+	}
+	if secret, ok := rev.Annotations[serving.DebugSecretAnnotationKey]; ok {
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name: "DEBUG_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+					Key:                  serving.DebugTokenSecretKey,
+				},
+			},
+		})
+	}
+	return c
 }
 
 func applyReadinessProbeDefaults(p *corev1.Probe, port int32) {
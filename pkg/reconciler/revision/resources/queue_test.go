@@ -359,6 +359,313 @@ func TestMakeQueueContainer(t *testing.T) {
 				"SERVING_REQUEST_METRICS_BACKEND": "prometheus",
 			}),
 		},
+	}, {
+		name: "activator registration enabled",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{
+			EnableActivatorRegistration: true,
+		},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY":       "0",
+				"ACTIVATOR_REGISTRATION_HOST": "activator-registration.knative-testing.svc.cluster.local",
+			}),
+		},
+	}, {
+		name: "rate limited",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.RateLimitAnnotationKey:      "2.5",
+					serving.RateLimitBurstAnnotationKey: "10",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+				"RATE_LIMIT":            "2.5",
+				"RATE_LIMIT_BURST":      "10",
+			}),
+		},
+	}, {
+		name: "data-plane config",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.RateLimitAnnotationKey: "2.5",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{
+			RequestMetricsBackend: "prometheus",
+		},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{
+			EnableDataPlaneConfig: true,
+		},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// The rate limit and metrics backend come from the data-plane
+			// configuration.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY":     "0",
+				"SERVING_DATA_PLANE_CONFIG": "true",
+			}),
+		},
+	}, {
+		name: "compressed",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.CompressionAnnotationKey: "gzip,deflate",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+				"COMPRESSION":           "gzip,deflate",
+			}),
+		},
+	}, {
+		name: "slo",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.SLOAvailabilityAnnotationKey:  "99.9",
+					serving.SLOLatencyAnnotationKey:       "300ms",
+					serving.SLOLatencyTargetAnnotationKey: "99",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+				"SLO_AVAILABILITY":      "99.9",
+				"SLO_LATENCY":           "300ms",
+				"SLO_LATENCY_TARGET":    "99",
+			}),
+		},
+	}, {
+		name: "dependencies",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.DependenciesAnnotationKey: "db:5432,http://auth/healthz",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+				"SERVING_DEPENDENCIES":  "db:5432,http://auth/healthz",
+			}),
+		},
+	}, {
+		name: "debug secret",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+				Annotations: map[string]string{
+					serving.DebugSecretAnnotationKey: "debug-token",
+				},
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueHTTPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: append(env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+			}), corev1.EnvVar{
+				Name: "DEBUG_TOKEN",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "debug-token"},
+						Key:                  "token",
+					},
+				},
+			}),
+		},
+	}, {
+		name: "tcp",
+		rev: &v1alpha1.Revision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "foo",
+				Name:      "bar",
+				UID:       "1234",
+			},
+			Spec: v1alpha1.RevisionSpec{
+				RevisionSpec: v1beta1.RevisionSpec{
+					ContainerConcurrency: 0,
+					TimeoutSeconds:       ptr.Int64(45),
+					PodSpec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name: containerName,
+							Ports: []corev1.ContainerPort{{
+								ContainerPort: 1883,
+								Name:          string(networking.ProtocolTCP),
+							}},
+						}},
+					},
+				},
+			},
+		},
+		lc: &logging.Config{},
+		oc: &metrics.ObservabilityConfig{},
+		ac: &autoscaler.Config{},
+		cc: &deployment.Config{},
+		want: &corev1.Container{
+			// These are effectively constant
+			Name:            QueueContainerName,
+			Resources:       createQueueResources(make(map[string]string), &corev1.Container{}),
+			Ports:           append(queueNonServingPorts, queueTCPPort),
+			ReadinessProbe:  defaultKnativeQReadinessProbe,
+			SecurityContext: queueSecurityContext,
+			// These changed based on the Revision and configs passed in.
+			Env: append(env(map[string]string{
+				"CONTAINER_CONCURRENCY": "0",
+				"USER_PORT":             "1883",
+				"QUEUE_SERVING_PORT":    "8014",
+			}), corev1.EnvVar{
+				Name:  "SERVING_PROTOCOL",
+				Value: "tcp",
+			}),
+		},
 	}}
 
 	for _, test := range tests {
@@ -1007,6 +1314,7 @@ var defaultEnv = map[string]string{
 	"SERVING_SERVICE":                 "",
 	"SERVING_CONFIGURATION":           "",
 	"SERVING_REVISION":                "bar",
+	"SERVING_REVISION_UID":            "1234",
 	"CONTAINER_CONCURRENCY":           "1",
 	"REVISION_TIMEOUT_SECONDS":        "45",
 	"SERVING_LOGGING_CONFIG":          "",
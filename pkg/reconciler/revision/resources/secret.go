@@ -0,0 +1,122 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"crypto/rand"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/queue"
+	"knative.dev/serving/pkg/reconciler/revision/resources/names"
+)
+
+const secretVolumeName = "knative-secret"
+
+var secretVolumeMount = corev1.VolumeMount{
+	Name:      secretVolumeName,
+	MountPath: queue.SecretVolumePath,
+	ReadOnly:  true,
+}
+
+// MakeRegistrationKey makes the Secret holding a new random key the
+// registration tokens of the revisions are derived from.
+func MakeRegistrationKey() (*corev1.Secret, error) {
+	key, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      activator.RegistrationKeySecretName,
+			Namespace: system.Namespace(),
+		},
+		Data: map[string][]byte{
+			activator.RegistrationKeySecretKey: key,
+		},
+	}, nil
+}
+
+// MakeDataPlaneKey makes a new random key to sign the data-plane
+// configuration of a revision with.
+func MakeDataPlaneKey() ([]byte, error) {
+	return randomKey()
+}
+
+// MakeProbeKey makes a new random key to sign the network probes of the pods
+// of a revision with.
+func MakeProbeKey() ([]byte, error) {
+	return randomKey()
+}
+
+func randomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// MakeSecret makes the Secret holding the key the network probes of the pods
+// of the revision are signed with, the token its queue-proxies sign their
+// registrations with, derived from registrationKey, and the key its
+// data-plane configuration is signed with. The latter two are left out when
+// nil.
+func MakeSecret(rev *v1alpha1.Revision, probeKey, registrationKey, dataPlaneKey []byte) *corev1.Secret {
+	data := map[string][]byte{
+		queue.ProbeKeyKey: probeKey,
+	}
+	if registrationKey != nil {
+		data[queue.RegistrationTokenKey] = queue.RegistrationToken(registrationKey, rev.Namespace, rev.Name, rev.UID)
+	}
+	if dataPlaneKey != nil {
+		data[queue.DataPlaneKeyKey] = dataPlaneKey
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            names.Secret(rev),
+			Namespace:       rev.Namespace,
+			Labels:          makeLabels(rev),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+		},
+		Data: data,
+	}
+}
+
+// applySecret mounts the Secret of the revision into the queue-proxy. The
+// Secret is created ahead of the Deployment, and the pods don't start
+// without it, as the queue-proxy needs the key its probes are signed with.
+func applySecret(podSpec *corev1.PodSpec, rev *v1alpha1.Revision) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: secretVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: names.Secret(rev),
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		if c.Name == QueueContainerName {
+			c.VolumeMounts = append(c.VolumeMounts, secretVolumeMount)
+		}
+	}
+}
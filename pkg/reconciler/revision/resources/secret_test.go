@@ -0,0 +1,150 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/queue"
+)
+
+func TestMakeRegistrationKey(t *testing.T) {
+	got, err := MakeRegistrationKey()
+	if err != nil {
+		t.Fatalf("MakeRegistrationKey() = %v", err)
+	}
+	if got.Name != activator.RegistrationKeySecretName || got.Namespace != system.Namespace() {
+		t.Errorf("MakeRegistrationKey() = %s/%s, want %s/%s", got.Namespace, got.Name,
+			system.Namespace(), activator.RegistrationKeySecretName)
+	}
+	if key := got.Data[activator.RegistrationKeySecretKey]; len(key) != 32 {
+		t.Errorf("len(key) = %d, want 32", len(key))
+	}
+
+	other, err := MakeRegistrationKey()
+	if err != nil {
+		t.Fatalf("MakeRegistrationKey() = %v", err)
+	}
+	if cmp.Equal(got.Data, other.Data) {
+		t.Error("MakeRegistrationKey() made the same key twice")
+	}
+}
+
+func TestMakeSecret(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+	}
+	want := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "foo",
+			Name:            "bar-secret",
+			Labels:          makeLabels(rev),
+			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(rev)},
+		},
+		Data: map[string][]byte{
+			queue.ProbeKeyKey:          []byte("probe"),
+			queue.RegistrationTokenKey: queue.RegistrationToken([]byte("key"), "foo", "bar", "1234"),
+			queue.DataPlaneKeyKey:      []byte("dataplane"),
+		},
+	}
+	if diff := cmp.Diff(want, MakeSecret(rev, []byte("probe"), []byte("key"), []byte("dataplane"))); diff != "" {
+		t.Errorf("MakeSecret() (-want, +got) = %v", diff)
+	}
+
+	// Only the probe key.
+	delete(want.Data, queue.RegistrationTokenKey)
+	delete(want.Data, queue.DataPlaneKeyKey)
+	if diff := cmp.Diff(want, MakeSecret(rev, []byte("probe"), nil, nil)); diff != "" {
+		t.Errorf("MakeSecret() (-want, +got) = %v", diff)
+	}
+}
+
+func TestMakeProbeKey(t *testing.T) {
+	got, err := MakeProbeKey()
+	if err != nil {
+		t.Fatalf("MakeProbeKey() = %v", err)
+	}
+	if len(got) != 32 {
+		t.Errorf("len(key) = %d, want 32", len(got))
+	}
+	other, err := MakeProbeKey()
+	if err != nil {
+		t.Fatalf("MakeProbeKey() = %v", err)
+	}
+	if cmp.Equal(got, other) {
+		t.Error("MakeProbeKey() made the same key twice")
+	}
+}
+
+func TestMakeDataPlaneKey(t *testing.T) {
+	got, err := MakeDataPlaneKey()
+	if err != nil {
+		t.Fatalf("MakeDataPlaneKey() = %v", err)
+	}
+	if len(got) != 32 {
+		t.Errorf("len(key) = %d, want 32", len(got))
+	}
+	other, err := MakeDataPlaneKey()
+	if err != nil {
+		t.Fatalf("MakeDataPlaneKey() = %v", err)
+	}
+	if cmp.Equal(got, other) {
+		t.Error("MakeDataPlaneKey() made the same key twice")
+	}
+}
+
+func TestApplySecret(t *testing.T) {
+	got := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "user-container",
+		}, {
+			Name: QueueContainerName,
+		}},
+	}
+	applySecret(&got, &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}})
+
+	want := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "user-container",
+		}, {
+			Name:         QueueContainerName,
+			VolumeMounts: []corev1.VolumeMount{secretVolumeMount},
+		}},
+		Volumes: []corev1.Volume{{
+			Name: secretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "foo-secret",
+				},
+			},
+		}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("applySecret() (-want, +got) = %v", diff)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+const (
+	userSocketVolumeName = "knative-socket"
+	userSocketVolumePath = "/var/run/knative"
+	userSocketPath       = userSocketVolumePath + "/user.sock"
+
+	// userSocketEnvKey tells the user container where to serve.
+	userSocketEnvKey = "K_SOCKET"
+	// queueUserSocketEnvKey tells the queue-proxy where to forward to.
+	queueUserSocketEnvKey = "USER_SOCKET"
+)
+
+var (
+	userSocketVolume = corev1.Volume{
+		Name: userSocketVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+
+	userSocketVolumeMount = corev1.VolumeMount{
+		Name:      userSocketVolumeName,
+		MountPath: userSocketVolumePath,
+	}
+)
+
+// applyUserSocket shares a directory between the user container and the
+// queue-proxy, if the revision asks for its user container to serve on a
+// Unix domain socket, and tells both of them the path of the socket.
+func applyUserSocket(podSpec *corev1.PodSpec, rev *v1alpha1.Revision) {
+	if rev.Annotations[serving.UserSocketAnnotationKey] != "true" {
+		return
+	}
+	podSpec.Volumes = append(podSpec.Volumes, userSocketVolume)
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		key := userSocketEnvKey
+		if c.Name == QueueContainerName {
+			key = queueUserSocketEnvKey
+		}
+		c.VolumeMounts = append(c.VolumeMounts, userSocketVolumeMount)
+		c.Env = append(c.Env, corev1.EnvVar{
+			Name:  key,
+			Value: userSocketPath,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestApplyUserSocket(t *testing.T) {
+	revision := func(socket string) *v1alpha1.Revision {
+		rev := &v1alpha1.Revision{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}
+		if socket != "" {
+			rev.Annotations = map[string]string{serving.UserSocketAnnotationKey: socket}
+		}
+		return rev
+	}
+	podSpec := func() corev1.PodSpec {
+		return corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: "user-container",
+			}, {
+				Name: QueueContainerName,
+			}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		rev  *v1alpha1.Revision
+		want corev1.PodSpec
+	}{{
+		name: "no annotation",
+		rev:  revision(""),
+		want: podSpec(),
+	}, {
+		name: "disabled",
+		rev:  revision("false"),
+		want: podSpec(),
+	}, {
+		name: "enabled",
+		rev:  revision("true"),
+		want: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:         "user-container",
+				VolumeMounts: []corev1.VolumeMount{userSocketVolumeMount},
+				Env: []corev1.EnvVar{{
+					Name:  userSocketEnvKey,
+					Value: userSocketPath,
+				}},
+			}, {
+				Name:         QueueContainerName,
+				VolumeMounts: []corev1.VolumeMount{userSocketVolumeMount},
+				Env: []corev1.EnvVar{{
+					Name:  queueUserSocketEnvKey,
+					Value: userSocketPath,
+				}},
+			}},
+			Volumes: []corev1.Volume{userSocketVolume},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := podSpec()
+			applyUserSocket(&got, test.rev)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("applyUserSocket() (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"math"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/kmeta"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler/revision/resources/names"
+)
+
+var (
+	// VPAGVK is the GroupVersionKind of VerticalPodAutoscalers.
+	VPAGVK = schema.GroupVersionKind{
+		Group:   "autoscaling.k8s.io",
+		Version: "v1beta2",
+		Kind:    "VerticalPodAutoscaler",
+	}
+	// VPAGVR is the GroupVersionResource of VerticalPodAutoscalers.
+	VPAGVR = VPAGVK.GroupVersion().WithResource("verticalpodautoscalers")
+)
+
+// vpaUpdateModeOff makes the VPA only compute recommendations, without ever
+// changing the resources of the pods.
+const vpaUpdateModeOff = "Off"
+
+// vpaRecommendation mirrors the recommendation in the status of a
+// VerticalPodAutoscaler. The VPA types are not vendored, so the
+// VerticalPodAutoscalers are handled as unstructured objects.
+type vpaRecommendation struct {
+	ContainerRecommendations []struct {
+		ContainerName string              `json:"containerName"`
+		Target        corev1.ResourceList `json:"target"`
+	} `json:"containerRecommendations"`
+}
+
+// MakeVPA makes a VerticalPodAutoscaler in recommendation mode for the
+// deployment of a revision.
+func MakeVPA(rev *v1alpha1.Revision) *unstructured.Unstructured {
+	vpa := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"apiVersion": appsv1.SchemeGroupVersion.String(),
+				"kind":       "Deployment",
+				"name":       names.Deployment(rev),
+			},
+			"updatePolicy": map[string]interface{}{
+				"updateMode": vpaUpdateModeOff,
+			},
+		},
+	}}
+	vpa.SetGroupVersionKind(VPAGVK)
+	vpa.SetName(names.VPA(rev))
+	vpa.SetNamespace(rev.Namespace)
+	vpa.SetLabels(makeLabels(rev))
+	vpa.SetOwnerReferences([]metav1.OwnerReference{*kmeta.NewControllerRef(rev)})
+	return vpa
+}
+
+// VPARecommendations returns the recommended resource requests of the
+// VerticalPodAutoscaler, keyed by container name.
+func VPARecommendations(vpa *unstructured.Unstructured) (map[string]corev1.ResourceList, error) {
+	raw, ok, err := unstructured.NestedMap(vpa.Object, "status", "recommendation")
+	if err != nil || !ok {
+		return nil, err
+	}
+	var rec vpaRecommendation
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &rec); err != nil {
+		return nil, err
+	}
+	recs := make(map[string]corev1.ResourceList, len(rec.ContainerRecommendations))
+	for _, cr := range rec.ContainerRecommendations {
+		recs[cr.ContainerName] = cr.Target
+	}
+	return recs, nil
+}
+
+// RecommendationDiffers returns whether any recommended resource differs
+// from the requested one by more than tolerance, relative to the request.
+// Resources that aren't requested always differ.
+func RecommendationDiffers(requests, recommendation corev1.ResourceList, tolerance float64) bool {
+	for name, rec := range recommendation {
+		req, ok := requests[name]
+		if !ok || req.IsZero() {
+			return true
+		}
+		want, have := float64(rec.MilliValue()), float64(req.MilliValue())
+		if math.Abs(want-have) > tolerance*have {
+			return true
+		}
+	}
+	return false
+}
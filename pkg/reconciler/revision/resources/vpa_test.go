@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestMakeVPA(t *testing.T) {
+	rev := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      "bar",
+			UID:       "1234",
+		},
+	}
+	got := MakeVPA(rev)
+
+	if got, want := got.GroupVersionKind(), VPAGVK; got != want {
+		t.Errorf("GVK = %v, want %v", got, want)
+	}
+	if got.GetName() != "bar" || got.GetNamespace() != "foo" {
+		t.Errorf("VPA is %s/%s, want foo/bar", got.GetNamespace(), got.GetName())
+	}
+	if !metav1.IsControlledBy(got, rev) {
+		t.Error("VPA is not controlled by the revision")
+	}
+	want := map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       "bar-deployment",
+		},
+		"updatePolicy": map[string]interface{}{
+			"updateMode": "Off",
+		},
+	}
+	if diff := cmp.Diff(want, got.Object["spec"]); diff != "" {
+		t.Errorf("spec (-want, +got) = %s", diff)
+	}
+}
+
+func TestVPARecommendations(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  map[string]interface{}
+		want    map[string]corev1.ResourceList
+		wantErr bool
+	}{{
+		name: "no status",
+	}, {
+		name: "recommendation",
+		status: map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{map[string]interface{}{
+					"containerName": "user-container",
+					"target": map[string]interface{}{
+						"cpu":    "250m",
+						"memory": "256Mi",
+					},
+					"upperBound": map[string]interface{}{
+						"cpu": "1",
+					},
+				}},
+			},
+		},
+		want: map[string]corev1.ResourceList{
+			"user-container": {
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+	}, {
+		name: "malformed",
+		status: map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": "lots",
+			},
+		},
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			vpa := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			if test.status != nil {
+				vpa.Object["status"] = test.status
+			}
+			got, err := VPARecommendations(vpa)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("VPARecommendations() = %v, wantErr %v", err, test.wantErr)
+			}
+			if len(got) == 0 && len(test.want) == 0 {
+				return
+			}
+			if !equality.Semantic.DeepEqual(test.want, got) {
+				t.Errorf("VPARecommendations() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecommendationDiffers(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	tests := []struct {
+		name string
+		rec  corev1.ResourceList
+		want bool
+	}{{
+		name: "within tolerance",
+		rec: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("950m"),
+			corev1.ResourceMemory: resource.MustParse("1100Mi"),
+		},
+	}, {
+		name: "much lower",
+		rec: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100m"),
+		},
+		want: true,
+	}, {
+		name: "much higher",
+		rec: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+		want: true,
+	}, {
+		name: "not requested",
+		rec: corev1.ResourceList{
+			corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+		},
+		want: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := RecommendationDiffers(requests, test.rec, 0.1); got != test.want {
+				t.Errorf("RecommendationDiffers() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -20,17 +20,20 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn/k8schain"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/types"
 	appsv1listers "k8s.io/client-go/listers/apps/v1"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	cachinglisters "knative.dev/caching/pkg/client/listers/caching/v1alpha1"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	"knative.dev/pkg/controller"
 	commonlogging "knative.dev/pkg/logging"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -41,10 +44,6 @@ import (
 	"knative.dev/serving/pkg/reconciler/revision/config"
 )
 
-type resolver interface {
-	Resolve(string, k8schain.Options, sets.String) (string, error)
-}
-
 // Reconciler implements controller.Reconciler for Revision resources.
 type Reconciler struct {
 	*reconciler.Base
@@ -56,9 +55,25 @@ type Reconciler struct {
 	deploymentLister    appsv1listers.DeploymentLister
 	serviceLister       corev1listers.ServiceLister
 	configMapLister     corev1listers.ConfigMapLister
+	secretLister        corev1listers.SecretLister
 
-	resolver    resolver
+	resolver    Resolver
 	configStore reconciler.ConfigStore
+
+	// enqueueAfter enqueues a Revision after the given delay.
+	enqueueAfter func(interface{}, time.Duration)
+
+	// newProbeKey makes the key the probes of the pods of a new Revision are
+	// signed with, resources.MakeProbeKey if nil. A seam for tests.
+	newProbeKey func() ([]byte, error)
+
+	// statusLimiter batches the status updates of the Revisions.
+	statusLimiter *reconciler.StatusLimiter
+
+	// reportedFailures holds, by the types.NamespacedName of each Revision,
+	// the last transition times of the conditions of its pods reported by
+	// reportFailure.
+	reportedFailures sync.Map
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -84,6 +99,7 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	// The resource may no longer exist, in which case we stop processing.
 	if apierrs.IsNotFound(err) {
 		logger.Errorf("revision %q in work queue no longer exists", key)
+		c.reportedFailures.Delete(types.NamespacedName{Namespace: namespace, Name: name})
 		return nil
 	} else if err != nil {
 		return err
@@ -106,6 +122,12 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 			"Failed to update status for Revision %q: %v", rev.Name, err)
 		return err
 	}
+	if cond := rev.Status.GetCondition(v1alpha1.RevisionConditionReady); cond.IsFalse() {
+		if before := original.Status.GetCondition(v1alpha1.RevisionConditionReady); !before.IsFalse() {
+			c.Recorder.Eventf(rev, corev1.EventTypeWarning, "RevisionFailed",
+				"Revision %q failed with reason %q: %s", rev.Name, cond.Reason, cond.Message)
+		}
+	}
 	if reconcileErr != nil {
 		c.Recorder.Event(rev, corev1.EventTypeWarning, "InternalError", reconcileErr.Error())
 		return reconcileErr
@@ -180,6 +202,15 @@ func (c *Reconciler) reconcile(ctx context.Context, rev *v1alpha1.Revision) erro
 	}{{
 		name: "image digest",
 		f:    c.reconcileDigest,
+	}, {
+		// Created ahead of the Deployment for its pods to start with it,
+		// and ahead of the data-plane config signed with its key.
+		name: "secret",
+		f:    c.reconcileSecret,
+	}, {
+		// Created ahead of the Deployment for its pods to start with it.
+		name: "data-plane config",
+		f:    c.reconcileDataPlaneConfig,
 	}, {
 		name: "user deployment",
 		f:    c.reconcileDeployment,
@@ -189,6 +220,12 @@ func (c *Reconciler) reconcile(ctx context.Context, rev *v1alpha1.Revision) erro
 	}, {
 		name: "PA",
 		f:    c.reconcilePA,
+	}, {
+		name: "VPA",
+		f:    c.reconcileVPA,
+	}, {
+		name: "queue log level",
+		f:    c.reconcileQueueLogLevel,
 	}}
 
 	for _, phase := range phases {
@@ -234,8 +271,26 @@ func (c *Reconciler) updateStatus(desired *v1alpha1.Revision) (*v1alpha1.Revisio
 	if reflect.DeepEqual(rev.Status, desired.Status) {
 		return rev, nil
 	}
+	key := desired.Namespace + "/" + desired.Name
+	if delay := c.statusLimiter.Delay(key, urgentStatusChange(&rev.Status, &desired.Status)); delay > 0 {
+		// The status is computed again, with whatever else changed, by the
+		// time it may be updated.
+		c.enqueueAfter(desired, delay)
+		return rev, nil
+	}
 	// Don't modify the informers copy
 	existing := rev.DeepCopy()
 	existing.Status = desired.Status
 	return c.ServingClientSet.ServingV1alpha1().Revisions(desired.Namespace).UpdateStatus(existing)
 }
+
+// urgentStatusChange reports whether the change of the status of a Revision
+// from before to after should be updated right away.
+func urgentStatusChange(before, after *v1alpha1.RevisionStatus) bool {
+	if reconciler.UrgentStatusChange(&before.Status, &after.Status) {
+		return true
+	}
+	b, a := before.DeepCopy(), after.DeepCopy()
+	b.Status, a.Status = duckv1beta1.Status{}, duckv1beta1.Status{}
+	return !equality.Semantic.DeepEqual(b, a)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package revision
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -30,8 +31,9 @@ import (
 	fakeimageinformer "knative.dev/caching/pkg/client/injection/informers/caching/v1alpha1/image/fake"
 	fakekubeclient "knative.dev/pkg/injection/clients/kubeclient/fake"
 	fakedeploymentinformer "knative.dev/pkg/injection/informers/kubeinformers/appsv1/deployment/fake"
-	_ "knative.dev/pkg/injection/informers/kubeinformers/corev1/configmap/fake"
+	fakeconfigmapinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/configmap/fake"
 	fakeendpointsinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/endpoints/fake"
+	fakesecretinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/secret/fake"
 	_ "knative.dev/pkg/injection/informers/kubeinformers/corev1/service/fake"
 	fakeservingclient "knative.dev/serving/pkg/client/injection/client/fake"
 	fakepainformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
@@ -46,6 +48,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientgotesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/configmap"
@@ -56,14 +59,18 @@ import (
 	"knative.dev/pkg/metrics"
 	_ "knative.dev/pkg/metrics/testing"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/activator"
 	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/autoscaler"
 	"knative.dev/serving/pkg/deployment"
 	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/queue"
+	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
 	resourcenames "knative.dev/serving/pkg/reconciler/revision/resources/names"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 
 	. "knative.dev/pkg/reconciler/testing"
 )
@@ -154,6 +161,11 @@ func newTestControllerWithConfig(t *testing.T, deploymentConfig *deployment.Conf
 			"panic-window":                            "10s",
 			"tick-interval":                           "2s",
 		},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      tracingconfig.ConfigName,
+		},
 	}, getTestDeploymentConfigMap()}
 
 	cms = append(cms, configs...)
@@ -230,6 +242,16 @@ func addResourcesToInformers(t *testing.T, ctx context.Context, rev *v1alpha1.Re
 		fakeimageinformer.Get(ctx).Informer().GetIndexer().Add(image)
 	}
 
+	secretName := resourcenames.Secret(rev)
+	secret, err := fakekubeclient.Get(ctx).CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) && haveBuild {
+		// If we're doing a Build this won't exist yet.
+	} else if err != nil {
+		t.Errorf("Secrets.Get(%v) = %v", secretName, err)
+	} else {
+		fakesecretinformer.Get(ctx).Informer().GetIndexer().Add(secret)
+	}
+
 	deploymentName := resourcenames.Deployment(rev)
 	deployment, err := fakekubeclient.Get(ctx).AppsV1().Deployments(ns).Get(deploymentName, metav1.GetOptions{})
 	if apierrs.IsNotFound(err) && haveBuild {
@@ -742,3 +764,341 @@ func TestGlobalResyncOnConfigMapUpdateDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestAffectedByConfig(t *testing.T) {
+	pinned := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{resources.IstioOutboundIPRangeAnnotation: "10.10.10.0/24"},
+		},
+	}
+	tests := []struct {
+		name    string
+		config  string
+		changed []string
+		rev     *v1alpha1.Revision
+		want    bool
+	}{{
+		name:    "deployment config",
+		config:  deployment.ConfigName,
+		changed: []string{"QueueSidecarImage"},
+		rev:     pinned,
+		want:    true,
+	}, {
+		name:    "forwarded headers",
+		config:  network.ConfigName,
+		changed: []string{"ForwardedHeaders"},
+		rev:     pinned,
+		want:    true,
+	}, {
+		name:    "outbound ip ranges",
+		config:  network.ConfigName,
+		changed: []string{"IstioOutboundIPRanges"},
+		rev:     &v1alpha1.Revision{},
+		want:    true,
+	}, {
+		name:    "outbound ip ranges overridden",
+		config:  network.ConfigName,
+		changed: []string{"IstioOutboundIPRanges"},
+		rev:     pinned,
+	}, {
+		name:    "route fields",
+		config:  network.ConfigName,
+		changed: []string{"DomainTemplate", "AutoTLS", "DefaultClusterIngressClass"},
+		rev:     &v1alpha1.Revision{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := affectedByConfig(test.config, sets.NewString(test.changed...), test.rev); got != test.want {
+				t.Errorf("affectedByConfig() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQueueLogLevel(t *testing.T) {
+	defer logtesting.ClearAll()
+	rev := testRevision()
+	loggingConfig := func(overrides map[string]string) *corev1.ConfigMap {
+		data := map[string]string{
+			"zap-logger-config":   "{\"level\": \"error\",\n\"outputPaths\": [\"stdout\"],\n\"errorOutputPaths\": [\"stderr\"],\n\"encoding\": \"json\"}",
+			"loglevel.queueproxy": "info",
+		}
+		for k, v := range overrides {
+			data[k] = v
+		}
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: system.Namespace(),
+				Name:      logging.ConfigMapName(),
+			},
+			Data: data,
+		}
+	}
+	ctx, _, ctrl, watcher := newTestControllerWithConfig(t, getTestDeploymentConfig(),
+		loggingConfig(map[string]string{"loglevel.queueproxy." + rev.Namespace + "." + rev.Name: "debug"}))
+
+	kubeClient := fakekubeclient.Get(ctx)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: rev.Namespace,
+			Name:      "test-rev-pod",
+			Labels:    map[string]string{serving.RevisionLabelKey: rev.Name},
+		},
+	}
+	kubeClient.CoreV1().Pods(rev.Namespace).Create(pod)
+
+	annotations := func() (string, string) {
+		t.Helper()
+		pod, err := kubeClient.CoreV1().Pods(rev.Namespace).Get(pod.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Pods.Get() = %v", err)
+		}
+		d, err := kubeClient.AppsV1().Deployments(rev.Namespace).Get(resourcenames.Deployment(rev), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Deployments.Get() = %v", err)
+		}
+		fakedeploymentinformer.Get(ctx).Informer().GetIndexer().Update(d)
+		return pod.Annotations[serving.QueueLogLevelAnnotationKey], d.Annotations[serving.QueueLogLevelAnnotationKey]
+	}
+
+	// The Deployment isn't in the informer yet after the first reconcile.
+	createRevision(t, ctx, ctrl, rev)
+	if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if podLevel, deploymentLevel := annotations(); podLevel != "debug" || deploymentLevel != "debug" {
+		t.Errorf("Log level annotations = %q, %q, want debug", podLevel, deploymentLevel)
+	}
+
+	watcher.OnChange(loggingConfig(nil))
+	kubeClient.ClearActions()
+	if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if _, deploymentLevel := annotations(); deploymentLevel != "" {
+		t.Errorf("Deployment log level annotation = %q, want none", deploymentLevel)
+	}
+	// The fake client can't remove fields with a patch, so check the patch.
+	want, err := queueLogLevelPatch("")
+	if err != nil {
+		t.Fatalf("queueLogLevelPatch() = %v", err)
+	}
+	var patched bool
+	for _, action := range kubeClient.Actions() {
+		if patch, ok := action.(clientgotesting.PatchAction); ok && patch.GetName() == pod.Name {
+			patched = true
+			if got := string(patch.GetPatch()); got != string(want) {
+				t.Errorf("Pod patch = %s, want %s", got, want)
+			}
+		}
+	}
+	if !patched {
+		t.Error("The pod was not patched")
+	}
+}
+
+func TestDataPlaneConfig(t *testing.T) {
+	defer logtesting.ClearAll()
+	deploymentConfigMap := getTestDeploymentConfigMap()
+	deploymentConfigMap.Data["enableDataPlaneConfig"] = "true"
+	ctx, _, ctrl, watcher := newTestControllerWithConfig(t, getTestDeploymentConfig(), deploymentConfigMap)
+	kubeClient := fakekubeclient.Get(ctx)
+	defaultTracing, _ := tracingconfig.NewTracingConfigFromMap(nil)
+
+	var key []byte
+	dataPlaneConfig := func() queue.DataPlaneConfig {
+		t.Helper()
+		secret, err := kubeClient.CoreV1().Secrets(testNamespace).Get("test-rev-secret", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Secrets.Get() = %v", err)
+		}
+		fakesecretinformer.Get(ctx).Informer().GetIndexer().Add(secret)
+		if key == nil {
+			key = secret.Data[queue.DataPlaneKeyKey]
+		} else if got := secret.Data[queue.DataPlaneKeyKey]; !bytes.Equal(got, key) {
+			t.Errorf("Data-plane key changed from %x to %x", key, got)
+		}
+		cm, err := kubeClient.CoreV1().ConfigMaps(testNamespace).Get("test-rev-dataplane", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("ConfigMaps.Get() = %v", err)
+		}
+		fakeconfigmapinformer.Get(ctx).Informer().GetIndexer().Add(cm)
+		cfg, err := queue.DecodeDataPlaneConfig([]byte(cm.Data[queue.DataPlaneConfigKey]),
+			[]byte(cm.Data[queue.DataPlaneSignatureKey]), key)
+		if err != nil {
+			t.Fatalf("DecodeDataPlaneConfig() = %v", err)
+		}
+		return *cfg
+	}
+
+	rev := createRevision(t, ctx, ctrl, testRevision())
+	if got, want := dataPlaneConfig(), (queue.DataPlaneConfig{TimeoutSeconds: 60, Tracing: *defaultTracing}); !cmp.Equal(got, want) {
+		t.Errorf("DataPlaneConfig = %+v, want %+v", got, want)
+	}
+
+	rev = rev.DeepCopy()
+	rev.Annotations[serving.TimeoutOverrideAnnotationKey] = "10"
+	rev.Annotations[serving.RateLimitAnnotationKey] = "5"
+	updateRevision(t, ctx, ctrl, rev)
+	if got, want := dataPlaneConfig(), (queue.DataPlaneConfig{TimeoutSeconds: 10, RateLimit: 5, Tracing: *defaultTracing}); !cmp.Equal(got, want) {
+		t.Errorf("DataPlaneConfig = %+v, want %+v", got, want)
+	}
+
+	// The log level override goes to the data-plane configuration rather
+	// than to the pods.
+	watcher.OnChange(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: system.Namespace(),
+			Name:      logging.ConfigMapName(),
+		},
+		Data: map[string]string{
+			"zap-logger-config":                    "{\"level\": \"error\",\n\"outputPaths\": [\"stdout\"],\n\"errorOutputPaths\": [\"stderr\"],\n\"encoding\": \"json\"}",
+			"loglevel.queueproxy":                  "info",
+			"loglevel.queueproxy." + testNamespace: "debug",
+		},
+	})
+	kubeClient.ClearActions()
+	if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+	if got, want := dataPlaneConfig(), (queue.DataPlaneConfig{TimeoutSeconds: 10, RateLimit: 5, LogLevel: "debug", Tracing: *defaultTracing}); !cmp.Equal(got, want) {
+		t.Errorf("DataPlaneConfig = %+v, want %+v", got, want)
+	}
+	for _, action := range kubeClient.Actions() {
+		if action.GetVerb() == "patch" {
+			t.Errorf("Unexpected patch of %s", action.GetResource().Resource)
+		}
+	}
+}
+
+func TestRegistrationSecret(t *testing.T) {
+	defer logtesting.ClearAll()
+	deploymentConfigMap := getTestDeploymentConfigMap()
+	deploymentConfigMap.Data["enableActivatorRegistration"] = "true"
+	ctx, _, ctrl, _ := newTestControllerWithConfig(t, getTestDeploymentConfig(), deploymentConfigMap)
+	kubeClient := fakekubeclient.Get(ctx)
+
+	createRevision(t, ctx, ctrl, testRevision())
+
+	key, err := kubeClient.CoreV1().Secrets(system.Namespace()).Get(activator.RegistrationKeySecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Secrets.Get(%s) = %v", activator.RegistrationKeySecretName, err)
+	}
+	secret, err := kubeClient.CoreV1().Secrets(testNamespace).Get("test-rev-secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Secrets.Get(test-rev-secret) = %v", err)
+	}
+	want := queue.RegistrationToken(key.Data[activator.RegistrationKeySecretKey], testNamespace, "test-rev", "test-rev-uid")
+	if got := secret.Data[queue.RegistrationTokenKey]; string(got) != string(want) {
+		t.Errorf("Registration token = %s, want %s", got, want)
+	}
+}
+
+func TestWindowsQueueSidecarImageMissing(t *testing.T) {
+	defer logtesting.ClearAll()
+	deploymentConfigMap := getTestDeploymentConfigMap()
+	deploymentConfigMap.Data["enableWindows"] = "true"
+	ctx, _, ctrl, _ := newTestControllerWithConfig(t, getTestDeploymentConfig(), deploymentConfigMap)
+
+	rev := testRevision()
+	rev.Annotations[serving.NodeOSAnnotationKey] = serving.NodeOSWindows
+	fakeservingclient.Get(ctx).ServingV1alpha1().Revisions(rev.Namespace).Create(rev)
+	fakerevisioninformer.Get(ctx).Informer().GetIndexer().Add(rev)
+	if err := ctrl.Reconciler.Reconcile(context.Background(), KeyOrDie(rev)); err != nil {
+		t.Fatalf("Reconcile() = %v", err)
+	}
+
+	rev, err := fakeservingclient.Get(ctx).ServingV1alpha1().Revisions(rev.Namespace).Get(rev.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Revisions.Get() = %v", err)
+	}
+	cond := rev.Status.GetCondition(v1alpha1.RevisionConditionResourcesAvailable)
+	if cond == nil || !cond.IsFalse() || cond.Reason != "WindowsQueueSidecarImageMissing" {
+		t.Errorf("ResourcesAvailable = %v, want False with reason WindowsQueueSidecarImageMissing", cond)
+	}
+	if _, err := fakekubeclient.Get(ctx).AppsV1().Deployments(rev.Namespace).Get(resourcenames.Deployment(rev), metav1.GetOptions{}); !apierrs.IsNotFound(err) {
+		t.Errorf("Deployments.Get() = %v, want not found", err)
+	}
+}
+
+func TestReportFailureOncePerTransition(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	c := &Reconciler{Base: &reconciler.Base{Recorder: recorder}}
+	rev := testRevision()
+	report := func(transition time.Time) {
+		c.reportFailure(rev, "PodScheduled", metav1.NewTime(transition), "Unschedulable", "Pods can't be scheduled")
+	}
+	events := func() int {
+		n := 0
+		for {
+			select {
+			case <-recorder.Events:
+				n++
+			default:
+				return n
+			}
+		}
+	}
+
+	first := time.Now()
+	report(first)
+	report(first)
+	if got := events(); got != 1 {
+		t.Errorf("Events after the condition appeared = %d, want 1", got)
+	}
+	report(first.Add(time.Minute))
+	if got := events(); got != 1 {
+		t.Errorf("Events after the condition transitioned = %d, want 1", got)
+	}
+	c.clearFailure(rev, "PodScheduled")
+	report(first.Add(time.Minute))
+	if got := events(); got != 1 {
+		t.Errorf("Events after the condition reappeared = %d, want 1", got)
+	}
+}
+
+func TestQueueLogLevelResync(t *testing.T) {
+	rev := testRevision()
+	other := testRevision()
+	other.Name = "other-rev"
+
+	ctx, _ := SetupFakeContext(t)
+	revisionInformer := fakerevisioninformer.Get(ctx).Informer()
+	revisionInformer.GetIndexer().Add(rev)
+	revisionInformer.GetIndexer().Add(other)
+
+	var got []string
+	resync := queueLogLevelResync(revisionInformer, func(obj interface{}) {
+		got = append(got, obj.(*v1alpha1.Revision).Name)
+	})
+
+	config := func(levels map[string]string) *logging.Config {
+		cfg, err := logging.NewConfigFromMap(levels)
+		if err != nil {
+			t.Fatalf("NewConfigFromMap() = %v", err)
+		}
+		return cfg
+	}
+	resync(logging.ConfigMapName(), config(nil))
+	resync(network.ConfigName, &network.Config{})
+	if len(got) != 0 {
+		t.Errorf("Resynced %v on the initial config, want none", got)
+	}
+
+	resync(logging.ConfigMapName(), config(map[string]string{
+		"loglevel.queueproxy": "debug",
+		"loglevel.queueproxy." + rev.Namespace + "." + rev.Name: "debug",
+	}))
+	if want := []string{rev.Name}; !cmp.Equal(got, want) {
+		t.Errorf("Resynced %v, want %v", got, want)
+	}
+
+	got = nil
+	resync(logging.ConfigMapName(), config(map[string]string{
+		"loglevel.queueproxy." + rev.Namespace: "warn",
+	}))
+	if want := []string{rev.Name, other.Name}; !cmp.Equal(sets.NewString(got...), sets.NewString(want...)) {
+		t.Errorf("Resynced %v, want %v", got, want)
+	}
+}
@@ -19,10 +19,12 @@ package revision
 import (
 	"context"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgotesting "k8s.io/client-go/testing"
 	caching "knative.dev/caching/pkg/apis/caching/v1alpha1"
@@ -32,6 +34,7 @@ import (
 	logtesting "knative.dev/pkg/logging/testing"
 	autoscalingv1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
 	"knative.dev/serving/pkg/apis/networking"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	"knative.dev/serving/pkg/autoscaler"
@@ -40,6 +43,8 @@ import (
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/revision/config"
 	"knative.dev/serving/pkg/reconciler/revision/resources"
+	presources "knative.dev/serving/pkg/resources"
+	tracingconfig "knative.dev/serving/pkg/tracing/config"
 
 	. "knative.dev/pkg/reconciler/testing"
 	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
@@ -75,6 +80,7 @@ func TestReconcile(t *testing.T) {
 		WantCreates: []runtime.Object{
 			// The first reconciliation of a Revision creates the following resources.
 			pa("foo", "first-reconcile"),
+			secret("foo", "first-reconcile"),
 			deploy("foo", "first-reconcile"),
 			image("foo", "first-reconcile"),
 		},
@@ -98,6 +104,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantCreates: []runtime.Object{
 			// We still see the following creates before the failure is induced.
+			secret("foo", "update-status-failure"),
 			deploy("foo", "update-status-failure"),
 			image("foo", "update-status-failure"),
 		},
@@ -125,6 +132,7 @@ func TestReconcile(t *testing.T) {
 		WantCreates: []runtime.Object{
 			// We still see the following creates before the failure is induced.
 			pa("foo", "create-pa-failure"),
+			secret("foo", "create-pa-failure"),
 			deploy("foo", "create-pa-failure"),
 			image("foo", "create-pa-failure"),
 		},
@@ -152,6 +160,7 @@ func TestReconcile(t *testing.T) {
 		},
 		WantCreates: []runtime.Object{
 			// We still see the following creates before the failure is induced.
+			secret("foo", "create-user-deploy-failure"),
 			deploy("foo", "create-user-deploy-failure"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
@@ -175,6 +184,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "stable-reconcile"),
 			deploy("foo", "stable-reconcile"),
 			image("foo", "stable-reconcile"),
+			secret("foo", "stable-reconcile"),
 		},
 		// No changes are made to any objects.
 		Key: "foo/stable-reconcile",
@@ -192,6 +202,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "needs-upgrade"),
 			deploy("foo", "needs-upgrade"),
 			image("foo", "needs-upgrade"),
+			secret("foo", "needs-upgrade"),
 		},
 		WantPatches: []clientgotesting.PatchActionImpl{{
 			ActionImpl: clientgotesting.ActionImpl{
@@ -211,11 +222,40 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "fix-containers"),
 			changeContainers(deploy("foo", "fix-containers")),
 			image("foo", "fix-containers"),
+			secret("foo", "fix-containers"),
 		},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: deploy("foo", "fix-containers"),
 		}},
 		Key: "foo/fix-containers",
+	}, {
+		Name: "keep deployment fields we don't own",
+		// Test that we leave alone the changes others make to the fields of
+		// the deployment we don't set.
+		Objects: []runtime.Object{
+			rev("foo", "keep-tweaks",
+				WithLogURL, AllUnknownConditions),
+			pa("foo", "keep-tweaks"),
+			addToleration(deploy("foo", "keep-tweaks")),
+			image("foo", "keep-tweaks"),
+			secret("foo", "keep-tweaks"),
+		},
+		Key: "foo/keep-tweaks",
+	}, {
+		Name: "remove deployment fields we no longer set",
+		// Test that we remove the fields we applied before but no longer want.
+		Objects: []runtime.Object{
+			rev("foo", "remove-stale",
+				WithLogURL, AllUnknownConditions),
+			pa("foo", "remove-stale"),
+			addStaleEnv(deploy("foo", "remove-stale")),
+			image("foo", "remove-stale"),
+			secret("foo", "remove-stale"),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: deploy("foo", "remove-stale"),
+		}},
+		Key: "foo/remove-stale",
 	}, {
 		Name: "failure updating deployment",
 		// Test that we handle an error updating the deployment properly.
@@ -229,6 +269,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "failure-update-deploy"),
 			changeContainers(deploy("foo", "failure-update-deploy")),
 			image("foo", "failure-update-deploy"),
+			secret("foo", "failure-update-deploy"),
 		},
 		WantUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: deploy("foo", "failure-update-deploy"),
@@ -250,6 +291,7 @@ func TestReconcile(t *testing.T) {
 				WithNoTraffic("NoTraffic", "This thing is inactive.")),
 			deploy("foo", "stable-deactivation"),
 			image("foo", "stable-deactivation"),
+			secret("foo", "stable-deactivation"),
 		},
 		Key: "foo/stable-deactivation",
 	}, {
@@ -260,6 +302,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "pa-ready", WithTraffic, WithPAStatusService("new-stuff")),
 			deploy("foo", "pa-ready"),
 			image("foo", "pa-ready"),
+			secret("foo", "pa-ready"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pa-ready", withK8sServiceName("new-stuff"),
@@ -284,6 +327,7 @@ func TestReconcile(t *testing.T) {
 				WithBufferedTraffic("Something", "This is something longer")),
 			deploy("foo", "pa-not-ready"),
 			image("foo", "pa-not-ready"),
+			secret("foo", "pa-not-ready"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pa-not-ready",
@@ -305,6 +349,7 @@ func TestReconcile(t *testing.T) {
 				WithNoTraffic("NoTraffic", "This thing is inactive.")),
 			deploy("foo", "pa-inactive"),
 			image("foo", "pa-inactive"),
+			secret("foo", "pa-inactive"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pa-inactive",
@@ -326,6 +371,7 @@ func TestReconcile(t *testing.T) {
 				WithPAStatusService("pa-inactive-svc")),
 			deploy("foo", "pa-inactive"),
 			image("foo", "pa-inactive"),
+			secret("foo", "pa-inactive"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pa-inactive",
@@ -348,6 +394,7 @@ func TestReconcile(t *testing.T) {
 				WithTraffic, WithPAStatusService("fix-mutated-pa")),
 			deploy("foo", "fix-mutated-pa"),
 			image("foo", "fix-mutated-pa"),
+			secret("foo", "fix-mutated-pa"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "fix-mutated-pa",
@@ -361,6 +408,31 @@ func TestReconcile(t *testing.T) {
 				WithPAStatusService("fix-mutated-pa")),
 		}},
 		Key: "foo/fix-mutated-pa",
+	}, {
+		Name: "mutated pa of a ready revision",
+		// A ready Revision stays ready while its PA is brought back to the
+		// required shape.
+		Objects: []runtime.Object{
+			rev("foo", "fix-mutated-pa-ready",
+				withK8sServiceName("fix-mutated-pa-ready"), WithLogURL, MarkRevisionReady),
+			pa("foo", "fix-mutated-pa-ready", WithProtocolType(networking.ProtocolH2C),
+				WithPAStatusService("fix-mutated-pa-ready"),
+				WithBufferedTraffic("Queued", "Requests are buffered.")),
+			deploy("foo", "fix-mutated-pa-ready"),
+			image("foo", "fix-mutated-pa-ready"),
+			secret("foo", "fix-mutated-pa-ready"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "fix-mutated-pa-ready",
+				withK8sServiceName("fix-mutated-pa-ready"), WithLogURL, MarkRevisionReady,
+				MarkActivating("Queued", "Requests are buffered.")),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: pa("foo", "fix-mutated-pa-ready",
+				WithPAStatusService("fix-mutated-pa-ready"),
+				WithBufferedTraffic("Queued", "Requests are buffered.")),
+		}},
+		Key: "foo/fix-mutated-pa-ready",
 	}, {
 		Name: "mutated pa gets error during the fix",
 		// Same as above, but will fail during the update.
@@ -371,6 +443,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "fix-mutated-pa-fail", WithProtocolType(networking.ProtocolH2C)),
 			deploy("foo", "fix-mutated-pa-fail"),
 			image("foo", "fix-mutated-pa-fail"),
+			secret("foo", "fix-mutated-pa-fail"),
 		},
 		WantErr: true,
 		WithReactors: []clientgotesting.ReactionFunc{
@@ -396,6 +469,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "deploy-timeout"), // pa can't be ready since deployment times out.
 			timeoutDeploy(deploy("foo", "deploy-timeout")),
 			image("foo", "deploy-timeout"),
+			secret("foo", "deploy-timeout"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "deploy-timeout",
@@ -408,8 +482,99 @@ func TestReconcile(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "ProgressDeadlineExceeded",
 				"Revision %s not ready due to Deployment timeout",
 				"deploy-timeout"),
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "deploy-timeout", "ProgressDeadlineExceeded", "Unable to create pods for more than 120 seconds."),
 		},
 		Key: "foo/deploy-timeout",
+	}, {
+		Name: "surface quota rejection",
+		// Test the propagation of a ReplicaFailure from the Deployment, which
+		// is how pods rejected by a ResourceQuota or LimitRange show up.
+		Objects: []runtime.Object{
+			rev("foo", "over-quota",
+				withK8sServiceName("the-taxman"), WithLogURL, MarkActive),
+			pa("foo", "over-quota"),
+			quotaDeploy(deploy("foo", "over-quota")),
+			image("foo", "over-quota"),
+			secret("foo", "over-quota"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "over-quota",
+				WithLogURL, AllUnknownConditions,
+				MarkResourcesExhausted("FailedCreate", "exceeded quota: compute-resources")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "over-quota", "ResourcesExhausted", "exceeded quota: compute-resources"),
+		},
+		Key: "foo/over-quota",
+	}, {
+		Name: "ready revision scaling up over quota",
+		// Pods rejected while a ready Revision scales up, e.g. from zero, are
+		// reported in an event, but the Revision stays ready.
+		Objects: []runtime.Object{
+			rev("foo", "over-quota-ready",
+				withK8sServiceName("over-quota-ready"), WithLogURL, MarkRevisionReady,
+				MarkInactive("NoTraffic", "This thing is inactive.")),
+			pa("foo", "over-quota-ready", WithPAStatusService("over-quota-ready"),
+				WithBufferedTraffic("Queued", "Requests are buffered.")),
+			quotaDeploy(deploy("foo", "over-quota-ready")),
+			image("foo", "over-quota-ready"),
+			secret("foo", "over-quota-ready"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "over-quota-ready",
+				withK8sServiceName("over-quota-ready"), WithLogURL, MarkRevisionReady,
+				MarkActivating("Queued", "Requests are buffered.")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "FailedCreate", "Pods of Revision %s can't be created: %s", "over-quota-ready", "exceeded quota: compute-resources"),
+		},
+		Key: "foo/over-quota-ready",
+	}, {
+		Name: "windows nodes not enabled",
+		// Test that a revision requesting Windows nodes is marked as failed
+		// rather than deployed when they are not enabled.
+		Objects: []runtime.Object{
+			rev("foo", "windows", withNodeOS(serving.NodeOSWindows)),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakePA(rev("foo", "windows", withNodeOS(serving.NodeOSWindows))),
+			secret("foo", "windows"),
+			resources.MakeImageCache(rev("foo", "windows", withNodeOS(serving.NodeOSWindows))),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "windows", withNodeOS(serving.NodeOSWindows),
+				WithLogURL, AllUnknownConditions,
+				MarkResourcesUnavailable("WindowsDisabled",
+					"Revision requests Windows nodes, but they are not enabled in config-deployment")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "windows", "WindowsDisabled",
+				"Revision requests Windows nodes, but they are not enabled in config-deployment"),
+		},
+		Key: "foo/windows",
+	}, {
+		Name: "guaranteed pods with mesh sidecar",
+		// Test that a revision requesting Guaranteed pods is marked as failed
+		// rather than deployed when a mesh sidecar is injected into them.
+		Objects: []runtime.Object{
+			rev("foo", "guaranteed", withQoSClass(corev1.PodQOSGuaranteed)),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakePA(rev("foo", "guaranteed", withQoSClass(corev1.PodQOSGuaranteed))),
+			secret("foo", "guaranteed"),
+			resources.MakeImageCache(rev("foo", "guaranteed", withQoSClass(corev1.PodQOSGuaranteed))),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "guaranteed", withQoSClass(corev1.PodQOSGuaranteed),
+				WithLogURL, AllUnknownConditions,
+				MarkResourcesUnavailable("GuaranteedWithSidecar",
+					`Revision requests Guaranteed pods, but they get a mesh sidecar injected; set sidecar.istio.io/inject to "false"`)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "guaranteed", "GuaranteedWithSidecar",
+				`Revision requests Guaranteed pods, but they get a mesh sidecar injected; set sidecar.istio.io/inject to "false"`),
+		},
+		Key: "foo/guaranteed",
 	}, {
 		Name: "surface ImagePullBackoff",
 		// Test the propagation of ImagePullBackoff from user container.
@@ -420,6 +585,7 @@ func TestReconcile(t *testing.T) {
 			pod("foo", "pull-backoff", WithWaitingContainer("user-container", "ImagePullBackoff", "can't pull it")),
 			timeoutDeploy(deploy("foo", "pull-backoff")),
 			image("foo", "pull-backoff"),
+			secret("foo", "pull-backoff"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pull-backoff",
@@ -427,6 +593,9 @@ func TestReconcile(t *testing.T) {
 				MarkResourcesUnavailable("ImagePullBackoff", "can't pull it")),
 		}},
 		Key: "foo/pull-backoff",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "pull-backoff", "ImagePullBackoff", "can't pull it"),
+		},
 	}, {
 		Name: "surface pod errors",
 		// Test the propagation of the termination state of a Pod into the revision.
@@ -440,12 +609,16 @@ func TestReconcile(t *testing.T) {
 			pod("foo", "pod-error", WithFailingContainer("user-container", 5, "I failed man!")),
 			deploy("foo", "pod-error"),
 			image("foo", "pod-error"),
+			secret("foo", "pod-error"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pod-error",
 				WithLogURL, AllUnknownConditions, MarkContainerExiting(5, "I failed man!")),
 		}},
 		Key: "foo/pod-error",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "pod-error", "ExitCode5", "Container failed with: I failed man!"),
+		},
 	}, {
 		Name: "surface pod schedule errors",
 		// Test the propagation of the scheduling errors of Pod into the revision.
@@ -458,12 +631,40 @@ func TestReconcile(t *testing.T) {
 			pod("foo", "pod-schedule-error", WithUnschedulableContainer("Insufficient energy", "Unschedulable")),
 			deploy("foo", "pod-schedule-error"),
 			image("foo", "pod-schedule-error"),
+			secret("foo", "pod-schedule-error"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "pod-schedule-error",
 				WithLogURL, AllUnknownConditions, MarkResourcesUnavailable("Insufficient energy", "Unschedulable")),
 		}},
 		Key: "foo/pod-schedule-error",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "pod-schedule-error", "Insufficient energy", "Unschedulable"),
+		},
+	}, {
+		Name: "ready revision scaling up with pod schedule errors",
+		// Pods that can't be scheduled while a ready Revision scales up, e.g.
+		// from zero, are reported in an event, but the Revision stays ready.
+		Objects: []runtime.Object{
+			rev("foo", "pod-schedule-error-ready",
+				withK8sServiceName("pod-schedule-error-ready"), WithLogURL, MarkRevisionReady,
+				MarkInactive("NoTraffic", "This thing is inactive.")),
+			pa("foo", "pod-schedule-error-ready", WithPAStatusService("pod-schedule-error-ready"),
+				WithBufferedTraffic("Queued", "Requests are buffered.")),
+			pod("foo", "pod-schedule-error-ready", WithUnschedulableContainer("Insufficient energy", "Unschedulable")),
+			deploy("foo", "pod-schedule-error-ready"),
+			image("foo", "pod-schedule-error-ready"),
+			secret("foo", "pod-schedule-error-ready"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "pod-schedule-error-ready",
+				withK8sServiceName("pod-schedule-error-ready"), WithLogURL, MarkRevisionReady,
+				MarkActivating("Queued", "Requests are buffered.")),
+		}},
+		Key: "foo/pod-schedule-error-ready",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "Insufficient energy", "Pods of Revision %s can't be scheduled: %s", "pod-schedule-error-ready", "Unschedulable"),
+		},
 	}, {
 		Name: "ready steady state",
 		// Test the transition that Reconcile makes when Endpoints become ready on the
@@ -476,6 +677,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "steady-ready", WithTraffic, WithPAStatusService("steadier-even")),
 			deploy("foo", "steady-ready"),
 			image("foo", "steady-ready"),
+			secret("foo", "steady-ready"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "steady-ready", withK8sServiceName("steadier-even"), WithLogURL,
@@ -496,6 +698,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "missing-owners", WithTraffic, WithPodAutoscalerOwnersRemoved),
 			deploy("foo", "missing-owners"),
 			image("foo", "missing-owners"),
+			secret("foo", "missing-owners"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "missing-owners", withK8sServiceName("lesser-revision"), WithLogURL,
@@ -504,6 +707,7 @@ func TestReconcile(t *testing.T) {
 				MarkResourceNotOwned("PodAutoscaler", "missing-owners")),
 		}},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "missing-owners", "NotOwned", "There is an existing PodAutoscaler \"missing-owners\" that we do not own."),
 			Eventf(corev1.EventTypeWarning, "InternalError", `revision: "missing-owners" does not own PodAutoscaler: "missing-owners"`),
 		},
 		Key: "foo/missing-owners",
@@ -516,6 +720,7 @@ func TestReconcile(t *testing.T) {
 			pa("foo", "missing-owners", WithTraffic),
 			noOwner(deploy("foo", "missing-owners")),
 			image("foo", "missing-owners"),
+			secret("foo", "missing-owners"),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			Object: rev("foo", "missing-owners", withK8sServiceName("youre-gonna-lose"), WithLogURL,
@@ -524,6 +729,7 @@ func TestReconcile(t *testing.T) {
 				MarkResourceNotOwned("Deployment", "missing-owners-deployment")),
 		}},
 		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "missing-owners", "NotOwned", "There is an existing Deployment \"missing-owners-deployment\" that we do not own."),
 			Eventf(corev1.EventTypeWarning, "InternalError", `revision: "missing-owners" does not own Deployment: "missing-owners-deployment"`),
 		},
 		Key: "foo/missing-owners",
@@ -539,12 +745,142 @@ func TestReconcile(t *testing.T) {
 			deploymentLister:    listers.GetDeploymentLister(),
 			serviceLister:       listers.GetK8sServiceLister(),
 			configMapLister:     listers.GetConfigMapLister(),
+			secretLister:        listers.GetSecretLister(),
+			newProbeKey:         testProbeKey,
 			resolver:            &nopResolver{},
 			configStore:         &testConfigStore{config: ReconcilerTestConfig()},
 		}
 	}))
 }
 
+func TestReconcileVPA(t *testing.T) {
+	recommendation := map[string]interface{}{
+		"containerRecommendations": []interface{}{map[string]interface{}{
+			"containerName": "user-container",
+			"target": map[string]interface{}{
+				"cpu":    "250m",
+				"memory": "256Mi",
+			},
+		}},
+	}
+
+	table := TableTest{{
+		Name: "create vpa",
+		Objects: []runtime.Object{
+			rev("foo", "vpa-create", WithLogURL, AllUnknownConditions),
+			pa("foo", "vpa-create"),
+			deploy("foo", "vpa-create"),
+			image("foo", "vpa-create"),
+			secret("foo", "vpa-create"),
+		},
+		WantCreates: []runtime.Object{
+			resources.MakeVPA(rev("foo", "vpa-create")),
+		},
+		Key: "foo/vpa-create",
+	}, {
+		Name: "surface recommendation",
+		Objects: []runtime.Object{
+			rev("foo", "vpa-recommend", WithLogURL, AllUnknownConditions),
+			pa("foo", "vpa-recommend"),
+			deploy("foo", "vpa-recommend"),
+			image("foo", "vpa-recommend"),
+			secret("foo", "vpa-recommend"),
+		},
+		WithReactors: []clientgotesting.ReactionFunc{
+			getVPA(vpa("foo", "vpa-recommend", func(u *unstructured.Unstructured) {
+				u.Object["status"] = map[string]interface{}{"recommendation": recommendation}
+			})),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "ResourceRecommendation",
+				`VPA recommends requests of cpu=250m, memory=256Mi for container "user-container", which requests nothing`),
+		},
+		Key: "foo/vpa-recommend",
+	}, {
+		Name: "no recommendation yet",
+		Objects: []runtime.Object{
+			rev("foo", "vpa-pending", WithLogURL, AllUnknownConditions),
+			pa("foo", "vpa-pending"),
+			deploy("foo", "vpa-pending"),
+			image("foo", "vpa-pending"),
+			secret("foo", "vpa-pending"),
+		},
+		WithReactors: []clientgotesting.ReactionFunc{
+			getVPA(vpa("foo", "vpa-pending")),
+		},
+		Key: "foo/vpa-pending",
+	}, {
+		Name: "vpa is disowned",
+		Objects: []runtime.Object{
+			rev("foo", "vpa-disowned", WithLogURL, AllUnknownConditions),
+			pa("foo", "vpa-disowned"),
+			deploy("foo", "vpa-disowned"),
+			image("foo", "vpa-disowned"),
+			secret("foo", "vpa-disowned"),
+		},
+		WithReactors: []clientgotesting.ReactionFunc{
+			getVPA(vpa("foo", "vpa-disowned", func(u *unstructured.Unstructured) {
+				u.SetOwnerReferences(nil)
+			})),
+		},
+		WantErr: true,
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: rev("foo", "vpa-disowned", WithLogURL, AllUnknownConditions,
+				MarkResourceNotOwned("VerticalPodAutoscaler", "vpa-disowned")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeWarning, "RevisionFailed", "Revision %q failed with reason %q: %s", "vpa-disowned", "NotOwned",
+				`There is an existing VerticalPodAutoscaler "vpa-disowned" that we do not own.`),
+			Eventf(corev1.EventTypeWarning, "InternalError", `revision: "vpa-disowned" does not own VerticalPodAutoscaler: "vpa-disowned"`),
+		},
+		Key: "foo/vpa-disowned",
+	}}
+
+	cfg := ReconcilerTestConfig()
+	cfg.Deployment.VPARecommendationInterval = time.Minute
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			revisionLister:      listers.GetRevisionLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			imageLister:         listers.GetImageLister(),
+			deploymentLister:    listers.GetDeploymentLister(),
+			serviceLister:       listers.GetK8sServiceLister(),
+			configMapLister:     listers.GetConfigMapLister(),
+			secretLister:        listers.GetSecretLister(),
+			newProbeKey:         testProbeKey,
+			resolver:            &nopResolver{},
+			configStore:         &testConfigStore{config: cfg},
+			enqueueAfter: func(obj interface{}, d time.Duration) {
+				if d != time.Minute {
+					t.Errorf("enqueueAfter() = %v, want %v", d, time.Minute)
+				}
+			},
+		}
+	}))
+}
+
+func vpa(namespace, name string, opts ...func(*unstructured.Unstructured)) *unstructured.Unstructured {
+	u := resources.MakeVPA(rev(namespace, name))
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// getVPA makes the dynamic client return the given VPA, since the object
+// sorter of the table tests doesn't know about VerticalPodAutoscalers.
+func getVPA(vpa *unstructured.Unstructured) clientgotesting.ReactionFunc {
+	return func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		if !action.Matches("get", "verticalpodautoscalers") {
+			return false, nil, nil
+		}
+		return true, vpa.DeepCopy(), nil
+	}
+}
+
 func timeoutDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
 	deploy.Status.Conditions = []appsv1.DeploymentCondition{{
 		Type:   appsv1.DeploymentProgressing,
@@ -554,6 +890,16 @@ func timeoutDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
 	return deploy
 }
 
+func quotaDeploy(deploy *appsv1.Deployment) *appsv1.Deployment {
+	deploy.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentReplicaFailure,
+		Status:  corev1.ConditionTrue,
+		Reason:  "FailedCreate",
+		Message: "exceeded quota: compute-resources",
+	}}
+	return deploy
+}
+
 func noOwner(deploy *appsv1.Deployment) *appsv1.Deployment {
 	deploy.OwnerReferences = nil
 	return deploy
@@ -567,6 +913,23 @@ func changeContainers(deploy *appsv1.Deployment) *appsv1.Deployment {
 	return deploy
 }
 
+func addToleration(deploy *appsv1.Deployment) *appsv1.Deployment {
+	deploy.Spec.Template.Spec.Tolerations = append(deploy.Spec.Template.Spec.Tolerations, corev1.Toleration{
+		Key:      "dedicated",
+		Operator: corev1.TolerationOpExists,
+	})
+	return deploy
+}
+
+// addStaleEnv adds an environment variable to the deployment as if we had
+// applied it before.
+func addStaleEnv(deploy *appsv1.Deployment) *appsv1.Deployment {
+	container := &deploy.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{Name: "STALE", Value: "true"})
+	presources.SetLastAppliedSpec(deploy, appliedDeploymentSpec(deploy))
+	return deploy
+}
+
 func rev(namespace, name string, ro ...RevisionOption) *v1alpha1.Revision {
 	r := &v1alpha1.Revision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -592,6 +955,24 @@ func rev(namespace, name string, ro ...RevisionOption) *v1alpha1.Revision {
 	return r
 }
 
+func withNodeOS(os string) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		if r.Annotations == nil {
+			r.Annotations = make(map[string]string)
+		}
+		r.Annotations[serving.NodeOSAnnotationKey] = os
+	}
+}
+
+func withQoSClass(class corev1.PodQOSClass) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		if r.Annotations == nil {
+			r.Annotations = make(map[string]string)
+		}
+		r.Annotations[serving.QoSClassAnnotationKey] = string(class)
+	}
+}
+
 func withK8sServiceName(sn string) RevisionOption {
 	return func(r *v1alpha1.Revision) {
 		r.Status.ServiceName = sn
@@ -627,10 +1008,11 @@ func deploy(namespace, name string, opts ...interface{}) *appsv1.Deployment {
 	// Do this here instead of in `rev` itself to ensure that we populate defaults
 	// before calling MakeDeployment within Reconcile.
 	rev.SetDefaults(context.Background())
-	return resources.MakeDeployment(rev, cfg.Logging, cfg.Network,
+	deployment := resources.MakeDeployment(rev, cfg.Logging, cfg.Network,
 		cfg.Observability, cfg.Autoscaler, cfg.Deployment,
 	)
-
+	presources.SetLastAppliedSpec(deployment, appliedDeploymentSpec(deployment))
+	return deployment
 }
 
 func image(namespace, name string, co ...configOption) *caching.Image {
@@ -642,6 +1024,15 @@ func image(namespace, name string, co ...configOption) *caching.Image {
 	return resources.MakeImageCache(rev(namespace, name))
 }
 
+func secret(namespace, name string) *corev1.Secret {
+	probeKey, _ := testProbeKey()
+	return resources.MakeSecret(rev(namespace, name), probeKey, nil, nil)
+}
+
+func testProbeKey() ([]byte, error) {
+	return []byte("probe-key"), nil
+}
+
 func pa(namespace, name string, ko ...PodAutoscalerOption) *autoscalingv1alpha1.PodAutoscaler {
 	rev := rev(namespace, name)
 	k := resources.MakePA(rev)
@@ -688,5 +1079,6 @@ func ReconcilerTestConfig() *config.Config {
 		},
 		Logging:    &logging.Config{},
 		Autoscaler: &autoscaler.Config{},
+		Tracing:    &tracingconfig.Config{},
 	}
 }
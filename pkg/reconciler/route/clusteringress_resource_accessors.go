@@ -22,11 +22,13 @@ import (
 
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
 
 	"knative.dev/pkg/logging"
 	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	networkinglisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/route/resources"
 	resourcenames "knative.dev/serving/pkg/reconciler/route/resources/names"
 	"knative.dev/serving/pkg/reconciler/route/traffic"
@@ -35,7 +37,8 @@ import (
 // ClusterIngressResources Cluster Ingress resources
 type ClusterIngressResources struct {
 	BaseIngressResources
-	clusterIngressLister networkinglisters.ClusterIngressLister
+	clusterIngressLister  networkinglisters.ClusterIngressLister
+	clusterIngressIndexer cache.Indexer
 }
 
 // makeIngress constructs a new ClusterIngress object
@@ -68,8 +71,8 @@ func (cir *ClusterIngressResources) getIngressForRoute(route *v1alpha1.Route) (n
 	}
 
 	// If that isn't found, then fallback on the legacy selector-based approach.
-	selector := routeOwnerLabelSelector(route)
-	ingresses, err := cir.clusterIngressLister.List(selector)
+	ingresses, err := cir.clusterIngressIndexer.ByIndex(reconciler.RouteIndex,
+		reconciler.IndexKey(route.Namespace, route.Name))
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +86,7 @@ func (cir *ClusterIngressResources) getIngressForRoute(route *v1alpha1.Route) (n
 		return nil, fmt.Errorf("more than one ClusterIngress are found for route %s/%s: %v", route.Namespace, route.Name, ingresses)
 	}
 
-	return ingresses[0], nil
+	return ingresses[0].(*netv1alpha1.ClusterIngress), nil
 }
 
 // updateIngress invokes APIs to update a ClusterIngress
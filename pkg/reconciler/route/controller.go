@@ -18,8 +18,10 @@ package route
 
 import (
 	"context"
+	"time"
 
 	serviceinformer "knative.dev/pkg/injection/informers/kubeinformers/corev1/service"
+	networkingfactory "knative.dev/serving/pkg/client/injection/informers/networking/factory"
 	certificateinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/certificate"
 	clusteringressinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/clusteringress"
 	ingressinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/ingress"
@@ -27,13 +29,21 @@ import (
 	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision"
 	routeinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/route"
 
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/system"
 	"knative.dev/pkg/tracker"
+	"knative.dev/serving/pkg/apis/networking"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/client/clientset/versioned"
+	networkinginformers "knative.dev/serving/pkg/client/informers/externalversions/networking/v1alpha1"
+	"knative.dev/serving/pkg/informers"
 	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/route/config"
@@ -43,6 +53,17 @@ const (
 	controllerAgentName = "route-controller"
 )
 
+func init() {
+	// The Certificates are only watched once auto TLS is enabled.
+	informers.RegisterLazy(informers.Certificates, func(ctx context.Context, lazy *informers.Lazy) {
+		networkingfactory.Get(ctx).InformerFor(&netv1alpha1.Certificate{},
+			func(client versioned.Interface, resync time.Duration) cache.SharedIndexInformer {
+				return lazy.Wrap(networkinginformers.NewCertificateInformer(client, metav1.NamespaceAll,
+					resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}))
+			})
+	})
+}
+
 // NewController initializes the controller and is called by the generated code
 // Registers eventhandlers to enqueue events
 func NewController(
@@ -77,9 +98,20 @@ func NewControllerWithClock(
 		clusterIngressLister: clusterIngressInformer.Lister(),
 		ingressLister:        ingressInformer.Lister(),
 		certificateLister:    certificateInformer.Lister(),
+		certificateInformers: informers.GetLazy(ctx, informers.Certificates),
 		clock:                clock,
+		resolverFor:          newHostResolver,
+
+		serviceIndexer:        serviceInformer.Informer().GetIndexer(),
+		clusterIngressIndexer: clusterIngressInformer.Informer().GetIndexer(),
+	}
+	for _, si := range []cache.SharedIndexInformer{serviceInformer.Informer(), clusterIngressInformer.Informer()} {
+		if err := reconciler.AddIndexers(si, reconciler.RouteIndex); err != nil {
+			c.Logger.Fatalw("Failed to index the resources of the Routes", zap.Error(err))
+		}
 	}
 	impl := controller.NewImpl(c, c.Logger, "Routes")
+	c.enqueueAfter = impl.EnqueueAfter
 
 	c.Logger.Info("Setting up event handlers")
 	routeInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
@@ -127,12 +159,28 @@ func NewControllerWithClock(
 		&network.Config{},
 		&config.Domain{},
 	}
-	resync := configmap.TypeFilter(configsToResync...)(func(string, interface{}) {
-		impl.GlobalResync(routeInformer.Informer())
-	})
+	resync := configmap.TypeFilter(configsToResync...)(reconciler.SelectiveResync(
+		routeInformer.Informer(), affectedByConfig, impl.Enqueue))
 	configStore := config.NewStore(c.Logger.Named("config-store"), controller.GetResyncPeriod(ctx), resync)
 	configStore.WatchConfigs(cmw)
 	c.configStore = configStore
 
 	return impl
 }
+
+// affectedByConfig reports whether the Route obj is affected by the change of
+// the fields changed of the config name. The default classes of the network
+// config only affect the Routes which do not pick their own.
+func affectedByConfig(name string, changed sets.String, obj interface{}) bool {
+	route, ok := obj.(*v1alpha1.Route)
+	if !ok || name != network.ConfigName {
+		return true
+	}
+	if changed.HasAny("DomainTemplate", "TagTemplate", "AutoTLS") {
+		return true
+	}
+	if changed.Has("DefaultClusterIngressClass") && route.Annotations[networking.IngressClassAnnotationKey] == "" {
+		return true
+	}
+	return changed.Has("DefaultCertificateClass") && route.Annotations[networking.CertificateClassAnnotationKey] == ""
+}
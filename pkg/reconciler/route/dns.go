@@ -0,0 +1,156 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package route
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
+)
+
+const (
+	// domainResolutionRecheckInterval is how long we wait before resolving
+	// the domain of a Route that doesn't resolve to its ingress again.
+	domainResolutionRecheckInterval = 30 * time.Second
+
+	// domainResolutionTimeout bounds the lookups of a single check.
+	domainResolutionTimeout = 5 * time.Second
+)
+
+// hostResolver resolves host names to addresses, as net.Resolver does.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// newHostResolver returns a hostResolver querying the given DNS servers in
+// turn, or the resolvers of the system when there are none.
+func newHostResolver(servers []string) hostResolver {
+	if len(servers) == 0 {
+		return net.DefaultResolver
+	}
+	var next uint32
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, netw, _ string) (net.Conn, error) {
+			server := servers[int(atomic.AddUint32(&next, 1)-1)%len(servers)]
+			var d net.Dialer
+			return d.DialContext(ctx, netw, server)
+		},
+	}
+}
+
+// checkDomainResolution marks the IngressReady condition of the Route unknown
+// and checks it again later, unless its domain resolves to the addresses
+// the ingress is exposed at.
+func (c *Reconciler) checkDomainResolution(ctx context.Context, r *v1alpha1.Route, ingress netv1alpha1.IngressAccessor, servers []string) {
+	ctx, cancel := context.WithTimeout(ctx, domainResolutionTimeout)
+	defer cancel()
+
+	host := r.Status.URL.Host
+	reason := ""
+	resolver := c.resolverFor(servers)
+	if want, err := c.ingressAddresses(ctx, resolver, ingress); err != nil {
+		reason = err.Error()
+	} else if want.Len() == 0 {
+		reason = "the ingress has no external address"
+	} else if got, err := resolver.LookupHost(ctx, host); err != nil {
+		reason = err.Error()
+	} else if !want.HasAny(got...) {
+		sort.Strings(got)
+		reason = fmt.Sprintf("it resolves to %s, the ingress is at %s",
+			strings.Join(got, ","), strings.Join(want.List(), ","))
+	}
+	if reason == "" {
+		return
+	}
+	r.Status.MarkDomainNotResolved(host, reason)
+	c.enqueueAfter(r, domainResolutionRecheckInterval)
+}
+
+// ingressAddresses returns the IP addresses the ingress is exposed at
+// outside of the cluster.
+func (c *Reconciler) ingressAddresses(ctx context.Context, resolver hostResolver, ingress netv1alpha1.IngressAccessor) (sets.String, error) {
+	status := ingress.GetStatus()
+	lb := status.PublicLoadBalancer
+	if lb == nil {
+		lb = status.LoadBalancer
+	}
+	addrs := sets.NewString()
+	if lb == nil {
+		return addrs, nil
+	}
+	for _, ing := range lb.Ingress {
+		switch {
+		case ing.MeshOnly:
+			continue
+		case ing.IP != "":
+			addrs.Insert(ing.IP)
+		case ing.Domain != "":
+			ips, err := resolver.LookupHost(ctx, ing.Domain)
+			if err != nil {
+				return nil, err
+			}
+			addrs.Insert(ips...)
+		case ing.DomainInternal != "":
+			ips, err := c.serviceAddresses(ctx, resolver, ing.DomainInternal)
+			if err != nil {
+				return nil, err
+			}
+			addrs.Insert(ips...)
+		}
+	}
+	return addrs, nil
+}
+
+// serviceAddresses returns the external addresses of the K8s Service the
+// cluster-local domain name refers to.
+func (c *Reconciler) serviceAddresses(ctx context.Context, resolver hostResolver, domain string) ([]string, error) {
+	parts := strings.SplitN(strings.TrimSuffix(domain, ".svc."+network.GetClusterDomainName()), ".", 2)
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	svc, err := c.serviceLister.Services(parts[1]).Get(parts[0])
+	if apierrs.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	addrs := append([]string{}, svc.Spec.ExternalIPs...)
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ing.IP != "" {
+			addrs = append(addrs, ing.IP)
+		} else if ing.Hostname != "" {
+			ips, err := resolver.LookupHost(ctx, ing.Hostname)
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, ips...)
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package route
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/pkg/apis"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := f[host]; ok {
+		return addrs, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func TestCheckDomainResolution(t *testing.T) {
+	lbService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "gateway",
+			Namespace: "ingress-system",
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{
+				Ingress: []corev1.LoadBalancerIngress{{IP: "10.0.0.2"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		resolver fakeResolver
+		lb       *netv1alpha1.LoadBalancerStatus
+		servers  []string
+		want     string
+	}{{
+		name:     "resolves to the ingress IP",
+		resolver: fakeResolver{"route.example.com": {"10.0.0.1"}},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{IP: "10.0.0.1"}},
+		},
+	}, {
+		name: "resolves to the ingress domain",
+		resolver: fakeResolver{
+			"route.example.com": {"10.0.0.1"},
+			"lb.example.com":    {"10.0.0.3", "10.0.0.1"},
+		},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{Domain: "lb.example.com"}},
+		},
+	}, {
+		name:     "resolves to the load balancer of the ingress service",
+		resolver: fakeResolver{"route.example.com": {"10.0.0.2"}},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{
+				DomainInternal: "gateway.ingress-system.svc.cluster.local",
+			}},
+		},
+	}, {
+		name:     "resolves elsewhere",
+		resolver: fakeResolver{"route.example.com": {"10.1.0.2", "10.1.0.1"}},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{IP: "10.0.0.1"}},
+		},
+		want: `Domain "route.example.com" does not resolve to the ingress yet: it resolves to 10.1.0.1,10.1.0.2, the ingress is at 10.0.0.1`,
+	}, {
+		name:     "does not resolve",
+		resolver: fakeResolver{},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{IP: "10.0.0.1"}},
+		},
+		servers: []string{"8.8.8.8:53"},
+		want:    `Domain "route.example.com" does not resolve to the ingress yet: no such host`,
+	}, {
+		name:     "mesh only ingress",
+		resolver: fakeResolver{"route.example.com": {"10.0.0.1"}},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{MeshOnly: true}},
+		},
+		want: `Domain "route.example.com" does not resolve to the ingress yet: the ingress has no external address`,
+	}, {
+		name:     "unknown ingress service",
+		resolver: fakeResolver{"route.example.com": {"10.0.0.1"}},
+		lb: &netv1alpha1.LoadBalancerStatus{
+			Ingress: []netv1alpha1.LoadBalancerIngressStatus{{
+				DomainInternal: "other.ingress-system.svc.cluster.local",
+			}},
+		},
+		want: `Domain "route.example.com" does not resolve to the ingress yet: the ingress has no external address`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			listers := NewListers([]runtime.Object{lbService})
+			var gotServers []string
+			var enqueued time.Duration
+			c := &Reconciler{
+				serviceLister: listers.GetK8sServiceLister(),
+				resolverFor: func(servers []string) hostResolver {
+					gotServers = servers
+					return test.resolver
+				},
+				enqueueAfter: func(_ interface{}, after time.Duration) {
+					enqueued = after
+				},
+			}
+			r := &v1alpha1.Route{}
+			r.Status.URL = &apis.URL{Scheme: "http", Host: "route.example.com"}
+			ingress := &netv1alpha1.Ingress{}
+			ingress.Status.MarkNetworkConfigured()
+			ingress.Status.MarkLoadBalancerReady(nil, test.lb.Ingress, nil)
+			r.Status.PropagateIngressStatus(ingress.Status)
+
+			c.checkDomainResolution(context.Background(), r, ingress, test.servers)
+
+			if len(gotServers) != len(test.servers) {
+				t.Errorf("Resolver servers = %v, want %v", gotServers, test.servers)
+			}
+			cond := r.Status.GetCondition(v1alpha1.RouteConditionIngressReady)
+			if test.want == "" {
+				if !cond.IsTrue() {
+					t.Errorf("IngressReady = %v, want True", cond)
+				}
+				if enqueued != 0 {
+					t.Errorf("Route was enqueued after %v, want not enqueued", enqueued)
+				}
+				return
+			}
+			if !cond.IsUnknown() || cond.Reason != "DomainNotResolved" {
+				t.Errorf("IngressReady = %v, want Unknown with reason DomainNotResolved", cond)
+			}
+			if cond.Message != test.want {
+				t.Errorf("Message = %q, want %q", cond.Message, test.want)
+			}
+			if enqueued != domainResolutionRecheckInterval {
+				t.Errorf("Route was enqueued after %v, want %v", enqueued, domainResolutionRecheckInterval)
+			}
+		})
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/resources"
 	"knative.dev/serving/pkg/reconciler/route/traffic"
+	presources "knative.dev/serving/pkg/resources"
 )
 
 func routeOwnerLabelSelector(route *v1alpha1.Route) labels.Selector {
@@ -72,6 +73,11 @@ func (c *Reconciler) reconcileIngress(
 		if optional {
 			return nil, nil
 		}
+		// Don't modify the desired ingress of the caller.
+		desired = desired.DeepCopyObject().(netv1alpha1.IngressAccessor)
+		if err := presources.SetLastAppliedSpec(desired, desired.GetSpec()); err != nil {
+			return nil, err
+		}
 		ingress, err = ira.createIngress(desired)
 		if err != nil {
 			logger.Errorw("Failed to create Ingress", zap.Error(err))
@@ -86,14 +92,29 @@ func (c *Reconciler) reconcileIngress(
 	} else if err != nil {
 		return nil, err
 	} else {
+		// Apply the spec we want onto the spec we have, leaving the fields we
+		// don't set alone.
+		var spec netv1alpha1.IngressSpec
+		if err := presources.ApplySpec(ingress, ingress.GetSpec(), desired.GetSpec(), &spec); err != nil {
+			return nil, err
+		}
+		// Don't modify the informers copy
+		origin := ingress.DeepCopyObject().(netv1alpha1.IngressAccessor)
+		origin.SetSpec(spec)
+		if err := presources.SetLastAppliedSpec(origin, desired.GetSpec()); err != nil {
+			return nil, err
+		}
+		// Add the labels the Ingresses created by older releases lack.
+		if !presources.ContainsAll(origin.GetLabels(), desired.GetLabels()) {
+			origin.SetLabels(presources.UnionMaps(origin.GetLabels(), desired.GetLabels()))
+		}
+
 		// It is notable that one reason for differences here may be defaulting.
 		// When that is the case, the Update will end up being a nop because the
 		// webhook will bring them into alignment and no new reconciliation will occur.
-		if !equality.Semantic.DeepEqual(ingress.GetSpec(), desired.GetSpec()) {
-			// Don't modify the informers copy
-			origin := ingress.DeepCopyObject().(netv1alpha1.IngressAccessor)
-			origin.SetSpec(*desired.GetSpec())
-
+		if !equality.Semantic.DeepEqual(ingress.GetSpec(), origin.GetSpec()) ||
+			!equality.Semantic.DeepEqual(ingress.GetAnnotations(), origin.GetAnnotations()) ||
+			!equality.Semantic.DeepEqual(ingress.GetLabels(), origin.GetLabels()) {
 			updated, err := ira.updateIngress(origin)
 			if err != nil {
 				logger.Errorw("Failed to update %s", resources.GetIngressTypeName(ingress), zap.Error(err))
@@ -139,7 +160,7 @@ func (c *Reconciler) reconcilePlaceholderServices(ctx context.Context, route *v1
 		service, err := c.serviceLister.Services(ns).Get(desiredService.Name)
 		if apierrs.IsNotFound(err) {
 			// Doesn't exist, create it.
-			service, err = c.KubeClientSet.CoreV1().Services(ns).Create(desiredService)
+			service, err = c.createPlaceholderService(desiredService)
 			if err != nil {
 				logger.Errorw("Failed to create placeholder service", zap.Error(err))
 				c.Recorder.Eventf(route, corev1.EventTypeWarning, "CreationFailed",
@@ -170,6 +191,16 @@ func (c *Reconciler) reconcilePlaceholderServices(ctx context.Context, route *v1
 	return services, nil
 }
 
+// createPlaceholderService creates service with the client of the child
+// resources of its namespace.
+func (c *Reconciler) createPlaceholderService(service *corev1.Service) (*corev1.Service, error) {
+	kubeClient, err := c.ChildKubeClientSet(service.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return kubeClient.CoreV1().Services(service.Namespace).Create(service)
+}
+
 func (c *Reconciler) updatePlaceholderServices(ctx context.Context, route *v1alpha1.Route, services []*corev1.Service, ingress netv1alpha1.IngressAccessor) error {
 	logger := logging.FromContext(ctx)
 	ns := route.Namespace
@@ -185,12 +216,21 @@ func (c *Reconciler) updatePlaceholderServices(ctx context.Context, route *v1alp
 				return nil
 			}
 
-			// Make sure that the service has the proper specification.
-			if !equality.Semantic.DeepEqual(service.Spec, desiredService.Spec) {
+			// Make sure that the service has the proper specification, and
+			// the labels the services created by older releases lack.
+			if !equality.Semantic.DeepEqual(service.Spec, desiredService.Spec) ||
+				!presources.ContainsAll(service.Labels, desiredService.Labels) {
 				// Don't modify the informers copy
 				existing := service.DeepCopy()
 				existing.Spec = desiredService.Spec
-				_, err = c.KubeClientSet.CoreV1().Services(ns).Update(existing)
+				if !presources.ContainsAll(existing.Labels, desiredService.Labels) {
+					existing.Labels = presources.UnionMaps(existing.Labels, desiredService.Labels)
+				}
+				kubeClient, err := c.ChildKubeClientSet(ns)
+				if err != nil {
+					return err
+				}
+				_, err = kubeClient.CoreV1().Services(ns).Update(existing)
 				if err != nil {
 					return err
 				}
@@ -292,10 +332,15 @@ func (c *Reconciler) reconcileCertificate(ctx context.Context, r *v1alpha1.Route
 		r.Status.MarkCertificateNotOwned(cert.Name)
 		return nil, fmt.Errorf("route: %s does not own certificate: %s", r.Name, cert.Name)
 	} else {
-		if !equality.Semantic.DeepEqual(cert.Spec, desiredCert.Spec) {
+		// Add the labels the Certificates created by older releases lack.
+		if !equality.Semantic.DeepEqual(cert.Spec, desiredCert.Spec) ||
+			!presources.ContainsAll(cert.Labels, desiredCert.Labels) {
 			// Don't modify the informers copy
 			existing := cert.DeepCopy()
 			existing.Spec = desiredCert.Spec
+			if !presources.ContainsAll(existing.Labels, desiredCert.Labels) {
+				existing.Labels = presources.UnionMaps(existing.Labels, desiredCert.Labels)
+			}
 			cert, err := c.ServingClientSet.NetworkingV1alpha1().Certificates(existing.Namespace).Update(existing)
 			if err != nil {
 				c.Recorder.Eventf(r, corev1.EventTypeWarning, "UpdateFailed",
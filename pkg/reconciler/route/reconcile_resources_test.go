@@ -28,6 +28,7 @@ import (
 	"knative.dev/pkg/kmeta"
 	"knative.dev/pkg/system"
 	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	fakecertinformer "knative.dev/serving/pkg/client/injection/informers/networking/v1alpha1/certificate/fake"
@@ -36,6 +37,7 @@ import (
 	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/resources"
 	"knative.dev/serving/pkg/reconciler/route/traffic"
+	presources "knative.dev/serving/pkg/resources"
 
 	. "knative.dev/pkg/logging/testing"
 )
@@ -55,7 +57,8 @@ func TestReconcileClusterIngress_Insert(t *testing.T) {
 		BaseIngressResources: BaseIngressResources{
 			servingClientSet: reconciler.ServingClientSet,
 		},
-		clusterIngressLister: reconciler.clusterIngressLister,
+		clusterIngressLister:  reconciler.clusterIngressLister,
+		clusterIngressIndexer: reconciler.clusterIngressIndexer,
 	}
 
 	if _, err := reconciler.reconcileIngress(TestContextWithLogger(t), ira, r, ci, true /* optional*/); err != nil {
@@ -81,7 +84,8 @@ func TestReconcileClusterIngress_Update(t *testing.T) {
 		BaseIngressResources: BaseIngressResources{
 			servingClientSet: reconciler.ServingClientSet,
 		},
-		clusterIngressLister: reconciler.clusterIngressLister,
+		clusterIngressLister:  reconciler.clusterIngressLister,
+		clusterIngressIndexer: reconciler.clusterIngressIndexer,
 	}
 
 	ci := newTestClusterIngress(t, r)
@@ -106,6 +110,7 @@ func TestReconcileClusterIngress_Update(t *testing.T) {
 	}
 
 	updated = getRouteClusterIngressFromClient(ctx, t, r)
+	presources.SetLastAppliedSpec(ci2, ci2.GetSpec())
 	if diff := cmp.Diff(ci2, updated); diff != "" {
 		t.Errorf("Unexpected diff (-want +got): %v", diff)
 	}
@@ -251,6 +256,39 @@ func TestReconcileCertificate_Update(t *testing.T) {
 	}
 }
 
+func TestReconcileCertificate_AddsLabels(t *testing.T) {
+	ctx, _, reconciler, _ := newTestReconciler(t)
+
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "test-ns",
+		},
+	}
+	// A Certificate created before its labels were.
+	certificate := newCerts([]string{"old.example.com"}, r)
+	if _, err := reconciler.reconcileCertificate(TestContextWithLogger(t), r, certificate); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	storedCert := getCertificateFromClient(t, ctx, certificate)
+	fakecertinformer.Get(ctx).Informer().GetIndexer().Add(storedCert)
+
+	labeled := certificate.DeepCopy()
+	labeled.Labels = map[string]string{
+		serving.RouteLabelKey:   r.Name,
+		serving.ServiceLabelKey: "test-service",
+	}
+	if _, err := reconciler.reconcileCertificate(TestContextWithLogger(t), r, labeled); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	updated := getCertificateFromClient(t, ctx, labeled)
+	if diff := cmp.Diff(labeled, updated); diff != "" {
+		t.Errorf("Unexpected diff (-want +got): %v", diff)
+	}
+}
+
 func newCerts(dnsNames []string, r *v1alpha1.Route) *netv1alpha1.Certificate {
 	return &netv1alpha1.Certificate{
 		ObjectMeta: metav1.ObjectMeta{
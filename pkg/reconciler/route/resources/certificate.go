@@ -61,6 +61,7 @@ func MakeCertificates(route *v1alpha1.Route, domainTagMap map[string]string, cer
 				Name:            certName,
 				Namespace:       route.Namespace,
 				OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(route)},
+				Labels:          makeLabels(route),
 				Annotations: resources.UnionMaps(route.ObjectMeta.Annotations,
 					map[string]string{
 						networking.CertificateClassAnnotationKey: certClass,
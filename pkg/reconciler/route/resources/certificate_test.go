@@ -25,6 +25,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 )
 
@@ -48,6 +49,9 @@ func TestMakeCertificates(t *testing.T) {
 				Name:            "route-12345-200999684",
 				Namespace:       "default",
 				OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(route)},
+				Labels: map[string]string{
+					serving.RouteLabelKey: "route",
+				},
 				Annotations: map[string]string{
 					networking.CertificateClassAnnotationKey: "foo-cert",
 				},
@@ -62,6 +66,9 @@ func TestMakeCertificates(t *testing.T) {
 				Name:            "route-12345",
 				Namespace:       "default",
 				OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(route)},
+				Labels: map[string]string{
+					serving.RouteLabelKey: "route",
+				},
 				Annotations: map[string]string{
 					networking.CertificateClassAnnotationKey: "foo-cert",
 				},
@@ -18,18 +18,25 @@ package resources
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"sort"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/system"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/networking"
 	"knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	servingv1alpha1 "knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/network"
+	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/domains"
 	"knative.dev/serving/pkg/reconciler/route/resources/labels"
 	"knative.dev/serving/pkg/reconciler/route/resources/names"
@@ -65,13 +72,11 @@ func MakeClusterIngress(
 			// As ClusterIngress resource is cluster-scoped,
 			// here we use GenerateName to avoid conflict.
 			Name: names.ClusterIngress(r),
-			Labels: map[string]string{
-				serving.RouteLabelKey:          r.Name,
+			Labels: resources.UnionMaps(makeLabels(r), map[string]string{
 				serving.RouteNamespaceLabelKey: r.Namespace,
-			},
-			Annotations: resources.UnionMaps(map[string]string{
-				networking.IngressClassAnnotationKey: ingressClass,
-			}, r.ObjectMeta.Annotations),
+			}),
+			Annotations: resources.UnionMaps(
+				ingressAnnotations(ctx, ingressClass, spec), r.ObjectMeta.Annotations),
 		},
 		Spec: spec,
 	}, nil
@@ -95,19 +100,44 @@ func MakeIngress(
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      names.Ingress(r),
 			Namespace: r.Namespace,
-			Labels: map[string]string{
-				serving.RouteLabelKey:          r.Name,
+			Labels: resources.UnionMaps(makeLabels(r), map[string]string{
 				serving.RouteNamespaceLabelKey: r.Namespace,
-			},
-			Annotations: resources.UnionMaps(map[string]string{
-				networking.IngressClassAnnotationKey: ingressClass,
-			}, r.ObjectMeta.Annotations),
+			}),
+			Annotations: resources.UnionMaps(
+				ingressAnnotations(ctx, ingressClass, spec), r.ObjectMeta.Annotations),
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(r)},
 		},
 		Spec: spec,
 	}, nil
 }
 
+// ingressAnnotations returns the annotations of the ingress of the given
+// class and spec, which include the public domains of its rules for
+// external-dns when that's enabled.
+func ingressAnnotations(ctx context.Context, ingressClass string, spec v1alpha1.IngressSpec) map[string]string {
+	annotations := map[string]string{
+		networking.IngressClassAnnotationKey: ingressClass,
+	}
+	if !config.FromContext(ctx).Network.ExternalDNS {
+		return annotations
+	}
+	hosts := sets.NewString()
+	for _, rule := range spec.Rules {
+		if rule.Visibility == v1alpha1.IngressVisibilityClusterLocal {
+			continue
+		}
+		for _, host := range rule.Hosts {
+			if !strings.HasSuffix(host, "."+network.GetClusterDomainName()) {
+				hosts.Insert(host)
+			}
+		}
+	}
+	if hosts.Len() > 0 {
+		annotations[networking.ExternalDNSHostnameAnnotationKey] = strings.Join(hosts.List(), ",")
+	}
+	return annotations
+}
+
 // MakeIngressSpec creates a new IngressSpec
 func MakeIngressSpec(
 	ctx context.Context,
@@ -127,6 +157,10 @@ func MakeIngressSpec(
 
 	// The routes are matching rule based on domain name to traffic split targets.
 	rules := make([]v1alpha1.IngressRule, 0, len(names))
+	fallback := fallbackRevision(targets[traffic.DefaultTarget])
+	overrides := makePathOverrides(r)
+	// The annotation was validated by the webhook.
+	redirects, _ := serving.ParsePathReplacements(r.Annotations[serving.PathRedirectsAnnotationKey])
 	for _, name := range names {
 		serviceDomain, err := domains.HostnameFromTemplate(ctx, r.Name, name)
 		if err != nil {
@@ -140,8 +174,22 @@ func MakeIngressSpec(
 			return v1alpha1.IngressSpec{}, err
 		}
 
-		rules = append(rules, *makeIngressRule(
-			routeDomains, r.Namespace, isClusterLocal, targets[name]))
+		rule := makeIngressRule(routeDomains, r.Namespace, name, isClusterLocal, targets[name])
+		overridePaths(rule, overrides)
+		if host := r.Annotations[serving.HostRewriteAnnotationKey]; host != "" {
+			rewriteHost(rule, host)
+		}
+		redirectPaths(rule, redirects)
+		if sticky := stickyBy(r); sticky != "" {
+			stickThroughActivator(rule, r.Name, sticky)
+		}
+		if fallback != "" {
+			fallBackThroughActivator(rule, r.Name, fallback)
+		}
+		if r.Annotations[serving.MaintenanceAnnotationKey] == "true" {
+			maintainThroughActivator(rule, r.Namespace, name, r.Annotations[serving.MaintenancePathsAnnotationKey])
+		}
+		rules = append(rules, *rule)
 	}
 
 	defaultDomain, err := domains.HostnameFromTemplate(ctx, r.Name, "")
@@ -190,29 +238,59 @@ func routeDomains(ctx context.Context, targetName string, r *servingv1alpha1.Rou
 	return ruleDomains, nil
 }
 
-func makeIngressRule(domains []string, ns string, isClusterLocal bool, targets traffic.RevisionTargets) *v1alpha1.IngressRule {
-	// Optimistically allocate |targets| elements.
-	splits := make([]v1alpha1.IngressBackendSplit, 0, len(targets))
+func makeIngressRule(domains []string, ns, tag string, isClusterLocal bool, targets traffic.RevisionTargets) *v1alpha1.IngressRule {
+	// The splits of each path, in the order of their targets.
+	prefixes := []string{}
+	splits := make(map[string][]v1alpha1.IngressBackendSplit)
 	for _, t := range targets {
 		if t.Percent == 0 {
 			continue
 		}
 
-		splits = append(splits, v1alpha1.IngressBackendSplit{
+		headers := map[string]string{
+			activator.RevisionHeaderName:      t.TrafficTarget.RevisionName,
+			activator.RevisionHeaderNamespace: ns,
+		}
+		if tag != "" {
+			headers[network.RouteTagHeaderName] = tag
+		}
+		// Port on the public service must match port on the activator.
+		// Otherwise, the serverless services can't guarantee seamless positive handoff.
+		// Named ports are the exception: only the pods back them.
+		port := networking.ServicePort(t.Protocol)
+		if t.Port != 0 {
+			port = int(t.Port)
+		}
+		if _, ok := splits[t.Path]; !ok && t.Path != "" {
+			prefixes = append(prefixes, t.Path)
+		}
+		splits[t.Path] = append(splits[t.Path], v1alpha1.IngressBackendSplit{
 			IngressBackend: v1alpha1.IngressBackend{
 				ServiceNamespace: ns,
 				ServiceName:      t.ServiceName,
-				// Port on the public service must match port on the activator.
-				// Otherwise, the serverless services can't guarantee seamless positive handoff.
-				ServicePort: intstr.FromInt(int(networking.ServicePort(t.Protocol))),
-			},
-			Percent: t.Percent,
-			AppendHeaders: map[string]string{
-				activator.RevisionHeaderName:      t.TrafficTarget.RevisionName,
-				activator.RevisionHeaderNamespace: ns,
+				ServicePort:      intstr.FromInt(port),
 			},
+			Percent:       t.Percent,
+			AppendHeaders: headers,
+		})
+	}
+
+	// The first path matching a request wins, so the longer prefixes go
+	// first and the targets without one catch the rest.
+	sort.SliceStable(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+	paths := make([]v1alpha1.HTTPIngressPath, 0, len(prefixes)+1)
+	for _, prefix := range prefixes {
+		paths = append(paths, v1alpha1.HTTPIngressPath{
+			Path:   pathPrefixRegExp([]string{prefix}),
+			Splits: splits[prefix],
 		})
 	}
+	paths = append(paths, v1alpha1.HTTPIngressPath{
+		Splits: splits[""],
+		// TODO(lichuqiang): #2201, plumbing to config timeout and retries.
+	})
 
 	visibility := v1alpha1.IngressVisibilityExternalIP
 	if isClusterLocal {
@@ -223,14 +301,267 @@ func makeIngressRule(domains []string, ns string, isClusterLocal bool, targets t
 		Hosts:      domains,
 		Visibility: visibility,
 		HTTP: &v1alpha1.HTTPIngressRuleValue{
-			Paths: []v1alpha1.HTTPIngressPath{{
-				Splits: splits,
-				// TODO(lichuqiang): #2201, plumbing to config timeout and retries.
-			}},
+			Paths: paths,
 		},
 	}
 }
 
+// pathPrefixRegExp returns a regular expression matching the paths starting
+// with any of prefixes.  The prefixes match as whole path segments, so that
+// "/api" doesn't match "/apis".
+func pathPrefixRegExp(prefixes []string) string {
+	quoted := make([]string, len(prefixes))
+	for i, prefix := range prefixes {
+		quoted[i] = regexp.QuoteMeta(strings.TrimSuffix(prefix, "/"))
+	}
+	return "^(" + strings.Join(quoted, "|") + ")([/?].*)?$"
+}
+
+// pathOverrides are the settings of the requests for the path prefixes the
+// annotations of a Route give them for, keyed by prefix.
+type pathOverrides struct {
+	timeouts map[string]time.Duration
+	retries  map[string]int
+	rewrites map[string]string
+}
+
+// makePathOverrides returns the path overrides of r.
+func makePathOverrides(r *servingv1alpha1.Route) pathOverrides {
+	// The annotations were validated by the webhook.
+	timeouts, _ := serving.ParsePathTimeouts(r.Annotations[serving.PathTimeoutsAnnotationKey])
+	retries, _ := serving.ParsePathRetries(r.Annotations[serving.PathRetriesAnnotationKey])
+	rewrites, _ := serving.ParsePathReplacements(r.Annotations[serving.PathRewritesAnnotationKey])
+	return pathOverrides{
+		timeouts: timeouts,
+		retries:  retries,
+		rewrites: rewrites,
+	}
+}
+
+// prefixes returns the path prefixes overridden, shortest first, so that
+// the paths of the longer ones they cover inherit their overrides.
+func (o pathOverrides) prefixes() []string {
+	seen := sets.NewString()
+	for prefix := range o.timeouts {
+		seen.Insert(prefix)
+	}
+	for prefix := range o.retries {
+		seen.Insert(prefix)
+	}
+	for prefix := range o.rewrites {
+		seen.Insert(prefix)
+	}
+	return seen.List()
+}
+
+// overridePaths sets the timeout, the retry attempts and the rewritten path
+// of the requests of rule for the path prefixes they are given for. The
+// prefixes the targets of rule don't route by get paths of their own, with
+// the splits of the path their requests were matched by until then.
+func overridePaths(rule *v1alpha1.IngressRule, overrides pathOverrides) {
+	for _, prefix := range overrides.prefixes() {
+		paths := rule.HTTP.Paths
+		i := 0
+		for ; i < len(paths)-1; i++ {
+			if matched, _ := regexp.MatchString(paths[i].Path, prefix); matched {
+				break
+			}
+		}
+		if re := pathPrefixRegExp([]string{prefix}); paths[i].Path != re {
+			// The first matching path wins, so the more specific one of
+			// prefix goes right before the one it was matched by.
+			own := paths[i].DeepCopy()
+			own.Path = re
+			paths = append(paths[:i], append([]v1alpha1.HTTPIngressPath{*own}, paths[i:]...)...)
+			rule.HTTP.Paths = paths
+		}
+
+		path := &paths[i]
+		if d, ok := overrides.timeouts[prefix]; ok {
+			path.Timeout = &metav1.Duration{Duration: d}
+			if path.Retries == nil {
+				path.Retries = &v1alpha1.HTTPRetry{Attempts: networking.DefaultRetryCount}
+			}
+			path.Retries.PerTryTimeout = &metav1.Duration{Duration: d}
+		}
+		if n, ok := overrides.retries[prefix]; ok {
+			if path.Retries == nil {
+				path.Retries = &v1alpha1.HTTPRetry{}
+			}
+			path.Retries.Attempts = n
+		}
+		if rewrite, ok := overrides.rewrites[prefix]; ok {
+			path.Rewrite = &v1alpha1.HTTPRewrite{Path: rewrite}
+		}
+	}
+}
+
+// rewriteHost sets the host the requests of rule are forwarded with.
+func rewriteHost(rule *v1alpha1.IngressRule, host string) {
+	for i := range rule.HTTP.Paths {
+		path := &rule.HTTP.Paths[i]
+		if path.Rewrite == nil {
+			path.Rewrite = &v1alpha1.HTTPRewrite{}
+		}
+		path.Rewrite.Host = host
+	}
+}
+
+// redirectPaths redirects the requests of rule for the path prefixes of
+// redirects to their paths, before they are matched by any other path.
+func redirectPaths(rule *v1alpha1.IngressRule, redirects map[string]string) {
+	prefixes := make([]string, 0, len(redirects))
+	for prefix := range redirects {
+		prefixes = append(prefixes, prefix)
+	}
+	// The longer prefixes go first, their redirects are more specific.
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+
+	paths := make([]v1alpha1.HTTPIngressPath, 0, len(prefixes)+len(rule.HTTP.Paths))
+	for _, prefix := range prefixes {
+		paths = append(paths, v1alpha1.HTTPIngressPath{
+			Path:     pathPrefixRegExp([]string{prefix}),
+			Redirect: &v1alpha1.HTTPRedirect{Path: redirects[prefix]},
+		})
+	}
+	rule.HTTP.Paths = append(paths, rule.HTTP.Paths...)
+}
+
+// stickyBy returns what the activator consistently assigns the users of r to
+// one of the Revisions it splits the traffic between by, or "" when r isn't
+// asking for that.
+func stickyBy(r *servingv1alpha1.Route) string {
+	switch r.Annotations[serving.StickySplitAnnotationKey] {
+	case serving.StickySplitCookie:
+		return serving.StickySplitCookie
+	case serving.StickySplitHeader:
+		return serving.StickySplitHeader + ":" + r.Annotations[serving.StickyHeaderAnnotationKey]
+	}
+	return ""
+}
+
+// stickThroughActivator replaces the traffic splits of rule with a single
+// backend, the activator, to which it hands the splits via headers instead,
+// so that it picks the Revision of each request by sticky. The activator
+// checks the Revision it picks against the traffic targets of route.
+func stickThroughActivator(rule *v1alpha1.IngressRule, route, sticky string) {
+	for i := range rule.HTTP.Paths {
+		path := &rule.HTTP.Paths[i]
+		if len(path.Splits) < 2 {
+			continue
+		}
+		// The activator serves HTTP on the ports of the public services, and
+		// can only proxy the requests of a single port.
+		port := path.Splits[0].ServicePort
+		if port.IntValue() != networking.ServiceHTTPPort && port.IntValue() != networking.ServiceHTTP2Port {
+			continue
+		}
+		splits := make([]string, 0, len(path.Splits))
+		for _, split := range path.Splits {
+			if split.ServicePort != port {
+				splits = nil
+				break
+			}
+			splits = append(splits, fmt.Sprintf("%s=%d",
+				split.AppendHeaders[activator.RevisionHeaderName], split.Percent))
+		}
+		if splits == nil {
+			continue
+		}
+
+		headers := resources.FilterMap(path.Splits[0].AppendHeaders, func(k string) bool {
+			return k == activator.RevisionHeaderName
+		})
+		headers[activator.RouteHeaderName] = route
+		headers[activator.SplitsHeaderName] = strings.Join(splits, ",")
+		headers[activator.StickyHeaderName] = sticky
+		path.Splits = []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: system.Namespace(),
+				ServiceName:      activator.K8sServiceName,
+				ServicePort:      port,
+			},
+			Percent:       100,
+			AppendHeaders: headers,
+		}}
+	}
+}
+
+// fallbackRevision returns the Revision of targets the requests the others
+// fail to serve are retried against, or "" when there is none.
+func fallbackRevision(targets traffic.RevisionTargets) string {
+	for _, t := range targets {
+		if t.Fallback {
+			return t.RevisionName
+		}
+	}
+	return ""
+}
+
+// fallBackThroughActivator routes the traffic splits of rule through the
+// activator, which retries the requests their Revisions fail to serve
+// against fallback, once it checked it is the one of route.
+func fallBackThroughActivator(rule *v1alpha1.IngressRule, route, fallback string) {
+	for i := range rule.HTTP.Paths {
+		for j := range rule.HTTP.Paths[i].Splits {
+			split := &rule.HTTP.Paths[i].Splits[j]
+			if split.AppendHeaders[activator.RevisionHeaderName] == fallback {
+				continue
+			}
+			// The activator serves HTTP on the ports of the public services.
+			if port := split.ServicePort.IntValue(); port != networking.ServiceHTTPPort && port != networking.ServiceHTTP2Port {
+				continue
+			}
+			split.ServiceNamespace = system.Namespace()
+			split.ServiceName = activator.K8sServiceName
+			split.AppendHeaders[activator.RouteHeaderName] = route
+			split.AppendHeaders[activator.FallbackHeaderName] = fallback
+		}
+	}
+}
+
+// maintainThroughActivator sends the requests of rule for the comma separated
+// path prefixes of paths, or all of them when paths is empty, to the
+// activator, which answers them with the maintenance page.
+func maintainThroughActivator(rule *v1alpha1.IngressRule, ns, tag, paths string) {
+	// The activator serves HTTP on the ports of the public services.
+	port := intstr.FromInt(networking.ServiceHTTPPort)
+	if splits := rule.HTTP.Paths[0].Splits; len(splits) > 0 &&
+		splits[0].ServicePort.IntValue() == networking.ServiceHTTP2Port {
+		port = splits[0].ServicePort
+	}
+	headers := map[string]string{
+		activator.RevisionHeaderNamespace: ns,
+		activator.MaintenanceHeaderName:   "true",
+	}
+	if tag != "" {
+		headers[network.RouteTagHeaderName] = tag
+	}
+	maintenance := v1alpha1.HTTPIngressPath{
+		Splits: []v1alpha1.IngressBackendSplit{{
+			IngressBackend: v1alpha1.IngressBackend{
+				ServiceNamespace: system.Namespace(),
+				ServiceName:      activator.K8sServiceName,
+				ServicePort:      port,
+			},
+			Percent:       100,
+			AppendHeaders: headers,
+		}},
+	}
+
+	if paths == "" {
+		rule.HTTP.Paths = []v1alpha1.HTTPIngressPath{maintenance}
+		return
+	}
+	maintenance.Path = pathPrefixRegExp(strings.Split(paths, ","))
+	rule.HTTP.Paths = append([]v1alpha1.HTTPIngressPath{maintenance}, rule.HTTP.Paths...)
+}
+
 // GetIngressTypeName returns ingress type name: ClusterIngress or Ingress
 func GetIngressTypeName(ingress v1alpha1.IngressAccessor) string {
 	if ingress.GetNamespace() == "" {
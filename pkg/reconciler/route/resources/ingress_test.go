@@ -19,6 +19,7 @@ package resources
 import (
 	"context"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -84,6 +85,69 @@ func TestMakeClusterIngress_CorrectMetadata(t *testing.T) {
 	}
 }
 
+func TestMakeIngress_ExternalDNS(t *testing.T) {
+	targets := map[string]traffic.RevisionTargets{
+		traffic.DefaultTarget: {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v2",
+				Percent:           100,
+			},
+			ServiceName: "gilberto",
+			Active:      true,
+		}},
+		"v1": {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           100,
+			},
+			ServiceName: "jobim",
+			Active:      true,
+		}},
+		"private": {{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: "config",
+				RevisionName:      "v1",
+				Percent:           100,
+			},
+			ServiceName: "caetano",
+			Active:      true,
+		}},
+	}
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-route",
+			Namespace: "test-ns",
+			UID:       "1234-5678",
+		},
+		Status: v1alpha1.RouteStatus{
+			RouteStatusFields: v1alpha1.RouteStatusFields{
+				URL: &apis.URL{
+					Scheme: "http",
+					Host:   "domain.com",
+				},
+			},
+		},
+	}
+	cfg := testConfig()
+	cfg.Network.ExternalDNS = true
+	ctx := config.ToContext(context.Background(), cfg)
+
+	ia, err := MakeIngress(ctx, r, &traffic.Config{Targets: targets}, nil,
+		sets.NewString("private-test-route"), "foo-ingress")
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	want := map[string]string{
+		networking.IngressClassAnnotationKey:        "foo-ingress",
+		networking.ExternalDNSHostnameAnnotationKey: "test-route.test-ns.example.com,v1-test-route.test-ns.example.com",
+	}
+	if got := ia.GetAnnotations(); !cmp.Equal(want, got) {
+		t.Errorf("Unexpected annotations (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
+
 func TestMakeClusterIngressSpec_CorrectRules(t *testing.T) {
 	targets := map[string]traffic.RevisionTargets{
 		traffic.DefaultTarget: {{
@@ -160,6 +224,7 @@ func TestMakeClusterIngressSpec_CorrectRules(t *testing.T) {
 					AppendHeaders: map[string]string{
 						"Knative-Serving-Revision":  "v1",
 						"Knative-Serving-Namespace": "test-ns",
+						"K-Route-Tag":               "v1",
 					},
 				}},
 			}},
@@ -434,7 +499,7 @@ func TestMakeClusterIngressRule_Vanilla(t *testing.T) {
 		Active:      true,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -464,6 +529,80 @@ func TestMakeClusterIngressRule_Vanilla(t *testing.T) {
 	}
 }
 
+// One target sent to a named port.
+func TestMakeClusterIngressRule_NamedPort(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "chocolate",
+		Active:      true,
+		Port:        9095,
+	}}
+	rule := makeIngressRule([]string{"a.com"}, ns, "", false, targets)
+	want := []netv1alpha1.IngressBackendSplit{{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: "test-ns",
+			ServiceName:      "chocolate",
+			ServicePort:      intstr.FromInt(9095),
+		},
+		Percent: 100,
+		AppendHeaders: map[string]string{
+			"Knative-Serving-Revision":  "revision",
+			"Knative-Serving-Namespace": "test-ns",
+		},
+	}}
+
+	if got := rule.HTTP.Paths[0].Splits; !cmp.Equal(want, got) {
+		t.Errorf("Unexpected splits (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
+
+// One active target of a tagged route.
+func TestMakeClusterIngressRule_Tagged(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			Tag:               "canary",
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "chocolate",
+		Active:      true,
+	}}
+	domains := []string{"canary-a.com"}
+	rule := makeIngressRule(domains, ns, "canary", false, targets)
+	expected := netv1alpha1.IngressRule{
+		Hosts: []string{
+			"canary-a.com",
+		},
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: "test-ns",
+						ServiceName:      "chocolate",
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100,
+					AppendHeaders: map[string]string{
+						"Knative-Serving-Revision":  "revision",
+						"Knative-Serving-Namespace": "test-ns",
+						"K-Route-Tag":               "canary",
+					},
+				}},
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	if !cmp.Equal(&expected, rule) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(&expected, rule))
+	}
+}
+
 // One active target and a target of zero percent.
 func TestMakeClusterIngressRule_ZeroPercentTarget(t *testing.T) {
 	targets := []traffic.RevisionTarget{{
@@ -484,7 +623,7 @@ func TestMakeClusterIngressRule_ZeroPercentTarget(t *testing.T) {
 	}}
 	domains := []string{"test.org"}
 	ns := "test-ns"
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -531,7 +670,7 @@ func TestMakeClusterIngressRule_TwoTargets(t *testing.T) {
 		Active:      true,
 	}}
 	domains := []string{"test.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -569,6 +708,345 @@ func TestMakeClusterIngressRule_TwoTargets(t *testing.T) {
 	}
 }
 
+func TestMakeClusterIngressRule_StickySplit(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           80,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "new-config",
+			RevisionName:      "new-revision",
+			Percent:           20,
+		},
+		ServiceName: "death",
+		Active:      true,
+	}}
+	r := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "route",
+			Annotations: map[string]string{
+				serving.StickySplitAnnotationKey:  serving.StickySplitHeader,
+				serving.StickyHeaderAnnotationKey: "X-User-Id",
+			},
+		},
+	}
+	rule := makeIngressRule([]string{"test.org"}, ns, "canary", false, targets)
+	stickThroughActivator(rule, r.Name, stickyBy(r))
+	expected := netv1alpha1.IngressRule{
+		Hosts: []string{"test.org"},
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: system.Namespace(),
+						ServiceName:      "activator-service",
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100,
+					AppendHeaders: map[string]string{
+						"Knative-Serving-Namespace": "test-ns",
+						"Knative-Serving-Route":     "route",
+						"Knative-Serving-Splits":    "revision=80,new-revision=20",
+						"Knative-Serving-Sticky":    "header:X-User-Id",
+						"K-Route-Tag":               "canary",
+					},
+				}},
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	if !cmp.Equal(&expected, rule) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(&expected, rule))
+	}
+}
+
+func TestMakeClusterIngressRule_StickySingleTarget(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}}
+	want := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	got := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	stickThroughActivator(got, "route", serving.StickySplitCookie)
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(want, got))
+	}
+}
+
+func TestMakeClusterIngressRule_Fallback(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "good-revision",
+			Fallback:          true,
+		},
+		ServiceName: "death",
+		Active:      true,
+	}}
+	rule := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	fallBackThroughActivator(rule, "route", fallbackRevision(targets))
+	expected := netv1alpha1.IngressRule{
+		Hosts: []string{"test.org"},
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{{
+					IngressBackend: netv1alpha1.IngressBackend{
+						ServiceNamespace: system.Namespace(),
+						ServiceName:      "activator-service",
+						ServicePort:      intstr.FromInt(80),
+					},
+					Percent: 100,
+					AppendHeaders: map[string]string{
+						"Knative-Serving-Namespace": "test-ns",
+						"Knative-Serving-Revision":  "revision",
+						"Knative-Serving-Route":     "route",
+						"Knative-Serving-Fallback":  "good-revision",
+					},
+				}},
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	if !cmp.Equal(&expected, rule) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(&expected, rule))
+	}
+}
+
+func TestMakeClusterIngressRule_Paths(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "api",
+			RevisionName:      "api-revision",
+			Percent:           100,
+			Path:              "/api",
+		},
+		ServiceName: "death",
+		Active:      true,
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "api",
+			RevisionName:      "api-v2-revision",
+			Percent:           100,
+			Path:              "/api/v2",
+		},
+		ServiceName: "rebirth",
+		Active:      true,
+	}}
+	split := func(service, revision string) []netv1alpha1.IngressBackendSplit {
+		return []netv1alpha1.IngressBackendSplit{{
+			IngressBackend: netv1alpha1.IngressBackend{
+				ServiceNamespace: ns,
+				ServiceName:      service,
+				ServicePort:      intstr.FromInt(80),
+			},
+			Percent: 100,
+			AppendHeaders: map[string]string{
+				"Knative-Serving-Namespace": "test-ns",
+				"Knative-Serving-Revision":  revision,
+			},
+		}}
+	}
+	rule := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	expected := netv1alpha1.IngressRule{
+		Hosts: []string{"test.org"},
+		HTTP: &netv1alpha1.HTTPIngressRuleValue{
+			Paths: []netv1alpha1.HTTPIngressPath{{
+				Path:   `^(/api/v2)([/?].*)?$`,
+				Splits: split("rebirth", "api-v2-revision"),
+			}, {
+				Path:   `^(/api)([/?].*)?$`,
+				Splits: split("death", "api-revision"),
+			}, {
+				Splits: split("nigh", "revision"),
+			}},
+		},
+		Visibility: netv1alpha1.IngressVisibilityExternalIP,
+	}
+
+	if !cmp.Equal(&expected, rule) {
+		t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(&expected, rule))
+	}
+}
+
+func TestMakeClusterIngressRule_PathOverrides(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "api",
+			RevisionName:      "api-revision",
+			Percent:           100,
+			Path:              "/api",
+		},
+		ServiceName: "death",
+		Active:      true,
+	}}
+	timeouts := map[string]time.Duration{
+		"/api":    10 * time.Second,
+		"/upload": 300 * time.Second,
+	}
+	retries := map[string]int{
+		"/api/batch": 0,
+	}
+	rule := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	api, catchAll := rule.HTTP.Paths[0], rule.HTTP.Paths[1]
+	overridePaths(rule, pathOverrides{timeouts: timeouts, retries: retries})
+
+	withOverrides := func(path netv1alpha1.HTTPIngressPath, prefix string, timeout time.Duration, attempts int) netv1alpha1.HTTPIngressPath {
+		path.Path = `^(` + prefix + `)([/?].*)?$`
+		path.Timeout = &metav1.Duration{Duration: timeout}
+		path.Retries = &netv1alpha1.HTTPRetry{
+			Attempts:      attempts,
+			PerTryTimeout: &metav1.Duration{Duration: timeout},
+		}
+		return path
+	}
+	want := []netv1alpha1.HTTPIngressPath{
+		withOverrides(api, "/api/batch", 10*time.Second, 0),
+		withOverrides(api, "/api", 10*time.Second, networking.DefaultRetryCount),
+		withOverrides(catchAll, "/upload", 300*time.Second, networking.DefaultRetryCount),
+		catchAll,
+	}
+	if !cmp.Equal(want, rule.HTTP.Paths) {
+		t.Errorf("Unexpected paths (-want, +got): %s", cmp.Diff(want, rule.HTTP.Paths))
+	}
+}
+
+func TestMakeClusterIngressRule_RewritesAndRedirects(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}}
+	rule := makeIngressRule([]string{"test.org"}, ns, "", false, targets)
+	catchAll := rule.HTTP.Paths[0]
+	overridePaths(rule, pathOverrides{rewrites: map[string]string{"/healthz": "/status"}})
+	rewriteHost(rule, "internal.example.com")
+	redirectPaths(rule, map[string]string{
+		"/blog":     "/posts",
+		"/blog/old": "/archive",
+	})
+
+	healthz := *catchAll.DeepCopy()
+	healthz.Path = `^(/healthz)([/?].*)?$`
+	healthz.Rewrite = &netv1alpha1.HTTPRewrite{
+		Path: "/status",
+		Host: "internal.example.com",
+	}
+	catchAll.Rewrite = &netv1alpha1.HTTPRewrite{
+		Host: "internal.example.com",
+	}
+	want := []netv1alpha1.HTTPIngressPath{{
+		Path:     `^(/blog/old)([/?].*)?$`,
+		Redirect: &netv1alpha1.HTTPRedirect{Path: "/archive"},
+	}, {
+		Path:     `^(/blog)([/?].*)?$`,
+		Redirect: &netv1alpha1.HTTPRedirect{Path: "/posts"},
+	}, healthz, catchAll}
+	if !cmp.Equal(want, rule.HTTP.Paths) {
+		t.Errorf("Unexpected paths (-want, +got): %s", cmp.Diff(want, rule.HTTP.Paths))
+	}
+}
+
+func TestMakeClusterIngressRule_Maintenance(t *testing.T) {
+	targets := []traffic.RevisionTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: "config",
+			RevisionName:      "revision",
+			Percent:           100,
+		},
+		ServiceName: "nigh",
+		Active:      true,
+	}}
+	maintenance := netv1alpha1.IngressBackendSplit{
+		IngressBackend: netv1alpha1.IngressBackend{
+			ServiceNamespace: system.Namespace(),
+			ServiceName:      "activator-service",
+			ServicePort:      intstr.FromInt(80),
+		},
+		Percent: 100,
+		AppendHeaders: map[string]string{
+			"Knative-Serving-Namespace":   "test-ns",
+			"Knative-Serving-Maintenance": "true",
+			"K-Route-Tag":                 "canary",
+		},
+	}
+
+	tests := []struct {
+		name  string
+		paths string
+		want  func(*netv1alpha1.IngressRule) []netv1alpha1.HTTPIngressPath
+	}{{
+		name: "all paths",
+		want: func(*netv1alpha1.IngressRule) []netv1alpha1.HTTPIngressPath {
+			return []netv1alpha1.HTTPIngressPath{{
+				Splits: []netv1alpha1.IngressBackendSplit{maintenance},
+			}}
+		},
+	}, {
+		name:  "some paths",
+		paths: "/checkout,/api/v1.0/",
+		want: func(rule *netv1alpha1.IngressRule) []netv1alpha1.HTTPIngressPath {
+			return append([]netv1alpha1.HTTPIngressPath{{
+				Path:   `^(/checkout|/api/v1\.0)([/?].*)?$`,
+				Splits: []netv1alpha1.IngressBackendSplit{maintenance},
+			}}, rule.HTTP.Paths...)
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := makeIngressRule([]string{"test.org"}, ns, "canary", false, targets)
+			want.HTTP.Paths = test.want(want)
+			got := makeIngressRule([]string{"test.org"}, ns, "canary", false, targets)
+			maintainThroughActivator(got, ns, "canary", test.paths)
+
+			if !cmp.Equal(want, got) {
+				t.Errorf("Unexpected rule (-want, +got): %s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
 // Inactive target.
 func TestMakeClusterIngressRule_InactiveTarget(t *testing.T) {
 	targets := []traffic.RevisionTarget{{
@@ -581,7 +1059,7 @@ func TestMakeClusterIngressRule_InactiveTarget(t *testing.T) {
 		Active:      false,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -630,7 +1108,7 @@ func TestMakeClusterIngressRule_TwoInactiveTargets(t *testing.T) {
 		Active:      false,
 	}}
 	domains := []string{"a.com", "b.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{
 			"a.com",
@@ -688,7 +1166,7 @@ func TestMakeClusterIngressRule_ZeroPercentTargetInactive(t *testing.T) {
 		Active: false,
 	}}
 	domains := []string{"test.org"}
-	rule := makeIngressRule(domains, ns, false, targets)
+	rule := makeIngressRule(domains, ns, "", false, targets)
 	expected := netv1alpha1.IngressRule{
 		Hosts: []string{"test.org"},
 		HTTP: &netv1alpha1.HTTPIngressRuleValue{
@@ -0,0 +1,35 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+// makeLabels returns the labels of the child resources of route: its name,
+// and the name of the Service owning it, if any. The resources can thus be
+// selected per Route or per Service, e.g. for cost allocation.
+func makeLabels(route *v1alpha1.Route) map[string]string {
+	labels := map[string]string{
+		serving.RouteLabelKey: route.Name,
+	}
+	if service, ok := route.Labels[serving.ServiceLabelKey]; ok {
+		labels[serving.ServiceLabelKey] = service
+	}
+	return labels
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestMakeLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		route *v1alpha1.Route
+		want  map[string]string
+	}{{
+		name: "route",
+		route: &v1alpha1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "route",
+				Namespace: "default",
+				Labels: map[string]string{
+					"team": "payments",
+				},
+			},
+		},
+		want: map[string]string{
+			serving.RouteLabelKey: "route",
+		},
+	}, {
+		name: "route of a service",
+		route: &v1alpha1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "route",
+				Namespace: "default",
+				Labels: map[string]string{
+					serving.ServiceLabelKey: "service",
+				},
+			},
+		},
+		want: map[string]string{
+			serving.RouteLabelKey:   "route",
+			serving.ServiceLabelKey: "service",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, makeLabels(test.route)); diff != "" {
+				t.Errorf("makeLabels (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
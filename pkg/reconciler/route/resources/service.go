@@ -105,9 +105,7 @@ func makeK8sService(ctx context.Context, route *v1alpha1.Route, targetName strin
 		return nil, err
 	}
 
-	svcLabels := map[string]string{
-		serving.RouteLabelKey: route.Name,
-	}
+	svcLabels := makeLabels(route)
 
 	if visibility, ok := route.Labels[config.VisibilityLabelKey]; ok {
 		svcLabels[config.VisibilityLabelKey] = visibility
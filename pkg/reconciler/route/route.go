@@ -19,6 +19,11 @@ package route
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -42,6 +47,8 @@ import (
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	networkinglisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
 	listers "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	"knative.dev/serving/pkg/informers"
+	"knative.dev/serving/pkg/network"
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/domains"
@@ -72,10 +79,21 @@ type Reconciler struct {
 	clusterIngressLister networkinglisters.ClusterIngressLister
 	ingressLister        networkinglisters.IngressLister
 	certificateLister    networkinglisters.CertificateLister
+	// serviceIndexer and clusterIngressIndexer look the Services and
+	// ClusterIngresses up by their Route.
+	serviceIndexer        cache.Indexer
+	clusterIngressIndexer cache.Indexer
+	// certificateInformers are started once auto TLS is enabled.
+	certificateInformers *informers.Lazy
 	configStore          reconciler.ConfigStore
 	tracker              tracker.Interface
 
 	clock system.Clock
+
+	// resolverFor returns the hostResolver querying the given DNS servers.
+	resolverFor func([]string) hostResolver
+	// enqueueAfter queues the Route up to be reconciled again later.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -125,6 +143,7 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 		c.Recorder.Event(route, corev1.EventTypeWarning, "InternalError", reconcileErr.Error())
 		return reconcileErr
 	}
+	c.recordStatusEvents(original, route)
 	// TODO(mattmoor): Remove this after 0.7 cuts.
 	// If the spec has changed, then assume we need an upgrade and issue a patch to trigger
 	// the webhook to upgrade via defaulting.  Status updates do not trigger this due to the
@@ -153,14 +172,15 @@ func certClass(ctx context.Context, r *v1alpha1.Route) string {
 }
 
 func (c *Reconciler) getServices(route *v1alpha1.Route) ([]*corev1.Service, error) {
-	currentServices, err := c.serviceLister.Services(route.Namespace).List(resources.SelectorFromRoute(route))
+	currentServices, err := c.serviceIndexer.ByIndex(reconciler.RouteIndex,
+		reconciler.IndexKey(route.Namespace, route.Name))
 	if err != nil {
 		return nil, err
 	}
 
 	serviceCopy := make([]*corev1.Service, len(currentServices))
 	for i, svc := range currentServices {
-		serviceCopy[i] = svc.DeepCopy()
+		serviceCopy[i] = svc.(*corev1.Service).DeepCopy()
 	}
 
 	return serviceCopy, err
@@ -249,7 +269,8 @@ func (c *Reconciler) reconcile(ctx context.Context, r *v1alpha1.Route) error {
 			BaseIngressResources: BaseIngressResources{
 				servingClientSet: c.ServingClientSet,
 			},
-			clusterIngressLister: c.clusterIngressLister,
+			clusterIngressLister:  c.clusterIngressLister,
+			clusterIngressIndexer: c.clusterIngressIndexer,
 		},
 		true, /* optional */
 	)
@@ -273,7 +294,15 @@ func (c *Reconciler) reconcile(ctx context.Context, r *v1alpha1.Route) error {
 		return err
 	}
 
+	wasReady := r.Status.GetCondition(v1alpha1.RouteConditionIngressReady).IsTrue()
 	r.Status.PropagateIngressStatus(*ingress.GetStatus())
+	// Routes becoming ready wait for their domain to resolve to the ingress,
+	// when that's asked for. Cluster-local domains always resolve.
+	if nc := config.FromContext(ctx).Network; nc.DomainResolutionCheck && !wasReady &&
+		r.Status.GetCondition(v1alpha1.RouteConditionIngressReady).IsTrue() &&
+		!strings.HasSuffix(r.Status.URL.Host, network.GetClusterDomainName()) {
+		c.checkDomainResolution(ctx, r, ingress, nc.DomainResolvers)
+	}
 
 	logger.Info("Updating placeholder k8s services with clusterIngress information")
 	if err := c.updatePlaceholderServices(ctx, r, services, ingress); err != nil {
@@ -306,6 +335,9 @@ func (c *Reconciler) tls(ctx context.Context, host string, r *v1alpha1.Route, tr
 	if !config.FromContext(ctx).Network.AutoTLS {
 		return tls, nil
 	}
+	if err := c.certificateInformers.Start(); err != nil {
+		return nil, err
+	}
 	tagToDomainMap, err := domains.GetAllDomainsAndTags(ctx, r, getTrafficNames(traffic.Targets), clusterLocalServiceNames)
 	if err != nil {
 		return nil, err
@@ -328,7 +360,11 @@ func (c *Reconciler) tls(ctx context.Context, host string, r *v1alpha1.Route, tr
 
 		dnsNames := sets.NewString(cert.Spec.DNSNames...)
 		if cert.Status.IsReady() {
-			r.Status.MarkCertificateReady(cert.Name)
+			if cert.Status.IsExpiring() {
+				r.Status.MarkCertificateExpiring(cert.Name, cert.Status.NotAfter.Time)
+			} else {
+				r.Status.MarkCertificateReady(cert.Name)
+			}
 			// r.Status.URL is for the major domain, so only change if the cert is for
 			// the major domain
 			if dnsNames.Has(host) {
@@ -418,6 +454,9 @@ func (c *Reconciler) configureTraffic(ctx context.Context, r *v1alpha1.Route, cl
 
 	logger.Info("All referred targets are routable, marking AllTrafficAssigned with traffic information.")
 	// Domain should already be present
+	// TODO: surface the per-tag request counts the queue-proxies record in
+	// annotations of the status, once the controller can read them from a
+	// metrics backend. Until then they are left to the monitoring stack.
 	r.Status.Traffic, err = t.GetRevisionTrafficTargets(ctx, r, clusterLocalServices)
 	if err != nil {
 		return nil, err
@@ -567,3 +606,48 @@ func setTargetsScheme(rs *v1alpha1.RouteStatus, dnsNames []string, scheme string
 		}
 	}
 }
+
+// recordStatusEvents emits Events for the user-relevant transitions between
+// the old and the new status of the Route: traffic moving between Revisions
+// and the Route's domain becoming reachable.
+func (c *Reconciler) recordStatusEvents(old, new *v1alpha1.Route) {
+	oldSplit, newSplit := trafficSplit(old.Status.Traffic), trafficSplit(new.Status.Traffic)
+	if len(newSplit) > 0 && !equality.Semantic.DeepEqual(oldSplit, newSplit) {
+		c.Recorder.Eventf(new, corev1.EventTypeNormal, "TrafficShifted",
+			"Traffic shifted to %s", describeTrafficSplit(newSplit))
+	}
+
+	if new.Status.URL != nil && isIngressReady(new) && !isIngressReady(old) {
+		c.Recorder.Eventf(new, corev1.EventTypeNormal, "DomainProgrammed",
+			"Route is reachable at %s", new.Status.URL)
+	}
+}
+
+// trafficSplit returns the percentage of traffic that each Revision receives.
+func trafficSplit(targets []v1alpha1.TrafficTarget) map[string]int {
+	split := make(map[string]int, len(targets))
+	for _, tt := range targets {
+		if tt.Percent > 0 {
+			split[tt.RevisionName] += tt.Percent
+		}
+	}
+	return split
+}
+
+func describeTrafficSplit(split map[string]int) string {
+	revs := make([]string, 0, len(split))
+	for rev := range split {
+		revs = append(revs, rev)
+	}
+	sort.Strings(revs)
+	parts := make([]string, 0, len(revs))
+	for _, rev := range revs {
+		parts = append(parts, fmt.Sprintf("%s=%d%%", rev, split[rev]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func isIngressReady(r *v1alpha1.Route) bool {
+	cond := r.Status.GetCondition(v1alpha1.RouteConditionIngressReady)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
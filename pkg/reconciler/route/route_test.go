@@ -40,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -47,6 +48,7 @@ import (
 	"knative.dev/pkg/controller"
 	logtesting "knative.dev/pkg/logging/testing"
 	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/networking"
 	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
@@ -672,6 +674,7 @@ func TestCreateRouteWithDuplicateTargets(t *testing.T) {
 						AppendHeaders: map[string]string{
 							"Knative-Serving-Revision":  rev.Name,
 							"Knative-Serving-Namespace": testNamespace,
+							"K-Route-Tag":               "test-revision-1",
 						},
 					}},
 				}},
@@ -694,6 +697,7 @@ func TestCreateRouteWithDuplicateTargets(t *testing.T) {
 						AppendHeaders: map[string]string{
 							"Knative-Serving-Revision":  rev.Name,
 							"Knative-Serving-Namespace": testNamespace,
+							"K-Route-Tag":               "test-revision-2",
 						},
 					}},
 				}},
@@ -806,6 +810,7 @@ func TestCreateRouteWithNamedTargets(t *testing.T) {
 						AppendHeaders: map[string]string{
 							"Knative-Serving-Revision":  cfgrev.Name,
 							"Knative-Serving-Namespace": testNamespace,
+							"K-Route-Tag":               "bar",
 						},
 					}},
 				}},
@@ -828,6 +833,7 @@ func TestCreateRouteWithNamedTargets(t *testing.T) {
 						AppendHeaders: map[string]string{
 							"Knative-Serving-Revision":  rev.Name,
 							"Knative-Serving-Namespace": testNamespace,
+							"K-Route-Tag":               "foo",
 						},
 					}},
 				}},
@@ -1113,3 +1119,53 @@ func TestRouteDomain(t *testing.T) {
 		}
 	}
 }
+
+func TestAffectedByConfig(t *testing.T) {
+	withClass := &v1alpha1.Route{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{networking.IngressClassAnnotationKey: "foo.ingress.networking.knative.dev"},
+		},
+	}
+	tests := []struct {
+		name    string
+		config  string
+		changed []string
+		route   *v1alpha1.Route
+		want    bool
+	}{{
+		name:   "domain config",
+		config: config.DomainConfigName,
+		route:  &v1alpha1.Route{},
+		want:   true,
+	}, {
+		name:    "domain template",
+		config:  network.ConfigName,
+		changed: []string{"DomainTemplate"},
+		route:   withClass,
+		want:    true,
+	}, {
+		name:    "default ingress class",
+		config:  network.ConfigName,
+		changed: []string{"DefaultClusterIngressClass"},
+		route:   &v1alpha1.Route{},
+		want:    true,
+	}, {
+		name:    "default ingress class overridden",
+		config:  network.ConfigName,
+		changed: []string{"DefaultClusterIngressClass"},
+		route:   withClass,
+	}, {
+		name:    "deployment fields",
+		config:  network.ConfigName,
+		changed: []string{"IstioOutboundIPRanges", "TrustedHops"},
+		route:   &v1alpha1.Route{},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := affectedByConfig(test.config, sets.NewString(test.changed...), test.route); got != test.want {
+				t.Errorf("affectedByConfig() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -48,7 +48,9 @@ import (
 	"knative.dev/serving/pkg/reconciler"
 	"knative.dev/serving/pkg/reconciler/route/config"
 	"knative.dev/serving/pkg/reconciler/route/resources"
+	resourcenames "knative.dev/serving/pkg/reconciler/route/resources/names"
 	"knative.dev/serving/pkg/reconciler/route/traffic"
+	presources "knative.dev/serving/pkg/resources"
 
 	. "knative.dev/pkg/reconciler/testing"
 	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
@@ -170,6 +172,7 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
 		},
 		Key: "default/becomes-ready",
 		// TODO(lichuqiang): config namespace validation in resource scope.
@@ -228,6 +231,7 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
 		},
 		Key: "default/becomes-ready",
 		// TODO(lichuqiang): config namespace validation in resource scope.
@@ -290,6 +294,7 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
 		},
 		Key: "default/becomes-ready",
 		// TODO(lichuqiang): config namespace validation in resource scope.
@@ -356,6 +361,8 @@ func TestReconcile(t *testing.T) {
 		}},
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
+			Eventf(corev1.EventTypeNormal, "DomainProgrammed", "Route is reachable at %s", "http://becomes-ready.default.example.com"),
 		},
 		Key: "default/becomes-ready",
 	}, {
@@ -849,6 +856,9 @@ func TestReconcile(t *testing.T) {
 		}},
 		Key:                     "default/new-latest-ready",
 		SkipNamespaceValidation: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00002=100%"),
+		},
 	}, {
 		Name: "failure updating cluster ingress",
 		// Starting from the new latest ready, induce a failure updating the cluster ingress.
@@ -1365,6 +1375,9 @@ func TestReconcile(t *testing.T) {
 					})),
 		}},
 		Key: "default/change-configs",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "newconfig-00001=100%"),
+		},
 	}, {
 		Name: "configuration missing",
 		Objects: []runtime.Object{
@@ -1459,6 +1472,10 @@ func TestReconcile(t *testing.T) {
 		}},
 		Key:                     "default/pinned-becomes-ready",
 		SkipNamespaceValidation: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
+			Eventf(corev1.EventTypeNormal, "DomainProgrammed", "Route is reachable at %s", "http://pinned-becomes-ready.default.example.com"),
+		},
 	}, {
 		Name: "traffic split becomes ready",
 		Objects: []runtime.Object{
@@ -1566,6 +1583,7 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "named-traffic-split"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "named-traffic-split"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "blue-00001=50%, green-00001=50%"),
 		},
 		Key:                     "default/named-traffic-split",
 		SkipNamespaceValidation: true,
@@ -1739,6 +1757,7 @@ func TestReconcile(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "also-gray-same-revision-targets"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "gray-same-revision-targets"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "same-revision-targets"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "gray-00001=100%"),
 		},
 		Key:                     "default/same-revision-targets",
 		SkipNamespaceValidation: true,
@@ -1850,6 +1869,9 @@ func TestReconcile(t *testing.T) {
 		}},
 		Key:                     "default/switch-configs",
 		SkipNamespaceValidation: true,
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "green-00001=100%"),
+		},
 	}, {
 		Name: "update single target to traffic split with unready revision",
 		// Start from a steady state referencing "blue", and modify the route spec to point to both
@@ -2249,14 +2271,16 @@ func TestReconcile(t *testing.T) {
 	defer logtesting.ClearAll()
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
 		return &Reconciler{
-			Base:                 reconciler.NewBase(ctx, controllerAgentName, cmw),
-			routeLister:          listers.GetRouteLister(),
-			configurationLister:  listers.GetConfigurationLister(),
-			revisionLister:       listers.GetRevisionLister(),
-			serviceLister:        listers.GetK8sServiceLister(),
-			clusterIngressLister: listers.GetClusterIngressLister(),
-			ingressLister:        listers.GetIngressLister(),
-			tracker:              &NullTracker{},
+			Base:                  reconciler.NewBase(ctx, controllerAgentName, cmw),
+			routeLister:           listers.GetRouteLister(),
+			configurationLister:   listers.GetConfigurationLister(),
+			revisionLister:        listers.GetRevisionLister(),
+			serviceLister:         listers.GetK8sServiceLister(),
+			serviceIndexer:        listers.GetIndexer(&corev1.Service{}),
+			clusterIngressLister:  listers.GetClusterIngressLister(),
+			clusterIngressIndexer: listers.GetIndexer(&netv1alpha1.ClusterIngress{}),
+			ingressLister:         listers.GetIngressLister(),
+			tracker:               &NullTracker{},
 			configStore: &testConfigStore{
 				config: ReconcilerTestConfig(false),
 			},
@@ -2324,6 +2348,7 @@ func TestReconcile_EnableAutoTLS(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Certificate %q/%q", "default", "route-12-34"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
 		},
 		Key:                     "default/becomes-ready",
 		SkipNamespaceValidation: true,
@@ -2408,6 +2433,92 @@ func TestReconcile_EnableAutoTLS(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
 			Eventf(corev1.EventTypeNormal, "Updated", "Updated Spec for Certificate %s/%s", "default", "route-12-34"),
 			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
+		},
+		Key:                     "default/becomes-ready",
+		SkipNamespaceValidation: true,
+	}, {
+		Name: "check that an expiring Certificate is reported in the Route status",
+		Objects: []runtime.Object{
+			route("default", "becomes-ready", WithConfigTarget("config"), WithRouteUID("12-34")),
+			cfg("default", "config",
+				WithGeneration(1), WithLatestCreated("config-00001"), WithLatestReady("config-00001")),
+			rev("default", "config", 1, MarkRevisionReady, WithRevName("config-00001"), WithServiceName("mcd")),
+			// MakeCertificates will create a certificate with DNS name "*.test-ns.example.com" which is not the host name
+			// needed by the input Route.
+			&netv1alpha1.Certificate{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "route-12-34",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(
+						route("default", "becomes-ready", WithConfigTarget("config"), WithRouteUID("12-34")))},
+					Annotations: map[string]string{
+						networking.CertificateClassAnnotationKey: network.CertManagerCertificateClassName,
+					},
+				},
+				Spec: netv1alpha1.CertificateSpec{
+					DNSNames: []string{"abc.test.example.com"},
+				},
+				Status: expiringCertStatus(),
+			},
+		},
+		WantCreates: []runtime.Object{
+			ingressWithTLS(
+				route("default", "becomes-ready", WithConfigTarget("config"), WithURL,
+					WithRouteUID("12-34")),
+				&traffic.Config{
+					Targets: map[string]traffic.RevisionTargets{
+						traffic.DefaultTarget: {{
+							TrafficTarget: v1beta1.TrafficTarget{
+								// Use the Revision name from the config.
+								RevisionName: "config-00001",
+								Percent:      100,
+							},
+							ServiceName: "mcd",
+							Active:      true,
+						}},
+					},
+				},
+				[]netv1alpha1.IngressTLS{
+					{
+						Hosts:           []string{"becomes-ready.default.example.com"},
+						SecretName:      "route-12-34",
+						SecretNamespace: "default",
+					},
+				},
+			),
+			simpleK8sService(
+				route("default", "becomes-ready", WithConfigTarget("config"), WithRouteUID("12-34")),
+				WithExternalName("becomes-ready.default.example.com"),
+			),
+		},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: certificateWithStatus(resources.MakeCertificates(route("default", "becomes-ready", WithConfigTarget("config"), WithURL, WithRouteUID("12-34")),
+				map[string]string{"becomes-ready.default.example.com": ""}, network.CertManagerCertificateClassName)[0], expiringCertStatus()),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patchFinalizers("default", "becomes-ready"),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route("default", "becomes-ready", WithConfigTarget("config"),
+				WithRouteUID("12-34"),
+				// Populated by reconciliation when all traffic has been assigned.
+				WithAddress, WithInitRouteConditions,
+				MarkTrafficAssigned, MarkIngressNotConfigured, WithStatusTraffic(v1alpha1.TrafficTarget{
+					TrafficTarget: v1beta1.TrafficTarget{
+						RevisionName:   "config-00001",
+						Percent:        100,
+						LatestRevision: ptr.Bool(true),
+					},
+				}), markCertificateExpiring,
+				// The certificate is still valid. So we want to have HTTPS URL.
+				WithHTTPSDomain),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Created", "Created placeholder service %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Spec for Certificate %s/%s", "default", "route-12-34"),
+			Eventf(corev1.EventTypeNormal, "Created", "Created Ingress %q", "becomes-ready"),
+			Eventf(corev1.EventTypeNormal, "TrafficShifted", "Traffic shifted to %s", "config-00001=100%"),
 		},
 		Key:                     "default/becomes-ready",
 		SkipNamespaceValidation: true,
@@ -2415,15 +2526,17 @@ func TestReconcile_EnableAutoTLS(t *testing.T) {
 	defer logtesting.ClearAll()
 	table.Test(t, MakeFactory(func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
 		return &Reconciler{
-			Base:                 reconciler.NewBase(ctx, controllerAgentName, cmw),
-			routeLister:          listers.GetRouteLister(),
-			configurationLister:  listers.GetConfigurationLister(),
-			revisionLister:       listers.GetRevisionLister(),
-			serviceLister:        listers.GetK8sServiceLister(),
-			clusterIngressLister: listers.GetClusterIngressLister(),
-			ingressLister:        listers.GetIngressLister(),
-			certificateLister:    listers.GetCertificateLister(),
-			tracker:              &NullTracker{},
+			Base:                  reconciler.NewBase(ctx, controllerAgentName, cmw),
+			routeLister:           listers.GetRouteLister(),
+			configurationLister:   listers.GetConfigurationLister(),
+			revisionLister:        listers.GetRevisionLister(),
+			serviceLister:         listers.GetK8sServiceLister(),
+			serviceIndexer:        listers.GetIndexer(&corev1.Service{}),
+			clusterIngressLister:  listers.GetClusterIngressLister(),
+			clusterIngressIndexer: listers.GetIndexer(&netv1alpha1.ClusterIngress{}),
+			ingressLister:         listers.GetIngressLister(),
+			certificateLister:     listers.GetCertificateLister(),
+			tracker:               &NullTracker{},
 			configStore: &testConfigStore{
 				config: ReconcilerTestConfig(true),
 			},
@@ -2535,6 +2648,8 @@ func baseIngressWithClass(r *v1alpha1.Route, tc *traffic.Config, class string, s
 	for _, opt := range io {
 		opt(ingress)
 	}
+	// The spec of the ingress is the one we applied.
+	presources.SetLastAppliedSpec(ingress, ingress.GetSpec())
 
 	return ingress
 }
@@ -2549,6 +2664,8 @@ func baseIngressWithTLS(r *v1alpha1.Route, tc *traffic.Config, tls []netv1alpha1
 	for _, opt := range io {
 		opt(ingress)
 	}
+	// The spec of the ingress is the one we applied.
+	presources.SetLastAppliedSpec(ingress, ingress.GetSpec())
 
 	return ingress
 }
@@ -2560,6 +2677,9 @@ func simpleReadyClusterIngress(r *v1alpha1.Route, tc *traffic.Config, io ...Ingr
 		opt(ingress)
 	}
 
+	// The spec of the ingress is the one we applied.
+	presources.SetLastAppliedSpec(ingress, ingress.GetSpec())
+
 	return ingress
 }
 
@@ -2570,6 +2690,9 @@ func simpleReadyIngress(r *v1alpha1.Route, tc *traffic.Config, io ...IngressOpti
 		opt(ingress)
 	}
 
+	// The spec of the ingress is the one we applied.
+	presources.SetLastAppliedSpec(ingress, ingress.GetSpec())
+
 	return ingress
 }
 
@@ -2700,6 +2823,17 @@ func readyCertStatus() netv1alpha1.CertificateStatus {
 	return *certStatus
 }
 
+func expiringCertStatus() netv1alpha1.CertificateStatus {
+	certStatus := readyCertStatus()
+	certStatus.NotAfter = &metav1.Time{Time: fakeCurTime.Add(24 * time.Hour)}
+	certStatus.MarkNotRenewed(fakeCurTime)
+	return certStatus
+}
+
+func markCertificateExpiring(r *v1alpha1.Route) {
+	r.Status.MarkCertificateExpiring(resourcenames.Certificate(r), fakeCurTime.Add(24*time.Hour))
+}
+
 func certificateWithStatus(cert *netv1alpha1.Certificate, status netv1alpha1.CertificateStatus) *netv1alpha1.Certificate {
 	cert.Status = status
 	return cert
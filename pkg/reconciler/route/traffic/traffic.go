@@ -43,6 +43,9 @@ type RevisionTarget struct {
 	Active      bool
 	Protocol    net.ProtocolType
 	ServiceName string // Revision service name.
+	// Port is the named port of the revision the traffic goes to, or 0 for
+	// the one of its protocol.
+	Port int32
 }
 
 // RevisionTargets is a collection of revision targets.
@@ -74,6 +77,7 @@ type Config struct {
 func BuildTrafficConfiguration(configLister listers.ConfigurationLister, revLister listers.RevisionLister,
 	r *v1alpha1.Route) (*Config, error) {
 	builder := newBuilder(configLister, revLister, r.Namespace, len(r.Spec.Traffic))
+	builder.portName = r.Annotations[serving.PortAnnotationKey]
 	builder.applySpecTraffic(r.Spec.Traffic)
 	return builder.build()
 }
@@ -90,6 +94,8 @@ func (t *Config) GetRevisionTrafficTargets(ctx context.Context, r *v1alpha1.Rout
 				RevisionName:   tt.RevisionName,
 				Percent:        tt.Percent,
 				LatestRevision: tt.LatestRevision,
+				Fallback:       tt.Fallback,
+				Path:           tt.Path,
 			},
 		}
 		if tt.Tag != "" {
@@ -117,6 +123,9 @@ type configBuilder struct {
 	configLister listers.ConfigurationLister
 	revLister    listers.RevisionLister
 	namespace    string
+	// portName is the name of the port of the revisions the traffic goes to,
+	// if not the one of their protocol.
+	portName string
 
 	// targets is a grouping of traffic targets serving the same origin.
 	targets map[string]RevisionTargets
@@ -228,12 +237,27 @@ func (t *configBuilder) addConfigurationTarget(tt *v1alpha1.TrafficTarget) error
 		Active:        !rev.Status.IsActivationRequired(),
 		Protocol:      rev.GetProtocol(),
 		ServiceName:   rev.Status.ServiceName,
+		Port:          t.port(rev),
 	}
 	target.TrafficTarget.RevisionName = rev.Name
 	t.addFlattenedTarget(target)
 	return nil
 }
 
+// port returns the number of the port of the revision named by portName, or 0
+// if it has none.
+func (t *configBuilder) port(rev *v1alpha1.Revision) int32 {
+	if t.portName == "" {
+		return 0
+	}
+	for _, p := range rev.GetPorts() {
+		if p.Name == t.portName {
+			return p.Port
+		}
+	}
+	return 0
+}
+
 func (t *configBuilder) addRevisionTarget(tt *v1alpha1.TrafficTarget) error {
 	rev, err := t.getRevision(tt.RevisionName)
 	if err != nil {
@@ -248,6 +272,7 @@ func (t *configBuilder) addRevisionTarget(tt *v1alpha1.TrafficTarget) error {
 		Active:        !rev.Status.IsActivationRequired(),
 		Protocol:      rev.GetProtocol(),
 		ServiceName:   rev.Status.ServiceName,
+		Port:          t.port(rev),
 	}
 	t.revisions[tt.RevisionName] = rev
 	if configName, ok := rev.Labels[serving.ConfigurationLabelKey]; ok {
@@ -265,30 +290,39 @@ func (t *configBuilder) addFlattenedTarget(target RevisionTarget) {
 	t.revisionTargets = append(t.revisionTargets, target)
 	t.targets[DefaultTarget] = append(t.targets[DefaultTarget], target)
 	if name != "" {
+		// The tagged domain serves every path by its target.
+		target.TrafficTarget.Path = ""
 		t.targets[name] = append(t.targets[name], target)
 	}
 }
 
 func consolidate(targets RevisionTargets) RevisionTargets {
-	byName := make(map[string]RevisionTarget)
-	names := []string{}
+	// The targets are consolidated within the path they serve.
+	type key struct {
+		path, name string
+	}
+	byKey := make(map[key]RevisionTarget)
+	keys := []key{}
+	perPath := make(map[string]int)
 	for _, tt := range targets {
-		name := tt.TrafficTarget.RevisionName
-		cur, ok := byName[name]
+		k := key{path: tt.TrafficTarget.Path, name: tt.TrafficTarget.RevisionName}
+		cur, ok := byKey[k]
 		if !ok {
-			byName[name] = tt
-			names = append(names, name)
+			byKey[k] = tt
+			keys = append(keys, k)
+			perPath[k.path]++
 		} else {
 			cur.TrafficTarget.Percent += tt.TrafficTarget.Percent
-			byName[name] = cur
+			cur.TrafficTarget.Fallback = cur.TrafficTarget.Fallback || tt.TrafficTarget.Fallback
+			byKey[k] = cur
 		}
 	}
-	consolidated := make([]RevisionTarget, len(names))
-	for i, name := range names {
-		consolidated[i] = byName[name]
-	}
-	if len(consolidated) == 1 {
-		consolidated[0].TrafficTarget.Percent = 100
+	consolidated := make([]RevisionTarget, len(keys))
+	for i, k := range keys {
+		consolidated[i] = byKey[k]
+		if perPath[k.path] == 1 {
+			consolidated[i].TrafficTarget.Percent = 100
+		}
 	}
 	return consolidated
 }
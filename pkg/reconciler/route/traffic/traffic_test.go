@@ -166,6 +166,52 @@ func TestBuildTrafficConfiguration_Vanilla(t *testing.T) {
 	}
 }
 
+// The route sends its traffic to the named port of the revisions that have it.
+func TestBuildTrafficConfiguration_Port(t *testing.T) {
+	config, oldRev, newRev := getTestReadyConfig("ported")
+	newRev.Spec.GetContainer().Ports = []corev1.ContainerPort{{
+		Name: "h2c",
+	}, {
+		Name:          "metrics",
+		ContainerPort: 9095,
+	}}
+	servingInformer := informers.NewSharedInformerFactory(fakeclientset.NewSimpleClientset(), 0)
+	configInformer := servingInformer.Serving().V1alpha1().Configurations()
+	configInformer.Informer().GetIndexer().Add(config)
+	revInformer := servingInformer.Serving().V1alpha1().Revisions()
+	revInformer.Informer().GetIndexer().Add(oldRev)
+	revInformer.Informer().GetIndexer().Add(newRev)
+
+	route := testRouteWithTrafficTargets([]v1alpha1.TrafficTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			ConfigurationName: config.Name,
+			Percent:           90,
+		},
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: oldRev.Name,
+			Percent:      10,
+		},
+	}})
+	route.Annotations = map[string]string{serving.PortAnnotationKey: "metrics"}
+
+	tc, err := BuildTrafficConfiguration(configInformer.Lister(), revInformer.Lister(), route)
+	if err != nil {
+		t.Fatalf("BuildTrafficConfiguration() = %v", err)
+	}
+	got := map[string]int32{}
+	for _, target := range tc.Targets[DefaultTarget] {
+		got[target.RevisionName] = target.Port
+	}
+	want := map[string]int32{
+		newRev.Name: 9095,
+		oldRev.Name: 0,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Ports (-want +got): %v", diff)
+	}
+}
+
 func TestBuildTrafficConfiguration_NoNameRevision(t *testing.T) {
 	tts := []v1alpha1.TrafficTarget{{
 		TrafficTarget: v1beta1.TrafficTarget{
@@ -559,6 +605,87 @@ func TestBuildTrafficConfiguration_TwoFixedRevisions(t *testing.T) {
 	}
 }
 
+// Serving a path of the domain by another revision.
+func TestBuildTrafficConfiguration_Paths(t *testing.T) {
+	tts := []v1alpha1.TrafficTarget{{
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: goodOldRev.Name,
+			Percent:      100,
+		},
+	}, {
+		TrafficTarget: v1beta1.TrafficTarget{
+			Tag:          "api",
+			RevisionName: goodNewRev.Name,
+			Percent:      100,
+			Path:         "/api",
+		},
+	}}
+	expected := &Config{
+		Targets: map[string]RevisionTargets{
+			DefaultTarget: {{
+				TrafficTarget: v1beta1.TrafficTarget{
+					ConfigurationName: goodConfig.Name,
+					RevisionName:      goodOldRev.Name,
+					Percent:           100,
+				},
+				Active:   true,
+				Protocol: net.ProtocolHTTP1,
+			}, {
+				TrafficTarget: v1beta1.TrafficTarget{
+					Tag:               "api",
+					ConfigurationName: goodConfig.Name,
+					RevisionName:      goodNewRev.Name,
+					Percent:           100,
+					Path:              "/api",
+				},
+				Active:   true,
+				Protocol: net.ProtocolH2C,
+			}},
+			"api": {{
+				TrafficTarget: v1beta1.TrafficTarget{
+					Tag:               "api",
+					ConfigurationName: goodConfig.Name,
+					RevisionName:      goodNewRev.Name,
+					Percent:           100,
+				},
+				Active:   true,
+				Protocol: net.ProtocolH2C,
+			}},
+		},
+		revisionTargets: []RevisionTarget{{
+			TrafficTarget: v1beta1.TrafficTarget{
+				ConfigurationName: goodConfig.Name,
+				RevisionName:      goodOldRev.Name,
+				Percent:           100,
+			},
+			Active:   true,
+			Protocol: net.ProtocolHTTP1,
+		}, {
+			TrafficTarget: v1beta1.TrafficTarget{
+				Tag:               "api",
+				ConfigurationName: goodConfig.Name,
+				RevisionName:      goodNewRev.Name,
+				Percent:           100,
+				Path:              "/api",
+			},
+			Active:   true,
+			Protocol: net.ProtocolH2C,
+		}},
+		Configurations: map[string]*v1alpha1.Configuration{
+			goodConfig.Name: goodConfig,
+		},
+		Revisions: map[string]*v1alpha1.Revision{
+			goodNewRev.Name: goodNewRev,
+			goodOldRev.Name: goodOldRev,
+		},
+	}
+	if tc, err := BuildTrafficConfiguration(configLister, revLister, testRouteWithTrafficTargets(tts)); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	} else if got, want := tc, expected; !cmp.Equal(want, got, cmpOpts...) {
+		t.Errorf("Unexpected traffic diff (-want +got): %v", cmp.Diff(want, got, cmpOpts...))
+	}
+}
+
 // Splitting traffic between a two fixed revisions of two configurations.
 func TestBuildTrafficConfiguration_TwoFixedRevisionsFromTwoConfigurations(t *testing.T) {
 	tts := []v1alpha1.TrafficTarget{{
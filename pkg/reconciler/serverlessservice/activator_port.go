@@ -0,0 +1,81 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverlessservice
+
+import (
+	"context"
+	"errors"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/networking"
+	netv1alpha1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+)
+
+var errNoActivatorPort = errors.New("all the activator ports for TCP revisions are taken")
+
+// reconcileActivatorPort allocates the port the activator listens on for the
+// SKS, if it's for a revision serving raw TCP streams. The port is unique
+// across all of the SKS in the cluster. Should two SKS end up with the same
+// port, e.g. when both got it from reconcilers working off stale listers,
+// the one created first keeps it and the other one is given a new one.
+func (r *reconciler) reconcileActivatorPort(ctx context.Context, sks *netv1alpha1.ServerlessService) error {
+	if sks.Spec.ProtocolType != networking.ProtocolTCP {
+		sks.Status.ActivatorPort = 0
+		return nil
+	}
+
+	all, err := r.sksLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	taken := make(map[int32]bool, len(all))
+	keep := sks.Status.ActivatorPort != 0
+	for _, other := range all {
+		port := other.Status.ActivatorPort
+		if port == 0 || (other.Namespace == sks.Namespace && other.Name == sks.Name) {
+			continue
+		}
+		taken[port] = true
+		if port == sks.Status.ActivatorPort && createdBefore(other, sks) {
+			keep = false
+		}
+	}
+	if keep {
+		return nil
+	}
+
+	for port := int32(networking.ActivatorTCPPortMin); port <= networking.ActivatorTCPPortMax; port++ {
+		if !taken[port] {
+			logging.FromContext(ctx).Infof("Allocated activator port %d, was %d", port, sks.Status.ActivatorPort)
+			sks.Status.ActivatorPort = port
+			return nil
+		}
+	}
+	return errNoActivatorPort
+}
+
+// createdBefore orders the SKS by creation, then by key.
+func createdBefore(a, b *netv1alpha1.ServerlessService) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
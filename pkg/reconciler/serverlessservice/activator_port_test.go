@@ -0,0 +1,117 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverlessservice
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/serving/pkg/apis/networking"
+	nv1a1 "knative.dev/serving/pkg/apis/networking/v1alpha1"
+	listers "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
+	. "knative.dev/serving/pkg/testing"
+)
+
+func withCreation(t time.Time) SKSOption {
+	return func(sks *nv1a1.ServerlessService) {
+		sks.CreationTimestamp = metav1.NewTime(t)
+	}
+}
+
+func TestReconcileActivatorPort(t *testing.T) {
+	now := time.Now()
+	older, newer := withCreation(now.Add(-time.Hour)), withCreation(now)
+
+	tests := []struct {
+		name    string
+		sks     *nv1a1.ServerlessService
+		others  []*nv1a1.ServerlessService
+		want    int32
+		wantErr bool
+	}{{
+		name: "http",
+		sks:  SKS("ns", "http", withActivatorPort(networking.ActivatorTCPPortMin)),
+		want: 0,
+	}, {
+		name: "first",
+		sks:  SKS("ns", "tcp", withTCPProtocol),
+		want: networking.ActivatorTCPPortMin,
+	}, {
+		name: "keeps its port",
+		sks:  SKS("ns", "tcp", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin+5)),
+		others: []*nv1a1.ServerlessService{
+			SKS("ns", "other", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin)),
+		},
+		want: networking.ActivatorTCPPortMin + 5,
+	}, {
+		name: "skips the taken ports",
+		sks:  SKS("ns", "tcp", withTCPProtocol),
+		others: []*nv1a1.ServerlessService{
+			SKS("ns", "a", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin)),
+			SKS("ns", "b", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin+1)),
+			SKS("ns", "c", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin+3)),
+		},
+		want: networking.ActivatorTCPPortMin + 2,
+	}, {
+		name: "conflict with a newer one",
+		sks:  SKS("ns", "tcp", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin), older),
+		others: []*nv1a1.ServerlessService{
+			SKS("ns", "other", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin), newer),
+		},
+		want: networking.ActivatorTCPPortMin,
+	}, {
+		name: "conflict with an older one",
+		sks:  SKS("ns", "tcp", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin), newer),
+		others: []*nv1a1.ServerlessService{
+			SKS("ns", "other", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin), older),
+		},
+		want: networking.ActivatorTCPPortMin + 1,
+	}, {
+		name: "all taken",
+		sks:  SKS("ns", "tcp", withTCPProtocol),
+		others: func() (all []*nv1a1.ServerlessService) {
+			for p := int32(networking.ActivatorTCPPortMin); p <= networking.ActivatorTCPPortMax; p++ {
+				all = append(all, SKS("ns", strconv.Itoa(int(p)), withTCPProtocol, withActivatorPort(p)))
+			}
+			return
+		}(),
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, sks := range append(test.others, test.sks) {
+				indexer.Add(sks)
+			}
+			r := &reconciler{sksLister: listers.NewServerlessServiceLister(indexer)}
+
+			sks := test.sks.DeepCopy()
+			err := r.reconcileActivatorPort(context.Background(), sks)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("reconcileActivatorPort() = %v, wantErr %v", err, test.wantErr)
+			}
+			if !test.wantErr && sks.Status.ActivatorPort != test.want {
+				t.Errorf("ActivatorPort = %d, want %d", sks.Status.ActivatorPort, test.want)
+			}
+		})
+	}
+}
@@ -29,12 +29,30 @@ import (
 
 // targetPort chooses the target (pod) port for the public and private service.
 func targetPort(sks *v1alpha1.ServerlessService) intstr.IntOrString {
-	if sks.Spec.ProtocolType == networking.ProtocolH2C {
+	switch sks.Spec.ProtocolType {
+	case networking.ProtocolH2C:
 		return intstr.FromInt(networking.BackendHTTP2Port)
+	case networking.ProtocolTCP:
+		return intstr.FromInt(networking.BackendTCPPort)
 	}
 	return intstr.FromInt(networking.BackendHTTPPort)
 }
 
+// namedPorts returns the service ports for the ports of the pods besides the
+// one serving the requests.
+func namedPorts(sks *v1alpha1.ServerlessService) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, 0, len(sks.Spec.Ports))
+	for _, p := range sks.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       p.Port,
+			TargetPort: intstr.FromInt(int(p.Port)),
+		})
+	}
+	return ports
+}
+
 // MakePublicService constructs a K8s Service that is not backed a selector
 // and will be manually reconciled by the SKS controller.
 func MakePublicService(sks *v1alpha1.ServerlessService) *corev1.Service {
@@ -51,12 +69,12 @@ func MakePublicService(sks *v1alpha1.ServerlessService) *corev1.Service {
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(sks)},
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{{
+			Ports: append([]corev1.ServicePort{{
 				Name:       networking.ServicePortName(sks.Spec.ProtocolType),
 				Protocol:   corev1.ProtocolTCP,
 				Port:       int32(networking.ServicePort(sks.Spec.ProtocolType)),
 				TargetPort: targetPort(sks),
-			}},
+			}}, namedPorts(sks)...),
 		},
 	}
 }
@@ -85,6 +103,21 @@ func MakePublicEndpoints(sks *v1alpha1.ServerlessService, src *corev1.Endpoints)
 	}
 }
 
+// MakeActivatorTCPEndpoints constructs the endpoints of the activator for a
+// revision serving raw TCP streams: the addresses of the activators, on the
+// port they listen on for the revision instead of their HTTP ports.
+func MakeActivatorTCPEndpoints(activatorEps *corev1.Endpoints, port int32) *corev1.Endpoints {
+	eps := activatorEps.DeepCopy()
+	for i := range eps.Subsets {
+		eps.Subsets[i].Ports = []corev1.EndpointPort{{
+			Name:     networking.ServicePortNameTCP,
+			Port:     port,
+			Protocol: corev1.ProtocolTCP,
+		}}
+	}
+	return eps
+}
+
 // MakePrivateService constructs a K8s service, that is backed by the pod selector
 // matching pods created by the revision.
 func MakePrivateService(sks *v1alpha1.ServerlessService, selector map[string]string) *corev1.Service {
@@ -101,14 +134,14 @@ func MakePrivateService(sks *v1alpha1.ServerlessService, selector map[string]str
 			OwnerReferences: []metav1.OwnerReference{*kmeta.NewControllerRef(sks)},
 		},
 		Spec: corev1.ServiceSpec{
-			Ports: []corev1.ServicePort{{
+			Ports: append([]corev1.ServicePort{{
 				Name:     networking.ServicePortName(sks.Spec.ProtocolType),
 				Protocol: corev1.ProtocolTCP,
 				Port:     networking.ServiceHTTPPort,
 				// This one is matching the public one, since this is the
 				// port queue-proxy listens on.
 				TargetPort: targetPort(sks),
-			}},
+			}}, namedPorts(sks)...),
 			Selector: selector,
 		},
 	}
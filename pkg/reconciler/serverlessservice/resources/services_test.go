@@ -296,6 +296,55 @@ func TestMakePublicService(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "HTTP - named ports",
+		sks: &v1alpha1.ServerlessService{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "melon",
+				Name:      "collie",
+				UID:       "1982",
+			},
+			Spec: v1alpha1.ServerlessServiceSpec{
+				ProtocolType: networking.ProtocolHTTP1,
+				Mode:         v1alpha1.SKSOperationModeServe,
+				Ports: []networking.NamedPort{{
+					Name: "metrics",
+					Port: 9095,
+				}},
+			},
+		},
+		want: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "melon",
+				Name:      "collie",
+				Labels: map[string]string{
+					networking.SKSLabelKey:    "collie",
+					networking.ServiceTypeKey: "Public",
+				},
+				Annotations: map[string]string{},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+					Kind:               "ServerlessService",
+					Name:               "collie",
+					UID:                "1982",
+					Controller:         ptr.Bool(true),
+					BlockOwnerDeletion: ptr.Bool(true),
+				}},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{
+					Name:       networking.ServicePortNameHTTP1,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       networking.ServiceHTTPPort,
+					TargetPort: intstr.FromInt(networking.BackendHTTPPort),
+				}, {
+					Name:       "metrics",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       9095,
+					TargetPort: intstr.FromInt(9095),
+				}},
+			},
+		},
 	}}
 
 	for _, test := range tests {
@@ -445,6 +494,43 @@ func TestMakeEndpoints(t *testing.T) {
 	}
 }
 
+func TestMakeActivatorTCPEndpoints(t *testing.T) {
+	activatorEps := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "knative-serving",
+			Name:      "activator-service",
+		},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+			Ports: []corev1.EndpointPort{{
+				Name:     "http",
+				Port:     8012,
+				Protocol: "TCP",
+			}, {
+				Name:     "http2",
+				Port:     8013,
+				Protocol: "TCP",
+			}},
+		}},
+	}
+	want := []corev1.EndpointSubset{{
+		Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+		Ports: []corev1.EndpointPort{{
+			Name:     "tcp",
+			Port:     20042,
+			Protocol: "TCP",
+		}},
+	}}
+
+	got := MakeActivatorTCPEndpoints(activatorEps, 20042)
+	if diff := cmp.Diff(want, got.Subsets); diff != "" {
+		t.Errorf("Activator TCP Endpoints mismatch (-want, +got) = %v", diff)
+	}
+	if activatorEps.Subsets[0].Ports[0].Port != 8012 {
+		t.Error("MakeActivatorTCPEndpoints modified the activator endpoints")
+	}
+}
+
 func TestMakePrivateService(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -564,6 +650,68 @@ func TestMakePrivateService(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "HTTP2 - named ports",
+		sks: &v1alpha1.ServerlessService{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "siamese",
+				Name:      "dream",
+				UID:       "1988",
+			},
+			Spec: v1alpha1.ServerlessServiceSpec{
+				ProtocolType: networking.ProtocolH2C,
+				Ports: []networking.NamedPort{{
+					Name: "metrics",
+					Port: 9095,
+				}, {
+					Name: "custom",
+					Port: 7000,
+				}},
+			},
+		},
+		selector: map[string]string{
+			"app": "today",
+		},
+		want: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:    "siamese",
+				GenerateName: "dream-",
+				Labels: map[string]string{
+					networking.SKSLabelKey:    "dream",
+					networking.ServiceTypeKey: "Private",
+				},
+				Annotations: map[string]string{},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         v1alpha1.SchemeGroupVersion.String(),
+					Kind:               "ServerlessService",
+					Name:               "dream",
+					UID:                "1988",
+					Controller:         ptr.Bool(true),
+					BlockOwnerDeletion: ptr.Bool(true),
+				}},
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{
+					"app": "today",
+				},
+				Ports: []corev1.ServicePort{{
+					Name:       networking.ServicePortNameH2C,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       networking.ServiceHTTPPort,
+					TargetPort: intstr.FromInt(networking.BackendHTTP2Port),
+				}, {
+					Name:       "metrics",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       9095,
+					TargetPort: intstr.FromInt(9095),
+				}, {
+					Name:       "custom",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       7000,
+					TargetPort: intstr.FromInt(7000),
+				}},
+			},
+		},
 	}}
 
 	for _, test := range tests {
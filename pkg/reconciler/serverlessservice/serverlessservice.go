@@ -115,6 +115,7 @@ func (r *reconciler) reconcile(ctx context.Context, sks *netv1alpha1.ServerlessS
 	for i, fn := range []func(context.Context, *netv1alpha1.ServerlessService) error{
 		r.reconcilePrivateService, // First make sure our data source is setup.
 		r.reconcilePublicService,
+		r.reconcileActivatorPort,
 		r.reconcilePublicEndpoints,
 	} {
 		if err := fn(ctx, sks); err != nil {
@@ -143,6 +144,10 @@ func (r *reconciler) updateStatus(sks *netv1alpha1.ServerlessService) (*netv1alp
 func (r *reconciler) reconcilePublicService(ctx context.Context, sks *netv1alpha1.ServerlessService) error {
 	logger := logging.FromContext(ctx)
 
+	kubeClient, err := r.ChildKubeClientSet(sks.Namespace)
+	if err != nil {
+		return err
+	}
 	sn := sks.Name
 	srv, err := r.serviceLister.Services(sks.Namespace).Get(sn)
 	if apierrs.IsNotFound(err) {
@@ -150,7 +155,7 @@ func (r *reconciler) reconcilePublicService(ctx context.Context, sks *netv1alpha
 		// We've just created the service, so it has no endpoints.
 		sks.Status.MarkEndpointsNotReady("CreatingPublicService")
 		srv = resources.MakePublicService(sks)
-		_, err := r.KubeClientSet.CoreV1().Services(sks.Namespace).Create(srv)
+		_, err := kubeClient.CoreV1().Services(sks.Namespace).Create(srv)
 		if err != nil {
 			logger.Errorw(fmt.Sprint("Error creating K8s Service:", sn), zap.Error(err))
 			return err
@@ -170,7 +175,7 @@ func (r *reconciler) reconcilePublicService(ctx context.Context, sks *netv1alpha
 
 		if !equality.Semantic.DeepEqual(want.Spec, srv.Spec) {
 			logger.Info("Public K8s Service changed; reconciling: ", sn, cmp.Diff(want.Spec, srv.Spec))
-			if _, err = r.KubeClientSet.CoreV1().Services(sks.Namespace).Update(want); err != nil {
+			if _, err = kubeClient.CoreV1().Services(sks.Namespace).Update(want); err != nil {
 				logger.Errorw(fmt.Sprint("Error updating public K8s Service:", sn), zap.Error(err))
 				return err
 			}
@@ -235,6 +240,10 @@ func (r *reconciler) reconcilePublicEndpoints(ctx context.Context, sks *netv1alp
 	case netv1alpha1.SKSOperationModeProxy:
 		srcEps = activatorEps
 	}
+	if srcEps == activatorEps && sks.Spec.ProtocolType == networking.ProtocolTCP {
+		// The activator tells the TCP revisions apart by port.
+		srcEps = resources.MakeActivatorTCPEndpoints(activatorEps, sks.Status.ActivatorPort)
+	}
 
 	sn := sks.Name
 	eps, err := r.endpointsLister.Endpoints(sks.Namespace).Get(sn)
@@ -312,13 +321,17 @@ func (r *reconciler) reconcilePrivateService(ctx context.Context, sks *netv1alph
 	if err != nil {
 		return errors.Wrap(err, "error retrieving deployment selector spec")
 	}
+	kubeClient, err := r.ChildKubeClientSet(sks.Namespace)
+	if err != nil {
+		return err
+	}
 
 	svc, err := r.privateService(sks)
 	if apierrs.IsNotFound(err) {
 		logger.Infof("SKS %s has no private service; creating.", sks.Name)
 		sks.Status.MarkEndpointsNotReady("CreatingPrivateService")
 		svc = resources.MakePrivateService(sks, selector)
-		svc, err = r.KubeClientSet.CoreV1().Services(sks.Namespace).Create(svc)
+		svc, err = kubeClient.CoreV1().Services(sks.Namespace).Create(svc)
 		if err != nil {
 			logger.Errorw("Error creating private K8s Service", zap.Error(err))
 			return err
@@ -340,7 +353,7 @@ func (r *reconciler) reconcilePrivateService(ctx context.Context, sks *netv1alph
 		if !equality.Semantic.DeepEqual(svc.Spec, want.Spec) {
 			sks.Status.MarkEndpointsNotReady("UpdatingPrivateService")
 			logger.Infof("Private K8s Service changed %s; reconciling: ", svc.Name)
-			if _, err = r.KubeClientSet.CoreV1().Services(sks.Namespace).Update(want); err != nil {
+			if _, err = kubeClient.CoreV1().Services(sks.Namespace).Update(want); err != nil {
 				logger.Errorw(fmt.Sprint("Error updating private K8s Service:", svc.Name), zap.Error(err))
 				return err
 			}
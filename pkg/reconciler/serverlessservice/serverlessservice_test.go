@@ -111,6 +111,32 @@ func TestReconcile(t *testing.T) {
 		WantEvents: []string{
 			Eventf(corev1.EventTypeNormal, "Updated", `Successfully updated ServerlessService "steady/to-proxy"`),
 		},
+	}, {
+		// The TCP revisions are proxied by the activator on a port of their own.
+		Name: "tcp switch to proxy mode",
+		Key:  "steady/tcp",
+		Objects: []runtime.Object{
+			SKS("steady", "tcp", markHappy, WithPubService, WithPrivateService("tcp-deadbeef"),
+				WithDeployRef("bar"), WithProxyMode, withTCPProtocol),
+			SKS("other", "tcp", withTCPProtocol, withActivatorPort(networking.ActivatorTCPPortMin)),
+			deploy("steady", "bar"),
+			svcpub("steady", "tcp", withTCP),
+			svcpriv("steady", "tcp", svcWithName("tcp-deadbeef"), withTCPPriv),
+			endpointspub("steady", "tcp", withOtherSubsets),
+			endpointspriv("steady", "tcp", epsWithName("tcp-deadbeef")),
+			activatorEndpoints(WithSubsets),
+		},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: SKS("steady", "tcp", WithDeployRef("bar"), markNoEndpoints, withTCPProtocol,
+				WithProxyMode, WithPubService, WithPrivateService("tcp-deadbeef"),
+				withActivatorPort(networking.ActivatorTCPPortMin+1)),
+		}},
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: endpointspub("steady", "tcp", WithSubsets, withTCPSubsetPort(networking.ActivatorTCPPortMin+1)),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", `Successfully updated ServerlessService "steady/tcp"`),
+		},
 	}, {
 		// This is the case for once we are proxying for unsufficient burst capacity.
 		// It should be a no-op.
@@ -695,6 +721,28 @@ func withHTTP2Protocol(sks *nv1a1.ServerlessService) {
 	sks.Spec.ProtocolType = networking.ProtocolH2C
 }
 
+func withTCPProtocol(sks *nv1a1.ServerlessService) {
+	sks.Spec.ProtocolType = networking.ProtocolTCP
+}
+
+func withActivatorPort(port int32) SKSOption {
+	return func(sks *nv1a1.ServerlessService) {
+		sks.Status.ActivatorPort = port
+	}
+}
+
+func withTCPSubsetPort(port int32) EndpointsOption {
+	return func(ep *corev1.Endpoints) {
+		for i := range ep.Subsets {
+			ep.Subsets[i].Ports = []corev1.EndpointPort{{
+				Name:     networking.ServicePortNameTCP,
+				Port:     port,
+				Protocol: corev1.ProtocolTCP,
+			}}
+		}
+	}
+}
+
 type deploymentOption func(*appsv1.Deployment)
 
 func deploy(namespace, name string, opts ...deploymentOption) *appsv1.Deployment {
@@ -730,6 +778,17 @@ func withHTTP2(svc *corev1.Service) {
 	svc.Spec.Ports[0].TargetPort = intstr.FromInt(networking.BackendHTTP2Port)
 }
 
+func withTCPPriv(svc *corev1.Service) {
+	svc.Spec.Ports[0].Name = "tcp"
+	svc.Spec.Ports[0].TargetPort = intstr.FromInt(networking.BackendTCPPort)
+}
+
+func withTCP(svc *corev1.Service) {
+	svc.Spec.Ports[0].Port = networking.ServiceTCPPort
+	svc.Spec.Ports[0].Name = "tcp"
+	svc.Spec.Ports[0].TargetPort = intstr.FromInt(networking.BackendTCPPort)
+}
+
 func withTargetPortNum(port int) K8sServiceOption {
 	return func(svc *corev1.Service) {
 		svc.Spec.Ports[0].TargetPort = intstr.FromInt(port)
@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+)
+
+// pinnedRevision returns the Revision the traffic following the latest
+// ready Revision of config is pinned to, or "" when it isn't pinned.
+func (c *Reconciler) pinnedRevision(ctx context.Context, service *v1alpha1.Service,
+	config *v1alpha1.Configuration, existing *v1alpha1.Route) (string, error) {
+	switch service.Annotations[serving.RolloutModeAnnotationKey] {
+	case serving.RolloutModeManual:
+		// An explicit promotion on the Service wins, then whatever we pinned the
+		// Route to previously, and finally the first Revision to become Ready.
+		promoted := service.Annotations[serving.PromotedRevisionAnnotationKey]
+		if promoted == "" && existing != nil {
+			promoted = existing.Annotations[serving.PromotedRevisionAnnotationKey]
+		}
+		if promoted == "" {
+			promoted = config.Status.LatestReadyRevisionName
+		}
+		return promoted, nil
+	case serving.RolloutModeBlueGreen:
+		return c.warmRevision(ctx, service, config, existing)
+	default:
+		return "", nil
+	}
+}
+
+// warmRevision implements the blue/green rollout mode: the traffic stays on
+// the Revision the Route is pinned to while the PodAutoscaler of the latest
+// ready Revision is pre-warmed to the scale the pinned one actually serves
+// at, and all of it moves to the latest ready Revision once it got there.
+func (c *Reconciler) warmRevision(ctx context.Context, service *v1alpha1.Service,
+	config *v1alpha1.Configuration, existing *v1alpha1.Route) (string, error) {
+	logger := logging.FromContext(ctx)
+
+	latest := config.Status.LatestReadyRevisionName
+	var current string
+	if existing != nil {
+		current = existing.Annotations[serving.PromotedRevisionAnnotationKey]
+	}
+	if latest == "" {
+		return current, nil
+	}
+
+	pa, err := c.paLister.PodAutoscalers(service.Namespace).Get(latest)
+	if apierrs.IsNotFound(err) {
+		// Without a PodAutoscaler we can't tell how warm it is.
+		if current == "" {
+			return latest, nil
+		}
+		return current, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if current == "" || current == latest {
+		// Nothing to switch from, so latest no longer needs pre-warming.
+		if _, ok := pa.Annotations[autoscaling.PreWarmScaleAnnotationKey]; ok {
+			return latest, c.patchPreWarmScale(ctx, service, latest, nil)
+		}
+		return latest, nil
+	}
+
+	var want int32
+	if old, err := c.paLister.PodAutoscalers(service.Namespace).Get(current); err == nil {
+		if old.Status.ActualScale != nil {
+			want = *old.Status.ActualScale
+		}
+	} else if !apierrs.IsNotFound(err) {
+		return "", err
+	}
+	// The latest revision may not be allowed to scale as far.
+	if _, max := pa.ScaleBounds(); max > 0 && want > max {
+		want = max
+	}
+
+	var got int32
+	if pa.Status.ActualScale != nil {
+		got = *pa.Status.ActualScale
+	}
+	if got >= want {
+		logger.Infof("Switching traffic from %q to %q at scale %d", current, latest, got)
+		return latest, nil
+	}
+
+	logger.Infof("Pre-warming %q to scale %d, got %d", latest, want, got)
+	if value := strconv.Itoa(int(want)); pa.Annotations[autoscaling.PreWarmScaleAnnotationKey] != value {
+		if err := c.patchPreWarmScale(ctx, service, latest, value); err != nil {
+			return "", err
+		}
+	}
+	return current, nil
+}
+
+// patchPreWarmScale sets the pre-warm scale of the PodAutoscaler named name
+// to value, or removes it when value is nil.
+func (c *Reconciler) patchPreWarmScale(ctx context.Context, service *v1alpha1.Service,
+	name string, value interface{}) error {
+	if reconciler.IsDryRun(service) && value == nil {
+		c.RecordDryRun(ctx, service, "Would stop pre-warming PodAutoscaler %q", name)
+		return nil
+	} else if reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would pre-warm PodAutoscaler %q to scale %v", name, value)
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				autoscaling.PreWarmScaleAnnotationKey: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.ServingClientSet.AutoscalingV1alpha1().PodAutoscalers(service.Namespace).Patch(
+		name, types.MergePatchType, patch)
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"knative.dev/pkg/kmeta"
+	"knative.dev/pkg/ptr"
 	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/reconciler/service/resources/names"
@@ -54,3 +55,25 @@ func MakeRoute(service *v1alpha1.Service) (*v1alpha1.Route, error) {
 
 	return c, nil
 }
+
+// PinToRevision rewrites the traffic targets of the Route that follow the
+// latest ready Revision of the Service's Configuration so that they send
+// their traffic to the named Revision instead.  It also records the pinned
+// Revision on the Route, so that it survives until the next promotion.
+func PinToRevision(route *v1alpha1.Route, service *v1alpha1.Service, revisionName string) {
+	configName := names.Configuration(service)
+	for idx := range route.Spec.Traffic {
+		tt := &route.Spec.Traffic[idx]
+		if tt.ConfigurationName != configName || tt.RevisionName != "" {
+			continue
+		}
+		tt.ConfigurationName = ""
+		tt.RevisionName = revisionName
+		if tt.LatestRevision != nil {
+			tt.LatestRevision = ptr.Bool(false)
+		}
+	}
+	route.Annotations = resources.UnionMaps(route.Annotations, map[string]string{
+		serving.PromotedRevisionAnnotationKey: revisionName,
+	})
+}
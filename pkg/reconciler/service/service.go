@@ -18,6 +18,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"time"
@@ -28,12 +29,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/kmp"
 	"knative.dev/pkg/logging"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
 	listers "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
 	"knative.dev/serving/pkg/reconciler"
 	cfgreconciler "knative.dev/serving/pkg/reconciler/configuration"
@@ -46,6 +51,13 @@ const (
 	ReconcilerName = "Services"
 )
 
+// serviceFinalizer is the name that we put into the resource finalizer list,
+// i.e. services.serving.knative.dev.
+var (
+	serviceResource  = v1alpha1.Resource("services")
+	serviceFinalizer = serviceResource.String()
+)
+
 // Reconciler implements controller.Reconciler for Service resources.
 type Reconciler struct {
 	*reconciler.Base
@@ -55,6 +67,10 @@ type Reconciler struct {
 	configurationLister listers.ConfigurationLister
 	revisionLister      listers.RevisionLister
 	routeLister         listers.RouteLister
+	paLister            palisters.PodAutoscalerLister
+
+	// enqueueAfter enqueues a Service after the given delay.
+	enqueueAfter func(interface{}, time.Duration)
 }
 
 // Check that our Reconciler implements controller.Reconciler
@@ -83,7 +99,8 @@ func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
 	}
 
 	if original.GetDeletionTimestamp() != nil {
-		return nil
+		// Check for a DeletionTimestamp.  If present, elide the normal reconcile logic.
+		return c.reconcileDeletion(ctx, original.DeepCopy())
 	}
 
 	// Don't modify the informers copy
@@ -142,6 +159,12 @@ func (c *Reconciler) reconcile(ctx context.Context, service *v1alpha1.Service) e
 		return err
 	}
 
+	// Add the finalizer before creating the Route so that its traffic is
+	// drained before the Revisions go away.
+	if err := c.ensureFinalizer(service); err != nil {
+		return err
+	}
+
 	config, err := c.config(ctx, logger, service)
 	if err != nil {
 		return err
@@ -166,7 +189,7 @@ func (c *Reconciler) reconcile(ctx context.Context, service *v1alpha1.Service) e
 		return nil
 	}
 
-	route, err := c.route(ctx, logger, service)
+	route, err := c.route(ctx, logger, service, config)
 	if err != nil {
 		return err
 	}
@@ -188,10 +211,83 @@ func (c *Reconciler) reconcile(ctx context.Context, service *v1alpha1.Service) e
 	return nil
 }
 
+// reconcileDeletion tears down the children of service in order, so that
+// requests aren't sent to pods going away: the Route is deleted first,
+// which deletes its Ingresses, then once in-flight requests were given
+// DrainTimeoutAnnotationKey to complete, the finalizer is removed and the
+// Configuration, its Revisions and their Deployments are garbage collected.
+func (c *Reconciler) reconcileDeletion(ctx context.Context, service *v1alpha1.Service) error {
+	logger := logging.FromContext(ctx)
+
+	if len(service.Finalizers) == 0 || service.Finalizers[0] != serviceFinalizer {
+		return nil
+	}
+
+	routeName := resourcenames.Route(service)
+	route, err := c.routeLister.Routes(service.Namespace).Get(routeName)
+	if err != nil && !apierrs.IsNotFound(err) {
+		return err
+	} else if err == nil && metav1.IsControlledBy(route, service) {
+		if route.GetDeletionTimestamp() == nil {
+			logger.Infof("Deleting Route %q", routeName)
+			err := c.ServingClientSet.ServingV1alpha1().Routes(service.Namespace).Delete(routeName, &metav1.DeleteOptions{})
+			if err != nil && !apierrs.IsNotFound(err) {
+				return err
+			}
+		}
+		// We are enqueued again once the Route is gone.
+		return nil
+	}
+
+	if left := time.Until(service.DeletionTimestamp.Add(drainTimeout(service))); left > 0 {
+		logger.Infof("Draining requests for %v", left)
+		c.enqueueAfter(service, left)
+		return nil
+	}
+
+	// Update the Service to remove the Finalizer.
+	logger.Info("Removing Finalizer")
+	service.Finalizers = service.Finalizers[1:]
+	_, err = c.ServingClientSet.ServingV1alpha1().Services(service.Namespace).Update(service)
+	return err
+}
+
+// drainTimeout returns how long the requests in-flight when service was
+// deleted are given to complete.
+func drainTimeout(service *v1alpha1.Service) time.Duration {
+	// The annotation is validated by the webhook.
+	d, _ := time.ParseDuration(service.Annotations[serving.DrainTimeoutAnnotationKey])
+	return d
+}
+
+func (c *Reconciler) ensureFinalizer(service *v1alpha1.Service) error {
+	finalizers := sets.NewString(service.Finalizers...)
+	if finalizers.Has(serviceFinalizer) {
+		return nil
+	}
+	mergePatch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      append(service.Finalizers, serviceFinalizer),
+			"resourceVersion": service.ResourceVersion,
+		},
+	}
+
+	patch, err := json.Marshal(mergePatch)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ServingClientSet.ServingV1alpha1().Services(service.Namespace).Patch(service.Name, types.MergePatchType, patch)
+	return err
+}
+
 func (c *Reconciler) config(ctx context.Context, logger *zap.SugaredLogger, service *v1alpha1.Service) (*v1alpha1.Configuration, error) {
 	configName := resourcenames.Configuration(service)
 	config, err := c.configurationLister.Configurations(service.Namespace).Get(configName)
-	if apierrs.IsNotFound(err) {
+	if apierrs.IsNotFound(err) && reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would create Configuration %q", configName)
+		return resources.MakeConfiguration(service)
+	} else if apierrs.IsNotFound(err) {
 		config, err = c.createConfiguration(service)
 		if err != nil {
 			logger.Errorf("Failed to create Configuration %q: %v", configName, err)
@@ -217,11 +313,18 @@ func (c *Reconciler) config(ctx context.Context, logger *zap.SugaredLogger, serv
 	return config, nil
 }
 
-func (c *Reconciler) route(ctx context.Context, logger *zap.SugaredLogger, service *v1alpha1.Service) (*v1alpha1.Route, error) {
+func (c *Reconciler) route(ctx context.Context, logger *zap.SugaredLogger, service *v1alpha1.Service, config *v1alpha1.Configuration) (*v1alpha1.Route, error) {
 	routeName := resourcenames.Route(service)
 	route, err := c.routeLister.Routes(service.Namespace).Get(routeName)
-	if apierrs.IsNotFound(err) {
-		route, err = c.createRoute(service)
+	if apierrs.IsNotFound(err) && reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would create Route %q", routeName)
+		pinned, err := c.pinnedRevision(ctx, service, config, nil)
+		if err != nil {
+			return nil, err
+		}
+		return makeRoute(service, pinned)
+	} else if apierrs.IsNotFound(err) {
+		route, err = c.createRoute(ctx, service, config)
 		if err != nil {
 			logger.Errorf("Failed to create Route %q: %v", routeName, err)
 			c.Recorder.Eventf(service, corev1.EventTypeWarning, "CreationFailed", "Failed to create Route %q: %v", routeName, err)
@@ -235,7 +338,7 @@ func (c *Reconciler) route(ctx context.Context, logger *zap.SugaredLogger, servi
 		// Surface an error in the service's status, and return an error.
 		service.Status.MarkRouteNotOwned(routeName)
 		return nil, fmt.Errorf("service: %q does not own route: %q", service.Name, routeName)
-	} else if route, err = c.reconcileRoute(ctx, service, route); err != nil {
+	} else if route, err = c.reconcileRoute(ctx, service, config, route); err != nil {
 		logger.Errorf("Failed to reconcile Service: %q failed to reconcile Route: %q", service.Name, routeName)
 		return nil, err
 	}
@@ -312,12 +415,52 @@ func configSemanticEquals(desiredConfig, config *v1alpha1.Configuration) bool {
 		equality.Semantic.DeepEqual(desiredConfig.ObjectMeta.Annotations, config.ObjectMeta.Annotations)
 }
 
+// preserveImageDigest carries the digest the Configuration reconciler
+// stamped on the template of config when watching its image tag over to
+// desired, unless the image changed. Dropping it would change the template
+// and stamp out yet another Revision.
+func preserveImageDigest(desired, config *v1alpha1.Configuration) {
+	digest, ok := config.Spec.GetTemplate().Annotations[serving.ImageDigestAnnotationKey]
+	if !ok {
+		return
+	}
+	// The spec is shared with the Service, don't modify it.
+	desired.Spec = *desired.Spec.DeepCopy()
+	template := desired.Spec.GetTemplate()
+	if template.Spec.GetContainer().Image != config.Spec.GetTemplate().Spec.GetContainer().Image {
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string, 1)
+	}
+	template.Annotations[serving.ImageDigestAnnotationKey] = digest
+}
+
+// preserveEnvFromHash carries the hash of the environment the Configuration
+// reconciler stamped on the template of config when watching it over to
+// desired. Dropping it would stamp out yet another Revision.
+func preserveEnvFromHash(desired, config *v1alpha1.Configuration) {
+	hash, ok := config.Spec.GetTemplate().Annotations[serving.EnvFromHashAnnotationKey]
+	if !ok {
+		return
+	}
+	// The spec is shared with the Service, don't modify it.
+	desired.Spec = *desired.Spec.DeepCopy()
+	template := desired.Spec.GetTemplate()
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string, 1)
+	}
+	template.Annotations[serving.EnvFromHashAnnotationKey] = hash
+}
+
 func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *v1alpha1.Service, config *v1alpha1.Configuration) (*v1alpha1.Configuration, error) {
 	logger := logging.FromContext(ctx)
 	desiredConfig, err := resources.MakeConfiguration(service)
 	if err != nil {
 		return nil, err
 	}
+	preserveImageDigest(desiredConfig, config)
+	preserveEnvFromHash(desiredConfig, config)
 
 	if configSemanticEquals(desiredConfig, config) {
 		// No differences to reconcile.
@@ -328,6 +471,10 @@ func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *v1alph
 		return nil, fmt.Errorf("failed to diff Configuration: %v", err)
 	}
 	logger.Infof("Reconciling configuration diff (-desired, +observed): %s", diff)
+	if reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would update Configuration %q", config.Name)
+		return config, nil
+	}
 
 	// Don't modify the informers copy.
 	existing := config.DeepCopy()
@@ -338,8 +485,12 @@ func (c *Reconciler) reconcileConfiguration(ctx context.Context, service *v1alph
 	return c.ServingClientSet.ServingV1alpha1().Configurations(service.Namespace).Update(existing)
 }
 
-func (c *Reconciler) createRoute(service *v1alpha1.Service) (*v1alpha1.Route, error) {
-	route, err := resources.MakeRoute(service)
+func (c *Reconciler) createRoute(ctx context.Context, service *v1alpha1.Service, config *v1alpha1.Configuration) (*v1alpha1.Route, error) {
+	pinned, err := c.pinnedRevision(ctx, service, config, nil)
+	if err != nil {
+		return nil, err
+	}
+	route, err := makeRoute(service, pinned)
 	if err != nil {
 		// This should be unreachable as configuration creation
 		// happens first in `reconcile()` and it verifies the edge cases
@@ -349,15 +500,33 @@ func (c *Reconciler) createRoute(service *v1alpha1.Service) (*v1alpha1.Route, er
 	return c.ServingClientSet.ServingV1alpha1().Routes(service.Namespace).Create(route)
 }
 
+// makeRoute creates the desired Route for the Service.  When pinned is not
+// empty, the traffic that would follow the latest ready Revision is instead
+// pinned to that Revision, see pinnedRevision.
+func makeRoute(service *v1alpha1.Service, pinned string) (*v1alpha1.Route, error) {
+	route, err := resources.MakeRoute(service)
+	if err != nil {
+		return nil, err
+	}
+	if pinned != "" {
+		resources.PinToRevision(route, service, pinned)
+	}
+	return route, nil
+}
+
 func routeSemanticEquals(desiredRoute, route *v1alpha1.Route) bool {
 	return equality.Semantic.DeepEqual(desiredRoute.Spec, route.Spec) &&
 		equality.Semantic.DeepEqual(desiredRoute.ObjectMeta.Labels, route.ObjectMeta.Labels) &&
 		equality.Semantic.DeepEqual(desiredRoute.ObjectMeta.Annotations, route.ObjectMeta.Annotations)
 }
 
-func (c *Reconciler) reconcileRoute(ctx context.Context, service *v1alpha1.Service, route *v1alpha1.Route) (*v1alpha1.Route, error) {
+func (c *Reconciler) reconcileRoute(ctx context.Context, service *v1alpha1.Service, config *v1alpha1.Configuration, route *v1alpha1.Route) (*v1alpha1.Route, error) {
 	logger := logging.FromContext(ctx)
-	desiredRoute, err := resources.MakeRoute(service)
+	pinned, err := c.pinnedRevision(ctx, service, config, route)
+	if err != nil {
+		return nil, err
+	}
+	desiredRoute, err := makeRoute(service, pinned)
 	if err != nil {
 		// This should be unreachable as configuration creation
 		// happens first in `reconcile()` and it verifies the edge cases
@@ -374,6 +543,10 @@ func (c *Reconciler) reconcileRoute(ctx context.Context, service *v1alpha1.Servi
 		return nil, fmt.Errorf("failed to diff Route: %v", err)
 	}
 	logger.Infof("Reconciling route diff (-desired, +observed): %s", diff)
+	if reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would update Route %q", route.Name)
+		return route, nil
+	}
 
 	// Don't modify the informers copy.
 	existing := route.DeepCopy()
@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	// Install our fake informers
+	_ "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/configuration/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision/fake"
 	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/route/fake"
@@ -31,6 +33,10 @@ import (
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	logtesting "knative.dev/pkg/logging/testing"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
 	"knative.dev/serving/pkg/apis/serving/v1alpha1"
 	"knative.dev/serving/pkg/apis/serving/v1beta1"
 	"knative.dev/serving/pkg/reconciler"
@@ -40,6 +46,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgotesting "k8s.io/client-go/testing"
 
 	. "knative.dev/pkg/reconciler/testing"
@@ -62,10 +69,79 @@ func TestReconcile(t *testing.T) {
 			Service("delete-pending", "foo", WithServiceDeletionTimestamp),
 		},
 		Key: "foo/delete-pending",
+	}, {
+		Name: "deletion deletes the route first",
+		Objects: []runtime.Object{
+			Service("delete-route", "foo", WithRunLatestRollout, WithServiceFinalizer, WithServiceDeletionTimestamp),
+			config("delete-route", "foo", WithRunLatestRollout),
+			route("delete-route", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/delete-route",
+		WantDeletes: []clientgotesting.DeleteActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+				Verb:      "delete",
+				Resource: schema.GroupVersionResource{
+					Group:    "serving.knative.dev",
+					Version:  "v1alpha1",
+					Resource: "routes",
+				},
+			},
+			Name: "delete-route",
+		}},
+	}, {
+		Name: "deletion waits for the route to be gone",
+		Objects: []runtime.Object{
+			Service("route-deleting", "foo", WithRunLatestRollout, WithServiceFinalizer, WithServiceDeletionTimestamp),
+			config("route-deleting", "foo", WithRunLatestRollout),
+			route("route-deleting", "foo", WithRunLatestRollout, WithRouteDeletionTimestamp),
+		},
+		Key: "foo/route-deleting",
+	}, {
+		Name: "deletion waits for requests to drain",
+		Objects: []runtime.Object{
+			Service("draining", "foo", WithRunLatestRollout, WithServiceFinalizer,
+				WithServiceAnnotations(map[string]string{serving.DrainTimeoutAnnotationKey: "1m"}),
+				func(s *v1alpha1.Service) {
+					now := metav1.Now()
+					s.DeletionTimestamp = &now
+				}),
+			config("draining", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/draining",
+	}, {
+		Name: "deletion removes the finalizer once drained",
+		Objects: []runtime.Object{
+			Service("drained", "foo", WithRunLatestRollout, WithServiceFinalizer, WithServiceDeletionTimestamp,
+				WithServiceAnnotations(map[string]string{serving.DrainTimeoutAnnotationKey: "1m"})),
+			config("drained", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/drained",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Service("drained", "foo", WithRunLatestRollout, WithServiceDeletionTimestamp,
+				WithServiceAnnotations(map[string]string{serving.DrainTimeoutAnnotationKey: "1m"})),
+		}},
+	}, {
+		Name: "add finalizer",
+		Objects: []runtime.Object{
+			Service("add-finalizer", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			config("add-finalizer", "foo", WithRunLatestRollout),
+			route("add-finalizer", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/add-finalizer",
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "add-finalizer",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		},
+			patchFinalizers("foo", "add-finalizer"),
+		},
 	}, {
 		Name: "inline - create route and service",
 		Objects: []runtime.Object{
-			Service("run-latest", "foo", WithInlineRollout),
+			Service("run-latest", "foo", WithServiceFinalizer, WithInlineRollout),
 		},
 		Key: "foo/run-latest",
 		WantCreates: []runtime.Object{
@@ -73,7 +149,7 @@ func TestReconcile(t *testing.T) {
 			route("run-latest", "foo", WithInlineRollout),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("run-latest", "foo", WithInlineRollout,
+			Object: Service("run-latest", "foo", WithServiceFinalizer, WithInlineRollout,
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -85,7 +161,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "runLatest - create route and service",
 		Objects: []runtime.Object{
-			Service("run-latest", "foo", WithRunLatestRollout),
+			Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout),
 		},
 		Key: "foo/run-latest",
 		WantCreates: []runtime.Object{
@@ -93,7 +169,7 @@ func TestReconcile(t *testing.T) {
 			route("run-latest", "foo", WithRunLatestRollout),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("run-latest", "foo", WithRunLatestRollout,
+			Object: Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -112,7 +188,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "pinned - create route and service",
 		Objects: []runtime.Object{
-			Service("pinned", "foo", WithPinnedRollout("pinned-0001")),
+			Service("pinned", "foo", WithServiceFinalizer, WithPinnedRollout("pinned-0001")),
 		},
 		Key: "foo/pinned",
 		WantCreates: []runtime.Object{
@@ -120,7 +196,7 @@ func TestReconcile(t *testing.T) {
 			route("pinned", "foo", WithPinnedRollout("pinned-0001")),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("pinned", "foo", WithPinnedRollout("pinned-0001"),
+			Object: Service("pinned", "foo", WithServiceFinalizer, WithPinnedRollout("pinned-0001"),
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -141,7 +217,7 @@ func TestReconcile(t *testing.T) {
 		// using Release.
 		Name: "pinned - create route and service - via release",
 		Objects: []runtime.Object{
-			Service("pinned2", "foo", WithReleaseRollout("pinned2-0001")),
+			Service("pinned2", "foo", WithServiceFinalizer, WithReleaseRollout("pinned2-0001")),
 		},
 		Key: "foo/pinned2",
 		WantCreates: []runtime.Object{
@@ -149,7 +225,7 @@ func TestReconcile(t *testing.T) {
 			route("pinned2", "foo", WithReleaseRollout("pinned2-0001")),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("pinned2", "foo", WithReleaseRollout("pinned2-0001"),
+			Object: Service("pinned2", "foo", WithServiceFinalizer, WithReleaseRollout("pinned2-0001"),
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -168,7 +244,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "pinned - with ready config and route",
 		Objects: []runtime.Object{
-			Service("pinned3", "foo", WithReleaseRollout("pinned3-00001"),
+			Service("pinned3", "foo", WithServiceFinalizer, WithReleaseRollout("pinned3-00001"),
 				WithInitSvcConditions),
 			config("pinned3", "foo", WithReleaseRollout("pinned3-00001"),
 				WithGeneration(1), WithObservedGen,
@@ -194,7 +270,7 @@ func TestReconcile(t *testing.T) {
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
 			// Make sure that status contains all the required propagated fields
 			// from config and route status.
-			Object: Service("pinned3", "foo",
+			Object: Service("pinned3", "foo", WithServiceFinalizer,
 				// Initial setup conditions.
 				WithReleaseRollout("pinned3-00001"),
 				// The delta induced by configuration object.
@@ -231,7 +307,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - with @latest",
 		Objects: []runtime.Object{
-			Service("release", "foo", WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword)),
+			Service("release", "foo", WithServiceFinalizer, WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword)),
 		},
 		Key: "foo/release",
 		WantCreates: []runtime.Object{
@@ -239,7 +315,7 @@ func TestReconcile(t *testing.T) {
 			route("release", "foo", WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword)),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release", "foo", WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword),
+			Object: Service("release", "foo", WithServiceFinalizer, WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword),
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -258,7 +334,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - create route and service",
 		Objects: []runtime.Object{
-			Service("release", "foo", WithReleaseRollout("release-00001", "release-00002")),
+			Service("release", "foo", WithServiceFinalizer, WithReleaseRollout("release-00001", "release-00002")),
 		},
 		Key: "foo/release",
 		WantCreates: []runtime.Object{
@@ -266,7 +342,7 @@ func TestReconcile(t *testing.T) {
 			route("release", "foo", WithReleaseRollout("release-00001", "release-00002")),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release", "foo", WithReleaseRollout("release-00001", "release-00002"),
+			Object: Service("release", "foo", WithServiceFinalizer, WithReleaseRollout("release-00001", "release-00002"),
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -285,7 +361,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - update service, route not ready",
 		Objects: []runtime.Object{
-			Service("release-nr", "foo", WithReleaseRollout("release-nr-00002"), WithInitSvcConditions),
+			Service("release-nr", "foo", WithServiceFinalizer, WithReleaseRollout("release-nr-00002"), WithInitSvcConditions),
 			config("release-nr", "foo", WithReleaseRollout("release-nr-00002"),
 				WithCreatedAndReady("release-nr-00002", "release-nr-00002")),
 			// NB: route points to the previous revision.
@@ -300,7 +376,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-nr",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-nr", "foo",
+			Object: Service("release-nr", "foo", WithServiceFinalizer,
 				WithReleaseRollout("release-nr-00002"),
 				WithReadyConfig("release-nr-00002"),
 				WithServiceStatusRouteNotReady, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -324,7 +400,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - update service, route not ready, 2 rev, no split",
 		Objects: []runtime.Object{
-			Service("release-nr", "foo", WithReleaseRollout("release-nr-00002", "release-nr-00003"), WithInitSvcConditions),
+			Service("release-nr", "foo", WithServiceFinalizer, WithReleaseRollout("release-nr-00002", "release-nr-00003"), WithInitSvcConditions),
 			config("release-nr", "foo", WithReleaseRollout("release-nr-00002", "release-nr-00003"),
 				WithCreatedAndReady("release-nr-00003", "release-nr-00003")),
 			// NB: route points to the previous revision.
@@ -339,7 +415,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-nr",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-nr", "foo",
+			Object: Service("release-nr", "foo", WithServiceFinalizer,
 				WithReleaseRollout("release-nr-00002", "release-nr-00003"),
 				WithReadyConfig("release-nr-00003"),
 				WithServiceStatusRouteNotReady, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -363,7 +439,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - update service, route not ready, traffic split",
 		Objects: []runtime.Object{
-			Service("release-nr-ts", "foo",
+			Service("release-nr-ts", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(42, "release-nr-ts-00002", "release-nr-ts-00003"),
 				WithInitSvcConditions),
 			config("release-nr-ts", "foo",
@@ -386,7 +462,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-nr-ts",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-nr-ts", "foo",
+			Object: Service("release-nr-ts", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(42, "release-nr-ts-00002", "release-nr-ts-00003"),
 				WithReadyConfig("release-nr-ts-00003"),
 				WithServiceStatusRouteNotReady, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -415,7 +491,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - update service, route not ready, traffic split, percentage changed",
 		Objects: []runtime.Object{
-			Service("release-nr-ts2", "foo",
+			Service("release-nr-ts2", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(58, "release-nr-ts2-00002", "release-nr-ts2-00003"),
 				WithInitSvcConditions),
 			config("release-nr-ts2", "foo",
@@ -439,7 +515,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-nr-ts2",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-nr-ts2", "foo",
+			Object: Service("release-nr-ts2", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(58, "release-nr-ts2-00002", "release-nr-ts2-00003"),
 				WithReadyConfig("release-nr-ts2-00003"),
 				WithServiceStatusRouteNotReady, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -468,7 +544,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - route and config ready, using @latest",
 		Objects: []runtime.Object{
-			Service("release-ready-lr", "foo",
+			Service("release-ready-lr", "foo", WithServiceFinalizer,
 				WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword), WithInitSvcConditions),
 			route("release-ready-lr", "foo",
 				WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword),
@@ -493,7 +569,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-ready-lr",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-ready-lr", "foo",
+			Object: Service("release-ready-lr", "foo", WithServiceFinalizer,
 				WithReleaseRollout(v1alpha1.ReleaseLatestRevisionKeyword),
 				// The delta induced by the config object.
 				WithReadyConfig("release-ready-lr-00001"),
@@ -529,7 +605,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - route and config ready, traffic split, using @latest",
 		Objects: []runtime.Object{
-			Service("release-ready-lr", "foo",
+			Service("release-ready-lr", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(
 					42, "release-ready-lr-00001", v1alpha1.ReleaseLatestRevisionKeyword), WithInitSvcConditions),
 			route("release-ready-lr", "foo",
@@ -562,7 +638,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-ready-lr",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-ready-lr", "foo",
+			Object: Service("release-ready-lr", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(
 					42, "release-ready-lr-00001", v1alpha1.ReleaseLatestRevisionKeyword),
 				// The delta induced by the config object.
@@ -605,7 +681,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - route and config ready, propagate ready, percentage set",
 		Objects: []runtime.Object{
-			Service("release-ready", "foo",
+			Service("release-ready", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(58, /*candidate traffic percentage*/
 					"release-ready-00001", "release-ready-00002"), WithInitSvcConditions),
 			route("release-ready", "foo",
@@ -638,7 +714,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/release-ready",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-ready", "foo",
+			Object: Service("release-ready", "foo", WithServiceFinalizer,
 				WithReleaseRolloutAndPercentage(58, /*candidate traffic percentage*/
 					"release-ready-00001", "release-ready-00002"),
 				// The delta induced by the config object.
@@ -682,7 +758,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "release - create route and service and percentage",
 		Objects: []runtime.Object{
-			Service("release-with-percent", "foo", WithReleaseRolloutAndPercentage(10, /*candidate traffic percentage*/
+			Service("release-with-percent", "foo", WithServiceFinalizer, WithReleaseRolloutAndPercentage(10, /*candidate traffic percentage*/
 				"release-with-percent-00001", "release-with-percent-00002")),
 		},
 		Key: "foo/release-with-percent",
@@ -691,7 +767,7 @@ func TestReconcile(t *testing.T) {
 			route("release-with-percent", "foo", WithReleaseRolloutAndPercentage(10, "release-with-percent-00001", "release-with-percent-00002")),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("release-with-percent", "foo", WithReleaseRolloutAndPercentage(10, "release-with-percent-00001", "release-with-percent-00002"),
+			Object: Service("release-with-percent", "foo", WithServiceFinalizer, WithReleaseRolloutAndPercentage(10, "release-with-percent-00001", "release-with-percent-00002"),
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions),
 		}},
@@ -707,10 +783,167 @@ func TestReconcile(t *testing.T) {
 			Eventf(corev1.EventTypeNormal, "Created", "Created Route %q", "release-with-percent"),
 			Eventf(corev1.EventTypeNormal, "Updated", "Updated Service %q", "release-with-percent"),
 		},
+	}, {
+		Name: "manual rollout - pin route to first ready revision",
+		Objects: []runtime.Object{
+			Service("manual", "foo", WithServiceFinalizer, withManualRollout(""), WithInitSvcConditions),
+			config("manual", "foo", withManualRollout(""),
+				WithGeneration(1), WithObservedGen,
+				WithLatestCreated("manual-00001"), WithLatestReady("manual-00001")),
+			route("manual", "foo", withManualRollout("")),
+		},
+		Key: "foo/manual",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route("manual", "foo", withManualRollout(""), pinnedTo("manual-00001")),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Service("manual", "foo", WithServiceFinalizer, withManualRollout(""),
+				WithInitSvcConditions, WithReadyConfig("manual-00001")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Service %q", "manual"),
+		},
+	}, {
+		Name: "manual rollout - new ready revision is not promoted",
+		Objects: []runtime.Object{
+			Service("manual", "foo", WithServiceFinalizer, withManualRollout(""),
+				WithReadyConfig("manual-00002"), WithServiceStatusRouteNotReady),
+			config("manual", "foo", withManualRollout(""),
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("manual-00002"), WithLatestReady("manual-00002")),
+			route("manual", "foo", withManualRollout(""), pinnedTo("manual-00001")),
+		},
+		Key: "foo/manual",
+	}, {
+		Name: "manual rollout - promote revision",
+		Objects: []runtime.Object{
+			Service("manual", "foo", WithServiceFinalizer, withManualRollout("manual-00002"),
+				WithReadyConfig("manual-00002"), WithServiceStatusRouteNotReady),
+			config("manual", "foo", withManualRollout(""),
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("manual-00002"), WithLatestReady("manual-00002")),
+			route("manual", "foo", withManualRollout(""), pinnedTo("manual-00001")),
+		},
+		Key: "foo/manual",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: config("manual", "foo", withManualRollout("manual-00002"),
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("manual-00002"), WithLatestReady("manual-00002")),
+		}, {
+			Object: route("manual", "foo", withManualRollout("manual-00002"), pinnedTo("manual-00002")),
+		}},
+	}, {
+		Name: "blue/green rollout - pin route to first ready revision",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout, WithInitSvcConditions),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(1), WithObservedGen,
+				WithLatestCreated("bg-00001"), WithLatestReady("bg-00001")),
+			route("bg", "foo", withBlueGreenRollout),
+			pa("bg-00001", 1, ""),
+		},
+		Key: "foo/bg",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+		}},
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithInitSvcConditions, WithReadyConfig("bg-00001")),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Service %q", "bg"),
+		},
+	}, {
+		Name: "blue/green rollout - pre-warm new revision",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+			pa("bg-00001", 3, ""),
+			pa("bg-00002", 1, ""),
+		},
+		Key:         "foo/bg",
+		WantPatches: []clientgotesting.PatchActionImpl{preWarmPatch("bg-00002", `"3"`)},
+	}, {
+		Name: "blue/green rollout - new revision is still warming up",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+			pa("bg-00001", 3, ""),
+			pa("bg-00002", 2, "3"),
+		},
+		Key: "foo/bg",
+	}, {
+		Name: "blue/green rollout - switch to warm revision",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+			pa("bg-00001", 3, ""),
+			pa("bg-00002", 3, "3"),
+		},
+		Key: "foo/bg",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00002")),
+		}},
+	}, {
+		Name: "blue/green rollout - switch to revision warm up to its maxScale",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+			pa("bg-00001", 3, ""),
+			withPAMaxScale(pa("bg-00002", 2, "2"), "2"),
+		},
+		Key: "foo/bg",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00002")),
+		}},
+	}, {
+		Name: "blue/green rollout - pre-warm new revision up to its maxScale",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00001")),
+			pa("bg-00001", 3, ""),
+			withPAMaxScale(pa("bg-00002", 1, ""), "2"),
+		},
+		Key:         "foo/bg",
+		WantPatches: []clientgotesting.PatchActionImpl{preWarmPatch("bg-00002", `"2"`)},
+	}, {
+		Name: "blue/green rollout - stop pre-warming switched revision",
+		Objects: []runtime.Object{
+			Service("bg", "foo", WithServiceFinalizer, withBlueGreenRollout,
+				WithReadyConfig("bg-00002"), WithServiceStatusRouteNotReady),
+			config("bg", "foo", withBlueGreenRollout,
+				WithGeneration(2), WithObservedGen,
+				WithLatestCreated("bg-00002"), WithLatestReady("bg-00002")),
+			route("bg", "foo", withBlueGreenRollout, pinnedTo("bg-00002")),
+			pa("bg-00001", 0, ""),
+			pa("bg-00002", 3, "3"),
+		},
+		Key:         "foo/bg",
+		WantPatches: []clientgotesting.PatchActionImpl{preWarmPatch("bg-00002", "null")},
 	}, {
 		Name: "runLatest - no updates",
 		Objects: []runtime.Object{
-			Service("no-updates", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("no-updates", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("no-updates", "foo", WithRunLatestRollout),
 			config("no-updates", "foo", WithRunLatestRollout),
 		},
@@ -725,7 +958,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "runLatest - update annotations",
 		Objects: []runtime.Object{
-			Service("update-annos", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Service("update-annos", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				func(s *v1alpha1.Service) {
 					s.Annotations = presources.UnionMaps(s.Annotations,
 						map[string]string{"new-key": "new-value"})
@@ -757,7 +990,7 @@ func TestReconcile(t *testing.T) {
 	}, {
 		Name: "runLatest - delete annotations",
 		Objects: []runtime.Object{
-			Service("update-annos", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("update-annos", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			config("update-annos", "foo", WithRunLatestRollout,
 				func(s *v1alpha1.Configuration) {
 					s.Annotations = presources.UnionMaps(s.Annotations,
@@ -782,10 +1015,61 @@ func TestReconcile(t *testing.T) {
 			Name:  "update-annos",
 			Patch: []byte(reconciler.ForceUpgradePatch),
 		}},
+	}, {
+		Name: "runLatest - keep stamped image digest",
+		Objects: []runtime.Object{
+			Service("keep-digest", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
+			config("keep-digest", "foo", WithRunLatestRollout, withImageDigest("busybox@sha256:deadbeef")),
+			route("keep-digest", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/keep-digest",
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "keep-digest",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		}},
+	}, {
+		Name: "runLatest - drop stamped image digest when the image changes",
+		Objects: []runtime.Object{
+			Service("drop-digest", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
+			config("drop-digest", "foo", WithRunLatestRollout, withImageDigest("busybox@sha256:deadbeef"),
+				func(cfg *v1alpha1.Configuration) {
+					cfg.Spec.GetTemplate().Spec.GetContainer().Image = "previous-image"
+				}),
+			route("drop-digest", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/drop-digest",
+		WantUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: config("drop-digest", "foo", WithRunLatestRollout),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "drop-digest",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		}},
+	}, {
+		Name: "runLatest - keep stamped environment hash",
+		Objects: []runtime.Object{
+			Service("keep-env-hash", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
+			config("keep-env-hash", "foo", WithRunLatestRollout, withEnvFromHash("cafebabe")),
+			route("keep-env-hash", "foo", WithRunLatestRollout),
+		},
+		Key: "foo/keep-env-hash",
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "keep-env-hash",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		}},
 	}, {
 		Name: "runLatest - update route and service",
 		Objects: []runtime.Object{
-			Service("update-route-and-config", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("update-route-and-config", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			// Mutate the Config/Route to have a different body than we want.
 			config("update-route-and-config", "foo", WithRunLatestRollout,
 				// This is just an unexpected mutation of the config spec vs. the service spec.
@@ -805,10 +1089,54 @@ func TestReconcile(t *testing.T) {
 			Name:  "update-route-and-config",
 			Patch: []byte(reconciler.ForceUpgradePatch),
 		}},
+	}, {
+		Name: "runLatest - dry-run create route and config",
+		Objects: []runtime.Object{
+			Service("dry-run", "foo", WithServiceFinalizer, WithRunLatestRollout, dryRun),
+		},
+		Key: "foo/dry-run",
+		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
+			Object: Service("dry-run", "foo", WithServiceFinalizer, WithRunLatestRollout, dryRun,
+				// The first reconciliation will initialize the status conditions.
+				WithInitSvcConditions),
+		}},
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "dry-run",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would create Configuration %q", "dry-run"),
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would create Route %q", "dry-run"),
+			Eventf(corev1.EventTypeNormal, "Updated", "Updated Service %q", "dry-run"),
+		},
+	}, {
+		Name: "runLatest - dry-run update route and config",
+		Objects: []runtime.Object{
+			Service("dry-run-update", "foo", WithServiceFinalizer, WithRunLatestRollout, dryRun, WithInitSvcConditions),
+			// Mutate the Config/Route to have a different body than we want.
+			config("dry-run-update", "foo", WithRunLatestRollout,
+				WithConfigContainerConcurrency(5)),
+			route("dry-run-update", "foo", WithRunLatestRollout, MutateRoute),
+		},
+		Key: "foo/dry-run-update",
+		WantPatches: []clientgotesting.PatchActionImpl{{
+			ActionImpl: clientgotesting.ActionImpl{
+				Namespace: "foo",
+			},
+			Name:  "dry-run-update",
+			Patch: []byte(reconciler.ForceUpgradePatch),
+		}},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would update Configuration %q", "dry-run-update"),
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would update Route %q", "dry-run-update"),
+		},
 	}, {
 		Name: "runLatest - update route and service (bad existing Revision)",
 		Objects: []runtime.Object{
-			Service("update-route-and-config", "foo", WithRunLatestRollout, func(svc *v1alpha1.Service) {
+			Service("update-route-and-config", "foo", WithServiceFinalizer, WithRunLatestRollout, func(svc *v1alpha1.Service) {
 				svc.Spec.DeprecatedRunLatest.Configuration.GetTemplate().Name = "update-route-and-config-blah"
 			}, WithInitSvcConditions),
 			// Mutate the Config/Route to have a different body than we want.
@@ -832,7 +1160,7 @@ func TestReconcile(t *testing.T) {
 				}),
 		}},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("update-route-and-config", "foo", WithRunLatestRollout, func(svc *v1alpha1.Service) {
+			Object: Service("update-route-and-config", "foo", WithServiceFinalizer, WithRunLatestRollout, func(svc *v1alpha1.Service) {
 				svc.Spec.DeprecatedRunLatest.Configuration.GetTemplate().Name = "update-route-and-config-blah"
 			}, WithInitSvcConditions, func(svc *v1alpha1.Service) {
 				svc.Status.MarkRevisionNameTaken("update-route-and-config-blah")
@@ -852,7 +1180,7 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - update route and config labels",
 		Objects: []runtime.Object{
 			// Mutate the Service to add some more labels
-			Service("update-route-and-config-labels", "foo", WithRunLatestRollout, WithInitSvcConditions, WithServiceLabel("new-label", "new-value")),
+			Service("update-route-and-config-labels", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions, WithServiceLabel("new-label", "new-value")),
 			config("update-route-and-config-labels", "foo", WithRunLatestRollout),
 			route("update-route-and-config-labels", "foo", WithRunLatestRollout),
 		},
@@ -873,7 +1201,7 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - update route config labels ignoring serving.knative.dev/route",
 		Objects: []runtime.Object{
 			// Mutate the Service to add some more labels
-			Service("update-child-labels-ignore-route-label", "foo",
+			Service("update-child-labels-ignore-route-label", "foo", WithServiceFinalizer,
 				WithRunLatestRollout, WithInitSvcConditions, WithServiceLabel("new-label", "new-value")),
 			config("update-child-labels-ignore-route-label", "foo",
 				WithRunLatestRollout, WithConfigLabel("serving.knative.dev/route", "update-child-labels-ignore-route-label")),
@@ -898,7 +1226,7 @@ func TestReconcile(t *testing.T) {
 		Objects: []runtime.Object{
 			// There is no spec.{runLatest,pinned} in this Service, which triggers the error
 			// path updating Configuration.
-			Service("bad-config-update", "foo", WithInitSvcConditions, WithRunLatestRollout,
+			Service("bad-config-update", "foo", WithServiceFinalizer, WithInitSvcConditions, WithRunLatestRollout,
 				func(svc *v1alpha1.Service) {
 					svc.Spec.DeprecatedRunLatest.Configuration.GetTemplate().Spec.GetContainer().Image = "#"
 				}),
@@ -924,7 +1252,7 @@ func TestReconcile(t *testing.T) {
 			InduceFailure("create", "routes"),
 		},
 		Objects: []runtime.Object{
-			Service("create-route-failure", "foo", WithRunLatestRollout),
+			Service("create-route-failure", "foo", WithServiceFinalizer, WithRunLatestRollout),
 		},
 		Key: "foo/create-route-failure",
 		WantCreates: []runtime.Object{
@@ -932,7 +1260,7 @@ func TestReconcile(t *testing.T) {
 			route("create-route-failure", "foo", WithRunLatestRollout),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("create-route-failure", "foo", WithRunLatestRollout,
+			Object: Service("create-route-failure", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// First reconcile initializes conditions.
 				WithInitSvcConditions),
 		}},
@@ -950,7 +1278,7 @@ func TestReconcile(t *testing.T) {
 			InduceFailure("create", "configurations"),
 		},
 		Objects: []runtime.Object{
-			Service("create-config-failure", "foo", WithRunLatestRollout),
+			Service("create-config-failure", "foo", WithServiceFinalizer, WithRunLatestRollout),
 		},
 		Key: "foo/create-config-failure",
 		WantCreates: []runtime.Object{
@@ -958,7 +1286,7 @@ func TestReconcile(t *testing.T) {
 			// We don't get to creating the Route.
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("create-config-failure", "foo", WithRunLatestRollout,
+			Object: Service("create-config-failure", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// First reconcile initializes conditions.
 				WithInitSvcConditions),
 		}},
@@ -975,7 +1303,7 @@ func TestReconcile(t *testing.T) {
 			InduceFailure("update", "routes"),
 		},
 		Objects: []runtime.Object{
-			Service("update-route-failure", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("update-route-failure", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			// Mutate the Route to have an unexpected body to trigger an update.
 			route("update-route-failure", "foo", WithRunLatestRollout, MutateRoute),
 			config("update-route-failure", "foo", WithRunLatestRollout),
@@ -995,7 +1323,7 @@ func TestReconcile(t *testing.T) {
 			InduceFailure("update", "configurations"),
 		},
 		Objects: []runtime.Object{
-			Service("update-config-failure", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("update-config-failure", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("update-config-failure", "foo", WithRunLatestRollout),
 			// Mutate the Config to have an unexpected body to trigger an update.
 			config("update-config-failure", "foo", WithRunLatestRollout,
@@ -1017,7 +1345,7 @@ func TestReconcile(t *testing.T) {
 			InduceFailure("update", "services"),
 		},
 		Objects: []runtime.Object{
-			Service("run-latest", "foo", WithRunLatestRollout),
+			Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout),
 		},
 		Key: "foo/run-latest",
 		WantCreates: []runtime.Object{
@@ -1025,7 +1353,7 @@ func TestReconcile(t *testing.T) {
 			route("run-latest", "foo", WithRunLatestRollout),
 		},
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("run-latest", "foo", WithRunLatestRollout,
+			Object: Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// We attempt to update the Service to initialize its
 				// conditions, which is where we induce the failure.
 				WithInitSvcConditions),
@@ -1040,7 +1368,7 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - route and config ready, propagate ready",
 		// When both route and config are ready, the service should become ready.
 		Objects: []runtime.Object{
-			Service("all-ready", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("all-ready", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("all-ready", "foo", WithRunLatestRollout, RouteReady,
 				WithURL, WithAddress, WithInitRouteConditions,
 				WithStatusTraffic(v1alpha1.TrafficTarget{
@@ -1056,7 +1384,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/all-ready",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("all-ready", "foo", WithRunLatestRollout,
+			Object: Service("all-ready", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				WithReadyConfig("all-ready-00001"),
 				// The delta induced by route object.
 				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -1084,7 +1412,7 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - configuration lagging",
 		// When both route and config are ready, the service should become ready.
 		Objects: []runtime.Object{
-			Service("all-ready", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Service("all-ready", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				WithReadyConfig("all-ready-00001")),
 			route("all-ready", "foo", WithRunLatestRollout, RouteReady,
 				WithURL, WithAddress, WithInitRouteConditions,
@@ -1101,7 +1429,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/all-ready",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("all-ready", "foo", WithRunLatestRollout,
+			Object: Service("all-ready", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				WithReadyConfig("all-ready-00001"),
 				// The delta induced by route object.
 				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -1128,7 +1456,7 @@ func TestReconcile(t *testing.T) {
 		// When both route and config are ready, but the route points to the previous revision
 		// the service should not be ready.
 		Objects: []runtime.Object{
-			Service("config-only-ready", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("config-only-ready", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("config-only-ready", "foo", WithRunLatestRollout, RouteReady,
 				WithURL, WithAddress, WithInitRouteConditions,
 				WithStatusTraffic(v1alpha1.TrafficTarget{
@@ -1144,7 +1472,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/config-only-ready",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("config-only-ready", "foo", WithRunLatestRollout,
+			Object: Service("config-only-ready", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				WithReadyConfig("config-only-ready-00002"),
 				WithServiceStatusRouteNotReady, WithSvcStatusDomain, WithSvcStatusAddress,
 				WithSvcStatusTraffic(v1alpha1.TrafficTarget{
@@ -1170,7 +1498,7 @@ func TestReconcile(t *testing.T) {
 		// Gen 2: config update fails;
 		//    => service is still OK serving Gen 1.
 		Objects: []runtime.Object{
-			Service("config-fails", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("config-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("config-fails", "foo", WithRunLatestRollout, RouteReady,
 				WithURL, WithAddress, WithInitRouteConditions,
 				WithStatusTraffic(v1alpha1.TrafficTarget{
@@ -1185,7 +1513,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/config-fails",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("config-fails", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Object: Service("config-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
 				WithSvcStatusTraffic(v1alpha1.TrafficTarget{
 					TrafficTarget: v1beta1.TrafficTarget{
@@ -1210,14 +1538,14 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - config fails, propagate failure",
 		// When config fails, the service should fail.
 		Objects: []runtime.Object{
-			Service("config-fails", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("config-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("config-fails", "foo", WithRunLatestRollout, RouteReady),
 			config("config-fails", "foo", WithRunLatestRollout, WithGeneration(1), WithObservedGen,
 				WithLatestCreated("config-fails-00001"), MarkLatestCreatedFailed("blah")),
 		},
 		Key: "foo/config-fails",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("config-fails", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Object: Service("config-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				WithServiceStatusRouteNotReady, WithFailedConfig(
 					"config-fails-00001", "RevisionFailed", "blah")),
 		}},
@@ -1235,7 +1563,7 @@ func TestReconcile(t *testing.T) {
 		Name: "runLatest - route fails, propagate failure",
 		// When route fails, the service should fail.
 		Objects: []runtime.Object{
-			Service("route-fails", "foo", WithRunLatestRollout, WithInitSvcConditions),
+			Service("route-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions),
 			route("route-fails", "foo", WithRunLatestRollout,
 				RouteFailed("Propagate me, please", "")),
 			config("route-fails", "foo", WithRunLatestRollout, WithGeneration(1), WithObservedGen,
@@ -1244,7 +1572,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/route-fails",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("route-fails", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Object: Service("route-fails", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				// When the Configuration is Ready, and the Route has failed,
 				// we expect the following changed to our status conditions.
 				WithReadyConfig("route-fails-00001"),
@@ -1264,12 +1592,12 @@ func TestReconcile(t *testing.T) {
 		Name:    "runLatest - not owned config exists",
 		WantErr: true,
 		Objects: []runtime.Object{
-			Service("run-latest", "foo", WithRunLatestRollout),
+			Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout),
 			config("run-latest", "foo", WithRunLatestRollout, WithConfigOwnersRemoved),
 		},
 		Key: "foo/run-latest",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("run-latest", "foo", WithRunLatestRollout,
+			Object: Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions, MarkConfigurationNotOwned),
 		}},
@@ -1280,13 +1608,13 @@ func TestReconcile(t *testing.T) {
 		Name:    "runLatest - not owned route exists",
 		WantErr: true,
 		Objects: []runtime.Object{
-			Service("run-latest", "foo", WithRunLatestRollout),
+			Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout),
 			config("run-latest", "foo", WithRunLatestRollout),
 			route("run-latest", "foo", WithRunLatestRollout, WithRouteOwnersRemoved),
 		},
 		Key: "foo/run-latest",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("run-latest", "foo", WithRunLatestRollout,
+			Object: Service("run-latest", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				// The first reconciliation will initialize the status conditions.
 				WithInitSvcConditions, MarkRouteNotOwned),
 		}},
@@ -1298,7 +1626,7 @@ func TestReconcile(t *testing.T) {
 		// If ready Route/Configuration that weren't owned have OwnerReferences attached,
 		// then a Reconcile will result in the Service becoming happy.
 		Objects: []runtime.Object{
-			Service("new-owner", "foo", WithRunLatestRollout, WithInitSvcConditions,
+			Service("new-owner", "foo", WithServiceFinalizer, WithRunLatestRollout, WithInitSvcConditions,
 				// This service was unhappy with the prior owner situation.
 				MarkConfigurationNotOwned, MarkRouteNotOwned),
 			// The service owns these, which should result in a happy result.
@@ -1316,7 +1644,7 @@ func TestReconcile(t *testing.T) {
 		},
 		Key: "foo/new-owner",
 		WantStatusUpdates: []clientgotesting.UpdateActionImpl{{
-			Object: Service("new-owner", "foo", WithRunLatestRollout,
+			Object: Service("new-owner", "foo", WithServiceFinalizer, WithRunLatestRollout,
 				WithReadyConfig("new-owner-00001"),
 				// The delta induced by route object.
 				WithReadyRoute, WithSvcStatusDomain, WithSvcStatusAddress,
@@ -1350,6 +1678,8 @@ func TestReconcile(t *testing.T) {
 			configurationLister: listers.GetConfigurationLister(),
 			revisionLister:      listers.GetRevisionLister(),
 			routeLister:         listers.GetRouteLister(),
+			paLister:            listers.GetPodAutoscalerLister(),
+			enqueueAfter:        func(interface{}, time.Duration) {},
 		}
 	}))
 }
@@ -1378,6 +1708,41 @@ func config(name, namespace string, so ServiceOption, co ...ConfigOption) *v1alp
 	return cfg
 }
 
+// withImageDigest stamps digest on the template of the Configuration the way
+// the Configuration reconciler does when watching its image tag.
+func withImageDigest(digest string) ConfigOption {
+	return func(cfg *v1alpha1.Configuration) {
+		template := cfg.Spec.GetTemplate()
+		template.Annotations = presources.UnionMaps(template.Annotations,
+			map[string]string{serving.ImageDigestAnnotationKey: digest})
+	}
+}
+
+// withEnvFromHash stamps hash on the template of the Configuration the way
+// the Configuration reconciler does when rolling out a changed environment.
+func withEnvFromHash(hash string) ConfigOption {
+	return func(cfg *v1alpha1.Configuration) {
+		template := cfg.Spec.GetTemplate()
+		template.Annotations = presources.UnionMaps(template.Annotations,
+			map[string]string{serving.EnvFromHashAnnotationKey: hash})
+	}
+}
+
+func patchFinalizers(namespace, name string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = namespace
+	patch := `{"metadata":{"finalizers":["services.serving.knative.dev"],"resourceVersion":""}}`
+	action.Patch = []byte(patch)
+	return action
+}
+
+// dryRun has the Service preview the changes to its Configuration and Route.
+func dryRun(s *v1alpha1.Service) {
+	s.Annotations = presources.UnionMaps(s.Annotations,
+		map[string]string{serving.DryRunAnnotationKey: "true"})
+}
+
 func route(name, namespace string, so ServiceOption, ro ...RouteOption) *v1alpha1.Route {
 	s := Service(name, namespace, so)
 	s.SetDefaults(v1beta1.WithUpgradeViaDefaulting(context.Background()))
@@ -1391,6 +1756,69 @@ func route(name, namespace string, so ServiceOption, ro ...RouteOption) *v1alpha
 	return route
 }
 
+func withManualRollout(promoted string) ServiceOption {
+	return func(s *v1alpha1.Service) {
+		WithInlineRollout(s)
+		s.Annotations = presources.UnionMaps(s.Annotations, map[string]string{
+			serving.RolloutModeAnnotationKey: serving.RolloutModeManual,
+		})
+		if promoted != "" {
+			s.Annotations[serving.PromotedRevisionAnnotationKey] = promoted
+		}
+	}
+}
+
+func withBlueGreenRollout(s *v1alpha1.Service) {
+	WithInlineRollout(s)
+	s.Annotations = presources.UnionMaps(s.Annotations, map[string]string{
+		serving.RolloutModeAnnotationKey: serving.RolloutModeBlueGreen,
+	})
+}
+
+// pa returns the PodAutoscaler of the Revision name, running actualScale
+// pods and pre-warmed to preWarmScale unless it is empty.
+func pa(name string, actualScale int32, preWarmScale string) *av1alpha1.PodAutoscaler {
+	pa := &av1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foo",
+			Name:      name,
+		},
+		Status: av1alpha1.PodAutoscalerStatus{
+			ActualScale: ptr.Int32(actualScale),
+		},
+	}
+	if preWarmScale != "" {
+		pa.Annotations = map[string]string{
+			autoscaling.PreWarmScaleAnnotationKey: preWarmScale,
+		}
+	}
+	return pa
+}
+
+// withPAMaxScale sets the maxScale annotation of pa to max.
+func withPAMaxScale(pa *av1alpha1.PodAutoscaler, max string) *av1alpha1.PodAutoscaler {
+	if pa.Annotations == nil {
+		pa.Annotations = make(map[string]string, 1)
+	}
+	pa.Annotations[autoscaling.MaxScaleAnnotationKey] = max
+	return pa
+}
+
+func preWarmPatch(name, value string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = "foo"
+	action.Patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%s}}}`,
+		autoscaling.PreWarmScaleAnnotationKey, value))
+	return action
+}
+
+func pinnedTo(revisionName string) RouteOption {
+	return func(r *v1alpha1.Route) {
+		resources.PinToRevision(r, Service(r.Name, r.Namespace), revisionName)
+	}
+}
+
 // TODO(mattmoor): Replace these when we refactor Route's table_test.go
 func MutateRoute(rt *v1alpha1.Route) {
 	rt.Spec = v1alpha1.RouteSpec{}
@@ -0,0 +1,138 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
+)
+
+const (
+	// StatusInterval is the minimum interval between two status updates of
+	// a resource that are not urgent.
+	StatusInterval = 5 * time.Second
+
+	// ResyncJitter is the window over which the resyncs of an informer are
+	// spread by JitterResyncs.
+	ResyncJitter = time.Minute
+)
+
+// StatusLimiter batches the status updates of resources. A status update
+// that is not urgent is delayed until StatusInterval has passed since the
+// previous update of the resource, so that the changes in between are
+// written at once.
+type StatusLimiter struct {
+	clock system.Clock
+
+	m       sync.Mutex
+	updated map[string]time.Time
+	swept   time.Time
+}
+
+// NewStatusLimiter creates a StatusLimiter.
+func NewStatusLimiter(clock system.Clock) *StatusLimiter {
+	return &StatusLimiter{
+		clock:   clock,
+		updated: make(map[string]time.Time),
+	}
+}
+
+// Delay returns how long the status update of the resource with key should
+// be delayed. When it is zero the update is recorded as made now. A nil
+// StatusLimiter never delays.
+func (l *StatusLimiter) Delay(key string, urgent bool) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	now := l.clock.Now()
+	if !urgent {
+		if left := l.updated[key].Add(StatusInterval).Sub(now); left > 0 {
+			return left
+		}
+	}
+	l.updated[key] = now
+
+	// Forget the resources whose updates can no longer delay others.
+	if now.Sub(l.swept) >= StatusInterval {
+		for k, t := range l.updated {
+			if now.Sub(t) >= StatusInterval {
+				delete(l.updated, k)
+			}
+		}
+		l.swept = now
+	}
+	return 0
+}
+
+// UrgentStatusChange reports whether the change of status from before to
+// after should be written right away, i.e. whether it changes the observed
+// generation, or the set, the statuses or the reasons of the conditions.
+// Changes of the messages or the severities can wait.
+func UrgentStatusChange(before, after *duckv1beta1.Status) bool {
+	if before.ObservedGeneration != after.ObservedGeneration ||
+		len(before.Conditions) != len(after.Conditions) {
+		return true
+	}
+	conds := make(map[apis.ConditionType]apis.Condition, len(before.Conditions))
+	for _, c := range before.Conditions {
+		conds[c.Type] = c
+	}
+	for _, c := range after.Conditions {
+		b, ok := conds[c.Type]
+		if !ok || b.Status != c.Status || b.Reason != c.Reason {
+			return true
+		}
+	}
+	return false
+}
+
+// JitterResyncs returns a handler enqueueing the resources with impl, which
+// spreads the resyncs of the informer, i.e. the updates that do not change
+// the resource, over window instead of enqueueing them all at once.
+func JitterResyncs(impl *controller.Impl, window time.Duration) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: impl.Enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if window > 0 && isResync(oldObj, newObj) {
+				impl.EnqueueAfter(newObj, time.Duration(rand.Int63n(int64(window))))
+				return
+			}
+			impl.Enqueue(newObj)
+		},
+		DeleteFunc: impl.Enqueue,
+	}
+}
+
+func isResync(oldObj, newObj interface{}) bool {
+	o, ok := oldObj.(metav1.Object)
+	if !ok {
+		return false
+	}
+	n, ok := newObj.(metav1.Object)
+	return ok && o.GetResourceVersion() != "" && o.GetResourceVersion() == n.GetResourceVersion()
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestStatusLimiter(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := NewStatusLimiter(clock)
+
+	if got := l.Delay("ns/a", false); got != 0 {
+		t.Errorf("first Delay = %v, want 0", got)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if got, want := l.Delay("ns/a", false), StatusInterval-time.Second; got != want {
+		t.Errorf("Delay = %v, want %v", got, want)
+	}
+	if got := l.Delay("ns/b", false); got != 0 {
+		t.Errorf("Delay of another key = %v, want 0", got)
+	}
+	if got := l.Delay("ns/a", true); got != 0 {
+		t.Errorf("urgent Delay = %v, want 0", got)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if got, want := l.Delay("ns/a", false), StatusInterval-time.Second; got != want {
+		t.Errorf("Delay after urgent = %v, want %v", got, want)
+	}
+	clock.now = clock.now.Add(StatusInterval)
+	if got := l.Delay("ns/a", false); got != 0 {
+		t.Errorf("Delay after StatusInterval = %v, want 0", got)
+	}
+	if got, want := len(l.updated), 1; got != want {
+		t.Errorf("len(updated) = %d, want %d", got, want)
+	}
+
+	var nilLimiter *StatusLimiter
+	if got := nilLimiter.Delay("ns/a", false); got != 0 {
+		t.Errorf("nil Delay = %v, want 0", got)
+	}
+}
+
+func TestUrgentStatusChange(t *testing.T) {
+	status := func(gen int64, conds ...apis.Condition) *duckv1beta1.Status {
+		return &duckv1beta1.Status{ObservedGeneration: gen, Conditions: conds}
+	}
+	ready := apis.Condition{Type: apis.ConditionReady, Status: corev1.ConditionTrue}
+	active := apis.Condition{Type: "Active", Status: corev1.ConditionUnknown, Reason: "Queued", Message: "1 pod pending"}
+	activeMessage := active
+	activeMessage.Message = "2 pods pending"
+	activeReason := active
+	activeReason.Reason = "Activating"
+	activeStatus := active
+	activeStatus.Status = corev1.ConditionTrue
+
+	tests := []struct {
+		name          string
+		before, after *duckv1beta1.Status
+		want          bool
+	}{{
+		name:   "same",
+		before: status(1, ready, active),
+		after:  status(1, ready, active),
+	}, {
+		name:   "message",
+		before: status(1, ready, active),
+		after:  status(1, ready, activeMessage),
+	}, {
+		name:   "generation",
+		before: status(1, ready),
+		after:  status(2, ready),
+		want:   true,
+	}, {
+		name:   "condition added",
+		before: status(1, ready),
+		after:  status(1, ready, active),
+		want:   true,
+	}, {
+		name:   "condition replaced",
+		before: status(1, ready),
+		after:  status(1, active),
+		want:   true,
+	}, {
+		name:   "reason",
+		before: status(1, ready, active),
+		after:  status(1, ready, activeReason),
+		want:   true,
+	}, {
+		name:   "status",
+		before: status(1, ready, active),
+		after:  status(1, ready, activeStatus),
+		want:   true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := UrgentStatusChange(test.before, test.after); got != test.want {
+				t.Errorf("UrgentStatusChange() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsResync(t *testing.T) {
+	withRV := func(rv string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: rv}}
+	}
+	tests := []struct {
+		name     string
+		old, new interface{}
+		want     bool
+	}{{
+		name: "resync",
+		old:  withRV("1"),
+		new:  withRV("1"),
+		want: true,
+	}, {
+		name: "update",
+		old:  withRV("1"),
+		new:  withRV("2"),
+	}, {
+		name: "no resource version",
+		old:  withRV(""),
+		new:  withRV(""),
+	}, {
+		name: "not an object",
+		old:  "1",
+		new:  "1",
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isResync(test.old, test.new); got != test.want {
+				t.Errorf("isResync() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SuspensionConfigName is the name of config map for the suspension of
+	// idle Services.
+	SuspensionConfigName = "config-suspension"
+)
+
+// Suspension holds the policy suspending the Services that went without
+// requests for a while.
+type Suspension struct {
+	// IdleTimeout is how long a Service goes without requests before it is
+	// suspended. Zero disables suspension.
+	IdleTimeout time.Duration
+	// RespondNotFound has the activator respond to the requests of
+	// suspended Services with a 404, instead of scaling them up again.
+	RespondNotFound bool
+	// NotFoundPage is the HTML page sent with those 404s.
+	NotFoundPage string
+}
+
+// Enabled returns whether Services are suspended at all.
+func (s *Suspension) Enabled() bool {
+	return s.IdleTimeout > 0
+}
+
+// NewSuspensionFromConfigMap creates a Suspension from the supplied ConfigMap.
+func NewSuspensionFromConfigMap(configMap *corev1.ConfigMap) (*Suspension, error) {
+	s := &Suspension{
+		NotFoundPage: configMap.Data["not-found-page"],
+	}
+
+	if raw, ok := configMap.Data["idle-timeout"]; ok {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse idle-timeout: %v", err)
+		} else if d < 0 {
+			return nil, fmt.Errorf("idle-timeout must not be negative, was %v", d)
+		}
+		s.IdleTimeout = d
+	}
+
+	if raw, ok := configMap.Data["respond-not-found"]; ok {
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse respond-not-found: %v", err)
+		}
+		s.RespondNotFound = b
+	}
+
+	return s, nil
+}
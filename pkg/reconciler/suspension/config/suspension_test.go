@@ -0,0 +1,109 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	logtesting "knative.dev/pkg/logging/testing"
+
+	. "knative.dev/pkg/configmap/testing"
+)
+
+func TestOurSuspension(t *testing.T) {
+	actual, example := ConfigMapsFromTestFile(t, SuspensionConfigName)
+	for _, tt := range []struct {
+		name string
+		fail bool
+		want *Suspension
+		data *corev1.ConfigMap
+	}{{
+		name: "actual config",
+		want: &Suspension{},
+		data: actual,
+	}, {
+		name: "example config",
+		want: &Suspension{},
+		data: example,
+	}, {
+		name: "with value overrides",
+		want: &Suspension{
+			IdleTimeout:     168 * time.Hour,
+			RespondNotFound: true,
+			NotFoundPage:    "<h1>Suspended</h1>",
+		},
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"idle-timeout":      "168h",
+				"respond-not-found": "true",
+				"not-found-page":    "<h1>Suspended</h1>",
+			},
+		},
+	}, {
+		name: "invalid idle timeout",
+		fail: true,
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"idle-timeout": "a week",
+			},
+		},
+	}, {
+		name: "negative idle timeout",
+		fail: true,
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"idle-timeout": "-1h",
+			},
+		},
+	}, {
+		name: "invalid respond not found",
+		fail: true,
+		data: &corev1.ConfigMap{
+			Data: map[string]string{
+				"respond-not-found": "sure",
+			},
+		},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSuspensionFromConfigMap(tt.data)
+			if (err != nil) != tt.fail {
+				t.Fatalf("NewSuspensionFromConfigMap() = %v, want failure: %v", err, tt.fail)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("NewSuspensionFromConfigMap() = %v, want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreLoadWithContext(t *testing.T) {
+	defer logtesting.ClearAll()
+	store := NewStore(logtesting.TestLogger(t))
+
+	suspensionConfig := ConfigMapFromTestFile(t, SuspensionConfigName)
+	store.OnConfigChanged(suspensionConfig)
+
+	config := FromContext(store.ToContext(context.Background()))
+	expected, _ := NewSuspensionFromConfigMap(suspensionConfig)
+	if diff := cmp.Diff(expected, config.Suspension); diff != "" {
+		t.Errorf("Unexpected suspension config (-want, +got): %v", diff)
+	}
+}
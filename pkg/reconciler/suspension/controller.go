@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suspension
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	painformer "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler"
+	revisioninformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision"
+	kserviceinformer "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/service"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/reconciler"
+	"knative.dev/serving/pkg/reconciler/suspension/config"
+)
+
+const (
+	controllerAgentName = "suspension-controller"
+)
+
+// NewController initializes the controller and is called by the generated code
+// Registers eventhandlers to enqueue events
+func NewController(
+	ctx context.Context,
+	cmw configmap.Watcher,
+) *controller.Impl {
+	serviceInformer := kserviceinformer.Get(ctx)
+	revisionInformer := revisioninformer.Get(ctx)
+	paInformer := painformer.Get(ctx)
+
+	c := &Reconciler{
+		Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+		serviceLister:       serviceInformer.Lister(),
+		podAutoscalerLister: paInformer.Lister(),
+		revisionIndexer:     revisionInformer.Informer().GetIndexer(),
+		clock:               system.RealClock{},
+	}
+	if err := reconciler.AddIndexers(revisionInformer.Informer(), reconciler.ServiceIndex); err != nil {
+		c.Logger.Fatalw("Failed to index the Revisions", zap.Error(err))
+	}
+	impl := controller.NewImpl(c, c.Logger, "Suspensions")
+	c.enqueueAfter = impl.EnqueueAfter
+
+	c.Logger.Info("Setting up event handlers")
+	serviceInformer.Informer().AddEventHandler(controller.HandleAll(impl.Enqueue))
+
+	// The Revisions of a Service report how long they are idle.
+	revisionInformer.Informer().AddEventHandler(controller.HandleAll(
+		impl.EnqueueLabelOfNamespaceScopedResource("", serving.ServiceLabelKey)))
+
+	c.Logger.Info("Setting up ConfigMap receivers")
+	resync := func(string, interface{}) {
+		impl.GlobalResync(serviceInformer.Informer())
+	}
+	configStore := config.NewStore(c.Logger.Named("config-store"), resync)
+	configStore.WatchConfigs(c.ConfigMapWatcher)
+	c.configStore = configStore
+
+	return impl
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suspension
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
+	listers "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
+	resourcenames "knative.dev/serving/pkg/reconciler/revision/resources/names"
+	"knative.dev/serving/pkg/reconciler/suspension/config"
+)
+
+// Reconciler implements controller.Reconciler for Service resources.
+type Reconciler struct {
+	*reconciler.Base
+
+	// listers index properties about resources
+	serviceLister       listers.ServiceLister
+	podAutoscalerLister palisters.PodAutoscalerLister
+
+	// revisionIndexer looks the Revisions up by their Service.
+	revisionIndexer cache.Indexer
+
+	configStore reconciler.ConfigStore
+	clock       system.Clock
+
+	// enqueueAfter enqueues a Service after the given delay.
+	enqueueAfter func(interface{}, time.Duration)
+}
+
+// Check that our Reconciler implements controller.Reconciler
+var _ controller.Reconciler = (*Reconciler)(nil)
+
+// Reconcile suspends the Service with this key once the Revisions it routes
+// traffic to went without requests for the idle timeout, and mirrors its
+// suspension onto all of its Revisions and their PodAutoscalers.
+func (c *Reconciler) Reconcile(ctx context.Context, key string) error {
+	// Convert the namespace/name string into a distinct namespace and name
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		c.Logger.Errorf("invalid resource key: %s", key)
+		return nil
+	}
+	ctx = c.configStore.ToContext(ctx)
+
+	service, err := c.serviceLister.Services(namespace).Get(name)
+	if apierrs.IsNotFound(err) {
+		// The Revisions of the Service go away with it.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if service.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	objs, err := c.revisionIndexer.ByIndex(reconciler.ServiceIndex, reconciler.IndexKey(namespace, service.Name))
+	if err != nil {
+		return err
+	}
+	revs := make([]*v1alpha1.Revision, 0, len(objs))
+	for _, obj := range objs {
+		revs = append(revs, obj.(*v1alpha1.Revision))
+	}
+
+	switch {
+	case isSuspended(service):
+		return c.markRevisions(revs, true)
+	case anySuspended(revs):
+		return c.resume(ctx, service, revs)
+	default:
+		return c.reconcileIdle(ctx, service, revs)
+	}
+}
+
+// reconcileIdle suspends service once it is idle for the idle timeout.
+func (c *Reconciler) reconcileIdle(ctx context.Context, service *v1alpha1.Service, revs []*v1alpha1.Revision) error {
+	logger := logging.FromContext(ctx)
+	cfg := config.FromContext(ctx).Suspension
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	since, ok := idleSince(service, revs)
+	if !ok {
+		// We are enqueued again when its Revisions become idle.
+		return nil
+	}
+	if left := since.Add(cfg.IdleTimeout).Sub(c.clock.Now()); left > 0 {
+		c.enqueueAfter(service, left)
+		return nil
+	}
+
+	if reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would suspend Service %q, idle since %v", service.Name, since)
+		return nil
+	}
+	logger.Infof("Suspending Service %q, idle since %v", service.Name, since)
+	if err := c.annotateService(service, serving.SuspendedAnnotationKey, "true"); err != nil {
+		return err
+	}
+	c.Recorder.Eventf(service, corev1.EventTypeNormal, "Suspended",
+		"Suspended Service %q, idle since %v", service.Name, since)
+	return c.markRevisions(revs, true)
+}
+
+// resume records that the owner of service resumed it, and lifts the
+// suspension of its Revisions.
+func (c *Reconciler) resume(ctx context.Context, service *v1alpha1.Service, revs []*v1alpha1.Revision) error {
+	logger := logging.FromContext(ctx)
+	if reconciler.IsDryRun(service) {
+		c.RecordDryRun(ctx, service, "Would resume Service %q", service.Name)
+		return nil
+	}
+
+	logger.Infof("Resuming Service %q", service.Name)
+	now := c.clock.Now().UTC().Format(time.RFC3339)
+	if err := c.annotateService(service, serving.LastResumedAnnotationKey, now); err != nil {
+		return err
+	}
+	if err := c.markRevisions(revs, false); err != nil {
+		return err
+	}
+	c.Recorder.Eventf(service, corev1.EventTypeNormal, "Resumed", "Resumed Service %q", service.Name)
+	return nil
+}
+
+// markRevisions mirrors the suspension of a Service onto its Revisions and
+// their PodAutoscalers.
+func (c *Reconciler) markRevisions(revs []*v1alpha1.Revision, suspended bool) error {
+	patch, err := suspendedPatch(suspended)
+	if err != nil {
+		return err
+	}
+	for _, rev := range revs {
+		if isSuspended(rev) != suspended {
+			if _, err := c.ServingClientSet.ServingV1alpha1().Revisions(rev.Namespace).Patch(
+				rev.Name, types.MergePatchType, patch); err != nil {
+				return err
+			}
+		}
+
+		pa, err := c.podAutoscalerLister.PodAutoscalers(rev.Namespace).Get(resourcenames.PA(rev))
+		if apierrs.IsNotFound(err) {
+			// The PodAutoscaler is created from the Revision, with its annotations.
+			continue
+		} else if err != nil {
+			return err
+		}
+		if isSuspended(pa) != suspended {
+			if _, err := c.ServingClientSet.AutoscalingV1alpha1().PodAutoscalers(pa.Namespace).Patch(
+				pa.Name, types.MergePatchType, patch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Reconciler) annotateService(service *v1alpha1.Service, key, value string) error {
+	patch, err := annotationPatch(key, value)
+	if err != nil {
+		return err
+	}
+	_, err = c.ServingClientSet.ServingV1alpha1().Services(service.Namespace).Patch(
+		service.Name, types.MergePatchType, patch)
+	return err
+}
+
+// idleSince returns the time all of the Revisions service routes traffic to
+// are idle since, or since service was last resumed if that is later, and
+// whether they are idle at all.
+func idleSince(service *v1alpha1.Service, revs []*v1alpha1.Revision) (time.Time, bool) {
+	byName := make(map[string]*v1alpha1.Revision, len(revs))
+	for _, rev := range revs {
+		byName[rev.Name] = rev
+	}
+
+	// The annotation is only written by us.
+	since, _ := time.Parse(time.RFC3339, service.Annotations[serving.LastResumedAnnotationKey])
+	routed := false
+	for _, tt := range service.Status.Traffic {
+		if tt.Percent == 0 {
+			continue
+		}
+		rev, ok := byName[tt.RevisionName]
+		if !ok || rev.Status.IdleSince == nil {
+			return time.Time{}, false
+		}
+		routed = true
+		if rev.Status.IdleSince.After(since) {
+			since = rev.Status.IdleSince.Time
+		}
+	}
+	return since, routed
+}
+
+type annotated interface {
+	GetAnnotations() map[string]string
+}
+
+func isSuspended(obj annotated) bool {
+	return obj.GetAnnotations()[serving.SuspendedAnnotationKey] == "true"
+}
+
+func anySuspended(revs []*v1alpha1.Revision) bool {
+	for _, rev := range revs {
+		if isSuspended(rev) {
+			return true
+		}
+	}
+	return false
+}
+
+// suspendedPatch returns the merge patch setting the suspension annotation,
+// or removing it.
+func suspendedPatch(suspended bool) ([]byte, error) {
+	if suspended {
+		return annotationPatch(serving.SuspendedAnnotationKey, "true")
+	}
+	return annotationPatch(serving.SuspendedAnnotationKey, nil)
+}
+
+func annotationPatch(key string, value interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				key: value,
+			},
+		},
+	})
+}
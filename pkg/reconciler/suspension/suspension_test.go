@@ -0,0 +1,315 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suspension
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	// Inject the fake informers that this controller needs.
+	_ "knative.dev/serving/pkg/client/injection/informers/autoscaling/v1alpha1/podautoscaler/fake"
+	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/revision/fake"
+	_ "knative.dev/serving/pkg/client/injection/informers/serving/v1alpha1/service/fake"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	logtesting "knative.dev/pkg/logging/testing"
+	av1alpha1 "knative.dev/serving/pkg/apis/autoscaling/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	"knative.dev/serving/pkg/reconciler"
+	"knative.dev/serving/pkg/reconciler/suspension/config"
+
+	. "knative.dev/pkg/reconciler/testing"
+	. "knative.dev/serving/pkg/reconciler/testing/v1alpha1"
+	. "knative.dev/serving/pkg/testing/v1alpha1"
+)
+
+var (
+	idleTime    = time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)
+	idleTimeout = 24 * time.Hour
+	longIdle    = idleTime.Add(idleTimeout)
+	shortIdle   = idleTime.Add(time.Hour)
+
+	suspended = map[string]string{
+		serving.SuspendedAnnotationKey: "true",
+	}
+)
+
+func TestReconcile(t *testing.T) {
+	table := TableTest{{
+		Name: "bad workqueue key",
+		// Make sure Reconcile handles bad keys.
+		Key: "too/many/parts",
+	}, {
+		Name: "key not found",
+		// Make sure Reconcile handles good keys that don't exist.
+		Key: "foo/not-found",
+	}, {
+		Name: "active revision",
+		Objects: []runtime.Object{
+			svc("active"),
+			rev("active", nil, nil),
+			pa("active", nil),
+		},
+		Key: "default/active",
+	}, {
+		Name: "idle for less than the timeout",
+		Objects: []runtime.Object{
+			svc("short"),
+			rev("short", nil, &shortIdle),
+			pa("short", nil),
+		},
+		Key: "default/short",
+	}, {
+		Name: "no traffic",
+		Objects: []runtime.Object{
+			Service("no-traffic", "default"),
+			rev("no-traffic", nil, &idleTime),
+			pa("no-traffic", nil),
+		},
+		Key: "default/no-traffic",
+	}, {
+		Name: "idle for the timeout",
+		Objects: []runtime.Object{
+			svc("idle"),
+			rev("idle", nil, &idleTime),
+			pa("idle", nil),
+		},
+		Key: "default/idle",
+		WantPatches: []clientgotesting.PatchActionImpl{
+			suspendPatch("idle", true),
+			suspendPatch("idle-00001", true),
+			suspendPatch("idle-00001", true),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Suspended", "Suspended Service %q, idle since %v", "idle", idleTime),
+		},
+	}, {
+		Name: "idle for the timeout, in dry-run",
+		Objects: []runtime.Object{
+			svc("dry-run", WithServiceAnnotations(map[string]string{
+				serving.DryRunAnnotationKey: "true",
+			})),
+			rev("dry-run", nil, &idleTime),
+			pa("dry-run", nil),
+		},
+		Key: "default/dry-run",
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "DryRun", "Would suspend Service %q, idle since %v", "dry-run", idleTime),
+		},
+	}, {
+		Name:    "failure suspending",
+		WantErr: true,
+		WithReactors: []clientgotesting.ReactionFunc{
+			InduceFailure("patch", "services"),
+		},
+		Objects: []runtime.Object{
+			svc("failure"),
+			rev("failure", nil, &idleTime),
+			pa("failure", nil),
+		},
+		Key: "default/failure",
+		WantPatches: []clientgotesting.PatchActionImpl{
+			suspendPatch("failure", true),
+		},
+	}, {
+		Name: "recently resumed",
+		Objects: []runtime.Object{
+			svc("resumed", WithServiceAnnotations(map[string]string{
+				serving.LastResumedAnnotationKey: shortIdle.Format(time.RFC3339),
+			})),
+			rev("resumed", nil, &idleTime),
+			pa("resumed", nil),
+		},
+		Key: "default/resumed",
+	}, {
+		Name: "suspended",
+		Objects: []runtime.Object{
+			svc("suspended", WithServiceAnnotations(suspended)),
+			rev("suspended", suspended, &idleTime),
+			pa("suspended", nil),
+		},
+		Key: "default/suspended",
+		WantPatches: []clientgotesting.PatchActionImpl{
+			suspendPatch("suspended-00001", true),
+		},
+	}, {
+		Name: "suspended, steady state",
+		Objects: []runtime.Object{
+			svc("steady", WithServiceAnnotations(suspended)),
+			rev("steady", suspended, &idleTime),
+			pa("steady", suspended),
+		},
+		Key: "default/steady",
+	}, {
+		Name: "resumed by the owner",
+		Objects: []runtime.Object{
+			svc("resume"),
+			rev("resume", suspended, &idleTime),
+			pa("resume", suspended),
+		},
+		Key: "default/resume",
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patch("resume", serving.LastResumedAnnotationKey, longIdle.Format(time.RFC3339)),
+			suspendPatch("resume-00001", false),
+			suspendPatch("resume-00001", false),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Resumed", "Resumed Service %q", "resume"),
+		},
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(newReconciler(&config.Suspension{IdleTimeout: idleTimeout})))
+}
+
+func TestReconcileDisabled(t *testing.T) {
+	table := TableTest{{
+		Name: "idle for the timeout",
+		Objects: []runtime.Object{
+			svc("idle"),
+			rev("idle", nil, &idleTime),
+			pa("idle", nil),
+		},
+		Key: "default/idle",
+	}, {
+		Name: "resumed by the owner",
+		Objects: []runtime.Object{
+			svc("resume"),
+			rev("resume", suspended, &idleTime),
+			pa("resume", suspended),
+		},
+		Key: "default/resume",
+		WantPatches: []clientgotesting.PatchActionImpl{
+			patch("resume", serving.LastResumedAnnotationKey, longIdle.Format(time.RFC3339)),
+			suspendPatch("resume-00001", false),
+			suspendPatch("resume-00001", false),
+		},
+		WantEvents: []string{
+			Eventf(corev1.EventTypeNormal, "Resumed", "Resumed Service %q", "resume"),
+		},
+	}}
+
+	defer logtesting.ClearAll()
+	table.Test(t, MakeFactory(newReconciler(&config.Suspension{})))
+}
+
+func TestNew(t *testing.T) {
+	defer logtesting.ClearAll()
+	ctx, _ := SetupFakeContext(t)
+
+	c := NewController(ctx, configmap.NewStaticWatcher(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: config.SuspensionConfigName,
+		},
+	}))
+
+	if c == nil {
+		t.Fatal("Expected NewController to return a non-nil value")
+	}
+}
+
+func newReconciler(cfg *config.Suspension) Ctor {
+	return func(ctx context.Context, listers *Listers, cmw configmap.Watcher) controller.Reconciler {
+		return &Reconciler{
+			Base:                reconciler.NewBase(ctx, controllerAgentName, cmw),
+			serviceLister:       listers.GetServiceLister(),
+			podAutoscalerLister: listers.GetPodAutoscalerLister(),
+			revisionIndexer:     listers.GetIndexer(&v1alpha1.Revision{}),
+			configStore: &testConfigStore{
+				config: &config.Config{Suspension: cfg},
+			},
+			clock:        FakeClock{Time: longIdle},
+			enqueueAfter: func(interface{}, time.Duration) {},
+		}
+	}
+}
+
+type testConfigStore struct {
+	config *config.Config
+}
+
+func (t *testConfigStore) ToContext(ctx context.Context) context.Context {
+	return config.ToContext(ctx, t.config)
+}
+
+var _ reconciler.ConfigStore = (*testConfigStore)(nil)
+
+func svc(name string, so ...ServiceOption) *v1alpha1.Service {
+	so = append(so, WithSvcStatusTraffic(v1alpha1.TrafficTarget{
+		TrafficTarget: v1beta1.TrafficTarget{
+			RevisionName: name + "-00001",
+			Percent:      100,
+		},
+	}))
+	return Service(name, "default", so...)
+}
+
+func rev(service string, annotations map[string]string, idle *time.Time) *v1alpha1.Revision {
+	r := &v1alpha1.Revision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        service + "-00001",
+			Annotations: annotations,
+			Labels: map[string]string{
+				serving.ServiceLabelKey: service,
+			},
+		},
+	}
+	if idle != nil {
+		r.Status.IdleSince = &metav1.Time{Time: *idle}
+	}
+	return r
+}
+
+func pa(service string, annotations map[string]string) *av1alpha1.PodAutoscaler {
+	return &av1alpha1.PodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        service + "-00001",
+			Annotations: annotations,
+		},
+	}
+}
+
+func suspendPatch(name string, suspended bool) clientgotesting.PatchActionImpl {
+	if suspended {
+		return patch(name, serving.SuspendedAnnotationKey, "true")
+	}
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = "default"
+	action.Patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, serving.SuspendedAnnotationKey))
+	return action
+}
+
+func patch(name, key, value string) clientgotesting.PatchActionImpl {
+	action := clientgotesting.PatchActionImpl{}
+	action.Name = name
+	action.Namespace = "default"
+	action.Patch = []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, key, value))
+	return action
+}
@@ -42,6 +42,7 @@ import (
 	palisters "knative.dev/serving/pkg/client/listers/autoscaling/v1alpha1"
 	networkinglisters "knative.dev/serving/pkg/client/listers/networking/v1alpha1"
 	servinglisters "knative.dev/serving/pkg/client/listers/serving/v1alpha1"
+	"knative.dev/serving/pkg/reconciler"
 )
 
 var clientSetSchemes = []func(*runtime.Scheme) error{
@@ -87,6 +88,20 @@ func (l *Listers) IndexerFor(obj runtime.Object) cache.Indexer {
 	return l.sorter.IndexerForObjectType(obj)
 }
 
+// GetIndexer returns an indexer of the objects of the type of obj, which
+// has the indexers of the reconcilers, unlike that of IndexerFor.
+func (l *Listers) GetIndexer(obj runtime.Object) cache.Indexer {
+	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	for name, index := range reconciler.Indexers {
+		indexers[name] = index
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, indexers)
+	for _, o := range l.IndexerFor(obj).List() {
+		indexer.Add(o)
+	}
+	return indexer
+}
+
 func (l *Listers) GetKubeObjects() []runtime.Object {
 	return l.sorter.ObjectsForSchemeFunc(fakekubeclientset.AddToScheme)
 }
@@ -0,0 +1,60 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clientchecker looks up the references of templates with the API
+// server.
+package clientchecker
+
+import (
+	"fmt"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/serving/pkg/refcheck"
+)
+
+type checker struct {
+	kubeClient kubernetes.Interface
+}
+
+var _ refcheck.Checker = (*checker)(nil)
+
+// New returns a refcheck.Checker getting the resources referred to with
+// kubeClient. The webhook has no informers, and references are only checked
+// when templates change.
+func New(kubeClient kubernetes.Interface) refcheck.Checker {
+	return &checker{kubeClient: kubeClient}
+}
+
+// Exists implements refcheck.Checker
+func (c *checker) Exists(namespace, kind, name string) (bool, error) {
+	var err error
+	switch kind {
+	case refcheck.Secret:
+		_, err = c.kubeClient.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	case refcheck.ConfigMap:
+		_, err = c.kubeClient.CoreV1().ConfigMaps(namespace).Get(name, metav1.GetOptions{})
+	case refcheck.ServiceAccount:
+		_, err = c.kubeClient.CoreV1().ServiceAccounts(namespace).Get(name, metav1.GetOptions{})
+	default:
+		return false, fmt.Errorf("unsupported kind %q", kind)
+	}
+	if apierrs.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
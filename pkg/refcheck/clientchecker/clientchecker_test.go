@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clientchecker
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"knative.dev/serving/pkg/refcheck"
+)
+
+func TestChecker(t *testing.T) {
+	meta := func(ns, name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: ns, Name: name}
+	}
+	c := New(kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: meta("default", "secret")},
+		&corev1.ConfigMap{ObjectMeta: meta("default", "config")},
+		&corev1.ServiceAccount{ObjectMeta: meta("default", "builder")},
+		&corev1.Secret{ObjectMeta: meta("other", "elsewhere")},
+	))
+
+	for _, test := range []struct {
+		kind, name string
+		want       bool
+	}{
+		{refcheck.Secret, "secret", true},
+		{refcheck.ConfigMap, "config", true},
+		{refcheck.ServiceAccount, "builder", true},
+		{refcheck.Secret, "config", false},
+		{refcheck.Secret, "elsewhere", false},
+	} {
+		if got, err := c.Exists("default", test.kind, test.name); err != nil || got != test.want {
+			t.Errorf("Exists(%s, %s) = %v, %v, want: %v", test.kind, test.name, got, err, test.want)
+		}
+	}
+	if _, err := c.Exists("default", "Pod", "pod"); err == nil {
+		t.Error("Exists(Pod) = nil, want an error")
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package refcheck checks that the Secrets, ConfigMaps and ServiceAccounts
+// the template of a Service or Configuration refers to exist before it is
+// admitted, rather than have its Revision fail to start.
+package refcheck
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+// The kinds of the resources referred to.
+const (
+	Secret         = "Secret"
+	ConfigMap      = "ConfigMap"
+	ServiceAccount = "ServiceAccount"
+)
+
+// Checker looks up the resources referred to. It is the extension point of
+// the webhook for where they are read from.
+type Checker interface {
+	// Exists returns whether the resource of kind named name exists in
+	// namespace.
+	Exists(namespace, kind, name string) (bool, error)
+}
+
+type checkerKey struct{}
+
+// WithChecker attaches c to ctx, for the resources validated with the
+// returned context to have their references checked.
+func WithChecker(ctx context.Context, c Checker) context.Context {
+	return context.WithValue(ctx, checkerKey{}, c)
+}
+
+// GetChecker returns the Checker attached to ctx, or nil.
+func GetChecker(ctx context.Context) Checker {
+	c, _ := ctx.Value(checkerKey{}).(Checker)
+	return c
+}
+
+// reference is a resource referred to by the field at path.
+type reference struct {
+	kind string
+	name string
+	path string
+}
+
+// Validate checks that the resources spec, the pod spec of a template in
+// namespace, refers to exist. Optional references aren't checked, nor is
+// anything when no Checker is attached to ctx or reference validation is
+// disabled.
+func Validate(ctx context.Context, namespace string, spec *corev1.PodSpec) *apis.FieldError {
+	checker := GetChecker(ctx)
+	if checker == nil || config.FromContextOrDefaults(ctx).Features.ReferenceValidation != config.Enabled {
+		return nil
+	}
+
+	var errs *apis.FieldError
+	exists := make(map[string]bool)
+	for _, ref := range references(spec) {
+		key := ref.kind + "/" + ref.name
+		ok, seen := exists[key]
+		if !seen {
+			var err error
+			if ok, err = checker.Exists(namespace, ref.kind, ref.name); err != nil {
+				return &apis.FieldError{
+					Message: fmt.Sprintf("Failed to check that %s %q exists", ref.kind, ref.name),
+					Paths:   []string{ref.path},
+					Details: err.Error(),
+				}
+			}
+			exists[key] = ok
+		}
+		if !ok {
+			errs = errs.Also(&apis.FieldError{
+				Message: fmt.Sprintf("%s %q does not exist in namespace %q", ref.kind, ref.name, namespace),
+				Paths:   []string{ref.path},
+			})
+		}
+	}
+	return errs
+}
+
+// references returns the resources spec refers to, but for the optional ones.
+func references(spec *corev1.PodSpec) []reference {
+	var refs []reference
+	add := func(kind, name string, optional *bool, path string, args ...interface{}) {
+		if name != "" && (optional == nil || !*optional) {
+			refs = append(refs, reference{kind: kind, name: name, path: fmt.Sprintf(path, args...)})
+		}
+	}
+
+	// The default ServiceAccount is created along with its namespace.
+	if spec.ServiceAccountName != "default" {
+		add(ServiceAccount, spec.ServiceAccountName, nil, "serviceAccountName")
+	}
+	for i, ips := range spec.ImagePullSecrets {
+		add(Secret, ips.Name, nil, "imagePullSecrets[%d].name", i)
+	}
+	for i, v := range spec.Volumes {
+		switch {
+		case v.Secret != nil:
+			add(Secret, v.Secret.SecretName, v.Secret.Optional, "volumes[%d].secret.secretName", i)
+		case v.ConfigMap != nil:
+			add(ConfigMap, v.ConfigMap.Name, v.ConfigMap.Optional, "volumes[%d].configMap.name", i)
+		case v.Projected != nil:
+			for j, s := range v.Projected.Sources {
+				switch {
+				case s.Secret != nil:
+					add(Secret, s.Secret.Name, s.Secret.Optional, "volumes[%d].projected.sources[%d].secret.name", i, j)
+				case s.ConfigMap != nil:
+					add(ConfigMap, s.ConfigMap.Name, s.ConfigMap.Optional, "volumes[%d].projected.sources[%d].configMap.name", i, j)
+				}
+			}
+		}
+	}
+	for i, c := range spec.Containers {
+		for j, ef := range c.EnvFrom {
+			switch {
+			case ef.SecretRef != nil:
+				add(Secret, ef.SecretRef.Name, ef.SecretRef.Optional, "containers[%d].envFrom[%d].secretRef.name", i, j)
+			case ef.ConfigMapRef != nil:
+				add(ConfigMap, ef.ConfigMapRef.Name, ef.ConfigMapRef.Optional, "containers[%d].envFrom[%d].configMapRef.name", i, j)
+			}
+		}
+		for j, ev := range c.Env {
+			switch {
+			case ev.ValueFrom == nil:
+			case ev.ValueFrom.SecretKeyRef != nil:
+				add(Secret, ev.ValueFrom.SecretKeyRef.Name, ev.ValueFrom.SecretKeyRef.Optional,
+					"containers[%d].env[%d].valueFrom.secretKeyRef.name", i, j)
+			case ev.ValueFrom.ConfigMapKeyRef != nil:
+				add(ConfigMap, ev.ValueFrom.ConfigMapKeyRef.Name, ev.ValueFrom.ConfigMapKeyRef.Optional,
+					"containers[%d].env[%d].valueFrom.configMapKeyRef.name", i, j)
+			}
+		}
+	}
+	return refs
+}
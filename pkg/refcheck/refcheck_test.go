@@ -0,0 +1,194 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package refcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+	"knative.dev/pkg/ptr"
+	"knative.dev/serving/pkg/apis/config"
+)
+
+type fakeChecker struct {
+	existing sets.String
+	err      error
+	lookups  int
+}
+
+func (fc *fakeChecker) Exists(_, kind, name string) (bool, error) {
+	fc.lookups++
+	return fc.existing.Has(kind + "/" + name), fc.err
+}
+
+func refContext(checker Checker, flag config.Flag) context.Context {
+	ctx := config.ToContext(context.Background(), &config.Config{
+		Features: &config.Features{ReferenceValidation: flag},
+	})
+	if checker != nil {
+		ctx = WithChecker(ctx, checker)
+	}
+	return ctx
+}
+
+func TestReferences(t *testing.T) {
+	spec := &corev1.PodSpec{
+		ServiceAccountName: "builder",
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "pull"}},
+		Volumes: []corev1.Volume{{
+			Name: "secret",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "certs"},
+			},
+		}, {
+			Name: "optional",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "extra"},
+					Optional:             ptr.Bool(true),
+				},
+			},
+		}, {
+			Name: "projected",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ConfigMap: &corev1.ConfigMapProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "files"},
+						},
+					}},
+				},
+			},
+		}},
+		Containers: []corev1.Container{{
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+				},
+			}},
+			Env: []corev1.EnvVar{{
+				Name:  "PLAIN",
+				Value: "value",
+			}, {
+				Name: "TOKEN",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "token"},
+						Key:                  "token",
+					},
+				},
+			}},
+		}},
+	}
+
+	want := []reference{
+		{kind: ServiceAccount, name: "builder", path: "serviceAccountName"},
+		{kind: Secret, name: "pull", path: "imagePullSecrets[0].name"},
+		{kind: Secret, name: "certs", path: "volumes[0].secret.secretName"},
+		{kind: ConfigMap, name: "files", path: "volumes[2].projected.sources[0].configMap.name"},
+		{kind: ConfigMap, name: "env", path: "containers[0].envFrom[0].configMapRef.name"},
+		{kind: Secret, name: "token", path: "containers[0].env[1].valueFrom.secretKeyRef.name"},
+	}
+	if got := references(spec); !cmp.Equal(got, want, cmp.AllowUnexported(reference{})) {
+		t.Errorf("references (-want, +got) = %s", cmp.Diff(want, got, cmp.AllowUnexported(reference{})))
+	}
+
+	spec.ServiceAccountName = "default"
+	if got := references(spec); got[0].kind == ServiceAccount {
+		t.Errorf("references() = %v, want the default ServiceAccount skipped", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	spec := &corev1.PodSpec{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull"}},
+		Containers: []corev1.Container{{
+			EnvFrom: []corev1.EnvFromSource{{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "pull"},
+				},
+			}, {
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "env"},
+				},
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		checker *fakeChecker
+		flag    config.Flag
+		want    *apis.FieldError
+		lookups int
+	}{{
+		name: "no checker",
+		flag: config.Enabled,
+	}, {
+		name:    "disabled",
+		checker: &fakeChecker{},
+		flag:    config.Disabled,
+	}, {
+		name:    "all exist",
+		checker: &fakeChecker{existing: sets.NewString("Secret/pull", "ConfigMap/env")},
+		flag:    config.Enabled,
+		lookups: 2,
+	}, {
+		name:    "missing",
+		checker: &fakeChecker{existing: sets.NewString("ConfigMap/env")},
+		flag:    config.Enabled,
+		want: (&apis.FieldError{
+			Message: `Secret "pull" does not exist in namespace "default"`,
+			Paths:   []string{"imagePullSecrets[0].name"},
+		}).Also(&apis.FieldError{
+			Message: `Secret "pull" does not exist in namespace "default"`,
+			Paths:   []string{"containers[0].envFrom[0].secretRef.name"},
+		}),
+		lookups: 2,
+	}, {
+		name:    "lookup fails",
+		checker: &fakeChecker{err: errors.New("connection refused")},
+		flag:    config.Enabled,
+		want: &apis.FieldError{
+			Message: `Failed to check that Secret "pull" exists`,
+			Paths:   []string{"imagePullSecrets[0].name"},
+			Details: "connection refused",
+		},
+		lookups: 1,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var checker Checker
+			if test.checker != nil {
+				checker = test.checker
+			}
+			got := Validate(refContext(checker, test.flag), "default", spec)
+			if !cmp.Equal(test.want.Error(), got.Error()) {
+				t.Errorf("Validate() (-want, +got) = %v", cmp.Diff(test.want.Error(), got.Error()))
+			}
+			if test.checker != nil && test.checker.lookups != test.lookups {
+				t.Errorf("Lookups = %d, want: %d", test.checker.lookups, test.lookups)
+			}
+		})
+	}
+}
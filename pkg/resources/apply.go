@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"knative.dev/serving/pkg/apis/serving"
+)
+
+// ApplySpec computes into applied the spec a resource owned by a reconciler
+// should be updated to, given have, its current spec, and desired, the spec
+// the reconciler wants.  Like `kubectl apply`, it merges desired onto have
+// using the spec last applied to obj: the fields desired doesn't set keep
+// their value, e.g. those set by users, other controllers or defaulted by
+// the API server, and the fields last applied but no longer desired are
+// removed.  Lists are merged by their patch merge keys, e.g. containers by
+// name, and replaced when they have none.
+func ApplySpec(obj metav1.Object, have, desired, applied interface{}) error {
+	schema, err := strategicpatch.NewPatchMetaFromStruct(desired)
+	if err != nil {
+		return err
+	}
+	current, err := json.Marshal(have)
+	if err != nil {
+		return err
+	}
+	modified, err := json.Marshal(desired)
+	if err != nil {
+		return err
+	}
+	// Without a last applied spec, e.g. for resources created before it was
+	// recorded, nothing is removed.
+	var original []byte
+	if last, ok := obj.GetAnnotations()[serving.LastAppliedSpecAnnotationKey]; ok {
+		original = []byte(last)
+	}
+
+	patch, err := strategicpatch.CreateThreeWayMergePatch(original, modified, current, schema, true /*overwrite*/)
+	if err != nil {
+		return err
+	}
+	merged, err := strategicpatch.StrategicMergePatchUsingLookupPatchMeta(current, patch, schema)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, applied)
+}
+
+// SetLastAppliedSpec records spec as the spec last applied to obj, for the
+// next ApplySpec.
+func SetLastAppliedSpec(obj metav1.Object, spec interface{}) error {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	obj.SetAnnotations(UnionMaps(obj.GetAnnotations(), map[string]string{
+		serving.LastAppliedSpecAnnotationKey: string(b),
+	}))
+	return nil
+}
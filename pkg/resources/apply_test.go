@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplySpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied *corev1.PodSpec
+		have    corev1.PodSpec
+		desired corev1.PodSpec
+		want    corev1.PodSpec
+	}{{
+		name: "nothing applied yet",
+		have: corev1.PodSpec{
+			Containers:    []corev1.Container{{Name: "user", Image: "busybox"}},
+			RestartPolicy: corev1.RestartPolicyAlways,
+		},
+		desired: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "ubuntu"}},
+		},
+		want: corev1.PodSpec{
+			Containers:    []corev1.Container{{Name: "user", Image: "ubuntu"}},
+			RestartPolicy: corev1.RestartPolicyAlways,
+		},
+	}, {
+		name: "keep the fields we don't own",
+		applied: &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+		},
+		have: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+			Tolerations: []corev1.Toleration{{
+				Key:    "dedicated",
+				Effect: corev1.TaintEffectNoSchedule,
+			}},
+		},
+		desired: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+		},
+		want: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+			Tolerations: []corev1.Toleration{{
+				Key:    "dedicated",
+				Effect: corev1.TaintEffectNoSchedule,
+			}},
+		},
+	}, {
+		name: "remove the fields we no longer set",
+		applied: &corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user",
+				Image: "busybox",
+				Env:   []corev1.EnvVar{{Name: "STALE", Value: "yes"}},
+			}},
+			ServiceAccountName: "builder",
+		},
+		have: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "user",
+				Image: "busybox",
+				Env:   []corev1.EnvVar{{Name: "STALE", Value: "yes"}},
+			}},
+			ServiceAccountName: "builder",
+			RestartPolicy:      corev1.RestartPolicyAlways,
+		},
+		desired: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+		},
+		want: corev1.PodSpec{
+			Containers:    []corev1.Container{{Name: "user", Image: "busybox"}},
+			RestartPolicy: corev1.RestartPolicyAlways,
+		},
+	}, {
+		name: "merge containers by name",
+		applied: &corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}},
+		},
+		have: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "busybox"}, {Name: "sidecar", Image: "envoy"}},
+		},
+		desired: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "ubuntu"}},
+		},
+		want: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "user", Image: "ubuntu"}, {Name: "sidecar", Image: "envoy"}},
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{}
+			if test.applied != nil {
+				if err := SetLastAppliedSpec(obj, test.applied); err != nil {
+					t.Fatalf("SetLastAppliedSpec() = %v", err)
+				}
+			}
+
+			var got corev1.PodSpec
+			if err := ApplySpec(obj, test.have, test.desired, &got); err != nil {
+				t.Fatalf("ApplySpec() = %v", err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ApplySpec (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
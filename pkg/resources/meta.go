@@ -52,3 +52,13 @@ func FilterMap(in map[string]string, filter func(string) bool) map[string]string
 	}
 	return ret
 }
+
+// ContainsAll returns whether every key of b is in a, with the same value.
+func ContainsAll(a, b map[string]string) bool {
+	for k, v := range b {
+		if av, ok := a[k]; !ok || av != v {
+			return false
+		}
+	}
+	return true
+}
@@ -136,3 +136,39 @@ func TestCopy(t *testing.T) {
 		})
 	}
 }
+
+func TestContainsAll(t *testing.T) {
+	tests := []struct {
+		name string
+		a    map[string]string
+		b    map[string]string
+		want bool
+	}{{
+		name: "nil all",
+		want: true,
+	}, {
+		name: "nil b",
+		a:    map[string]string{"comfortably": "numb"},
+		want: true,
+	}, {
+		name: "nil a",
+		b:    map[string]string{"comfortably": "numb"},
+	}, {
+		name: "subset",
+		a:    map[string]string{"comfortably": "numb", "time": "money"},
+		b:    map[string]string{"comfortably": "numb"},
+		want: true,
+	}, {
+		name: "different value",
+		a:    map[string]string{"comfortably": "numb"},
+		b:    map[string]string{"comfortably": "awake"},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ContainsAll(test.a, test.b); got != test.want {
+				t.Errorf("ContainsAll() = %v, want: %v", got, test.want)
+			}
+		})
+	}
+}
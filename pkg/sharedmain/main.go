@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharedmain is the shared main of knative.dev/pkg for injected
+// controllers, serving the health of the controllers on health.Port.
+package sharedmain
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/injection/clients/kubeclient"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
+	"knative.dev/serving/pkg/health"
+)
+
+// Main runs the controllers ctors construct as component.
+func Main(component string, ctors ...injection.ControllerConstructor) {
+	// Set up signals so we handle the first shutdown signal gracefully.
+	MainWithContext(signals.NewContext(), component, ctors...)
+}
+
+// MainWithContext runs the controllers ctors construct as component, until
+// ctx is done.
+func MainWithContext(ctx context.Context, component string, ctors ...injection.ControllerConstructor) {
+	var (
+		masterURL  = flag.String("master", "", "The address of the Kubernetes API server. Overrides any value in kubeconfig. Only required if out-of-cluster.")
+		kubeconfig = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	)
+	flag.Parse()
+
+	cfg, err := clientcmd.BuildConfigFromFlags(*masterURL, *kubeconfig)
+	if err != nil {
+		log.Fatal("Error building kubeconfig", err)
+	}
+	MainWithConfig(ctx, component, cfg, ctors...)
+}
+
+// MainWithConfig runs the controllers ctors construct as component with
+// cfg, until ctx is done. The controllers are ready once their informers
+// are synced and their ConfigMaps loaded.
+func MainWithConfig(ctx context.Context, component string, cfg *rest.Config, ctors ...injection.ControllerConstructor) {
+	// Set up our logger.
+	loggingConfigMap, err := configmap.Load("/etc/config-logging")
+	if err != nil {
+		log.Fatal("Error loading logging configuration:", err)
+	}
+	loggingConfig, err := logging.NewConfigFromMap(loggingConfigMap)
+	if err != nil {
+		log.Fatal("Error parsing logging configuration:", err)
+	}
+	logger, atomicLevel := logging.NewLoggerFromConfig(loggingConfig, component)
+	defer flush(logger)
+	ctx = logging.WithLogger(ctx, logger)
+
+	logger.Infof("Registering %d clients", len(injection.Default.GetClients()))
+	logger.Infof("Registering %d informer factories", len(injection.Default.GetInformerFactories()))
+	logger.Infof("Registering %d informers", len(injection.Default.GetInformers()))
+	logger.Infof("Registering %d controllers", len(ctors))
+
+	// Adjust our client's rate limits based on the number of controller's we are running.
+	cfg.QPS = float32(len(ctors)) * rest.DefaultQPS
+	cfg.Burst = len(ctors) * rest.DefaultBurst
+
+	ctx, informers := injection.Default.SetupInformers(ctx, cfg)
+
+	cmw := health.NewConfigMapWatcher(configmap.NewInformedWatcher(kubeclient.Get(ctx), system.Namespace()))
+
+	// Serve the health of the controllers while they start.
+	healthHandler := health.NewHandler()
+	healthHandler.AddCheck("informers", health.InformersSynced(informers...))
+	healthHandler.AddCheck("configmaps", cmw.Check)
+	go func() {
+		if err := health.ListenAndServe(ctx.Done(), healthHandler); err != nil {
+			logger.Fatalw("Failed to serve the health of the controllers", zap.Error(err))
+		}
+	}()
+
+	// Based on the reconcilers we have linked, build up the set of controllers to run.
+	controllers := make([]*controller.Impl, 0, len(ctors))
+	for _, cf := range ctors {
+		controllers = append(controllers, cf(ctx, cmw))
+	}
+
+	// Watch the logging config map and dynamically update logging levels.
+	cmw.Watch(logging.ConfigMapName(), logging.UpdateLevelFromConfigMap(logger, atomicLevel, component))
+	// Watch the observability config map and dynamically update metrics exporter.
+	cmw.Watch(metrics.ConfigMapName(), metrics.UpdateExporterFromConfigMap(component, logger))
+	if err := cmw.Start(ctx.Done()); err != nil {
+		logger.Fatalw("failed to start configuration manager", zap.Error(err))
+	}
+
+	// Start all of the informers and wait for them to sync.
+	logger.Info("Starting informers.")
+	if err := controller.StartInformers(ctx.Done(), informers...); err != nil {
+		logger.Fatalw("Failed to start informers", err)
+	}
+
+	// Start all of the controllers.
+	logger.Info("Starting controllers...")
+	controller.StartAll(ctx.Done(), controllers...)
+}
+
+func flush(logger *zap.SugaredLogger) {
+	logger.Sync()
+	metrics.FlushExporter()
+}
@@ -84,6 +84,16 @@ func WithLatestReady(name string) ConfigOption {
 	}
 }
 
+// WithConfigResourcesExhausted propagates a ResourcesExhausted condition with
+// the given reason and message onto the Configuration.
+func WithConfigResourcesExhausted(reason, message string) ConfigOption {
+	return func(cfg *v1alpha1.Configuration) {
+		rs := &v1alpha1.RevisionStatus{}
+		rs.MarkResourcesExhausted(reason, message)
+		cfg.Status.PropagateResourcesExhausted(rs)
+	}
+}
+
 // MarkRevisionCreationFailed calls .Status.MarkRevisionCreationFailed.
 func MarkRevisionCreationFailed(msg string) ConfigOption {
 	return func(cfg *v1alpha1.Configuration) {
@@ -146,6 +146,13 @@ func MarkResourcesUnavailable(reason, message string) RevisionOption {
 	}
 }
 
+// MarkResourcesExhausted calls .Status.MarkResourcesExhausted on the Revision.
+func MarkResourcesExhausted(reason, message string) RevisionOption {
+	return func(r *v1alpha1.Revision) {
+		r.Status.MarkResourcesExhausted(reason, message)
+	}
+}
+
 // MarkRevisionReady calls the necessary helpers to make the Revision Ready=True.
 func MarkRevisionReady(r *v1alpha1.Revision) {
 	WithInitRevConditions(r)
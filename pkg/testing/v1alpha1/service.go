@@ -72,6 +72,11 @@ func WithServiceDeletionTimestamp(r *v1alpha1.Service) {
 	r.ObjectMeta.SetDeletionTimestamp(&t)
 }
 
+// WithServiceFinalizer adds the Service finalizer to the Service.
+func WithServiceFinalizer(r *v1alpha1.Service) {
+	r.ObjectMeta.Finalizers = append(r.ObjectMeta.Finalizers, "services.serving.knative.dev")
+}
+
 // WithEnv configures the Service to use the provided environment variables.
 func WithEnv(evs ...corev1.EnvVar) ServiceOption {
 	return func(s *v1alpha1.Service) {
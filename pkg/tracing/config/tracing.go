@@ -19,7 +19,9 @@ package config
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -36,15 +38,45 @@ const (
 
 // Config holds the configuration for tracers
 type Config struct {
-	Enable         bool
-	ZipkinEndpoint string
-	Debug          bool
-	SampleRate     float64
+	Enable         bool    `json:"enable"`
+	ZipkinEndpoint string  `json:"zipkinEndpoint,omitempty"`
+	Debug          bool    `json:"debug,omitempty"`
+	SampleRate     float64 `json:"sampleRate"`
+
+	// SampleRateOverrides holds the sample rates of the namespaces, keyed
+	// by namespace, and of the revisions, keyed by `<namespace>.<revision>`.
+	SampleRateOverrides map[string]float64 `json:"-"`
+	// ZipkinEndpointOverrides holds the zipkin endpoints of the namespaces
+	// and of the revisions, keyed like SampleRateOverrides.
+	ZipkinEndpointOverrides map[string]string `json:"-"`
 }
 
 // Equals returns true if two Configs are identical
 func (cfg *Config) Equals(other *Config) bool {
-	return other.Enable == cfg.Enable && other.ZipkinEndpoint == cfg.ZipkinEndpoint && other.Debug == cfg.Debug && other.SampleRate == cfg.SampleRate
+	return other.Enable == cfg.Enable && other.ZipkinEndpoint == cfg.ZipkinEndpoint && other.Debug == cfg.Debug && other.SampleRate == cfg.SampleRate &&
+		reflect.DeepEqual(other.SampleRateOverrides, cfg.SampleRateOverrides) &&
+		reflect.DeepEqual(other.ZipkinEndpointOverrides, cfg.ZipkinEndpointOverrides)
+}
+
+// ForRevision returns the configuration of the given revision: the overrides
+// of its namespace apply on top of the global configuration, and those of
+// the revision on top of them. The result holds no overrides.
+func (cfg *Config) ForRevision(namespace, revision string) *Config {
+	out := &Config{
+		Enable:         cfg.Enable,
+		ZipkinEndpoint: cfg.ZipkinEndpoint,
+		Debug:          cfg.Debug,
+		SampleRate:     cfg.SampleRate,
+	}
+	for _, key := range []string{namespace, namespace + "." + revision} {
+		if rate, ok := cfg.SampleRateOverrides[key]; ok {
+			out.SampleRate = rate
+		}
+		if endpoint, ok := cfg.ZipkinEndpointOverrides[key]; ok {
+			out.ZipkinEndpoint = endpoint
+		}
+	}
+	return out
 }
 
 // NewTracingConfigFromMap returns a Config given a map corresponding to a ConfigMap
@@ -86,6 +118,27 @@ func NewTracingConfigFromMap(cfgMap map[string]string) (*Config, error) {
 		tc.SampleRate = sampleRateFloat
 	}
 
+	// The overrides are keyed `sample-rate.<namespace>[.<revision>]` and
+	// `zipkin-endpoint.<namespace>[.<revision>]`.
+	for k, v := range cfgMap {
+		switch {
+		case strings.HasPrefix(k, sampleRateKey+"."):
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("%s must be a rate in [0, 1], was %q", k, v)
+			}
+			if tc.SampleRateOverrides == nil {
+				tc.SampleRateOverrides = make(map[string]float64)
+			}
+			tc.SampleRateOverrides[strings.TrimPrefix(k, sampleRateKey+".")] = rate
+		case strings.HasPrefix(k, zipkinEndpointKey+"."):
+			if tc.ZipkinEndpointOverrides == nil {
+				tc.ZipkinEndpointOverrides = make(map[string]string)
+			}
+			tc.ZipkinEndpointOverrides[strings.TrimPrefix(k, zipkinEndpointKey+".")] = v
+		}
+	}
+
 	return &tc, nil
 }
 
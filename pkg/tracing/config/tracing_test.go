@@ -39,6 +39,11 @@ func TestEquals(t *testing.T) {
 		cmp1:   Config{Enable: true},
 		cmp2:   Config{},
 		expect: false,
+	}, {
+		name:   "Unequal overrides",
+		cmp1:   Config{SampleRateOverrides: map[string]float64{"ns": 1}},
+		cmp2:   Config{SampleRateOverrides: map[string]float64{"ns": 0.5}},
+		expect: false,
 	}}
 
 	for _, tc := range tt {
@@ -75,6 +80,25 @@ func TestNewConfigFromMap(t *testing.T) {
 			ZipkinEndpoint: "some-endpoint",
 			SampleRate:     0.5,
 		},
+	}, {
+		name: "Overrides",
+		input: map[string]string{
+			sampleRateKey + ".noisy":          "0.001",
+			sampleRateKey + ".noisy.new":      "1",
+			zipkinEndpointKey + ".noisy":      "noisy-endpoint",
+			zipkinEndpointKey + ".quiet.rev1": "quiet-endpoint",
+		},
+		output: Config{
+			SampleRate: 0.1,
+			SampleRateOverrides: map[string]float64{
+				"noisy":     0.001,
+				"noisy.new": 1,
+			},
+			ZipkinEndpointOverrides: map[string]string{
+				"noisy":      "noisy-endpoint",
+				"quiet.rev1": "quiet-endpoint",
+			},
+		},
 	}}
 
 	for _, tc := range tt {
@@ -90,6 +114,64 @@ func TestNewConfigFromMap(t *testing.T) {
 	}
 }
 
+func TestNewConfigFromMapErrors(t *testing.T) {
+	for _, input := range []map[string]string{{
+		sampleRateKey + ".ns": "often",
+	}, {
+		sampleRateKey + ".ns.rev": "1.5",
+	}} {
+		if cfg, err := NewTracingConfigFromMap(input); err == nil {
+			t.Errorf("NewTracingConfigFromMap(%v) = %v, wanted error", input, cfg)
+		}
+	}
+}
+
+func TestForRevision(t *testing.T) {
+	cfg := &Config{
+		Enable:         true,
+		ZipkinEndpoint: "endpoint",
+		SampleRate:     0.1,
+		SampleRateOverrides: map[string]float64{
+			"noisy":     0.001,
+			"noisy.new": 1,
+		},
+		ZipkinEndpointOverrides: map[string]string{
+			"noisy": "noisy-endpoint",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		revision  string
+		want      *Config
+	}{{
+		name:      "no override",
+		namespace: "quiet",
+		revision:  "new",
+		want:      &Config{Enable: true, ZipkinEndpoint: "endpoint", SampleRate: 0.1},
+	}, {
+		name:      "namespace override",
+		namespace: "noisy",
+		revision:  "old",
+		want:      &Config{Enable: true, ZipkinEndpoint: "noisy-endpoint", SampleRate: 0.001},
+	}, {
+		name:      "revision override",
+		namespace: "noisy",
+		revision:  "new",
+		want:      &Config{Enable: true, ZipkinEndpoint: "noisy-endpoint", SampleRate: 1},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := cfg.ForRevision(test.namespace, test.revision)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ForRevision (-want, +got) = %v", diff)
+			}
+		})
+	}
+}
+
 func TestConfigFromConfigMap(t *testing.T) {
 	cfg, err := NewTracingConfigFromConfigMap(&corev1.ConfigMap{
 		Data: map[string]string{
@@ -23,6 +23,20 @@ package config
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
+	if in.SampleRateOverrides != nil {
+		in, out := &in.SampleRateOverrides, &out.SampleRateOverrides
+		*out = make(map[string]float64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ZipkinEndpointOverrides != nil {
+		in, out := &in.ZipkinEndpointOverrides, &out.ZipkinEndpointOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
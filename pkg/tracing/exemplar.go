@@ -0,0 +1,40 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/trace"
+)
+
+// ExemplarAttachments returns the attachments making a measurement the
+// exemplar of the trace of the span in ctx, or nil if there is no sampled
+// span in ctx. The backends supporting exemplars use them to link the
+// buckets of distributions to example traces.
+func ExemplarAttachments(ctx context.Context) metricdata.Attachments {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return nil
+	}
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return nil
+	}
+	return metricdata.Attachments{metricdata.AttachmentKeySpanContext: sc}
+}
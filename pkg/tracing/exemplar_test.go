@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.opencensus.io/trace"
+)
+
+func TestExemplarAttachments(t *testing.T) {
+	if got := ExemplarAttachments(context.Background()); got != nil {
+		t.Errorf("ExemplarAttachments() = %v without a span, want nil", got)
+	}
+
+	ctx, span := trace.StartSpan(context.Background(), "unsampled", trace.WithSampler(trace.NeverSample()))
+	span.End()
+	if got := ExemplarAttachments(ctx); got != nil {
+		t.Errorf("ExemplarAttachments() = %v for an unsampled span, want nil", got)
+	}
+
+	ctx, span = trace.StartSpan(context.Background(), "sampled", trace.WithSampler(trace.AlwaysSample()))
+	span.End()
+	got := ExemplarAttachments(ctx)
+	if sc, ok := got[metricdata.AttachmentKeySpanContext].(trace.SpanContext); !ok || sc != span.SpanContext() {
+		t.Errorf("ExemplarAttachments() = %v, want the span context %v", got, span.SpanContext())
+	}
+}
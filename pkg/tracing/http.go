@@ -20,9 +20,22 @@ import (
 	"net/http"
 
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
 )
 
 // HTTPSpanMiddleware is a http.Handler middleware to create spans for the HTTP endpoint
 func HTTPSpanMiddleware(next http.Handler) http.Handler {
 	return &ochttp.Handler{Handler: next}
 }
+
+// HTTPSpanMiddlewareWithSampler is HTTPSpanMiddleware sampling the traces of
+// each request with the sampler that sampler returns for it, or with the
+// default sampler when that is nil.
+func HTTPSpanMiddlewareWithSampler(next http.Handler, sampler func(*http.Request) trace.Sampler) http.Handler {
+	return &ochttp.Handler{
+		Handler: next,
+		GetStartOptions: func(r *http.Request) trace.StartOptions {
+			return trace.StartOptions{Sampler: sampler(r)}
+		},
+	}
+}
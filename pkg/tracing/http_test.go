@@ -24,6 +24,7 @@ import (
 	openzipkin "github.com/openzipkin/zipkin-go"
 	zipkinreporter "github.com/openzipkin/zipkin-go/reporter"
 	reporterrecorder "github.com/openzipkin/zipkin-go/reporter/recorder"
+	"go.opencensus.io/trace"
 	"knative.dev/serving/pkg/tracing/config"
 )
 
@@ -98,3 +99,48 @@ func TestHTTPSpanMiddleware(t *testing.T) {
 		t.Errorf("spans[0].TraceID = %s, want %s", got, traceID)
 	}
 }
+
+func TestHTTPSpanMiddlewareWithSampler(t *testing.T) {
+	cfg := config.Config{
+		Enable:     true,
+		SampleRate: 1,
+	}
+
+	reporter := reporterrecorder.NewReporter()
+	defer reporter.Close()
+	endpoint, _ := openzipkin.NewEndpoint("test", "localhost:1234")
+	oct := NewOpenCensusTracer(WithZipkinExporter(func(cfg *config.Config) (zipkinreporter.Reporter, error) {
+		return reporter, nil
+	}, endpoint))
+	defer oct.Finish()
+
+	if err := oct.ApplyConfig(&cfg); err != nil {
+		t.Errorf("Failed to apply tracer config: %v", err)
+	}
+
+	middleware := HTTPSpanMiddlewareWithSampler(&testHandler{}, func(r *http.Request) trace.Sampler {
+		if r.Host == "quiet.example.com" {
+			return trace.NeverSample()
+		}
+		return nil
+	})
+
+	var lastWrite []byte
+	fw := fakeWriter{lastWrite: &lastWrite}
+	for _, host := range []string{"quiet.example.com", "test.example.com"} {
+		req, err := http.NewRequest("GET", "http://"+host, nil)
+		if err != nil {
+			t.Errorf("Failed to make fake request: %v", err)
+		}
+		middleware.ServeHTTP(fw, req)
+	}
+
+	// Only the request the default sampler applies to is traced.
+	spans := reporter.Flush()
+	if len(spans) != 1 {
+		t.Fatalf("Got %d spans, expected 1: spans = %v", len(spans), spans)
+	}
+	if got, want := spans[0].Tags["http.host"], "test.example.com"; got != want {
+		t.Errorf("spans[0] host = %s, want %s", got, want)
+	}
+}
@@ -85,19 +85,19 @@ func (oct *OpenCensusTracer) acquireGlobal() error {
 }
 
 func createOCTConfig(cfg *config.Config) *trace.Config {
-	octCfg := trace.Config{}
+	return &trace.Config{DefaultSampler: Sampler(cfg)}
+}
 
-	if cfg.Enable {
-		if cfg.Debug {
-			octCfg.DefaultSampler = trace.AlwaysSample()
-		} else {
-			octCfg.DefaultSampler = trace.ProbabilitySampler(cfg.SampleRate)
-		}
-	} else {
-		octCfg.DefaultSampler = trace.NeverSample()
+// Sampler returns the sampler of the traces described by cfg.
+func Sampler(cfg *config.Config) trace.Sampler {
+	switch {
+	case !cfg.Enable:
+		return trace.NeverSample()
+	case cfg.Debug:
+		return trace.AlwaysSample()
+	default:
+		return trace.ProbabilitySampler(cfg.SampleRate)
 	}
-
-	return &octCfg
 }
 
 func WithZipkinExporter(reporterFact ZipkinReporterFactory, endpoint *zipkinmodel.Endpoint) ConfigOption {
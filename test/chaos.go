@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// chaos.go provides methods to disrupt the Knative Serving system components
+// and to wait for them to recover.
+
+package test
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"knative.dev/pkg/system"
+)
+
+// KillSystemPods deletes all pods of the system component with the given app
+// label, without a grace period, and returns the names of the deleted pods.
+func KillSystemPods(clients *Clients, app string) ([]string, error) {
+	pods := clients.KubeClient.Kube.CoreV1().Pods(system.Namespace())
+	list, err := pods.List(metav1.ListOptions{LabelSelector: "app=" + app})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no pods found for %q", app)
+	}
+
+	var zero int64
+	killed := make([]string, 0, len(list.Items))
+	for _, pod := range list.Items {
+		if err := pods.Delete(pod.Name, &metav1.DeleteOptions{GracePeriodSeconds: &zero}); err != nil {
+			return killed, fmt.Errorf("failed to delete pod %s: %v", pod.Name, err)
+		}
+		killed = append(killed, pod.Name)
+	}
+	return killed, nil
+}
+
+// WaitForSystemPodsReady waits until the system component with the given app
+// label is back to serving, i.e. none of the killed pods are around anymore and
+// at least one pod is ready. It returns the time it took.
+func WaitForSystemPodsReady(clients *Clients, app string, killed []string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	gone := sets.NewString(killed...)
+	pods := clients.KubeClient.Kube.CoreV1().Pods(system.Namespace())
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		list, err := pods.List(metav1.ListOptions{LabelSelector: "app=" + app})
+		if err != nil {
+			return false, err
+		}
+		ready := 0
+		for _, pod := range list.Items {
+			if gone.Has(pod.Name) {
+				return false, nil
+			}
+			if podReady(&pod) {
+				ready++
+			}
+		}
+		return ready > 0 && ready == len(list.Items), nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s did not recover within %v: %v", app, timeout, err)
+	}
+	return time.Since(start), nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+// +build e2e
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaos
+
+import (
+	"testing"
+	"time"
+
+	pkgTest "knative.dev/pkg/test"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	v1a1opts "knative.dev/serving/pkg/testing/v1alpha1"
+	"knative.dev/serving/test"
+	"knative.dev/serving/test/e2e"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+const (
+	// minProbes is the minimum number of probes sent, both before the
+	// component is killed and after it recovered.
+	minProbes = 100
+	// recoveryTimeout is the longest a component may take to come back.
+	recoveryTimeout = 2 * time.Minute
+)
+
+// TestKillComponentUnderLoad deletes the pods of a system component while a
+// Service is probed and checks that the component recovers in time and the
+// error rate stays bounded. The activator is forced into the request path, so
+// that killing it actually affects the traffic.
+func TestKillComponentUnderLoad(t *testing.T) {
+	tests := []struct {
+		app string
+		// slo is the minimum success rate while the component is down.
+		slo float64
+	}{{
+		app: "activator",
+		slo: 0.9,
+	}, {
+		// Neither the autoscaler nor the controller are on the data path.
+		app: "autoscaler",
+		slo: 1.0,
+	}, {
+		app: "controller",
+		slo: 1.0,
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.app, func(t *testing.T) {
+			clients := e2e.Setup(t)
+			names := test.ResourceNames{
+				Service: test.ObjectNameForTest(t),
+				Image:   "helloworld",
+			}
+			defer test.TearDown(clients, names)
+			test.CleanupOnInterrupt(func() { test.TearDown(clients, names) })
+
+			objects, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+				v1a1opts.WithConfigAnnotations(map[string]string{
+					autoscaling.MinScaleAnnotationKey:  "1",
+					autoscaling.TargetBurstCapacityKey: "-1",
+				}))
+			if err != nil {
+				t.Fatalf("Failed to create Service: %v", err)
+			}
+			domain := objects.Route.Status.URL.Host
+			if _, err := pkgTest.WaitForEndpointState(
+				clients.KubeClient,
+				t.Logf,
+				domain,
+				v1a1test.RetryingRouteInconsistency(pkgTest.MatchesAllOf(pkgTest.IsStatusOK, pkgTest.MatchesBody(test.HelloWorldText))),
+				"HelloWorldServesText",
+				test.ServingFlags.ResolvableDomain); err != nil {
+				t.Fatalf("The endpoint at domain %s didn't serve the expected text: %v", domain, err)
+			}
+
+			pm := test.NewProberManager(t.Logf, clients, minProbes)
+			pm.Spawn(domain)
+			defer pm.Stop()
+
+			t.Logf("Killing the %s pods", tc.app)
+			killed, err := test.KillSystemPods(clients, tc.app)
+			if err != nil {
+				t.Fatalf("Failed to kill %s: %v", tc.app, err)
+			}
+			recovery, err := test.WaitForSystemPodsReady(clients, tc.app, killed, recoveryTimeout)
+			if err != nil {
+				t.Fatalf("Failed waiting for %s: %v", tc.app, err)
+			}
+			t.Logf("%s recovered in %v", tc.app, recovery)
+
+			// Keep probing for a while after the recovery, to make sure the
+			// traffic is served again.
+			after := test.NewProberManager(t.Logf, clients, minProbes)
+			after.Spawn(domain)
+			if err := after.Stop(); err != nil {
+				t.Fatalf("Stop() = %v", err)
+			}
+			if err := test.CheckSLO(1.0, "after-recovery", after); err != nil {
+				t.Errorf("CheckSLO() = %v", err)
+			}
+
+			if err := pm.Stop(); err != nil {
+				t.Fatalf("Stop() = %v", err)
+			}
+			if err := test.CheckSLO(tc.slo, "during-disruption", pm); err != nil {
+				t.Errorf("CheckSLO() = %v", err)
+			}
+
+			// The Service must still be reconcilable once the control plane
+			// is back.
+			if _, err := v1a1test.PatchServiceImage(t, clients, objects.Service, pkgTest.ImagePath(test.PizzaPlanet1)); err != nil {
+				t.Fatalf("Failed to update Service: %v", err)
+			}
+			names.Image = test.PizzaPlanet1
+			if _, err := v1a1test.WaitForServiceLatestRevision(clients, names); err != nil {
+				t.Fatalf("Service did not roll out a new Revision after %s recovered: %v", tc.app, err)
+			}
+		})
+	}
+}
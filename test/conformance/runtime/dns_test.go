@@ -0,0 +1,48 @@
+// +build e2e
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"testing"
+
+	"knative.dev/serving/test"
+	"knative.dev/serving/test/types"
+)
+
+// TestShouldResolveHosts verifies that the container's resolver can resolve the cluster-local host names that are
+// declared as "SHOULD be resolvable" in runtime-contract.
+func TestShouldResolveHosts(t *testing.T) {
+	t.Parallel()
+	clients := test.Setup(t)
+	_, ri, err := fetchRuntimeInfo(t, clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, host := range types.ShouldResolveHosts {
+		info, ok := ri.Host.DNS[host]
+		if !ok {
+			t.Errorf("runtime contract DNS info not present: %s", host)
+		} else if info.Error != "" {
+			t.Errorf("Error resolving %s: %s", host, info.Error)
+		} else if len(info.Addrs) == 0 {
+			t.Errorf("%s resolved to no addresses", host)
+		}
+	}
+}
@@ -95,3 +95,60 @@ func TestShouldHaveFiles(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestMustWritableDirs asserts that files can be created in all the directories the runtime contract requires to be
+// writable.
+func TestMustWritableDirs(t *testing.T) {
+	t.Parallel()
+	clients := test.Setup(t)
+	_, ri, err := fetchRuntimeInfo(t, clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range types.MustWritableDirs {
+		w, ok := ri.Host.Writes[dir]
+		if !ok {
+			t.Errorf("runtime contract write info not present: %s", dir)
+		} else if !w.Writable {
+			t.Errorf("%s is not writable: %s", dir, w.Error)
+		}
+	}
+}
+
+// TestReadOnlyRootFilesystem asserts that when the platform provider chooses to mount the root filesystem read-only,
+// it is indeed not writable. The required writable directories are checked by TestMustWritableDirs either way.
+func TestReadOnlyRootFilesystem(t *testing.T) {
+	t.Parallel()
+	clients := test.Setup(t)
+	_, ri, err := fetchRuntimeInfo(t, clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var root *types.Mount
+	for _, m := range ri.Host.Mounts {
+		if m.Error != "" {
+			t.Fatalf("Error reading mounts: %s", m.Error)
+		}
+		if m.Path == "/" {
+			root = m
+		}
+	}
+	if root == nil {
+		t.Fatal("runtime contract mount info not present: /")
+	}
+
+	readOnly := false
+	for _, o := range root.Options {
+		if o == "ro" {
+			readOnly = true
+		}
+	}
+	if !readOnly {
+		t.Skip("The root filesystem is not mounted read-only")
+	}
+	if w := ri.Host.Writes["/"]; w.Writable {
+		t.Error("/ is mounted read-only, but is writable")
+	}
+}
@@ -29,14 +29,18 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/system"
 	pkgTest "knative.dev/pkg/test"
-	ingress "knative.dev/pkg/test/ingress"
 	"knative.dev/pkg/test/logstream"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+	"knative.dev/serving/pkg/apis/serving/v1beta1"
+	rnames "knative.dev/serving/pkg/reconciler/revision/resources/names"
 	rtesting "knative.dev/serving/pkg/testing/v1alpha1"
 	"knative.dev/serving/test"
 	ping "knative.dev/serving/test/test_images/grpc-ping/proto"
@@ -191,18 +195,15 @@ func testGRPC(t *testing.T, f grpcTest, fopts ...rtesting.ServiceOption) {
 		t.Fatalf("The endpoint for Route %s at domain %s didn't return success: %v", names.Route, domain, err)
 	}
 
-	host := &domain
+	host := domain
 	if !test.ServingFlags.ResolvableDomain {
-		host = &pkgTest.Flags.IngressEndpoint
-		if pkgTest.Flags.IngressEndpoint == "" {
-			host, err = ingress.GetIngressEndpoint(clients.KubeClient.Kube)
-			if err != nil {
-				t.Fatalf("Could not get service endpoint: %v", err)
-			}
+		host, err = test.GetIngressEndpoint(clients.KubeClient.Kube)
+		if err != nil {
+			t.Fatalf("Could not get service endpoint: %v", err)
 		}
 	}
 
-	f(t, resources, clients, *host, domain)
+	f(t, resources, clients, host, domain)
 }
 
 func TestGRPCUnaryPing(t *testing.T) {
@@ -258,3 +259,110 @@ func TestGRPCStreamingPingViaActivator(t *testing.T) {
 		}),
 	)
 }
+
+func TestGRPCUnaryPingViaTag(t *testing.T) {
+	testGRPC(t,
+		func(t *testing.T, resources *v1a1test.ResourceObjects, clients *test.Clients, host, domain string) {
+			tagDomain := resources.Route.Status.Traffic[0].URL.Host
+			if _, err := pkgTest.WaitForEndpointState(
+				clients.KubeClient,
+				t.Logf,
+				tagDomain,
+				v1a1test.RetryingRouteInconsistency(pkgTest.IsStatusOK),
+				"gRPCPingTagReadyToServe",
+				test.ServingFlags.ResolvableDomain); err != nil {
+				t.Fatalf("The endpoint at tag domain %s didn't return success: %v", tagDomain, err)
+			}
+			if test.ServingFlags.ResolvableDomain {
+				host = tagDomain
+			}
+			unaryTest(t, resources, clients, host, tagDomain)
+		},
+		rtesting.WithInlineRouteSpec(v1alpha1.RouteSpec{
+			Traffic: []v1alpha1.TrafficTarget{{
+				TrafficTarget: v1beta1.TrafficTarget{
+					Tag:     "current",
+					Percent: 100,
+				},
+			}},
+		}),
+	)
+}
+
+func TestGRPCUnaryPingTimeout(t *testing.T) {
+	const timeoutSeconds = 5
+	testGRPC(t,
+		func(t *testing.T, resources *v1a1test.ResourceObjects, clients *test.Clients, host, domain string) {
+			conn, err := dial(host, domain)
+			if err != nil {
+				t.Fatalf("Fail to dial: %v", err)
+			}
+			defer conn.Close()
+			pc := ping.NewPingServiceClient(conn)
+
+			pingWithDelay := func(delay time.Duration) error {
+				ctx := metadata.AppendToOutgoingContext(context.Background(), "delay", delay.String())
+				_, err := pc.Ping(ctx, &ping.Request{Msg: "Hello!"})
+				return err
+			}
+
+			t.Log("Testing a unary Ping that answers within the timeout")
+			if err := pingWithDelay(time.Second); err != nil {
+				t.Errorf("Ping() = %v, want no error", err)
+			}
+			t.Log("Testing a unary Ping that exceeds the timeout")
+			if err := pingWithDelay(2 * timeoutSeconds * time.Second); err == nil {
+				t.Error("Ping() = nil, want an error because of the revision timeout")
+			}
+		},
+		rtesting.WithRevisionTimeoutSeconds(timeoutSeconds),
+	)
+}
+
+func TestGRPCStreamingScale(t *testing.T) {
+	const streams = 3
+	testGRPC(t,
+		func(t *testing.T, resources *v1a1test.ResourceObjects, clients *test.Clients, host, domain string) {
+			conn, err := dial(host, domain)
+			if err != nil {
+				t.Fatalf("Fail to dial: %v", err)
+			}
+			defer conn.Close()
+			pc := ping.NewPingServiceClient(conn)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+
+			// Every open stream is an in-flight request, so with a container
+			// concurrency of 1 each of them needs its own pod.
+			t.Logf("Opening %d streams", streams)
+			for i := 0; i < streams; i++ {
+				stream, err := pc.PingStream(ctx)
+				if err != nil {
+					t.Fatalf("Error creating stream %d: %v", i, err)
+				}
+				if err := stream.Send(&ping.Request{Msg: "Hello!"}); err != nil {
+					t.Fatalf("Error sending on stream %d: %v", i, err)
+				}
+				if _, err := stream.Recv(); err != nil {
+					t.Fatalf("Error receiving on stream %d: %v", i, err)
+				}
+				defer stream.CloseSend()
+			}
+
+			deploymentName := rnames.Deployment(resources.Revision)
+			if err := pkgTest.WaitForDeploymentState(
+				clients.KubeClient,
+				deploymentName,
+				func(d *appsv1.Deployment) (bool, error) {
+					return d.Status.ReadyReplicas >= streams, nil
+				},
+				"DeploymentScaledForStreams",
+				test.ServingNamespace,
+				3*time.Minute); err != nil {
+				t.Fatalf("Deployment %s did not scale to %d pods for %d open streams: %v", deploymentName, streams, streams, err)
+			}
+		},
+		rtesting.WithContainerConcurrency(1),
+	)
+}
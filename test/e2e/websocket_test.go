@@ -25,14 +25,16 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/gorilla/websocket"
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"knative.dev/pkg/system"
 	pkgTest "knative.dev/pkg/test"
-	ingress "knative.dev/pkg/test/ingress"
 	"knative.dev/pkg/test/logstream"
 	"knative.dev/serving/pkg/activator"
 	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/apis/serving"
+	rnames "knative.dev/serving/pkg/reconciler/revision/resources/names"
 	rtesting "knative.dev/serving/pkg/testing/v1alpha1"
 	"knative.dev/serving/test"
 	v1a1test "knative.dev/serving/test/v1alpha1"
@@ -74,26 +76,20 @@ func connect(t *testing.T, ingressIP string, domain string) (*websocket.Conn, er
 	return conn, waitErr
 }
 
-func validateWebSocketConnection(t *testing.T, clients *test.Clients, names test.ResourceNames) error {
-	var err error
-	gatewayIP := &pkgTest.Flags.IngressEndpoint
-	if pkgTest.Flags.IngressEndpoint == "" {
-		if gatewayIP, err = ingress.GetIngressEndpoint(clients.KubeClient.Kube); err != nil {
-			return err
-		}
-	}
-
-	// Establish the websocket connection.
-	conn, err := connect(t, *gatewayIP, names.Domain)
+// dialWebSocket establishes a websocket connection to the service of names
+// through the ingress.
+func dialWebSocket(t *testing.T, clients *test.Clients, names test.ResourceNames) (*websocket.Conn, error) {
+	gatewayIP, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer conn.Close()
+	return connect(t, gatewayIP, names.Domain)
+}
 
-	// Send a message.
-	const sent = "Hello, websocket"
+// echo sends sent over conn and checks that the server echoes it back.
+func echo(t *testing.T, conn *websocket.Conn, sent string) error {
 	t.Logf("Sending message %q to server.", sent)
-	if err = conn.WriteMessage(websocket.TextMessage, []byte(sent)); err != nil {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(sent)); err != nil {
 		return err
 	}
 	t.Log("Message sent.")
@@ -109,6 +105,17 @@ func validateWebSocketConnection(t *testing.T, clients *test.Clients, names test
 	return nil
 }
 
+func validateWebSocketConnection(t *testing.T, clients *test.Clients, names test.ResourceNames) error {
+	// Establish the websocket connection.
+	conn, err := dialWebSocket(t, clients, names)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return echo(t, conn, "Hello, websocket")
+}
+
 // TestWebSocket (1) creates a service based on the `wsserver` image,
 // (2) connects to the service using websocket, (3) sends a message, and
 // (4) verifies that we receive back the same message.
@@ -187,3 +194,202 @@ func TestWebSocketViaActivator(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// TestWebSocketKeepsRevisionActive holds a websocket connection open, both
+// directly through the ingress and through the activator, for longer than it
+// takes an idle revision to scale to zero. It verifies that the connection
+// survives and that the open connection keeps the revision from scaling to
+// zero, and that the revision scales to zero once the connection is closed.
+func TestWebSocketKeepsRevisionActive(t *testing.T) {
+	t.Parallel()
+	cancel := logstream.Start(t)
+	defer cancel()
+
+	clients := Setup(t)
+
+	cfg, err := autoscalerCM(clients)
+	if err != nil {
+		t.Fatalf("Error retrieving autoscaler configmap: %v", err)
+	}
+	hold := cfg.StableWindow + cfg.ScaleToZeroGracePeriod + 30*time.Second
+
+	for _, path := range []struct {
+		name string
+		tbc  string
+	}{{
+		name: "ingress",
+		tbc:  "0",
+	}, {
+		name: "activator",
+		tbc:  "-1",
+	}} {
+		path := path
+		t.Run(path.name, func(t *testing.T) {
+			t.Parallel()
+			names := test.ResourceNames{
+				Service: test.ObjectNameForTest(t),
+				Image:   wsServerTestImageName,
+			}
+
+			// Clean up in both abnormal and normal exits.
+			defer test.TearDown(clients, names)
+			test.CleanupOnInterrupt(func() { test.TearDown(clients, names) })
+
+			resources, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+				rtesting.WithConfigAnnotations(map[string]string{
+					autoscaling.TargetBurstCapacityKey: path.tbc,
+				}),
+			)
+			if err != nil {
+				t.Fatalf("Failed to create WebSocket server: %v", err)
+			}
+			deploymentName := rnames.Deployment(resources.Revision)
+
+			conn, err := dialWebSocket(t, clients, names)
+			if err != nil {
+				t.Fatalf("Failed to connect: %v", err)
+			}
+			defer conn.Close()
+
+			t.Logf("Holding the connection open for %v", hold)
+			for start := time.Now(); time.Since(start) < hold; time.Sleep(10 * time.Second) {
+				if err := echo(t, conn, fmt.Sprintf("Still there after %v?", time.Since(start).Round(time.Second))); err != nil {
+					t.Fatalf("Connection did not survive: %v", err)
+				}
+				d, err := clients.KubeClient.Kube.AppsV1().Deployments(test.ServingNamespace).Get(deploymentName, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("Error getting deployment %s: %v", deploymentName, err)
+				}
+				if d.Status.ReadyReplicas == 0 {
+					t.Fatalf("Deployment %s scaled to zero while a connection was open", deploymentName)
+				}
+			}
+
+			// The connection is the only in-flight request, so closing it
+			// must allow the revision to scale to zero.
+			conn.Close()
+			if err := WaitForScaleToZero(t, deploymentName, clients); err != nil {
+				t.Fatalf("Deployment %s did not scale to zero after the connection was closed: %v", deploymentName, err)
+			}
+		})
+	}
+}
+
+// TestWebSocketConcurrency verifies that every open websocket connection is
+// accounted as an in-flight request: with a container concurrency of 1 each
+// connection needs its own pod.
+func TestWebSocketConcurrency(t *testing.T) {
+	t.Parallel()
+	cancel := logstream.Start(t)
+	defer cancel()
+
+	const connections = 3
+	clients := Setup(t)
+
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   wsServerTestImageName,
+	}
+
+	// Clean up in both abnormal and normal exits.
+	defer test.TearDown(clients, names)
+	test.CleanupOnInterrupt(func() { test.TearDown(clients, names) })
+
+	resources, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+		rtesting.WithContainerConcurrency(1),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create WebSocket server: %v", err)
+	}
+
+	conns := make([]*websocket.Conn, 0, connections)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for i := 0; i < connections; i++ {
+		conn, err := dialWebSocket(t, clients, names)
+		if err != nil {
+			t.Fatalf("Failed to establish connection %d: %v", i, err)
+		}
+		conns = append(conns, conn)
+		if err := echo(t, conn, fmt.Sprintf("Hello from connection %d", i)); err != nil {
+			t.Fatalf("Connection %d failed: %v", i, err)
+		}
+	}
+
+	deploymentName := rnames.Deployment(resources.Revision)
+	if err := pkgTest.WaitForDeploymentState(
+		clients.KubeClient,
+		deploymentName,
+		func(d *appsv1.Deployment) (bool, error) {
+			return d.Status.ReadyReplicas >= connections, nil
+		},
+		"DeploymentScaledForConnections",
+		test.ServingNamespace,
+		3*time.Minute); err != nil {
+		t.Fatalf("Deployment %s did not scale to %d pods for %d open connections: %v", deploymentName, connections, connections, err)
+	}
+
+	// Scaling up must not have disturbed the existing connections.
+	for i, conn := range conns {
+		if err := echo(t, conn, fmt.Sprintf("Hello again from connection %d", i)); err != nil {
+			t.Errorf("Connection %d failed after scaling up: %v", i, err)
+		}
+	}
+}
+
+// TestWebSocketGracefulClose verifies that when the pod serving a websocket
+// connection is terminated, the close frame the server sends on SIGTERM makes
+// it all the way to the client, rather than the connection being dropped.
+func TestWebSocketGracefulClose(t *testing.T) {
+	t.Parallel()
+	cancel := logstream.Start(t)
+	defer cancel()
+
+	clients := Setup(t)
+
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   wsServerTestImageName,
+	}
+
+	// Clean up in both abnormal and normal exits.
+	defer test.TearDown(clients, names)
+	test.CleanupOnInterrupt(func() { test.TearDown(clients, names) })
+
+	if _, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names); err != nil {
+		t.Fatalf("Failed to create WebSocket server: %v", err)
+	}
+
+	conn, err := dialWebSocket(t, clients, names)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if err := echo(t, conn, "Hello, websocket"); err != nil {
+		t.Fatalf("Connection failed: %v", err)
+	}
+
+	pods := clients.KubeClient.Kube.CoreV1().Pods(test.ServingNamespace)
+	podList, err := pods.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", serving.RevisionLabelKey, names.Revision),
+	})
+	if err != nil {
+		t.Fatalf("Error listing pods of revision %s: %v", names.Revision, err)
+	}
+	for _, pod := range podList.Items {
+		t.Logf("Deleting pod %s", pod.Name)
+		if err := pods.Delete(pod.Name, &metav1.DeleteOptions{}); err != nil {
+			t.Fatalf("Error deleting pod %s: %v", pod.Name, err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Minute))
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("ReadMessage() = %v, want a close error with code %d", err, websocket.CloseGoingAway)
+	}
+	t.Logf("Connection closed gracefully: %v", err)
+}
@@ -21,6 +21,8 @@ package test
 
 import (
 	"flag"
+	"fmt"
+	"log"
 
 	"knative.dev/pkg/test"
 	"knative.dev/pkg/test/logging"
@@ -54,7 +56,8 @@ var ServingFlags = initializeServingFlags()
 
 // ServingEnvironmentFlags holds the e2e flags needed only by the serving repo.
 type ServingEnvironmentFlags struct {
-	ResolvableDomain bool // Resolve Route controller's `domainSuffix`
+	ResolvableDomain bool   // Resolve Route controller's `domainSuffix`
+	Ingress          string // Name of the ingress provider the requests are sent through
 }
 
 func initializeServingFlags() *ServingEnvironmentFlags {
@@ -63,7 +66,13 @@ func initializeServingFlags() *ServingEnvironmentFlags {
 	flag.BoolVar(&f.ResolvableDomain, "resolvabledomain", false,
 		"Set this flag to true if you have configured the `domainSuffix` on your Route controller to a domain that will resolve to your test cluster.")
 
+	flag.StringVar(&f.Ingress, "ingress", DefaultIngressProvider,
+		"Set this flag to the ingress provider installed in your test cluster, one of "+fmt.Sprint(ingressProviderNames())+".")
+
 	flag.Parse()
+	if err := setupIngressProvider(f.Ingress); err != nil {
+		log.Fatalf("Invalid --ingress flag: %v", err)
+	}
 	flag.Set("alsologtostderr", "true")
 	logging.InitializeLogger(test.Flags.LogVerbose)
 
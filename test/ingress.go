@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// ingress.go abstracts over the ingress implementation the tests send their
+// requests through.
+
+package test
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	pkgTest "knative.dev/pkg/test"
+	"knative.dev/pkg/test/ingress"
+)
+
+const (
+	// gatewayOverrideEnv and gatewayNamespaceOverrideEnv are honored by the
+	// spoofing client to find the ingress gateway.
+	gatewayOverrideEnv          = "GATEWAY_OVERRIDE"
+	gatewayNamespaceOverrideEnv = "GATEWAY_NAMESPACE_OVERRIDE"
+
+	// DefaultIngressProvider is the ingress provider used if none is given.
+	DefaultIngressProvider = "istio"
+)
+
+// IngressProvider knows how to reach the ingress of a networking layer from
+// outside of the cluster.
+type IngressProvider interface {
+	// Gateway returns the namespace and name of the Kubernetes Service that
+	// exposes the ingress.
+	Gateway() (namespace, name string)
+
+	// Endpoint returns the IP or hostname, optionally with a port, the
+	// requests for Knative Services have to be sent to.
+	Endpoint(kube kubernetes.Interface) (string, error)
+}
+
+// ServiceIngressProvider is an IngressProvider whose endpoint is the load
+// balancer of a Kubernetes Service.
+type ServiceIngressProvider struct {
+	Namespace string
+	Name      string
+}
+
+var _ IngressProvider = ServiceIngressProvider{}
+
+// Gateway implements IngressProvider.
+func (p ServiceIngressProvider) Gateway() (string, string) {
+	return p.Namespace, p.Name
+}
+
+// Endpoint implements IngressProvider.
+func (p ServiceIngressProvider) Endpoint(kube kubernetes.Interface) (string, error) {
+	svc, err := kube.CoreV1().Services(p.Namespace).Get(p.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return ingress.EndpointFromService(svc)
+}
+
+// ingressProviders are the known ingress providers, selectable via the
+// --ingress flag.
+var ingressProviders = map[string]IngressProvider{
+	"istio": ServiceIngressProvider{
+		Namespace: "istio-system",
+		Name:      "istio-ingressgateway",
+	},
+	"gloo": ServiceIngressProvider{
+		Namespace: "gloo-system",
+		Name:      "clusteringress-proxy",
+	},
+	"kourier": ServiceIngressProvider{
+		Namespace: "kourier-system",
+		Name:      "kourier",
+	},
+}
+
+func ingressProviderNames() []string {
+	names := make([]string, 0, len(ingressProviders))
+	for name := range ingressProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetIngressProvider returns the ingress provider selected via the --ingress
+// flag.
+func GetIngressProvider() IngressProvider {
+	return ingressProviders[ServingFlags.Ingress]
+}
+
+// GetIngressEndpoint returns the endpoint the requests for Knative Services
+// have to be sent to. An endpoint passed via --ingressendpoint takes
+// precedence over the one of the selected ingress provider.
+func GetIngressEndpoint(kube kubernetes.Interface) (string, error) {
+	if pkgTest.Flags.IngressEndpoint != "" {
+		return pkgTest.Flags.IngressEndpoint, nil
+	}
+	return GetIngressProvider().Endpoint(kube)
+}
+
+// setupIngressProvider validates the selected ingress provider and points the
+// spoofing client to its gateway, unless that has been overridden explicitly.
+func setupIngressProvider(name string) error {
+	p, ok := ingressProviders[name]
+	if !ok {
+		return fmt.Errorf("unknown ingress provider %q, must be one of %v", name, ingressProviderNames())
+	}
+	ns, svc := p.Gateway()
+	if os.Getenv(gatewayOverrideEnv) == "" {
+		os.Setenv(gatewayOverrideEnv, svc)
+	}
+	if os.Getenv(gatewayNamespaceOverrideEnv) == "" {
+		os.Setenv(gatewayNamespaceOverrideEnv, ns)
+	}
+	return nil
+}
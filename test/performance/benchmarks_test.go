@@ -29,7 +29,6 @@ import (
 	perf "github.com/knative/test-infra/shared/performance"
 	"github.com/knative/test-infra/shared/testgrid"
 	pkgTest "knative.dev/pkg/test"
-	"knative.dev/pkg/test/ingress"
 	"knative.dev/serving/test"
 	v1a1test "knative.dev/serving/test/v1alpha1"
 
@@ -72,7 +71,7 @@ func runTest(t *testing.T, pacer vegeta.Pacer, saveMetrics bool) {
 	}
 
 	domain := objs.Route.Status.URL.Host
-	endpoint, err := ingress.GetIngressEndpoint(clients.KubeClient.Kube)
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
 	if err != nil {
 		t.Fatalf("Cannot get service endpoint: %v", err)
 	}
@@ -90,7 +89,7 @@ func runTest(t *testing.T, pacer vegeta.Pacer, saveMetrics bool) {
 	targeter := vegeta.NewStaticTargeter(vegeta.Target{
 		Method: http.MethodGet,
 		Header: map[string][]string{"Host": {domain}},
-		URL:    fmt.Sprintf("http://%s", *endpoint),
+		URL:    fmt.Sprintf("http://%s", endpoint),
 	})
 	attacker := vegeta.NewAttacker()
 
@@ -161,3 +160,45 @@ func TestBenchmarkBurstNto2N(t *testing.T) {
 		})
 	}
 }
+
+// TestBenchmarkSinusoidal generates traffic oscillating between N/2 and 3N/2 requests, for different values of N.
+func TestBenchmarkSinusoidal(t *testing.T) {
+	for _, load := range loads {
+		t.Run(fmt.Sprintf("N%d", load), func(t *testing.T) {
+			sinePacer := vegeta.SinePacer{
+				Period:  duration,
+				Mean:    vegeta.Rate{Freq: load, Per: time.Second},
+				Amp:     vegeta.Rate{Freq: load / 2, Per: time.Second},
+				StartAt: vegeta.MeanUp,
+			}
+			runTest(t, sinePacer, true)
+		})
+	}
+}
+
+// TestBenchmarkStep generates traffic jumping between N/4, N and N/2 requests, with an idle period in between, for
+// different values of N.
+func TestBenchmarkStep(t *testing.T) {
+	for _, load := range loads {
+		t.Run(fmt.Sprintf("N%d", load), func(t *testing.T) {
+			stepPacer := NewStepPacer([]vegeta.Rate{
+				{Freq: load / 4, Per: time.Second},
+				{Freq: load, Per: time.Second},
+				{Freq: 0, Per: time.Second},
+				{Freq: load / 2, Per: time.Second},
+			}, duration/4)
+			runTest(t, stepPacer, true)
+		})
+	}
+}
+
+// TestBenchmarkPoisson generates traffic of N requests on average, arriving like they were sent by independent
+// users, for different values of N.
+func TestBenchmarkPoisson(t *testing.T) {
+	for _, load := range loads {
+		t.Run(fmt.Sprintf("N%d", load), func(t *testing.T) {
+			poissonPacer := NewPoissonPacer(vegeta.Rate{Freq: load, Per: time.Second}, int64(load))
+			runTest(t, poissonPacer, true)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/knative/test-infra/shared/junit"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/testgrid"
+	"knative.dev/serving/pkg/reconciler/revision/resources/names"
+	"knative.dev/serving/test"
+	"knative.dev/serving/test/e2e"
+)
+
+// coldStartIterations is the number of times the service is scaled to zero
+// and woken up by a single request.
+const coldStartIterations = 20
+
+// percentiles are the percentiles of the cold start latency we report.
+var percentiles = []float64{50, 90, 99}
+
+// timeToFirstByte sends a single GET request for host to url and returns how
+// long it took until the first byte of the response arrived.
+func timeToFirstByte(client *http.Client, url, host string) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Host = host
+
+	var ttfb time.Duration
+	start := time.Now()
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			ttfb = time.Since(start)
+		},
+	}))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return ttfb, nil
+}
+
+// percentile returns the p-th percentile of the sorted durations, using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// TestColdStartLatency repeatedly lets a service scale to zero, wakes it up
+// with a single request and reports the distribution of the time to first
+// byte of that request.
+func TestColdStartLatency(t *testing.T) {
+	pc, err := Setup(t)
+	if err != nil {
+		t.Fatalf("Failed to setup clients: %v", err)
+	}
+	objs, cleanup, err := createServices(t, pc, 1)
+	if err != nil {
+		t.Fatalf("Failed to create services: %v", err)
+	}
+	defer cleanup()
+
+	clients := pc.E2EClients
+	ro := objs[0]
+	domain := ro.Route.Status.URL.Host
+	deploymentName := names.Deployment(ro.Revision)
+
+	// Talk to the ingress directly unless the domain is resolvable, so that
+	// nothing but the measured request reaches the service.
+	target := domain
+	if !test.ServingFlags.ResolvableDomain {
+		endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
+		if err != nil {
+			t.Fatalf("Cannot get service endpoint: %v", err)
+		}
+		target = endpoint
+	}
+	client := &http.Client{Timeout: waitToServe}
+
+	durations := make([]time.Duration, 0, coldStartIterations)
+	for i := 0; i < coldStartIterations; i++ {
+		if err := e2e.WaitForScaleToZero(t, deploymentName, clients); err != nil {
+			t.Fatalf("%02d: failed waiting for deployment to scale to zero: %v", i, err)
+		}
+		ttfb, err := timeToFirstByte(client, "http://"+target, domain)
+		if err != nil {
+			t.Fatalf("%02d: cold start request failed: %v", i, err)
+		}
+		t.Logf("%02d: time to first byte: %v", i, ttfb)
+		durations = append(durations, ttfb)
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	tName := t.Name()
+	tc := make([]junit.TestCase, 0, len(percentiles)+2)
+	for _, p := range percentiles {
+		tc = append(tc, perf.CreatePerfTestCase(float32(percentile(durations, p).Seconds()), fmt.Sprintf("p%d(s)", int(p)), tName))
+	}
+	tc = append(tc,
+		perf.CreatePerfTestCase(float32(durations[0].Seconds()), "Min", tName),
+		perf.CreatePerfTestCase(float32(durations[len(durations)-1].Seconds()), "Max", tName))
+	if err := testgrid.CreateXMLOutput(tc, tName); err != nil {
+		t.Fatalf("Error creating testgrid output: %v", err)
+	}
+}
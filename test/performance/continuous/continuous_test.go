@@ -0,0 +1,220 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{{
+		name: "valid",
+		yaml: `
+- name: steady
+  url: http://foo.default.svc.cluster.local
+  rate: 100
+  duration: 4m
+- name: burst
+  url: http://foo.default.svc.cluster.local
+  host: foo.example.com
+  rate: 1000
+  duration: 30s`,
+	}, {
+		name:    "empty",
+		yaml:    "[]",
+		wantErr: true,
+	}, {
+		name: "duplicate name",
+		yaml: `
+- name: steady
+  url: http://foo
+  rate: 1
+  duration: 1m
+- name: steady
+  url: http://bar
+  rate: 1
+  duration: 1m`,
+		wantErr: true,
+	}, {
+		name: "no url",
+		yaml: `
+- name: steady
+  rate: 1
+  duration: 1m`,
+		wantErr: true,
+	}, {
+		name: "no rate",
+		yaml: `
+- name: steady
+  url: http://foo
+  duration: 1m`,
+		wantErr: true,
+	}, {
+		name: "bad duration",
+		yaml: `
+- name: steady
+  url: http://foo
+  rate: 1
+  duration: forever`,
+		wantErr: true,
+	}}
+
+	dir, err := ioutil.TempDir("", "scenarios")
+	if err != nil {
+		t.Fatalf("TempDir() = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, "scenarios.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.yaml), 0644); err != nil {
+				t.Fatalf("WriteFile() = %v", err)
+			}
+			got, err := loadScenarios(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("loadScenarios() = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if len(got) != 2 {
+				t.Fatalf("len(scenarios) = %d, want 2", len(got))
+			}
+			if got, want := got[1].duration, 30*time.Second; got != want {
+				t.Errorf("duration = %v, want %v", got, want)
+			}
+			if got, want := got[1].Host, "foo.example.com"; got != want {
+				t.Errorf("Host = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestRegressed(t *testing.T) {
+	latency := metric{name: "latency"}
+	success := metric{name: "success", higherIsBetter: true}
+
+	tests := []struct {
+		name   string
+		metric metric
+		value  float64
+		base   baseline
+		want   bool
+	}{{
+		name:   "latency within stddev",
+		metric: latency,
+		value:  120,
+		base:   baseline{mean: 100, stddev: 10},
+	}, {
+		name:   "latency regressed",
+		metric: latency,
+		value:  140,
+		base:   baseline{mean: 100, stddev: 10},
+		want:   true,
+	}, {
+		name:   "latency improved",
+		metric: latency,
+		value:  10,
+		base:   baseline{mean: 100, stddev: 10},
+	}, {
+		name:   "stable history within tolerance",
+		metric: latency,
+		value:  105,
+		base:   baseline{mean: 100},
+	}, {
+		name:   "stable history beyond tolerance",
+		metric: latency,
+		value:  111,
+		base:   baseline{mean: 100},
+		want:   true,
+	}, {
+		name:   "success dropped",
+		metric: success,
+		value:  0.8,
+		base:   baseline{mean: 1, stddev: 0.01},
+		want:   true,
+	}, {
+		name:   "success increased",
+		metric: success,
+		value:  1,
+		base:   baseline{mean: 0.8, stddev: 0.01},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := regressed(test.metric, test.value, test.base, 3, 0.1); got != test.want {
+				t.Errorf("regressed() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPushgateway(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer ts.Close()
+
+	p := &pushgateway{url: ts.URL, job: "bench", client: ts.Client()}
+	if err := p.push(context.Background(), "steady", map[string]float64{
+		"success_ratio":  1,
+		"latency_p50_ms": 12.5,
+	}); err != nil {
+		t.Fatalf("push() = %v", err)
+	}
+
+	if got, want := gotMethod, http.MethodPut; got != want {
+		t.Errorf("method = %s, want %s", got, want)
+	}
+	if got, want := gotPath, "/metrics/job/bench/scenario/steady"; got != want {
+		t.Errorf("path = %s, want %s", got, want)
+	}
+	want := "# TYPE knative_perf_latency_p50_ms gauge\n" +
+		"knative_perf_latency_p50_ms 12.5\n" +
+		"# TYPE knative_perf_success_ratio gauge\n" +
+		"knative_perf_success_ratio 1\n"
+	if gotBody != want {
+		t.Errorf("body = %q, want %q", gotBody, want)
+	}
+}
+
+func TestPushgatewayError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	p := &pushgateway{url: ts.URL, job: "bench", client: ts.Client()}
+	if err := p.push(context.Background(), "steady", map[string]float64{"a": 1}); err == nil {
+		t.Error("push() = nil, want error")
+	}
+}
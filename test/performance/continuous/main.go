@@ -0,0 +1,100 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// continuous runs a fixed set of load scenarios, stores their results in a
+// Prometheus pushgateway and compares them against the history of previous
+// runs to detect performance regressions. It is meant to be run on a schedule,
+// see config/continuous.yaml.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+var (
+	scenariosFile  = flag.String("scenarios", "/etc/continuous/scenarios.yaml", "The YAML file listing the scenarios to run.")
+	pushgatewayURL = flag.String("pushgateway", "", "The URL of the Prometheus pushgateway the results are pushed to.")
+	prometheusURL  = flag.String("prometheus", "", "The URL of the Prometheus server scraping the pushgateway. Regressions are not checked if empty.")
+	job            = flag.String("job", "knative-serving-continuous", "The job name the results are pushed as.")
+	window         = flag.Duration("history", 14*24*time.Hour, "How far back the history a run is compared against goes.")
+	sigmas         = flag.Float64("sigmas", 3, "How many standard deviations worse than the history a result has to be to count as a regression.")
+	tolerance      = flag.Float64("tolerance", 0.1, "The relative deviation from the mean of the history that never counts as a regression.")
+)
+
+func main() {
+	flag.Parse()
+	if *pushgatewayURL == "" {
+		log.Fatal("-pushgateway must be set")
+	}
+
+	scenarios, err := loadScenarios(*scenariosFile)
+	if err != nil {
+		log.Fatalf("Error loading scenarios: %v", err)
+	}
+
+	var hist history
+	if *prometheusURL != "" {
+		client, err := api.NewClient(api.Config{Address: *prometheusURL})
+		if err != nil {
+			log.Fatalf("Error creating Prometheus client: %v", err)
+		}
+		hist = &promHistory{api: v1.NewAPI(client), job: *job, window: *window}
+	}
+	sink := &pushgateway{url: *pushgatewayURL, job: *job, client: http.DefaultClient}
+
+	ctx := context.Background()
+	regressions := 0
+	for _, s := range scenarios {
+		log.Printf("Running scenario %s: %d rps for %v against %s", s.Name, s.Rate, s.duration, s.URL)
+		values := s.run()
+		for _, m := range metrics {
+			log.Printf("%s: %s = %v", s.Name, m.name, values[m.name])
+		}
+
+		// Query the history before pushing, so the current run is not part
+		// of its own baseline.
+		if hist != nil {
+			for _, m := range metrics {
+				b, err := hist.baseline(ctx, s.Name, m.name)
+				if err != nil {
+					log.Printf("%s: no baseline for %s, skipping: %v", s.Name, m.name, err)
+					continue
+				}
+				if regressed(m, values[m.name], b, *sigmas, *tolerance) {
+					log.Printf("%s: REGRESSION in %s: %v, baseline %v ± %v", s.Name, m.name, values[m.name], b.mean, b.stddev)
+					regressions++
+				}
+			}
+		}
+
+		if err := sink.push(ctx, s.Name, values); err != nil {
+			log.Fatalf("Error pushing results of %s: %v", s.Name, err)
+		}
+	}
+
+	if regressions > 0 {
+		log.Printf("Found %d regressions", regressions)
+		os.Exit(1)
+	}
+}
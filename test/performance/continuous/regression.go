@@ -0,0 +1,85 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/knative/test-infra/shared/prometheus"
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+// baseline describes the past values of a metric of a scenario.
+type baseline struct {
+	mean   float64
+	stddev float64
+}
+
+// history returns the baseline of a metric of a scenario.
+type history interface {
+	baseline(ctx context.Context, scenario, metric string) (baseline, error)
+}
+
+// promHistory computes the baseline from the time series Prometheus scraped
+// off the pushgateway.
+type promHistory struct {
+	api    v1.API
+	job    string
+	window time.Duration
+}
+
+var _ history = (*promHistory)(nil)
+
+func (h *promHistory) baseline(ctx context.Context, scenario, metric string) (baseline, error) {
+	series := fmt.Sprintf(`%s%s{job=%q,scenario=%q}[%ds]`, metricPrefix, metric, h.job, scenario, int(h.window.Seconds()))
+	now := time.Now()
+
+	var b baseline
+	for _, q := range []struct {
+		fn  string
+		val *float64
+	}{{
+		fn:  "avg_over_time",
+		val: &b.mean,
+	}, {
+		fn:  "stddev_over_time",
+		val: &b.stddev,
+	}} {
+		val, err := h.api.Query(ctx, fmt.Sprintf("%s(%s)", q.fn, series), now)
+		if err != nil {
+			return b, err
+		}
+		if *q.val, err = prometheus.VectorValue(val); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+// regressed returns whether value is worse than the baseline by more than
+// sigmas standard deviations. Deviations within tolerance (relative to the
+// mean) never count, so that a very stable history doesn't flag noise.
+func regressed(m metric, value float64, b baseline, sigmas, tolerance float64) bool {
+	margin := math.Max(sigmas*b.stddev, tolerance*math.Abs(b.mean))
+	if m.higherIsBetter {
+		return value < b.mean-margin
+	}
+	return value > b.mean+margin
+}
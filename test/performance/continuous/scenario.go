@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/ghodss/yaml"
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+// scenario is a fixed load pattern run against a Knative Service.
+type scenario struct {
+	// Name identifies the scenario in the time series.
+	Name string `json:"name"`
+	// URL is the URL requests are sent to.
+	URL string `json:"url"`
+	// Host optionally overrides the Host header of the requests.
+	Host string `json:"host,omitempty"`
+	// Rate is the number of requests per second.
+	Rate int `json:"rate"`
+	// Duration is how long the load is sent for, e.g. "4m".
+	Duration string `json:"duration"`
+
+	duration time.Duration
+}
+
+// loadScenarios reads and validates the scenarios in the YAML file at path.
+func loadScenarios(path string) ([]scenario, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var scenarios []scenario
+	if err := yaml.Unmarshal(b, &scenarios); err != nil {
+		return nil, err
+	}
+	if len(scenarios) == 0 {
+		return nil, errors.New("no scenarios defined")
+	}
+
+	seen := make(map[string]bool, len(scenarios))
+	for i := range scenarios {
+		s := &scenarios[i]
+		if s.Name == "" {
+			return nil, fmt.Errorf("scenario #%d has no name", i+1)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("scenario %q is defined more than once", s.Name)
+		}
+		seen[s.Name] = true
+		if s.URL == "" {
+			return nil, fmt.Errorf("scenario %q has no url", s.Name)
+		}
+		if s.Rate <= 0 {
+			return nil, fmt.Errorf("scenario %q: rate must be positive, was %d", s.Name, s.Rate)
+		}
+		if s.duration, err = time.ParseDuration(s.Duration); err != nil {
+			return nil, fmt.Errorf("scenario %q: invalid duration: %v", s.Name, err)
+		} else if s.duration <= 0 {
+			return nil, fmt.Errorf("scenario %q: duration must be positive, was %v", s.Name, s.duration)
+		}
+	}
+	return scenarios, nil
+}
+
+// run sends the load of the scenario and returns the resulting metrics.
+func (s scenario) run() map[string]float64 {
+	target := vegeta.Target{
+		Method: http.MethodGet,
+		URL:    s.URL,
+	}
+	if s.Host != "" {
+		target.Header = http.Header{"Host": {s.Host}}
+	}
+
+	var m vegeta.Metrics
+	attacker := vegeta.NewAttacker()
+	for res := range attacker.Attack(vegeta.NewStaticTargeter(target), vegeta.Rate{Freq: s.Rate, Per: time.Second}, s.duration, s.Name) {
+		m.Add(res)
+	}
+	m.Close()
+
+	values := make(map[string]float64, len(metrics))
+	for _, metric := range metrics {
+		values[metric.name] = metric.value(&m)
+	}
+	return values
+}
+
+// metric is a value extracted from the results of a scenario.
+type metric struct {
+	name string
+	// higherIsBetter is true for metrics that regress when they go down.
+	higherIsBetter bool
+	value          func(*vegeta.Metrics) float64
+}
+
+// metrics are the metrics recorded for every scenario.
+var metrics = []metric{{
+	name:  "latency_p50_ms",
+	value: func(m *vegeta.Metrics) float64 { return ms(m.Latencies.P50) },
+}, {
+	name:  "latency_p95_ms",
+	value: func(m *vegeta.Metrics) float64 { return ms(m.Latencies.P95) },
+}, {
+	name:  "latency_p99_ms",
+	value: func(m *vegeta.Metrics) float64 { return ms(m.Latencies.P99) },
+}, {
+	name:           "success_ratio",
+	higherIsBetter: true,
+	value:          func(m *vegeta.Metrics) float64 { return m.Success },
+}, {
+	name:           "throughput_rps",
+	higherIsBetter: true,
+	value:          throughput,
+}}
+
+// throughput returns the number of successful requests per second.
+func throughput(m *vegeta.Metrics) float64 {
+	total := m.Duration + m.Wait
+	if total <= 0 {
+		return 0
+	}
+	return m.Success * float64(m.Requests) / total.Seconds()
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
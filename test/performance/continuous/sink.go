@@ -0,0 +1,72 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// metricPrefix is prepended to the names of all the pushed metrics.
+const metricPrefix = "knative_perf_"
+
+// pushgateway stores the results of the scenarios in a Prometheus
+// pushgateway, from where Prometheus scrapes them into a time series.
+type pushgateway struct {
+	url    string
+	job    string
+	client *http.Client
+}
+
+// push replaces the metrics of the given scenario with values.
+func (p *pushgateway) push(ctx context.Context, scenario string, values map[string]float64) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var body bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&body, "# TYPE %s%s gauge\n", metricPrefix, name)
+		fmt.Fprintf(&body, "%s%s %s\n", metricPrefix, name, strconv.FormatFloat(values[name], 'g', -1, 64))
+	}
+
+	u := fmt.Sprintf("%s/metrics/job/%s/scenario/%s", p.url, url.PathEscape(p.job), url.PathEscape(scenario))
+	req, err := http.NewRequest(http.MethodPut, u, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("pushing to %s failed with status %d: %s", u, resp.StatusCode, b)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/knative/test-infra/shared/junit"
+	"github.com/knative/test-infra/shared/loadgenerator"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/testgrid"
+	pkgTest "knative.dev/pkg/test"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	testingv1alpha1 "knative.dev/serving/pkg/testing/v1alpha1"
+	"knative.dev/serving/test"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+// dataplaneConcurrencies are the numbers of concurrent clients each path is
+// measured with.
+var dataplaneConcurrencies = []int{1, 10, 50}
+
+// dataplanePaths are the request paths whose overhead is measured. With a
+// target burst capacity of 0 the activator is removed from the path as soon
+// as the revision has pods, with -1 it always stays in the path.
+var dataplanePaths = []struct {
+	name string
+	tbc  string
+}{{
+	name: "queue-proxy",
+	tbc:  "0",
+}, {
+	name: "activator",
+	tbc:  "-1",
+}}
+
+// TestDataplaneOverhead measures latency and maximum throughput of requests
+// to a no-op backend going through queue-proxy alone and through
+// activator+queue-proxy, so that the tax of each hop can be tracked across
+// releases.
+func TestDataplaneOverhead(t *testing.T) {
+	tName := t.Name()
+	tc := make([]junit.TestCase, 0)
+	// p50 latencies in ms, indexed by path and concurrency.
+	p50s := make(map[string]map[int]float64, len(dataplanePaths))
+	for _, path := range dataplanePaths {
+		p50s[path.name] = make(map[int]float64, len(dataplaneConcurrencies))
+		t.Run(path.name, func(t *testing.T) {
+			tc = append(tc, dataplaneOverhead(t, tName, path.name, path.tbc, p50s[path.name])...)
+		})
+	}
+
+	// The difference between both paths is the tax of the activator hop.
+	for _, c := range dataplaneConcurrencies {
+		qp, qpOK := p50s["queue-proxy"][c]
+		act, actOK := p50s["activator"][c]
+		if qpOK && actOK {
+			tc = append(tc, perf.CreatePerfTestCase(float32(act-qp), fmt.Sprintf("activator-overhead-c%03d-p50(ms)", c), tName))
+		}
+	}
+
+	if err := testgrid.CreateXMLOutput(tc, tName); err != nil {
+		t.Fatalf("Cannot create output XML: %v", err)
+	}
+}
+
+func dataplaneOverhead(t *testing.T, tName, pathName, tbc string, p50s map[int]float64) []junit.TestCase {
+	perfClients, err := Setup(t)
+	if err != nil {
+		t.Fatalf("Cannot initialize performance client: %v", err)
+	}
+
+	clients := perfClients.E2EClients
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   helloWorldImage,
+	}
+
+	defer TearDown(perfClients, names, t.Logf)
+	test.CleanupOnInterrupt(func() { TearDown(perfClients, names, t.Logf) })
+
+	t.Log("Creating a new Service")
+	objs, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+		testingv1alpha1.WithConfigAnnotations(map[string]string{
+			autoscaling.MinScaleAnnotationKey:  "1",
+			autoscaling.TargetBurstCapacityKey: tbc,
+		}))
+	if err != nil {
+		t.Fatalf("Failed to create Service: %v", err)
+	}
+
+	domain := objs.Route.Status.URL.Host
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
+	if err != nil {
+		t.Fatalf("Cannot get service endpoint: %v", err)
+	}
+
+	if _, err := pkgTest.WaitForEndpointState(
+		clients.KubeClient,
+		t.Logf,
+		domain,
+		v1a1test.RetryingRouteInconsistency(pkgTest.IsStatusOK),
+		"WaitForSuccessfulResponse",
+		test.ServingFlags.ResolvableDomain); err != nil {
+		t.Fatalf("Error probing domain %s: %v", domain, err)
+	}
+
+	tc := make([]junit.TestCase, 0)
+	for _, c := range dataplaneConcurrencies {
+		opts := loadgenerator.GeneratorOptions{
+			Duration:       duration,
+			NumThreads:     c,
+			NumConnections: c,
+			Domain:         domain,
+			// A negative QPS makes fortio send requests as fast as possible,
+			// which gives us the maximum throughput of the path.
+			BaseQPS:        -1,
+			URL:            fmt.Sprintf("http://%s/", endpoint),
+			LoadFactors:    []float64{1},
+			FileNamePrefix: strings.Replace(fmt.Sprintf("%s/c%03d", t.Name(), c), "/", "_", -1),
+		}
+		t.Logf("Generating load through %s with %d clients", pathName, c)
+		resp, err := opts.RunLoadTest(loadgenerator.AddHostHeader)
+		if err != nil {
+			t.Fatalf("Generating traffic via fortio failed: %v", err)
+		}
+
+		// Save the json result for benchmarking
+		resp.SaveJSON()
+
+		prefix := fmt.Sprintf("%s-c%03d", pathName, c)
+		tc = append(tc, perf.CreatePerfTestCase(float32(resp.Result[0].ActualQPS), prefix+"-maxQPS", tName))
+		tc = append(tc, perf.CreatePerfTestCase(float32(resp.ErrorsPercentage(0)), prefix+"-errorsPercentage", tName))
+		for _, p := range resp.Result[0].DurationHistogram.Percentiles {
+			val := p.Value * 1000
+			if p.Percentile == 50 {
+				p50s[c] = val
+			}
+			tc = append(tc, perf.CreatePerfTestCase(float32(val), fmt.Sprintf("%s-p%d(ms)", prefix, int(p.Percentile)), tName))
+		}
+	}
+	return tc
+}
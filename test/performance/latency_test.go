@@ -30,7 +30,6 @@ import (
 	perf "github.com/knative/test-infra/shared/performance"
 	"github.com/knative/test-infra/shared/testgrid"
 	pkgTest "knative.dev/pkg/test"
-	ingress "knative.dev/pkg/test/ingress"
 	"knative.dev/serving/test"
 	v1a1test "knative.dev/serving/test/v1alpha1"
 )
@@ -66,7 +65,7 @@ func timeToServe(t *testing.T, img, query string, reqTimeout time.Duration) {
 	}
 
 	domain := objs.Route.Status.URL.Host
-	endpoint, err := ingress.GetIngressEndpoint(clients.KubeClient.Kube)
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
 	if err != nil {
 		t.Fatalf("Cannot get service endpoint: %v", err)
 	}
@@ -86,7 +85,7 @@ func timeToServe(t *testing.T, img, query string, reqTimeout time.Duration) {
 		NumThreads:     1,
 		NumConnections: 5,
 		Domain:         domain,
-		URL:            fmt.Sprintf("http://%s/?%s", *endpoint, query),
+		URL:            fmt.Sprintf("http://%s/?%s", endpoint, query),
 		RequestTimeout: reqTimeout,
 		LoadFactors:    []float64{1},
 		FileNamePrefix: tName,
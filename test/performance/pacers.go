@@ -19,6 +19,8 @@ package performance
 import (
 	"fmt"
 	"math"
+	"math/rand"
+	"sync"
 	"time"
 
 	vegeta "github.com/tsenart/vegeta/lib"
@@ -137,3 +139,155 @@ func (sup steadyUpPacer) hitsPerNs(t time.Duration) float64 {
 func hitsPerNs(cp vegeta.ConstantPacer) float64 {
 	return float64(cp.Freq) / float64(cp.Per)
 }
+
+// stepPacer is a Pacer that describes attack request rates that change in steps.
+//       |          ,------.
+//       |   ,------'      |
+//       |   |             '------
+//       |---'
+//       +------------------------------> t
+//       |<-S->|
+type stepPacer struct {
+	// Steps are the attack request rates, each of which is kept for StepDuration.
+	// The last rate is kept until the end of the attack. A rate of 0 sends no
+	// requests at all.
+	Steps []vegeta.Rate
+	// StepDuration is the duration of each of the steps.
+	StepDuration time.Duration
+}
+
+// NewStepPacer returns a new stepPacer with the given config.
+func NewStepPacer(steps []vegeta.Rate, stepDuration time.Duration) vegeta.Pacer {
+	return stepPacer{
+		Steps:        steps,
+		StepDuration: stepDuration,
+	}
+}
+
+// stepPacer satisfies the Pacer interface.
+var _ vegeta.Pacer = stepPacer{}
+
+// String returns a pretty-printed description of the stepPacer's behaviour.
+func (sp stepPacer) String() string {
+	return fmt.Sprintf("Step{%v every %s}", sp.Steps, sp.StepDuration)
+}
+
+// invalid tests the constraints documented in the stepPacer struct definition.
+func (sp stepPacer) invalid() bool {
+	if sp.StepDuration <= 0 || len(sp.Steps) == 0 {
+		return true
+	}
+	for _, s := range sp.Steps {
+		if s.Freq < 0 || (s.Freq > 0 && s.Per <= 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pace determines the length of time to sleep until the next hit is sent.
+func (sp stepPacer) Pace(elapsedTime time.Duration, elapsedHits uint64) (time.Duration, bool) {
+	if sp.invalid() {
+		// If pacer configuration is invalid, stop the attack.
+		return 0, true
+	}
+
+	// Find the point in time the next hit is due at, walking the steps until
+	// they have accumulated enough hits.
+	wanted := float64(elapsedHits + 1)
+	var start time.Duration
+	var hits float64
+	for i := range sp.Steps {
+		rate := sp.hitsPerNs(i)
+		last := i == len(sp.Steps)-1
+		if rate > 0 && (last || hits+rate*float64(sp.StepDuration) >= wanted) {
+			due := start + time.Duration(math.Round((wanted-hits)/rate))
+			if due <= elapsedTime {
+				// Running behind, send next hit immediately.
+				return 0, false
+			}
+			return due - elapsedTime, false
+		}
+		if last {
+			// The final rate is 0, so no more hits are due.
+			return 0, true
+		}
+		hits += rate * float64(sp.StepDuration)
+		start += sp.StepDuration
+	}
+	return 0, true
+}
+
+// hitsPerNs returns the attack rate of the i-th step.
+func (sp stepPacer) hitsPerNs(i int) float64 {
+	if sp.Steps[i].Freq == 0 {
+		return 0
+	}
+	return hitsPerNs(sp.Steps[i])
+}
+
+// poissonPacer is a Pacer that sends hits following a Poisson process, i.e.
+// with exponentially distributed times between them, like independent users do.
+type poissonPacer struct {
+	// Rate is the average attack request rate.
+	Rate vegeta.Rate
+
+	// m guards the fields below, as the attack may ask for the same hit more
+	// than once.
+	m     sync.Mutex
+	rand  *rand.Rand
+	count uint64
+	due   time.Duration
+}
+
+// NewPoissonPacer returns a new poissonPacer with the given average rate. The
+// arrival times are drawn from a source seeded with seed, to make attacks
+// reproducible.
+func NewPoissonPacer(rate vegeta.Rate, seed int64) vegeta.Pacer {
+	p := &poissonPacer{
+		Rate: rate,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+	if !p.invalid() {
+		p.due = p.interarrival()
+	}
+	return p
+}
+
+// poissonPacer satisfies the Pacer interface.
+var _ vegeta.Pacer = (*poissonPacer)(nil)
+
+// String returns a pretty-printed description of the poissonPacer's behaviour.
+func (pp *poissonPacer) String() string {
+	return fmt.Sprintf("Poisson{%s}", pp.Rate)
+}
+
+// invalid tests the constraints documented in the poissonPacer struct definition.
+func (pp *poissonPacer) invalid() bool {
+	return pp.Rate.Freq <= 0 || pp.Rate.Per <= 0
+}
+
+// Pace determines the length of time to sleep until the next hit is sent.
+func (pp *poissonPacer) Pace(elapsedTime time.Duration, elapsedHits uint64) (time.Duration, bool) {
+	if pp.invalid() {
+		// If pacer configuration is invalid, stop the attack.
+		return 0, true
+	}
+
+	pp.m.Lock()
+	defer pp.m.Unlock()
+	for pp.count < elapsedHits {
+		pp.due += pp.interarrival()
+		pp.count++
+	}
+	if pp.due <= elapsedTime {
+		// Running behind, send next hit immediately.
+		return 0, false
+	}
+	return pp.due - elapsedTime, false
+}
+
+// interarrival draws the time until the next hit.
+func (pp *poissonPacer) interarrival() time.Duration {
+	return time.Duration(pp.rand.ExpFloat64() / hitsPerNs(pp.Rate))
+}
@@ -0,0 +1,134 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	vegeta "github.com/tsenart/vegeta/lib"
+)
+
+func TestStepPacer(t *testing.T) {
+	pacer := NewStepPacer([]vegeta.Rate{
+		{Freq: 1, Per: time.Second},
+		{Freq: 0, Per: time.Second},
+		{Freq: 2, Per: time.Second},
+	}, 10*time.Second)
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		hits     uint64
+		wantWait time.Duration
+		wantStop bool
+	}{{
+		name:     "first hit of the first step",
+		elapsed:  0,
+		hits:     0,
+		wantWait: time.Second,
+	}, {
+		name:     "running behind",
+		elapsed:  5 * time.Second,
+		hits:     2,
+		wantWait: 0,
+	}, {
+		name:     "wait through the idle step",
+		elapsed:  10 * time.Second,
+		hits:     10,
+		wantWait: 10*time.Second + 500*time.Millisecond,
+	}, {
+		name:     "last step is kept",
+		elapsed:  time.Minute,
+		hits:     90,
+		wantWait: 500 * time.Millisecond,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wait, stop := pacer.Pace(test.elapsed, test.hits)
+			if stop != test.wantStop {
+				t.Errorf("stop = %v, want %v", stop, test.wantStop)
+			}
+			if wait != test.wantWait {
+				t.Errorf("wait = %v, want %v", wait, test.wantWait)
+			}
+		})
+	}
+}
+
+func TestStepPacerEndsIdle(t *testing.T) {
+	pacer := NewStepPacer([]vegeta.Rate{{Freq: 1, Per: time.Second}, {Freq: 0}}, 10*time.Second)
+	if _, stop := pacer.Pace(10*time.Second, 10); !stop {
+		t.Error("stop = false, want true once the final idle step is reached")
+	}
+}
+
+func TestStepPacerInvalid(t *testing.T) {
+	for _, pacer := range []vegeta.Pacer{
+		NewStepPacer(nil, time.Second),
+		NewStepPacer([]vegeta.Rate{{Freq: 1, Per: time.Second}}, 0),
+		NewStepPacer([]vegeta.Rate{{Freq: -1, Per: time.Second}}, time.Second),
+	} {
+		if _, stop := pacer.Pace(0, 0); !stop {
+			t.Errorf("%v: stop = false, want true", pacer)
+		}
+	}
+}
+
+func TestPoissonPacer(t *testing.T) {
+	const (
+		rate = 100
+		hits = 10000
+	)
+	pacer := NewPoissonPacer(vegeta.Rate{Freq: rate, Per: time.Second}, 42)
+
+	// Asking for the same hit twice must give the same answer.
+	first, _ := pacer.Pace(0, 0)
+	if again, _ := pacer.Pace(0, 0); again != first {
+		t.Errorf("Pace() = %v for the same hit, was %v", again, first)
+	}
+
+	// Follow the pacer and check the average rate matches.
+	var elapsed time.Duration
+	for i := uint64(0); i < hits; i++ {
+		wait, stop := pacer.Pace(elapsed, i)
+		if stop {
+			t.Fatalf("stop = true at hit %d", i)
+		}
+		elapsed += wait
+	}
+	got := float64(hits) / elapsed.Seconds()
+	if math.Abs(got-rate)/rate > 0.05 {
+		t.Errorf("average rate = %v, want %v ± 5%%", got, rate)
+	}
+
+	// The pacer is reproducible given the same seed.
+	other := NewPoissonPacer(vegeta.Rate{Freq: rate, Per: time.Second}, 42)
+	if got, _ := other.Pace(0, 0); got != first {
+		t.Errorf("Pace() = %v with the same seed, want %v", got, first)
+	}
+}
+
+func TestPoissonPacerInvalid(t *testing.T) {
+	if _, stop := NewPoissonPacer(vegeta.Rate{}, 1).Pace(0, 0); !stop {
+		t.Error("stop = false, want true")
+	}
+}
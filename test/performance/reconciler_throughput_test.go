@@ -0,0 +1,192 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/knative/test-infra/shared/junit"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/prometheus"
+	"github.com/knative/test-infra/shared/testgrid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	"knative.dev/serving/pkg/pool"
+	testingv1alpha1 "knative.dev/serving/pkg/testing/v1alpha1"
+	"knative.dev/serving/test"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+const (
+	// reconcilerCreates is the maximum number of in-flight Service creations.
+	reconcilerCreates = 100
+	// reconcilerTimeout is the time all the Services have to become ready in.
+	reconcilerTimeout = 20 * time.Minute
+
+	// controllerCPUQuery is the CPU time in seconds used by the controller
+	// over the last %d seconds.
+	controllerCPUQuery = `sum(increase(container_cpu_usage_seconds_total{namespace="knative-serving",container_name="controller"}[%ds]))`
+	// apiCallsQuery is the number of requests against the API server for
+	// the given API group over the last %d seconds.
+	apiCallsQuery = `sum(increase(apiserver_request_total{group=~%q}[%ds]))`
+)
+
+// reconcilerScales are the numbers of Services created at once.
+var reconcilerScales = []int{100, 200, 400}
+
+// TestReconcilerThroughput creates a few hundred Services as fast as possible
+// and reports how long they take to become ready, together with the CPU used
+// by the controller and the number of API calls made in the meantime.
+func TestReconcilerThroughput(t *testing.T) {
+	var results []junit.TestCase
+	for _, scale := range reconcilerScales {
+		t.Run(fmt.Sprintf("services-%03d", scale), func(t *testing.T) {
+			results = append(results, reconcilerThroughput(t, scale)...)
+		})
+	}
+	if err := testgrid.CreateXMLOutput(results, t.Name()); err != nil {
+		t.Fatalf("Cannot create output XML: %v", err)
+	}
+}
+
+func reconcilerThroughput(t *testing.T, scale int) []junit.TestCase {
+	perfClients, err := Setup(t, EnablePrometheus)
+	if err != nil {
+		t.Fatalf("Cannot initialize performance client: %v", err)
+	}
+	clients := perfClients.E2EClients
+	defer TearDown(perfClients, test.ResourceNames{}, t.Logf)
+
+	width := int(math.Ceil(math.Log10(float64(scale))))
+	var (
+		mu       sync.Mutex
+		ready    = make([]time.Duration, 0, scale)
+		allNames = make([]test.ResourceNames, 0, scale)
+	)
+	cleanup := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, names := range allNames {
+			test.TearDown(clients, names)
+		}
+	}
+	defer cleanup()
+	test.CleanupOnInterrupt(cleanup)
+
+	t.Logf("Creating %d Services", scale)
+	start := time.Now()
+	wg := pool.NewWithCapacity(reconcilerCreates, scale)
+	for i := 0; i < scale; i++ {
+		names := test.ResourceNames{
+			Service: test.SubServiceNameForTest(t, fmt.Sprintf("%0[1]*[2]d", width, i)),
+			Image:   helloWorldImage,
+		}
+		wg.Go(func() error {
+			if _, err := v1a1test.CreateLatestService(t, clients, names,
+				testingv1alpha1.WithResourceRequirements(corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("10m"),
+						corev1.ResourceMemory: resource.MustParse("20Mi"),
+					},
+				}),
+				testingv1alpha1.WithConfigAnnotations(map[string]string{
+					autoscaling.MaxScaleAnnotationKey: "1",
+				})); err != nil {
+				return fmt.Errorf("failed to create Service %s: %v", names.Service, err)
+			}
+			mu.Lock()
+			allNames = append(allNames, names)
+			mu.Unlock()
+
+			if err := v1a1test.WaitForServiceState(clients.ServingAlphaClient, names.Service,
+				v1a1test.IsServiceReady, "ServiceIsReady"); err != nil {
+				return fmt.Errorf("Service %s did not become ready: %v", names.Service, err)
+			}
+			mu.Lock()
+			ready = append(ready, time.Since(start))
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	doneCh := make(chan error, 1)
+	go func() {
+		doneCh <- wg.Wait()
+	}()
+	select {
+	case err := <-doneCh:
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	case <-time.After(reconcilerTimeout):
+		t.Fatalf("Timed out after %v waiting for %d Services to become ready", reconcilerTimeout, scale)
+	}
+	elapsed := time.Since(start)
+	t.Logf("%d Services became ready in %v", scale, elapsed)
+
+	tName := t.Name()
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Slice(ready, func(i, j int) bool { return ready[i] < ready[j] })
+	tc := make([]junit.TestCase, 0, len(percentiles)+6)
+	for _, p := range percentiles {
+		tc = append(tc, perf.CreatePerfTestCase(float32(percentile(ready, p).Seconds()), fmt.Sprintf("time-to-ready-p%d(s)", int(p)), tName))
+	}
+	tc = append(tc,
+		perf.CreatePerfTestCase(float32(ready[len(ready)-1].Seconds()), "time-to-ready-max(s)", tName),
+		perf.CreatePerfTestCase(float32(float64(scale)/elapsed.Seconds()), "services-per-second", tName))
+
+	// Query the control plane costs over the duration of the run.
+	prometheus.AllowPrometheusSync(t.Logf)
+	promAPI, err := prometheus.PromAPI()
+	if err != nil {
+		t.Logf("Cannot setup prometheus API, skipping control plane metrics: %v", err)
+		return tc
+	}
+	window := int(time.Since(start).Seconds())
+	queries := []struct {
+		name  string
+		query string
+	}{{
+		name:  "controller-cpu(s)",
+		query: fmt.Sprintf(controllerCPUQuery, window),
+	}, {
+		name:  "api-calls-knative",
+		query: fmt.Sprintf(apiCallsQuery, ".*knative.dev", window),
+	}, {
+		name:  "api-calls-core",
+		query: fmt.Sprintf(apiCallsQuery, "|apps", window),
+	}}
+	for _, q := range queries {
+		val, err := prometheus.RunQuery(context.Background(), t.Logf, promAPI, q.query)
+		if err != nil {
+			t.Logf("Error querying %s: %v", q.name, err)
+			continue
+		}
+		tc = append(tc, perf.CreatePerfTestCase(float32(val), q.name, tName))
+	}
+	return tc
+}
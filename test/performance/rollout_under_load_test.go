@@ -0,0 +1,151 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knative/test-infra/shared/junit"
+	"github.com/knative/test-infra/shared/loadgenerator"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/testgrid"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/serving/test"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+const (
+	// rolloutClients is the number of clients generating the steady load.
+	rolloutClients = 20
+	// rollouts is the number of new revisions stamped out under load.
+	rollouts = 3
+	// rolloutInterval is the time between two consecutive rollouts.
+	rolloutInterval = 45 * time.Second
+)
+
+// TestRolloutUnderLoad drives steady load at a Service while repeatedly
+// updating its template, and reports the error rate and latency observed
+// during the resulting traffic shifts.
+func TestRolloutUnderLoad(t *testing.T) {
+	perfClients, err := Setup(t)
+	if err != nil {
+		t.Fatalf("Cannot initialize performance client: %v", err)
+	}
+
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   helloWorldImage,
+	}
+	clients := perfClients.E2EClients
+
+	defer TearDown(perfClients, names, t.Logf)
+	test.CleanupOnInterrupt(func() { TearDown(perfClients, names, t.Logf) })
+
+	t.Log("Creating a new Service")
+	objs, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names)
+	if err != nil {
+		t.Fatalf("Failed to create Service: %v", err)
+	}
+
+	domain := objs.Route.Status.URL.Host
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
+	if err != nil {
+		t.Fatalf("Cannot get service endpoint: %v", err)
+	}
+
+	opts := loadgenerator.GeneratorOptions{
+		// Keep the load going for a while after the last rollout.
+		Duration:       (rollouts + 1) * rolloutInterval,
+		NumThreads:     rolloutClients,
+		NumConnections: rolloutClients,
+		Domain:         domain,
+		BaseQPS:        qpsPerClient * rolloutClients,
+		URL:            fmt.Sprintf("http://%s/", endpoint),
+		LoadFactors:    []float64{1},
+		FileNamePrefix: strings.Replace(t.Name(), "/", "_", -1),
+	}
+
+	type loadResult struct {
+		resp *loadgenerator.GeneratorResults
+		err  error
+	}
+	loadCh := make(chan loadResult, 1)
+	t.Logf("Starting load with %d clients at %s", rolloutClients, time.Now())
+	go func() {
+		resp, err := opts.RunLoadTest(loadgenerator.AddHostHeader)
+		loadCh <- loadResult{resp: resp, err: err}
+	}()
+
+	tName := t.Name()
+	tc := make([]junit.TestCase, 0)
+	svc := objs.Service
+	for i := 1; i <= rollouts; i++ {
+		time.Sleep(rolloutInterval)
+
+		desired := svc.DeepCopy()
+		container := desired.Spec.ConfigurationSpec.GetTemplate().Spec.GetContainer()
+		container.Env = append(container.Env, corev1.EnvVar{Name: "ROLLOUT", Value: strconv.Itoa(i)})
+
+		start := time.Now()
+		t.Logf("Rollout %d: updating the Service template", i)
+		if svc, err = v1a1test.PatchService(t, clients, svc, desired); err != nil {
+			t.Fatalf("Rollout %d: failed to patch Service: %v", i, err)
+		}
+		if names.Revision, err = v1a1test.WaitForServiceLatestRevision(clients, names); err != nil {
+			t.Fatalf("Rollout %d: new Revision did not become ready: %v", i, err)
+		}
+		if err := v1a1test.WaitForServiceState(clients.ServingAlphaClient, names.Service, v1a1test.IsServiceReady, "ServiceIsReady"); err != nil {
+			t.Fatalf("Rollout %d: Service did not become ready: %v", i, err)
+		}
+		rolloutTime := time.Since(start)
+		t.Logf("Rollout %d: %s took %v", i, names.Revision, rolloutTime)
+		tc = append(tc, perf.CreatePerfTestCase(float32(rolloutTime.Seconds()), fmt.Sprintf("rollout-%02d(seconds)", i), tName))
+	}
+
+	res := <-loadCh
+	if res.err != nil {
+		t.Fatalf("Generating traffic via fortio failed: %v", res.err)
+	}
+	resp := res.resp
+
+	// Save the json result for benchmarking
+	resp.SaveJSON()
+
+	tc = append(tc, perf.CreatePerfTestCase(float32(resp.Result[0].DurationHistogram.Count), "requestCount", tName))
+	tc = append(tc, perf.CreatePerfTestCase(float32(resp.Result[0].ActualQPS), "actualQPS", tName))
+	tc = append(tc, perf.CreatePerfTestCase(float32(resp.ErrorsPercentage(0)), "errorsPercentage", tName))
+	for code, count := range resp.Result[0].RetCodes {
+		if code != 200 {
+			t.Logf("Got %d responses with status %d during the rollouts", count, code)
+		}
+	}
+	for _, p := range resp.Result[0].DurationHistogram.Percentiles {
+		val := float32(p.Value) * 1000
+		name := fmt.Sprintf("p%d(ms)", int(p.Percentile))
+		tc = append(tc, perf.CreatePerfTestCase(val, name, tName))
+	}
+
+	if err := testgrid.CreateXMLOutput(tc, tName); err != nil {
+		t.Fatalf("Cannot create output XML: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/knative/test-infra/shared/junit"
+	"github.com/knative/test-infra/shared/loadgenerator"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/testgrid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/controller"
+	"knative.dev/serving/pkg/resources"
+	testingv1alpha1 "knative.dev/serving/pkg/testing/v1alpha1"
+	"knative.dev/serving/test"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+const (
+	scaleDownClients = 80
+	// scaleDownStepDuration is the duration of a single load factor. It is
+	// longer than iterationDuration, since scaling down only happens once
+	// the stable window has passed.
+	scaleDownStepDuration = 2 * time.Minute
+)
+
+// scaleDownFactors are the load factors applied one after the other. A factor
+// of 0 means no load at all for the duration of the step.
+var scaleDownFactors = []float64{1, 0.5, 0.25, 0}
+
+// TestScaleDownByLoad applies decreasing load to a revision and records how
+// quickly it scales down and whether requests fail while it does.
+func TestScaleDownByLoad(t *testing.T) {
+	perfClients, err := Setup(t)
+	if err != nil {
+		t.Fatalf("Cannot initialize performance client: %v", err)
+	}
+
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   "observed-concurrency",
+	}
+	clients := perfClients.E2EClients
+
+	defer TearDown(perfClients, names, t.Logf)
+	test.CleanupOnInterrupt(func() { TearDown(perfClients, names, t.Logf) })
+
+	t.Log("Creating a new Service")
+	objs, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+		testingv1alpha1.WithResourceRequirements(corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("20Mi"),
+			},
+		}),
+		testingv1alpha1.WithConfigAnnotations(map[string]string{"autoscaling.knative.dev/target": strconv.Itoa(targetConcurrency)}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create Service: %v", err)
+	}
+
+	domain := objs.Route.Status.URL.Host
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
+	if err != nil {
+		t.Fatalf("Cannot get service endpoint: %v", err)
+	}
+
+	scaleEvents := make([]*scaleEvent, 0)
+	var scaleEventsMutex sync.Mutex
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	factory := informers.NewSharedInformerFactory(clients.KubeClient.Kube, 0)
+	endpointsInformer := factory.Core().V1().Endpoints().Informer()
+	endpointsInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			newEndpoints := newObj.(*corev1.Endpoints)
+			if strings.Contains(newEndpoints.GetName(), names.Service) {
+				newNumAddresses := resources.ReadyAddressCount(newEndpoints)
+				oldNumAddresses := resources.ReadyAddressCount(oldObj.(*corev1.Endpoints))
+				if newNumAddresses != oldNumAddresses {
+					event := &scaleEvent{
+						oldScale:  oldNumAddresses,
+						newScale:  newNumAddresses,
+						timestamp: time.Now(),
+					}
+					scaleEventsMutex.Lock()
+					defer scaleEventsMutex.Unlock()
+					scaleEvents = append(scaleEvents, event)
+				}
+			}
+		},
+	})
+	controller.StartInformers(stopCh, endpointsInformer)
+
+	tName := t.Name()
+	tc := make([]junit.TestCase, 0)
+	stepStarts := make([]time.Time, len(scaleDownFactors))
+	for i, f := range scaleDownFactors {
+		stepStarts[i] = time.Now()
+		stepName := fmt.Sprintf("factor-%.2f", f)
+		if f == 0 {
+			// The load generator treats a QPS of 0 as "as fast as possible",
+			// so an idle step is just waiting.
+			t.Logf("Idling for %v at %s", scaleDownStepDuration, stepStarts[i])
+			time.Sleep(scaleDownStepDuration)
+			continue
+		}
+
+		// The load generator multiplies the factors cumulatively, so every
+		// step gets its own run with the absolute QPS.
+		opts := loadgenerator.GeneratorOptions{
+			Duration:       scaleDownStepDuration,
+			NumThreads:     scaleDownClients,
+			NumConnections: scaleDownClients,
+			Domain:         domain,
+			BaseQPS:        qpsPerClient * scaleDownClients * f,
+			URL:            fmt.Sprintf("http://%s/?timeout=%d", endpoint, processingTimeMillis),
+			LoadFactors:    []float64{1},
+			FileNamePrefix: strings.Replace(tName+"/"+stepName, "/", "_", -1),
+		}
+
+		t.Logf("Starting load at factor %.2f at %s", f, stepStarts[i])
+		resp, err := opts.RunLoadTest(loadgenerator.AddHostHeader)
+		if err != nil {
+			t.Fatalf("Generating traffic via fortio failed: %v", err)
+		}
+
+		// Save the json result for benchmarking
+		resp.SaveJSON()
+
+		tc = append(tc, perf.CreatePerfTestCase(float32(resp.Result[0].DurationHistogram.Count), stepName+"-requestCount", tName))
+		tc = append(tc, perf.CreatePerfTestCase(float32(resp.Result[0].ActualQPS), stepName+"-actualQPS", tName))
+		tc = append(tc, perf.CreatePerfTestCase(float32(resp.ErrorsPercentage(0)), stepName+"-errorsPercentage", tName))
+		for _, p := range resp.Result[0].DurationHistogram.Percentiles {
+			val := float32(p.Value) * 1000
+			name := fmt.Sprintf("%s-p%d(ms)", stepName, int(p.Percentile))
+			tc = append(tc, perf.CreatePerfTestCase(val, name, tName))
+		}
+	}
+
+	// Report every scale down relative to the start of the step it happened in.
+	scaleEventsMutex.Lock()
+	defer scaleEventsMutex.Unlock()
+	for _, ev := range scaleEvents {
+		if ev.newScale >= ev.oldScale {
+			continue
+		}
+		step := 0
+		for step+1 < len(stepStarts) && !ev.timestamp.Before(stepStarts[step+1]) {
+			step++
+		}
+		elapsed := ev.timestamp.Sub(stepStarts[step])
+		t.Logf("Scaled: %d -> %d in %v after switching to factor %.2f", ev.oldScale, ev.newScale, elapsed, scaleDownFactors[step])
+		tc = append(tc, perf.CreatePerfTestCase(float32(elapsed/time.Second),
+			fmt.Sprintf("factor-%.2f-scale-from-%02d-to-%02d(seconds)", scaleDownFactors[step], ev.oldScale, ev.newScale), tName))
+	}
+
+	if err := testgrid.CreateXMLOutput(tc, tName); err != nil {
+		t.Fatalf("Cannot create output XML: %v", err)
+	}
+}
@@ -36,7 +36,6 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"knative.dev/pkg/controller"
 	pkgTest "knative.dev/pkg/test"
-	ingress "knative.dev/pkg/test/ingress"
 	"knative.dev/serving/pkg/resources"
 	testingv1alpha1 "knative.dev/serving/pkg/testing/v1alpha1"
 	"knative.dev/serving/test"
@@ -102,7 +101,7 @@ func scaleRevisionByLoad(t *testing.T, numClients int) []junit.TestCase {
 	}
 
 	domain := objs.Route.Status.URL.Host
-	endpoint, err := ingress.GetIngressEndpoint(clients.KubeClient.Kube)
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
 	if err != nil {
 		t.Fatalf("Cannot get service endpoint: %v", err)
 	}
@@ -156,7 +155,7 @@ func scaleRevisionByLoad(t *testing.T, numClients int) []junit.TestCase {
 		NumConnections: numClients,
 		Domain:         domain,
 		BaseQPS:        qpsPerClient * float64(numClients),
-		URL:            fmt.Sprintf("http://%s/?timeout=%d", *endpoint, processingTimeMillis),
+		URL:            fmt.Sprintf("http://%s/?timeout=%d", endpoint, processingTimeMillis),
 		LoadFactors:    []float64{1},
 		FileNamePrefix: strings.Replace(t.Name(), "/", "_", -1),
 	}
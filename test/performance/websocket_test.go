@@ -0,0 +1,156 @@
+// +build performance
+
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/knative/test-infra/shared/junit"
+	perf "github.com/knative/test-infra/shared/performance"
+	"github.com/knative/test-infra/shared/testgrid"
+	"golang.org/x/sync/errgroup"
+	"knative.dev/serving/pkg/apis/autoscaling"
+	testingv1alpha1 "knative.dev/serving/pkg/testing/v1alpha1"
+	"knative.dev/serving/test"
+	v1a1test "knative.dev/serving/test/v1alpha1"
+)
+
+const (
+	wsServerImage = "wsserver"
+	// wsEchoRounds is the number of messages sent over every connection.
+	wsEchoRounds = 10
+)
+
+// wsConnections are the numbers of concurrently open connections measured.
+var wsConnections = []int{10, 50, 100}
+
+// TestWebSocketConnections opens increasing numbers of concurrent websocket
+// connections to a service and reports how long establishing them took and
+// the round trip time of messages sent over them.
+func TestWebSocketConnections(t *testing.T) {
+	perfClients, err := Setup(t)
+	if err != nil {
+		t.Fatalf("Cannot initialize performance client: %v", err)
+	}
+
+	clients := perfClients.E2EClients
+	names := test.ResourceNames{
+		Service: test.ObjectNameForTest(t),
+		Image:   wsServerImage,
+	}
+
+	defer TearDown(perfClients, names, t.Logf)
+	test.CleanupOnInterrupt(func() { TearDown(perfClients, names, t.Logf) })
+
+	t.Log("Creating a new Service")
+	objs, err := v1a1test.CreateRunLatestServiceReady(t, clients, &names,
+		testingv1alpha1.WithConfigAnnotations(map[string]string{
+			autoscaling.MinScaleAnnotationKey: "1",
+		}))
+	if err != nil {
+		t.Fatalf("Failed to create Service: %v", err)
+	}
+
+	domain := objs.Route.Status.URL.Host
+	endpoint, err := test.GetIngressEndpoint(clients.KubeClient.Kube)
+	if err != nil {
+		t.Fatalf("Cannot get service endpoint: %v", err)
+	}
+	u := url.URL{Scheme: "ws", Host: endpoint, Path: "/"}
+
+	tName := t.Name()
+	tc := make([]junit.TestCase, 0)
+	for _, n := range wsConnections {
+		t.Logf("Opening %d connections", n)
+		connects, rtts, failures := measureWebSocketConnections(t, u.String(), domain, n)
+
+		prefix := fmt.Sprintf("c%03d", n)
+		tc = append(tc, perf.CreatePerfTestCase(float32(failures), prefix+"-failures", tName))
+		for _, p := range percentiles {
+			tc = append(tc,
+				perf.CreatePerfTestCase(float32(percentile(connects, p).Seconds()*1000), fmt.Sprintf("%s-connect-p%d(ms)", prefix, int(p)), tName),
+				perf.CreatePerfTestCase(float32(percentile(rtts, p).Seconds()*1000), fmt.Sprintf("%s-rtt-p%d(ms)", prefix, int(p)), tName))
+		}
+	}
+
+	if err := testgrid.CreateXMLOutput(tc, tName); err != nil {
+		t.Fatalf("Cannot create output XML: %v", err)
+	}
+}
+
+// measureWebSocketConnections concurrently opens n connections to u, sends
+// wsEchoRounds messages over each of them and returns the sorted connection
+// times, the sorted round trip times and the number of failed connections.
+func measureWebSocketConnections(t *testing.T, u, domain string, n int) (connects, rtts []time.Duration, failures int) {
+	var (
+		mu  sync.Mutex
+		grp errgroup.Group
+	)
+	for i := 0; i < n; i++ {
+		i := i
+		grp.Go(func() error {
+			start := time.Now()
+			conn, _, err := websocket.DefaultDialer.Dial(u, http.Header{"Host": {domain}})
+			if err != nil {
+				t.Logf("Connection %d failed: %v", i, err)
+				mu.Lock()
+				failures++
+				mu.Unlock()
+				return nil
+			}
+			defer conn.Close()
+			connect := time.Since(start)
+
+			connRTTs := make([]time.Duration, 0, wsEchoRounds)
+			for j := 0; j < wsEchoRounds; j++ {
+				msg := []byte(fmt.Sprintf("%d-%d", i, j))
+				start := time.Now()
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					return fmt.Errorf("connection %d: error writing: %v", i, err)
+				}
+				if _, recv, err := conn.ReadMessage(); err != nil {
+					return fmt.Errorf("connection %d: error reading: %v", i, err)
+				} else if string(recv) != string(msg) {
+					return fmt.Errorf("connection %d: received %q, want %q", i, recv, msg)
+				}
+				connRTTs = append(connRTTs, time.Since(start))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			connects = append(connects, connect)
+			rtts = append(rtts, connRTTs...)
+			return nil
+		})
+	}
+	if err := grp.Wait(); err != nil {
+		t.Fatalf("Error sending messages: %v", err)
+	}
+
+	sort.Slice(connects, func(i, j int) bool { return connects[i] < connects[j] })
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	return connects, rtts, failures
+}
@@ -18,14 +18,21 @@ import (
 	"io"
 	"log"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	ping "knative.dev/serving/test/test_images/grpc-ping/proto"
 )
 
 const port = ":8080"
 
+// delayKey is the metadata key of the duration a unary ping is delayed by.
+const delayKey = "delay"
+
 func pong(req *ping.Request) *ping.Response {
 	return &ping.Response{Msg: req.Msg}
 }
@@ -35,6 +42,20 @@ type server struct{}
 func (s *server) Ping(ctx context.Context, req *ping.Request) (*ping.Response, error) {
 	log.Printf("Received ping: %v", req.Msg)
 
+	// Delay the response if asked to, to exercise request timeouts.
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(delayKey)) > 0 {
+		delay, err := time.ParseDuration(md.Get(delayKey)[0])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", delayKey, err)
+		}
+		log.Printf("Delaying pong by %v", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	resp := pong(req)
 
 	log.Printf("Sending pong: %v", resp.Msg)
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handlers
+
+import (
+	"net"
+
+	"knative.dev/serving/test/types"
+)
+
+// dns resolves each of the given host names using the container's resolver.
+func dns(hosts ...string) map[string]types.DNSInfo {
+	infos := map[string]types.DNSInfo{}
+	for _, host := range hosts {
+		addrs, err := net.LookupHost(host)
+		if err != nil {
+			infos[host] = types.DNSInfo{Error: err.Error()}
+			continue
+		}
+		infos[host] = types.DNSInfo{Addrs: addrs}
+	}
+	return infos
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package handlers
 
 import (
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -45,3 +46,20 @@ func fileInfo(paths ...string) map[string]types.FileInfo {
 	}
 	return files
 }
+
+// writes tries to create a file in each of the given directories and returns
+// whether that succeeded.
+func writes(dirs ...string) map[string]types.Write {
+	ws := map[string]types.Write{}
+	for _, dir := range dirs {
+		f, err := ioutil.TempFile(dir, "runtime-contract")
+		if err != nil {
+			ws[dir] = types.Write{Error: err.Error()}
+			continue
+		}
+		f.Close()
+		os.Remove(f.Name())
+		ws[dir] = types.Write{Writable: true}
+	}
+	return ws
+}
@@ -44,6 +44,8 @@ func runtimeHandler(w http.ResponseWriter, r *http.Request) {
 			Stdin:   stdin(),
 			User:    userInfo(),
 			Args:    args(),
+			Writes:  writes(append(types.MustWritableDirs, types.MayWritableDirs...)...),
+			DNS:     dns(types.ShouldResolveHosts...),
 		},
 	}
 
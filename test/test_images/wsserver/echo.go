@@ -16,12 +16,15 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
-	"knative.dev/serving/test"
+	"knative.dev/pkg/signals"
 )
 
 var addr = flag.String("addr", "localhost:8080", "http service address")
@@ -33,6 +36,38 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// conns are the currently open connections, which are closed with a
+// "going away" close frame when the server shuts down.
+var (
+	connsMu sync.Mutex
+	conns   = make(map[*websocket.Conn]struct{})
+)
+
+func track(conn *websocket.Conn) func() {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	conns[conn] = struct{}{}
+	return func() {
+		connsMu.Lock()
+		defer connsMu.Unlock()
+		delete(conns, conn)
+	}
+}
+
+// closeConnections tells all clients that the server is going away, so they
+// can distinguish a graceful shutdown from a dropped connection.
+func closeConnections() {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	for conn := range conns {
+		if err := conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second)); err != nil {
+			log.Println("Failed to send close message:", err)
+		}
+	}
+	log.Printf("Sent close message to %d clients.", len(conns))
+}
+
 func handler(w http.ResponseWriter, r *http.Request) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -40,13 +75,14 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer conn.Close()
+	defer track(conn)()
 	log.Println("Connection upgraded to WebSocket. Entering receive loop.")
 	for {
 		messageType, message, err := conn.ReadMessage()
 		if err != nil {
 			// We close abnormally, because we're just closing the connection in the client,
 			// which is okay. There's no value delaying closure of the connection unnecessarily.
-			if websocket.IsCloseError(err, websocket.CloseAbnormalClosure) {
+			if websocket.IsCloseError(err, websocket.CloseAbnormalClosure, websocket.CloseGoingAway) {
 				log.Println("Client disconnected.")
 			} else {
 				log.Println("Handler exiting on error:", err)
@@ -65,5 +101,12 @@ func handler(w http.ResponseWriter, r *http.Request) {
 func main() {
 	flag.Parse()
 	log.SetFlags(0)
-	test.ListenAndServeGracefully(*addr, handler)
+	server := http.Server{Addr: *addr, Handler: http.HandlerFunc(handler)}
+	go server.ListenAndServe()
+
+	<-signals.SetupSignalHandler()
+	// Hijacked connections are not tracked by the server, so they have to be
+	// closed explicitly before shutting down.
+	closeConnections()
+	server.Shutdown(context.Background())
 }
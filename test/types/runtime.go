@@ -109,6 +109,26 @@ var ShouldFiles = map[string]FileInfo{
 	},
 }
 
+// MustWritableDirs specifies the directories that MUST be writable by the container as specified in the runtime
+// contract. They must remain writable even when the operator chooses to mount the root filesystem read-only.
+var MustWritableDirs = []string{
+	"/tmp",
+	"/var/log",
+}
+
+// MayWritableDirs specifies the directories whose writability is up to the operator as specified in the runtime
+// contract, e.g. the root filesystem MAY be mounted read-only.
+var MayWritableDirs = []string{
+	"/",
+}
+
+// ShouldResolveHosts specifies the host names the container's DNS resolver SHOULD be able to resolve as specified in
+// the runtime contract. The names are relative to the cluster domain, so that they rely on the search path of the
+// container's /etc/resolv.conf.
+var ShouldResolveHosts = []string{
+	"kubernetes.default.svc",
+}
+
 // RuntimeInfo encapsulates both the host and request information.
 type RuntimeInfo struct {
 	// Request is information about the request.
@@ -148,6 +168,26 @@ type HostInfo struct {
 	Stdin  *Stdin    `json:"stdin"`
 	User   *UserInfo `json:"user"`
 	Args   []string  `json:"args"`
+	// Writes is a map of the results of writing a file into each probed directory.
+	Writes map[string]Write `json:"writes"`
+	// DNS is a map of the results of resolving each probed host name.
+	DNS map[string]DNSInfo `json:"dns"`
+}
+
+// Write contains the result of writing a file into a directory.
+type Write struct {
+	// Writable is true if a file could be created in the directory.
+	Writable bool `json:"writable"`
+	// Error is the String representation of the error returned writing the file.
+	Error string `json:"error,omitempty"`
+}
+
+// DNSInfo contains the result of resolving a host name.
+type DNSInfo struct {
+	// Addrs are the addresses the host name resolved to.
+	Addrs []string `json:"addrs,omitempty"`
+	// Error is the String representation of the error returned resolving the host name.
+	Error string `json:"error,omitempty"`
 }
 
 // Stdin contains information about the Stdin file descriptor for the container.